@@ -12,6 +12,7 @@ import android.app.Fragment;
 import android.app.FragmentManager;
 import android.app.FragmentTransaction;
 import android.content.Context;
+import android.graphics.Bitmap;
 import android.graphics.Canvas;
 import android.graphics.Color;
 import android.graphics.Matrix;
@@ -56,6 +57,8 @@ import android.view.accessibility.AccessibilityEvent;
 import android.view.accessibility.AccessibilityManager;
 
 import java.io.UnsupportedEncodingException;
+import java.util.ArrayList;
+import java.util.List;
 
 public final class GioView extends SurfaceView implements Choreographer.FrameCallback {
 	private static boolean jniLoaded;
@@ -66,6 +69,7 @@ public final class GioView extends SurfaceView implements Choreographer.FrameCal
 	private final float scrollXScale;
 	private final float scrollYScale;
 	private int keyboardHint;
+	private boolean systemGesturesDisabled;
 	private AccessibilityManager accessManager;
 
 	private long nhandle;
@@ -152,6 +156,12 @@ public final class GioView extends SurfaceView implements Choreographer.FrameCal
 		return true;
 	}
 
+	@Override public boolean onHoverEvent(MotionEvent event) {
+		// Report hovering mice and pens in range of the screen.
+		dispatchMotionEvent(event);
+		return true;
+	}
+
 	@Override public boolean onTouchEvent(MotionEvent event) {
 		// Ask for unbuffered events. Flutter and Chrome do it
 		// so assume it's good for us as well.
@@ -171,6 +181,21 @@ public final class GioView extends SurfaceView implements Choreographer.FrameCal
 		setPointerIcon(pointerIcon);
 	}
 
+	private Object newImageCursor(int[] pixels, int width, int height, int hotX, int hotY) {
+		if (Build.VERSION.SDK_INT < Build.VERSION_CODES.N) {
+			return null;
+		}
+		Bitmap bitmap = Bitmap.createBitmap(pixels, width, height, Bitmap.Config.ARGB_8888);
+		return PointerIcon.create(bitmap, hotX, hotY);
+	}
+
+	private void setImageCursor(Object icon) {
+		if (Build.VERSION.SDK_INT < Build.VERSION_CODES.N) {
+			return;
+		}
+		setPointerIcon((PointerIcon) icon);
+	}
+
 	private void setOrientation(int id, int fallback) {
 		if (Build.VERSION.SDK_INT < Build.VERSION_CODES.JELLY_BEAN_MR2) {
 			id = fallback;
@@ -250,6 +275,37 @@ public final class GioView extends SurfaceView implements Choreographer.FrameCal
 		}
 	}
 
+	private void setSystemGesturesDisabled(boolean disabled) {
+		this.systemGesturesDisabled = disabled;
+		this.updateGestureExclusion();
+	}
+
+	private void updateGestureExclusion() {
+		if (Build.VERSION.SDK_INT < Build.VERSION_CODES.Q) {
+			return;
+		}
+		List<Rect> rects = new ArrayList<Rect>();
+		if (this.systemGesturesDisabled) {
+			// The system limits the excluded height of each edge.
+			rects.add(new Rect(0, 0, this.getWidth(), this.getHeight()));
+		}
+		this.setSystemGestureExclusionRects(rects);
+	}
+
+	@Override protected void onSizeChanged(int w, int h, int oldw, int oldh) {
+		super.onSizeChanged(w, h, oldw, oldh);
+		this.updateGestureExclusion();
+	}
+
+	private void setContentProtection(boolean enabled) {
+		Window window = ((Activity) this.getContext()).getWindow();
+		if (enabled) {
+			window.addFlags(WindowManager.LayoutParams.FLAG_SECURE);
+		} else {
+			window.clearFlags(WindowManager.LayoutParams.FLAG_SECURE);
+		}
+	}
+
 	private void setStatusColor(int color, int luminance) {
 		this.setBarColor(Bar.STATUS, color, luminance);
 	}
@@ -347,6 +403,31 @@ public final class GioView extends SurfaceView implements Choreographer.FrameCal
 		return accessManager.isEnabled();
 	}
 
+	void sendA11yText(int viewId, String before, String after, int from, int removed, int added) {
+		if (!accessManager.isEnabled()) {
+			return;
+		}
+		AccessibilityEvent event = obtainA11yEvent(AccessibilityEvent.TYPE_VIEW_TEXT_CHANGED, viewId);
+		event.setBeforeText(before);
+		event.getText().add(after);
+		event.setFromIndex(from);
+		event.setRemovedCount(removed);
+		event.setAddedCount(added);
+		getParent().requestSendAccessibilityEvent(this, event);
+	}
+
+	void sendA11ySelection(int viewId, String text, int start, int end) {
+		if (!accessManager.isEnabled()) {
+			return;
+		}
+		AccessibilityEvent event = obtainA11yEvent(AccessibilityEvent.TYPE_VIEW_TEXT_SELECTION_CHANGED, viewId);
+		event.getText().add(text);
+		event.setFromIndex(start);
+		event.setToIndex(end);
+		event.setItemCount(text.length());
+		getParent().requestSendAccessibilityEvent(this, event);
+	}
+
 	void sendA11yChange(int viewId) {
 		if (!accessManager.isEnabled()) {
 			return;
@@ -362,19 +443,19 @@ public final class GioView extends SurfaceView implements Choreographer.FrameCal
 		if (nhandle == 0) {
 			return;
 		}
+		// Report the samples batched since the previous event, for
+		// coalescing into the following events.
 		for (int j = 0; j < event.getHistorySize(); j++) {
 			long time = event.getHistoricalEventTime(j);
 			for (int i = 0; i < event.getPointerCount(); i++) {
-				onTouchEvent(
+				onTouchHistory(
 						nhandle,
-						event.ACTION_MOVE,
 						event.getPointerId(i),
-						event.getToolType(i),
 						event.getHistoricalX(i, j),
 						event.getHistoricalY(i, j),
-						scrollXScale*event.getHistoricalAxisValue(MotionEvent.AXIS_HSCROLL, i, j),
-						scrollYScale*event.getHistoricalAxisValue(MotionEvent.AXIS_VSCROLL, i, j),
-						event.getButtonState(),
+						event.getHistoricalPressure(i, j),
+						event.getHistoricalAxisValue(MotionEvent.AXIS_TILT, i, j),
+						event.getHistoricalOrientation(i, j),
 						time);
 			}
 		}
@@ -393,6 +474,9 @@ public final class GioView extends SurfaceView implements Choreographer.FrameCal
 					event.getX(i), event.getY(i),
 					scrollXScale*event.getAxisValue(MotionEvent.AXIS_HSCROLL, i),
 					scrollYScale*event.getAxisValue(MotionEvent.AXIS_VSCROLL, i),
+					event.getPressure(i),
+					event.getAxisValue(MotionEvent.AXIS_TILT, i),
+					event.getOrientation(i),
 					event.getButtonState(),
 					event.getEventTime());
 		}
@@ -456,6 +540,14 @@ public final class GioView extends SurfaceView implements Choreographer.FrameCal
 		return getResources().getConfiguration().fontScale;
 	}
 
+	float getRefreshRate() {
+		Display display = getDisplay();
+		if (display == null) {
+			return 0;
+		}
+		return display.getRefreshRate();
+	}
+
 	public void start() {
 		if (nhandle != 0) {
 			onStartView(nhandle);
@@ -539,7 +631,8 @@ public final class GioView extends SurfaceView implements Choreographer.FrameCal
 	static private native void onConfigurationChanged(long handle);
 	static private native void onWindowInsets(long handle, int top, int right, int bottom, int left);
 	static public native void onLowMemory();
-	static private native void onTouchEvent(long handle, int action, int pointerID, int tool, float x, float y, float scrollX, float scrollY, int buttons, long time);
+	static private native void onTouchEvent(long handle, int action, int pointerID, int tool, float x, float y, float scrollX, float scrollY, float pressure, float tilt, float orientation, int buttons, long time);
+	static private native void onTouchHistory(long handle, int pointerID, float x, float y, float pressure, float tilt, float orientation, long time);
 	static private native void onKeyEvent(long handle, int code, int character, boolean pressed, long time);
 	static private native void onFrameCallback(long handle);
 	static private native boolean onBack(long handle);
@@ -549,6 +642,8 @@ public final class GioView extends SurfaceView implements Choreographer.FrameCal
 	static private native void onExitTouchExploration(long handle);
 	static private native void onA11yFocus(long handle, int viewId);
 	static private native void onClearA11yFocus(long handle, int viewId);
+	static private native boolean onA11yClick(long handle, int viewId);
+	static private native boolean onA11yInputFocus(long handle, int viewId);
 	static private native void imeSetSnippet(long handle, int start, int end);
 	static private native String imeSnippet(long handle);
 	static private native int imeSnippetStart(long handle);
@@ -830,6 +925,14 @@ public final class GioView extends SurfaceView implements Choreographer.FrameCal
 					GioView.this.onClearA11yFocus(nhandle, viewId);
 					GioView.this.sendA11yEvent(AccessibilityEvent.TYPE_VIEW_ACCESSIBILITY_FOCUS_CLEARED, viewId);
 					return true;
+				case AccessibilityNodeInfo.ACTION_CLICK:
+					if (GioView.this.onA11yClick(nhandle, viewId)) {
+						GioView.this.sendA11yEvent(AccessibilityEvent.TYPE_VIEW_CLICKED, viewId);
+						return true;
+					}
+					return false;
+				case AccessibilityNodeInfo.ACTION_FOCUS:
+					return GioView.this.onA11yInputFocus(nhandle, viewId);
 				}
 				return false;
 			}
@@ -0,0 +1,217 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Seikaijyu/gio/font"
+	"github.com/Seikaijyu/gio/font/opentype"
+	"github.com/Seikaijyu/gio/i18n"
+)
+
+// Assets is a bundle of application assets, such as fonts, icons and
+// translations, looked up by logical name. The bundle is usually an
+// embed.FS, so that the assets are part of the program on every
+// platform, including the Android and iOS bundles built by packaging
+// tools. Alternatively, BundledAssets returns the assets packaged into
+// the bundle of the platform.
+//
+// Logical names are slash separated paths in the bundle, such as
+// "icons/logo.png". Images may come in variants for displays of higher
+// density, named by inserting "@<scale>x" before the extension, such as
+// "icons/logo@2x.png" and "icons/logo@1.5x.png". The plain name is the
+// variant for scale 1.
+//
+// Translations are JSON files named by language tag, such as
+// "i18n/en.json" and "i18n/pt-BR.json", and are loaded into an
+// i18n.Catalog by Catalog.
+type Assets struct {
+	fsys fs.FS
+}
+
+// AssetVariant is a density variant of an asset.
+type AssetVariant struct {
+	// Name is the path of the variant in the bundle.
+	Name string
+	// Scale is the display density the variant is made for, in pixels
+	// per dp.
+	Scale float32
+}
+
+// NewAssets returns a bundle of the assets in fsys.
+func NewAssets(fsys fs.FS) *Assets {
+	return &Assets{fsys: fsys}
+}
+
+// BundledAssets returns the assets that packaging tools copy from the
+// "assets" directory of a project into the bundle of the platform:
+// the assets of the APK on Android, the resources of the application
+// bundle on iOS and macOS, and the "assets" directory next to the
+// executable on other platforms.
+//
+// BUG: BundledAssets fails on Android until init functions have
+// completed.
+func BundledAssets() (*Assets, error) {
+	fsys, err := bundleAssets()
+	if err != nil {
+		return nil, err
+	}
+	return NewAssets(fsys), nil
+}
+
+// Open opens the asset name.
+func (a *Assets) Open(name string) (fs.File, error) {
+	return a.fsys.Open(name)
+}
+
+// ReadFile reads the asset name.
+func (a *Assets) ReadFile(name string) ([]byte, error) {
+	return fs.ReadFile(a.fsys, name)
+}
+
+// Variants returns the density variants of the asset name, in order
+// of increasing scale.
+func (a *Assets) Variants(name string) ([]AssetVariant, error) {
+	dir, file := path.Split(name)
+	ext := path.Ext(file)
+	base := strings.TrimSuffix(file, ext)
+	entries, err := fs.ReadDir(a.fsys, path.Clean("./"+dir))
+	if err != nil {
+		return nil, err
+	}
+	var variants []AssetVariant
+	for _, e := range entries {
+		n := e.Name()
+		if e.IsDir() || path.Ext(n) != ext {
+			continue
+		}
+		stem := strings.TrimSuffix(n, ext)
+		switch {
+		case stem == base:
+			variants = append(variants, AssetVariant{Name: dir + n, Scale: 1})
+		case strings.HasPrefix(stem, base+"@") && strings.HasSuffix(stem, "x"):
+			s := strings.TrimSuffix(strings.TrimPrefix(stem, base+"@"), "x")
+			scale, err := strconv.ParseFloat(s, 32)
+			if err != nil || scale <= 0 {
+				continue
+			}
+			variants = append(variants, AssetVariant{Name: dir + n, Scale: float32(scale)})
+		}
+	}
+	if len(variants) == 0 {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	// Variants of equal scale, such as "@2x" and "@2.0x", are ordered
+	// by name for a deterministic choice.
+	sort.SliceStable(variants, func(i, j int) bool {
+		vi, vj := variants[i], variants[j]
+		if vi.Scale != vj.Scale {
+			return vi.Scale < vj.Scale
+		}
+		return vi.Name < vj.Name
+	})
+	return variants, nil
+}
+
+// Variant returns the best variant of the asset name for a display of
+// scale pixels per dp, such as Metric.PxPerDp. It picks the variant of
+// the smallest scale not less than scale, or the variant of the largest
+// scale if there is none, because downscaling looks better than
+// upscaling.
+func (a *Assets) Variant(name string, scale float32) (AssetVariant, error) {
+	variants, err := a.Variants(name)
+	if err != nil {
+		return AssetVariant{}, err
+	}
+	for _, v := range variants {
+		if v.Scale >= scale {
+			return v, nil
+		}
+	}
+	return variants[len(variants)-1], nil
+}
+
+// Fonts parses the OpenType fonts and collections in the directory dir
+// of the bundle, with the extensions .ttf, .otf, .ttc and .otc, such
+// as for text.WithCollection.
+func (a *Assets) Fonts(dir string) ([]font.FontFace, error) {
+	entries, err := fs.ReadDir(a.fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	var faces []font.FontFace
+	var errs []error
+	for _, e := range entries {
+		switch strings.ToLower(path.Ext(e.Name())) {
+		case ".ttf", ".otf", ".ttc", ".otc":
+		default:
+			continue
+		}
+		name := path.Join(dir, e.Name())
+		data, err := fs.ReadFile(a.fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		f, err := opentype.ParseCollection(data)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+		faces = append(faces, f...)
+	}
+	if len(faces) == 0 && len(errs) > 0 {
+		return nil, errs[0]
+	}
+	return faces, nil
+}
+
+// Catalog loads the translations in the directory dir of the bundle
+// into a new catalog whose messages for fallback are used for languages
+// that lack a message. Every file of dir with the extension .json holds
+// the messages of the language it is named after, as an object from
+// message keys to either a message or an object of its plural forms:
+//
+//	{
+//		"app.title": "Notes",
+//		"app.notes": {"one": "%d note", "other": "%d notes"}
+//	}
+func (a *Assets) Catalog(dir, fallback string) (*i18n.Catalog, error) {
+	entries, err := fs.ReadDir(a.fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	c := i18n.NewCatalog(fallback)
+	for _, e := range entries {
+		if e.IsDir() || path.Ext(e.Name()) != ".json" {
+			continue
+		}
+		name := path.Join(dir, e.Name())
+		data, err := fs.ReadFile(a.fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		var msgs map[string]json.RawMessage
+		if err := json.Unmarshal(data, &msgs); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		lang := strings.TrimSuffix(e.Name(), ".json")
+		for key, raw := range msgs {
+			var m i18n.Message
+			if err := json.Unmarshal(raw, &m.Other); err != nil {
+				// The field names of Message match the plural forms.
+				if err := json.Unmarshal(raw, &m); err != nil {
+					return nil, fmt.Errorf("%s: message %q: %w", name, key, err)
+				}
+			}
+			c.SetMessage(lang, key, m)
+		}
+	}
+	return c, nil
+}
@@ -0,0 +1,199 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package app
+
+/*
+#cgo LDFLAGS: -landroid
+
+#include <jni.h>
+#include <stdlib.h>
+#include <android/asset_manager.h>
+#include <android/asset_manager_jni.h>
+
+static AAssetManager *gio_assetManager(JNIEnv *env, jobject assets) {
+	// The manager is valid only while its Java object is.
+	return AAssetManager_fromJava(env, (*env)->NewGlobalRef(env, assets));
+}
+*/
+import "C"
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+	"sync"
+	"time"
+	"unsafe"
+)
+
+// androidAssets is the file system of the assets of the APK.
+type androidAssets struct {
+	mgr *C.AAssetManager
+}
+
+// androidAsset is an open asset.
+type androidAsset struct {
+	name  string
+	asset *C.AAsset
+}
+
+// androidAssetDir is an open directory of assets.
+type androidAssetDir struct {
+	name    string
+	entries []fs.DirEntry
+}
+
+// assetInfo describes an asset or a directory of assets, and implements
+// both fs.FileInfo and fs.DirEntry.
+type assetInfo struct {
+	name string
+	size int64
+	dir  bool
+}
+
+var assetManager struct {
+	once sync.Once
+	fsys androidAssets
+	err  error
+}
+
+func bundleAssets() (fs.FS, error) {
+	assetManager.once.Do(func() {
+		android.mu.Lock()
+		jvm, ctx := android.jvm, android.appCtx
+		android.mu.Unlock()
+		if jvm == nil {
+			assetManager.err = errors.New("app: the application context is not available")
+			return
+		}
+		runInJVM(jvm, func(env *C.JNIEnv) {
+			cls := getObjectClass(env, ctx)
+			getAssets := getMethodID(env, cls, "getAssets", "()Landroid/content/res/AssetManager;")
+			assets, err := callObjectMethod(env, ctx, getAssets)
+			if err != nil {
+				assetManager.err = err
+				return
+			}
+			assetManager.fsys.mgr = C.gio_assetManager(env, assets)
+		})
+	})
+	return assetManager.fsys, assetManager.err
+}
+
+// Open opens the asset name. Because the asset manager lists only the
+// files of directories, a directory exists if it contains files.
+func (a androidAssets) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	if name != "." {
+		if asset := a.open(name); asset != nil {
+			return &androidAsset{name: name, asset: asset}, nil
+		}
+	}
+	dirName := name
+	if name == "." {
+		dirName = ""
+	}
+	cname := C.CString(dirName)
+	defer C.free(unsafe.Pointer(cname))
+	dir := C.AAssetManager_openDir(a.mgr, cname)
+	if dir == nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	defer C.AAssetDir_close(dir)
+	d := &androidAssetDir{name: name}
+	for n := C.AAssetDir_getNextFileName(dir); n != nil; n = C.AAssetDir_getNextFileName(dir) {
+		file := path.Join(dirName, C.GoString(n))
+		info := &assetInfo{name: file}
+		if asset := a.open(file); asset != nil {
+			info.size = int64(C.AAsset_getLength64(asset))
+			C.AAsset_close(asset)
+		}
+		d.entries = append(d.entries, info)
+	}
+	if len(d.entries) == 0 && name != "." {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return d, nil
+}
+
+func (a androidAssets) open(name string) *C.AAsset {
+	cname := C.CString(name)
+	defer C.free(unsafe.Pointer(cname))
+	return C.AAssetManager_open(a.mgr, cname, C.AASSET_MODE_STREAMING)
+}
+
+func (f *androidAsset) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	n := C.AAsset_read(f.asset, unsafe.Pointer(&p[0]), C.size_t(len(p)))
+	switch {
+	case n < 0:
+		return 0, &fs.PathError{Op: "read", Path: f.name, Err: errors.New("asset read failed")}
+	case n == 0:
+		return 0, io.EOF
+	}
+	return int(n), nil
+}
+
+func (f *androidAsset) Stat() (fs.FileInfo, error) {
+	return &assetInfo{name: f.name, size: int64(C.AAsset_getLength64(f.asset))}, nil
+}
+
+func (f *androidAsset) Close() error {
+	C.AAsset_close(f.asset)
+	return nil
+}
+
+func (d *androidAssetDir) Read(p []byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.name, Err: errors.New("is a directory")}
+}
+
+func (d *androidAssetDir) Stat() (fs.FileInfo, error) {
+	return &assetInfo{name: d.name, dir: true}, nil
+}
+
+func (d *androidAssetDir) Close() error {
+	return nil
+}
+
+func (d *androidAssetDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	if n <= 0 {
+		entries := d.entries
+		d.entries = nil
+		return entries, nil
+	}
+	if len(d.entries) == 0 {
+		return nil, io.EOF
+	}
+	if n > len(d.entries) {
+		n = len(d.entries)
+	}
+	entries := d.entries[:n]
+	d.entries = d.entries[n:]
+	return entries, nil
+}
+
+func (i *assetInfo) Name() string { return path.Base(i.name) }
+
+func (i *assetInfo) Size() int64 { return i.size }
+
+func (i *assetInfo) Mode() fs.FileMode {
+	if i.dir {
+		return fs.ModeDir | 0o555
+	}
+	return 0o444
+}
+
+func (i *assetInfo) ModTime() time.Time { return time.Time{} }
+
+func (i *assetInfo) IsDir() bool { return i.dir }
+
+func (i *assetInfo) Sys() interface{} { return nil }
+
+func (i *assetInfo) Type() fs.FileMode { return i.Mode().Type() }
+
+func (i *assetInfo) Info() (fs.FileInfo, error) { return i, nil }
@@ -0,0 +1,33 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package app
+
+/*
+#cgo CFLAGS: -Werror -xobjective-c -fobjc-arc
+#cgo LDFLAGS: -framework Foundation
+
+#include <Foundation/Foundation.h>
+
+static CFTypeRef resourcePath(void) {
+	@autoreleasepool {
+		return CFBridgingRetain([[NSBundle mainBundle] resourcePath]);
+	}
+}
+*/
+import "C"
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+func bundleAssets() (fs.FS, error) {
+	path := C.resourcePath()
+	if path == 0 {
+		return nil, errors.New("app: no application bundle")
+	}
+	defer C.CFRelease(path)
+	return os.DirFS(filepath.Join(nsstringToString(path), "assets")), nil
+}
@@ -0,0 +1,20 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+//go:build !android && !darwin
+// +build !android,!darwin
+
+package app
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+func bundleAssets() (fs.FS, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	return os.DirFS(filepath.Join(filepath.Dir(exe), "assets")), nil
+}
@@ -0,0 +1,108 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package app
+
+import (
+	"errors"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+func TestAssetVariants(t *testing.T) {
+	a := NewAssets(fstest.MapFS{
+		"icons/logo.png":      {Data: []byte("1")},
+		"icons/logo@2x.png":   {Data: []byte("2")},
+		"icons/logo@1.5x.png": {Data: []byte("1.5")},
+		"icons/logo@3.0x.png": {},
+		"icons/logo@3x.png":   {},
+		"icons/logo@bad.png":  {},
+		"icons/logos.png":     {},
+		"icons/logo@3x.svg":   {},
+		"hi@4x.png":           {},
+	})
+	tests := []struct {
+		name  string
+		scale float32
+		want  string
+	}{
+		{"icons/logo.png", 1, "icons/logo.png"},
+		{"icons/logo.png", 1.25, "icons/logo@1.5x.png"},
+		{"icons/logo.png", 2, "icons/logo@2x.png"},
+		// Variants of equal scale are ordered by name.
+		{"icons/logo.png", 3, "icons/logo@3.0x.png"},
+		{"icons/logo.png", 4, "icons/logo@3x.png"},
+		{"icons/logo.png", 0.5, "icons/logo.png"},
+		{"hi.png", 1, "hi@4x.png"},
+	}
+	for _, tc := range tests {
+		v, err := a.Variant(tc.name, tc.scale)
+		if err != nil {
+			t.Fatalf("Variant(%q, %v): %v", tc.name, tc.scale, err)
+		}
+		if v.Name != tc.want {
+			t.Errorf("Variant(%q, %v) = %q, want %q", tc.name, tc.scale, v.Name, tc.want)
+		}
+	}
+	if _, err := a.Variant("icons/missing.png", 1); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("missing asset: got error %v, want fs.ErrNotExist", err)
+	}
+	data, err := a.ReadFile("icons/logo@1.5x.png")
+	if err != nil || string(data) != "1.5" {
+		t.Errorf("ReadFile = %q, %v", data, err)
+	}
+}
+
+func TestAssetFonts(t *testing.T) {
+	a := NewAssets(fstest.MapFS{
+		"fonts/regular.ttf": {Data: goregular.TTF},
+		"fonts/README":      {Data: []byte("not a font")},
+	})
+	faces, err := a.Fonts("fonts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(faces) != 1 {
+		t.Errorf("got %d faces, want 1", len(faces))
+	}
+}
+
+func TestAssetCatalog(t *testing.T) {
+	a := NewAssets(fstest.MapFS{
+		"i18n/en.json":    {Data: []byte(`{"title": "Notes", "notes": {"one": "%d note", "other": "%d notes"}}`)},
+		"i18n/pt-BR.json": {Data: []byte(`{"title": "Notas"}`)},
+		"i18n/README":     {Data: []byte("not a translation")},
+	})
+	c, err := a.Catalog("i18n", "en")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		lang, key string
+		n         int
+		want      string
+	}{
+		{"en", "notes", 1, "1 note"},
+		{"en", "notes", 2, "2 notes"},
+		// Missing messages fall back to English.
+		{"pt-BR", "notes", 3, "3 notes"},
+	}
+	for _, tc := range tests {
+		if got := c.Plural(tc.lang, tc.key, tc.n, tc.n); got != tc.want {
+			t.Errorf("%s %s(%d) = %q, want %q", tc.lang, tc.key, tc.n, got, tc.want)
+		}
+	}
+	for lang, want := range map[string]string{"en": "Notes", "pt-BR": "Notas", "pt": "Notes"} {
+		if got := c.Text(lang, "title"); got != want {
+			t.Errorf("%s title = %q, want %q", lang, got, want)
+		}
+	}
+	bad := NewAssets(fstest.MapFS{
+		"i18n/en.json": {Data: []byte(`{"title": 1}`)},
+	})
+	if _, err := bad.Catalog("i18n", "en"); err == nil {
+		t.Error("loaded a message that is neither a string nor plural forms")
+	}
+}
@@ -0,0 +1,137 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package app
+
+import (
+	"sync"
+	"time"
+)
+
+// FrameScheduler drives the frame timing of a window: the time reported
+// by FrameEvent.Now, and when the frames requested by op.InvalidateOp
+// are delivered. Implement it to run windows in virtual time, such as
+// ManualClock, or to synchronize Gio to an external clock such as the
+// frame clock of a host compositor.
+type FrameScheduler interface {
+	// Now returns the current time.
+	Now() time.Time
+	// AfterFunc arranges for f to be called, from any goroutine, once
+	// the time reaches t. Calling stop before then cancels the call.
+	// f doesn't block.
+	AfterFunc(t time.Time, f func()) (stop func())
+}
+
+// systemScheduler is the FrameScheduler of the system clock.
+type systemScheduler struct{}
+
+// clockScheduler is the FrameScheduler of the FrameClock option. Its
+// timers measure durations relative to now in real time.
+type clockScheduler struct {
+	now func() time.Time
+}
+
+// ManualClock is a synthetic clock that only moves when advanced
+// explicitly. Use it with the Scheduler option to test animations
+// without sleeping: frames scheduled by op.InvalidateOp are delivered
+// when the clock is advanced past their time. The zero value starts at
+// the zero time and is ready to use.
+type ManualClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*manualTimer
+}
+
+type manualTimer struct {
+	at time.Time
+	f  func()
+}
+
+func (systemScheduler) Now() time.Time {
+	return time.Now()
+}
+
+func (systemScheduler) AfterFunc(t time.Time, f func()) func() {
+	timer := time.AfterFunc(time.Until(t), f)
+	return func() { timer.Stop() }
+}
+
+func (c *clockScheduler) Now() time.Time {
+	return c.now()
+}
+
+func (c *clockScheduler) AfterFunc(t time.Time, f func()) func() {
+	timer := time.AfterFunc(t.Sub(c.now()), f)
+	return func() { timer.Stop() }
+}
+
+// frameScheduler returns the scheduler set by the options, or the
+// system clock.
+func (c *Config) frameScheduler() FrameScheduler {
+	if c.scheduler == nil {
+		return systemScheduler{}
+	}
+	return c.scheduler
+}
+
+// Now returns the current time of the clock.
+func (c *ManualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Set the current time of the clock, and run the functions scheduled
+// at or before t.
+func (c *ManualClock) Set(t time.Time) {
+	c.mu.Lock()
+	c.now = t
+	c.fire()
+}
+
+// Advance the clock by d.
+func (c *ManualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	c.fire()
+}
+
+// AfterFunc implements FrameScheduler. f is called by the Set or
+// Advance call that moves the clock to t or later.
+func (c *ManualClock) AfterFunc(t time.Time, f func()) (stop func()) {
+	c.mu.Lock()
+	timer := &manualTimer{at: t, f: f}
+	c.timers = append(c.timers, timer)
+	c.fire()
+	return func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		for i, t := range c.timers {
+			if t == timer {
+				c.timers = append(c.timers[:i], c.timers[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// fire removes the due timers and runs them. It is called with c.mu
+// held and releases it.
+func (c *ManualClock) fire() {
+	var due []*manualTimer
+	timers := c.timers[:0]
+	for _, t := range c.timers {
+		if t.at.After(c.now) {
+			timers = append(timers, t)
+		} else {
+			due = append(due, t)
+		}
+	}
+	for i := len(timers); i < len(c.timers); i++ {
+		c.timers[i] = nil
+	}
+	c.timers = timers
+	c.mu.Unlock()
+	for _, t := range due {
+		t.f()
+	}
+}
@@ -0,0 +1,77 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Seikaijyu/gio/unit"
+)
+
+func TestManualClockAfterFunc(t *testing.T) {
+	var c ManualClock
+	start := c.Now()
+	var fired []int
+	c.AfterFunc(start.Add(2*time.Second), func() { fired = append(fired, 2) })
+	stop := c.AfterFunc(start.Add(time.Second), func() { fired = append(fired, 1) })
+	c.AfterFunc(start.Add(3*time.Second), func() { fired = append(fired, 3) })
+	c.Advance(500 * time.Millisecond)
+	if len(fired) != 0 {
+		t.Fatalf("fired %v before their time", fired)
+	}
+	stop()
+	c.Advance(2 * time.Second)
+	if len(fired) != 1 || fired[0] != 2 {
+		t.Fatalf("fired %v, want [2]", fired)
+	}
+	c.Set(start.Add(time.Hour))
+	if len(fired) != 2 || fired[1] != 3 {
+		t.Fatalf("fired %v, want [2 3]", fired)
+	}
+	// Functions scheduled in the past run immediately.
+	c.AfterFunc(start, func() { fired = append(fired, 0) })
+	if len(fired) != 3 {
+		t.Fatalf("fired %v, want [2 3 0]", fired)
+	}
+}
+
+func TestManualClock(t *testing.T) {
+	var c ManualClock
+	if now := c.Now(); !now.IsZero() {
+		t.Fatalf("zero clock at %v, want the zero time", now)
+	}
+	c.Advance(time.Second)
+	if got, want := c.Now(), (time.Time{}).Add(time.Second); !got.Equal(want) {
+		t.Errorf("advanced clock at %v, want %v", got, want)
+	}
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	c.Set(start)
+	c.Advance(16 * time.Millisecond)
+	if got, want := c.Now(), start.Add(16*time.Millisecond); !got.Equal(want) {
+		t.Errorf("clock at %v, want %v", got, want)
+	}
+}
+
+func TestFrameClock(t *testing.T) {
+	var clock ManualClock
+	clock.Set(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	var cnf Config
+	if _, ok := cnf.frameScheduler().(systemScheduler); !ok {
+		t.Fatalf("default scheduler is %T, want the system clock", cnf.frameScheduler())
+	}
+	m := unit.Metric{PxPerDp: 1}
+	cnf.apply(m, []Option{FrameClock(clock.Now)})
+	s := cnf.frameScheduler()
+	if got, want := s.Now(), clock.Now(); !got.Equal(want) {
+		t.Errorf("FrameClock scheduler at %v, want %v", got, want)
+	}
+	clock.Advance(time.Minute)
+	if got, want := s.Now(), clock.Now(); !got.Equal(want) {
+		t.Errorf("FrameClock scheduler at %v after Advance, want %v", got, want)
+	}
+	cnf.apply(m, []Option{FrameClock(nil)})
+	if _, ok := cnf.frameScheduler().(systemScheduler); !ok {
+		t.Errorf("FrameClock(nil) scheduler is %T, want the system clock", cnf.frameScheduler())
+	}
+}
@@ -0,0 +1,95 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package app
+
+import (
+	"errors"
+	"image"
+	"sync"
+)
+
+// CursorCapability describes the support of a platform for querying and
+// moving the cursor with Window.CursorPosition and Window.WarpCursor.
+type CursorCapability uint8
+
+const (
+	// CursorQuery means that CursorPosition reports the position of
+	// the cursor.
+	CursorQuery CursorCapability = 1 << iota
+	// CursorWarp means that WarpCursor moves the cursor.
+	CursorWarp
+)
+
+// ErrCursorUnsupported is returned by CursorPosition and WarpCursor on
+// platforms that lack the corresponding CursorCapability.
+var ErrCursorUnsupported = errors.New("app: querying or moving the cursor is not supported")
+
+// cursorDriver is implemented by drivers that can query and move the
+// cursor anywhere on the desktop. Its methods may be called from any
+// goroutine.
+type cursorDriver interface {
+	CursorCapabilities() CursorCapability
+	CursorPosition() (image.Point, error)
+	WarpCursor(p image.Point) error
+}
+
+// desktopCursor holds the cursorDriver of a window, if its driver is
+// one.
+type desktopCursor struct {
+	mu sync.Mutex
+	d  cursorDriver
+}
+
+// CursorCapabilities reports the support for CursorPosition and
+// WarpCursor on the platform of the window. Windows, macOS and X11
+// support both. Wayland doesn't reveal the cursor position outside the
+// windows of a program, and Android, iOS and browsers have no cursor to
+// speak of, so they support neither.
+//
+// The capabilities are known once the window is created, before its
+// first event.
+func (w *Window) CursorCapabilities() CursorCapability {
+	if d := w.cursorDriver(); d != nil {
+		return d.CursorCapabilities()
+	}
+	return 0
+}
+
+// CursorPosition returns the position of the cursor on the desktop in
+// the coordinates of Config.Position, wherever the cursor is, such as
+// for picking a color from the screen. It returns ErrCursorUnsupported
+// if the platform lacks CursorQuery.
+func (w *Window) CursorPosition() (image.Point, error) {
+	d := w.cursorDriver()
+	if d == nil || d.CursorCapabilities()&CursorQuery == 0 {
+		return image.Point{}, ErrCursorUnsupported
+	}
+	return d.CursorPosition()
+}
+
+// WarpCursor moves the cursor to p on the desktop, in the coordinates of
+// Config.Position, such as for keeping the cursor in place while the
+// pointer moves an object without a pointer lock. It returns
+// ErrCursorUnsupported if the platform lacks CursorWarp.
+func (w *Window) WarpCursor(p image.Point) error {
+	d := w.cursorDriver()
+	if d == nil || d.CursorCapabilities()&CursorWarp == 0 {
+		return ErrCursorUnsupported
+	}
+	return d.WarpCursor(p)
+}
+
+func (w *Window) cursorDriver() cursorDriver {
+	w.desktopCursor.mu.Lock()
+	defer w.desktopCursor.mu.Unlock()
+	return w.desktopCursor.d
+}
+
+// setCursorDriver records d for CursorPosition and WarpCursor, if it is
+// a cursorDriver.
+func (w *Window) setCursorDriver(d driver) {
+	cd, _ := d.(cursorDriver)
+	w.desktopCursor.mu.Lock()
+	w.desktopCursor.d = cd
+	w.desktopCursor.mu.Unlock()
+}
@@ -0,0 +1,335 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package app
+
+import (
+	"image"
+	"io"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/Seikaijyu/gio/f32"
+	"github.com/Seikaijyu/gio/io/pointer"
+	"github.com/Seikaijyu/gio/layout"
+)
+
+// DragOutEvent is sent to a Window when the pointer of a drag-and-drop
+// gesture started in the window leaves the window bounds, such as when
+// a tab is dragged out of a tabbed window. The program may respond by
+// creating a window at the pointer and handing the gesture off to it
+// with DetachDrag. DragOutEvent is sent at most once per gesture.
+type DragOutEvent struct {
+	// Types are the MIME types offered by the data source of the
+	// gesture.
+	Types []string
+	// Position is the position of the pointer relative to the window,
+	// in pixels.
+	Position f32.Point
+	// ScreenPosition is Position translated by Config.Position and
+	// converted to its units. Moving a window with the same decorations
+	// to ScreenPosition minus an offset places the pointer at that
+	// offset in the window. ScreenPosition is only meaningful on
+	// platforms that report Config.Position.
+	ScreenPosition image.Point
+}
+
+// dragState tracks the drag-and-drop gestures leaving a window.
+type dragState struct {
+	// out is set when a DragOutEvent was sent for the active gesture.
+	out bool
+	// target, mime and offset are the arguments to DetachDrag for the
+	// active gesture.
+	target *Window
+	mime   string
+	offset image.Point
+	// dropped is set when the handed off gesture ended, and its data
+	// is awaited from the source.
+	dropped bool
+	// over is the other window of the program under the pointer of
+	// the active gesture. overMime is the type accepted by the
+	// transfer target at overPos in over, or empty if there is none.
+	over     *Window
+	overMime string
+	overPos  image.Point
+}
+
+// positionDriver is implemented by drivers that report the position of
+// their windows on the desktop in Config.Position, which is required
+// for moving drag-and-drop gestures between windows.
+type positionDriver interface {
+	driver
+	reportsPosition()
+}
+
+// screenWindows tracks the desktop bounds of the windows of the
+// program, in the units of Config.Position, so drag-and-drop gestures
+// can move between them. The most recently focused window is first.
+var screenWindows struct {
+	mu      sync.Mutex
+	windows []screenWindow
+}
+
+type screenWindow struct {
+	w      *Window
+	bounds image.Rectangle
+}
+
+func (DragOutEvent) ImplementsEvent() {}
+
+// DetachDrag hands the drag-and-drop gesture reported by a DragOutEvent
+// off to target, typically a window just created at the pointer, as
+// browsers do with tabs dragged out of their windows.
+//
+// Until the gesture ends, target follows the pointer such that the
+// pointer stays at offset from its top-left corner, in pixels. Moving
+// target is supported where the Position option is: on Windows, macOS
+// and X11. When the gesture ends, the potential targets in the window
+// are cancelled, and the data source receives a transfer.RequestEvent
+// for mime. The data it offers is delivered in a transfer.DataEvent to
+// the transfer target at offset in target, which must accept mime.
+//
+// DetachDrag does nothing if the gesture has ended.
+//
+// Without DetachDrag, a gesture that moves over another window of the
+// program is dropped into that window, as if the windows shared their
+// transfer targets. The pointer position is translated by the
+// Config.Position of the windows, which must have the same
+// decorations. Moving gestures between windows is supported on
+// Windows, macOS and X11.
+func (w *Window) DetachDrag(target *Window, mime string, offset image.Point) {
+	w.driverDefer(func(d driver) {
+		if _, dragging := w.queue.q.DragSource(); !dragging {
+			return
+		}
+		w.drag.target, w.drag.mime, w.drag.offset = target, mime, offset
+		w.drag.dropped = false
+		w.queue.q.RedirectDrop(mime)
+	})
+}
+
+// trackDrag reports drag-and-drop gestures leaving the window, and
+// moves the window a gesture was handed off to, after the router
+// processed e.
+func (w *Window) trackDrag(e pointer.Event) {
+	mimes, dragging := w.queue.q.DragSource()
+	if !dragging {
+		w.drag.out = false
+		switch {
+		case w.drag.target == nil:
+		case e.Kind == pointer.Release:
+			w.drag.dropped = true
+		case e.Kind == pointer.Cancel:
+			w.drag.target = nil
+		}
+		return
+	}
+	if w.drag.dropped {
+		// A new gesture; the source of the previous one never
+		// offered its data.
+		w.drag.target, w.drag.dropped = nil, false
+	}
+	if e.Kind != pointer.Move && e.Kind != pointer.Drag {
+		return
+	}
+	screen := w.decorations.Config.Position.Add(w.toScreen(e.Position))
+	if t := w.drag.target; t != nil {
+		t.Option(Position(screen.Sub(w.toScreen(layout.FPt(w.drag.offset)))))
+		return
+	}
+	size := layout.FPt(w.decorations.Config.Size)
+	p := e.Position
+	inside := p.X >= 0 && p.Y >= 0 && p.X < size.X && p.Y < size.Y
+	var over *Window
+	if !inside {
+		over = windowAt(screen, w)
+	}
+	w.dragOver(over, mimes, screen)
+	if w.drag.out || inside || over != nil {
+		return
+	}
+	w.drag.out = true
+	w.out <- DragOutEvent{
+		Types:          mimes,
+		Position:       e.Position,
+		ScreenPosition: screen,
+	}
+}
+
+// dragOver moves the active gesture, offering mimes, over the window
+// over at the screen position, or out of other windows if over is nil.
+func (w *Window) dragOver(over *Window, mimes []string, screen image.Point) {
+	if o := w.drag.over; o != nil && o != over {
+		go o.driverDefer(func(d driver) {
+			o.queue.q.CancelExternalDrag()
+			o.redrawDrag(d)
+		})
+	}
+	if over != w.drag.over {
+		w.drag.over, w.drag.overMime = over, ""
+	}
+	if over == nil {
+		return
+	}
+	// The windows may share a driver goroutine, so don't wait for
+	// them.
+	go over.driverDefer(func(d driver) {
+		mime, pos, ok := over.dragEnter(mimes, screen)
+		over.redrawDrag(d)
+		if !ok {
+			mime = ""
+		}
+		go w.driverDefer(func(d driver) {
+			if w.drag.over == over {
+				w.drag.overMime, w.drag.overPos = mime, pos
+			}
+		})
+	})
+}
+
+// dragEnter reports a gesture offering mimes at the screen position to
+// the window, and returns the position in the window and the type
+// accepted by the transfer target there.
+func (w *Window) dragEnter(mimes []string, screen image.Point) (string, image.Point, bool) {
+	pos := w.fromScreen(screen.Sub(w.decorations.Config.Position))
+	q := &w.queue.q
+	q.ExternalDrag(mimes)
+	mime, ok := q.ExternalTarget(pos)
+	return mime, pos.Round(), ok
+}
+
+// dropOver redirects the drop of a gesture over another window to the
+// transfer target under the pointer there, before the router processes
+// the pointer event e. The data is delivered by deliverDrop like the
+// data of a gesture handed off by DetachDrag.
+func (w *Window) dropOver(e pointer.Event) {
+	o := w.drag.over
+	if o == nil || e.Kind != pointer.Release && e.Kind != pointer.Cancel {
+		return
+	}
+	mime := w.drag.overMime
+	w.drag.over, w.drag.overMime = nil, ""
+	if _, dragging := w.queue.q.DragSource(); dragging && e.Kind == pointer.Release && mime != "" {
+		w.drag.target, w.drag.mime, w.drag.offset = o, mime, w.drag.overPos
+		w.drag.dropped = false
+		w.queue.q.RedirectDrop(mime)
+		return
+	}
+	go o.driverDefer(func(d driver) {
+		o.queue.q.CancelExternalDrag()
+		o.redrawDrag(d)
+	})
+}
+
+// redrawDrag schedules a frame for the transfer events of a gesture
+// from another window.
+func (w *Window) redrawDrag(d driver) {
+	w.setNextFrame(time.Time{})
+	w.updateAnimation(d)
+}
+
+// updateScreenBounds records the desktop bounds of the window for
+// windowAt, if the driver reports them.
+func (w *Window) updateScreenBounds(d driver) {
+	if _, ok := d.(positionDriver); !ok {
+		return
+	}
+	cnf := w.decorations.Config
+	b := image.Rectangle{Min: cnf.Position, Max: cnf.Position.Add(w.toScreen(layout.FPt(cnf.Size)))}
+	s := &screenWindows
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.windows {
+		if sw := &s.windows[i]; sw.w == w {
+			sw.bounds = b
+			if cnf.Focused {
+				// Move the window to the front.
+				copy(s.windows[1:i+1], s.windows[:i])
+				s.windows[0] = screenWindow{w: w, bounds: b}
+			}
+			return
+		}
+	}
+	s.windows = append(s.windows, screenWindow{w: w, bounds: b})
+}
+
+// removeScreenBounds forgets the bounds of the window.
+func (w *Window) removeScreenBounds() {
+	s := &screenWindows
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, sw := range s.windows {
+		if sw.w == w {
+			s.windows = append(s.windows[:i], s.windows[i+1:]...)
+			return
+		}
+	}
+}
+
+// windowAt returns the foremost window of the program other than
+// except that contains the screen position p, or nil.
+func windowAt(p image.Point, except *Window) *Window {
+	s := &screenWindows
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, sw := range s.windows {
+		if sw.w != except && p.In(sw.bounds) {
+			return sw.w
+		}
+	}
+	return nil
+}
+
+// deliverDrop delivers the data offered for a gesture handed off by
+// DetachDrag, once its source offered it.
+func (w *Window) deliverDrop() {
+	data, ok := w.queue.q.ExternalOffer()
+	if !ok {
+		return
+	}
+	t, mime, pos := w.drag.target, w.drag.mime, layout.FPt(w.drag.offset)
+	w.drag.target, w.drag.dropped = nil, false
+	if t == nil {
+		data.Close()
+		return
+	}
+	// The target may share the driver goroutine of the window, so
+	// don't wait for it.
+	go t.driverDefer(func(d driver) {
+		t.receiveDrop(mime, pos, data)
+		t.redrawDrag(d)
+	})
+}
+
+// receiveDrop delivers the data of mime dropped at pos to the transfer
+// target there, or closes it if the target doesn't accept mime.
+func (w *Window) receiveDrop(mime string, pos f32.Point, data io.ReadCloser) {
+	q := &w.queue.q
+	q.ExternalDrag([]string{mime})
+	if m, ok := q.ExternalDrop(pos); ok && m == mime {
+		q.ExternalData(m, data)
+	} else {
+		data.Close()
+		q.CancelExternalDrag()
+	}
+}
+
+// toScreen converts the distance p, in pixels, to the units of
+// Config.Position.
+func (w *Window) toScreen(p f32.Point) image.Point {
+	if runtime.GOOS == "darwin" && w.metric.PxPerDp > 0 {
+		// Config.Position is in points on macOS.
+		p = p.Mul(1 / w.metric.PxPerDp)
+	}
+	return p.Round()
+}
+
+// fromScreen converts the distance p, in the units of Config.Position,
+// to pixels.
+func (w *Window) fromScreen(p image.Point) f32.Point {
+	fp := layout.FPt(p)
+	if runtime.GOOS == "darwin" && w.metric.PxPerDp > 0 {
+		fp = fp.Mul(w.metric.PxPerDp)
+	}
+	return fp
+}
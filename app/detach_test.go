@@ -0,0 +1,113 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package app
+
+import (
+	"image"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/Seikaijyu/gio/f32"
+	"github.com/Seikaijyu/gio/io/pointer"
+	"github.com/Seikaijyu/gio/io/transfer"
+	"github.com/Seikaijyu/gio/layout"
+	"github.com/Seikaijyu/gio/op"
+	"github.com/Seikaijyu/gio/op/clip"
+)
+
+// positionedDriver is a driver that reports window positions.
+type positionedDriver struct {
+	driver
+}
+
+func (positionedDriver) reportsPosition() {}
+
+func TestDragBetweenWindows(t *testing.T) {
+	// Window a is left of window b on the desktop.
+	a, b := new(Window), new(Window)
+	a.decorations.Config.Position, a.decorations.Config.Size = image.Pt(100, 100), image.Pt(200, 100)
+	b.decorations.Config.Position, b.decorations.Config.Size = image.Pt(400, 100), image.Pt(200, 100)
+	b.decorations.Config.Focused = true
+	a.updateScreenBounds(positionedDriver{})
+	b.updateScreenBounds(positionedDriver{})
+	defer a.removeScreenBounds()
+	defer b.removeScreenBounds()
+
+	// The data source is in a, and the target in b.
+	var srcOps, tgtOps op.Ops
+	src, tgt := new(int), new(int)
+	cl := clip.Rect(image.Rect(0, 0, 20, 20)).Push(&srcOps)
+	transfer.SourceOp{Tag: src, Type: "text/plain"}.Add(&srcOps)
+	cl.Pop()
+	a.queue.q.Frame(&srcOps)
+	cl = clip.Rect(image.Rect(40, 40, 80, 80)).Push(&tgtOps)
+	transfer.TargetOp{Tag: tgt, Type: "text/plain"}.Add(&tgtOps)
+	cl.Pop()
+	b.queue.q.Frame(&tgtOps)
+	a.queue.q.Events(src)
+	b.queue.q.Events(tgt)
+
+	a.queue.q.Queue(
+		pointer.Event{Kind: pointer.Press, Source: pointer.Mouse, Buttons: pointer.ButtonPrimary, Position: f32.Pt(10, 10)},
+		pointer.Event{Kind: pointer.Move, Source: pointer.Mouse, Buttons: pointer.ButtonPrimary, Position: f32.Pt(10, 10)},
+	)
+	mimes, ok := a.queue.q.DragSource()
+	if !ok {
+		t.Fatal("no drag in progress")
+	}
+
+	// The pointer moves over b.
+	pos := f32.Pt(350, 60)
+	screen := a.decorations.Config.Position.Add(a.toScreen(pos))
+	if w := windowAt(screen, a); w != b {
+		t.Fatalf("window at %v is %p, want b", screen, w)
+	}
+	if w := windowAt(a.decorations.Config.Position, a); w != nil {
+		t.Errorf("found another window over a")
+	}
+	mime, bpos, ok := b.dragEnter(mimes, screen)
+	if !ok || mime != "text/plain" {
+		t.Fatalf("target accepts %q, %v; want text/plain", mime, ok)
+	}
+	if want := image.Pt(50, 60); bpos != want {
+		t.Errorf("drag at %v in b, want %v", bpos, want)
+	}
+	if evts := b.queue.q.Events(tgt); len(evts) != 1 || evts[0] != (transfer.InitiateEvent{}) {
+		t.Errorf("target received %v, want an InitiateEvent", evts)
+	}
+
+	// The drop is redirected to b.
+	a.drag.over, a.drag.overMime, a.drag.overPos = b, mime, bpos
+	release := pointer.Event{Kind: pointer.Release, Source: pointer.Mouse, Position: pos}
+	a.dropOver(release)
+	a.queue.q.Queue(release)
+	a.trackDrag(release)
+	if a.drag.target != b || !a.drag.dropped {
+		t.Fatal("the drop wasn't handed to b")
+	}
+	evts := a.queue.q.Events(src)
+	if len(evts) != 1 || evts[0] != (transfer.RequestEvent{Type: "text/plain"}) {
+		t.Fatalf("source received %v, want a RequestEvent", evts)
+	}
+	transfer.OfferOp{Tag: src, Type: "text/plain", Data: io.NopCloser(strings.NewReader("hello"))}.Add(&srcOps)
+	a.queue.q.Frame(&srcOps)
+	data, ok := a.queue.q.ExternalOffer()
+	if !ok {
+		t.Fatal("no data offered")
+	}
+	b.receiveDrop(a.drag.mime, layout.FPt(a.drag.offset), data)
+	evts = b.queue.q.Events(tgt)
+	if len(evts) != 1 {
+		t.Fatalf("target received %v, want a DataEvent", evts)
+	}
+	e, ok := evts[0].(transfer.DataEvent)
+	if !ok {
+		t.Fatalf("target received %T, want a DataEvent", evts[0])
+	}
+	r := e.Open()
+	defer r.Close()
+	if got, _ := io.ReadAll(r); string(got) != "hello" {
+		t.Errorf("dropped %q, want %q", got, "hello")
+	}
+}
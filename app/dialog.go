@@ -0,0 +1,164 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package app
+
+import (
+	"sync"
+
+	"github.com/Seikaijyu/gio/io/event"
+	"github.com/Seikaijyu/gio/io/key"
+	"github.com/Seikaijyu/gio/io/pointer"
+	"github.com/Seikaijyu/gio/unit"
+)
+
+// dialogState tracks the relationship of a window with its owner and
+// its dialogs.
+type dialogState struct {
+	// owner and modal are the Owner and Modal options of the window.
+	owner *Window
+	modal bool
+
+	mu sync.Mutex
+	// handle is the native handle of the window, as reported by
+	// its driver for the dialogs of the window.
+	handle interface{}
+	// modals is the number of open modal dialogs of the window.
+	modals int
+	// pressed lists the pointers pressed in the window, whose
+	// gestures may end while a modal dialog is open. It is only
+	// accessed by the event loop of the window.
+	pressed []pointer.ID
+}
+
+// Owner makes the window a dialog of owner. The platform keeps the
+// dialog above its owner and centers it over the owner when it is
+// created:
+//
+//   - Windows: the dialog is an owned window of owner.
+//   - macOS: the dialog is a child window, or a sheet if it is Modal.
+//   - X11: the dialog is transient for owner.
+//   - Wayland: the owner is the parent of the dialog, through the
+//     xdg-foreign protocol if the compositor supports it.
+//
+// The owner must be created before the dialog, and other platforms
+// ignore Owner. Owner must be given to NewWindow; it is ignored by
+// Window.Option.
+func Owner(owner *Window) Option {
+	return func(_ unit.Metric, cnf *Config) {
+		cnf.owner = owner
+	}
+}
+
+// Modal blocks pointer and key input to the owner of a dialog, as
+// given by Owner, until the dialog is destroyed. Where supported, the
+// platform also disables the owner window. Pointer gestures in
+// progress in the owner when the dialog opens, such as drags, may
+// still end. Modal must be given to
+// NewWindow; it is ignored by Window.Option.
+func Modal(modal bool) Option {
+	return func(_ unit.Metric, cnf *Config) {
+		cnf.modal = modal
+	}
+}
+
+// dialogOwner returns the native handle of the owner given to Owner,
+// and whether the window is modal. The handle is nil if the window has
+// no owner or the owner has no native handle.
+func dialogOwner(options []Option) (handle interface{}, modal bool) {
+	var cnf Config
+	cnf.apply(unit.Metric{}, options)
+	o := cnf.owner
+	if o == nil {
+		return nil, false
+	}
+	o.dialog.mu.Lock()
+	defer o.dialog.mu.Unlock()
+	return o.dialog.handle, cnf.modal
+}
+
+// SetOwnerHandle records the native handle drivers use for making the
+// window the owner of dialogs.
+func (c *callbacks) SetOwnerHandle(h interface{}) {
+	c.w.dialog.mu.Lock()
+	defer c.w.dialog.mu.Unlock()
+	c.w.dialog.handle = h
+}
+
+// openDialog blocks input to the owner of a modal window.
+func (w *Window) openDialog() {
+	if o := w.dialog.owner; o != nil && w.dialog.modal {
+		o.dialog.mu.Lock()
+		o.dialog.modals++
+		o.dialog.mu.Unlock()
+	}
+}
+
+// closeDialog undoes openDialog.
+func (w *Window) closeDialog() {
+	if o := w.dialog.owner; o != nil && w.dialog.modal {
+		o.dialog.mu.Lock()
+		o.dialog.modals--
+		o.dialog.mu.Unlock()
+		w.dialog.owner = nil
+	}
+}
+
+// blockedByModal reports whether e is input blocked by a modal dialog
+// of the window.
+func (w *Window) blockedByModal(e event.Event) bool {
+	w.dialog.mu.Lock()
+	n := w.dialog.modals
+	w.dialog.mu.Unlock()
+	switch e := e.(type) {
+	case pointer.Event:
+		if n == 0 {
+			w.trackPressed(e)
+			return false
+		}
+		switch e.Kind {
+		case pointer.Cancel, pointer.Leave:
+			w.trackPressed(e)
+			return false
+		case pointer.Move, pointer.Release:
+			// Let gestures in progress end.
+			if w.pressedPointer(e.PointerID) {
+				w.trackPressed(e)
+				return false
+			}
+		}
+		return true
+	case key.Event, key.EditEvent:
+		return n > 0
+	}
+	return false
+}
+
+// trackPressed updates the pressed pointers for e.
+func (w *Window) trackPressed(e pointer.Event) {
+	d := &w.dialog
+	switch e.Kind {
+	case pointer.Press:
+		if !w.pressedPointer(e.PointerID) {
+			d.pressed = append(d.pressed, e.PointerID)
+		}
+	case pointer.Release, pointer.Leave:
+		for i, id := range d.pressed {
+			if id == e.PointerID {
+				d.pressed = append(d.pressed[:i], d.pressed[i+1:]...)
+				break
+			}
+		}
+	case pointer.Cancel:
+		d.pressed = d.pressed[:0]
+	}
+}
+
+// pressedPointer reports whether the pointer id is pressed.
+func (w *Window) pressedPointer(id pointer.ID) bool {
+	for _, p := range w.dialog.pressed {
+		if p == id {
+			return true
+		}
+	}
+	return false
+}
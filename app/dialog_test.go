@@ -0,0 +1,50 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package app
+
+import (
+	"testing"
+
+	"github.com/Seikaijyu/gio/io/key"
+	"github.com/Seikaijyu/gio/io/pointer"
+)
+
+func TestBlockedByModal(t *testing.T) {
+	w := new(Window)
+	ptr := func(k pointer.Kind, id pointer.ID) pointer.Event {
+		return pointer.Event{Kind: k, PointerID: id}
+	}
+	// A drag of pointer 1 is in progress when the dialog opens.
+	if w.blockedByModal(ptr(pointer.Press, 1)) {
+		t.Fatal("press blocked without a modal dialog")
+	}
+	w.dialog.modals = 1
+	tests := []struct {
+		e       pointer.Event
+		blocked bool
+	}{
+		{ptr(pointer.Press, 2), true},
+		{ptr(pointer.Move, 2), true},
+		{ptr(pointer.Release, 2), true},
+		{ptr(pointer.Scroll, 1), true},
+		// The gesture in progress continues and ends.
+		{ptr(pointer.Move, 1), false},
+		{ptr(pointer.Release, 1), false},
+		{ptr(pointer.Move, 1), true},
+		{ptr(pointer.Press, 1), true},
+		{ptr(pointer.Release, 1), true},
+		{ptr(pointer.Cancel, 0), false},
+	}
+	for i, tc := range tests {
+		if got := w.blockedByModal(tc.e); got != tc.blocked {
+			t.Errorf("event %d (%v of pointer %d) blocked %v, want %v", i, tc.e.Kind, tc.e.PointerID, got, tc.blocked)
+		}
+	}
+	if !w.blockedByModal(key.Event{Name: "A"}) {
+		t.Error("key event not blocked by a modal dialog")
+	}
+	w.dialog.modals = 0
+	if w.blockedByModal(key.Event{Name: "A"}) || w.blockedByModal(ptr(pointer.Press, 2)) {
+		t.Error("input blocked after the modal dialog closed")
+	}
+}
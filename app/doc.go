@@ -63,6 +63,23 @@ A FrameEvent's Queue method returns an event.Queue implementation that distribut
 incoming events to the event handlers declared in the last frame.
 See the github.com/Seikaijyu/gio/io/event package for more information about event handlers.
 
+# Building frames on other goroutines
+
+An op.Ops may be built on any goroutine, but a FrameEvent must be
+completed by its Frame function while the window waits, blocking the
+processing of events. A FrameHandoff lets a worker goroutine build
+frames at its own pace, handing each completed frame to the window.
+
+# Accessibility
+
+The semantic tree described by the operations of package
+github.com/Seikaijyu/gio/io/semantic is published to the accessibility
+services of Android and to UI Automation clients such as Narrator on
+Windows. Screen readers can navigate the tree, move the keyboard focus,
+click buttons, toggle check boxes and switches, select radio buttons and
+set the content of editors. Bridges to NSAccessibility on macOS and iOS,
+and AT-SPI on Linux are not implemented yet.
+
 # Permissions
 
 The packages under github.com/Seikaijyu/gio/app/permission should be imported
@@ -0,0 +1,171 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package app
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// FrameStats are rolling statistics of the frame times of a window. The
+// time of a frame runs from the delivery of its FrameEvent until the
+// frame is submitted to the GPU, excluding the wait for the display.
+type FrameStats struct {
+	// Frames is the number of recent frames covered by the statistics.
+	Frames int
+	// Total is the number of frames measured since the window was
+	// created.
+	Total uint64
+	// Last is the time of the most recent frame.
+	Last time.Duration
+	// Mean is the average frame time.
+	Mean time.Duration
+	// P90 is the 90th percentile of the frame times, a measure of the
+	// slow frames that is insensitive to occasional hiccups.
+	P90 time.Duration
+	// Max is the longest frame time.
+	Max time.Duration
+}
+
+// AdaptiveQuality scales the quality of rendering to the performance of
+// the machine, such as by disabling shadows and blurs when frames are
+// slow. Pass it the FrameStats of the window every frame:
+//
+//	var quality app.AdaptiveQuality
+//	quality.Add(func() { shadows = false }, func() { shadows = true })
+//	...
+//	case system.FrameEvent:
+//		quality.Update(w.FrameStats())
+//
+// AdaptiveQuality degrades quality one step at a time, in the order the
+// steps were added, while frames take longer than the budget, and
+// restores it in the opposite order when frames take less than half the
+// budget. After each step it waits for the statistics to cover frames
+// of the new quality only.
+type AdaptiveQuality struct {
+	// Budget is the target frame time. The zero value selects a 60 Hz
+	// frame time. The RefreshInterval of FrameEvents gives the budget
+	// of the display.
+	Budget time.Duration
+	// MinFrames is the number of frames needed to decide on a step. The
+	// zero value selects 30.
+	MinFrames int
+
+	steps []qualityStep
+	// level is the number of steps degraded.
+	level int
+	// changed is FrameStats.Total at the last step.
+	changed uint64
+}
+
+type qualityStep struct {
+	degrade, restore func()
+}
+
+// frameStats accumulates the frame times of a window.
+type frameStats struct {
+	mu    sync.Mutex
+	times [frameStatsLen]time.Duration
+	total uint64
+	// layout is the time of the frame being rendered spent before
+	// rendering.
+	layout time.Duration
+	// scratch is for sorting frame times.
+	scratch []time.Duration
+}
+
+// frameStatsLen is the number of frames of the rolling statistics.
+const frameStatsLen = 120
+
+// Add a degradation step, with the functions that lower and restore
+// quality. The steps added first are taken first.
+func (q *AdaptiveQuality) Add(degrade, restore func()) {
+	q.steps = append(q.steps, qualityStep{degrade: degrade, restore: restore})
+}
+
+// Level returns the number of degradation steps taken.
+func (q *AdaptiveQuality) Level() int {
+	return q.level
+}
+
+// Update takes a degradation step or restores one according to s, and
+// reports whether it did.
+func (q *AdaptiveQuality) Update(s FrameStats) bool {
+	minFrames := q.MinFrames
+	if minFrames == 0 {
+		minFrames = 30
+	}
+	budget := q.Budget
+	if budget == 0 {
+		budget = time.Second / 60
+	}
+	// Wait until the statistics cover frames of the current quality only.
+	fresh := s.Total - q.changed
+	if fresh < uint64(minFrames) || (q.changed > 0 && fresh < uint64(s.Frames)) {
+		return false
+	}
+	switch {
+	case s.P90 > budget && q.level < len(q.steps):
+		if f := q.steps[q.level].degrade; f != nil {
+			f()
+		}
+		q.level++
+	case s.P90 < budget/2 && q.level > 0:
+		q.level--
+		if f := q.steps[q.level].restore; f != nil {
+			f()
+		}
+	default:
+		return false
+	}
+	q.changed = s.Total
+	return true
+}
+
+// FrameStats returns the statistics of the recent frame times of the
+// window. It is safe to call from any goroutine.
+func (w *Window) FrameStats() FrameStats {
+	return w.frameStats.stats()
+}
+
+// startFrame records the time spent on a frame before rendering.
+func (f *frameStats) startFrame(layout time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.layout = layout
+}
+
+// endFrame records a frame whose rendering took d.
+func (f *frameStats) endFrame(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.times[f.total%frameStatsLen] = f.layout + d
+	f.total++
+	f.layout = 0
+}
+
+func (f *frameStats) stats() FrameStats {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s := FrameStats{Total: f.total}
+	n := frameStatsLen
+	if f.total < frameStatsLen {
+		n = int(f.total)
+	}
+	if n == 0 {
+		return s
+	}
+	s.Frames = n
+	s.Last = f.times[(f.total-1)%frameStatsLen]
+	f.scratch = append(f.scratch[:0], f.times[:n]...)
+	sort.Slice(f.scratch, func(i, j int) bool { return f.scratch[i] < f.scratch[j] })
+	var sum time.Duration
+	for _, t := range f.scratch {
+		sum += t
+	}
+	s.Mean = sum / time.Duration(n)
+	s.P90 = f.scratch[(n*9)/10]
+	s.Max = f.scratch[n-1]
+	return s
+}
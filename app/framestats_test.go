@@ -0,0 +1,62 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package app
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFrameStats(t *testing.T) {
+	var f frameStats
+	if s := f.stats(); s.Frames != 0 {
+		t.Fatalf("stats of no frames: %+v", s)
+	}
+	for i := 1; i <= frameStatsLen+10; i++ {
+		f.startFrame(time.Millisecond)
+		f.endFrame(time.Duration(i%10) * time.Millisecond)
+	}
+	s := f.stats()
+	if s.Frames != frameStatsLen || s.Total != frameStatsLen+10 {
+		t.Errorf("stats of %d frames of %d, want %d of %d", s.Frames, s.Total, frameStatsLen, frameStatsLen+10)
+	}
+	if s.Last != time.Millisecond || s.Max != 10*time.Millisecond {
+		t.Errorf("last %v and max %v, want 1ms and 10ms", s.Last, s.Max)
+	}
+	if s.P90 != 10*time.Millisecond || s.Mean != 5500*time.Microsecond {
+		t.Errorf("90th percentile %v and mean %v, want 10ms and 5.5ms", s.P90, s.Mean)
+	}
+}
+
+func TestAdaptiveQuality(t *testing.T) {
+	var shadows, blur = true, true
+	q := AdaptiveQuality{Budget: 10 * time.Millisecond}
+	q.Add(func() { blur = false }, func() { blur = true })
+	q.Add(func() { shadows = false }, func() { shadows = true })
+	s := FrameStats{Frames: 20, Total: 20, P90: 20 * time.Millisecond}
+	if q.Update(s) {
+		t.Fatal("stepped before MinFrames frames")
+	}
+	s.Frames, s.Total = 30, 30
+	if !q.Update(s) || blur || !shadows {
+		t.Fatalf("slow frames degraded to blur %v, shadows %v; want only blur off", blur, shadows)
+	}
+	// The next step waits for frames of the new quality.
+	s.Total += 10
+	if q.Update(s) {
+		t.Fatal("stepped again before new frames")
+	}
+	s.Total += 30
+	if !q.Update(s) || shadows || q.Level() != 2 {
+		t.Fatalf("slow frames left shadows %v at level %d", shadows, q.Level())
+	}
+	s.Total += 30
+	if q.Update(s) {
+		t.Error("stepped beyond the last step")
+	}
+	// Fast frames restore quality in reverse.
+	s.P90 = 2 * time.Millisecond
+	if !q.Update(s) || !shadows || blur {
+		t.Fatalf("fast frames restored blur %v, shadows %v; want only shadows", blur, shadows)
+	}
+}
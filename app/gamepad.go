@@ -0,0 +1,148 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package app
+
+import (
+	"io"
+	"sync"
+	"time"
+
+	"github.com/Seikaijyu/gio/io/gamepad"
+)
+
+// gamepadState is a snapshot of the state of a controller, as polled
+// by the platform gamepad support.
+type gamepadState struct {
+	connected bool
+	name      string
+	// buttons is the set of pressed buttons, indexed by
+	// gamepad.Button.
+	buttons uint32
+	// axes are the axis values, indexed by gamepad.Axis.
+	axes [gamepad.AxisRightTrigger + 1]float32
+}
+
+// gamepadPollInterval is the interval between gamepad polls for
+// platforms that don't notify about changes.
+const gamepadPollInterval = time.Second / 120
+
+// gamepadDeadzone is the smallest axis change reported.
+const gamepadDeadzone = 1.0 / 256
+
+// update changes the state of the controller id to s, and appends the
+// events describing the change to events.
+func (g *gamepadState) update(id gamepad.ID, s gamepadState, t time.Duration, events []gamepad.Event) []gamepad.Event {
+	if !s.connected {
+		s = gamepadState{}
+	}
+	if s.connected && !g.connected {
+		events = append(events, gamepad.Event{Kind: gamepad.Connect, ID: id, Name: s.name, Time: t})
+	}
+	for b := gamepad.ButtonA; b <= gamepad.ButtonDPadRight; b++ {
+		mask := uint32(1) << b
+		if s.buttons&mask == g.buttons&mask {
+			continue
+		}
+		kind := gamepad.Release
+		if s.buttons&mask != 0 {
+			kind = gamepad.Press
+		}
+		events = append(events, gamepad.Event{Kind: kind, ID: id, Button: b, Time: t})
+	}
+	for a := range s.axes {
+		v := s.axes[a]
+		if d := v - g.axes[a]; -gamepadDeadzone < d && d < gamepadDeadzone && v != 0 {
+			// Ignore noise, but always report centered axes.
+			s.axes[a] = g.axes[a]
+			continue
+		}
+		if v == g.axes[a] {
+			continue
+		}
+		events = append(events, gamepad.Event{Kind: gamepad.Move, ID: id, Axis: gamepad.Axis(a), Value: v, Time: t})
+	}
+	if !s.connected && g.connected {
+		events = append(events, gamepad.Event{Kind: gamepad.Disconnect, ID: id, Time: t})
+	}
+	*g = s
+	return events
+}
+
+// gamepadPoller polls controllers at gamepadPollInterval, for
+// platforms that don't notify about changes.
+type gamepadPoller struct {
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+// pollGamepads polls n controller slots with poll and delivers the
+// resulting events until closed. Disconnected slots are polled less
+// frequently, because polling them may be expensive.
+func pollGamepads(n int, poll func(slot int) gamepadState, deliver func([]gamepad.Event)) io.Closer {
+	p := &gamepadPoller{done: make(chan struct{})}
+	go func() {
+		states := make([]gamepadState, n)
+		start := time.Now()
+		ticker := time.NewTicker(gamepadPollInterval)
+		defer ticker.Stop()
+		const idlePolls = int(time.Second / gamepadPollInterval)
+		for tick := 0; ; tick++ {
+			var events []gamepad.Event
+			t := time.Since(start)
+			for i := range states {
+				if !states[i].connected && tick%idlePolls != 0 {
+					continue
+				}
+				events = states[i].update(gamepad.ID(i+1), poll(i), t, events)
+			}
+			if len(events) > 0 {
+				deliver(events)
+			}
+			select {
+			case <-ticker.C:
+			case <-p.done:
+				return
+			}
+		}
+	}()
+	return p
+}
+
+func (p *gamepadPoller) Close() error {
+	p.closeOnce.Do(func() {
+		close(p.done)
+	})
+	return nil
+}
+
+// startGamepads starts delivering gamepad events to the window, if the
+// platform supports gamepads and they're not already started.
+func (w *Window) startGamepads() {
+	if w.gamepads != nil {
+		return
+	}
+	w.gamepads = listenGamepads(w.deliverGamepad)
+	if w.gamepads == nil {
+		// Not supported; don't try again.
+		w.gamepads = nopCloser{}
+	}
+}
+
+func (w *Window) closeGamepads() {
+	if w.gamepads != nil {
+		w.gamepads.Close()
+		w.gamepads = nil
+	}
+}
+
+func (w *Window) deliverGamepad(events []gamepad.Event) {
+	w.driverDefer(func(d driver) {
+		for _, e := range events {
+			w.callbacks.Event(e)
+		}
+	})
+}
+
+type nopCloser struct{}
+
+func (nopCloser) Close() error { return nil }
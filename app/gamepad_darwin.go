@@ -0,0 +1,104 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package app
+
+/*
+#cgo CFLAGS: -Werror -xobjective-c -fobjc-arc
+#cgo LDFLAGS: -framework GameController
+
+#include <GameController/GameController.h>
+
+// gamepadState fills buttons in the order of gamepad.Button and
+// axes in the order of gamepad.Axis, and reports whether the
+// controller in slot exists and supports the extended gamepad
+// profile.
+static int gamepadState(int slot, int *buttons, float *axes) {
+	@autoreleasepool {
+		NSArray<GCController *> *pads = [GCController controllers];
+		if (slot >= (int)pads.count) {
+			return 0;
+		}
+		GCExtendedGamepad *g = pads[slot].extendedGamepad;
+		if (g == nil) {
+			return 0;
+		}
+		buttons[0] = g.buttonA.pressed;
+		buttons[1] = g.buttonB.pressed;
+		buttons[2] = g.buttonX.pressed;
+		buttons[3] = g.buttonY.pressed;
+		buttons[4] = g.leftShoulder.pressed;
+		buttons[5] = g.rightShoulder.pressed;
+		if (@available(macOS 10.15, iOS 13, tvOS 13, *)) {
+			buttons[6] = g.buttonOptions.pressed;
+			buttons[7] = g.buttonMenu.pressed;
+		}
+		if (@available(macOS 11, iOS 14, tvOS 14, *)) {
+			buttons[8] = g.buttonHome.pressed;
+		}
+		if (@available(macOS 10.14.1, iOS 12.1, tvOS 12.1, *)) {
+			buttons[9] = g.leftThumbstickButton.pressed;
+			buttons[10] = g.rightThumbstickButton.pressed;
+		}
+		buttons[11] = g.dpad.up.pressed;
+		buttons[12] = g.dpad.down.pressed;
+		buttons[13] = g.dpad.left.pressed;
+		buttons[14] = g.dpad.right.pressed;
+		// GameController sticks point up.
+		axes[0] = g.leftThumbstick.xAxis.value;
+		axes[1] = -g.leftThumbstick.yAxis.value;
+		axes[2] = g.rightThumbstick.xAxis.value;
+		axes[3] = -g.rightThumbstick.yAxis.value;
+		axes[4] = g.leftTrigger.value;
+		axes[5] = g.rightTrigger.value;
+		return 1;
+	}
+}
+
+static CFTypeRef gamepadName(int slot) {
+	@autoreleasepool {
+		NSArray<GCController *> *pads = [GCController controllers];
+		if (slot >= (int)pads.count) {
+			return nil;
+		}
+		return CFBridgingRetain(pads[slot].vendorName);
+	}
+}
+*/
+import "C"
+
+import (
+	"io"
+
+	"github.com/Seikaijyu/gio/io/gamepad"
+)
+
+// maxGameControllers is the number of GameController slots polled.
+const maxGameControllers = 4
+
+func listenGamepads(deliver func([]gamepad.Event)) io.Closer {
+	return pollGamepads(maxGameControllers, pollGameController, deliver)
+}
+
+func pollGameController(slot int) gamepadState {
+	var (
+		buttons [gamepad.ButtonDPadRight + 1]C.int
+		axes    [gamepad.AxisRightTrigger + 1]C.float
+	)
+	if C.gamepadState(C.int(slot), &buttons[0], &axes[0]) == 0 {
+		return gamepadState{}
+	}
+	s := gamepadState{connected: true}
+	if name := C.gamepadName(C.int(slot)); name != 0 {
+		s.name = nsstringToString(name)
+		C.CFRelease(name)
+	}
+	for b, pressed := range buttons {
+		if pressed != 0 {
+			s.buttons |= 1 << b
+		}
+	}
+	for a, v := range axes {
+		s.axes[a] = float32(v)
+	}
+	return s
+}
@@ -0,0 +1,77 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package app
+
+import (
+	"io"
+	"syscall/js"
+
+	"github.com/Seikaijyu/gio/io/gamepad"
+)
+
+// maxWebGamepads is the number of gamepad slots polled. Browsers
+// usually report at most 4.
+const maxWebGamepads = 4
+
+// webButtons maps the buttons of the standard Gamepad API mapping to
+// gamepad buttons. The triggers, buttons 6 and 7, are reported as axes.
+var webButtons = [...]struct {
+	index int
+	btn   gamepad.Button
+}{
+	{0, gamepad.ButtonA},
+	{1, gamepad.ButtonB},
+	{2, gamepad.ButtonX},
+	{3, gamepad.ButtonY},
+	{4, gamepad.ButtonLeftShoulder},
+	{5, gamepad.ButtonRightShoulder},
+	{8, gamepad.ButtonBack},
+	{9, gamepad.ButtonStart},
+	{10, gamepad.ButtonLeftThumb},
+	{11, gamepad.ButtonRightThumb},
+	{12, gamepad.ButtonDPadUp},
+	{13, gamepad.ButtonDPadDown},
+	{14, gamepad.ButtonDPadLeft},
+	{15, gamepad.ButtonDPadRight},
+	{16, gamepad.ButtonGuide},
+}
+
+func listenGamepads(deliver func([]gamepad.Event)) io.Closer {
+	nav := js.Global().Get("navigator")
+	if nav.Get("getGamepads").IsUndefined() {
+		return nil
+	}
+	return pollGamepads(maxWebGamepads, func(slot int) gamepadState {
+		return pollWebGamepad(nav, slot)
+	}, deliver)
+}
+
+func pollWebGamepad(nav js.Value, slot int) gamepadState {
+	pads := nav.Call("getGamepads")
+	if slot >= pads.Length() {
+		return gamepadState{}
+	}
+	pad := pads.Index(slot)
+	if !pad.Truthy() || !pad.Get("connected").Bool() {
+		return gamepadState{}
+	}
+	s := gamepadState{connected: true, name: pad.Get("id").String()}
+	btns := pad.Get("buttons")
+	n := btns.Length()
+	for _, b := range webButtons {
+		if b.index < n && btns.Index(b.index).Get("pressed").Bool() {
+			s.buttons |= 1 << b.btn
+		}
+	}
+	if n > 7 {
+		s.axes[gamepad.AxisLeftTrigger] = float32(btns.Index(6).Get("value").Float())
+		s.axes[gamepad.AxisRightTrigger] = float32(btns.Index(7).Get("value").Float())
+	}
+	axes := pad.Get("axes")
+	for i, a := range []gamepad.Axis{gamepad.AxisLeftX, gamepad.AxisLeftY, gamepad.AxisRightX, gamepad.AxisRightY} {
+		if i < axes.Length() {
+			s.axes[a] = float32(axes.Index(i).Float())
+		}
+	}
+	return s
+}
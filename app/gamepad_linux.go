@@ -0,0 +1,321 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+//go:build linux && !android
+// +build linux,!android
+
+package app
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+	"unsafe"
+
+	"github.com/Seikaijyu/gio/io/gamepad"
+)
+
+// evdevGamepads reads controllers from the Linux evdev devices in
+// /dev/input. Devices are rescanned periodically to detect
+// controllers connected later.
+type evdevGamepads struct {
+	deliver func([]gamepad.Event)
+	done    chan struct{}
+
+	mu      sync.Mutex
+	closed  bool
+	devices map[string]*os.File
+	nextID  gamepad.ID
+}
+
+// evdev constants from linux/input-event-codes.h.
+const (
+	evKey = 0x01
+	evAbs = 0x03
+
+	btnGamepad = 0x130
+	keyMax     = 0x2ff
+
+	absX     = 0x00
+	absY     = 0x01
+	absZ     = 0x02
+	absRX    = 0x03
+	absRY    = 0x04
+	absRZ    = 0x05
+	absHat0X = 0x10
+	absHat0Y = 0x11
+	absMax   = 0x3f
+)
+
+// evdevButtons maps evdev key codes to gamepad buttons.
+var evdevButtons = map[uint16]gamepad.Button{
+	0x130: gamepad.ButtonA,             // BTN_SOUTH
+	0x131: gamepad.ButtonB,             // BTN_EAST
+	0x133: gamepad.ButtonX,             // BTN_X
+	0x134: gamepad.ButtonY,             // BTN_Y
+	0x136: gamepad.ButtonLeftShoulder,  // BTN_TL
+	0x137: gamepad.ButtonRightShoulder, // BTN_TR
+	0x13a: gamepad.ButtonBack,          // BTN_SELECT
+	0x13b: gamepad.ButtonStart,         // BTN_START
+	0x13c: gamepad.ButtonGuide,         // BTN_MODE
+	0x13d: gamepad.ButtonLeftThumb,     // BTN_THUMBL
+	0x13e: gamepad.ButtonRightThumb,    // BTN_THUMBR
+	0x220: gamepad.ButtonDPadUp,        // BTN_DPAD_UP
+	0x221: gamepad.ButtonDPadDown,      // BTN_DPAD_DOWN
+	0x222: gamepad.ButtonDPadLeft,      // BTN_DPAD_LEFT
+	0x223: gamepad.ButtonDPadRight,     // BTN_DPAD_RIGHT
+}
+
+// evdevAxes maps evdev absolute axes to gamepad axes.
+var evdevAxes = map[uint16]gamepad.Axis{
+	absX:  gamepad.AxisLeftX,
+	absY:  gamepad.AxisLeftY,
+	absRX: gamepad.AxisRightX,
+	absRY: gamepad.AxisRightY,
+	absZ:  gamepad.AxisLeftTrigger,
+	absRZ: gamepad.AxisRightTrigger,
+}
+
+// evdevAbsInfo mirrors struct input_absinfo.
+type evdevAbsInfo struct {
+	Value, Minimum, Maximum, Fuzz, Flat, Resolution int32
+}
+
+// gamepadRescanInterval is the interval between scans for new devices.
+const gamepadRescanInterval = 2 * time.Second
+
+func listenGamepads(deliver func([]gamepad.Event)) io.Closer {
+	g := &evdevGamepads{
+		deliver: deliver,
+		done:    make(chan struct{}),
+		devices: make(map[string]*os.File),
+	}
+	go g.run()
+	return g
+}
+
+func (g *evdevGamepads) run() {
+	ticker := time.NewTicker(gamepadRescanInterval)
+	defer ticker.Stop()
+	for {
+		g.scan()
+		select {
+		case <-ticker.C:
+		case <-g.done:
+			return
+		}
+	}
+}
+
+// scan opens the controllers not already open.
+func (g *evdevGamepads) scan() {
+	paths, _ := filepath.Glob("/dev/input/event*")
+	for _, path := range paths {
+		g.mu.Lock()
+		_, open := g.devices[path]
+		g.mu.Unlock()
+		if open {
+			continue
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			// Most likely a device without read permission.
+			continue
+		}
+		if !isEvdevGamepad(f) {
+			f.Close()
+			continue
+		}
+		g.mu.Lock()
+		if g.closed {
+			g.mu.Unlock()
+			f.Close()
+			return
+		}
+		g.nextID++
+		id := g.nextID
+		g.devices[path] = f
+		g.mu.Unlock()
+		go g.read(path, id, f)
+	}
+}
+
+// read delivers the events of the device f until it is closed or
+// disconnected.
+func (g *evdevGamepads) read(path string, id gamepad.ID, f *os.File) {
+	var axes [absMax + 1]evdevAbsInfo
+	for code := range evdevAxes {
+		axes[code] = evdevAbs(f, code)
+	}
+	for _, code := range []uint16{absHat0X, absHat0Y} {
+		axes[code] = evdevAbs(f, code)
+	}
+	start := time.Now()
+	var state, next gamepadState
+	next.connected = true
+	next.name = evdevName(f)
+	for code, a := range evdevAxes {
+		next.axes[a] = normalizeAbs(axes[code], axes[code].Value, a >= gamepad.AxisLeftTrigger)
+	}
+	events := state.update(id, next, time.Since(start), nil)
+	g.deliver(events)
+
+	// struct input_event is a struct timeval followed by the type,
+	// code and value of the event.
+	tvSize := int(unsafe.Sizeof(syscall.Timeval{}))
+	buf := make([]byte, 64*(tvSize+8))
+	for {
+		n, err := f.Read(buf)
+		if err != nil {
+			break
+		}
+		for off := 0; off+tvSize+8 <= n; off += tvSize + 8 {
+			ev := buf[off+tvSize:]
+			typ := binary.LittleEndian.Uint16(ev[0:])
+			code := binary.LittleEndian.Uint16(ev[2:])
+			val := int32(binary.LittleEndian.Uint32(ev[4:]))
+			switch typ {
+			case evKey:
+				if b, ok := evdevButtons[code]; ok {
+					if val != 0 {
+						next.buttons |= 1 << b
+					} else {
+						next.buttons &^= 1 << b
+					}
+				}
+			case evAbs:
+				switch code {
+				case absHat0X:
+					setHat(&next, val, gamepad.ButtonDPadLeft, gamepad.ButtonDPadRight)
+				case absHat0Y:
+					setHat(&next, val, gamepad.ButtonDPadUp, gamepad.ButtonDPadDown)
+				default:
+					if a, ok := evdevAxes[code]; ok {
+						next.axes[a] = normalizeAbs(axes[code], val, a >= gamepad.AxisLeftTrigger)
+					}
+				}
+			}
+		}
+		if events := state.update(id, next, time.Since(start), nil); len(events) > 0 {
+			g.deliver(events)
+		}
+	}
+	g.mu.Lock()
+	closed := g.closed
+	delete(g.devices, path)
+	g.mu.Unlock()
+	f.Close()
+	if !closed {
+		g.deliver(state.update(id, gamepadState{}, time.Since(start), nil))
+	}
+}
+
+func (g *evdevGamepads) Close() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.closed {
+		return nil
+	}
+	g.closed = true
+	close(g.done)
+	for _, f := range g.devices {
+		f.Close()
+	}
+	return nil
+}
+
+// setHat updates the dpad buttons neg and pos from a hat axis value.
+func setHat(s *gamepadState, val int32, neg, pos gamepad.Button) {
+	s.buttons &^= 1<<neg | 1<<pos
+	switch {
+	case val < 0:
+		s.buttons |= 1 << neg
+	case val > 0:
+		s.buttons |= 1 << pos
+	}
+}
+
+// normalizeAbs maps val to [-1, 1], or [0, 1] for triggers. Values
+// within the flat range of the center of a stick map to 0.
+func normalizeAbs(info evdevAbsInfo, val int32, trigger bool) float32 {
+	span := float32(info.Maximum - info.Minimum)
+	if span <= 0 {
+		return 0
+	}
+	v := float32(val-info.Minimum) / span
+	if trigger {
+		return v
+	}
+	if center := (info.Minimum + info.Maximum) / 2; val-center <= info.Flat && center-val <= info.Flat {
+		return 0
+	}
+	return v*2 - 1
+}
+
+// isEvdevGamepad reports whether the device f has gamepad buttons.
+func isEvdevGamepad(f *os.File) bool {
+	var bits [keyMax/8 + 1]byte
+	if err := evdevIoctl(f, evdevIOC(evdevIOCGBIT+evKey, len(bits)), unsafe.Pointer(&bits[0])); err != nil {
+		return false
+	}
+	return bits[btnGamepad/8]&(1<<(btnGamepad%8)) != 0
+}
+
+func evdevName(f *os.File) string {
+	var name [256]byte
+	if err := evdevIoctl(f, evdevIOC(evdevIOCGNAME, len(name)), unsafe.Pointer(&name[0])); err != nil {
+		return ""
+	}
+	if i := bytes.IndexByte(name[:], 0); i >= 0 {
+		return string(name[:i])
+	}
+	return string(name[:])
+}
+
+func evdevAbs(f *os.File, code uint16) evdevAbsInfo {
+	var info evdevAbsInfo
+	req := evdevIOC(evdevIOCGABS+int(code), int(unsafe.Sizeof(info)))
+	evdevIoctl(f, req, unsafe.Pointer(&info))
+	return info
+}
+
+// ioctl request numbers from linux/input.h, before encoding.
+const (
+	evdevIOCGNAME = 0x06
+	evdevIOCGBIT  = 0x20
+	evdevIOCGABS  = 0x40
+)
+
+// evdevIOC encodes the read ioctl request nr of size bytes for evdev
+// devices, as _IOR('E', nr, size).
+func evdevIOC(nr, size int) uintptr {
+	const (
+		iocRead = 2
+		typ     = 'E'
+	)
+	return uintptr(iocRead<<30 | size<<16 | typ<<8 | nr)
+}
+
+func evdevIoctl(f *os.File, req uintptr, arg unsafe.Pointer) error {
+	// Use SyscallConn instead of Fd, because Fd puts the file in
+	// blocking mode which prevents Close from interrupting reads.
+	conn, err := f.SyscallConn()
+	if err != nil {
+		return err
+	}
+	var errno syscall.Errno
+	err = conn.Control(func(fd uintptr) {
+		_, _, errno = syscall.Syscall(syscall.SYS_IOCTL, fd, req, uintptr(arg))
+	})
+	if err != nil {
+		return err
+	}
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
@@ -0,0 +1,16 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+//go:build android || freebsd || openbsd
+// +build android freebsd openbsd
+
+package app
+
+import (
+	"io"
+
+	"github.com/Seikaijyu/gio/io/gamepad"
+)
+
+func listenGamepads(deliver func([]gamepad.Event)) io.Closer {
+	return nil
+}
@@ -0,0 +1,67 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package app
+
+import (
+	"io"
+
+	"github.com/Seikaijyu/gio/app/internal/windows"
+	"github.com/Seikaijyu/gio/io/gamepad"
+)
+
+// xinputButtons maps XInput buttons to gamepad buttons.
+var xinputButtons = [...]struct {
+	mask uint16
+	btn  gamepad.Button
+}{
+	{windows.XINPUT_GAMEPAD_A, gamepad.ButtonA},
+	{windows.XINPUT_GAMEPAD_B, gamepad.ButtonB},
+	{windows.XINPUT_GAMEPAD_X, gamepad.ButtonX},
+	{windows.XINPUT_GAMEPAD_Y, gamepad.ButtonY},
+	{windows.XINPUT_GAMEPAD_LEFT_SHOULDER, gamepad.ButtonLeftShoulder},
+	{windows.XINPUT_GAMEPAD_RIGHT_SHOULDER, gamepad.ButtonRightShoulder},
+	{windows.XINPUT_GAMEPAD_BACK, gamepad.ButtonBack},
+	{windows.XINPUT_GAMEPAD_START, gamepad.ButtonStart},
+	{windows.XINPUT_GAMEPAD_LEFT_THUMB, gamepad.ButtonLeftThumb},
+	{windows.XINPUT_GAMEPAD_RIGHT_THUMB, gamepad.ButtonRightThumb},
+	{windows.XINPUT_GAMEPAD_DPAD_UP, gamepad.ButtonDPadUp},
+	{windows.XINPUT_GAMEPAD_DPAD_DOWN, gamepad.ButtonDPadDown},
+	{windows.XINPUT_GAMEPAD_DPAD_LEFT, gamepad.ButtonDPadLeft},
+	{windows.XINPUT_GAMEPAD_DPAD_RIGHT, gamepad.ButtonDPadRight},
+}
+
+func listenGamepads(deliver func([]gamepad.Event)) io.Closer {
+	if !windows.XInputAvailable() {
+		return nil
+	}
+	return pollGamepads(windows.XUSER_MAX_COUNT, pollXInput, deliver)
+}
+
+func pollXInput(user int) gamepadState {
+	var xs windows.XInputState
+	if !windows.XInputGetState(uint32(user), &xs) {
+		return gamepadState{}
+	}
+	pad := xs.Gamepad
+	s := gamepadState{connected: true, name: "XInput Controller"}
+	for _, b := range xinputButtons {
+		if pad.Buttons&b.mask != 0 {
+			s.buttons |= 1 << b.btn
+		}
+	}
+	// XInput sticks point up; gamepad axes point down.
+	s.axes[gamepad.AxisLeftX] = xinputThumb(pad.ThumbLX)
+	s.axes[gamepad.AxisLeftY] = -xinputThumb(pad.ThumbLY)
+	s.axes[gamepad.AxisRightX] = xinputThumb(pad.ThumbRX)
+	s.axes[gamepad.AxisRightY] = -xinputThumb(pad.ThumbRY)
+	s.axes[gamepad.AxisLeftTrigger] = float32(pad.LeftTrigger) / 255
+	s.axes[gamepad.AxisRightTrigger] = float32(pad.RightTrigger) / 255
+	return s
+}
+
+func xinputThumb(v int16) float32 {
+	if v < 0 {
+		return float32(v) / 32768
+	}
+	return float32(v) / 32767
+}
@@ -0,0 +1,170 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package app
+
+import (
+	"image"
+	"sync"
+
+	"github.com/Seikaijyu/gio/unit"
+)
+
+// WindowGeometry is the placement of a window on the desktop, as
+// saved and restored by a Geometry.
+type WindowGeometry struct {
+	// Width and Height are the size of the window when Windowed, in Dp
+	// so that it is restored at the same size on monitors of other
+	// densities.
+	Width, Height unit.Dp
+	// Position is the position of the window when Windowed. See
+	// Config.Position.
+	Position image.Point
+	// Mode is the window mode. Minimized windows are saved with the
+	// mode they had before they were minimized.
+	Mode WindowMode
+	// Monitor is the bounds of the monitor the window was on, or empty
+	// if the platform doesn't report monitors.
+	Monitor image.Rectangle
+}
+
+// GeometryStore loads and saves window geometries by name. Settings
+// implements GeometryStore, and Settings.Geometry returns a Geometry
+// stored in the settings.
+type GeometryStore interface {
+	// LoadGeometry returns the geometry saved under name, and whether
+	// there was one.
+	LoadGeometry(name string) (WindowGeometry, bool)
+	// SaveGeometry saves g under name.
+	SaveGeometry(name string, g WindowGeometry) error
+}
+
+// Geometry persists the geometry of a window across launches. Pass
+// the result of Options to NewWindow, and call Update with every
+// ConfigEvent of the window:
+//
+//	g := app.NewGeometry(settings, "main")
+//	w := app.NewWindow(g.Options()...)
+//	for {
+//		switch e := w.NextEvent().(type) {
+//		case app.ConfigEvent:
+//			g.Update(e.Config)
+//		...
+//
+// Restoring a position is supported on Windows, macOS and X11. If the
+// monitor the window was on is gone or has changed, the window is
+// centered on the nearest monitor instead. Geometry is safe for
+// concurrent use.
+type Geometry struct {
+	store GeometryStore
+	name  string
+
+	mu    sync.Mutex
+	geom  WindowGeometry
+	valid bool
+}
+
+// NewGeometry returns a Geometry for the window geometry saved in
+// store under name.
+func NewGeometry(store GeometryStore, name string) *Geometry {
+	g := &Geometry{store: store, name: name}
+	g.geom, g.valid = store.LoadGeometry(name)
+	return g
+}
+
+// Options returns the options that restore the saved geometry, if any.
+func (g *Geometry) Options() []Option {
+	g.mu.Lock()
+	geom, valid := g.geom, g.valid
+	g.mu.Unlock()
+	if !valid {
+		return nil
+	}
+	var opts []Option
+	if geom.Width > 0 && geom.Height > 0 {
+		opts = append(opts, Size(geom.Width, geom.Height))
+	}
+	opts = append(opts, func(_ unit.Metric, cnf *Config) {
+		cnf.Position = geom.Position
+		cnf.positionMonitor = geom.Monitor
+	})
+	if geom.Mode != Windowed && geom.Mode != Minimized {
+		opts = append(opts, geom.Mode.Option())
+	}
+	return opts
+}
+
+// Update saves the geometry of the window from its configuration,
+// if it changed. The size and position are only saved for Windowed
+// windows, so restoring a maximized window doesn't lose its windowed
+// placement. The size is saved once the window has drawn a frame, when
+// its density is known.
+func (g *Geometry) Update(cnf Config) error {
+	g.mu.Lock()
+	geom := g.geom
+	switch cnf.Mode {
+	case Minimized:
+		g.mu.Unlock()
+		return nil
+	case Windowed:
+		if m := cnf.metric; cnf.Size.X > 0 && cnf.Size.Y > 0 && m.PxPerDp > 0 {
+			geom.Width = unit.Dp(float32(cnf.Size.X) / m.PxPerDp)
+			geom.Height = unit.Dp(float32(cnf.Size.Y) / m.PxPerDp)
+		}
+		geom.Position = cnf.Position
+	}
+	geom.Mode = cnf.Mode
+	geom.Monitor = cnf.monitor
+	if g.valid && geom == g.geom {
+		g.mu.Unlock()
+		return nil
+	}
+	g.geom, g.valid = geom, true
+	g.mu.Unlock()
+	return g.store.SaveGeometry(g.name, geom)
+}
+
+// Position moves the window to the position p, in the coordinates of
+// Config.Position. It is ignored on platforms that don't support
+// positioning windows.
+func Position(p image.Point) Option {
+	return func(_ unit.Metric, cnf *Config) {
+		cnf.Position = p
+		cnf.positionMonitor = image.Rectangle{}
+	}
+}
+
+// fitToMonitor returns the position of the window frame r, moved to
+// be visible. bounds and work are the bounds and work area of the
+// monitor that contains the center of r or, if found is false, of the
+// nearest monitor. saved is the bounds of the monitor the position was
+// saved on, if known.
+//
+// A window whose monitor is gone or has changed is centered in the
+// work area; otherwise it is moved just enough to be inside the work
+// area.
+func fitToMonitor(r, saved, bounds, work image.Rectangle, found bool) image.Point {
+	if !found || (!saved.Empty() && saved != bounds) {
+		p := work.Min.Add(work.Size().Sub(r.Size()).Div(2))
+		if p.X < work.Min.X {
+			p.X = work.Min.X
+		}
+		if p.Y < work.Min.Y {
+			p.Y = work.Min.Y
+		}
+		return p
+	}
+	p := r.Min
+	if p.X+r.Dx() > work.Max.X {
+		p.X = work.Max.X - r.Dx()
+	}
+	if p.Y+r.Dy() > work.Max.Y {
+		p.Y = work.Max.Y - r.Dy()
+	}
+	if p.X < work.Min.X {
+		p.X = work.Min.X
+	}
+	if p.Y < work.Min.Y {
+		p.Y = work.Min.Y
+	}
+	return p
+}
@@ -0,0 +1,75 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package app
+
+import (
+	"image"
+	"testing"
+
+	"github.com/Seikaijyu/gio/unit"
+)
+
+type memGeometryStore map[string]WindowGeometry
+
+func (m memGeometryStore) LoadGeometry(name string) (WindowGeometry, bool) {
+	g, ok := m[name]
+	return g, ok
+}
+
+func (m memGeometryStore) SaveGeometry(name string, g WindowGeometry) error {
+	m[name] = g
+	return nil
+}
+
+func TestGeometry(t *testing.T) {
+	store := make(memGeometryStore)
+	g := NewGeometry(store, "main")
+	if opts := g.Options(); len(opts) != 0 {
+		t.Errorf("%d options without a saved geometry", len(opts))
+	}
+	monitor := image.Rect(0, 0, 1920, 1080)
+	// The size is unknown in Dp before the first frame.
+	g.Update(Config{Size: image.Pt(900, 700), Position: image.Pt(100, 50), monitor: monitor})
+	if got := store["main"]; got.Width != 0 || got.Height != 0 {
+		t.Errorf("saved size %vx%v before the first frame", got.Width, got.Height)
+	}
+	m := unit.Metric{PxPerDp: 2}
+	g.Update(Config{Size: image.Pt(800, 600), Position: image.Pt(100, 50), monitor: monitor, metric: m})
+	// Maximizing and minimizing keep the windowed placement.
+	g.Update(Config{Mode: Maximized, Size: image.Pt(1920, 1040), Position: image.Pt(-8, -8), monitor: monitor, metric: m})
+	g.Update(Config{Mode: Minimized, metric: m})
+	want := WindowGeometry{Width: 400, Height: 300, Position: image.Pt(100, 50), Mode: Maximized, Monitor: monitor}
+	if got := store["main"]; got != want {
+		t.Errorf("saved %+v, want %+v", got, want)
+	}
+
+	// The size is restored in Dp on a monitor of another density.
+	var cnf Config
+	cnf.apply(unit.Metric{PxPerDp: 1}, NewGeometry(store, "main").Options())
+	if cnf.Size != image.Pt(400, 300) || cnf.Position != want.Position || cnf.Mode != Maximized || cnf.positionMonitor != monitor {
+		t.Errorf("restored %+v", cnf)
+	}
+}
+
+func TestFitToMonitor(t *testing.T) {
+	bounds := image.Rect(0, 0, 1000, 800)
+	work := image.Rect(0, 0, 1000, 760)
+	tests := []struct {
+		name  string
+		r     image.Rectangle
+		saved image.Rectangle
+		found bool
+		want  image.Point
+	}{
+		{"inside", image.Rect(100, 100, 400, 300), bounds, true, image.Pt(100, 100)},
+		{"overlapping", image.Rect(900, 700, 1200, 900), bounds, true, image.Pt(700, 560)},
+		{"unknown monitor", image.Rect(100, 100, 400, 300), image.Rectangle{}, true, image.Pt(100, 100)},
+		{"monitor changed", image.Rect(100, 100, 400, 300), image.Rect(0, 0, 1920, 1080), true, image.Pt(350, 280)},
+		{"monitor gone", image.Rect(2000, 100, 2300, 300), image.Rect(1000, 0, 2920, 1080), false, image.Pt(350, 280)},
+	}
+	for _, test := range tests {
+		if got := fitToMonitor(test.r, test.saved, bounds, work, test.found); got != test.want {
+			t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,15 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+//go:build !windows && (!darwin || ios)
+// +build !windows
+// +build !darwin ios
+
+package app
+
+import "github.com/Seikaijyu/gio/io/system"
+
+// gestureSettings returns no settings, leaving the defaults of package
+// gesture.
+func gestureSettings() system.Gestures {
+	return system.Gestures{}
+}
@@ -4,6 +4,7 @@ package app
 
 import (
 	"errors"
+	"fmt"
 	"syscall/js"
 
 	"github.com/Seikaijyu/gio/gpu"
@@ -22,10 +23,19 @@ func newContext(w *window) (*glContext, error) {
 		// See https://developers.google.com/web/updates/2019/05/desynchronized.
 		"desynchronized":        true,
 		"preserveDrawingBuffer": true,
+		"alpha":                 !w.canvasOpts.Opaque,
 	}
-	ctx := w.cnv.Call("getContext", "webgl2", args)
-	if ctx.IsNull() {
-		ctx = w.cnv.Call("getContext", "webgl", args)
+	var ctx js.Value
+	switch typ := w.canvasOpts.Context; typ {
+	case "":
+		ctx = w.cnv.Call("getContext", "webgl2", args)
+		if ctx.IsNull() {
+			ctx = w.cnv.Call("getContext", "webgl", args)
+		}
+	case "webgl", "webgl2":
+		ctx = w.cnv.Call("getContext", typ, args)
+	default:
+		return nil, fmt.Errorf("app: unsupported canvas context type %q", typ)
 	}
 	if ctx.IsNull() {
 		return nil, errors.New("app: webgl is not supported")
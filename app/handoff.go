@@ -0,0 +1,145 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package app
+
+import (
+	"sync"
+
+	"github.com/Seikaijyu/gio/io/router"
+	"github.com/Seikaijyu/gio/io/system"
+	"github.com/Seikaijyu/gio/op"
+)
+
+// FrameHandoff hands frames built on a worker goroutine to a Window,
+// so that heavy layouts don't block the event processing of the
+// window. The event loop of the window passes every FrameEvent to
+// Frame, which presents the most recent frame submitted by the worker
+// without waiting for it:
+//
+//	h := app.NewFrameHandoff(w)
+//	go func() {
+//		for {
+//			e, ops, ok := h.Next()
+//			if !ok {
+//				return
+//			}
+//			gtx := layout.NewContext(ops, e)
+//			// Lay out into gtx.
+//			...
+//			h.Submit(ops)
+//		}
+//	}()
+//	for {
+//		switch e := w.NextEvent().(type) {
+//		case system.FrameEvent:
+//			h.Frame(e)
+//		case system.DestroyEvent:
+//			h.Close()
+//			return
+//		}
+//	}
+//
+// The op lists are double buffered: the worker builds the next frame
+// while the window keeps the current one. The FrameEvents given to the
+// worker have a Queue of the events received by the time of the
+// request, detached from the window so the worker may use it
+// concurrently. The frames of the worker lag at least a frame behind
+// the window.
+type FrameHandoff struct {
+	w *Window
+
+	mu   sync.Mutex
+	cond sync.Cond
+	bufs [2]op.Ops
+	// shown is the index of the buffer of the window.
+	shown int
+	// ready is set when the other buffer holds a submitted frame not
+	// yet shown.
+	ready bool
+	// building is set while the worker builds into the other buffer.
+	building bool
+	// req is the pending request for a frame, and reqEvents its
+	// events.
+	req       *system.FrameEvent
+	reqEvents *router.EventSnapshot
+	closed    bool
+}
+
+// NewFrameHandoff returns a FrameHandoff for frames of w.
+func NewFrameHandoff(w *Window) *FrameHandoff {
+	h := &FrameHandoff{w: w}
+	h.cond.L = &h.mu
+	return h
+}
+
+// Frame completes e with the most recent frame submitted, and requests
+// the worker to build the next. It must be called from the event loop
+// of the window for every FrameEvent.
+func (h *FrameHandoff) Frame(e system.FrameEvent) {
+	events := new(router.EventSnapshot)
+	if q, ok := e.Queue.(*queue); ok {
+		events = q.q.TakeEvents()
+	}
+	h.mu.Lock()
+	if h.ready {
+		h.shown, h.ready = 1-h.shown, false
+	}
+	frame := &h.bufs[h.shown]
+	req := e
+	req.Frame = nil
+	// Keep the events of a request the worker didn't take yet.
+	if h.reqEvents != nil {
+		h.reqEvents.Merge(events)
+		events = h.reqEvents
+	}
+	req.Queue = events
+	h.req, h.reqEvents = &req, events
+	h.cond.Broadcast()
+	h.mu.Unlock()
+	// The worker doesn't touch the shown buffer.
+	e.Frame(frame)
+}
+
+// Next waits for a frame request and returns its FrameEvent, and the
+// reset op list to build the frame into. It returns false after Close.
+// The FrameEvent has no Frame function; use Submit instead.
+func (h *FrameHandoff) Next() (system.FrameEvent, *op.Ops, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	// Wait for the window to show the previously submitted frame, to
+	// pace the worker.
+	for !h.closed && (h.req == nil || h.ready) {
+		h.cond.Wait()
+	}
+	if h.closed {
+		return system.FrameEvent{}, nil, false
+	}
+	e := *h.req
+	h.req, h.reqEvents = nil, nil
+	h.building = true
+	ops := &h.bufs[1-h.shown]
+	ops.Reset()
+	return e, ops, true
+}
+
+// Submit hands the frame built into ops to the window, and requests a
+// FrameEvent to present it.
+func (h *FrameHandoff) Submit(ops *op.Ops) {
+	h.mu.Lock()
+	if !h.building || ops != &h.bufs[1-h.shown] {
+		h.mu.Unlock()
+		panic("app: Submit of op list not returned by Next")
+	}
+	h.building, h.ready = false, true
+	h.mu.Unlock()
+	h.w.Invalidate()
+}
+
+// Close ends the handoff, such as when the window is destroyed. Next
+// returns false after Close.
+func (h *FrameHandoff) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	h.cond.Broadcast()
+}
@@ -0,0 +1,94 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package app
+
+import (
+	"image"
+	"testing"
+
+	"github.com/Seikaijyu/gio/f32"
+	"github.com/Seikaijyu/gio/io/pointer"
+	"github.com/Seikaijyu/gio/io/system"
+	"github.com/Seikaijyu/gio/op"
+	"github.com/Seikaijyu/gio/op/clip"
+)
+
+func TestFrameHandoff(t *testing.T) {
+	w := &Window{redraws: make(chan struct{}, 1)}
+	h := NewFrameHandoff(w)
+	q := new(queue)
+	tag := new(int)
+	var ops op.Ops
+	cl := clip.Rect(image.Rect(0, 0, 100, 100)).Push(&ops)
+	pointer.InputOp{Tag: tag, Kinds: pointer.Press}.Add(&ops)
+	cl.Pop()
+	q.q.Frame(&ops)
+	press := func() {
+		q.q.Queue(pointer.Event{Kind: pointer.Press, Source: pointer.Mouse, Buttons: pointer.ButtonPrimary, Position: f32.Pt(50, 50)})
+	}
+	var shown *op.Ops
+	frame := func() {
+		h.Frame(system.FrameEvent{Queue: q, Frame: func(o *op.Ops) { shown = o }})
+	}
+
+	press()
+	frame()
+	first := shown
+	press()
+	// The events of a request not yet taken by the worker are kept.
+	frame()
+	if shown != first {
+		t.Fatal("the window changed frames without a submitted frame")
+	}
+	e, built, ok := h.Next()
+	if !ok {
+		t.Fatal("Next failed before Close")
+	}
+	if e.Frame != nil {
+		t.Error("the FrameEvent of the worker has a Frame function")
+	}
+	if built == shown {
+		t.Fatal("the worker builds into the op list of the window")
+	}
+	// The events are moved to the worker, and not delivered twice.
+	n := 0
+	for _, e := range e.Queue.Events(tag) {
+		if e, ok := e.(pointer.Event); ok && e.Kind == pointer.Press {
+			n++
+		}
+	}
+	if n != 2 {
+		t.Errorf("the worker received %d presses, want 2", n)
+	}
+	if evts := e.Queue.Events(tag); len(evts) > 0 {
+		t.Errorf("the worker received %v twice", evts)
+	}
+	if evts := q.q.Events(tag); len(evts) > 0 {
+		t.Errorf("the window kept %v after the handoff", evts)
+	}
+
+	h.Submit(built)
+	select {
+	case <-w.redraws:
+	default:
+		t.Error("Submit didn't request a frame")
+	}
+	frame()
+	if shown != built {
+		t.Error("the window didn't show the submitted frame")
+	}
+
+	// Next waits for a request, and fails after Close.
+	if _, _, ok := h.Next(); !ok {
+		t.Fatal("Next failed before Close")
+	}
+	done := make(chan bool)
+	go func() {
+		_, _, ok := h.Next()
+		done <- ok
+	}()
+	h.Close()
+	if <-done {
+		t.Error("Next succeeded after Close")
+	}
+}
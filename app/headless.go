@@ -0,0 +1,300 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package app
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/Seikaijyu/gio/gpu/headless"
+	"github.com/Seikaijyu/gio/internal/ops"
+	"github.com/Seikaijyu/gio/io/clipboard"
+	"github.com/Seikaijyu/gio/io/key"
+	"github.com/Seikaijyu/gio/io/pointer"
+	"github.com/Seikaijyu/gio/io/router"
+	"github.com/Seikaijyu/gio/io/system"
+	"github.com/Seikaijyu/gio/layout"
+	"github.com/Seikaijyu/gio/op"
+	"github.com/Seikaijyu/gio/op/clip"
+	"github.com/Seikaijyu/gio/op/paint"
+	"github.com/Seikaijyu/gio/unit"
+)
+
+// headlessWindow is a window driver that runs without a display
+// server. Frames are rendered to an offscreen buffer.
+type headlessWindow struct {
+	w        *callbacks
+	interval time.Duration
+	config   Config
+	wakeups  chan struct{}
+	// device is the simulated device.
+	device layout.Device
+
+	animating bool
+	closed    bool
+	clipboard string
+	primary   string
+	// content is the clipboard in formats other than text.
+	content []clipboard.Content
+
+	// mu protects the fields below, which are accessed by
+	// Window.Screenshot.
+	mu sync.Mutex
+	// target is the offscreen buffer of the most recent frame.
+	target *headless.Window
+	// err is the error from creating or rendering to target.
+	err error
+	ops op.Ops
+}
+
+// defaultHeadlessInterval is the frame interval of headless windows
+// if none is specified.
+const defaultHeadlessInterval = time.Second / 60
+
+// errNoHeadlessFrame is returned by Screenshot before the first frame.
+var errNoHeadlessFrame = errors.New("app: no frame rendered")
+
+// Headless runs the window without a display server, for example
+// for integration tests in CI. FrameEvents are generated every
+// interval while the window is animating, or every 1/60 second if
+// interval is zero.
+//
+// Frames are rendered to an offscreen buffer, and Screenshot returns
+// its content. The GIO_HEADLESS environment variable runs every
+// window headless, with an optional interval such as "10ms".
+//
+// Headless must be given to NewWindow; it is ignored by Window.Option.
+func Headless(interval time.Duration) Option {
+	if interval <= 0 {
+		interval = defaultHeadlessInterval
+	}
+	return func(_ unit.Metric, cnf *Config) {
+		cnf.headless = interval
+	}
+}
+
+// Simulate makes a headless window simulate the scale, insets, display
+// cutouts and locale of device, for exercising adaptive layouts across
+// device profiles. Frames of the window have the insets of the device
+// and its cutouts, and screenshots show the cutouts in black.
+//
+// Simulate is ignored by windows that are not headless.
+func Simulate(device layout.Device) Option {
+	return func(_ unit.Metric, cnf *Config) {
+		cnf.device = &device
+	}
+}
+
+// headlessEnv returns the Headless option requested by the
+// GIO_HEADLESS environment variable, or nil.
+func headlessEnv() Option {
+	v := os.Getenv("GIO_HEADLESS")
+	if v == "" || v == "0" {
+		return nil
+	}
+	d, _ := time.ParseDuration(v)
+	return Headless(d)
+}
+
+// headlessInterval returns the frame interval given to Headless, or 0.
+func headlessInterval(options []Option) time.Duration {
+	var cnf Config
+	cnf.apply(unit.Metric{}, options)
+	return cnf.headless
+}
+
+func newHeadlessWindow(win *callbacks, options []Option) (*headlessWindow, error) {
+	if embedParent(options) != 0 {
+		return nil, errEmbedUnsupported
+	}
+	w := &headlessWindow{
+		w:        win,
+		interval: headlessInterval(options),
+		wakeups:  make(chan struct{}, 1),
+	}
+	go w.run(options)
+	return w, nil
+}
+
+func (w *headlessWindow) run(options []Option) {
+	w.w.SetDriver(w)
+	w.Configure(options)
+	w.w.Event(system.StageEvent{Stage: system.StageRunning})
+	w.draw(true)
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for !w.closed {
+		select {
+		case <-w.wakeups:
+			w.w.Event(wakeupEvent{})
+		case <-ticker.C:
+			if w.animating {
+				w.draw(false)
+			}
+		}
+	}
+	w.w.Event(system.DestroyEvent{})
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.target != nil {
+		w.target.Release()
+		w.target = nil
+	}
+}
+
+func (w *headlessWindow) draw(sync bool) {
+	if w.config.Size.X == 0 || w.config.Size.Y == 0 {
+		return
+	}
+	w.w.Event(frameEvent{
+		FrameEvent: w.device.Frame(time.Now(), w.config.Size),
+		Sync:       sync,
+	})
+}
+
+// render the frame to the offscreen buffer. It is called before the
+// frame is released to the client.
+func (w *headlessWindow) render(frame *op.Ops, size image.Point) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.target != nil && w.target.Size() != size {
+		w.target.Release()
+		w.target = nil
+	}
+	if w.target == nil {
+		w.target, w.err = headless.NewWindow(size.X, size.Y)
+		if w.err != nil {
+			return
+		}
+	}
+	// Clear to white like regular windows.
+	w.ops.Reset()
+	paint.ColorOp{Color: color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}}.Add(&w.ops)
+	paint.PaintOp{}.Add(&w.ops)
+	ops.AddCall(&w.ops.Internal, &frame.Internal, ops.PC{}, ops.PCFor(&frame.Internal))
+	for _, c := range w.device.Cutouts {
+		paint.FillShape(&w.ops, color.NRGBA{A: 0xff}, clip.Rect(c).Op())
+	}
+	w.err = w.target.Frame(&w.ops)
+}
+
+func (w *headlessWindow) screenshot() (*image.RGBA, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err != nil {
+		return nil, w.err
+	}
+	if w.target == nil {
+		return nil, errNoHeadlessFrame
+	}
+	img := image.NewRGBA(image.Rectangle{Max: w.target.Size()})
+	if err := w.target.Screenshot(img); err != nil {
+		return nil, err
+	}
+	return img, nil
+}
+
+// Screenshot returns the content of the most recent frame of a window
+// created with the Headless option. It returns an error for other
+// windows, and for headless windows if no GPU API is available for
+// offscreen rendering.
+func (w *Window) Screenshot() (*image.RGBA, error) {
+	if w.headless == nil {
+		return nil, errors.New("app: Screenshot of a window that is not headless")
+	}
+	return w.headless.screenshot()
+}
+
+func (w *headlessWindow) SetAnimating(anim bool) {
+	w.animating = anim
+}
+
+func (w *headlessWindow) ShowTextInput(show bool) {}
+
+func (w *headlessWindow) SetInputHint(mode key.InputHint) {}
+
+// NewContext is not supported; headless windows render with their own
+// offscreen context.
+func (w *headlessWindow) NewContext() (context, error) {
+	return nil, errors.New("app: headless windows have no GPU context")
+}
+
+func (w *headlessWindow) ReadClipboard() {
+	w.w.Event(clipboard.Event{Text: w.clipboard})
+}
+
+func (w *headlessWindow) WriteClipboard(s string) {
+	w.clipboard = s
+	w.content = nil
+}
+
+func (w *headlessWindow) ReadClipboardContent(types []string) {
+	var content []clipboard.Content
+	for _, t := range types {
+		for _, c := range w.content {
+			if c.Type == t {
+				content = append(content, c)
+			}
+		}
+	}
+	w.w.Event(clipboard.Event{Text: w.clipboard, Content: content})
+}
+
+func (w *headlessWindow) WriteClipboardContent(s string, content []clipboard.Content) {
+	w.clipboard = s
+	w.content = append([]clipboard.Content(nil), content...)
+}
+
+func (w *headlessWindow) ReadPrimary() {
+	w.w.Event(clipboard.Event{Text: w.primary, Primary: true})
+}
+
+func (w *headlessWindow) WritePrimary(s string) {
+	w.primary = s
+}
+
+func (w *headlessWindow) Configure(options []Option) {
+	prev := w.config
+	cnf := w.config
+	// Apply the options with the metric of the simulated device, which
+	// they may change.
+	cnf.apply(unit.Metric{}, options)
+	if cnf.device != nil {
+		w.device = *cnf.device
+	}
+	cnf = w.config
+	cnf.apply(w.device.Metric(), options)
+	// There are no decorations to draw.
+	cnf.Decorated = true
+	w.config = cnf
+	w.w.Event(ConfigEvent{Config: w.config})
+	if (prev.Size != cnf.Size || prev.device != cnf.device) && prev.Size != (image.Point{}) {
+		w.draw(true)
+	}
+}
+
+func (w *headlessWindow) SetCursor(cursor pointer.Cursor) {}
+
+func (w *headlessWindow) SetImageCursor(cursor *pointer.ImageCursor) {}
+
+func (w *headlessWindow) SetNativeViews(views []router.NativeView) {}
+
+func (w *headlessWindow) Wakeup() {
+	select {
+	case w.wakeups <- struct{}{}:
+	default:
+	}
+}
+
+func (w *headlessWindow) Perform(acts system.Action) {
+	if acts&system.ActionClose != 0 {
+		w.closed = true
+		w.Wakeup()
+	}
+}
+
+func (w *headlessWindow) EditorStateChanged(old, new editorState) {}
@@ -0,0 +1,63 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package app
+
+import (
+	"image"
+	"image/color"
+	"testing"
+	"time"
+
+	"github.com/Seikaijyu/gio/io/system"
+	"github.com/Seikaijyu/gio/op"
+	"github.com/Seikaijyu/gio/op/paint"
+)
+
+func TestHeadlessWindow(t *testing.T) {
+	w := NewWindow(Headless(time.Millisecond), Size(20, 10))
+	colors := []color.NRGBA{
+		{R: 0xff, A: 0xff},
+		{G: 0xff, A: 0xff},
+		{B: 0xff, A: 0xff},
+	}
+	var ops op.Ops
+	frames := 0
+	for {
+		switch e := w.NextEvent().(type) {
+		case system.FrameEvent:
+			if e.Size != image.Pt(20, 10) {
+				t.Errorf("frame size %v, want (20,10)", e.Size)
+			}
+			ops.Reset()
+			c := colors[frames]
+			paint.Fill(&ops, c)
+			if frames < len(colors)-1 {
+				op.InvalidateOp{}.Add(&ops)
+			}
+			e.Frame(&ops)
+			frames++
+			img, err := w.Screenshot()
+			if err != nil {
+				w.Perform(system.ActionClose)
+				t.Skipf("failed to render headless frame, skipping: %v", err)
+			}
+			if got := img.Bounds().Size(); got != e.Size {
+				t.Errorf("frame %d: screenshot size %v, want %v", frames, got, e.Size)
+			}
+			if got, want := img.RGBAAt(5, 5), (color.RGBA{R: c.R, G: c.G, B: c.B, A: c.A}); got != want {
+				t.Errorf("frame %d: screenshot color %v, want %v", frames, got, want)
+			}
+			if frames == len(colors) {
+				w.Perform(system.ActionClose)
+			}
+		case system.DestroyEvent:
+			if e.Err != nil {
+				t.Fatal(e.Err)
+			}
+			if frames != len(colors) {
+				t.Errorf("got %d frames, want %d", frames, len(colors))
+			}
+			return
+		}
+	}
+}
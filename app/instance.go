@@ -0,0 +1,93 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+
+	"github.com/Seikaijyu/gio/io/system"
+	"github.com/Seikaijyu/gio/unit"
+)
+
+// InstanceEvent is sent to a window configured with SingleInstance
+// when another instance of the program is launched with the same
+// instance id. The window is raised before the event is delivered.
+type InstanceEvent struct {
+	// Args are the command line arguments of the new instance,
+	// excluding the program name. URLs passed by the platform
+	// when launching the program are included as arguments.
+	Args []string
+}
+
+// ErrAlreadyRunning is reported by the DestroyEvent of a window
+// configured with SingleInstance when another instance is already
+// running. The command line arguments are forwarded to the running
+// instance before the error is reported.
+var ErrAlreadyRunning = errors.New("app: another instance is already running")
+
+// SingleInstance ensures only one instance of the program with the
+// given id is running at a time. The first instance receives an
+// InstanceEvent for every subsequent launch, whose window is
+// destroyed with ErrAlreadyRunning.
+//
+// Instances communicate through a named pipe on Windows and a Unix
+// domain socket elsewhere, in $XDG_RUNTIME_DIR or else a directory
+// private to the user in the temporary directory. On macOS, the new instance also activates
+// the running instance, and URLs opened with the running application
+// are delivered as InstanceEvents. On Android and iOS the operating system
+// already enforces a single instance, and the option is ignored in
+// browsers.
+//
+// SingleInstance only has an effect when the window is created.
+func SingleInstance(id string) Option {
+	if id == "" {
+		panic("empty instance id")
+	}
+	return func(_ unit.Metric, cnf *Config) {
+		cnf.instanceID = id
+	}
+}
+
+// claimInstance attempts to become the primary instance for id. If
+// another instance is running, the program arguments are forwarded
+// to it and ErrAlreadyRunning is returned.
+func (w *Window) claimInstance(id string) error {
+	l, err := listenInstance(id, w.deliverInstance)
+	if err != nil {
+		return err
+	}
+	if l == nil {
+		if err := forwardInstance(id, os.Args[1:]); err != nil {
+			return err
+		}
+		return ErrAlreadyRunning
+	}
+	w.instance = l
+	return nil
+}
+
+func (w *Window) deliverInstance(args []string) {
+	w.driverDefer(func(d driver) {
+		d.Perform(system.ActionRaise)
+		w.callbacks.Event(InstanceEvent{Args: args})
+	})
+}
+
+// readInstanceArgs decodes arguments written by writeInstanceArgs.
+func readInstanceArgs(r io.Reader) ([]string, error) {
+	var args []string
+	err := json.NewDecoder(r).Decode(&args)
+	return args, err
+}
+
+func writeInstanceArgs(w io.Writer, args []string) error {
+	if args == nil {
+		args = []string{}
+	}
+	return json.NewEncoder(w).Encode(args)
+}
+
+func (InstanceEvent) ImplementsEvent() {}
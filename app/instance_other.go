@@ -0,0 +1,24 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+//go:build android || ios || js
+// +build android ios js
+
+package app
+
+import "io"
+
+type nopInstance struct{}
+
+// listenInstance is a no-op, because the platform runs at most
+// one instance of a program.
+func listenInstance(id string, deliver func(args []string)) (io.Closer, error) {
+	return nopInstance{}, nil
+}
+
+func forwardInstance(id string, args []string) error {
+	return nil
+}
+
+func (nopInstance) Close() error {
+	return nil
+}
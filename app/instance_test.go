@@ -0,0 +1,43 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package app
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestInstanceArgs(t *testing.T) {
+	tests := [][]string{
+		nil,
+		{},
+		{"file.txt"},
+		{"--flag", "two words", "line\nbreak", "gio://open?x=1", "日本語"},
+	}
+	for _, args := range tests {
+		var buf bytes.Buffer
+		if err := writeInstanceArgs(&buf, args); err != nil {
+			t.Fatal(err)
+		}
+		got, err := readInstanceArgs(&buf)
+		if err != nil {
+			t.Fatalf("%q: %v", args, err)
+		}
+		want := args
+		if want == nil {
+			want = []string{}
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("read %q, want %q", got, want)
+		}
+	}
+}
+
+func TestInstanceArgsCorrupt(t *testing.T) {
+	for _, data := range []string{"", "[\"a\"", "{}", "[1]"} {
+		if args, err := readInstanceArgs(bytes.NewBufferString(data)); err == nil {
+			t.Errorf("%q: read %q, want error", data, args)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+//go:build (linux && !android) || freebsd || openbsd || (darwin && !ios)
+// +build linux,!android freebsd openbsd darwin,!ios
+
+package app
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// listenInstance listens for other instances on a Unix domain socket
+// and calls deliver with their arguments. It returns a nil io.Closer
+// if another instance is already listening.
+func listenInstance(id string, deliver func(args []string)) (io.Closer, error) {
+	path, err := instanceSocket(id)
+	if err != nil {
+		return nil, err
+	}
+	// Serialize instances starting at the same time, so a live
+	// socket is never taken for a stale one.
+	unlock, err := lockInstance(path + ".lock")
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		if !errors.Is(err, syscall.EADDRINUSE) {
+			return nil, err
+		}
+		c, err := net.Dial("unix", path)
+		if err == nil {
+			c.Close()
+			return nil, nil
+		}
+		if !errors.Is(err, syscall.ECONNREFUSED) {
+			return nil, err
+		}
+		// The socket is stale; the previous primary instance
+		// didn't shut down cleanly.
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+		if l, err = net.Listen("unix", path); err != nil {
+			return nil, err
+		}
+	}
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			args, err := readInstanceArgs(c)
+			c.Close()
+			if err == nil {
+				deliver(args)
+			}
+		}
+	}()
+	return l, nil
+}
+
+// lockInstance takes an exclusive lock on the file at path, and
+// returns the function that releases it.
+func lockInstance(path string) (func(), error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return func() {
+		syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+		f.Close()
+	}, nil
+}
+
+func forwardInstance(id string, args []string) error {
+	path, err := instanceSocket(id)
+	if err != nil {
+		return err
+	}
+	c, err := net.Dial("unix", path)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	if err := writeInstanceArgs(c, args); err != nil {
+		return err
+	}
+	activateInstance(c)
+	return nil
+}
+
+// instanceSocket returns the path of the socket of the instance id, in
+// a directory only the user can access.
+func instanceSocket(id string) (string, error) {
+	dir := os.Getenv("XDG_RUNTIME_DIR")
+	if dir == "" {
+		// Fall back to a private directory in the shared temporary
+		// directory, which must not be one planted by another user.
+		uid := os.Getuid()
+		dir = filepath.Join(os.TempDir(), fmt.Sprintf("gio-%d", uid))
+		if err := os.Mkdir(dir, 0o700); err != nil && !errors.Is(err, fs.ErrExist) {
+			return "", err
+		}
+		fi, err := os.Lstat(dir)
+		if err != nil {
+			return "", err
+		}
+		st, ok := fi.Sys().(*syscall.Stat_t)
+		if !fi.IsDir() || !ok || int(st.Uid) != uid || fi.Mode().Perm()&0o077 != 0 {
+			return "", fmt.Errorf("app: insecure instance directory %s", dir)
+		}
+	}
+	return filepath.Join(dir, "gio-"+id+".sock"), nil
+}
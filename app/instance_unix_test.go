@@ -0,0 +1,97 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+//go:build (linux && !android) || freebsd || openbsd || (darwin && !ios)
+// +build linux,!android freebsd openbsd darwin,!ios
+
+package app
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestInstanceForward(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+	id := fmt.Sprintf("test-%d", os.Getpid())
+	received := make(chan []string, 1)
+	l, err := listenInstance(id, func(args []string) { received <- args })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	if l2, err := listenInstance(id, nil); err != nil || l2 != nil {
+		t.Fatalf("second instance listened (%v, %v), want nil", l2, err)
+	}
+	want := []string{"a", "b c"}
+	if err := forwardInstance(id, want); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case got := <-received:
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("received %q, want %q", got, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("arguments not delivered")
+	}
+}
+
+func TestInstanceStaleSocket(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+	id := fmt.Sprintf("test-%d", os.Getpid())
+	path, err := instanceSocket(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Leave the socket behind, as if the primary instance crashed.
+	stale, err := net.ListenUnix("unix", &net.UnixAddr{Name: path, Net: "unix"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale.SetUnlinkOnClose(false)
+	stale.Close()
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("no stale socket: %v", err)
+	}
+	l, err := listenInstance(id, func([]string) {})
+	if err != nil {
+		t.Fatalf("failed to replace the stale socket: %v", err)
+	}
+	if l == nil {
+		t.Fatal("stale socket taken for a running instance")
+	}
+	l.Close()
+}
+
+func TestInstanceDir(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", "")
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+	path, err := instanceSocket("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Dir(path)
+	fi, err := os.Stat(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Dir(dir) != tmp || fi.Mode().Perm() != 0o700 {
+		t.Errorf("instance directory %s with mode %v, want a private directory in %s", dir, fi.Mode(), tmp)
+	}
+	// A directory other users can access is refused.
+	if err := os.Chmod(dir, 0o777); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := instanceSocket("test"); err == nil {
+		t.Error("accepted a directory accessible to other users")
+	}
+	if _, err := listenInstance("test", nil); err == nil {
+		t.Error("listened in a directory accessible to other users")
+	}
+}
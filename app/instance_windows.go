@@ -0,0 +1,118 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package app
+
+import (
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	syscall "golang.org/x/sys/windows"
+)
+
+// pipeInstance accepts connections from other instances on a named
+// pipe. The named mutex marks the instance as the primary instance.
+type pipeInstance struct {
+	name  string
+	mutex syscall.Handle
+
+	mu     sync.Mutex
+	closed bool
+}
+
+// listenInstance claims the named mutex for id and listens for other
+// instances on a named pipe. It returns a nil io.Closer if another
+// instance owns the mutex.
+func listenInstance(id string, deliver func(args []string)) (io.Closer, error) {
+	mname, err := syscall.UTF16PtrFromString(`Local\gio-` + id)
+	if err != nil {
+		return nil, err
+	}
+	mutex, err := syscall.CreateMutex(nil, false, mname)
+	if err != nil {
+		if errors.Is(err, syscall.ERROR_ALREADY_EXISTS) {
+			syscall.CloseHandle(mutex)
+			return nil, nil
+		}
+		return nil, err
+	}
+	p := &pipeInstance{name: instancePipe(id), mutex: mutex}
+	pname, err := syscall.UTF16PtrFromString(p.name)
+	if err != nil {
+		syscall.CloseHandle(mutex)
+		return nil, err
+	}
+	go func() {
+		for {
+			h, err := syscall.CreateNamedPipe(pname,
+				syscall.PIPE_ACCESS_INBOUND,
+				syscall.PIPE_TYPE_BYTE|syscall.PIPE_READMODE_BYTE|syscall.PIPE_WAIT,
+				syscall.PIPE_UNLIMITED_INSTANCES, 0, 4096, 0, nil)
+			if err != nil {
+				return
+			}
+			err = syscall.ConnectNamedPipe(h, nil)
+			if err != nil && !errors.Is(err, syscall.ERROR_PIPE_CONNECTED) {
+				syscall.CloseHandle(h)
+				return
+			}
+			f := os.NewFile(uintptr(h), p.name)
+			args, err := readInstanceArgs(f)
+			f.Close()
+			if p.isClosed() {
+				return
+			}
+			if err == nil {
+				deliver(args)
+			}
+		}
+	}()
+	return p, nil
+}
+
+func forwardInstance(id string, args []string) error {
+	name := instancePipe(id)
+	var f *os.File
+	var err error
+	// The primary instance may be busy serving another instance,
+	// or not have created its pipe yet.
+	for i := 0; i < 10; i++ {
+		f, err = os.OpenFile(name, os.O_WRONLY, 0)
+		if err == nil {
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return writeInstanceArgs(f, args)
+}
+
+func (p *pipeInstance) isClosed() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.closed
+}
+
+func (p *pipeInstance) Close() error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closed = true
+	p.mu.Unlock()
+	// Unblock the listening goroutine waiting for a connection.
+	if f, err := os.OpenFile(p.name, os.O_WRONLY, 0); err == nil {
+		f.Close()
+	}
+	return syscall.CloseHandle(p.mutex)
+}
+
+func instancePipe(id string) string {
+	return `\\.\pipe\gio-` + id
+}
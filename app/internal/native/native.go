@@ -0,0 +1,72 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+// Package native defines the native handles of windows, shared by
+// package app and package unsafeinterop.
+package native
+
+import (
+	"unsafe"
+
+	"github.com/Seikaijyu/gio/gpu"
+)
+
+// Platform is a windowing system.
+type Platform uint8
+
+const (
+	// Unknown is the platform of windows without native handles, such
+	// as headless windows and windows without a view.
+	Unknown Platform = iota
+	Windows
+	MacOS
+	IOS
+	X11
+	Wayland
+	Android
+)
+
+// Handles are the native handles of a window. Only the fields of its
+// Platform are set.
+type Handles struct {
+	Platform Platform
+	// Window is the HWND on Windows, the CFTypeRef of the NSWindow on
+	// macOS, and the window ID on X11.
+	Window uintptr
+	// View is the CFTypeRef of the NSView or UIView on macOS and iOS, and
+	// a JNI global reference to the android.view.View on Android.
+	View uintptr
+	// Layer is the CFTypeRef of the CALayer of View on macOS.
+	Layer uintptr
+	// ViewController is the CFTypeRef of the UIViewController on iOS.
+	ViewController uintptr
+	// Display is the *Display of X11, and the *wl_display of Wayland.
+	Display unsafe.Pointer
+	// Surface is the *wl_surface on Wayland, and the *ANativeWindow of
+	// the view on Android.
+	Surface unsafe.Pointer
+}
+
+// Lookup returns the handles and the GPU API of the app.Window w. It is
+// set by package app.
+var Lookup func(w interface{}) (Handles, gpu.API)
+
+func (p Platform) String() string {
+	switch p {
+	case Unknown:
+		return "Unknown"
+	case Windows:
+		return "Windows"
+	case MacOS:
+		return "macOS"
+	case IOS:
+		return "iOS"
+	case X11:
+		return "X11"
+	case Wayland:
+		return "Wayland"
+	case Android:
+		return "Android"
+	default:
+		panic("invalid Platform")
+	}
+}
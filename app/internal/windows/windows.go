@@ -8,6 +8,7 @@ package windows
 import (
 	"fmt"
 	"runtime"
+	gosyscall "syscall"
 	"time"
 	"unicode/utf16"
 	"unsafe"
@@ -64,10 +65,78 @@ type Msg struct {
 	LPrivate uint32
 }
 
+// GUID 是 COM 接口和属性集的标识符。
+type GUID struct {
+	Data1 uint32
+	Data2 uint16
+	Data3 uint16
+	Data4 [8]byte
+}
+
+// propertyKey 对应 PROPERTYKEY 结构体。
+type propertyKey struct {
+	fmtid GUID
+	pid   uint32
+}
+
+// propVariant 对应只保存简单值的 PROPVARIANT 结构体。
+type propVariant struct {
+	vt  uint16
+	_   [3]uint16
+	val uint64
+	_   uintptr
+}
+
+// propertyStore 对应 IPropertyStore 接口。
+type propertyStore struct {
+	vtbl *struct {
+		QueryInterface uintptr
+		AddRef         uintptr
+		Release        uintptr
+		GetCount       uintptr
+		GetAt          uintptr
+		GetValue       uintptr
+		SetValue       uintptr
+		Commit         uintptr
+	}
+}
+
+var (
+	iidPropertyStore = GUID{0x886d8eeb, 0x8cf2, 0x4446, [8]byte{0x8d, 0x02, 0xcd, 0xba, 0x1d, 0xbd, 0xcf, 0x99}}
+
+	// UI 自动化提供程序实现的接口
+	IID_IUnknown                        = GUID{0x00000000, 0x0000, 0x0000, [8]byte{0xc0, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x46}}
+	IID_IRawElementProviderSimple       = GUID{0xd6dd68d1, 0x86fd, 0x4332, [8]byte{0x86, 0x66, 0x9a, 0xbe, 0xde, 0xa2, 0xd2, 0x4c}}
+	IID_IRawElementProviderFragment     = GUID{0xf7063da8, 0x8359, 0x439c, [8]byte{0x92, 0x97, 0xbb, 0xc5, 0x29, 0x9a, 0x7d, 0x87}}
+	IID_IRawElementProviderFragmentRoot = GUID{0x620ce2a5, 0xab8f, 0x40a9, [8]byte{0x86, 0xcb, 0xde, 0x3c, 0x75, 0x59, 0x9b, 0x58}}
+	IID_IInvokeProvider                 = GUID{0x54fcb24b, 0xe18e, 0x47a2, [8]byte{0xb4, 0xd3, 0xec, 0xcb, 0xe7, 0x75, 0x99, 0xa2}}
+	IID_IToggleProvider                 = GUID{0x56d00bd0, 0xc4f4, 0x433c, [8]byte{0xa8, 0x36, 0x1a, 0x52, 0xa5, 0x7e, 0x08, 0x92}}
+	IID_IValueProvider                  = GUID{0xc7935180, 0x6fb3, 0x4201, [8]byte{0xb1, 0x74, 0x7d, 0xf7, 0x3a, 0xdb, 0xf6, 0x4a}}
+	IID_ISelectionItemProvider          = GUID{0x2acad808, 0xb2d4, 0x452d, [8]byte{0xa4, 0x07, 0x91, 0xff, 0x1a, 0xd1, 0x67, 0xb2}}
+
+	pkeyEdgeGestureDisableTouchWhenFullscreen = propertyKey{
+		fmtid: GUID{0x32ce38b2, 0x2c9a, 0x41b1, [8]byte{0x9b, 0xc5, 0xb3, 0x78, 0x43, 0x94, 0xaa, 0x44}},
+		pid:   2,
+	}
+)
+
 type Point struct {
 	X, Y int32
 }
 
+// Variant 对应只保存简单值的 VARIANT 结构体。它比 64 位系统上的
+// VARIANT 短，只能用于写入由调用方分配的 VARIANT。
+type Variant struct {
+	VT  uint16
+	_   [3]uint16
+	Val uint64
+}
+
+// UiaRect 对应 UI 自动化使用的 UiaRect 结构体，以屏幕像素为单位。
+type UiaRect struct {
+	Left, Top, Width, Height float64
+}
+
 type MinMaxInfo struct {
 	PtReserved     Point
 	PtMaxSize      Point
@@ -101,6 +170,88 @@ type WindowPlacement struct {
 	rcDevice         Rect
 }
 
+// PointerPenInfo 对应 POINTER_PEN_INFO 结构体，包含触控笔的指针信息
+type PointerPenInfo struct {
+	PointerType           uint32
+	PointerID             uint32
+	FrameID               uint32
+	PointerFlags          uint32
+	SourceDevice          syscall.Handle
+	HwndTarget            syscall.Handle
+	PtPixelLocation       Point
+	PtHimetricLocation    Point
+	PtPixelLocationRaw    Point
+	PtHimetricLocationRaw Point
+	DwTime                uint32
+	HistoryCount          uint32
+	InputData             int32
+	DwKeyStates           uint32
+	PerformanceCount      uint64
+	ButtonChangeType      int32
+
+	PenFlags uint32
+	PenMask  uint32
+	Pressure uint32
+	Rotation uint32
+	TiltX    int32
+	TiltY    int32
+}
+
+// GestureInfo 对应 GESTUREINFO 结构体，包含手势的信息
+type GestureInfo struct {
+	cbSize      uint32
+	Flags       uint32
+	ID          uint32
+	HwndTarget  syscall.Handle
+	Location    [2]int16
+	InstanceID  uint32
+	SequenceID  uint32
+	Arguments   uint64
+	cbExtraArgs uint32
+}
+
+// GestureConfig 对应 GESTURECONFIG 结构体
+type GestureConfig struct {
+	ID    uint32
+	Want  uint32
+	Block uint32
+}
+
+// IconInfo 对应 ICONINFO 结构体，描述图标或光标的热点和位图
+type IconInfo struct {
+	Icon     int32
+	XHotspot uint32
+	YHotspot uint32
+	Mask     syscall.Handle
+	Color    syscall.Handle
+}
+
+// FlashWInfo 对应 FLASHWINFO 结构体，描述窗口的闪烁方式
+type FlashWInfo struct {
+	Size    uint32
+	Hwnd    syscall.Handle
+	Flags   uint32
+	Count   uint32
+	Timeout uint32
+}
+
+// XInputState 对应 XINPUT_STATE 结构体，包含游戏手柄的状态
+type XInputState struct {
+	PacketNumber uint32
+	Gamepad      XInputGamepad
+}
+
+// XInputGamepad 对应 XINPUT_GAMEPAD 结构体
+type XInputGamepad struct {
+	Buttons      uint16
+	LeftTrigger  uint8
+	RightTrigger uint8
+	ThumbLX      int16
+	ThumbLY      int16
+	ThumbRX      int16
+	ThumbRY      int16
+}
+
 type MonitorInfo struct {
 	cbSize   uint32
 	Monitor  Rect
@@ -108,6 +259,32 @@ type MonitorInfo struct {
 	Flags    uint32
 }
 
+// monitorInfoEx 对应 MONITORINFOEXW 结构体，额外包含显示器的设备名称。
+type monitorInfoEx struct {
+	MonitorInfo
+	device [32]uint16
+}
+
+// devMode 对应 DEVMODEW 结构体，只命名了显示设备用到的字段。
+type devMode struct {
+	deviceName       [32]uint16
+	specVersion      uint16
+	driverVersion    uint16
+	size             uint16
+	driverExtra      uint16
+	fields           uint32
+	_                [16]byte
+	_                [10]byte
+	formName         [32]uint16
+	logPixels        uint16
+	bitsPerPel       uint32
+	pelsWidth        uint32
+	pelsHeight       uint32
+	displayFlags     uint32
+	displayFrequency uint32
+	_                [32]byte
+}
+
 const (
 	TRUE = 1
 
@@ -121,7 +298,8 @@ const (
 
 	CW_USEDEFAULT = -2147483648
 
-	GWL_STYLE = ^(uintptr(16) - 1) // -16
+	GWL_STYLE   = ^(uintptr(16) - 1) // -16
+	GWL_EXSTYLE = ^(uintptr(20) - 1) // -20
 
 	GCS_COMPSTR       = 0x0008
 	GCS_COMPREADSTR   = 0x0001
@@ -135,6 +313,14 @@ const (
 
 	HWND_TOPMOST = ^(uint32(1) - 1) // -1
 
+	PT_PEN = 3
+
+	PEN_FLAG_BARREL   = 0x00000001
+	PEN_FLAG_INVERTED = 0x00000002
+	PEN_FLAG_ERASER   = 0x00000004
+
+	POINTER_FLAG_INCONTACT = 0x00000004
+
 	HTCAPTION     = 2
 	HTCLIENT      = 1
 	HTLEFT        = 10
@@ -167,7 +353,9 @@ const (
 
 	MDT_EFFECTIVE_DPI = 0
 
+	MONITOR_DEFAULTTONULL    = 0
 	MONITOR_DEFAULTTOPRIMARY = 1
+	MONITOR_DEFAULTTONEAREST = 2
 
 	NI_COMPOSITIONSTR = 0x0015
 
@@ -179,14 +367,28 @@ const (
 
 	SM_CXSIZEFRAME = 32
 	SM_CYSIZEFRAME = 33
-
-	SW_SHOWDEFAULT   = 10
-	SW_SHOWMINIMIZED = 2
-	SW_SHOWMAXIMIZED = 3
-	SW_SHOWNORMAL    = 1
-	SW_SHOW          = 5
+	SM_CXDRAG      = 68
+	SM_CYDRAG      = 69
+
+	SPI_GETWHEELSCROLLLINES = 0x0068
+	SPI_GETWHEELSCROLLCHARS = 0x006C
+
+	// WHEEL_DELTA 是鼠标滚轮一格的距离
+	WHEEL_DELTA = 120
+	// WHEEL_PAGESCROLL 表示滚轮每格滚动一页
+	WHEEL_PAGESCROLL = 0xFFFFFFFF
+
+	SW_SHOWDEFAULT     = 10
+	SW_SHOWMINIMIZED   = 2
+	SW_SHOWMAXIMIZED   = 3
+	SW_SHOWNORMAL      = 1
+	SW_SHOWNOACTIVATE  = 4
+	SW_SHOWMINNOACTIVE = 7
+	SW_SHOW            = 5
+	SW_HIDE            = 0
 
 	SWP_FRAMECHANGED  = 0x0020
+	SWP_NOACTIVATE    = 0x0010
 	SWP_NOMOVE        = 0x0002
 	SWP_NOOWNERZORDER = 0x0200
 	SWP_NOSIZE        = 0x0001
@@ -246,10 +448,12 @@ const (
 
 	WM_CANCELMODE           = 0x001F
 	WM_CHAR                 = 0x0102
+	WM_DEADCHAR             = 0x0103
 	WM_CLOSE                = 0x0010
 	WM_CREATE               = 0x0001
 	WM_DPICHANGED           = 0x02E0
 	WM_DESTROY              = 0x0002
+	WM_DISPLAYCHANGE        = 0x007E
 	WM_ERASEBKGND           = 0x0014
 	WM_GETMINMAXINFO        = 0x0024
 	WM_IME_COMPOSITION      = 0x010F
@@ -265,10 +469,15 @@ const (
 	WM_MOUSEMOVE            = 0x0200
 	WM_MOUSEWHEEL           = 0x020A
 	WM_MOUSEHWHEEL          = 0x020E
+	WM_MOVE                 = 0x0003
 	WM_NCACTIVATE           = 0x0086
 	WM_NCHITTEST            = 0x0084
 	WM_NCCALCSIZE           = 0x0083
 	WM_PAINT                = 0x000F
+	WM_POINTERUPDATE        = 0x0245
+	WM_POINTERDOWN          = 0x0246
+	WM_POINTERUP            = 0x0247
+	WM_POINTERLEAVE         = 0x024A
 	WM_QUIT                 = 0x0012
 	WM_SETCURSOR            = 0x0020
 	WM_SETFOCUS             = 0x0007
@@ -281,9 +490,13 @@ const (
 	WM_TIMER                = 0x0113
 	WM_UNICHAR              = 0x0109
 	WM_DROPFILES            = 0x0233
+	WM_GESTURE              = 0x0119
+	WM_GETOBJECT            = 0x003D
 	WM_USER                 = 0x0400
 	WM_WINDOWPOSCHANGED     = 0x0047
 
+	WS_CHILD            = 0x40000000
+	WS_POPUP            = 0x80000000
 	WS_CLIPCHILDREN     = 0x02000000
 	WS_CLIPSIBLINGS     = 0x04000000
 	WS_MAXIMIZE         = 0x01000000
@@ -299,6 +512,7 @@ const (
 	WS_MAXIMIZEBOX = 0x00010000
 
 	WS_EX_APPWINDOW  = 0x00040000
+	WS_EX_NOACTIVATE = 0x08000000
 	WS_EX_WINDOWEDGE = 0x00000100
 
 	QS_ALLINPUT = 0x04FF
@@ -327,6 +541,110 @@ const (
 	LR_MONOCHROME       = 0x00000001
 	LR_SHARED           = 0x00008000
 	LR_VGACOLOR         = 0x00000080
+
+	XUSER_MAX_COUNT = 4
+
+	GID_ZOOM   = 3
+	GID_ROTATE = 5
+
+	GF_BEGIN = 0x00000001
+
+	GC_ALLGESTURES = 0x00000001
+
+	FLASHW_ALL       = 0x00000003
+	FLASHW_TIMERNOFG = 0x0000000C
+
+	DWMWA_USE_IMMERSIVE_DARK_MODE = 20
+	DWMWA_CAPTION_COLOR           = 35
+	DWMWA_TEXT_COLOR              = 36
+	DWMWA_COLOR_DEFAULT           = 0xFFFFFFFF
+
+	ENUM_CURRENT_SETTINGS = 0xFFFFFFFF
+
+	ES_SYSTEM_REQUIRED  = 0x00000001
+	ES_DISPLAY_REQUIRED = 0x00000002
+	ES_CONTINUOUS       = 0x80000000
+
+	VT_EMPTY    = 0
+	VT_I4       = 3
+	VT_BSTR     = 8
+	VT_BOOL     = 11
+	VT_I4_ARRAY = 0x2003
+
+	VARIANT_TRUE  = 0xffff
+	VARIANT_FALSE = 0
+
+	S_OK                      = 0
+	E_NOTIMPL                 = 0x80004001
+	E_NOINTERFACE             = 0x80004002
+	E_POINTER                 = 0x80004003
+	E_INVALIDARG              = 0x80070057
+	UIA_E_ELEMENTNOTENABLED   = 0x80040200
+	UIA_E_ELEMENTNOTAVAILABLE = 0x80040201
+	UIA_E_INVALIDOPERATION    = 0x80131509
+
+	// UiaRootObjectId 是 WM_GETOBJECT 请求 UI 自动化提供程序时的对象 ID
+	UiaRootObjectId = -25
+
+	UiaAppendRuntimeId = 3
+
+	ProviderOptions_ServerSideProvider = 0x1
+
+	NavigateDirection_Parent          = 0
+	NavigateDirection_NextSibling     = 1
+	NavigateDirection_PreviousSibling = 2
+	NavigateDirection_FirstChild      = 3
+	NavigateDirection_LastChild       = 4
+
+	ToggleState_Off = 0
+	ToggleState_On  = 1
+
+	StructureChangeType_ChildrenInvalidated = 2
+
+	UIA_StructureChangedEventId       = 20002
+	UIA_AutomationFocusChangedEventId = 20005
+
+	UIA_InvokePatternId        = 10000
+	UIA_SelectionItemPatternId = 10010
+	UIA_ValuePatternId         = 10002
+	UIA_TogglePatternId        = 10015
+
+	UIA_ControlTypePropertyId             = 30003
+	UIA_NamePropertyId                    = 30005
+	UIA_HasKeyboardFocusPropertyId        = 30008
+	UIA_IsKeyboardFocusablePropertyId     = 30009
+	UIA_IsEnabledPropertyId               = 30010
+	UIA_HelpTextPropertyId                = 30013
+	UIA_FrameworkIdPropertyId             = 30024
+	UIA_ValueValuePropertyId              = 30045
+	UIA_SelectionItemIsSelectedPropertyId = 30079
+	UIA_ToggleToggleStatePropertyId       = 30086
+
+	UIA_ButtonControlTypeId      = 50000
+	UIA_CheckBoxControlTypeId    = 50002
+	UIA_EditControlTypeId        = 50004
+	UIA_RadioButtonControlTypeId = 50013
+	UIA_TextControlTypeId        = 50020
+	UIA_GroupControlTypeId       = 50026
+
+	WDA_NONE               = 0x00000000
+	WDA_MONITOR            = 0x00000001
+	WDA_EXCLUDEFROMCAPTURE = 0x00000011
+
+	XINPUT_GAMEPAD_DPAD_UP        = 0x0001
+	XINPUT_GAMEPAD_DPAD_DOWN      = 0x0002
+	XINPUT_GAMEPAD_DPAD_LEFT      = 0x0004
+	XINPUT_GAMEPAD_DPAD_RIGHT     = 0x0008
+	XINPUT_GAMEPAD_START          = 0x0010
+	XINPUT_GAMEPAD_BACK           = 0x0020
+	XINPUT_GAMEPAD_LEFT_THUMB     = 0x0040
+	XINPUT_GAMEPAD_RIGHT_THUMB    = 0x0080
+	XINPUT_GAMEPAD_LEFT_SHOULDER  = 0x0100
+	XINPUT_GAMEPAD_RIGHT_SHOULDER = 0x0200
+	XINPUT_GAMEPAD_A              = 0x1000
+	XINPUT_GAMEPAD_B              = 0x2000
+	XINPUT_GAMEPAD_X              = 0x4000
+	XINPUT_GAMEPAD_Y              = 0x8000
 )
 
 var (
@@ -342,12 +660,18 @@ var (
 	// GlobalFree函数用于释放之前由GlobalAlloc函数分配的内存块
 	_GlobalFree = kernel32.NewProc("GlobalFree")
 
+	// GlobalSize函数用于获取全局内存块的大小
+	_GlobalSize = kernel32.NewProc("GlobalSize")
+
 	// GlobalLock函数用于锁定之前由GlobalAlloc函数分配的内存块，防止系统移动这个内存块
 	_GlobalLock = kernel32.NewProc("GlobalLock")
 
 	// GlobalUnlock函数用于解锁之前由GlobalLock函数锁定的内存块
 	_GlobalUnlock = kernel32.NewProc("GlobalUnlock")
 
+	// SetThreadExecutionState函数用于在调用线程运行期间阻止系统睡眠或者关闭显示器
+	_SetThreadExecutionState = kernel32.NewProc("SetThreadExecutionState")
+
 	// user32.dll是Windows操作系统的用户界面库，包含了许多用于创建和管理窗口的函数
 	user32 = syscall.NewLazySystemDLL("user32.dll")
 
@@ -361,9 +685,15 @@ var (
 	// CloseClipboard函数用于关闭剪贴板，结束剪贴板的更新
 	_CloseClipboard = user32.NewProc("CloseClipboard")
 
+	// CreateIconIndirect函数用于从位图创建一个图标或光标
+	_CreateIconIndirect = user32.NewProc("CreateIconIndirect")
+
 	// CreateWindowExW函数用于创建一个新的窗口
 	_CreateWindowEx = user32.NewProc("CreateWindowExW")
 
+	// DestroyCursor函数用于销毁由CreateIconIndirect创建的光标
+	_DestroyCursor = user32.NewProc("DestroyCursor")
+
 	// DefWindowProcW函数是默认的窗口过程函数，用于处理窗口接收到的消息
 	_DefWindowProc = user32.NewProc("DefWindowProcW")
 
@@ -376,6 +706,15 @@ var (
 	// EmptyClipboard函数用于清空剪贴板的内容
 	_EmptyClipboard = user32.NewProc("EmptyClipboard")
 
+	// EnableWindow函数用于启用或禁用窗口的鼠标和键盘输入
+	_EnableWindow = user32.NewProc("EnableWindow")
+
+	// EnumDisplaySettingsW函数用于获取显示设备的显示模式，如分辨率、刷新率等
+	_EnumDisplaySettings = user32.NewProc("EnumDisplaySettingsW")
+
+	// FlashWindowEx函数用于闪烁窗口的标题栏和任务栏按钮
+	_FlashWindowEx = user32.NewProc("FlashWindowEx")
+
 	// GetWindowRect函数用于获取一个窗口的尺寸和位置
 	_GetWindowRect = user32.NewProc("GetWindowRect")
 
@@ -385,9 +724,15 @@ var (
 	// GetClipboardData函数用于获取剪贴板上的数据
 	_GetClipboardData = user32.NewProc("GetClipboardData")
 
+	// GetCursorPos函数用于获取鼠标光标在屏幕上的位置
+	_GetCursorPos = user32.NewProc("GetCursorPos")
+
 	// GetDC函数用于获取一个窗口的设备上下文，用于在窗口上绘图
 	_GetDC = user32.NewProc("GetDC")
 
+	// GetDoubleClickTime函数用于获取鼠标双击的最大间隔，单位为毫秒
+	_GetDoubleClickTime = user32.NewProc("GetDoubleClickTime")
+
 	// GetDpiForWindow函数用于获取一个窗口的DPI设置
 	_GetDpiForWindow = user32.NewProc("GetDpiForWindow")
 
@@ -403,6 +748,18 @@ var (
 	// GetMonitorInfoW函数用于获取一个显示器的信息
 	_GetMonitorInfo = user32.NewProc("GetMonitorInfoW")
 
+	// GetGestureInfo函数用于获取手势消息的信息
+	_GetGestureInfo = user32.NewProc("GetGestureInfo")
+
+	// CloseGestureInfoHandle函数用于释放手势消息的信息
+	_CloseGestureInfoHandle = user32.NewProc("CloseGestureInfoHandle")
+
+	// GetPointerPenInfo函数用于获取触控笔指针的压力、倾斜等信息
+	_GetPointerPenInfo = user32.NewProc("GetPointerPenInfo")
+
+	// GetPointerType函数用于获取指针的输入设备类型
+	_GetPointerType = user32.NewProc("GetPointerType")
+
 	// GetSystemMetrics函数用于获取系统的一些参数，如屏幕尺寸、颜色深度等
 	_GetSystemMetrics = user32.NewProc("GetSystemMetrics")
 
@@ -454,21 +811,39 @@ var (
 	// RegisterClassExW函数用于注册一个窗口类，这个窗口类可以用于创建窗口
 	_RegisterClassExW = user32.NewProc("RegisterClassExW")
 
+	// RegisterClipboardFormatW函数用于注册一个新的剪贴板格式，例如 HTML 和 RTF
+	_RegisterClipboardFormatW = user32.NewProc("RegisterClipboardFormatW")
+
 	// ReleaseDC函数用于释放之前由GetDC函数获取的设备上下文
 	_ReleaseDC = user32.NewProc("ReleaseDC")
 
+	// SetGestureConfig函数用于配置窗口接收的手势
+	_SetGestureConfig = user32.NewProc("SetGestureConfig")
+
 	// ScreenToClient函数用于将屏幕坐标转换为客户区坐标
 	_ScreenToClient = user32.NewProc("ScreenToClient")
 
 	// ShowWindow函数用于显示或隐藏一个窗口
 	_ShowWindow = user32.NewProc("ShowWindow")
 
+	// SendMessageW函数用于向窗口发送消息，并等待窗口过程处理完毕
+	_SendMessage = user32.NewProc("SendMessageW")
+
+	// ClientToScreen函数用于将客户区坐标转换为屏幕坐标
+	_ClientToScreen = user32.NewProc("ClientToScreen")
+
+	// SystemParametersInfoW函数用于获取系统范围的参数
+	_SystemParametersInfo = user32.NewProc("SystemParametersInfoW")
+
 	// SetCapture函数用于设置鼠标捕获，使一个窗口接收所有的鼠标输入
 	_SetCapture = user32.NewProc("SetCapture")
 
 	// SetCursor函数用于设置光标
 	_SetCursor = user32.NewProc("SetCursor")
 
+	// SetCursorPos函数用于将鼠标光标移动到屏幕上的指定位置
+	_SetCursorPos = user32.NewProc("SetCursorPos")
+
 	// SetClipboardData函数用于设置剪贴板的数据
 	_SetClipboardData = user32.NewProc("SetClipboardData")
 	// Windows User32 API 函数
@@ -480,17 +855,24 @@ var (
 	_SetWindowLong32     = user32.NewProc("SetWindowLongW")      // 改变一个窗口的属性（32位版本）
 	_SetWindowPlacement  = user32.NewProc("SetWindowPlacement")  // 设置窗口的显示状态和位置
 	_SetWindowPos        = user32.NewProc("SetWindowPos")        // 改变窗口的大小和位置
+	_SetParent           = user32.NewProc("SetParent")           // 改变窗口的父窗口
+	_SetWindowRgn        = user32.NewProc("SetWindowRgn")        // 设置窗口的可见区域
 	_SetWindowText       = user32.NewProc("SetWindowTextW")      // 设置窗口的标题
 	_TranslateMessage    = user32.NewProc("TranslateMessage")    // 将虚拟键消息转换为字符消息
 	_UnregisterClass     = user32.NewProc("UnregisterClassW")    // 注销窗口类
 	_UpdateWindow        = user32.NewProc("UpdateWindow")        // 更新窗口的客户区
 
+	_SetWindowDisplayAffinity = user32.NewProc("SetWindowDisplayAffinity") // 设置窗口内容能否被截屏或录屏
+
 	// Windows Shcore API 函数
 	shcore            = syscall.NewLazySystemDLL("shcore")
 	_GetDpiForMonitor = shcore.NewProc("GetDpiForMonitor") // 获取指定监视器的DPI设置
 
 	// Windows Gdi32 API 函数
 	gdi32          = syscall.NewLazySystemDLL("gdi32")
+	_CreateBitmap  = gdi32.NewProc("CreateBitmap")  // 创建位图
+	_CreateRectRgn = gdi32.NewProc("CreateRectRgn") // 创建矩形区域
+	_DeleteObject  = gdi32.NewProc("DeleteObject")  // 删除 GDI 对象
 	_GetDeviceCaps = gdi32.NewProc("GetDeviceCaps") // 获取设备的能力
 
 	// Windows Imm32 API 函数
@@ -505,12 +887,35 @@ var (
 	// Windows Dwmapi API 函数
 	dwmapi                        = syscall.NewLazySystemDLL("dwmapi")
 	_DwmExtendFrameIntoClientArea = dwmapi.NewProc("DwmExtendFrameIntoClientArea") // 扩展窗口帧到客户区
+	_DwmSetWindowAttribute        = dwmapi.NewProc("DwmSetWindowAttribute")        // 设置窗口的 DWM 属性
 
 	// Windows Shell32 API 函数
 	shell32              = syscall.NewLazyDLL("shell32.dll")
 	_ProcDragAcceptFiles = shell32.NewProc("DragAcceptFiles") // 允许窗口接受拖放文件
 	_ProcDragQueryFile   = shell32.NewProc("DragQueryFileW")  // 获取拖放文件的信息，注意,只有DragQueryFileW才使用w_char*编码字符串，DragQueryFileA使用char*编码字符串
 	_ProcDragFinish      = shell32.NewProc("DragFinish")      // 释放拖放文件的资源
+
+	_SHGetPropertyStoreForWindow = shell32.NewProc("SHGetPropertyStoreForWindow") // 获取窗口的属性存储
+
+	// Windows OleAut32 API 函数
+	oleaut32               = syscall.NewLazySystemDLL("oleaut32.dll")
+	_SysAllocString        = oleaut32.NewProc("SysAllocString")        // 分配 BSTR 字符串
+	_SysFreeString         = oleaut32.NewProc("SysFreeString")         // 释放 BSTR 字符串
+	_SafeArrayCreateVector = oleaut32.NewProc("SafeArrayCreateVector") // 创建一维的 SAFEARRAY
+	_SafeArrayPutElement   = oleaut32.NewProc("SafeArrayPutElement")   // 设置 SAFEARRAY 的元素
+
+	// Windows UI 自动化 API 函数
+	uiautomationcore                        = syscall.NewLazySystemDLL("uiautomationcore.dll")
+	_UiaClientsAreListening                 = uiautomationcore.NewProc("UiaClientsAreListening")                 // 查询是否有 UI 自动化客户端在监听事件
+	_UiaHostProviderFromHwnd                = uiautomationcore.NewProc("UiaHostProviderFromHwnd")                // 获取窗口的宿主提供程序
+	_UiaRaiseAutomationEvent                = uiautomationcore.NewProc("UiaRaiseAutomationEvent")                // 发出 UI 自动化事件
+	_UiaRaiseAutomationPropertyChangedEvent = uiautomationcore.NewProc("UiaRaiseAutomationPropertyChangedEvent") // 发出属性变化事件
+	_UiaRaiseStructureChangedEvent          = uiautomationcore.NewProc("UiaRaiseStructureChangedEvent")          // 发出结构变化事件
+	_UiaReturnRawElementProvider            = uiautomationcore.NewProc("UiaReturnRawElementProvider")            // 响应 WM_GETOBJECT 返回提供程序
+
+	// Windows XInput API 函数
+	xinput          = syscall.NewLazySystemDLL("xinput1_4.dll")
+	_XInputGetState = xinput.NewProc("XInputGetState") // 获取游戏手柄的状态
 )
 
 // 窗口是否接受文件拖放
@@ -628,6 +1033,15 @@ func DwmExtendFrameIntoClientArea(hwnd syscall.Handle, margins Margins) error {
 	return nil
 }
 
+// DwmSetWindowAttribute 设置窗口 hwnd 的 32 位 DWM 属性 attr
+func DwmSetWindowAttribute(hwnd syscall.Handle, attr uint32, value uint32) error {
+	r, _, _ := _DwmSetWindowAttribute.Call(uintptr(hwnd), uintptr(attr), uintptr(unsafe.Pointer(&value)), unsafe.Sizeof(value))
+	if r != 0 {
+		return fmt.Errorf("DwmSetWindowAttribute: %#x", r)
+	}
+	return nil
+}
+
 func EmptyClipboard() error {
 	r, _, err := _EmptyClipboard.Call()
 	if r == 0 {
@@ -636,6 +1050,15 @@ func EmptyClipboard() error {
 	return nil
 }
 
+// EnableWindow 启用或禁用窗口的鼠标和键盘输入
+func EnableWindow(hwnd syscall.Handle, enable bool) {
+	var e uintptr
+	if enable {
+		e = 1
+	}
+	_EnableWindow.Call(uintptr(hwnd), e)
+}
+
 func GetWindowRect(hwnd syscall.Handle) Rect {
 	var r Rect
 	_GetWindowRect.Call(uintptr(hwnd), uintptr(unsafe.Pointer(&r)))
@@ -656,6 +1079,20 @@ func GetClipboardData(format uint32) (syscall.Handle, error) {
 	return syscall.Handle(r), nil
 }
 
+func GetCursorPos() (Point, error) {
+	var p Point
+	r, _, err := _GetCursorPos.Call(uintptr(unsafe.Pointer(&p)))
+	if r == 0 {
+		return Point{}, fmt.Errorf("GetCursorPos: %v", err)
+	}
+	return p, nil
+}
+
+func GetDoubleClickTime() time.Duration {
+	r, _, _ := _GetDoubleClickTime.Call()
+	return time.Duration(r) * time.Millisecond
+}
+
 func GetDC(hwnd syscall.Handle) (syscall.Handle, error) {
 	hdc, _, err := _GetDC.Call(uintptr(hwnd))
 	if hdc == 0 {
@@ -718,6 +1155,37 @@ func GetMessageTime() time.Duration {
 	return time.Duration(r) * time.Millisecond
 }
 
+func GetGestureInfo(lParam uintptr) (GestureInfo, error) {
+	info := GestureInfo{cbSize: uint32(unsafe.Sizeof(GestureInfo{}))}
+	r, _, err := _GetGestureInfo.Call(lParam, uintptr(unsafe.Pointer(&info)))
+	if r == 0 {
+		return info, fmt.Errorf("GetGestureInfo: %v", err)
+	}
+	return info, nil
+}
+
+func CloseGestureInfoHandle(lParam uintptr) {
+	_CloseGestureInfoHandle.Call(lParam)
+}
+
+func GetPointerType(id uint32) (uint32, error) {
+	var typ uint32
+	r, _, err := _GetPointerType.Call(uintptr(id), uintptr(unsafe.Pointer(&typ)))
+	if r == 0 {
+		return 0, fmt.Errorf("GetPointerType: %v", err)
+	}
+	return typ, nil
+}
+
+func GetPointerPenInfo(id uint32) (PointerPenInfo, error) {
+	var info PointerPenInfo
+	r, _, err := _GetPointerPenInfo.Call(uintptr(id), uintptr(unsafe.Pointer(&info)))
+	if r == 0 {
+		return info, fmt.Errorf("GetPointerPenInfo: %v", err)
+	}
+	return info, nil
+}
+
 func GetSystemMetrics(nIndex int) int {
 	r, _, _ := _GetSystemMetrics.Call(uintptr(nIndex))
 	return int(r)
@@ -758,6 +1226,17 @@ func GetWindowLong(hwnd syscall.Handle, index uintptr) (val uintptr) {
 	return
 }
 
+// XInputAvailable 报告系统是否支持 XInput
+func XInputAvailable() bool {
+	return _XInputGetState.Find() == nil
+}
+
+// XInputGetState 获取游戏手柄 user 的状态，如果手柄未连接则返回 false
+func XInputGetState(user uint32, state *XInputState) bool {
+	r, _, _ := _XInputGetState.Call(uintptr(user), uintptr(unsafe.Pointer(state)))
+	return r == 0
+}
+
 func ImmGetContext(hwnd syscall.Handle) syscall.Handle {
 	h, _, _ := _ImmGetContext.Call(uintptr(hwnd))
 	return syscall.Handle(h)
@@ -826,6 +1305,154 @@ func SetWindowPos(hwnd syscall.Handle, hwndInsertAfter uint32, x, y, dx, dy int3
 	)
 }
 
+// SetWindowDisplayAffinity 设置顶层窗口 hwnd 的内容在截屏和录屏中的显示方式。
+func SetWindowDisplayAffinity(hwnd syscall.Handle, affinity uint32) error {
+	r, _, err := _SetWindowDisplayAffinity.Call(uintptr(hwnd), uintptr(affinity))
+	if r == 0 {
+		return fmt.Errorf("SetWindowDisplayAffinity: %v", err)
+	}
+	return nil
+}
+
+// SetThreadExecutionState 设置调用线程的执行状态，例如用 ES_DISPLAY_REQUIRED 阻止显示器关闭。
+func SetThreadExecutionState(flags uint32) {
+	_SetThreadExecutionState.Call(uintptr(flags))
+}
+
+// SetEdgeGesturesDisabled 设置在窗口全屏时是否禁用触摸屏的边缘手势。
+func SetEdgeGesturesDisabled(hwnd syscall.Handle, disabled bool) error {
+	var store *propertyStore
+	r, _, _ := _SHGetPropertyStoreForWindow.Call(uintptr(hwnd), uintptr(unsafe.Pointer(&iidPropertyStore)), uintptr(unsafe.Pointer(&store)))
+	if r != 0 {
+		return fmt.Errorf("SHGetPropertyStoreForWindow: %#x", r)
+	}
+	defer gosyscall.Syscall(store.vtbl.Release, 1, uintptr(unsafe.Pointer(store)), 0, 0)
+	v := propVariant{vt: VT_BOOL}
+	if disabled {
+		// VARIANT_TRUE
+		v.val = 0xffff
+	}
+	r, _, _ = gosyscall.Syscall(store.vtbl.SetValue, 3, uintptr(unsafe.Pointer(store)), uintptr(unsafe.Pointer(&pkeyEdgeGestureDisableTouchWhenFullscreen)), uintptr(unsafe.Pointer(&v)))
+	if r != 0 {
+		return fmt.Errorf("IPropertyStore.SetValue: %#x", r)
+	}
+	return nil
+}
+
+// SysAllocString 分配保存 s 的 BSTR 字符串，由接收方负责释放。
+func SysAllocString(s string) uintptr {
+	r, _, _ := _SysAllocString.Call(uintptr(unsafe.Pointer(syscall.StringToUTF16Ptr(s))))
+	return r
+}
+
+// NewRuntimeId 创建 UI 自动化元素的运行时 ID，即由 UiaAppendRuntimeId 和 id
+// 组成的 SAFEARRAY，由接收方负责释放。
+func NewRuntimeId(id int32) uintptr {
+	sa, _, _ := _SafeArrayCreateVector.Call(VT_I4, 0, 2)
+	if sa == 0 {
+		return 0
+	}
+	for i, v := range [2]int32{UiaAppendRuntimeId, id} {
+		idx := int32(i)
+		_SafeArrayPutElement.Call(sa, uintptr(unsafe.Pointer(&idx)), uintptr(unsafe.Pointer(&v)))
+	}
+	return sa
+}
+
+// UiaClientsAreListening 报告是否有 UI 自动化客户端在监听事件。
+func UiaClientsAreListening() bool {
+	if uiautomationcore.Load() != nil {
+		return false
+	}
+	r, _, _ := _UiaClientsAreListening.Call()
+	return r != 0
+}
+
+// UiaHostProviderFromHwnd 返回窗口 hwnd 的宿主提供程序，它提供窗口本身的属性。
+func UiaHostProviderFromHwnd(hwnd syscall.Handle, provider *uintptr) error {
+	r, _, _ := _UiaHostProviderFromHwnd.Call(uintptr(hwnd), uintptr(unsafe.Pointer(provider)))
+	if r != S_OK {
+		return fmt.Errorf("UiaHostProviderFromHwnd: %#x", r)
+	}
+	return nil
+}
+
+// UiaReturnRawElementProvider 把窗口的提供程序 provider 作为 WM_GETOBJECT 的结果返回。
+// provider 为 nil 时通知 UI 自动化释放窗口的所有提供程序。
+func UiaReturnRawElementProvider(hwnd syscall.Handle, wParam, lParam uintptr, provider unsafe.Pointer) uintptr {
+	if uiautomationcore.Load() != nil {
+		return 0
+	}
+	r, _, _ := _UiaReturnRawElementProvider.Call(uintptr(hwnd), wParam, lParam, uintptr(provider))
+	return r
+}
+
+// UiaRaiseAutomationEvent 为提供程序 provider 发出 ID 为 id 的事件。
+func UiaRaiseAutomationEvent(provider unsafe.Pointer, id int32) {
+	_UiaRaiseAutomationEvent.Call(uintptr(provider), uintptr(id))
+}
+
+// UiaRaiseStructureChangedEvent 发出提供程序 provider 的结构变化事件，
+// runtimeID 是 provider 的运行时 ID，由宿主窗口提供运行时 ID 时为空。
+func UiaRaiseStructureChangedEvent(provider unsafe.Pointer, typ int32, runtimeID []int32) {
+	var id *int32
+	if len(runtimeID) > 0 {
+		id = &runtimeID[0]
+	}
+	_UiaRaiseStructureChangedEvent.Call(uintptr(provider), uintptr(typ), uintptr(unsafe.Pointer(id)), uintptr(len(runtimeID)))
+}
+
+// UiaRaiseAutomationPropertyChangedEvent 发出提供程序 provider 的属性 id
+// 从 old 变为 new 的事件。值为 BSTR 时在返回前释放。
+func UiaRaiseAutomationPropertyChangedEvent(provider unsafe.Pointer, id int32, old, new Variant) {
+	defer freeVariant(old)
+	defer freeVariant(new)
+	if runtime.GOARCH == "386" {
+		// stdcall 在栈上按值传递 16 字节的 VARIANT。
+		words := func(v Variant) (uintptr, uintptr, uintptr, uintptr) {
+			return uintptr(v.VT), 0, uintptr(uint32(v.Val)), uintptr(v.Val >> 32)
+		}
+		o0, o1, o2, o3 := words(old)
+		n0, n1, n2, n3 := words(new)
+		_UiaRaiseAutomationPropertyChangedEvent.Call(uintptr(provider), uintptr(id), o0, o1, o2, o3, n0, n1, n2, n3)
+		return
+	}
+	// 其它架构按引用传递 VARIANT 的副本，副本必须有完整的 VARIANT 大小。
+	type variant struct {
+		Variant
+		_ uintptr
+	}
+	o, n := variant{Variant: old}, variant{Variant: new}
+	_UiaRaiseAutomationPropertyChangedEvent.Call(uintptr(provider), uintptr(id), uintptr(unsafe.Pointer(&o)), uintptr(unsafe.Pointer(&n)))
+}
+
+func freeVariant(v Variant) {
+	if v.VT == VT_BSTR && v.Val != 0 {
+		_SysFreeString.Call(uintptr(v.Val))
+	}
+}
+
+// SetParent 把 hwnd 变为 parent 的子窗口。
+func SetParent(hwnd, parent syscall.Handle) {
+	_SetParent.Call(uintptr(hwnd), uintptr(parent))
+}
+
+// SetWindowRgn 把 hwnd 的可见区域限制为窗口坐标中的矩形 r。
+func SetWindowRgn(hwnd syscall.Handle, r Rect, redraw bool) {
+	rgn, _, _ := _CreateRectRgn.Call(uintptr(r.Left), uintptr(r.Top), uintptr(r.Right), uintptr(r.Bottom))
+	if rgn == 0 {
+		return
+	}
+	var draw uintptr
+	if redraw {
+		draw = TRUE
+	}
+	// 成功时系统拥有区域，不需要删除。
+	if ret, _, _ := _SetWindowRgn.Call(uintptr(hwnd), rgn, draw); ret == 0 {
+		_DeleteObject.Call(rgn)
+	}
+}
+
 func SetWindowText(hwnd syscall.Handle, title string) {
 	wname := syscall.StringToUTF16Ptr(title)
 	_SetWindowText.Call(uintptr(hwnd), uintptr(unsafe.Pointer(wname)))
@@ -843,6 +1470,12 @@ func GlobalFree(h syscall.Handle) {
 	_GlobalFree.Call(uintptr(h))
 }
 
+// GlobalSize 返回全局内存块的字节数
+func GlobalSize(h syscall.Handle) int {
+	r, _, _ := _GlobalSize.Call(uintptr(h))
+	return int(r)
+}
+
 func GlobalLock(h syscall.Handle) (unsafe.Pointer, error) {
 	r, _, err := _GlobalLock.Call(uintptr(h))
 	if r == 0 {
@@ -871,6 +1504,40 @@ func LoadCursor(curID uint16) (syscall.Handle, error) {
 	return syscall.Handle(h), nil
 }
 
+// CreateImageCursor 从宽 width、高 height 的 32 位 ARGB 像素 pix 创建光标，
+// 热点位于 (x, y)。光标不再使用时需要调用 DestroyCursor 销毁
+func CreateImageCursor(width, height int, pix []uint32, x, y int) (syscall.Handle, error) {
+	if len(pix) == 0 {
+		return 0, fmt.Errorf("CreateImageCursor: empty image")
+	}
+	color, _, err := _CreateBitmap.Call(uintptr(width), uintptr(height), 1, 32, uintptr(unsafe.Pointer(&pix[0])))
+	if color == 0 {
+		return 0, fmt.Errorf("CreateBitmap failed: %v", err)
+	}
+	defer _DeleteObject.Call(color)
+	// 带有 alpha 通道的光标会忽略掩码位图的内容
+	mask, _, err := _CreateBitmap.Call(uintptr(width), uintptr(height), 1, 1, 0)
+	if mask == 0 {
+		return 0, fmt.Errorf("CreateBitmap failed: %v", err)
+	}
+	defer _DeleteObject.Call(mask)
+	info := IconInfo{
+		XHotspot: uint32(x),
+		YHotspot: uint32(y),
+		Mask:     syscall.Handle(mask),
+		Color:    syscall.Handle(color),
+	}
+	h, _, err := _CreateIconIndirect.Call(uintptr(unsafe.Pointer(&info)))
+	if h == 0 {
+		return 0, fmt.Errorf("CreateIconIndirect failed: %v", err)
+	}
+	return syscall.Handle(h), nil
+}
+
+func DestroyCursor(h syscall.Handle) {
+	_DestroyCursor.Call(uintptr(h))
+}
+
 func LoadImage(hInst syscall.Handle, res uint32, typ uint32, cx, cy int, fuload uint32) (syscall.Handle, error) {
 	h, _, err := _LoadImage.Call(uintptr(hInst), uintptr(res), uintptr(typ), uintptr(cx), uintptr(cy), uintptr(fuload))
 	if h == 0 {
@@ -888,10 +1555,54 @@ func MoveWindow(hwnd syscall.Handle, x, y, width, height int32, repaint bool) {
 }
 
 func monitorFromPoint(pt Point, flags uint32) syscall.Handle {
-	r, _, _ := _MonitorFromPoint.Call(uintptr(pt.X), uintptr(pt.Y), uintptr(flags))
+	var r uintptr
+	if runtime.GOARCH == "386" {
+		r, _, _ = _MonitorFromPoint.Call(uintptr(pt.X), uintptr(pt.Y), uintptr(flags))
+	} else {
+		// 64 位平台按值传递的 POINT 结构体占用一个参数
+		r, _, _ = _MonitorFromPoint.Call(uintptr(uint32(pt.X))|uintptr(uint32(pt.Y))<<32, uintptr(flags))
+	}
 	return syscall.Handle(r)
 }
 
+// MonitorFromWindow 返回与窗口 hwnd 相交面积最大的显示器的句柄，flags 决定
+// 窗口不在任何显示器上时的返回值。
+func MonitorFromWindow(hwnd syscall.Handle, flags uint32) syscall.Handle {
+	r, _, _ := _MonitorFromWindow.Call(uintptr(hwnd), uintptr(flags))
+	return syscall.Handle(r)
+}
+
+// GetMonitorRefreshRate 返回显示器 hmon 当前显示模式的刷新率（赫兹），
+// 刷新率未知时返回 0。
+func GetMonitorRefreshRate(hmon syscall.Handle) uint32 {
+	var mi monitorInfoEx
+	mi.cbSize = uint32(unsafe.Sizeof(mi))
+	if r, _, _ := _GetMonitorInfo.Call(uintptr(hmon), uintptr(unsafe.Pointer(&mi))); r == 0 {
+		return 0
+	}
+	var dm devMode
+	dm.size = uint16(unsafe.Sizeof(dm))
+	r, _, _ := _EnumDisplaySettings.Call(uintptr(unsafe.Pointer(&mi.device[0])), ENUM_CURRENT_SETTINGS, uintptr(unsafe.Pointer(&dm)))
+	// 0 和 1 表示使用硬件的默认刷新率。
+	if r == 0 || dm.displayFrequency <= 1 {
+		return 0
+	}
+	return dm.displayFrequency
+}
+
+// GetMonitorInfoFromPoint 返回包含 pt 的显示器的信息。如果没有显示器包含 pt，
+// 返回最近的显示器的信息，并且 ok 为 false
+func GetMonitorInfoFromPoint(pt Point) (mi MonitorInfo, ok bool) {
+	mi.cbSize = uint32(unsafe.Sizeof(mi))
+	hmon := monitorFromPoint(pt, MONITOR_DEFAULTTONULL)
+	ok = hmon != 0
+	if !ok {
+		hmon = monitorFromPoint(pt, MONITOR_DEFAULTTONEAREST)
+	}
+	_GetMonitorInfo.Call(uintptr(hmon), uintptr(unsafe.Pointer(&mi)))
+	return mi, ok
+}
+
 func MsgWaitForMultipleObjectsEx(nCount uint32, pHandles uintptr, millis, mask, flags uint32) (uint32, error) {
 	r, _, err := _MsgWaitForMultipleObjectsEx.Call(uintptr(nCount), pHandles, uintptr(millis), uintptr(mask), uintptr(flags))
 	res := uint32(r)
@@ -926,6 +1637,12 @@ func PostMessage(hwnd syscall.Handle, msg uint32, wParam, lParam uintptr) error
 	return nil
 }
 
+// SendMessage 向窗口 hwnd 发送消息，并在窗口过程处理完毕后返回结果。
+func SendMessage(hwnd syscall.Handle, msg uint32, wParam, lParam uintptr) uintptr {
+	r, _, _ := _SendMessage.Call(uintptr(hwnd), uintptr(msg), wParam, lParam)
+	return r
+}
+
 func ReleaseCapture() bool {
 	r, _, _ := _ReleaseCapture.Call()
 	return r != 0
@@ -939,6 +1656,16 @@ func RegisterClassEx(cls *WndClassEx) (uint16, error) {
 	return uint16(a), nil
 }
 
+// RegisterClipboardFormat 注册名为 name 的剪贴板格式并返回其编号。
+// 同名的格式总是返回相同的编号
+func RegisterClipboardFormat(name string) (uint32, error) {
+	r, _, err := _RegisterClipboardFormatW.Call(uintptr(unsafe.Pointer(syscall.StringToUTF16Ptr(name))))
+	if r == 0 {
+		return 0, fmt.Errorf("RegisterClipboardFormatW failed: %v", err)
+	}
+	return uint32(r), nil
+}
+
 func ReleaseDC(hdc syscall.Handle) {
 	_ReleaseDC.Call(uintptr(hdc))
 }
@@ -960,6 +1687,14 @@ func SetCapture(hwnd syscall.Handle) syscall.Handle {
 	return syscall.Handle(r)
 }
 
+func SetCursorPos(x, y int32) error {
+	r, _, err := _SetCursorPos.Call(uintptr(x), uintptr(y))
+	if r == 0 {
+		return fmt.Errorf("SetCursorPos: %v", err)
+	}
+	return nil
+}
+
 func SetClipboardData(format uint32, mem syscall.Handle) error {
 	r, _, err := _SetClipboardData.Call(uintptr(format), uintptr(mem))
 	if r == 0 {
@@ -980,10 +1715,41 @@ func SetTimer(hwnd syscall.Handle, nIDEvent uintptr, uElapse uint32, timerProc u
 	return nil
 }
 
+// SetGestureConfig 启用窗口 hwnd 的所有手势
+func SetGestureConfig(hwnd syscall.Handle) {
+	cfg := GestureConfig{Want: GC_ALLGESTURES}
+	_SetGestureConfig.Call(uintptr(hwnd), 0, 1, uintptr(unsafe.Pointer(&cfg)), unsafe.Sizeof(cfg))
+}
+
+// FlashWindow 闪烁窗口 hwnd 的标题栏和任务栏按钮，直到窗口被切换到前台
+func FlashWindow(hwnd syscall.Handle) {
+	info := FlashWInfo{
+		Hwnd:  hwnd,
+		Flags: FLASHW_ALL | FLASHW_TIMERNOFG,
+	}
+	info.Size = uint32(unsafe.Sizeof(info))
+	_FlashWindowEx.Call(uintptr(unsafe.Pointer(&info)))
+}
+
 func ScreenToClient(hwnd syscall.Handle, p *Point) {
 	_ScreenToClient.Call(uintptr(hwnd), uintptr(unsafe.Pointer(p)))
 }
 
+// ClientToScreen 将窗口 hwnd 的客户区坐标 p 转换为屏幕坐标。
+func ClientToScreen(hwnd syscall.Handle, p *Point) {
+	_ClientToScreen.Call(uintptr(hwnd), uintptr(unsafe.Pointer(p)))
+}
+
+// SystemParametersInfoUint 获取类型为 UINT 的系统参数 action，失败时返回 def
+func SystemParametersInfoUint(action uint32, def uint32) uint32 {
+	var v uint32
+	r, _, _ := _SystemParametersInfo.Call(uintptr(action), 0, uintptr(unsafe.Pointer(&v)), 0)
+	if r == 0 {
+		return def
+	}
+	return v
+}
+
 func ShowWindow(hwnd syscall.Handle, nCmdShow int32) {
 	_ShowWindow.Call(uintptr(hwnd), uintptr(nCmdShow))
 }
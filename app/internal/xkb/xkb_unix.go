@@ -37,8 +37,21 @@ type Context struct {
 	compTable *C.struct_xkb_compose_table
 	compState *C.struct_xkb_compose_state
 	utf8Buf   []byte
+	// composing is set while a compose sequence is in progress,
+	// and pending is the text of its keys.
+	composing bool
+	pending   []byte
 }
 
+// ComposeEvent reports the pending text of a dead key or compose
+// sequence in progress, for display by the focused editor. An empty
+// Text ends the sequence.
+type ComposeEvent struct {
+	Text string
+}
+
+func (ComposeEvent) ImplementsEvent() {}
+
 var (
 	_XKB_MOD_NAME_CTRL  = []byte("Control\x00")
 	_XKB_MOD_NAME_SHIFT = []byte("Shift\x00")
@@ -115,7 +128,7 @@ func (x *Context) SetKeymap(xkbKeyMap, xkbState unsafe.Pointer) {
 
 func (x *Context) LoadKeymap(format int, fd int, size int) error {
 	x.DestroyKeymapState()
-	mapData, err := syscall.Mmap(int(fd), 0, int(size), syscall.PROT_READ, syscall.MAP_SHARED)
+	mapData, err := syscall.Mmap(int(fd), 0, int(size), syscall.PROT_READ, syscall.MAP_PRIVATE)
 	if err != nil {
 		return fmt.Errorf("newXKB: mmap of keymap failed: %v", err)
 	}
@@ -164,8 +177,9 @@ func (x *Context) DispatchKey(keyCode uint32, state key.State) (events []event.E
 		x.utf8Buf = make([]byte, 1)
 	}
 	sym := C.xkb_state_key_get_one_sym(x.state, kc)
+	var cmd event.Event
 	if name, ok := convertKeysym(sym); ok {
-		cmd := key.Event{
+		e := key.Event{
 			Name:      name,
 			Modifiers: x.Modifiers(),
 			State:     state,
@@ -173,15 +187,46 @@ func (x *Context) DispatchKey(keyCode uint32, state key.State) (events []event.E
 		// Ensure that a physical backtab key is translated to
 		// Shift-Tab.
 		if sym == C.XKB_KEY_ISO_Left_Tab {
-			cmd.Modifiers |= key.ModShift
+			e.Modifiers |= key.ModShift
 		}
-		events = append(events, cmd)
+		cmd = e
+	}
+	if state != key.Press {
+		if cmd != nil {
+			events = append(events, cmd)
+		}
+		return
 	}
-	C.xkb_compose_state_feed(x.compState, sym)
+	// Only presses take part in compose sequences.
+	res := C.xkb_compose_state_feed(x.compState, sym)
 	var str []byte
 	switch C.xkb_compose_state_get_status(x.compState) {
-	case C.XKB_COMPOSE_CANCELLED, C.XKB_COMPOSE_COMPOSING:
+	case C.XKB_COMPOSE_COMPOSING:
+		if cmd != nil {
+			events = append(events, cmd)
+		}
+		if res == C.XKB_COMPOSE_FEED_IGNORED {
+			// Modifier keys don't affect the sequence.
+			return
+		}
+		x.composing = true
+		x.pending = append(x.pending, x.composeText(sym, kc)...)
+		pending := string(x.pending)
+		if pending == "" {
+			// Show a placeholder for the compose key.
+			pending = "·"
+		}
+		events = append(events, ComposeEvent{Text: pending})
 		return
+	case C.XKB_COMPOSE_CANCELLED:
+		C.xkb_compose_state_reset(x.compState)
+		// Keep the accents of a sequence cancelled by a character,
+		// followed by the character. Other keys such as Escape or
+		// Backspace discard the sequence.
+		chars := x.charsForKeycode(kc)
+		if r, _ := utf8.DecodeRune(chars); len(chars) > 0 && unicode.IsPrint(r) {
+			str = append(x.pending, chars...)
+		}
 	case C.XKB_COMPOSE_COMPOSED:
 		size := C.xkb_compose_state_get_utf8(x.compState, (*C.char)(unsafe.Pointer(&x.utf8Buf[0])), C.size_t(len(x.utf8Buf)))
 		if int(size) >= len(x.utf8Buf) {
@@ -196,10 +241,20 @@ func (x *Context) DispatchKey(keyCode uint32, state key.State) (events []event.E
 			str = x.charsForKeycode(kc)
 		}
 	}
+	if x.composing {
+		// End the sequence before reporting the key, so the pending
+		// text is removed before the key is processed.
+		x.composing = false
+		x.pending = x.pending[:0]
+		events = append(events, ComposeEvent{})
+	}
+	if cmd != nil {
+		events = append(events, cmd)
+	}
 	// Report only printable runes.
 	var n int
 	for n < len(str) {
-		r, s := utf8.DecodeRune(str)
+		r, s := utf8.DecodeRune(str[n:])
 		if unicode.IsPrint(r) {
 			n += s
 		} else {
@@ -207,12 +262,53 @@ func (x *Context) DispatchKey(keyCode uint32, state key.State) (events []event.E
 			str = str[:len(str)-s]
 		}
 	}
-	if state == key.Press && len(str) > 0 {
+	if len(str) > 0 {
 		events = append(events, key.EditEvent{Text: string(str)})
 	}
 	return
 }
 
+// composeText returns the text representing the keysym sym of the key
+// kc in a pending compose sequence. Dead keys are represented by their
+// spacing accents.
+func (x *Context) composeText(sym C.xkb_keysym_t, kc C.xkb_keycode_t) []byte {
+	var r rune
+	switch sym {
+	case C.XKB_KEY_Multi_key:
+		return nil
+	case C.XKB_KEY_dead_grave:
+		r = '`'
+	case C.XKB_KEY_dead_acute:
+		r = '´'
+	case C.XKB_KEY_dead_circumflex:
+		r = '^'
+	case C.XKB_KEY_dead_tilde:
+		r = '~'
+	case C.XKB_KEY_dead_macron:
+		r = '¯'
+	case C.XKB_KEY_dead_breve:
+		r = '˘'
+	case C.XKB_KEY_dead_abovedot:
+		r = '˙'
+	case C.XKB_KEY_dead_diaeresis:
+		r = '¨'
+	case C.XKB_KEY_dead_abovering:
+		r = '˚'
+	case C.XKB_KEY_dead_doubleacute:
+		r = '˝'
+	case C.XKB_KEY_dead_caron:
+		r = 'ˇ'
+	case C.XKB_KEY_dead_cedilla:
+		r = '¸'
+	case C.XKB_KEY_dead_ogonek:
+		r = '˛'
+	default:
+		// Copy the characters, because utf8Buf is reused.
+		return append([]byte(nil), x.charsForKeycode(kc)...)
+	}
+	return []byte(string(r))
+}
+
 func (x *Context) charsForKeycode(keyCode C.xkb_keycode_t) []byte {
 	size := C.xkb_state_key_get_utf8(x.state, keyCode, (*C.char)(unsafe.Pointer(&x.utf8Buf[0])), C.size_t(len(x.utf8Buf)))
 	if int(size) >= len(x.utf8Buf) {
@@ -0,0 +1,60 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package app
+
+import (
+	"sync"
+
+	"github.com/Seikaijyu/gio/app/internal/native"
+	"github.com/Seikaijyu/gio/gpu"
+)
+
+// interop tracks the native handles of a window for package
+// unsafeinterop, which may read them from any goroutine.
+type interop struct {
+	// view is the most recent ViewEvent, if any, accessed from the
+	// driver only.
+	view *ViewEvent
+
+	mu      sync.Mutex
+	handles native.Handles
+	api     gpu.API
+}
+
+// nativeDriver is implemented by drivers with native handles.
+type nativeDriver interface {
+	// handles returns the native handles of the view of e.
+	handles(e ViewEvent) native.Handles
+}
+
+func init() {
+	native.Lookup = func(w interface{}) (native.Handles, gpu.API) {
+		i := &w.(*Window).interop
+		i.mu.Lock()
+		defer i.mu.Unlock()
+		return i.handles, i.api
+	}
+}
+
+// update the handles from the driver d and its GPU context ctx, if
+// any.
+func (i *interop) update(d driver, ctx context) {
+	var h native.Handles
+	if n, ok := d.(nativeDriver); ok && i.view != nil {
+		h = n.handles(*i.view)
+	}
+	var api gpu.API
+	if ctx != nil {
+		api = ctx.API()
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.handles, i.api = h, api
+}
+
+// releaseAPI forgets the GPU API of a released context.
+func (i *interop) releaseAPI() {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.api = nil
+}
@@ -6,12 +6,16 @@ import (
 	"errors"
 	"image"
 	"image/color"
+	"time"
 
 	"github.com/Seikaijyu/gio/io/key"
 
 	"github.com/Seikaijyu/gio/gpu"
+	"github.com/Seikaijyu/gio/io/clipboard"
 	"github.com/Seikaijyu/gio/io/pointer"
+	"github.com/Seikaijyu/gio/io/router"
 	"github.com/Seikaijyu/gio/io/system"
+	"github.com/Seikaijyu/gio/layout"
 	"github.com/Seikaijyu/gio/unit"
 )
 
@@ -43,9 +47,68 @@ type Config struct {
 	CustomRenderer bool
 	// Decorated reports whether window decorations are provided automatically.
 	Decorated bool
+	// TitleBarColor is the background color of the title bar of
+	// decorated windows. The zero value selects the platform default.
+	TitleBarColor color.NRGBA
+	// TitleColor is the color of the title of decorated windows. The
+	// zero value selects the platform default.
+	TitleColor color.NRGBA
+	// DarkDecorations requests dark window decorations.
+	DarkDecorations bool
+	// ContentProtected reports whether the window content is excluded
+	// from screenshots and screen recordings.
+	ContentProtected bool
+	// KeepScreenOn reports whether the display is kept awake and the
+	// screensaver suppressed while the window is visible.
+	KeepScreenOn bool
+	// SystemGesturesDisabled reports whether system gestures such as
+	// swipes from the screen edges are suppressed for the window.
+	SystemGesturesDisabled bool
+	// NoActivate reports whether the window declines activation when
+	// it is shown or clicked, so it doesn't take keyboard focus from
+	// the active window.
+	NoActivate bool
+	// Focused reports whether the window is active and receives
+	// keyboard input.
+	Focused bool
+	// Position is the position of the top-left corner of the window
+	// on the desktop, in screen pixels on Windows and X11 and in points
+	// on macOS. It includes the window decorations. Position is zero on
+	// other platforms.
+	Position image.Point
 	// decoHeight is the height of the fallback decoration for platforms such
 	// as Wayland that may need fallback client-side decorations.
 	decoHeight unit.Dp
+	// scheduler drives the frame timing, as set by the Scheduler and
+	// FrameClock options.
+	scheduler FrameScheduler
+	// helpTooltips and helpTooltip are set by the HelpTooltips and
+	// HelpTooltipStyle options.
+	helpTooltips bool
+	helpTooltip  func(gtx layout.Context, text string) layout.Dimensions
+	// instanceID is the id set by the SingleInstance option.
+	instanceID string
+	// parent is the native window or view to embed the window in, as
+	// given to NewWindowFrom.
+	parent uintptr
+	// headless is the frame interval given to the Headless option.
+	headless time.Duration
+	// device is the device given to the Simulate option.
+	device *layout.Device
+	// canvas is the CanvasOptions given to the Canvas option on js.
+	canvas interface{}
+	// owner and modal are set by the Owner and Modal options.
+	owner *Window
+	modal bool
+	// metric is the metric of the window when the Config was reported,
+	// or zero before its first frame.
+	metric unit.Metric
+	// monitor is the bounds of the monitor the window is on, as
+	// reported by the platform.
+	monitor image.Rectangle
+	// positionMonitor is the bounds of the monitor Position was saved
+	// on, as restored by Geometry.
+	positionMonitor image.Rectangle
 }
 
 // ConfigEvent is sent whenever the configuration of a Window changes.
@@ -161,10 +224,19 @@ type driver interface {
 	ReadClipboard()
 	// WriteClipboard requests a clipboard write.
 	WriteClipboard(s string)
+	// ReadPrimary requests the primary selection content.
+	ReadPrimary()
+	// WritePrimary requests a primary selection write.
+	WritePrimary(s string)
 	// Configure the window.
 	Configure([]Option)
 	// SetCursor updates the current cursor to name.
 	SetCursor(cursor pointer.Cursor)
+	// SetImageCursor updates the current cursor to an image.
+	SetImageCursor(cursor *pointer.ImageCursor)
+	// SetNativeViews places the native child views of the window.
+	// Views placed earlier but not in views are hidden.
+	SetNativeViews(views []router.NativeView)
 	// Wakeup wakes up the event loop and sends a WakeupEvent.
 	Wakeup()
 	// Perform actions on the window.
@@ -173,6 +245,27 @@ type driver interface {
 	EditorStateChanged(old, new editorState)
 }
 
+// richClipboardDriver is implemented by drivers that support clipboard
+// formats other than text.
+type richClipboardDriver interface {
+	// ReadClipboardContent requests the clipboard content, including
+	// the formats of types that are available.
+	ReadClipboardContent(types []string)
+	// WriteClipboardContent requests a clipboard write of text along
+	// with its other formats.
+	WriteClipboardContent(text string, content []clipboard.Content)
+}
+
+// threadedDriver is implemented by drivers whose contexts may draw and
+// present frames on a thread other than the thread delivering events.
+// The frames of such windows are rendered by a renderLoop, so a window
+// waiting for the vertical blank of its display doesn't hold up the
+// events and frames of other windows.
+type threadedDriver interface {
+	driver
+	renderThreaded()
+}
+
 type windowRendezvous struct {
 	in   chan windowAndConfig
 	out  chan windowAndConfig
@@ -221,3 +314,38 @@ func walkActions(actions system.Action, do func(system.Action)) {
 		}
 	}
 }
+
+// errEmbedUnsupported is returned by drivers that can't embed windows
+// in native windows.
+var errEmbedUnsupported = errors.New("app: NewWindowFrom is not supported on this platform")
+
+// embedParent returns the native parent given to NewWindowFrom, or 0.
+func embedParent(options []Option) uintptr {
+	var cnf Config
+	cnf.apply(unit.Metric{}, options)
+	return cnf.parent
+}
+
+// maxImageCursors is the number of native cursors a window caches for
+// image cursors. The cache is emptied, except for the current cursor,
+// when it is full.
+const maxImageCursors = 16
+
+// imageCursorARGB returns the pixels of the image of c as 32-bit ARGB
+// values, optionally with premultiplied alpha.
+func imageCursorARGB(c *pointer.ImageCursor, premultiply bool) []uint32 {
+	img := c.Image()
+	sz := img.Bounds().Size()
+	pix := make([]uint32, 0, sz.X*sz.Y)
+	for y := 0; y < sz.Y; y++ {
+		row := img.Pix[y*img.Stride : y*img.Stride+sz.X*4]
+		for i := 0; i < len(row); i += 4 {
+			r, g, b, a := uint32(row[i]), uint32(row[i+1]), uint32(row[i+2]), uint32(row[i+3])
+			if premultiply {
+				r, g, b = r*a/0xff, g*a/0xff, b*a/0xff
+			}
+			pix = append(pix, a<<24|r<<16|g<<8|b)
+		}
+	}
+	return pix
+}
@@ -80,6 +80,14 @@ static jsize jni_GetArrayLength(JNIEnv *env, jbyteArray arr) {
 	return (*env)->GetArrayLength(env, arr);
 }
 
+static jintArray jni_NewIntArray(JNIEnv *env, jsize length) {
+	return (*env)->NewIntArray(env, length);
+}
+
+static void jni_SetIntArrayRegion(JNIEnv *env, jintArray arr, jsize start, jsize len, const jint *buf) {
+	(*env)->SetIntArrayRegion(env, arr, start, len, buf);
+}
+
 static jstring jni_NewString(JNIEnv *env, const jchar *unicodeChars, jsize len) {
 	return (*env)->NewString(env, unicodeChars, len);
 }
@@ -134,6 +142,7 @@ import (
 	"unicode/utf16"
 	"unsafe"
 
+	"github.com/Seikaijyu/gio/app/internal/native"
 	"github.com/Seikaijyu/gio/internal/f32color"
 
 	"github.com/Seikaijyu/gio/f32"
@@ -155,6 +164,8 @@ type window struct {
 	dpi       int
 	fontScale float32
 	insets    pixelInsets
+	// refresh is the refresh interval of the display, or 0 if unknown.
+	refresh time.Duration
 
 	stage     system.Stage
 	started   bool
@@ -163,11 +174,25 @@ type window struct {
 	win    *C.ANativeWindow
 	config Config
 
+	// imageCursors caches global references to the PointerIcons
+	// of image cursors.
+	imageCursors map[*pointer.ImageCursor]C.jobject
+	// history is the historical samples of each pointer, reported
+	// before the event they are coalesced into.
+	history map[pointer.ID][]pointer.Sample
+
 	semantic struct {
 		hoverID router.SemanticID
 		rootID  router.SemanticID
 		focusID router.SemanticID
 		diffs   []router.SemanticID
+		// editor describes the editor with input focus, as last
+		// reported to accessibility services.
+		editor struct {
+			id        router.SemanticID
+			value     string
+			selection semantic.SelectionOp
+		}
 	}
 }
 
@@ -176,19 +201,27 @@ var gioView struct {
 	once               sync.Once
 	getDensity         C.jmethodID
 	getFontScale       C.jmethodID
+	getRefreshRate     C.jmethodID
 	showTextInput      C.jmethodID
 	hideTextInput      C.jmethodID
 	setInputHint       C.jmethodID
 	postFrameCallback  C.jmethodID
 	invalidate         C.jmethodID // requests draw, called from UI thread
 	setCursor          C.jmethodID
+	newImageCursor     C.jmethodID
+	setImageCursor     C.jmethodID
 	setOrientation     C.jmethodID
 	setNavigationColor C.jmethodID
 	setStatusColor     C.jmethodID
 	setFullscreen      C.jmethodID
+	setProtection      C.jmethodID
+	setKeepScreenOn    C.jmethodID
+	setNoGestures      C.jmethodID
 	unregister         C.jmethodID
 	sendA11yEvent      C.jmethodID
 	sendA11yChange     C.jmethodID
+	sendA11yText       C.jmethodID
+	sendA11ySelection  C.jmethodID
 	isA11yActive       C.jmethodID
 	restartInput       C.jmethodID
 	updateSelection    C.jmethodID
@@ -256,6 +289,10 @@ var android struct {
 		setEnabled C.jmethodID
 		// setAccessibilityFocused(boolean)
 		setAccessibilityFocused C.jmethodID
+		// setEditable(boolean)
+		setEditable C.jmethodID
+		// setTextSelection(int, int)
+		setTextSelection C.jmethodID
 	}
 
 	// android.graphics.Rect class.
@@ -311,6 +348,7 @@ const (
 	ACTION_ACCESSIBILITY_FOCUS       = 64
 	ACTION_CLEAR_ACCESSIBILITY_FOCUS = 128
 	ACTION_CLICK                     = 16
+	ACTION_FOCUS                     = 1
 )
 
 func (w *window) NewContext() (context, error) {
@@ -420,6 +458,8 @@ func initJVM(env *C.JNIEnv, gio C.jclass, ctx C.jobject) {
 	android.accessibilityNodeInfo.setChecked = getMethodID(env, cls, "setChecked", "(Z)V")
 	android.accessibilityNodeInfo.setEnabled = getMethodID(env, cls, "setEnabled", "(Z)V")
 	android.accessibilityNodeInfo.setAccessibilityFocused = getMethodID(env, cls, "setAccessibilityFocused", "(Z)V")
+	android.accessibilityNodeInfo.setEditable = getMethodID(env, cls, "setEditable", "(Z)V")
+	android.accessibilityNodeInfo.setTextSelection = getMethodID(env, cls, "setTextSelection", "(II)V")
 
 	cls = findClass(env, "android/graphics/Rect")
 	android.rect.cls = C.jclass(C.jni_NewGlobalRef(env, C.jobject(cls)))
@@ -465,19 +505,27 @@ func Java_org_gioui_GioView_onCreateView(env *C.JNIEnv, class C.jclass, view C.j
 		m := &gioView
 		m.getDensity = getMethodID(env, class, "getDensity", "()I")
 		m.getFontScale = getMethodID(env, class, "getFontScale", "()F")
+		m.getRefreshRate = getMethodID(env, class, "getRefreshRate", "()F")
 		m.showTextInput = getMethodID(env, class, "showTextInput", "()V")
 		m.hideTextInput = getMethodID(env, class, "hideTextInput", "()V")
 		m.setInputHint = getMethodID(env, class, "setInputHint", "(I)V")
 		m.postFrameCallback = getMethodID(env, class, "postFrameCallback", "()V")
 		m.invalidate = getMethodID(env, class, "invalidate", "()V")
 		m.setCursor = getMethodID(env, class, "setCursor", "(I)V")
+		m.newImageCursor = getMethodID(env, class, "newImageCursor", "([IIIII)Ljava/lang/Object;")
+		m.setImageCursor = getMethodID(env, class, "setImageCursor", "(Ljava/lang/Object;)V")
 		m.setOrientation = getMethodID(env, class, "setOrientation", "(II)V")
 		m.setNavigationColor = getMethodID(env, class, "setNavigationColor", "(II)V")
 		m.setStatusColor = getMethodID(env, class, "setStatusColor", "(II)V")
 		m.setFullscreen = getMethodID(env, class, "setFullscreen", "(Z)V")
+		m.setProtection = getMethodID(env, class, "setContentProtection", "(Z)V")
+		m.setKeepScreenOn = getMethodID(env, class, "setKeepScreenOn", "(Z)V")
+		m.setNoGestures = getMethodID(env, class, "setSystemGesturesDisabled", "(Z)V")
 		m.unregister = getMethodID(env, class, "unregister", "()V")
 		m.sendA11yEvent = getMethodID(env, class, "sendA11yEvent", "(II)V")
 		m.sendA11yChange = getMethodID(env, class, "sendA11yChange", "(I)V")
+		m.sendA11yText = getMethodID(env, class, "sendA11yText", "(ILjava/lang/String;Ljava/lang/String;III)V")
+		m.sendA11ySelection = getMethodID(env, class, "sendA11ySelection", "(ILjava/lang/String;II)V")
 		m.isA11yActive = getMethodID(env, class, "isA11yActive", "()Z")
 		m.restartInput = getMethodID(env, class, "restartInput", "()V")
 		m.updateSelection = getMethodID(env, class, "updateSelection", "()V")
@@ -539,6 +587,7 @@ func Java_org_gioui_GioView_onSurfaceDestroyed(env *C.JNIEnv, class C.jclass, ha
 func Java_org_gioui_GioView_onSurfaceChanged(env *C.JNIEnv, class C.jclass, handle C.jlong, surf C.jobject) {
 	w := cgo.Handle(handle).Value().(*window)
 	w.win = C.ANativeWindow_fromSurface(env, surf)
+	w.loadRefreshRate(env)
 	if w.started {
 		w.setVisible(env)
 	}
@@ -661,6 +710,18 @@ func Java_org_gioui_GioView_onClearA11yFocus(env *C.JNIEnv, class C.jclass, view
 	}
 }
 
+//export Java_org_gioui_GioView_onA11yClick
+func Java_org_gioui_GioView_onA11yClick(env *C.JNIEnv, class C.jclass, view C.jlong, virtID C.jint) C.jboolean {
+	w := cgo.Handle(view).Value().(*window)
+	return javaBool(w.callbacks.ClickSemantic(w.semIDFor(virtID)))
+}
+
+//export Java_org_gioui_GioView_onA11yInputFocus
+func Java_org_gioui_GioView_onA11yInputFocus(env *C.JNIEnv, class C.jclass, view C.jlong, virtID C.jint) C.jboolean {
+	w := cgo.Handle(view).Value().(*window)
+	return javaBool(w.callbacks.FocusSemantic(w.semIDFor(virtID)))
+}
+
 func (w *window) initAccessibilityNodeInfo(env *C.JNIEnv, sem router.SemanticNode, off image.Point, info C.jobject) error {
 	for _, ch := range sem.Children {
 		err := callVoidMethod(env, info, android.accessibilityNodeInfo.addChild, jvalue(w.view), jvalue(w.virtualIDFor(ch.ID)))
@@ -687,14 +748,40 @@ func (w *window) initAccessibilityNodeInfo(env *C.JNIEnv, sem router.SemanticNod
 		}
 	}
 	d := sem.Desc
-	if l := d.Label; l != "" {
+	if d.Class == semantic.Editor {
+		// Report the editor content as text, so screen readers can
+		// follow the caret.
+		jval := javaString(env, d.Value)
+		if err := callVoidMethod(env, info, android.accessibilityNodeInfo.setText, jvalue(jval)); err != nil {
+			return err
+		}
+		if err := callVoidMethod(env, info, android.accessibilityNodeInfo.setEditable, jvalue(javaBool(true))); err != nil {
+			return err
+		}
+		start, end := utf16Index(d.Value, d.Selection.Start), utf16Index(d.Value, d.Selection.End)
+		if err := callVoidMethod(env, info, android.accessibilityNodeInfo.setTextSelection, jvalue(start), jvalue(end)); err != nil {
+			return err
+		}
+	} else if l := d.Label; l != "" {
 		jlbl := javaString(env, l)
 		if err := callVoidMethod(env, info, android.accessibilityNodeInfo.setText, jvalue(jlbl)); err != nil {
 			return err
 		}
 	}
-	if d.Description != "" {
-		jd := javaString(env, d.Description)
+	desc := d.Description
+	if h := d.Help; h != "" {
+		// Report the help text as part of the description, after
+		// the name of the component.
+		if desc == "" && d.Class != semantic.Editor {
+			desc = d.Label
+		}
+		if desc != "" {
+			desc += ", "
+		}
+		desc += h
+	}
+	if desc != "" {
+		jd := javaString(env, desc)
 		if err := callVoidMethod(env, info, android.accessibilityNodeInfo.setContentDescription, jvalue(jd)); err != nil {
 			return err
 		}
@@ -707,6 +794,9 @@ func (w *window) initAccessibilityNodeInfo(env *C.JNIEnv, sem router.SemanticNod
 	if d.Gestures&router.ClickGesture != 0 {
 		addAction(ACTION_CLICK)
 	}
+	if d.Class == semantic.Editor {
+		addAction(ACTION_FOCUS)
+	}
 	clsName := android.strings.androidViewView
 	selectMethod := android.accessibilityNodeInfo.setChecked
 	checkable := false
@@ -769,6 +859,10 @@ func (w *window) detach(env *C.JNIEnv) {
 	w.callbacks.Event(ViewEvent{})
 	w.callbacks.SetDriver(nil)
 	w.handle.Delete()
+	for _, c := range w.imageCursors {
+		C.jni_DeleteGlobalRef(env, c)
+	}
+	w.imageCursors = nil
 	C.jni_DeleteGlobalRef(env, w.view)
 	w.view = 0
 }
@@ -805,6 +899,7 @@ func (w *window) nativeWindow() (*C.ANativeWindow, int, int) {
 func (w *window) loadConfig(env *C.JNIEnv, class C.jclass) {
 	dpi := int(C.jni_CallIntMethod(env, w.view, gioView.getDensity))
 	w.fontScale = float32(C.jni_CallFloatMethod(env, w.view, gioView.getFontScale))
+	w.loadRefreshRate(env)
 	switch dpi {
 	case C.ACONFIGURATION_DENSITY_NONE,
 		C.ACONFIGURATION_DENSITY_DEFAULT,
@@ -816,6 +911,15 @@ func (w *window) loadConfig(env *C.JNIEnv, class C.jclass) {
 	}
 }
 
+// loadRefreshRate updates the refresh interval from the display of the
+// view, which is known only while the view is attached.
+func (w *window) loadRefreshRate(env *C.JNIEnv) {
+	w.refresh = 0
+	if hz := float64(C.jni_CallFloatMethod(env, w.view, gioView.getRefreshRate)); hz > 0 {
+		w.refresh = time.Duration(float64(time.Second) / hz)
+	}
+}
+
 func (w *window) SetAnimating(anim bool) {
 	w.animating = anim
 	if anim {
@@ -845,9 +949,10 @@ func (w *window) draw(env *C.JNIEnv, sync bool) {
 	}
 	w.callbacks.Event(frameEvent{
 		FrameEvent: system.FrameEvent{
-			Now:    time.Now(),
-			Size:   w.config.Size,
-			Insets: insets,
+			Now:             time.Now(),
+			RefreshInterval: w.refresh,
+			Size:            w.config.Size,
+			Insets:          insets,
 			Metric: unit.Metric{
 				PxPerDp: ppdp,
 				PxPerSp: w.fontScale * ppdp,
@@ -875,7 +980,79 @@ func (w *window) draw(env *C.JNIEnv, sync bool) {
 		for _, id := range w.semantic.diffs {
 			callVoidMethod(env, w.view, gioView.sendA11yChange, jvalue(w.virtualIDFor(id)))
 		}
+		w.updateA11yEditor(env)
+	}
+}
+
+// updateA11yEditor reports changes to the content and selection of the
+// focused editor, for screen readers to speak text editing and caret
+// navigation.
+func (w *window) updateA11yEditor(env *C.JNIEnv) {
+	ed := &w.semantic.editor
+	id, ok := w.callbacks.SemanticFocus()
+	if !ok {
+		ed.id = 0
+		return
+	}
+	sem, found := w.callbacks.LookupSemantic(id)
+	if !found || sem.Desc.Class != semantic.Editor {
+		ed.id = 0
+		return
+	}
+	d := sem.Desc
+	if ed.id != id {
+		// Focus moved; don't report the content as changed.
+		ed.id = id
+		ed.value = d.Value
+		ed.selection = d.Selection
+		return
+	}
+	virtID := w.virtualIDFor(id)
+	if d.Value != ed.value {
+		from, removed, added := utf16Diff(ed.value, d.Value)
+		before, after := javaString(env, ed.value), javaString(env, d.Value)
+		callVoidMethod(env, w.view, gioView.sendA11yText, jvalue(virtID), jvalue(before), jvalue(after), jvalue(from), jvalue(removed), jvalue(added))
+	}
+	if d.Value != ed.value || d.Selection != ed.selection {
+		start, end := utf16Index(d.Value, d.Selection.Start), utf16Index(d.Value, d.Selection.End)
+		text := javaString(env, d.Value)
+		callVoidMethod(env, w.view, gioView.sendA11ySelection, jvalue(virtID), jvalue(text), jvalue(start), jvalue(end))
+	}
+	ed.value = d.Value
+	ed.selection = d.Selection
+}
+
+// utf16Index converts the rune offset runes in s to an offset in UTF-16
+// code units, as used by Java strings.
+func utf16Index(s string, runes int) int {
+	idx := 0
+	for _, r := range s {
+		if runes <= 0 {
+			break
+		}
+		idx++
+		if r >= 0x10000 {
+			// Surrogate pair.
+			idx++
+		}
+		runes--
 	}
+	return idx
+}
+
+// utf16Diff returns the UTF-16 offset of the first difference between
+// before and after, along with the number of UTF-16 code units removed
+// from before and added by after at that offset.
+func utf16Diff(before, after string) (from, removed, added int) {
+	b, a := utf16.Encode([]rune(before)), utf16.Encode([]rune(after))
+	for from < len(b) && from < len(a) && b[from] == a[from] {
+		from++
+	}
+	end := 0
+	for end < len(b)-from && end < len(a)-from && b[len(b)-1-end] == a[len(a)-1-end] {
+		end++
+	}
+	return from, len(b) - from - end, len(a) - from - end
 }
 
 func runInJVM(jvm *C.JavaVM, f func(env *C.JNIEnv)) {
@@ -951,9 +1128,10 @@ func Java_org_gioui_GioView_onKeyEvent(env *C.JNIEnv, class C.jclass, handle C.j
 }
 
 //export Java_org_gioui_GioView_onTouchEvent
-func Java_org_gioui_GioView_onTouchEvent(env *C.JNIEnv, class C.jclass, handle C.jlong, action, pointerID, tool C.jint, x, y, scrollX, scrollY C.jfloat, jbtns C.jint, t C.jlong) {
+func Java_org_gioui_GioView_onTouchEvent(env *C.JNIEnv, class C.jclass, handle C.jlong, action, pointerID, tool C.jint, x, y, scrollX, scrollY, pressure, tilt, orientation C.jfloat, jbtns C.jint, t C.jlong) {
 	w := cgo.Handle(handle).Value().(*window)
 	var kind pointer.Kind
+	hover := false
 	switch action {
 	case C.AMOTION_EVENT_ACTION_DOWN, C.AMOTION_EVENT_ACTION_POINTER_DOWN:
 		kind = pointer.Press
@@ -963,6 +1141,12 @@ func Java_org_gioui_GioView_onTouchEvent(env *C.JNIEnv, class C.jclass, handle C
 		kind = pointer.Cancel
 	case C.AMOTION_EVENT_ACTION_MOVE:
 		kind = pointer.Move
+	case C.AMOTION_EVENT_ACTION_HOVER_ENTER, C.AMOTION_EVENT_ACTION_HOVER_MOVE:
+		kind = pointer.Move
+		hover = true
+	case C.AMOTION_EVENT_ACTION_HOVER_EXIT:
+		// The pointer left the view, or the pen moved out of range.
+		kind = pointer.Leave
 	case C.AMOTION_EVENT_ACTION_SCROLL:
 		kind = pointer.Scroll
 	default:
@@ -979,11 +1163,19 @@ func Java_org_gioui_GioView_onTouchEvent(env *C.JNIEnv, class C.jclass, handle C
 	if jbtns&C.AMOTION_EVENT_BUTTON_TERTIARY != 0 {
 		btns |= pointer.ButtonTertiary
 	}
+	if jbtns&C.AMOTION_EVENT_BUTTON_STYLUS_PRIMARY != 0 {
+		btns |= pointer.ButtonSecondary
+	}
+	if jbtns&C.AMOTION_EVENT_BUTTON_STYLUS_SECONDARY != 0 {
+		btns |= pointer.ButtonTertiary
+	}
 	switch tool {
 	case C.AMOTION_EVENT_TOOL_TYPE_FINGER:
 		src = pointer.Touch
 	case C.AMOTION_EVENT_TOOL_TYPE_STYLUS:
-		src = pointer.Touch
+		src = pointer.Stylus
+	case C.AMOTION_EVENT_TOOL_TYPE_ERASER:
+		src = pointer.Eraser
 	case C.AMOTION_EVENT_TOOL_TYPE_MOUSE:
 		src = pointer.Mouse
 	case C.AMOTION_EVENT_TOOL_TYPE_UNKNOWN:
@@ -993,7 +1185,7 @@ func Java_org_gioui_GioView_onTouchEvent(env *C.JNIEnv, class C.jclass, handle C
 	default:
 		return
 	}
-	w.callbacks.Event(pointer.Event{
+	e := pointer.Event{
 		Kind:      kind,
 		Source:    src,
 		Buttons:   btns,
@@ -1001,7 +1193,57 @@ func Java_org_gioui_GioView_onTouchEvent(env *C.JNIEnv, class C.jclass, handle C
 		Time:      time.Duration(t) * time.Millisecond,
 		Position:  f32.Point{X: float32(x), Y: float32(y)},
 		Scroll:    f32.Pt(float32(scrollX), float32(scrollY)),
-	})
+	}
+	if hist := w.history[e.PointerID]; len(hist) > 0 {
+		delete(w.history, e.PointerID)
+		if src != pointer.Stylus && src != pointer.Eraser {
+			for i := range hist {
+				hist[i].Pressure, hist[i].TiltX, hist[i].TiltY = 0, 0, 0
+			}
+		}
+		e.History = hist
+	}
+	if kind == pointer.Scroll {
+		// Android scrolls wheels by ticks, scaled to pixels by the
+		// scroll factor of the platform.
+		e.ScrollMode = pointer.ScrollLines
+	}
+	if src == pointer.Stylus || src == pointer.Eraser {
+		if kind == pointer.Press || kind == pointer.Move && !hover {
+			// Report pen contact as a primary button press.
+			e.Buttons |= pointer.ButtonPrimary
+		}
+		e.Pressure = float32(math.Min(math.Max(float64(pressure), 0), 1))
+		e.TiltX, e.TiltY = androidTilt(float64(tilt), float64(orientation))
+	}
+	w.callbacks.Event(e)
+}
+
+//export Java_org_gioui_GioView_onTouchHistory
+func Java_org_gioui_GioView_onTouchHistory(env *C.JNIEnv, class C.jclass, handle C.jlong, pointerID C.jint, x, y, pressure, tilt, orientation C.jfloat, t C.jlong) {
+	w := cgo.Handle(handle).Value().(*window)
+	s := pointer.Sample{
+		Time:     time.Duration(t) * time.Millisecond,
+		Position: f32.Point{X: float32(x), Y: float32(y)},
+		Pressure: float32(math.Min(math.Max(float64(pressure), 0), 1)),
+	}
+	s.TiltX, s.TiltY = androidTilt(float64(tilt), float64(orientation))
+	if w.history == nil {
+		w.history = make(map[pointer.ID][]pointer.Sample)
+	}
+	id := pointer.ID(pointerID)
+	w.history[id] = append(w.history[id], s)
+}
+
+// androidTilt converts the tilt and orientation angles in radians
+// of an Android stylus into the tilt angles in degrees along the
+// screen axes. An orientation of 0 means the stylus points up, and
+// increasing orientations rotate the stylus clockwise.
+func androidTilt(tilt, orientation float64) (float32, float32) {
+	t := math.Tan(tilt)
+	tx := math.Atan(t*math.Sin(orientation)) * 180 / math.Pi
+	ty := -math.Atan(t*math.Cos(orientation)) * 180 / math.Pi
+	return float32(tx), float32(ty)
 }
 
 //export Java_org_gioui_GioView_imeSelectionStart
@@ -1134,6 +1376,17 @@ func (w *window) EditorStateChanged(old, new editorState) {
 	})
 }
 
+func (w *window) handles(e ViewEvent) native.Handles {
+	if e.View == 0 {
+		return native.Handles{}
+	}
+	return native.Handles{
+		Platform: native.Android,
+		View:     e.View,
+		Surface:  unsafe.Pointer(w.win),
+	}
+}
+
 func (w *window) ShowTextInput(show bool) {
 	runInJVM(javaVM(), func(env *C.JNIEnv) {
 		if show {
@@ -1292,6 +1545,11 @@ func osMain() {
 }
 
 func newWindow(window *callbacks, options []Option) error {
+	if embedParent(options) != 0 {
+		// Host applications embed Gio by adding a GioView to their
+		// layouts.
+		return errors.New("android: NewWindowFrom is not supported; add a GioView to the host layout instead")
+	}
 	mainWindow.in <- windowAndConfig{window, options}
 	return <-mainWindow.errs
 }
@@ -1316,6 +1574,14 @@ func (w *window) ReadClipboard() {
 	})
 }
 
+// ReadPrimary delivers an empty Event; Android has no primary
+// selection.
+func (w *window) ReadPrimary() {
+	w.callbacks.Event(clipboard.Event{Primary: true})
+}
+
+func (w *window) WritePrimary(s string) {}
+
 func (w *window) Configure(options []Option) {
 	runInJVM(javaVM(), func(env *C.JNIEnv) {
 		prev := w.config
@@ -1349,6 +1615,30 @@ func (w *window) Configure(options []Option) {
 		if cnf.Decorated != prev.Decorated {
 			w.config.Decorated = cnf.Decorated
 		}
+		if cnf.ContentProtected != prev.ContentProtected {
+			w.config.ContentProtected = cnf.ContentProtected
+			protect := jvalue(C.JNI_FALSE)
+			if cnf.ContentProtected {
+				protect = C.JNI_TRUE
+			}
+			callVoidMethod(env, w.view, gioView.setProtection, protect)
+		}
+		if cnf.KeepScreenOn != prev.KeepScreenOn {
+			w.config.KeepScreenOn = cnf.KeepScreenOn
+			on := jvalue(C.JNI_FALSE)
+			if cnf.KeepScreenOn {
+				on = C.JNI_TRUE
+			}
+			callVoidMethod(env, w.view, gioView.setKeepScreenOn, on)
+		}
+		if cnf.SystemGesturesDisabled != prev.SystemGesturesDisabled {
+			w.config.SystemGesturesDisabled = cnf.SystemGesturesDisabled
+			disabled := jvalue(C.JNI_FALSE)
+			if cnf.SystemGesturesDisabled {
+				disabled = C.JNI_TRUE
+			}
+			callVoidMethod(env, w.view, gioView.setNoGestures, disabled)
+		}
 		w.callbacks.Event(ConfigEvent{Config: w.config})
 	})
 }
@@ -1361,6 +1651,37 @@ func (w *window) SetCursor(cursor pointer.Cursor) {
 	})
 }
 
+func (w *window) SetImageCursor(cursor *pointer.ImageCursor) {
+	runInJVM(javaVM(), func(env *C.JNIEnv) {
+		c, ok := w.imageCursors[cursor]
+		if !ok {
+			c = newImageCursor(env, w.view, cursor)
+			if c == 0 {
+				setCursor(env, w.view, pointer.CursorDefault)
+				return
+			}
+			if w.imageCursors == nil {
+				w.imageCursors = make(map[*pointer.ImageCursor]C.jobject)
+			}
+			w.imageCursors[cursor] = c
+		}
+		callVoidMethod(env, w.view, gioView.setImageCursor, jvalue(c))
+		if len(w.imageCursors) > maxImageCursors {
+			for k, v := range w.imageCursors {
+				if k != cursor {
+					C.jni_DeleteGlobalRef(env, v)
+					delete(w.imageCursors, k)
+				}
+			}
+		}
+	})
+}
+
+// SetNativeViews is not supported, because the Gio view is a SurfaceView
+// without child views. Native views are ignored, as documented in package
+// native.
+func (w *window) SetNativeViews(views []router.NativeView) {}
+
 func (w *window) Wakeup() {
 	runOnMain(func(env *C.JNIEnv) {
 		w.callbacks.Event(wakeupEvent{})
@@ -1401,6 +1722,27 @@ func setCursor(env *C.JNIEnv, view C.jobject, cursor pointer.Cursor) {
 	callVoidMethod(env, view, gioView.setCursor, jvalue(curID))
 }
 
+// newImageCursor returns a global reference to a PointerIcon for
+// cursor, or 0 if the platform doesn't support image cursors.
+func newImageCursor(env *C.JNIEnv, view C.jobject, cursor *pointer.ImageCursor) C.jobject {
+	sz := cursor.Image().Bounds().Size()
+	pix := imageCursorARGB(cursor, false)
+	if len(pix) == 0 {
+		return 0
+	}
+	arr := C.jni_NewIntArray(env, C.jsize(len(pix)))
+	if arr == 0 {
+		return 0
+	}
+	C.jni_SetIntArrayRegion(env, arr, 0, C.jsize(len(pix)), (*C.jint)(unsafe.Pointer(&pix[0])))
+	hot := cursor.Hotspot()
+	icon, err := callObjectMethod(env, view, gioView.newImageCursor, jvalue(arr), jvalue(sz.X), jvalue(sz.Y), jvalue(hot.X), jvalue(hot.Y))
+	if err != nil || icon == 0 {
+		return 0
+	}
+	return C.jni_NewGlobalRef(env, icon)
+}
+
 func setOrientation(env *C.JNIEnv, view C.jobject, mode Orientation) {
 	var (
 		id         int
@@ -11,6 +11,7 @@ __attribute__ ((visibility ("hidden"))) void gio_releaseDisplayLink(CFTypeRef dl
 __attribute__ ((visibility ("hidden"))) int gio_startDisplayLink(CFTypeRef dl);
 __attribute__ ((visibility ("hidden"))) int gio_stopDisplayLink(CFTypeRef dl);
 __attribute__ ((visibility ("hidden"))) void gio_setDisplayLinkDisplay(CFTypeRef dl, uint64_t did);
+__attribute__ ((visibility ("hidden"))) double gio_displayLinkRefreshPeriod(CFTypeRef dl);
 __attribute__ ((visibility ("hidden"))) void gio_hideCursor();
 __attribute__ ((visibility ("hidden"))) void gio_showCursor();
 __attribute__ ((visibility ("hidden"))) void gio_setCursor(NSUInteger curID);
@@ -58,6 +59,8 @@ import (
 // start, stop and start again within a short duration.
 type displayLink struct {
 	callback func()
+	// dl is the native display link.
+	dl C.CFTypeRef
 	// states is for starting or stopping the display link.
 	states chan bool
 	// done is closed when the display link is destroyed.
@@ -135,6 +138,7 @@ func newDisplayLink(callback func()) (*displayLink, error) {
 	if dl == 0 {
 		return nil, errors.New("app: failed to create display link")
 	}
+	d.dl = dl
 	go d.run(dl)
 	return d, nil
 }
@@ -194,6 +198,13 @@ func (d *displayLink) Stop() {
 	d.states <- false
 }
 
+// RefreshInterval returns the refresh interval of the display driving
+// the link, or zero if unknown.
+func (d *displayLink) RefreshInterval() time.Duration {
+	p := float64(C.gio_displayLinkRefreshPeriod(d.dl))
+	return time.Duration(p * float64(time.Second))
+}
+
 func (d *displayLink) Close() {
 	close(d.done)
 }
@@ -36,6 +36,12 @@ static CFTypeRef readClipboard(void) {
 	}
 }
 
+__attribute__ ((visibility ("hidden"))) void gio_setSystemGesturesDisabled(CFTypeRef controllerRef, int disabled);
+
+static void setIdleTimerDisabled(int disabled) {
+	[UIApplication sharedApplication].idleTimerDisabled = disabled ? YES : NO;
+}
+
 static void showTextInput(CFTypeRef viewRef) {
 	UIView *view = (__bridge UIView *)viewRef;
 	[view becomeFirstResponder];
@@ -46,6 +52,50 @@ static void hideTextInput(CFTypeRef viewRef) {
 	[view resignFirstResponder];
 }
 
+// newNativeViewContainer adds a container for the native view child to
+// view. The container clips child to its bounds.
+static CFTypeRef newNativeViewContainer(CFTypeRef viewRef, CFTypeRef childRef) {
+	UIView *view = (__bridge UIView *)viewRef;
+	UIView *child = (__bridge UIView *)childRef;
+	UIView *container = [[UIView alloc] initWithFrame:CGRectZero];
+	container.clipsToBounds = YES;
+	container.hidden = YES;
+	[child removeFromSuperview];
+	child.autoresizingMask = UIViewAutoresizingNone;
+	[container addSubview:child];
+	[view addSubview:container];
+	return CFBridgingRetain(container);
+}
+
+// placeNativeView moves the container to the clip rectangle (cx, cy, cw, ch)
+// and its child to the rectangle (x, y, w, h), and moves it to the top of
+// the subviews of view. The rectangles are in points from the top left
+// corner of view.
+static void placeNativeView(CFTypeRef viewRef, CFTypeRef containerRef, CGFloat x, CGFloat y, CGFloat w, CGFloat h, CGFloat cx, CGFloat cy, CGFloat cw, CGFloat ch) {
+	UIView *view = (__bridge UIView *)viewRef;
+	UIView *container = (__bridge UIView *)containerRef;
+	UIView *child = container.subviews.firstObject;
+	container.frame = CGRectMake(cx, cy, cw, ch);
+	child.frame = CGRectMake(x-cx, y-cy, w, h);
+	[view bringSubviewToFront:container];
+	container.hidden = NO;
+}
+
+static void hideNativeView(CFTypeRef containerRef) {
+	UIView *container = (__bridge UIView *)containerRef;
+	container.hidden = YES;
+}
+
+static void releaseNativeView(CFTypeRef containerRef) {
+	UIView *container = (__bridge_transfer UIView *)containerRef;
+	[container removeFromSuperview];
+}
+
+static CGFloat viewScale(CFTypeRef viewRef) {
+	UIView *v = (__bridge UIView *)viewRef;
+	return v.layer.contentsScale;
+}
+
 static struct drawParams viewDrawParams(CFTypeRef viewRef) {
 	UIView *v = (__bridge UIView *)viewRef;
 	struct drawParams params;
@@ -78,10 +128,12 @@ import (
 	"unicode/utf16"
 	"unsafe"
 
+	"github.com/Seikaijyu/gio/app/internal/native"
 	"github.com/Seikaijyu/gio/f32"
 	"github.com/Seikaijyu/gio/io/clipboard"
 	"github.com/Seikaijyu/gio/io/key"
 	"github.com/Seikaijyu/gio/io/pointer"
+	"github.com/Seikaijyu/gio/io/router"
 	"github.com/Seikaijyu/gio/io/system"
 	"github.com/Seikaijyu/gio/unit"
 )
@@ -93,6 +145,7 @@ type ViewEvent struct {
 
 type window struct {
 	view        C.CFTypeRef
+	controller  C.CFTypeRef
 	w           *callbacks
 	displayLink *displayLink
 
@@ -101,6 +154,10 @@ type window struct {
 	config  Config
 
 	pointerMap []C.CFTypeRef
+
+	// nativeViews maps native child views to the containers that
+	// clip them.
+	nativeViews map[C.CFTypeRef]C.CFTypeRef
 }
 
 var mainWindow = newWindowRendezvous()
@@ -115,7 +172,8 @@ func init() {
 //export onCreate
 func onCreate(view, controller C.CFTypeRef) {
 	w := &window{
-		view: view,
+		view:       view,
+		controller: controller,
 	}
 	dl, err := newDisplayLink(func() {
 		w.draw(false)
@@ -157,7 +215,8 @@ func (w *window) draw(sync bool) {
 	dppp := unit.Dp(1. / m.PxPerDp)
 	w.w.Event(frameEvent{
 		FrameEvent: system.FrameEvent{
-			Now: time.Now(),
+			Now:             time.Now(),
+			RefreshInterval: w.displayLink.RefreshInterval(),
 			Size: image.Point{
 				X: int(params.width + .5),
 				Y: int(params.height + .5),
@@ -188,7 +247,12 @@ func onDestroy(view C.CFTypeRef) {
 	w.w.Event(ViewEvent{})
 	w.w.Event(system.DestroyEvent{})
 	w.displayLink.Close()
+	w.releaseNativeViews()
+	if w.config.KeepScreenOn {
+		C.setIdleTimerDisabled(C.int(0))
+	}
 	w.view = 0
+	w.controller = 0
 }
 
 //export onFocus
@@ -277,9 +341,36 @@ func (w *window) WriteClipboard(s string) {
 	C.writeClipboard(chars, C.NSUInteger(len(u16)))
 }
 
-func (w *window) Configure([]Option) {
+// ReadPrimary delivers an empty Event; iOS has no primary
+// selection.
+func (w *window) ReadPrimary() {
+	w.w.Event(clipboard.Event{Primary: true})
+}
+
+func (w *window) WritePrimary(s string) {}
+
+func (w *window) Configure(options []Option) {
+	prev := w.config
+	cnf := w.config
+	cnf.apply(unit.Metric{}, options)
 	// Decorations are never disabled.
 	w.config.Decorated = true
+	if cnf.KeepScreenOn != prev.KeepScreenOn {
+		w.config.KeepScreenOn = cnf.KeepScreenOn
+		disabled := C.int(0)
+		if cnf.KeepScreenOn {
+			disabled = 1
+		}
+		C.setIdleTimerDisabled(disabled)
+	}
+	if cnf.SystemGesturesDisabled != prev.SystemGesturesDisabled {
+		w.config.SystemGesturesDisabled = cnf.SystemGesturesDisabled
+		disabled := C.int(0)
+		if cnf.SystemGesturesDisabled {
+			disabled = 1
+		}
+		C.gio_setSystemGesturesDisabled(w.controller, disabled)
+	}
 	w.w.Event(ConfigEvent{Config: w.config})
 }
 
@@ -303,6 +394,57 @@ func (w *window) SetCursor(cursor pointer.Cursor) {
 	w.cursor = windowSetCursor(w.cursor, cursor)
 }
 
+// SetImageCursor is not supported; the default cursor is used instead.
+func (w *window) SetImageCursor(cursor *pointer.ImageCursor) {
+	w.SetCursor(pointer.CursorDefault)
+}
+
+// SetNativeViews is not supported; native views are ignored.
+// SetNativeViews places the native views, moving each to the top such
+// that they end up stacked in the order of views.
+func (w *window) SetNativeViews(views []router.NativeView) {
+	placed := make(map[C.CFTypeRef]bool, len(views))
+	scale := float32(C.viewScale(w.view))
+	for _, v := range views {
+		child := C.CFTypeRef(v.Handle)
+		placed[child] = true
+		container, ok := w.nativeViews[child]
+		if !ok {
+			container = C.newNativeViewContainer(w.view, child)
+			if w.nativeViews == nil {
+				w.nativeViews = make(map[C.CFTypeRef]C.CFTypeRef)
+			}
+			w.nativeViews[child] = container
+		}
+		b := v.Bounds
+		clip := v.Clip.Intersect(b)
+		if clip.Empty() {
+			C.hideNativeView(container)
+			continue
+		}
+		// Convert from pixels to points.
+		pt := func(v int) C.CGFloat {
+			return C.CGFloat(float32(v) / scale)
+		}
+		C.placeNativeView(w.view, container,
+			pt(b.Min.X), pt(b.Min.Y), pt(b.Dx()), pt(b.Dy()),
+			pt(clip.Min.X), pt(clip.Min.Y), pt(clip.Dx()), pt(clip.Dy()),
+		)
+	}
+	for child, container := range w.nativeViews {
+		if !placed[child] {
+			C.hideNativeView(container)
+		}
+	}
+}
+
+func (w *window) releaseNativeViews() {
+	for _, c := range w.nativeViews {
+		C.releaseNativeView(c)
+	}
+	w.nativeViews = nil
+}
+
 func (w *window) onKeyCommand(name string) {
 	w.w.Event(key.Event{
 		Name: name,
@@ -333,6 +475,17 @@ func (w *window) contextView() C.CFTypeRef {
 	return w.view
 }
 
+func (w *window) handles(e ViewEvent) native.Handles {
+	if e.ViewController == 0 {
+		return native.Handles{}
+	}
+	return native.Handles{
+		Platform:       native.IOS,
+		View:           uintptr(w.view),
+		ViewController: e.ViewController,
+	}
+}
+
 func (w *window) ShowTextInput(show bool) {
 	if show {
 		C.showTextInput(w.view)
@@ -344,6 +497,9 @@ func (w *window) ShowTextInput(show bool) {
 func (w *window) SetInputHint(_ key.InputHint) {}
 
 func newWindow(win *callbacks, options []Option) error {
+	if embedParent(options) != 0 {
+		return errEmbedUnsupported
+	}
 	mainWindow.in <- windowAndConfig{win, options}
 	return <-mainWindow.errs
 }
@@ -13,10 +13,20 @@ __attribute__ ((visibility ("hidden"))) Class gio_layerClass(void);
 @interface GioView: UIView <UIKeyInput>
 @end
 
+@interface GioViewController ()
+@property (nonatomic) BOOL systemGesturesDisabled;
+@end
+
 @implementation GioViewController
 
 CGFloat _keyboardHeight;
 
+#if !TARGET_OS_TV
+- (UIRectEdge)preferredScreenEdgesDeferringSystemGestures {
+	return self.systemGesturesDisabled ? UIRectEdgeAll : UIRectEdgeNone;
+}
+#endif
+
 - (void)loadView {
 	gio_runMain();
 
@@ -260,6 +270,22 @@ void gio_setDisplayLinkDisplay(CFTypeRef dl, uint64_t did) {
 	// Nothing to do on iOS.
 }
 
+double gio_displayLinkRefreshPeriod(CFTypeRef dlref) {
+	CADisplayLink *dl = (__bridge CADisplayLink *)dlref;
+	// duration is zero until the first frame callback.
+	return dl.duration;
+}
+
+void gio_setSystemGesturesDisabled(CFTypeRef controllerRef, int disabled) {
+	GioViewController *controller = (__bridge GioViewController *)controllerRef;
+	controller.systemGesturesDisabled = disabled ? YES : NO;
+#if !TARGET_OS_TV
+	if (@available(iOS 11.0, *)) {
+		[controller setNeedsUpdateOfScreenEdgesDeferringSystemGestures];
+	}
+#endif
+}
+
 void gio_hideCursor() {
 	// Not supported.
 }
@@ -3,9 +3,13 @@
 package app
 
 import (
+	"bytes"
+	"encoding/base64"
+	"errors"
 	"fmt"
 	"image"
 	"image/color"
+	"image/png"
 	"strings"
 	"syscall/js"
 	"time"
@@ -18,6 +22,7 @@ import (
 	"github.com/Seikaijyu/gio/io/clipboard"
 	"github.com/Seikaijyu/gio/io/key"
 	"github.com/Seikaijyu/gio/io/pointer"
+	"github.com/Seikaijyu/gio/io/router"
 	"github.com/Seikaijyu/gio/io/system"
 	"github.com/Seikaijyu/gio/unit"
 )
@@ -26,6 +31,40 @@ type ViewEvent struct {
 	Element js.Value
 }
 
+// CanvasOptions configures the canvas element a window renders to.
+type CanvasOptions struct {
+	// Element is an existing canvas element to render to, for example
+	// one placed by the page layout. The window follows the size of the
+	// element, which must be sized by CSS because the window sets the
+	// width and height attributes of the element to its size in
+	// device pixels. If Element is undefined or null, a new canvas
+	// filling the page is created.
+	Element js.Value
+	// Opaque requests a canvas without an alpha channel. Opaque
+	// canvases hide the page content behind the window, but may render
+	// faster.
+	Opaque bool
+	// Context is the type of WebGL context, "webgl2" or "webgl". The
+	// empty string tries "webgl2" and falls back to "webgl".
+	Context string
+}
+
+// Canvas configures the canvas of the window. Canvas applies only when
+// the window is created.
+func Canvas(opts CanvasOptions) Option {
+	return func(_ unit.Metric, cnf *Config) {
+		cnf.canvas = opts
+	}
+}
+
+// canvasOptions returns the CanvasOptions among options.
+func canvasOptions(options []Option) CanvasOptions {
+	var cnf Config
+	cnf.apply(unit.Metric{}, options)
+	opts, _ := cnf.canvas.(CanvasOptions)
+	return opts
+}
+
 type contextStatus int
 
 const (
@@ -44,6 +83,7 @@ type window struct {
 	w                     *callbacks
 	redraw                js.Func
 	clipboardCallback     js.Func
+	clipboardError        js.Func
 	requestAnimationFrame js.Value
 	browserHistory        js.Value
 	visualViewport        js.Value
@@ -66,23 +106,40 @@ type window struct {
 	wakeups       chan struct{}
 
 	contextStatus contextStatus
+	// imageCursors caches the CSS cursor values of image cursors.
+	imageCursors map[*pointer.ImageCursor]string
+	// canvasOpts are the options given to the Canvas option.
+	canvasOpts CanvasOptions
 }
 
 func newWindow(win *callbacks, options []Option) error {
+	if embedParent(options) != 0 {
+		return errEmbedUnsupported
+	}
 	doc := js.Global().Get("document")
-	cont := getContainer(doc)
-	cnv := createCanvas(doc)
-	cont.Call("appendChild", cnv)
+	opts := canvasOptions(options)
+	var cont, cnv js.Value
+	if cnv = opts.Element; cnv.Truthy() {
+		cont = cnv.Get("parentElement")
+		if !cont.Truthy() {
+			return errors.New("app: canvas element is not attached to the page")
+		}
+	} else {
+		cont = getContainer(doc)
+		cnv = createCanvas(doc)
+		cont.Call("appendChild", cnv)
+	}
 	tarea := createTextArea(doc)
 	cont.Call("appendChild", tarea)
 	w := &window{
-		cnv:       cnv,
-		document:  doc,
-		tarea:     tarea,
-		window:    js.Global().Get("window"),
-		head:      doc.Get("head"),
-		clipboard: js.Global().Get("navigator").Get("clipboard"),
-		wakeups:   make(chan struct{}, 1),
+		canvasOpts: opts,
+		cnv:        cnv,
+		document:   doc,
+		tarea:      tarea,
+		window:     js.Global().Get("window"),
+		head:       doc.Get("head"),
+		clipboard:  js.Global().Get("navigator").Get("clipboard"),
+		wakeups:    make(chan struct{}, 1),
 	}
 	w.requestAnimationFrame = w.window.Get("requestAnimationFrame")
 	w.browserHistory = w.window.Get("history")
@@ -104,6 +161,9 @@ func newWindow(win *callbacks, options []Option) error {
 		go win.Event(clipboard.Event{Text: content})
 		return nil
 	})
+	w.clipboardError = w.funcOf(func(this js.Value, args []js.Value) interface{} {
+		return nil
+	})
 	w.addEventListeners()
 	w.addHistory()
 	w.w = win
@@ -196,6 +256,7 @@ func (w *window) addEventListeners() {
 		w.requestRedraw()
 		return nil
 	})
+	w.observeResize()
 	w.addEventListener(w.window, "contextmenu", func(this js.Value, args []js.Value) interface{} {
 		args[0].Call("preventDefault")
 		return nil
@@ -218,11 +279,11 @@ func (w *window) addEventListeners() {
 		return nil
 	})
 	w.addEventListener(w.cnv, "mousemove", func(this js.Value, args []js.Value) interface{} {
-		w.pointerEvent(pointer.Move, 0, 0, args[0])
+		w.pointerEvent(pointer.Move, f32.Point{}, pointer.ScrollPixels, args[0])
 		return nil
 	})
 	w.addEventListener(w.cnv, "mousedown", func(this js.Value, args []js.Value) interface{} {
-		w.pointerEvent(pointer.Press, 0, 0, args[0])
+		w.pointerEvent(pointer.Press, f32.Point{}, pointer.ScrollPixels, args[0])
 		if w.requestFocus {
 			w.focus()
 			w.requestFocus = false
@@ -230,7 +291,7 @@ func (w *window) addEventListeners() {
 		return nil
 	})
 	w.addEventListener(w.cnv, "mouseup", func(this js.Value, args []js.Value) interface{} {
-		w.pointerEvent(pointer.Release, 0, 0, args[0])
+		w.pointerEvent(pointer.Release, f32.Point{}, pointer.ScrollPixels, args[0])
 		return nil
 	})
 	w.addEventListener(w.cnv, "wheel", func(this js.Value, args []js.Value) interface{} {
@@ -240,16 +301,19 @@ func (w *window) addEventListeners() {
 		if e.Get("shiftKey").Bool() {
 			dx, dy = dy, dx
 		}
-		mode := e.Get("deltaMode").Int()
-		switch mode {
+		mode := pointer.ScrollPixels
+		switch e.Get("deltaMode").Int() {
+		case 0x00: // DOM_DELTA_PIXEL
+			dx *= float64(w.scale)
+			dy *= float64(w.scale)
 		case 0x01: // DOM_DELTA_LINE
-			dx *= 10
-			dy *= 10
+			mode = pointer.ScrollLines
 		case 0x02: // DOM_DELTA_PAGE
-			dx *= 120
-			dy *= 120
+			mode = pointer.ScrollPages
 		}
-		w.pointerEvent(pointer.Scroll, float32(dx), float32(dy), e)
+		_, _, metric := w.getConfig()
+		scroll := scrollPixels(metric, mode, f32.Point{X: float32(dx), Y: float32(dy)}, w.config.Size)
+		w.pointerEvent(pointer.Scroll, scroll, mode, e)
 		return nil
 	})
 	w.addEventListener(w.cnv, "touchstart", func(this js.Value, args []js.Value) interface{} {
@@ -323,6 +387,25 @@ func (w *window) addEventListeners() {
 	})
 }
 
+// observeResize tracks the size of the canvas with a ResizeObserver,
+// which also reports changes by the page layout that don't resize the
+// browser window.
+func (w *window) observeResize() {
+	ctor := js.Global().Get("ResizeObserver")
+	if !ctor.Truthy() {
+		return
+	}
+	obs := ctor.New(w.funcOf(func(this js.Value, args []js.Value) interface{} {
+		w.resize()
+		w.requestRedraw()
+		return nil
+	}))
+	obs.Call("observe", w.cnv)
+	w.cleanfuncs = append(w.cleanfuncs, func() {
+		obs.Call("disconnect")
+	})
+}
+
 func (w *window) addHistory() {
 	w.browserHistory.Call("pushState", nil, nil, w.window.Get("location").Get("href"))
 }
@@ -448,7 +531,9 @@ func (w *window) touchIDFor(touch js.Value) pointer.ID {
 	return pid
 }
 
-func (w *window) pointerEvent(kind pointer.Kind, dx, dy float32, e js.Value) {
+// pointerEvent sends a pointer event for the DOM event e, with the
+// scroll distance in pixels.
+func (w *window) pointerEvent(kind pointer.Kind, scroll f32.Point, mode pointer.ScrollMode, e js.Value) {
 	e.Call("preventDefault")
 	x, y := e.Get("clientX").Float(), e.Get("clientY").Float()
 	rect := w.cnv.Call("getBoundingClientRect")
@@ -459,10 +544,6 @@ func (w *window) pointerEvent(kind pointer.Kind, dx, dy float32, e js.Value) {
 		X: float32(x) * scale,
 		Y: float32(y) * scale,
 	}
-	scroll := f32.Point{
-		X: dx * scale,
-		Y: dy * scale,
-	}
 	t := time.Duration(e.Get("timeStamp").Int()) * time.Millisecond
 	jbtns := e.Get("buttons").Int()
 	var btns pointer.Buttons
@@ -476,13 +557,14 @@ func (w *window) pointerEvent(kind pointer.Kind, dx, dy float32, e js.Value) {
 		btns |= pointer.ButtonTertiary
 	}
 	w.w.Event(pointer.Event{
-		Kind:      kind,
-		Source:    pointer.Mouse,
-		Buttons:   btns,
-		Position:  pos,
-		Scroll:    scroll,
-		Time:      t,
-		Modifiers: modifiersFor(e),
+		Kind:       kind,
+		Source:     pointer.Mouse,
+		Buttons:    btns,
+		Position:   pos,
+		Scroll:     scroll,
+		ScrollMode: mode,
+		Time:       t,
+		Modifiers:  modifiersFor(e),
 	})
 }
 
@@ -530,19 +612,50 @@ func (w *window) ReadClipboard() {
 	if w.clipboard.Get("readText").IsUndefined() {
 		return
 	}
-	w.clipboard.Call("readText", w.clipboard).Call("then", w.clipboardCallback)
+	// The promise is rejected if the user denies access to the
+	// clipboard; the paste is dropped.
+	w.clipboard.Call("readText", w.clipboard).Call("then", w.clipboardCallback, w.clipboardError)
 }
 
 func (w *window) WriteClipboard(s string) {
-	if w.clipboard.IsUndefined() {
+	if w.clipboard.IsUndefined() || w.clipboard.Get("writeText").IsUndefined() {
+		// The async Clipboard API is only available to secure
+		// contexts.
+		w.execCopy(s)
 		return
 	}
-	if w.clipboard.Get("writeText").IsUndefined() {
+	w.clipboard.Call("writeText", s).Call("catch", w.clipboardError)
+}
+
+// execCopy copies s through the selection of a temporary text area,
+// for browsers without the async Clipboard API.
+func (w *window) execCopy(s string) {
+	body := w.document.Get("body")
+	if !body.Truthy() || !w.document.Get("execCommand").Truthy() {
 		return
 	}
-	w.clipboard.Call("writeText", s)
+	tmp := w.document.Call("createElement", "textarea")
+	tmp.Set("value", s)
+	style := tmp.Get("style")
+	style.Set("position", "fixed")
+	style.Set("opacity", "0")
+	body.Call("appendChild", tmp)
+	tmp.Call("select")
+	w.document.Call("execCommand", "copy")
+	body.Call("removeChild", tmp)
+	if w.requestFocus {
+		w.tarea.Call("focus")
+	}
 }
 
+// ReadPrimary delivers an empty Event; browsers don't expose the
+// primary selection.
+func (w *window) ReadPrimary() {
+	w.w.Event(clipboard.Event{Primary: true})
+}
+
+func (w *window) WritePrimary(s string) {}
+
 func (w *window) Configure(options []Option) {
 	prev := w.config
 	cnf := w.config
@@ -571,7 +684,11 @@ func (w *window) Configure(options []Option) {
 	w.w.Event(ConfigEvent{Config: w.config})
 }
 
-func (w *window) Perform(system.Action) {}
+func (w *window) Perform(acts system.Action) {
+	if acts&system.ActionFocus != 0 {
+		w.window.Call("focus")
+	}
+}
 
 var webCursor = [...]string{
 	pointer.CursorDefault:                  "default",
@@ -607,6 +724,33 @@ func (w *window) SetCursor(cursor pointer.Cursor) {
 	style.Set("cursor", webCursor[cursor])
 }
 
+func (w *window) SetImageCursor(cursor *pointer.ImageCursor) {
+	css, ok := w.imageCursors[cursor]
+	if !ok {
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, cursor.Image()); err != nil {
+			w.SetCursor(pointer.CursorDefault)
+			return
+		}
+		hot := cursor.Hotspot()
+		data := base64.StdEncoding.EncodeToString(buf.Bytes())
+		css = fmt.Sprintf("url(data:image/png;base64,%s) %d %d, %s", data, hot.X, hot.Y, webCursor[pointer.CursorDefault])
+		if len(w.imageCursors) >= maxImageCursors {
+			w.imageCursors = nil
+		}
+		if w.imageCursors == nil {
+			w.imageCursors = make(map[*pointer.ImageCursor]string)
+		}
+		w.imageCursors[cursor] = css
+	}
+	style := w.cnv.Get("style")
+	style.Set("cursor", css)
+}
+
+// SetNativeViews is not supported, because the browser has no native
+// views. Native views are ignored, as documented in package native.
+func (w *window) SetNativeViews(views []router.NativeView) {}
+
 func (w *window) Wakeup() {
 	select {
 	case w.wakeups <- struct{}{}:
@@ -643,7 +787,9 @@ func (w *window) resize() {
 		w.w.Event(ConfigEvent{Config: w.config})
 	}
 
-	if vx, vy := w.visualViewport.Get("width"), w.visualViewport.Get("height"); !vx.IsUndefined() && !vy.IsUndefined() {
+	// The visual viewport only covers windows that fill the page.
+	vx, vy := w.visualViewport.Get("width"), w.visualViewport.Get("height")
+	if !w.canvasOpts.Element.Truthy() && !vx.IsUndefined() && !vy.IsUndefined() {
 		w.inset.X = float32(w.config.Size.X) - float32(vx.Float())*w.scale
 		w.inset.Y = float32(w.config.Size.Y) - float32(vy.Float())*w.scale
 	}
@@ -8,35 +8,58 @@ package app
 import (
 	"errors"
 	"image"
+	"image/color"
+	"math"
+	"net"
 	"runtime"
+	"strings"
 	"time"
 	"unicode"
 	"unicode/utf8"
+	"unsafe"
 
+	"github.com/Seikaijyu/gio/app/internal/native"
 	"github.com/Seikaijyu/gio/internal/f32"
 	"github.com/Seikaijyu/gio/io/clipboard"
 	"github.com/Seikaijyu/gio/io/key"
 	"github.com/Seikaijyu/gio/io/pointer"
+	"github.com/Seikaijyu/gio/io/router"
 	"github.com/Seikaijyu/gio/io/system"
 	"github.com/Seikaijyu/gio/unit"
+	"golang.org/x/sys/unix"
 
 	_ "github.com/Seikaijyu/gio/internal/cocoainit"
 )
 
 /*
 #cgo CFLAGS: -Werror -Wno-deprecated-declarations -fobjc-arc -x objective-c
-#cgo LDFLAGS: -framework AppKit -framework QuartzCore
+#cgo LDFLAGS: -framework AppKit -framework QuartzCore -framework IOKit -framework CoreGraphics
 
 #include <AppKit/AppKit.h>
+#include <IOKit/pwr_mgt/IOPMLib.h>
 
 #define MOUSE_MOVE 1
 #define MOUSE_UP 2
 #define MOUSE_DOWN 3
 #define MOUSE_SCROLL 4
 
+#define GESTURE_PINCH 1
+#define GESTURE_ROTATE 2
+#define GESTURE_SMART_ZOOM 3
+
+#define SCROLL_PHASE_NONE 0
+#define SCROLL_PHASE_BEGAN 1
+#define SCROLL_PHASE_CHANGED 2
+#define SCROLL_PHASE_ENDED 3
+#define SCROLL_PHASE_MOMENTUM 4
+#define SCROLL_PHASE_MOMENTUM_ENDED 5
+
 __attribute__ ((visibility ("hidden"))) void gio_main(void);
 __attribute__ ((visibility ("hidden"))) CFTypeRef gio_createView(void);
 __attribute__ ((visibility ("hidden"))) CFTypeRef gio_createWindow(CFTypeRef viewRef, CGFloat width, CGFloat height, CGFloat minWidth, CGFloat minHeight, CGFloat maxWidth, CGFloat maxHeight);
+__attribute__ ((visibility ("hidden"))) void gio_setWindowNoActivate(CFTypeRef windowRef, int noActivate);
+__attribute__ ((visibility ("hidden"))) void gio_focusWindow(CFTypeRef windowRef);
+__attribute__ ((visibility ("hidden"))) void gio_setCursor(NSUInteger curID);
 
 static void writeClipboard(CFTypeRef str) {
 	@autoreleasepool {
@@ -89,6 +112,11 @@ static void makeKeyAndOrderFront(CFTypeRef windowRef) {
 	[window makeKeyAndOrderFront:nil];
 }
 
+static void orderFront(CFTypeRef windowRef) {
+	NSWindow *window = (__bridge NSWindow *)windowRef;
+	[window orderFront:nil];
+}
+
 static void toggleFullScreen(CFTypeRef windowRef) {
 	NSWindow *window = (__bridge NSWindow *)windowRef;
 	[window toggleFullScreen:nil];
@@ -109,11 +137,90 @@ static void setWindowTitleVisibility(CFTypeRef windowRef, NSWindowTitleVisibilit
 	window.titleVisibility = state;
 }
 
+static IOPMAssertionID preventDisplaySleep(void) {
+	IOPMAssertionID id;
+	IOReturn ret = IOPMAssertionCreateWithName(kIOPMAssertionTypePreventUserIdleDisplaySleep, kIOPMAssertionLevelOn, CFSTR("Gio KeepScreenOn"), &id);
+	if (ret != kIOReturnSuccess) {
+		return kIOPMNullAssertionID;
+	}
+	return id;
+}
+
+static void setWindowSharingType(CFTypeRef windowRef, int protect) {
+	NSWindow *window = (__bridge NSWindow *)windowRef;
+	window.sharingType = protect ? NSWindowSharingNone : NSWindowSharingReadOnly;
+}
+
 static void setWindowTitlebarAppearsTransparent(CFTypeRef windowRef, int transparent) {
 	NSWindow *window = (__bridge NSWindow *)windowRef;
 	window.titlebarAppearsTransparent = (BOOL)transparent;
 }
 
+static void embedView(CFTypeRef parentRef, CFTypeRef viewRef) {
+	NSView *parent = (__bridge NSView *)parentRef;
+	NSView *view = (__bridge NSView *)viewRef;
+	view.frame = parent.bounds;
+	view.autoresizingMask = NSViewWidthSizable | NSViewHeightSizable;
+	[parent addSubview:view];
+}
+
+// newNativeViewContainer adds a container for the native view child to
+// view. The container clips child to its bounds.
+static CFTypeRef newNativeViewContainer(CFTypeRef viewRef, CFTypeRef childRef) {
+	NSView *view = (__bridge NSView *)viewRef;
+	NSView *child = (__bridge NSView *)childRef;
+	NSView *container = [[NSView alloc] initWithFrame:NSZeroRect];
+	container.wantsLayer = YES;
+	container.layer.masksToBounds = YES;
+	container.hidden = YES;
+	[child removeFromSuperview];
+	child.autoresizingMask = NSViewNotSizable;
+	[container addSubview:child];
+	[view addSubview:container];
+	return CFBridgingRetain(container);
+}
+
+// placeNativeView moves the container to the clip rectangle (cx, cy, cw, ch)
+// and its child to the rectangle (x, y, w, h), and moves it to the top of
+// the subviews of view. The rectangles are in points from the top left
+// corner of view.
+static void placeNativeView(CFTypeRef viewRef, CFTypeRef containerRef, CGFloat x, CGFloat y, CGFloat w, CGFloat h, CGFloat cx, CGFloat cy, CGFloat cw, CGFloat ch) {
+	NSView *view = (__bridge NSView *)viewRef;
+	NSView *container = (__bridge NSView *)containerRef;
+	NSView *child = container.subviews.firstObject;
+	CGFloat height = view.bounds.size.height;
+	container.frame = NSMakeRect(cx, height-cy-ch, cw, ch);
+	child.frame = NSMakeRect(x-cx, (cy+ch)-(y+h), w, h);
+	if (view.subviews.lastObject != container) {
+		[view addSubview:container positioned:NSWindowAbove relativeTo:nil];
+	}
+	container.hidden = NO;
+}
+
+static void hideNativeView(CFTypeRef containerRef) {
+	NSView *container = (__bridge NSView *)containerRef;
+	container.hidden = YES;
+}
+
+static void setWindowTitleBarStyle(CFTypeRef windowRef, int decorated, int tint, CGFloat r, CGFloat g, CGFloat b, int dark) {
+	NSWindow *window = (__bridge NSWindow *)windowRef;
+	if (@available(macOS 10.14, *)) {
+		window.appearance = dark ? [NSAppearance appearanceNamed:NSAppearanceNameDarkAqua] : nil;
+	}
+	if (!decorated) {
+		// The title bar is already transparent.
+		return;
+	}
+	if (tint) {
+		// Let the window background show through the title bar.
+		window.titlebarAppearsTransparent = YES;
+		window.backgroundColor = [NSColor colorWithSRGBRed:r green:g blue:b alpha:1.0];
+	} else {
+		window.titlebarAppearsTransparent = NO;
+		window.backgroundColor = [NSColor windowBackgroundColor];
+	}
+}
+
 static void setWindowStandardButtonHidden(CFTypeRef windowRef, NSWindowButton btn, int hide) {
 	NSWindow *window = (__bridge NSWindow *)windowRef;
 	[window standardWindowButton:btn].hidden = (BOOL)hide;
@@ -124,11 +231,79 @@ static void performWindowDragWithEvent(CFTypeRef windowRef, CFTypeRef evt) {
 	[window performWindowDragWithEvent:(__bridge NSEvent*)evt];
 }
 
+static int tabletPoint(CFTypeRef evt, float *pressure, float *tiltX, float *tiltY) {
+	NSEvent *event = (__bridge NSEvent *)evt;
+	if (event.type == NSEventTypeScrollWheel || event.subtype != NSEventSubtypeTabletPoint) {
+		return 0;
+	}
+	*pressure = event.pressure;
+	*tiltX = event.tilt.x;
+	*tiltY = event.tilt.y;
+	return 1;
+}
+
+static int preciseScrolling(CFTypeRef evt) {
+	NSEvent *event = (__bridge NSEvent *)evt;
+	return event.type == NSEventTypeScrollWheel && event.hasPreciseScrollingDeltas;
+}
+
+static int scrollPhase(CFTypeRef evt) {
+	NSEvent *event = (__bridge NSEvent *)evt;
+	if (event.type != NSEventTypeScrollWheel) {
+		return SCROLL_PHASE_NONE;
+	}
+	switch (event.momentumPhase) {
+	case NSEventPhaseBegan:
+	case NSEventPhaseChanged:
+	case NSEventPhaseStationary:
+		return SCROLL_PHASE_MOMENTUM;
+	case NSEventPhaseEnded:
+	case NSEventPhaseCancelled:
+		return SCROLL_PHASE_MOMENTUM_ENDED;
+	default:
+		break;
+	}
+	switch (event.phase) {
+	case NSEventPhaseBegan:
+		return SCROLL_PHASE_BEGAN;
+	case NSEventPhaseChanged:
+	case NSEventPhaseStationary:
+		return SCROLL_PHASE_CHANGED;
+	case NSEventPhaseEnded:
+	case NSEventPhaseCancelled:
+		return SCROLL_PHASE_ENDED;
+	default:
+		return SCROLL_PHASE_NONE;
+	}
+}
+
 static void closeWindow(CFTypeRef windowRef) {
 	NSWindow* window = (__bridge NSWindow *)windowRef;
+	if (window.sheetParent != nil) {
+		// Sheets have no close button for performClose.
+		[window.sheetParent endSheet:window];
+		[window close];
+		return;
+	}
 	[window performClose:nil];
 }
 
+static void beginSheet(CFTypeRef ownerRef, CFTypeRef windowRef) {
+	NSWindow* owner = (__bridge NSWindow *)ownerRef;
+	NSWindow* window = (__bridge NSWindow *)windowRef;
+	[owner beginSheet:window completionHandler:nil];
+}
+
+static void addChildWindow(CFTypeRef ownerRef, CFTypeRef windowRef) {
+	NSWindow* owner = (__bridge NSWindow *)ownerRef;
+	NSWindow* window = (__bridge NSWindow *)windowRef;
+	NSRect of = owner.frame;
+	NSRect wf = window.frame;
+	NSPoint origin = NSMakePoint(NSMidX(of) - wf.size.width/2, NSMidY(of) - wf.size.height/2);
+	[window setFrameOrigin:origin];
+	[owner addChildWindow:window ordered:NSWindowAbove];
+}
+
 static void setSize(CFTypeRef windowRef, CGFloat width, CGFloat height) {
 	NSWindow* window = (__bridge NSWindow *)windowRef;
 	NSSize size = NSMakeSize(width, height);
@@ -151,6 +326,86 @@ static void setScreenFrame(CFTypeRef windowRef, CGFloat x, CGFloat y, CGFloat w,
 	[window setFrame:r display:YES];
 }
 
+// flipY converts between the bottom-left origin of screen coordinates
+// and the top-left origin of Config.Position.
+static CGFloat flipY(CGFloat y) {
+	return NSScreen.screens[0].frame.size.height - y;
+}
+
+static void flipRect(NSRect r, CGFloat rect[4]) {
+	rect[0] = r.origin.x;
+	rect[1] = flipY(r.origin.y + r.size.height);
+	rect[2] = r.size.width;
+	rect[3] = r.size.height;
+}
+
+// getCursorPosition returns the cursor position in global display
+// coordinates, whose origin is the top-left corner of the main
+// screen like Config.Position.
+static void getCursorPosition(CGFloat pos[2]) {
+	CGEventRef e = CGEventCreate(NULL);
+	CGPoint p = CGEventGetLocation(e);
+	CFRelease(e);
+	pos[0] = p.x;
+	pos[1] = p.y;
+}
+
+static void warpCursor(CGFloat x, CGFloat y) {
+	CGWarpMouseCursorPosition(CGPointMake(x, y));
+	// Don't suppress mouse movement for a while after warping.
+	CGAssociateMouseAndMouseCursorPosition(true);
+}
+
+static void activateProcess(pid_t pid) {
+	@autoreleasepool {
+		NSRunningApplication *app = [NSRunningApplication runningApplicationWithProcessIdentifier:pid];
+		[app activateWithOptions:NSApplicationActivateIgnoringOtherApps];
+	}
+}
+
+static double doubleClickInterval(void) {
+	return [NSEvent doubleClickInterval];
+}
+
+static void getWindowFrame(CFTypeRef windowRef, CGFloat frame[4]) {
+	NSWindow* window = (__bridge NSWindow *)windowRef;
+	flipRect(window.frame, frame);
+}
+
+static void setWindowTopLeft(CFTypeRef windowRef, CGFloat x, CGFloat y) {
+	NSWindow* window = (__bridge NSWindow *)windowRef;
+	[window setFrameTopLeftPoint:NSMakePoint(x, flipY(y))];
+}
+
+static int getWindowScreen(CFTypeRef windowRef, CGFloat frame[4]) {
+	NSWindow* window = (__bridge NSWindow *)windowRef;
+	if (window.screen == nil) {
+		return 0;
+	}
+	flipRect(window.screen.frame, frame);
+	return 1;
+}
+
+// getScreen returns the frame and visible frame of the screen that
+// contains (x, y), or of the main screen if no screen contains it.
+static int getScreen(CGFloat x, CGFloat y, CGFloat frame[4], CGFloat visible[4]) {
+	NSPoint p = NSMakePoint(x, flipY(y));
+	NSScreen *screen = nil;
+	for (NSScreen *s in NSScreen.screens) {
+		if (NSPointInRect(p, s.frame)) {
+			screen = s;
+			break;
+		}
+	}
+	int found = screen != nil;
+	if (!found) {
+		screen = NSScreen.mainScreen;
+	}
+	flipRect(screen.frame, frame);
+	flipRect(screen.visibleFrame, visible);
+	return found;
+}
+
 static void hideWindow(CFTypeRef windowRef) {
 	NSWindow* window = (__bridge NSWindow *)windowRef;
 	[window miniaturize:window];
@@ -191,6 +446,41 @@ static CFTypeRef windowForView(CFTypeRef viewRef) {
 	return (__bridge CFTypeRef)view.window;
 }
 
+static CFTypeRef newImageCursor(const void *pix, int width, int height, CGFloat hotX, CGFloat hotY, CGFloat scale) {
+	@autoreleasepool {
+		NSBitmapImageRep *rep = [[NSBitmapImageRep alloc] initWithBitmapDataPlanes:NULL
+									   pixelsWide:width
+									   pixelsHigh:height
+									bitsPerSample:8
+								      samplesPerPixel:4
+									     hasAlpha:YES
+									     isPlanar:NO
+								       colorSpaceName:NSDeviceRGBColorSpace
+									 bitmapFormat:NSBitmapFormatAlphaNonpremultiplied
+									  bytesPerRow:width*4
+									 bitsPerPixel:32];
+		if (rep == nil) {
+			return nil;
+		}
+		memcpy(rep.bitmapData, pix, width*height*4);
+		// Size the image in points for sharp cursors on Retina screens.
+		NSImage *img = [[NSImage alloc] initWithSize:NSMakeSize(width/scale, height/scale)];
+		[img addRepresentation:rep];
+		NSCursor *cursor = [[NSCursor alloc] initWithImage:img hotSpot:NSMakePoint(hotX/scale, hotY/scale)];
+		return CFBridgingRetain(cursor);
+	}
+}
+
+static void setImageCursor(CFTypeRef cursor) {
+	[(__bridge NSCursor *)cursor set];
+}
+
+static void requestAttention(void) {
+	// Bounce the dock icon until the application is activated. It
+	// does nothing if the application is already active.
+	[NSApp requestUserAttention:NSCriticalRequest];
+}
+
 static void raiseWindow(CFTypeRef windowRef) {
 	NSRunningApplication *currentApp = [NSRunningApplication currentApplication];
 	if (![currentApp isActive]) {
@@ -254,9 +544,26 @@ type window struct {
 	redraw      chan struct{}
 	cursor      pointer.Cursor
 	pointerBtns pointer.Buttons
+	// eraser tracks whether the tablet pen in proximity is inverted.
+	eraser bool
+	// image is the current image cursor, if any.
+	image *pointer.ImageCursor
+	// imageCursors caches the native cursors of image cursors.
+	imageCursors map[*pointer.ImageCursor]C.CFTypeRef
+	// parent is the NSView the view is embedded in, as given to
+	// NewWindowFrom.
+	parent C.CFTypeRef
+	// nativeViews maps native child views to the containers that
+	// clip them.
+	nativeViews map[C.CFTypeRef]C.CFTypeRef
 
 	scale  float32
 	config Config
+	// sleepAssertion is the power assertion held for KeepScreenOn.
+	sleepAssertion C.IOPMAssertionID
+	// frameSize is the size of the last frame. The drawable is
+	// refreshed synchronously when it changes.
+	frameSize image.Point
 }
 
 // viewMap is the mapping from Cocoa NSViews to Go windows.
@@ -314,6 +621,14 @@ func (w *window) WriteClipboard(s string) {
 	C.writeClipboard(cstr)
 }
 
+// ReadPrimary delivers an empty Event; macOS has no primary
+// selection.
+func (w *window) ReadPrimary() {
+	w.w.Event(clipboard.Event{Primary: true})
+}
+
+func (w *window) WritePrimary(s string) {}
+
 func (w *window) updateWindowMode() {
 	style := int(C.getWindowStyleMask(C.windowForView(w.view)))
 	if style&C.NSWindowStyleMaskFullScreen != 0 {
@@ -327,12 +642,30 @@ func (w *window) updateWindowMode() {
 func (w *window) Configure(options []Option) {
 	screenScale := float32(C.getScreenBackingScale())
 	cfg := configFor(screenScale)
+	if w.parent == 0 {
+		w.updatePosition()
+	}
 	prev := w.config
 	w.updateWindowMode()
 	cnf := w.config
 	cnf.apply(cfg, options)
+	if cnf.KeepScreenOn != prev.KeepScreenOn {
+		w.config.KeepScreenOn = cnf.KeepScreenOn
+		w.setKeepScreenOn(cnf.KeepScreenOn)
+	}
+	if w.parent != 0 {
+		// Embedded views are sized and decorated by the host.
+		w.config.Decorated = true
+		w.w.Event(ConfigEvent{Config: w.config})
+		return
+	}
 	window := C.windowForView(w.view)
 
+	moved := cnf.Position != prev.Position
+	if moved && cnf.Mode != Windowed {
+		// Move the window to the screen to maximize it on.
+		w.moveTo(window, cnf.Position, cnf.positionMonitor)
+	}
 	switch cnf.Mode {
 	case Fullscreen:
 		switch prev.Mode {
@@ -392,6 +725,10 @@ func (w *window) Configure(options []Option) {
 			cnf.MaxSize = cnf.MaxSize.Div(int(screenScale))
 			C.setMaxSize(window, C.CGFloat(cnf.MaxSize.X), C.CGFloat(cnf.MaxSize.Y))
 		}
+		if moved {
+			// Move after resizing, which keeps the bottom-left corner.
+			w.moveTo(window, cnf.Position, cnf.positionMonitor)
+		}
 	}
 	if cnf.Decorated != prev.Decorated {
 		w.config.Decorated = cnf.Decorated
@@ -413,9 +750,93 @@ func (w *window) Configure(options []Option) {
 		C.setWindowStandardButtonHidden(window, C.NSWindowMiniaturizeButton, barTrans)
 		C.setWindowStandardButtonHidden(window, C.NSWindowZoomButton, barTrans)
 	}
+	if cnf.TitleBarColor != prev.TitleBarColor || cnf.DarkDecorations != prev.DarkDecorations || cnf.Decorated != prev.Decorated {
+		w.config.TitleBarColor = cnf.TitleBarColor
+		w.config.DarkDecorations = cnf.DarkDecorations
+		c := cnf.TitleBarColor
+		tint := C.int(C.NO)
+		if c != (color.NRGBA{}) {
+			tint = C.YES
+		}
+		dark := C.int(C.NO)
+		if cnf.DarkDecorations {
+			dark = C.YES
+		}
+		decorated := C.int(C.NO)
+		if cnf.Decorated {
+			decorated = C.YES
+		}
+		C.setWindowTitleBarStyle(window, decorated, tint, C.CGFloat(c.R)/255, C.CGFloat(c.G)/255, C.CGFloat(c.B)/255, dark)
+	}
+	if cnf.ContentProtected != prev.ContentProtected {
+		w.config.ContentProtected = cnf.ContentProtected
+		protect := C.int(C.NO)
+		if cnf.ContentProtected {
+			protect = C.YES
+		}
+		C.setWindowSharingType(window, protect)
+	}
+	if cnf.NoActivate != prev.NoActivate {
+		w.config.NoActivate = cnf.NoActivate
+		noActivate := C.int(C.NO)
+		if cnf.NoActivate {
+			noActivate = C.YES
+		}
+		C.gio_setWindowNoActivate(window, noActivate)
+	}
 	w.w.Event(ConfigEvent{Config: w.config})
 }
 
+// orderFront shows a new window, and makes it the key window unless
+// the window is NoActivate. It assumes ownership of the window
+// reference.
+func (w *window) orderFront(window C.CFTypeRef) {
+	if w.config.NoActivate {
+		C.orderFront(window)
+	} else {
+		C.makeKeyAndOrderFront(window)
+	}
+}
+
+// setKeepScreenOn holds or releases a power assertion that keeps the
+// display awake.
+func (w *window) setKeepScreenOn(on bool) {
+	if on && w.sleepAssertion == C.kIOPMNullAssertionID {
+		w.sleepAssertion = C.preventDisplaySleep()
+	}
+	if !on && w.sleepAssertion != C.kIOPMNullAssertionID {
+		C.IOPMAssertionRelease(w.sleepAssertion)
+		w.sleepAssertion = C.kIOPMNullAssertionID
+	}
+}
+
+// updatePosition reads the position of the window and its screen.
+func (w *window) updatePosition() {
+	window := C.windowForView(w.view)
+	var frame [4]C.CGFloat
+	C.getWindowFrame(window, &frame[0])
+	w.config.Position = image.Pt(int(frame[0]), int(frame[1]))
+	if C.getWindowScreen(window, &frame[0]) != 0 {
+		w.config.monitor = screenRect(frame)
+	}
+}
+
+// moveTo moves the window to p, keeping it visible on a screen.
+func (w *window) moveTo(window C.CFTypeRef, p image.Point, saved image.Rectangle) {
+	var frame, visible [4]C.CGFloat
+	C.getWindowFrame(window, &frame[0])
+	r := image.Rectangle{Min: p, Max: p.Add(image.Pt(int(frame[2]), int(frame[3])))}
+	c := r.Min.Add(r.Size().Div(2))
+	found := C.getScreen(C.CGFloat(c.X), C.CGFloat(c.Y), &frame[0], &visible[0]) != 0
+	p = fitToMonitor(r, saved, screenRect(frame), screenRect(visible), found)
+	C.setWindowTopLeft(window, C.CGFloat(p.X), C.CGFloat(p.Y))
+	w.config.Position = p
+}
+
+func screenRect(r [4]C.CGFloat) image.Rectangle {
+	return image.Rect(int(r[0]), int(r[1]), int(r[0]+r[2]), int(r[1]+r[3]))
+}
+
 func (w *window) setTitle(prev, cnf Config) {
 	if prev.Title != cnf.Title {
 		w.config.Title = cnf.Title
@@ -426,6 +847,10 @@ func (w *window) setTitle(prev, cnf Config) {
 }
 
 func (w *window) Perform(acts system.Action) {
+	if w.parent != 0 {
+		// Embedded views are managed by the host.
+		return
+	}
 	window := C.windowForView(w.view)
 	walkActions(acts, func(a system.Action) {
 		switch a {
@@ -438,6 +863,10 @@ func (w *window) Perform(acts system.Action) {
 			C.setScreenFrame(window, C.CGFloat(x), C.CGFloat(y), C.CGFloat(sz.X), C.CGFloat(sz.Y))
 		case system.ActionRaise:
 			C.raiseWindow(window)
+		case system.ActionFocus:
+			C.gio_focusWindow(window)
+		case system.ActionRequestAttention:
+			C.requestAttention()
 		}
 	})
 	if acts&system.ActionClose != 0 {
@@ -446,9 +875,144 @@ func (w *window) Perform(acts system.Action) {
 }
 
 func (w *window) SetCursor(cursor pointer.Cursor) {
+	if w.image != nil {
+		w.image = nil
+		if cursor == pointer.CursorDefault {
+			// windowSetCursor ignores unchanged cursors.
+			C.gio_setCursor(C.NSUInteger(macosCursorID[cursor]))
+			return
+		}
+	}
 	w.cursor = windowSetCursor(w.cursor, cursor)
 }
 
+func (w *window) CursorCapabilities() CursorCapability {
+	return CursorQuery | CursorWarp
+}
+
+func (w *window) CursorPosition() (image.Point, error) {
+	var pos [2]C.CGFloat
+	C.getCursorPosition(&pos[0])
+	return image.Pt(int(pos[0]), int(pos[1])), nil
+}
+
+func (w *window) WarpCursor(p image.Point) error {
+	C.warpCursor(C.CGFloat(p.X), C.CGFloat(p.Y))
+	return nil
+}
+
+// activateInstance activates the primary instance at the other end of
+// c. macOS doesn't let the primary instance bring itself to the front
+// while another application is active.
+func activateInstance(c net.Conn) {
+	uc, ok := c.(*net.UnixConn)
+	if !ok {
+		return
+	}
+	raw, err := uc.SyscallConn()
+	if err != nil {
+		return
+	}
+	pid := 0
+	raw.Control(func(fd uintptr) {
+		pid, err = unix.GetsockoptInt(int(fd), unix.SOL_LOCAL, unix.LOCAL_PEERPID)
+	})
+	if err == nil && pid > 0 {
+		C.activateProcess(C.pid_t(pid))
+	}
+}
+
+// gestureSettings returns the double click interval set by the user.
+// macOS has no drag threshold setting.
+func gestureSettings() system.Gestures {
+	secs := float64(C.doubleClickInterval())
+	return system.Gestures{DoubleClick: time.Duration(secs * float64(time.Second))}
+}
+
+func (w *window) SetImageCursor(cursor *pointer.ImageCursor) {
+	c, ok := w.imageCursors[cursor]
+	if !ok {
+		img := cursor.Image()
+		sz := img.Bounds().Size()
+		hot := cursor.Hotspot()
+		if sz.X > 0 && sz.Y > 0 {
+			c = C.newImageCursor(unsafe.Pointer(&img.Pix[0]), C.int(sz.X), C.int(sz.Y), C.CGFloat(hot.X), C.CGFloat(hot.Y), C.CGFloat(w.scale))
+		}
+		if c == 0 {
+			w.SetCursor(pointer.CursorDefault)
+			return
+		}
+		if w.imageCursors == nil {
+			w.imageCursors = make(map[*pointer.ImageCursor]C.CFTypeRef)
+		}
+		w.imageCursors[cursor] = c
+	}
+	// The image cursor replaces the named cursor, which is
+	// restored by SetCursor.
+	w.SetCursor(pointer.CursorDefault)
+	w.image = cursor
+	C.setImageCursor(c)
+	if len(w.imageCursors) > maxImageCursors {
+		for k, v := range w.imageCursors {
+			if k != cursor {
+				C.CFRelease(v)
+				delete(w.imageCursors, k)
+			}
+		}
+	}
+}
+
+// SetNativeViews places the native views, moving each to the top such
+// that they end up stacked in the order of views.
+func (w *window) SetNativeViews(views []router.NativeView) {
+	placed := make(map[C.CFTypeRef]bool, len(views))
+	for _, v := range views {
+		child := C.CFTypeRef(v.Handle)
+		placed[child] = true
+		container, ok := w.nativeViews[child]
+		if !ok {
+			container = C.newNativeViewContainer(w.view, child)
+			if w.nativeViews == nil {
+				w.nativeViews = make(map[C.CFTypeRef]C.CFTypeRef)
+			}
+			w.nativeViews[child] = container
+		}
+		b := v.Bounds
+		clip := v.Clip.Intersect(b)
+		if clip.Empty() {
+			C.hideNativeView(container)
+			continue
+		}
+		// Convert from pixels to points.
+		pt := func(v int) C.CGFloat {
+			return C.CGFloat(float32(v) / w.scale)
+		}
+		C.placeNativeView(w.view, container,
+			pt(b.Min.X), pt(b.Min.Y), pt(b.Dx()), pt(b.Dy()),
+			pt(clip.Min.X), pt(clip.Min.Y), pt(clip.Dx()), pt(clip.Dy()),
+		)
+	}
+	for child, container := range w.nativeViews {
+		if !placed[child] {
+			C.hideNativeView(container)
+		}
+	}
+}
+
+func (w *window) releaseNativeViews() {
+	for _, c := range w.nativeViews {
+		C.CFRelease(c)
+	}
+	w.nativeViews = nil
+}
+
+func (w *window) releaseImageCursors() {
+	for _, c := range w.imageCursors {
+		C.CFRelease(c)
+	}
+	w.imageCursors = nil
+}
+
 func (w *window) EditorStateChanged(old, new editorState) {
 	if old.Selection.Range != new.Selection.Range || old.Snippet != new.Snippet {
 		C.discardMarkedText(w.view)
@@ -459,6 +1023,18 @@ func (w *window) EditorStateChanged(old, new editorState) {
 	}
 }
 
+func (w *window) handles(e ViewEvent) native.Handles {
+	if e.View == 0 {
+		return native.Handles{}
+	}
+	return native.Handles{
+		Platform: native.MacOS,
+		Window:   uintptr(C.windowForView(w.view)),
+		View:     e.View,
+		Layer:    e.Layer,
+	}
+}
+
 func (w *window) ShowTextInput(show bool) {}
 
 func (w *window) SetInputHint(_ key.InputHint) {}
@@ -555,7 +1131,7 @@ func gio_onMouse(view, evt C.CFTypeRef, cdir C.int, cbtn C.NSInteger, x, y, dx,
 	default:
 		panic("invalid direction")
 	}
-	w.w.Event(pointer.Event{
+	e := pointer.Event{
 		Kind:      typ,
 		Source:    pointer.Mouse,
 		Time:      t,
@@ -563,7 +1139,70 @@ func gio_onMouse(view, evt C.CFTypeRef, cdir C.int, cbtn C.NSInteger, x, y, dx,
 		Position:  pos,
 		Scroll:    f32.Point{X: dxf, Y: dyf},
 		Modifiers: convertMods(mods),
-	})
+	}
+	if typ == pointer.Scroll {
+		if C.preciseScrolling(evt) == 0 {
+			// dx and dy are in rows and columns.
+			e.ScrollMode = pointer.ScrollLines
+			e.Scroll = scrollPixels(configFor(w.scale), e.ScrollMode, f32.Point{X: float32(dx), Y: float32(dy)}, w.config.Size)
+		}
+		switch C.scrollPhase(evt) {
+		case C.SCROLL_PHASE_BEGAN:
+			e.Phase = pointer.PhaseBegan
+		case C.SCROLL_PHASE_CHANGED:
+			e.Phase = pointer.PhaseChanged
+		case C.SCROLL_PHASE_ENDED:
+			e.Phase = pointer.PhaseEnded
+		case C.SCROLL_PHASE_MOMENTUM:
+			e.Phase = pointer.PhaseMomentum
+		case C.SCROLL_PHASE_MOMENTUM_ENDED:
+			e.Phase = pointer.PhaseMomentumEnded
+		}
+	}
+	var pressure, tiltX, tiltY C.float
+	if C.tabletPoint(evt, &pressure, &tiltX, &tiltY) != 0 {
+		e.Source = pointer.Stylus
+		if w.eraser {
+			e.Source = pointer.Eraser
+		}
+		e.Pressure = float32(pressure)
+		// NSEvent tilt ranges from -1 to 1, with y pointing up.
+		e.TiltX = float32(tiltX) * 90
+		e.TiltY = -float32(tiltY) * 90
+	}
+	w.w.Event(e)
+}
+
+//export gio_onGesture
+func gio_onGesture(view C.CFTypeRef, typ C.int, x, y, value C.CGFloat, ti C.double, mods C.NSUInteger) {
+	w := mustView(view)
+	e := pointer.Event{
+		Source:    pointer.Mouse,
+		Time:      time.Duration(float64(ti)*float64(time.Second) + .5),
+		Buttons:   w.pointerBtns,
+		Position:  f32.Point{X: float32(x) * w.scale, Y: float32(y) * w.scale},
+		Modifiers: convertMods(mods),
+	}
+	switch typ {
+	case C.GESTURE_PINCH:
+		e.Kind = pointer.Pinch
+		e.Zoom = float32(value)
+	case C.GESTURE_ROTATE:
+		// NSEvent rotation is in degrees, counterclockwise.
+		e.Kind = pointer.Rotate
+		e.Rotation = -float32(value) * math.Pi / 180
+	case C.GESTURE_SMART_ZOOM:
+		e.Kind = pointer.SmartZoom
+	default:
+		panic("invalid gesture")
+	}
+	w.w.Event(e)
+}
+
+//export gio_onTabletProximity
+func gio_onTabletProximity(view C.CFTypeRef, entering, eraser C.int) {
+	w := mustView(view)
+	w.eraser = entering != 0 && eraser != 0
 }
 
 //export gio_onDraw
@@ -583,7 +1222,18 @@ func gio_onFocus(view C.CFTypeRef, focus C.int) {
 			w.setStage(system.StageRunning)
 		}
 	}
-	w.SetCursor(w.cursor)
+	if w.image != nil {
+		w.SetImageCursor(w.image)
+	} else {
+		w.SetCursor(w.cursor)
+	}
+}
+
+//export gio_onMove
+func gio_onMove(view C.CFTypeRef) {
+	w := mustView(view)
+	w.updatePosition()
+	w.w.Event(ConfigEvent{Config: w.config})
 }
 
 //export gio_onChangeScreen
@@ -776,16 +1426,30 @@ func (w *window) draw() {
 	}
 	cfg := configFor(w.scale)
 	w.setStage(system.StageRunning)
+	// Resized frames are rendered before returning to keep the
+	// content in step with live resizing. Other frames are presented
+	// on the render thread of the window, so windows waiting for the
+	// vertical blank of their display don't stall the main thread.
+	resized := sz != w.frameSize
+	w.frameSize = sz
 	w.w.Event(frameEvent{
 		FrameEvent: system.FrameEvent{
-			Now:    time.Now(),
-			Size:   w.config.Size,
-			Metric: cfg,
+			Now:             time.Now(),
+			RefreshInterval: w.displayLink.RefreshInterval(),
+			Size:            w.config.Size,
+			Metric:          cfg,
 		},
-		Sync: true,
+		Sync: resized,
 	})
 }
 
+// renderThreaded implements threadedDriver. Both Metal and OpenGL
+// contexts render from any thread while locked.
+func (w *window) renderThreaded() {}
+
+// reportsPosition implements positionDriver.
+func (w *window) reportsPosition() {}
+
 func configFor(scale float32) unit.Metric {
 	return unit.Metric{
 		PxPerDp: scale,
@@ -796,11 +1460,15 @@ func configFor(scale float32) unit.Metric {
 //export gio_onClose
 func gio_onClose(view C.CFTypeRef) {
 	w := mustView(view)
+	w.w.SetOwnerHandle(nil)
+	w.setKeepScreenOn(false)
 	w.w.Event(ViewEvent{})
 	w.w.Event(system.DestroyEvent{})
 	w.displayLink.Close()
 	w.displayLink = nil
 	deleteView(view)
+	w.releaseImageCursors()
+	w.releaseNativeViews()
 	C.CFRelease(w.view)
 	w.view = 0
 }
@@ -838,6 +1506,19 @@ func gio_onAppHide() {
 	}
 }
 
+//export gio_onOpenURLs
+func gio_onOpenURLs(urls C.CFTypeRef) {
+	// The running application receives the URLs opened with it instead
+	// of a new instance. Deliver them like the arguments of a new
+	// instance.
+	args := strings.Split(nsstringToString(urls), "\n")
+	for _, w := range viewMap {
+		if win := w.w.w; win.instanceID != "" {
+			go win.deliverInstance(args)
+		}
+	}
+}
+
 //export gio_onAppShow
 func gio_onAppShow() {
 	for _, w := range viewMap {
@@ -851,6 +1532,9 @@ func gio_onFinishLaunching() {
 }
 
 func newWindow(win *callbacks, options []Option) error {
+	if parent := C.CFTypeRef(embedParent(options)); parent != 0 {
+		return newEmbeddedWindow(win, parent, options)
+	}
 	<-launched
 	errch := make(chan error)
 	runOnMain(func() {
@@ -864,15 +1548,58 @@ func newWindow(win *callbacks, options []Option) error {
 		window := C.gio_createWindow(w.view, 0, 0, 0, 0, 0, 0)
 		w.updateWindowMode()
 		win.SetDriver(w)
+		win.SetOwnerHandle(w.view)
+		w.updatePosition()
+		pos := w.config.Position
 		w.Configure(options)
-		if nextTopLeft.x == 0 && nextTopLeft.y == 0 {
-			// cascadeTopLeftFromPoint treats (0, 0) as a no-op,
-			// and just returns the offset we need for the first window.
+		var owner C.CFTypeRef
+		h, modal := dialogOwner(options)
+		if view, ok := h.(C.CFTypeRef); ok && view != 0 {
+			owner = C.windowForView(view)
+		}
+		switch {
+		case owner != 0 && modal:
+			// Like makeKeyAndOrderFront, the sheet is released when
+			// closed.
+			C.beginSheet(owner, window)
+		case owner != 0:
+			C.addChildWindow(owner, window)
+			w.orderFront(window)
+		case w.config.Position != pos:
+			// Keep the position given by the options.
+			w.orderFront(window)
+		default:
+			if nextTopLeft.x == 0 && nextTopLeft.y == 0 {
+				// cascadeTopLeftFromPoint treats (0, 0) as a no-op,
+				// and just returns the offset we need for the first window.
+				nextTopLeft = C.cascadeTopLeftFromPoint(window, nextTopLeft)
+			}
 			nextTopLeft = C.cascadeTopLeftFromPoint(window, nextTopLeft)
+			w.orderFront(window)
 		}
-		nextTopLeft = C.cascadeTopLeftFromPoint(window, nextTopLeft)
-		// makeKeyAndOrderFront assumes ownership of our window reference.
-		C.makeKeyAndOrderFront(window)
+		layer := C.layerForView(w.view)
+		w.w.Event(ViewEvent{View: uintptr(w.view), Layer: uintptr(layer)})
+	})
+	return <-errch
+}
+
+// newEmbeddedWindow creates a view in parent. It doesn't wait for the
+// application to finish launching, because the host application owns
+// NSApp.
+func newEmbeddedWindow(win *callbacks, parent C.CFTypeRef, options []Option) error {
+	errch := make(chan error)
+	runOnMain(func() {
+		w, err := newOSWindow()
+		if err != nil {
+			errch <- err
+			return
+		}
+		errch <- nil
+		w.w = win
+		w.parent = parent
+		C.embedView(parent, w.view)
+		win.SetDriver(w)
+		w.Configure(options)
 		layer := C.layerForView(w.view)
 		w.w.Event(ViewEvent{View: uintptr(w.view), Layer: uintptr(layer)})
 	})
@@ -11,9 +11,25 @@ __attribute__ ((visibility ("hidden"))) CALayer *gio_layerFactory(void);
 @interface GioAppDelegate : NSObject<NSApplicationDelegate>
 @end
 
+@interface GioWindow : NSWindow
+// noActivate prevents the window from becoming the key window, except
+// while focusing is set.
+@property BOOL noActivate;
+@property BOOL focusing;
+@end
+
 @interface GioWindowDelegate : NSObject<NSWindowDelegate>
 @end
 
+@implementation GioWindow
+- (BOOL)canBecomeKeyWindow {
+	if (self.noActivate && !self.focusing) {
+		return NO;
+	}
+	return [super canBecomeKeyWindow];
+}
+@end
+
 @implementation GioWindowDelegate
 - (void)windowWillMiniaturize:(NSNotification *)notification {
 	NSWindow *window = (NSWindow *)[notification object];
@@ -31,6 +47,10 @@ __attribute__ ((visibility ("hidden"))) CALayer *gio_layerFactory(void);
 	NSWindow *window = (NSWindow *)[notification object];
 	gio_onWindowed((__bridge CFTypeRef)window.contentView);
 }
+- (void)windowDidMove:(NSNotification *)notification {
+	NSWindow *window = (NSWindow *)[notification object];
+	gio_onMove((__bridge CFTypeRef)window.contentView);
+}
 - (void)windowDidChangeScreen:(NSNotification *)notification {
 	NSWindow *window = (NSWindow *)[notification object];
 	CGDirectDisplayID dispID = [[[window screen] deviceDescription][@"NSScreenNumber"] unsignedIntValue];
@@ -49,16 +69,17 @@ __attribute__ ((visibility ("hidden"))) CALayer *gio_layerFactory(void);
 
 static void handleMouse(NSView *view, NSEvent *event, int typ, CGFloat dx, CGFloat dy) {
 	NSPoint p = [view convertPoint:[event locationInWindow] fromView:nil];
-	if (!event.hasPreciseScrollingDeltas) {
-		// dx and dy are in rows and columns.
-		dx *= 10;
-		dy *= 10;
-	}
 	// Origin is in the lower left corner. Convert to upper left.
 	CGFloat height = view.bounds.size.height;
 	gio_onMouse((__bridge CFTypeRef)view, (__bridge CFTypeRef)event, typ, event.buttonNumber, p.x, height - p.y, dx, dy, [event timestamp], [event modifierFlags]);
 }
 
+static void handleGesture(NSView *view, NSEvent *event, int typ, CGFloat value) {
+	NSPoint p = [view convertPoint:[event locationInWindow] fromView:nil];
+	CGFloat height = view.bounds.size.height;
+	gio_onGesture((__bridge CFTypeRef)view, typ, p.x, height - p.y, value, [event timestamp], [event modifierFlags]);
+}
+
 @interface GioView : NSView <CALayerDelegate,NSTextInputClient>
 @end
 
@@ -116,13 +137,31 @@ static void handleMouse(NSView *view, NSEvent *event, int typ, CGFloat dx, CGFlo
 - (void)otherMouseDragged:(NSEvent *)event {
 	handleMouse(self, event, MOUSE_MOVE, 0, 0);
 }
+- (void)tabletProximity:(NSEvent *)event {
+	gio_onTabletProximity((__bridge CFTypeRef)self, event.enteringProximity, event.pointingDeviceType == NSPointingDeviceTypeEraser);
+}
+- (void)magnifyWithEvent:(NSEvent *)event {
+	handleGesture(self, event, GESTURE_PINCH, event.magnification);
+}
+- (void)rotateWithEvent:(NSEvent *)event {
+	handleGesture(self, event, GESTURE_ROTATE, event.rotation);
+}
+- (void)smartMagnifyWithEvent:(NSEvent *)event {
+	handleGesture(self, event, GESTURE_SMART_ZOOM, 0);
+}
 - (void)scrollWheel:(NSEvent *)event {
 	CGFloat dx = -event.scrollingDeltaX;
 	CGFloat dy = -event.scrollingDeltaY;
 	handleMouse(self, event, MOUSE_SCROLL, dx, dy);
 }
 - (void)keyDown:(NSEvent *)event {
+	// Keys that complete or cancel marked text, such as the pending
+	// accent of a dead key, are consumed by the text input system.
+	BOOL composing = [self hasMarkedText];
 	[self interpretKeyEvents:[NSArray arrayWithObject:event]];
+	if (composing) {
+		return;
+	}
 	NSString *keys = [event charactersIgnoringModifiers];
 	gio_onKeys((__bridge CFTypeRef)self, (__bridge CFTypeRef)keys, [event timestamp], [event modifierFlags], true);
 }
@@ -226,6 +265,19 @@ void gio_setDisplayLinkDisplay(CFTypeRef dl, uint64_t did) {
 	CVDisplayLinkSetCurrentCGDisplay((CVDisplayLinkRef)dl, (CGDirectDisplayID)did);
 }
 
+double gio_displayLinkRefreshPeriod(CFTypeRef dl) {
+	// The actual period is only known while the link is running.
+	double period = CVDisplayLinkGetActualOutputVideoRefreshPeriod((CVDisplayLinkRef)dl);
+	if (period > 0) {
+		return period;
+	}
+	CVTime t = CVDisplayLinkGetNominalOutputVideoRefreshPeriod((CVDisplayLinkRef)dl);
+	if ((t.flags & kCVTimeIsIndefinite) != 0 || t.timeScale == 0) {
+		return 0;
+	}
+	return (double)t.timeValue / (double)t.timeScale;
+}
+
 void gio_hideCursor() {
 	@autoreleasepool {
 		[NSCursor hide];
@@ -349,7 +401,7 @@ CFTypeRef gio_createWindow(CFTypeRef viewRef, CGFloat width, CGFloat height, CGF
 			NSMiniaturizableWindowMask |
 			NSClosableWindowMask;
 
-		NSWindow* window = [[NSWindow alloc] initWithContentRect:rect
+		GioWindow* window = [[GioWindow alloc] initWithContentRect:rect
 													   styleMask:styleMask
 														 backing:NSBackingStoreBuffered
 														   defer:NO];
@@ -368,6 +420,19 @@ CFTypeRef gio_createWindow(CFTypeRef viewRef, CGFloat width, CGFloat height, CGF
 	}
 }
 
+void gio_setWindowNoActivate(CFTypeRef windowRef, int noActivate) {
+	GioWindow *window = (__bridge GioWindow *)windowRef;
+	window.noActivate = noActivate ? YES : NO;
+}
+
+void gio_focusWindow(CFTypeRef windowRef) {
+	GioWindow *window = (__bridge GioWindow *)windowRef;
+	[NSApp activateIgnoringOtherApps:YES];
+	window.focusing = YES;
+	[window makeKeyAndOrderFront:nil];
+	window.focusing = NO;
+}
+
 CFTypeRef gio_createView(void) {
 	@autoreleasepool {
 		NSRect frame = NSMakeRect(0, 0, 0, 0);
@@ -390,6 +455,11 @@ CFTypeRef gio_createView(void) {
 - (void)applicationWillUnhide:(NSNotification *)notification {
 	gio_onAppShow();
 }
+- (void)application:(NSApplication *)application openURLs:(NSArray<NSURL *> *)urls {
+	// URLs can't contain newlines.
+	NSString *joined = [[urls valueForKey:@"absoluteString"] componentsJoinedByString:@"\n"];
+	gio_onOpenURLs((__bridge CFTypeRef)joined);
+}
 @end
 
 void gio_main() {
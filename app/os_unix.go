@@ -7,6 +7,7 @@ package app
 
 import (
 	"errors"
+	"net"
 	"unsafe"
 
 	"github.com/Seikaijyu/gio/io/pointer"
@@ -43,6 +44,10 @@ func osMain() {
 	select {}
 }
 
+// activateInstance does nothing, because the primary instance raises
+// its window itself.
+func activateInstance(c net.Conn) {}
+
 type windowDriver func(*callbacks, []Option) error
 
 // Instead of creating files with build tags for each combination of wayland +/- x11
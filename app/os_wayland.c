@@ -7,7 +7,12 @@
 #include <wayland-client.h>
 #include "wayland_xdg_shell.h"
 #include "wayland_xdg_decoration.h"
+#include "wayland_xdg_activation.h"
+#include "wayland_fractional_scale.h"
+#include "wayland_primary_selection.h"
+#include "wayland_xdg_foreign.h"
 #include "wayland_text_input.h"
+#include "wayland_tablet.h"
 #include "_cgo_export.h"
 
 const struct wl_registry_listener gio_registry_listener = {
@@ -34,6 +39,24 @@ const struct zxdg_toplevel_decoration_v1_listener gio_zxdg_toplevel_decoration_v
 	.configure = gio_onToplevelDecorationConfigure,
 };
 
+const struct xdg_activation_token_v1_listener gio_xdg_activation_token_v1_listener = {
+	// Cast away const parameter.
+	.done = (void (*)(void *, struct xdg_activation_token_v1 *, const char *))gio_onActivationTokenDone,
+};
+
+const struct wp_fractional_scale_v1_listener gio_wp_fractional_scale_v1_listener = {
+	.preferred_scale = gio_onPreferredScale,
+};
+
+const struct zxdg_exported_v2_listener gio_zxdg_exported_v2_listener = {
+	// Cast away const parameter.
+	.handle = (void (*)(void *, struct zxdg_exported_v2 *, const char *))gio_onExportedHandle,
+};
+
+const struct zxdg_imported_v2_listener gio_zxdg_imported_v2_listener = {
+	.destroyed = gio_onImportedDestroyed,
+};
+
 static void xdg_wm_base_handle_ping(void *data, struct xdg_wm_base *wm, uint32_t serial) {
 	xdg_wm_base_pong(wm, serial);
 }
@@ -70,6 +93,9 @@ const struct wl_pointer_listener gio_pointer_listener = {
 	.axis_source = gio_onPointerAxisSource,
 	.axis_stop = gio_onPointerAxisStop,
 	.axis_discrete = gio_onPointerAxisDiscrete,
+#ifdef WL_POINTER_AXIS_VALUE120_SINCE_VERSION
+	.axis_value120 = gio_onPointerAxisValue120,
+#endif
 };
 
 const struct wl_touch_listener gio_touch_listener = {
@@ -78,6 +104,11 @@ const struct wl_touch_listener gio_touch_listener = {
 	.motion = gio_onTouchMotion,
 	.frame = gio_onTouchFrame,
 	.cancel = gio_onTouchCancel,
+#ifdef WL_POINTER_AXIS_VALUE120_SINCE_VERSION
+	// Sent by seat version 6 and later.
+	.shape = gio_onTouchShape,
+	.orientation = gio_onTouchOrientation,
+#endif
 };
 
 const struct wl_keyboard_listener gio_keyboard_listener = {
@@ -114,6 +145,22 @@ const struct wl_data_offer_listener gio_data_offer_listener = {
 	.action = gio_onDataOfferAction,
 };
 
+const struct zwp_primary_selection_device_v1_listener gio_primary_selection_device_listener = {
+	.data_offer = gio_onPrimarySelectionDeviceOffer,
+	.selection = gio_onPrimarySelectionDeviceSelection,
+};
+
+const struct zwp_primary_selection_offer_v1_listener gio_primary_selection_offer_listener = {
+	// Cast away const parameter.
+	.offer = (void (*)(void *, struct zwp_primary_selection_offer_v1 *, const char *))gio_onPrimarySelectionOfferOffer,
+};
+
+const struct zwp_primary_selection_source_v1_listener gio_primary_selection_source_listener = {
+	// Cast away const parameter.
+	.send = (void (*)(void *, struct zwp_primary_selection_source_v1 *, const char *, int32_t))gio_onPrimarySelectionSourceSend,
+	.cancelled = gio_onPrimarySelectionSourceCancelled,
+};
+
 const struct wl_data_source_listener gio_data_source_listener = {
 	.target = (void (*)(void *, struct wl_data_source *, const char *))gio_onDataSourceTarget,
 	.send = (void (*)(void *, struct wl_data_source *, const char *, int32_t))gio_onDataSourceSend,
@@ -122,3 +169,62 @@ const struct wl_data_source_listener gio_data_source_listener = {
 	.dnd_finished = gio_onDataSourceDNDFinished,
 	.action = gio_onDataSourceAction,
 };
+
+// Tablets and pads are not used, and have no listeners.
+static void tablet_seat_handle_tablet_added(void *data, struct zwp_tablet_seat_v2 *seat, struct zwp_tablet_v2 *tablet) {
+}
+
+static void tablet_seat_handle_pad_added(void *data, struct zwp_tablet_seat_v2 *seat, struct zwp_tablet_pad_v2 *pad) {
+}
+
+const struct zwp_tablet_seat_v2_listener gio_tablet_seat_listener = {
+	.tablet_added = tablet_seat_handle_tablet_added,
+	.tool_added = gio_onTabletToolAdded,
+	.pad_added = tablet_seat_handle_pad_added,
+};
+
+static void tablet_tool_handle_hardware_serial(void *data, struct zwp_tablet_tool_v2 *tool, uint32_t hi, uint32_t lo) {
+}
+
+static void tablet_tool_handle_hardware_id_wacom(void *data, struct zwp_tablet_tool_v2 *tool, uint32_t hi, uint32_t lo) {
+}
+
+static void tablet_tool_handle_capability(void *data, struct zwp_tablet_tool_v2 *tool, uint32_t capability) {
+}
+
+static void tablet_tool_handle_done(void *data, struct zwp_tablet_tool_v2 *tool) {
+}
+
+static void tablet_tool_handle_distance(void *data, struct zwp_tablet_tool_v2 *tool, uint32_t distance) {
+}
+
+static void tablet_tool_handle_rotation(void *data, struct zwp_tablet_tool_v2 *tool, wl_fixed_t degrees) {
+}
+
+static void tablet_tool_handle_slider(void *data, struct zwp_tablet_tool_v2 *tool, int32_t position) {
+}
+
+static void tablet_tool_handle_wheel(void *data, struct zwp_tablet_tool_v2 *tool, wl_fixed_t degrees, int32_t clicks) {
+}
+
+const struct zwp_tablet_tool_v2_listener gio_tablet_tool_listener = {
+	.type = gio_onTabletToolType,
+	.hardware_serial = tablet_tool_handle_hardware_serial,
+	.hardware_id_wacom = tablet_tool_handle_hardware_id_wacom,
+	.capability = tablet_tool_handle_capability,
+	.done = tablet_tool_handle_done,
+	.removed = gio_onTabletToolRemoved,
+	.proximity_in = gio_onTabletToolProximityIn,
+	.proximity_out = gio_onTabletToolProximityOut,
+	.down = gio_onTabletToolDown,
+	.up = gio_onTabletToolUp,
+	.motion = gio_onTabletToolMotion,
+	.pressure = gio_onTabletToolPressure,
+	.distance = tablet_tool_handle_distance,
+	.tilt = gio_onTabletToolTilt,
+	.rotation = tablet_tool_handle_rotation,
+	.slider = tablet_tool_handle_slider,
+	.wheel = tablet_tool_handle_wheel,
+	.button = gio_onTabletToolButton,
+	.frame = gio_onTabletToolFrame,
+};
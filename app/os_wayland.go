@@ -8,6 +8,7 @@ package app
 
 import (
 	"bytes"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"image"
@@ -22,17 +23,19 @@ import (
 
 	syscall "golang.org/x/sys/unix"
 
+	"github.com/Seikaijyu/gio/app/internal/native"
 	"github.com/Seikaijyu/gio/app/internal/xkb"
 	"github.com/Seikaijyu/gio/f32"
 	"github.com/Seikaijyu/gio/internal/fling"
 	"github.com/Seikaijyu/gio/io/clipboard"
 	"github.com/Seikaijyu/gio/io/key"
 	"github.com/Seikaijyu/gio/io/pointer"
+	"github.com/Seikaijyu/gio/io/router"
 	"github.com/Seikaijyu/gio/io/system"
 	"github.com/Seikaijyu/gio/unit"
 )
 
-// Use wayland-scanner to generate glue code for the xdg-shell and xdg-decoration extensions.
+// Use wayland-scanner to generate glue code for the xdg-shell, xdg-decoration, xdg-activation, fractional-scale, viewporter, primary-selection, xdg-foreign, idle-inhibit and tablet extensions.
 //go:generate wayland-scanner client-header /usr/share/wayland-protocols/stable/xdg-shell/xdg-shell.xml wayland_xdg_shell.h
 //go:generate wayland-scanner private-code /usr/share/wayland-protocols/stable/xdg-shell/xdg-shell.xml wayland_xdg_shell.c
 
@@ -42,9 +45,37 @@ import (
 //go:generate wayland-scanner client-header /usr/share/wayland-protocols/unstable/xdg-decoration/xdg-decoration-unstable-v1.xml wayland_xdg_decoration.h
 //go:generate wayland-scanner private-code /usr/share/wayland-protocols/unstable/xdg-decoration/xdg-decoration-unstable-v1.xml wayland_xdg_decoration.c
 
+//go:generate wayland-scanner client-header /usr/share/wayland-protocols/staging/xdg-activation/xdg-activation-v1.xml wayland_xdg_activation.h
+//go:generate wayland-scanner private-code /usr/share/wayland-protocols/staging/xdg-activation/xdg-activation-v1.xml wayland_xdg_activation.c
+
+//go:generate wayland-scanner client-header /usr/share/wayland-protocols/staging/fractional-scale/fractional-scale-v1.xml wayland_fractional_scale.h
+//go:generate wayland-scanner private-code /usr/share/wayland-protocols/staging/fractional-scale/fractional-scale-v1.xml wayland_fractional_scale.c
+
+//go:generate wayland-scanner client-header /usr/share/wayland-protocols/stable/viewporter/viewporter.xml wayland_viewporter.h
+//go:generate wayland-scanner private-code /usr/share/wayland-protocols/stable/viewporter/viewporter.xml wayland_viewporter.c
+
+//go:generate wayland-scanner client-header /usr/share/wayland-protocols/unstable/primary-selection/primary-selection-unstable-v1.xml wayland_primary_selection.h
+//go:generate wayland-scanner private-code /usr/share/wayland-protocols/unstable/primary-selection/primary-selection-unstable-v1.xml wayland_primary_selection.c
+
+//go:generate wayland-scanner client-header /usr/share/wayland-protocols/unstable/xdg-foreign/xdg-foreign-unstable-v2.xml wayland_xdg_foreign.h
+//go:generate wayland-scanner private-code /usr/share/wayland-protocols/unstable/xdg-foreign/xdg-foreign-unstable-v2.xml wayland_xdg_foreign.c
+
+//go:generate wayland-scanner client-header /usr/share/wayland-protocols/unstable/idle-inhibit/idle-inhibit-unstable-v1.xml wayland_idle_inhibit.h
+//go:generate wayland-scanner private-code /usr/share/wayland-protocols/unstable/idle-inhibit/idle-inhibit-unstable-v1.xml wayland_idle_inhibit.c
+
+//go:generate wayland-scanner client-header /usr/share/wayland-protocols/unstable/tablet/tablet-unstable-v2.xml wayland_tablet.h
+//go:generate wayland-scanner private-code /usr/share/wayland-protocols/unstable/tablet/tablet-unstable-v2.xml wayland_tablet.c
+
 //go:generate sed -i "1s;^;//go:build ((linux \\&\\& !android) || freebsd) \\&\\& !nowayland\\n// +build linux,!android freebsd\\n// +build !nowayland\\n\\n;" wayland_xdg_shell.c
 //go:generate sed -i "1s;^;//go:build ((linux \\&\\& !android) || freebsd) \\&\\& !nowayland\\n// +build linux,!android freebsd\\n// +build !nowayland\\n\\n;" wayland_xdg_decoration.c
 //go:generate sed -i "1s;^;//go:build ((linux \\&\\& !android) || freebsd) \\&\\& !nowayland\\n// +build linux,!android freebsd\\n// +build !nowayland\\n\\n;" wayland_text_input.c
+//go:generate sed -i "1s;^;//go:build ((linux \\&\\& !android) || freebsd) \\&\\& !nowayland\\n// +build linux,!android freebsd\\n// +build !nowayland\\n\\n;" wayland_xdg_activation.c
+//go:generate sed -i "1s;^;//go:build ((linux \\&\\& !android) || freebsd) \\&\\& !nowayland\\n// +build linux,!android freebsd\\n// +build !nowayland\\n\\n;" wayland_fractional_scale.c
+//go:generate sed -i "1s;^;//go:build ((linux \\&\\& !android) || freebsd) \\&\\& !nowayland\\n// +build linux,!android freebsd\\n// +build !nowayland\\n\\n;" wayland_viewporter.c
+//go:generate sed -i "1s;^;//go:build ((linux \\&\\& !android) || freebsd) \\&\\& !nowayland\\n// +build linux,!android freebsd\\n// +build !nowayland\\n\\n;" wayland_primary_selection.c
+//go:generate sed -i "1s;^;//go:build ((linux \\&\\& !android) || freebsd) \\&\\& !nowayland\\n// +build linux,!android freebsd\\n// +build !nowayland\\n\\n;" wayland_xdg_foreign.c
+//go:generate sed -i "1s;^;//go:build ((linux \\&\\& !android) || freebsd) \\&\\& !nowayland\\n// +build linux,!android freebsd\\n// +build !nowayland\\n\\n;" wayland_idle_inhibit.c
+//go:generate sed -i "1s;^;//go:build ((linux \\&\\& !android) || freebsd) \\&\\& !nowayland\\n// +build linux,!android freebsd\\n// +build !nowayland\\n\\n;" wayland_tablet.c
 
 /*
 #cgo linux pkg-config: wayland-client wayland-cursor
@@ -58,12 +89,31 @@ import (
 #include "wayland_text_input.h"
 #include "wayland_xdg_shell.h"
 #include "wayland_xdg_decoration.h"
+#include "wayland_xdg_activation.h"
+#include "wayland_fractional_scale.h"
+#include "wayland_viewporter.h"
+#include "wayland_primary_selection.h"
+#include "wayland_xdg_foreign.h"
+#include "wayland_idle_inhibit.h"
+#include "wayland_tablet.h"
+
+// Seat version 8 replaces discrete axis events with high resolution
+// value120 events. Older headers don't declare them.
+#ifdef WL_POINTER_AXIS_VALUE120_SINCE_VERSION
+#define GIO_SEAT_VERSION 8
+#else
+#define GIO_SEAT_VERSION 5
+#endif
 
 extern const struct wl_registry_listener gio_registry_listener;
 extern const struct wl_surface_listener gio_surface_listener;
 extern const struct xdg_surface_listener gio_xdg_surface_listener;
 extern const struct xdg_toplevel_listener gio_xdg_toplevel_listener;
 extern const struct zxdg_toplevel_decoration_v1_listener gio_zxdg_toplevel_decoration_v1_listener;
+extern const struct xdg_activation_token_v1_listener gio_xdg_activation_token_v1_listener;
+extern const struct wp_fractional_scale_v1_listener gio_wp_fractional_scale_v1_listener;
+extern const struct zxdg_exported_v2_listener gio_zxdg_exported_v2_listener;
+extern const struct zxdg_imported_v2_listener gio_zxdg_imported_v2_listener;
 extern const struct xdg_wm_base_listener gio_xdg_wm_base_listener;
 extern const struct wl_callback_listener gio_callback_listener;
 extern const struct wl_output_listener gio_output_listener;
@@ -73,8 +123,13 @@ extern const struct wl_touch_listener gio_touch_listener;
 extern const struct wl_keyboard_listener gio_keyboard_listener;
 extern const struct zwp_text_input_v3_listener gio_zwp_text_input_v3_listener;
 extern const struct wl_data_device_listener gio_data_device_listener;
+extern const struct zwp_primary_selection_device_v1_listener gio_primary_selection_device_listener;
+extern const struct zwp_primary_selection_offer_v1_listener gio_primary_selection_offer_listener;
+extern const struct zwp_primary_selection_source_v1_listener gio_primary_selection_source_listener;
 extern const struct wl_data_offer_listener gio_data_offer_listener;
 extern const struct wl_data_source_listener gio_data_source_listener;
+extern const struct zwp_tablet_seat_v2_listener gio_tablet_seat_listener;
+extern const struct zwp_tablet_tool_v2_listener gio_tablet_tool_listener;
 */
 import "C"
 
@@ -86,7 +141,15 @@ type wlDisplay struct {
 	imm               *C.struct_zwp_text_input_manager_v3
 	shm               *C.struct_wl_shm
 	dataDeviceManager *C.struct_wl_data_device_manager
+	primaryManager    *C.struct_zwp_primary_selection_device_manager_v1
 	decor             *C.struct_zxdg_decoration_manager_v1
+	activation        *C.struct_xdg_activation_v1
+	fractionalScale   *C.struct_wp_fractional_scale_manager_v1
+	viewporter        *C.struct_wp_viewporter
+	exporter          *C.struct_zxdg_exporter_v2
+	importer          *C.struct_zxdg_importer_v2
+	idleInhibit       *C.struct_zwp_idle_inhibit_manager_v1
+	tabletManager     *C.struct_zwp_tablet_manager_v2
 	seat              *wlSeat
 	xkb               *xkb.Context
 	outputMap         map[C.uint32_t]*C.struct_wl_output
@@ -116,6 +179,12 @@ type wlSeat struct {
 	keyboardFocus *window
 	touchFoci     map[C.int32_t]*window
 
+	// Tablet support.
+	tabletSeat *C.struct_zwp_tablet_seat_v2
+	tools      map[*C.struct_zwp_tablet_tool_v2]*wlTool
+	// nextToolID is the pointer.ID of the next tool.
+	nextToolID pointer.ID
+
 	// Clipboard support.
 	dataDev *C.struct_wl_data_device
 	// offers is a map from active wl_data_offers to
@@ -130,6 +199,15 @@ type wlSeat struct {
 	source *C.struct_wl_data_source
 	// content is the data belonging to source.
 	content []byte
+
+	// Primary selection support, with the same structure as the
+	// clipboard support above.
+	primaryDev      *C.struct_zwp_primary_selection_device_v1
+	primaryOffers   map[*C.struct_zwp_primary_selection_offer_v1][]string
+	primary         *C.struct_zwp_primary_selection_offer_v1
+	primaryMimeType string
+	primarySource   *C.struct_zwp_primary_selection_source_v1
+	primaryContent  []byte
 }
 
 type repeatState struct {
@@ -154,11 +232,21 @@ type window struct {
 	wmSurf     *C.struct_xdg_surface
 	topLvl     *C.struct_xdg_toplevel
 	decor      *C.struct_zxdg_toplevel_decoration_v1
+	token      *C.struct_xdg_activation_token_v1 // Pending attention request.
+	focused    bool                              // Whether the window has keyboard focus.
+	fracScale  *C.struct_wp_fractional_scale_v1
+	viewport   *C.struct_wp_viewport
+	exported   *C.struct_zxdg_exported_v2 // Handle for dialogs of the window.
+	imported   *C.struct_zxdg_imported_v2 // Handle of the owner of a dialog.
+	inhibitor  *C.struct_zwp_idle_inhibitor_v1
 	ppdp, ppsp float32
 	scroll     struct {
 		time  time.Duration
 		steps image.Point
-		dist  f32.Point
+		// v120 is the high resolution wheel rotation, in 1/120ths
+		// of a step.
+		v120 image.Point
+		dist f32.Point
 	}
 	pointerBtns pointer.Buttons
 	lastPos     f32.Point
@@ -171,8 +259,14 @@ type window struct {
 		// such as border resizes and window moves. It
 		// is nil if the pointer is not in a system gesture
 		// area.
-		system  *C.struct_wl_cursor
-		surf    *C.struct_wl_surface
+		system *C.struct_wl_cursor
+		surf   *C.struct_wl_surface
+		// scale is the buffer scale of surf.
+		scale int
+		// image is the current image cursor, if any.
+		image *pointer.ImageCursor
+		// images caches the buffers of image cursors.
+		images  map[*pointer.ImageCursor]*C.struct_wl_buffer
 		cursors struct {
 			pointer         *C.struct_wl_cursor
 			resizeNorth     *C.struct_wl_cursor
@@ -203,6 +297,14 @@ type window struct {
 	// The most recent configure serial waiting to be ack'ed.
 	serial C.uint32_t
 	scale  int
+	// preferredScale is the fractional scale preferred by the
+	// compositor, or 0 if the integer scale is used.
+	preferredScale float32
+	// refresh is the refresh interval of the fastest output showing the
+	// window, or 0 if unknown.
+	refresh time.Duration
+	// viewportSize is the destination size of viewport.
+	viewportSize image.Point
 	// size is the unscaled window size (unlike config.Size which is scaled).
 	size         image.Point
 	config       Config
@@ -228,6 +330,8 @@ type wlOutput struct {
 	transform  C.int32_t
 	scale      int
 	windows    []*window
+	// refresh is the refresh rate of the current mode in mHz.
+	refresh int
 }
 
 // callbackMap maps Wayland native handles to corresponding Go
@@ -250,6 +354,11 @@ func init() {
 }
 
 func newWLWindow(callbacks *callbacks, options []Option) error {
+	if embedParent(options) != 0 {
+		// Wayland has no global window handles; embedding is left
+		// to XWayland.
+		return errors.New("wayland: NewWindowFrom is not supported")
+	}
 	d, err := newWLDisplay()
 	if err != nil {
 		return err
@@ -276,6 +385,7 @@ func newWLWindow(callbacks *callbacks, options []Option) error {
 		})
 
 		err := w.loop()
+		w.w.SetOwnerHandle(nil)
 		w.w.Event(WaylandViewEvent{})
 		w.w.Event(system.DestroyEvent{Err: err})
 	}()
@@ -306,6 +416,48 @@ func (d *wlDisplay) writeClipboard(content []byte) error {
 	return nil
 }
 
+func (d *wlDisplay) writePrimary(content []byte) {
+	s := d.seat
+	if s == nil {
+		return
+	}
+	// Clear old offer.
+	if s.primarySource != nil {
+		C.zwp_primary_selection_source_v1_destroy(s.primarySource)
+		s.primarySource = nil
+		s.primaryContent = nil
+	}
+	if d.primaryManager == nil || s.primaryDev == nil {
+		return
+	}
+	s.primaryContent = content
+	s.primarySource = C.zwp_primary_selection_device_manager_v1_create_source(d.primaryManager)
+	C.zwp_primary_selection_source_v1_add_listener(s.primarySource, &C.gio_primary_selection_source_listener, unsafe.Pointer(s.seat))
+	for _, mime := range clipboardMimeTypes {
+		cmime := C.CString(mime)
+		C.zwp_primary_selection_source_v1_offer(s.primarySource, cmime)
+		C.free(unsafe.Pointer(cmime))
+	}
+	C.zwp_primary_selection_device_v1_set_selection(s.primaryDev, s.primarySource, s.serial)
+}
+
+func (d *wlDisplay) readPrimary() (io.ReadCloser, error) {
+	s := d.seat
+	if s == nil || s.primary == nil {
+		return nil, nil
+	}
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	// The receive request dups the write end of the pipe.
+	defer w.Close()
+	cmimeType := C.CString(s.primaryMimeType)
+	defer C.free(unsafe.Pointer(cmimeType))
+	C.zwp_primary_selection_offer_v1_receive(s.primary, cmimeType, C.int32_t(w.Fd()))
+	return r, nil
+}
+
 func (d *wlDisplay) readClipboard() (io.ReadCloser, error) {
 	s := d.seat
 	if s == nil {
@@ -347,6 +499,7 @@ func (d *wlDisplay) createNativeWindow(options []Option) (*window, error) {
 		}
 	}
 	ppdp := detectUIScale()
+	ownerHandle, _ := dialogOwner(options)
 
 	w := &window{
 		disp:      d,
@@ -404,7 +557,8 @@ func (d *wlDisplay) createNativeWindow(options []Option) (*window, error) {
 		w.destroy()
 		return nil, errors.New("wayland: wl_compositor_create_surface failed")
 	}
-	C.wl_surface_set_buffer_scale(w.cursor.surf, C.int32_t(w.scale))
+	w.cursor.scale = w.scale
+	C.wl_surface_set_buffer_scale(w.cursor.surf, C.int32_t(w.cursor.scale))
 	C.xdg_wm_base_add_listener(d.wm, &C.gio_xdg_wm_base_listener, unsafe.Pointer(w.surf))
 	C.wl_surface_add_listener(w.surf, &C.gio_surface_listener, unsafe.Pointer(w.surf))
 	C.xdg_surface_add_listener(w.wmSurf, &C.gio_xdg_surface_listener, unsafe.Pointer(w.surf))
@@ -414,6 +568,26 @@ func (d *wlDisplay) createNativeWindow(options []Option) (*window, error) {
 		w.decor = C.zxdg_decoration_manager_v1_get_toplevel_decoration(d.decor, w.topLvl)
 		C.zxdg_toplevel_decoration_v1_add_listener(w.decor, &C.gio_zxdg_toplevel_decoration_v1_listener, unsafe.Pointer(w.surf))
 	}
+	// Fractional scaling needs both the preferred scale and a viewport
+	// for scaling the buffer down to the surface size.
+	if d.fractionalScale != nil && d.viewporter != nil {
+		w.fracScale = C.wp_fractional_scale_manager_v1_get_fractional_scale(d.fractionalScale, w.surf)
+		C.wp_fractional_scale_v1_add_listener(w.fracScale, &C.gio_wp_fractional_scale_v1_listener, unsafe.Pointer(w.surf))
+		w.viewport = C.wp_viewporter_get_viewport(d.viewporter, w.surf)
+	}
+	// Each window has its own display connection, so owners and
+	// dialogs refer to each other through exported handles.
+	if d.exporter != nil {
+		w.exported = C.zxdg_exporter_v2_export_toplevel(d.exporter, w.surf)
+		C.zxdg_exported_v2_add_listener(w.exported, &C.gio_zxdg_exported_v2_listener, unsafe.Pointer(w.surf))
+	}
+	if h, ok := ownerHandle.(string); ok && d.importer != nil {
+		chandle := C.CString(h)
+		defer C.free(unsafe.Pointer(chandle))
+		w.imported = C.zxdg_importer_v2_import_toplevel(d.importer, chandle)
+		C.zxdg_imported_v2_add_listener(w.imported, &C.gio_zxdg_imported_v2_listener, unsafe.Pointer(w.surf))
+		C.zxdg_imported_v2_set_parent_of(w.imported, w.surf)
+	}
 	w.updateOpaqueRegion()
 	return w, nil
 }
@@ -481,11 +655,27 @@ func (s *wlSeat) flushOffers() {
 	}
 }
 
+// flushPrimaryOffers is like flushOffers for the primary selection.
+func (s *wlSeat) flushPrimaryOffers() {
+	for o := range s.primaryOffers {
+		if o == s.primary {
+			continue
+		}
+		delete(s.primaryOffers, o)
+		callbackDelete(unsafe.Pointer(o))
+		C.zwp_primary_selection_offer_v1_destroy(o)
+	}
+}
+
 func (s *wlSeat) destroy() {
 	if s.source != nil {
 		C.wl_data_source_destroy(s.source)
 		s.source = nil
 	}
+	if s.primarySource != nil {
+		C.zwp_primary_selection_source_v1_destroy(s.primarySource)
+		s.primarySource = nil
+	}
 	if s.im != nil {
 		C.zwp_text_input_v3_destroy(s.im)
 		s.im = nil
@@ -504,6 +694,19 @@ func (s *wlSeat) destroy() {
 	if s.dataDev != nil {
 		C.wl_data_device_release(s.dataDev)
 	}
+	s.primary = nil
+	s.flushPrimaryOffers()
+	if s.primaryDev != nil {
+		callbackDelete(unsafe.Pointer(s.primaryDev))
+		C.zwp_primary_selection_device_v1_destroy(s.primaryDev)
+	}
+	for _, t := range s.tools {
+		t.destroy()
+	}
+	if s.tabletSeat != nil {
+		callbackDelete(unsafe.Pointer(s.tabletSeat))
+		C.zwp_tablet_seat_v2_destroy(s.tabletSeat)
+	}
 	if s.seat != nil {
 		callbackDelete(unsafe.Pointer(s.seat))
 		C.wl_seat_release(s.seat)
@@ -600,6 +803,7 @@ func gio_onOutputMode(data unsafe.Pointer, output *C.struct_wl_output, flags C.u
 	c := d.outputConfig[output]
 	c.width = int(width)
 	c.height = int(height)
+	c.refresh = int(refresh)
 }
 
 //export gio_onOutputGeometry
@@ -677,7 +881,10 @@ func gio_onRegistryGlobal(data unsafe.Pointer, reg *C.struct_wl_registry, name C
 		if d.seat != nil {
 			break
 		}
-		s := (*C.struct_wl_seat)(C.wl_registry_bind(reg, name, &C.wl_seat_interface, 5))
+		if version > C.GIO_SEAT_VERSION {
+			version = C.GIO_SEAT_VERSION
+		}
+		s := (*C.struct_wl_seat)(C.wl_registry_bind(reg, name, &C.wl_seat_interface, version))
 		if s == nil {
 			// No support for v5 protocol.
 			break
@@ -688,22 +895,45 @@ func gio_onRegistryGlobal(data unsafe.Pointer, reg *C.struct_wl_registry, name C
 			seat:      s,
 			offers:    make(map[*C.struct_wl_data_offer][]string),
 			touchFoci: make(map[C.int32_t]*window),
+			tools:     make(map[*C.struct_zwp_tablet_tool_v2]*wlTool),
+
+			primaryOffers: make(map[*C.struct_zwp_primary_selection_offer_v1][]string),
 		}
 		callbackStore(unsafe.Pointer(s), d.seat)
 		C.wl_seat_add_listener(s, &C.gio_seat_listener, unsafe.Pointer(s))
 		d.bindDataDevice()
+		d.bindPrimaryDevice()
+		d.bindTabletSeat()
 	case "wl_shm":
 		d.shm = (*C.struct_wl_shm)(C.wl_registry_bind(reg, name, &C.wl_shm_interface, 1))
 	case "xdg_wm_base":
 		d.wm = (*C.struct_xdg_wm_base)(C.wl_registry_bind(reg, name, &C.xdg_wm_base_interface, 1))
 	case "zxdg_decoration_manager_v1":
 		d.decor = (*C.struct_zxdg_decoration_manager_v1)(C.wl_registry_bind(reg, name, &C.zxdg_decoration_manager_v1_interface, 1))
+	case "xdg_activation_v1":
+		d.activation = (*C.struct_xdg_activation_v1)(C.wl_registry_bind(reg, name, &C.xdg_activation_v1_interface, 1))
+	case "wp_fractional_scale_manager_v1":
+		d.fractionalScale = (*C.struct_wp_fractional_scale_manager_v1)(C.wl_registry_bind(reg, name, &C.wp_fractional_scale_manager_v1_interface, 1))
+	case "wp_viewporter":
+		d.viewporter = (*C.struct_wp_viewporter)(C.wl_registry_bind(reg, name, &C.wp_viewporter_interface, 1))
+	case "zxdg_exporter_v2":
+		d.exporter = (*C.struct_zxdg_exporter_v2)(C.wl_registry_bind(reg, name, &C.zxdg_exporter_v2_interface, 1))
+	case "zxdg_importer_v2":
+		d.importer = (*C.struct_zxdg_importer_v2)(C.wl_registry_bind(reg, name, &C.zxdg_importer_v2_interface, 1))
+	case "zwp_idle_inhibit_manager_v1":
+		d.idleInhibit = (*C.struct_zwp_idle_inhibit_manager_v1)(C.wl_registry_bind(reg, name, &C.zwp_idle_inhibit_manager_v1_interface, 1))
 		// TODO: Implement and test text-input support.
 		/*case "zwp_text_input_manager_v3":
 		d.imm = (*C.struct_zwp_text_input_manager_v3)(C.wl_registry_bind(reg, name, &C.zwp_text_input_manager_v3_interface, 1))*/
 	case "wl_data_device_manager":
 		d.dataDeviceManager = (*C.struct_wl_data_device_manager)(C.wl_registry_bind(reg, name, &C.wl_data_device_manager_interface, 3))
 		d.bindDataDevice()
+	case "zwp_primary_selection_device_manager_v1":
+		d.primaryManager = (*C.struct_zwp_primary_selection_device_manager_v1)(C.wl_registry_bind(reg, name, &C.zwp_primary_selection_device_manager_v1_interface, 1))
+		d.bindPrimaryDevice()
+	case "zwp_tablet_manager_v2":
+		d.tabletManager = (*C.struct_zwp_tablet_manager_v2)(C.wl_registry_bind(reg, name, &C.zwp_tablet_manager_v2_interface, 1))
+		d.bindTabletSeat()
 	}
 }
 
@@ -766,6 +996,38 @@ loop:
 	}
 }
 
+//export gio_onPrimarySelectionOfferOffer
+func gio_onPrimarySelectionOfferOffer(data unsafe.Pointer, offer *C.struct_zwp_primary_selection_offer_v1, mime *C.char) {
+	s := callbackLoad(data).(*wlSeat)
+	s.primaryOffers[offer] = append(s.primaryOffers[offer], C.GoString(mime))
+}
+
+//export gio_onPrimarySelectionDeviceOffer
+func gio_onPrimarySelectionDeviceOffer(data unsafe.Pointer, dev *C.struct_zwp_primary_selection_device_v1, id *C.struct_zwp_primary_selection_offer_v1) {
+	s := callbackLoad(data).(*wlSeat)
+	callbackStore(unsafe.Pointer(id), s)
+	C.zwp_primary_selection_offer_v1_add_listener(id, &C.gio_primary_selection_offer_listener, unsafe.Pointer(id))
+	s.primaryOffers[id] = nil
+}
+
+//export gio_onPrimarySelectionDeviceSelection
+func gio_onPrimarySelectionDeviceSelection(data unsafe.Pointer, dev *C.struct_zwp_primary_selection_device_v1, id *C.struct_zwp_primary_selection_offer_v1) {
+	s := callbackLoad(data).(*wlSeat)
+	defer s.flushPrimaryOffers()
+	s.primary = nil
+loop:
+	for _, want := range clipboardMimeTypes {
+		for _, got := range s.primaryOffers[id] {
+			if want != got {
+				continue
+			}
+			s.primary = id
+			s.primaryMimeType = got
+			break loop
+		}
+	}
+}
+
 //export gio_onRegistryGlobalRemove
 func gio_onRegistryGlobalRemove(data unsafe.Pointer, reg *C.struct_wl_registry, name C.uint32_t) {
 	d := callbackLoad(data).(*wlDisplay)
@@ -787,8 +1049,8 @@ func gio_onTouchDown(data unsafe.Pointer, touch *C.struct_wl_touch, serial, t C.
 	w := callbackLoad(unsafe.Pointer(surf)).(*window)
 	s.touchFoci[id] = w
 	w.lastTouch = f32.Point{
-		X: fromFixed(x) * float32(w.scale),
-		Y: fromFixed(y) * float32(w.scale),
+		X: fromFixed(x) * w.scaleFactor(),
+		Y: fromFixed(y) * w.scaleFactor(),
 	}
 	w.w.Event(pointer.Event{
 		Kind:      pointer.Press,
@@ -821,8 +1083,8 @@ func gio_onTouchMotion(data unsafe.Pointer, touch *C.struct_wl_touch, t C.uint32
 	s := callbackLoad(data).(*wlSeat)
 	w := s.touchFoci[id]
 	w.lastTouch = f32.Point{
-		X: fromFixed(x) * float32(w.scale),
-		Y: fromFixed(y) * float32(w.scale),
+		X: fromFixed(x) * w.scaleFactor(),
+		Y: fromFixed(y) * w.scaleFactor(),
 	}
 	w.w.Event(pointer.Event{
 		Kind:      pointer.Move,
@@ -838,6 +1100,14 @@ func gio_onTouchMotion(data unsafe.Pointer, touch *C.struct_wl_touch, t C.uint32
 func gio_onTouchFrame(data unsafe.Pointer, touch *C.struct_wl_touch) {
 }
 
+//export gio_onTouchShape
+func gio_onTouchShape(data unsafe.Pointer, touch *C.struct_wl_touch, id C.int32_t, major, minor C.wl_fixed_t) {
+}
+
+//export gio_onTouchOrientation
+func gio_onTouchOrientation(data unsafe.Pointer, touch *C.struct_wl_touch, id C.int32_t, orientation C.wl_fixed_t) {
+}
+
 //export gio_onTouchCancel
 func gio_onTouchCancel(data unsafe.Pointer, touch *C.struct_wl_touch) {
 	s := callbackLoad(data).(*wlSeat)
@@ -850,6 +1120,168 @@ func gio_onTouchCancel(data unsafe.Pointer, touch *C.struct_wl_touch) {
 	}
 }
 
+// wlTool is a tablet tool, such as a pen.
+type wlTool struct {
+	seat   *wlSeat
+	tool   *C.struct_zwp_tablet_tool_v2
+	id     pointer.ID
+	source pointer.Source
+	// focus is the window the tool is in proximity of.
+	focus *window
+
+	// The state of the tool, reported by the frame event.
+	pos          f32.Point
+	pressure     float32
+	tiltX, tiltY float32
+	buttons      pointer.Buttons
+	// kind is the pointer.Kind of the current frame.
+	kind pointer.Kind
+}
+
+// Linux input codes of the stylus barrel buttons.
+const (
+	btnStylus  = 0x14b
+	btnStylus2 = 0x14c
+)
+
+func (t *wlTool) destroy() {
+	delete(t.seat.tools, t.tool)
+	callbackDelete(unsafe.Pointer(t.tool))
+	C.zwp_tablet_tool_v2_destroy(t.tool)
+}
+
+//export gio_onTabletToolAdded
+func gio_onTabletToolAdded(data unsafe.Pointer, seat *C.struct_zwp_tablet_seat_v2, id *C.struct_zwp_tablet_tool_v2) {
+	s := callbackLoad(data).(*wlSeat)
+	// Offset tool ids to tell them apart from touch points.
+	t := &wlTool{seat: s, tool: id, id: 0x8000 + s.nextToolID, source: pointer.Stylus, kind: pointer.Move}
+	s.nextToolID++
+	s.tools[id] = t
+	callbackStore(unsafe.Pointer(id), t)
+	C.zwp_tablet_tool_v2_add_listener(id, &C.gio_tablet_tool_listener, unsafe.Pointer(id))
+}
+
+//export gio_onTabletToolType
+func gio_onTabletToolType(data unsafe.Pointer, tool *C.struct_zwp_tablet_tool_v2, typ C.uint32_t) {
+	t := callbackLoad(data).(*wlTool)
+	switch typ {
+	case C.ZWP_TABLET_TOOL_V2_TYPE_ERASER:
+		t.source = pointer.Eraser
+	case C.ZWP_TABLET_TOOL_V2_TYPE_MOUSE, C.ZWP_TABLET_TOOL_V2_TYPE_LENS:
+		t.source = pointer.Mouse
+	default:
+		t.source = pointer.Stylus
+	}
+}
+
+//export gio_onTabletToolRemoved
+func gio_onTabletToolRemoved(data unsafe.Pointer, tool *C.struct_zwp_tablet_tool_v2) {
+	t := callbackLoad(data).(*wlTool)
+	t.destroy()
+}
+
+//export gio_onTabletToolProximityIn
+func gio_onTabletToolProximityIn(data unsafe.Pointer, tool *C.struct_zwp_tablet_tool_v2, serial C.uint32_t, tablet *C.struct_zwp_tablet_v2, surf *C.struct_wl_surface) {
+	t := callbackLoad(data).(*wlTool)
+	t.seat.serial = serial
+	t.focus = callbackLoad(unsafe.Pointer(surf)).(*window)
+}
+
+//export gio_onTabletToolProximityOut
+func gio_onTabletToolProximityOut(data unsafe.Pointer, tool *C.struct_zwp_tablet_tool_v2) {
+	t := callbackLoad(data).(*wlTool)
+	t.kind = pointer.Leave
+}
+
+//export gio_onTabletToolDown
+func gio_onTabletToolDown(data unsafe.Pointer, tool *C.struct_zwp_tablet_tool_v2, serial C.uint32_t) {
+	t := callbackLoad(data).(*wlTool)
+	t.seat.serial = serial
+	t.buttons |= pointer.ButtonPrimary
+	t.kind = pointer.Press
+}
+
+//export gio_onTabletToolUp
+func gio_onTabletToolUp(data unsafe.Pointer, tool *C.struct_zwp_tablet_tool_v2) {
+	t := callbackLoad(data).(*wlTool)
+	t.buttons &^= pointer.ButtonPrimary
+	t.kind = pointer.Release
+}
+
+//export gio_onTabletToolMotion
+func gio_onTabletToolMotion(data unsafe.Pointer, tool *C.struct_zwp_tablet_tool_v2, x, y C.wl_fixed_t) {
+	t := callbackLoad(data).(*wlTool)
+	if w := t.focus; w != nil {
+		t.pos = f32.Point{
+			X: fromFixed(x) * w.scaleFactor(),
+			Y: fromFixed(y) * w.scaleFactor(),
+		}
+	}
+}
+
+//export gio_onTabletToolPressure
+func gio_onTabletToolPressure(data unsafe.Pointer, tool *C.struct_zwp_tablet_tool_v2, pressure C.uint32_t) {
+	t := callbackLoad(data).(*wlTool)
+	t.pressure = float32(pressure) / 65535
+}
+
+//export gio_onTabletToolTilt
+func gio_onTabletToolTilt(data unsafe.Pointer, tool *C.struct_zwp_tablet_tool_v2, tiltX, tiltY C.wl_fixed_t) {
+	t := callbackLoad(data).(*wlTool)
+	t.tiltX, t.tiltY = fromFixed(tiltX), fromFixed(tiltY)
+}
+
+//export gio_onTabletToolButton
+func gio_onTabletToolButton(data unsafe.Pointer, tool *C.struct_zwp_tablet_tool_v2, serial, button, state C.uint32_t) {
+	t := callbackLoad(data).(*wlTool)
+	t.seat.serial = serial
+	var btn pointer.Buttons
+	switch button {
+	case btnStylus:
+		btn = pointer.ButtonSecondary
+	case btnStylus2:
+		btn = pointer.ButtonTertiary
+	default:
+		return
+	}
+	if state == C.ZWP_TABLET_TOOL_V2_BUTTON_STATE_PRESSED {
+		t.buttons |= btn
+		t.kind = pointer.Press
+	} else {
+		t.buttons &^= btn
+		t.kind = pointer.Release
+	}
+}
+
+//export gio_onTabletToolFrame
+func gio_onTabletToolFrame(data unsafe.Pointer, tool *C.struct_zwp_tablet_tool_v2, ti C.uint32_t) {
+	t := callbackLoad(data).(*wlTool)
+	kind := t.kind
+	t.kind = pointer.Move
+	w := t.focus
+	if w == nil {
+		return
+	}
+	e := pointer.Event{
+		Kind:      kind,
+		Source:    t.source,
+		PointerID: t.id,
+		Position:  t.pos,
+		Buttons:   t.buttons,
+		Time:      time.Duration(ti) * time.Millisecond,
+		Modifiers: w.disp.xkb.Modifiers(),
+	}
+	if t.source != pointer.Mouse {
+		e.Pressure = t.pressure
+		e.TiltX, e.TiltY = t.tiltX, t.tiltY
+	}
+	if kind == pointer.Leave {
+		t.focus = nil
+		t.buttons = 0
+	}
+	w.w.Event(e)
+}
+
 //export gio_onPointerEnter
 func gio_onPointerEnter(data unsafe.Pointer, pointer *C.struct_wl_pointer, serial C.uint32_t, surf *C.struct_wl_surface, x, y C.wl_fixed_t) {
 	s := callbackLoad(data).(*wlSeat)
@@ -1017,6 +1449,24 @@ func gio_onPointerAxisDiscrete(data unsafe.Pointer, p *C.struct_wl_pointer, axis
 	}
 }
 
+//export gio_onPointerAxisValue120
+func gio_onPointerAxisValue120(data unsafe.Pointer, p *C.struct_wl_pointer, axis C.uint32_t, value120 C.int32_t) {
+	s := callbackLoad(data).(*wlSeat)
+	w := s.pointerFocus
+	w.resetFling()
+	switch axis {
+	case C.WL_POINTER_AXIS_HORIZONTAL_SCROLL:
+		w.scroll.v120.X += int(value120)
+	case C.WL_POINTER_AXIS_VERTICAL_SCROLL:
+		// horizontal scroll if shift + mousewheel(up/down) pressed.
+		if w.disp.xkb.Modifiers() == key.ModShift {
+			w.scroll.v120.X += int(value120)
+		} else {
+			w.scroll.v120.Y += int(value120)
+		}
+	}
+}
+
 func (w *window) ReadClipboard() {
 	r, err := w.disp.readClipboard()
 	// Send empty responses on unavailable clipboards or errors.
@@ -1037,12 +1487,32 @@ func (w *window) WriteClipboard(s string) {
 	w.disp.writeClipboard([]byte(s))
 }
 
+func (w *window) ReadPrimary() {
+	r, err := w.disp.readPrimary()
+	// Send empty responses on unavailable selections or errors.
+	if r == nil || err != nil {
+		w.w.Event(clipboard.Event{Primary: true})
+		return
+	}
+	go func() {
+		defer r.Close()
+		data, _ := io.ReadAll(r)
+		w.clipReads <- clipboard.Event{Text: string(data), Primary: true}
+		w.Wakeup()
+	}()
+}
+
+func (w *window) WritePrimary(s string) {
+	w.disp.writePrimary([]byte(s))
+}
+
 func (w *window) Configure(options []Option) {
 	_, cfg := w.getConfig()
 	prev := w.config
 	cnf := w.config
 	cnf.apply(cfg, options)
 	w.config.decoHeight = cnf.decoHeight
+	w.setKeepScreenOn(cnf.KeepScreenOn)
 
 	switch cnf.Mode {
 	case Fullscreen:
@@ -1073,20 +1543,20 @@ func (w *window) Configure(options []Option) {
 		switch prev.Mode {
 		case Fullscreen:
 			w.config.Mode = Windowed
-			w.size = w.wsize.Div(w.scale)
+			w.size = w.surfaceSize(w.wsize)
 			C.xdg_toplevel_unset_fullscreen(w.topLvl)
 		case Minimized:
 			w.config.Mode = Windowed
 		case Maximized:
 			w.config.Mode = Windowed
-			w.size = w.wsize.Div(w.scale)
+			w.size = w.surfaceSize(w.wsize)
 			C.xdg_toplevel_unset_maximized(w.topLvl)
 		}
 		w.setTitle(prev, cnf)
 		if prev.Size != cnf.Size {
 			w.config.Size = cnf.Size
-			w.config.Size.Y += int(w.decoHeight()) * w.scale
-			w.size = w.config.Size.Div(w.scale)
+			w.config.Size.Y += w.bufferSize(image.Pt(0, w.decoHeight())).Y
+			w.size = w.surfaceSize(w.config.Size)
 		}
 		w.config.MinSize = cnf.MinSize
 		w.config.MaxSize = cnf.MaxSize
@@ -1096,12 +1566,25 @@ func (w *window) Configure(options []Option) {
 	w.redraw = true
 }
 
+// setKeepScreenOn creates or destroys the idle inhibitor of the
+// window. The compositor only honors it while the window is visible.
+func (w *window) setKeepScreenOn(on bool) {
+	w.config.KeepScreenOn = on
+	if on && w.inhibitor == nil && w.disp.idleInhibit != nil {
+		w.inhibitor = C.zwp_idle_inhibit_manager_v1_create_inhibitor(w.disp.idleInhibit, w.surf)
+	}
+	if !on && w.inhibitor != nil {
+		C.zwp_idle_inhibitor_v1_destroy(w.inhibitor)
+		w.inhibitor = nil
+	}
+}
+
 func (w *window) setWindowConstraints() {
 	decoHeight := w.decoHeight()
-	if scaled := w.config.MinSize.Div(w.scale); scaled != (image.Point{}) {
+	if scaled := w.surfaceSize(w.config.MinSize); scaled != (image.Point{}) {
 		C.xdg_toplevel_set_min_size(w.topLvl, C.int32_t(scaled.X), C.int32_t(scaled.Y+decoHeight))
 	}
-	if scaled := w.config.MaxSize.Div(w.scale); scaled != (image.Point{}) {
+	if scaled := w.surfaceSize(w.config.MaxSize); scaled != (image.Point{}) {
 		C.xdg_toplevel_set_max_size(w.topLvl, C.int32_t(scaled.X), C.int32_t(scaled.Y+decoHeight))
 	}
 }
@@ -1131,10 +1614,79 @@ func (w *window) Perform(actions system.Action) {
 		switch action {
 		case system.ActionClose:
 			w.dead = true
+		case system.ActionRequestAttention:
+			// A focused window needs no attention.
+			if !w.focused {
+				w.requestActivation(false)
+			}
+		case system.ActionFocus:
+			w.requestActivation(true)
 		}
 	})
 }
 
+// requestActivation asks the compositor to activate the window, with a
+// token carrying the most recent input serial of the seat. With focus,
+// the token names the focused window, which lets compositors activate
+// the window while another window of the program is focused. Without
+// focus, the token names the window itself; compositors that prevent
+// focus stealing don't activate the window for such tokens, and mark
+// it as demanding attention instead.
+func (w *window) requestActivation(focus bool) {
+	a := w.disp.activation
+	if a == nil || w.token != nil {
+		return
+	}
+	w.token = C.xdg_activation_v1_get_activation_token(a)
+	C.xdg_activation_token_v1_add_listener(w.token, &C.gio_xdg_activation_token_v1_listener, unsafe.Pointer(w.surf))
+	surf := w.surf
+	if s := w.disp.seat; s != nil {
+		C.xdg_activation_token_v1_set_serial(w.token, s.serial, s.seat)
+		if f := s.keyboardFocus; focus && f != nil && f.focused {
+			surf = f.surf
+		}
+	}
+	C.xdg_activation_token_v1_set_surface(w.token, surf)
+	C.xdg_activation_token_v1_commit(w.token)
+}
+
+//export gio_onPreferredScale
+func gio_onPreferredScale(data unsafe.Pointer, fracScale *C.struct_wp_fractional_scale_v1, scale C.uint32_t) {
+	w := callbackLoad(data).(*window)
+	// The scale is the numerator of a fraction with denominator 120.
+	s := float32(scale) / 120
+	if s == w.preferredScale {
+		return
+	}
+	if w.preferredScale == 0 {
+		// The viewport scales the buffer from now on.
+		C.wl_surface_set_buffer_scale(w.surf, 1)
+	}
+	w.preferredScale = s
+	w.redraw = true
+}
+
+//export gio_onExportedHandle
+func gio_onExportedHandle(data unsafe.Pointer, exported *C.struct_zxdg_exported_v2, handle *C.char) {
+	w := callbackLoad(data).(*window)
+	w.w.SetOwnerHandle(C.GoString(handle))
+}
+
+//export gio_onImportedDestroyed
+func gio_onImportedDestroyed(data unsafe.Pointer, imported *C.struct_zxdg_imported_v2) {
+	w := callbackLoad(data).(*window)
+	C.zxdg_imported_v2_destroy(imported)
+	w.imported = nil
+}
+
+//export gio_onActivationTokenDone
+func gio_onActivationTokenDone(data unsafe.Pointer, token *C.struct_xdg_activation_token_v1, ctoken *C.char) {
+	w := callbackLoad(data).(*window)
+	C.xdg_activation_token_v1_destroy(token)
+	w.token = nil
+	C.xdg_activation_v1_activate(w.disp.activation, ctoken, w.surf)
+}
+
 func (w *window) move(serial C.uint32_t) {
 	s := w.seat
 	if !w.inCompositor && s != nil {
@@ -1153,10 +1705,79 @@ func (w *window) resize(serial, edge C.uint32_t) {
 }
 
 func (w *window) SetCursor(cursor pointer.Cursor) {
+	w.cursor.image = nil
 	w.cursor.cursor = w.loadCursor(cursor)
 	w.updateCursor()
 }
 
+func (w *window) SetImageCursor(cursor *pointer.ImageCursor) {
+	if _, ok := w.cursor.images[cursor]; !ok {
+		buf, err := w.newCursorBuffer(cursor)
+		if err != nil {
+			w.SetCursor(pointer.CursorDefault)
+			return
+		}
+		if w.cursor.images == nil {
+			w.cursor.images = make(map[*pointer.ImageCursor]*C.struct_wl_buffer)
+		}
+		w.cursor.images[cursor] = buf
+	}
+	w.cursor.image = cursor
+	w.cursor.cursor = w.cursor.cursors.pointer
+	w.updateCursor()
+	if len(w.cursor.images) > maxImageCursors {
+		for k, buf := range w.cursor.images {
+			if k != cursor {
+				C.wl_buffer_destroy(buf)
+				delete(w.cursor.images, k)
+			}
+		}
+	}
+}
+
+// SetNativeViews is not supported, because native views would have to
+// be subsurfaces of the window surface. Native views are ignored, as
+// documented in package native.
+func (w *window) SetNativeViews(views []router.NativeView) {}
+
+// newCursorBuffer creates a shared memory buffer with the pixels of
+// an image cursor. The buffer is padded to a multiple of the cursor
+// surface scale, as required by the protocol.
+func (w *window) newCursorBuffer(cursor *pointer.ImageCursor) (*C.struct_wl_buffer, error) {
+	sz := cursor.Image().Bounds().Size()
+	if sz.X == 0 || sz.Y == 0 {
+		return nil, errors.New("wayland: empty cursor image")
+	}
+	scale := w.cursor.scale
+	width := (sz.X + scale - 1) / scale * scale
+	height := (sz.Y + scale - 1) / scale * scale
+	stride := width * 4
+	pix := make([]byte, stride*height)
+	for i, p := range imageCursorARGB(cursor, true) {
+		x, y := i%sz.X, i/sz.X
+		binary.LittleEndian.PutUint32(pix[y*stride+x*4:], p)
+	}
+	f, err := os.CreateTemp(os.Getenv("XDG_RUNTIME_DIR"), "gio-cursor-")
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	os.Remove(f.Name())
+	if _, err := f.Write(pix); err != nil {
+		return nil, err
+	}
+	pool := C.wl_shm_create_pool(w.disp.shm, C.int32_t(f.Fd()), C.int32_t(len(pix)))
+	if pool == nil {
+		return nil, errors.New("wayland: wl_shm_create_pool failed")
+	}
+	defer C.wl_shm_pool_destroy(pool)
+	buf := C.wl_shm_pool_create_buffer(pool, 0, C.int32_t(width), C.int32_t(height), C.int32_t(stride), C.WL_SHM_FORMAT_ARGB8888)
+	if buf == nil {
+		return nil, errors.New("wayland: wl_shm_pool_create_buffer failed")
+	}
+	return buf, nil
+}
+
 func (w *window) updateCursor() {
 	ptr := w.disp.seat.pointer
 	if ptr == nil {
@@ -1168,6 +1789,17 @@ func (w *window) updateCursor() {
 func (w *window) setCursor(pointer *C.struct_wl_pointer, serial C.uint32_t) {
 	c := w.cursor.system
 	if c == nil {
+		if img := w.cursor.image; img != nil {
+			buf := w.cursor.images[img]
+			sz := img.Image().Bounds().Size()
+			hot := img.Hotspot()
+			scale := w.cursor.scale
+			C.wl_pointer_set_cursor(pointer, serial, w.cursor.surf, C.int32_t(hot.X/scale), C.int32_t(hot.Y/scale))
+			C.wl_surface_attach(w.cursor.surf, buf, 0, 0)
+			C.wl_surface_damage(w.cursor.surf, 0, 0, C.int32_t(sz.X), C.int32_t(sz.Y))
+			C.wl_surface_commit(w.cursor.surf)
+			return
+		}
 		c = w.cursor.cursor
 	}
 	if c == nil {
@@ -1213,6 +1845,7 @@ func gio_onKeyboardEnter(data unsafe.Pointer, keyboard *C.struct_wl_keyboard, se
 	w := callbackLoad(unsafe.Pointer(surf)).(*window)
 	s.keyboardFocus = w
 	s.disp.repeat.Stop(0)
+	w.focused = true
 	w.w.Event(key.FocusEvent{Focus: true})
 }
 
@@ -1222,6 +1855,7 @@ func gio_onKeyboardLeave(data unsafe.Pointer, keyboard *C.struct_wl_keyboard, se
 	s.serial = serial
 	s.disp.repeat.Stop(0)
 	w := s.keyboardFocus
+	w.focused = false
 	w.w.Event(key.FocusEvent{Focus: false})
 }
 
@@ -1236,10 +1870,13 @@ func gio_onKeyboardKey(data unsafe.Pointer, keyboard *C.struct_wl_keyboard, seri
 	kc := mapXKBKeycode(uint32(keyCode))
 	ks := mapXKBKeyState(uint32(state))
 	for _, e := range w.disp.xkb.DispatchKey(kc, ks) {
-		if ee, ok := e.(key.EditEvent); ok {
+		switch e := e.(type) {
+		case key.EditEvent:
 			// There's no support for IME yet.
-			w.w.EditorInsert(ee.Text)
-		} else {
+			w.w.EditorInsert(e.Text)
+		case xkb.ComposeEvent:
+			w.w.EditorCompose(e.Text)
+		default:
 			w.w.Event(e)
 		}
 	}
@@ -1331,10 +1968,13 @@ func (r *repeatState) Repeat(d *wlDisplay) {
 			break
 		}
 		for _, e := range d.xkb.DispatchKey(r.key, key.Press) {
-			if ee, ok := e.(key.EditEvent); ok {
+			switch e := e.(type) {
+			case key.EditEvent:
 				// There's no support for IME yet.
-				r.win.EditorInsert(ee.Text)
-			} else {
+				r.win.EditorInsert(e.Text)
+			case xkb.ComposeEvent:
+				r.win.EditorCompose(e.Text)
+			default:
 				r.win.Event(e)
 			}
 		}
@@ -1385,6 +2025,32 @@ func (d *wlDisplay) bindDataDevice() {
 	}
 }
 
+// bindPrimaryDevice initializes the primaryDev field if and only if
+// both the seat and primaryManager fields are initialized.
+func (d *wlDisplay) bindPrimaryDevice() {
+	if d.seat != nil && d.primaryManager != nil {
+		d.seat.primaryDev = C.zwp_primary_selection_device_manager_v1_get_device(d.primaryManager, d.seat.seat)
+		if d.seat.primaryDev == nil {
+			return
+		}
+		callbackStore(unsafe.Pointer(d.seat.primaryDev), d.seat)
+		C.zwp_primary_selection_device_v1_add_listener(d.seat.primaryDev, &C.gio_primary_selection_device_listener, unsafe.Pointer(d.seat.primaryDev))
+	}
+}
+
+// bindTabletSeat initializes the tabletSeat field if and only if both
+// the seat and tabletManager fields are initialized.
+func (d *wlDisplay) bindTabletSeat() {
+	if d.seat != nil && d.tabletManager != nil {
+		d.seat.tabletSeat = C.zwp_tablet_manager_v2_get_tablet_seat(d.tabletManager, d.seat.seat)
+		if d.seat.tabletSeat == nil {
+			return
+		}
+		callbackStore(unsafe.Pointer(d.seat.tabletSeat), d.seat)
+		C.zwp_tablet_seat_v2_add_listener(d.seat.tabletSeat, &C.gio_tablet_seat_listener, unsafe.Pointer(d.seat.tabletSeat))
+	}
+}
+
 func (d *wlDisplay) dispatch(p *poller) error {
 	dispfd := C.wl_display_get_fd(d.disp)
 	// Poll for events and notifications.
@@ -1451,6 +2117,10 @@ func (w *window) destroy() {
 	if w.cursor.surf != nil {
 		C.wl_surface_destroy(w.cursor.surf)
 	}
+	for _, buf := range w.cursor.images {
+		C.wl_buffer_destroy(buf)
+	}
+	w.cursor.images = nil
 	if w.cursor.theme != nil {
 		C.wl_cursor_theme_destroy(w.cursor.theme)
 	}
@@ -1466,6 +2136,24 @@ func (w *window) destroy() {
 	if w.decor != nil {
 		C.zxdg_toplevel_decoration_v1_destroy(w.decor)
 	}
+	if w.token != nil {
+		C.xdg_activation_token_v1_destroy(w.token)
+	}
+	if w.fracScale != nil {
+		C.wp_fractional_scale_v1_destroy(w.fracScale)
+	}
+	if w.viewport != nil {
+		C.wp_viewport_destroy(w.viewport)
+	}
+	if w.exported != nil {
+		C.zxdg_exported_v2_destroy(w.exported)
+	}
+	if w.imported != nil {
+		C.zxdg_imported_v2_destroy(w.imported)
+	}
+	if w.inhibitor != nil {
+		C.zwp_idle_inhibitor_v1_destroy(w.inhibitor)
+	}
 	callbackDelete(unsafe.Pointer(w.surf))
 }
 
@@ -1540,6 +2228,26 @@ func gio_onDataSourceCancelled(data unsafe.Pointer, source *C.struct_wl_data_sou
 	C.wl_data_source_destroy(source)
 }
 
+//export gio_onPrimarySelectionSourceSend
+func gio_onPrimarySelectionSourceSend(data unsafe.Pointer, source *C.struct_zwp_primary_selection_source_v1, mime *C.char, fd C.int32_t) {
+	s := callbackLoad(data).(*wlSeat)
+	content := s.primaryContent
+	go func() {
+		defer syscall.Close(int(fd))
+		syscall.Write(int(fd), content)
+	}()
+}
+
+//export gio_onPrimarySelectionSourceCancelled
+func gio_onPrimarySelectionSourceCancelled(data unsafe.Pointer, source *C.struct_zwp_primary_selection_source_v1) {
+	s := callbackLoad(data).(*wlSeat)
+	if s.primarySource == source {
+		s.primaryContent = nil
+		s.primarySource = nil
+	}
+	C.zwp_primary_selection_source_v1_destroy(source)
+}
+
 //export gio_onDataSourceDNDDropPerformed
 func gio_onDataSourceDNDDropPerformed(data unsafe.Pointer, source *C.struct_wl_data_source) {
 }
@@ -1558,42 +2266,58 @@ func (w *window) flushScroll() {
 		dist := float32(w.fling.anim.Tick(time.Now()))
 		fling = w.fling.dir.Mul(dist)
 	}
-	// The Wayland reported scroll distance for
-	// discrete scroll axes is only 10 pixels, where
-	// 100 seems more appropriate.
-	const discreteScale = 10
-	if w.scroll.steps.X != 0 {
-		w.scroll.dist.X *= discreteScale
-	}
-	if w.scroll.steps.Y != 0 {
-		w.scroll.dist.Y *= discreteScale
+	// Wheels scroll wheelLines lines for each notch, regardless of
+	// the distance reported by Wayland. High resolution wheels
+	// report fractions of a notch, whose distances are scaled
+	// alike to scroll smoothly.
+	mode := pointer.ScrollPixels
+	wheel := w.scroll.steps != (image.Point{}) || w.scroll.v120 != (image.Point{})
+	if wheel {
+		mode = pointer.ScrollLines
+		w.scroll.dist = f32.Point{
+			X: wheelNotches(w.scroll.steps.X, w.scroll.v120.X) * wheelLines,
+			Y: wheelNotches(w.scroll.steps.Y, w.scroll.v120.Y) * wheelLines,
+		}
+		_, metric := w.getConfig()
+		w.scroll.dist = scrollPixels(metric, mode, w.scroll.dist, w.config.Size)
 	}
 	total := w.scroll.dist.Add(fling)
 	if total == (f32.Point{}) {
 		return
 	}
 	w.w.Event(pointer.Event{
-		Kind:      pointer.Scroll,
-		Source:    pointer.Mouse,
-		Buttons:   w.pointerBtns,
-		Position:  w.lastPos,
-		Scroll:    total,
-		Time:      w.scroll.time,
-		Modifiers: w.disp.xkb.Modifiers(),
+		Kind:       pointer.Scroll,
+		Source:     pointer.Mouse,
+		Buttons:    w.pointerBtns,
+		Position:   w.lastPos,
+		Scroll:     total,
+		ScrollMode: mode,
+		Time:       w.scroll.time,
+		Modifiers:  w.disp.xkb.Modifiers(),
 	})
-	if w.scroll.steps == (image.Point{}) {
+	if !wheel {
 		w.fling.xExtrapolation.SampleDelta(w.scroll.time, -w.scroll.dist.X)
 		w.fling.yExtrapolation.SampleDelta(w.scroll.time, -w.scroll.dist.Y)
 	}
 	w.scroll.dist = f32.Point{}
 	w.scroll.steps = image.Point{}
+	w.scroll.v120 = image.Point{}
+}
+
+// wheelNotches returns the number of notches a wheel turned, from
+// either the high resolution value or discrete steps.
+func wheelNotches(steps, v120 int) float32 {
+	if v120 != 0 {
+		return float32(v120) / 120
+	}
+	return float32(steps)
 }
 
 func (w *window) onPointerMotion(x, y C.wl_fixed_t, t C.uint32_t) {
 	w.flushScroll()
 	w.lastPos = f32.Point{
-		X: fromFixed(x) * float32(w.scale),
-		Y: fromFixed(y) * float32(w.scale),
+		X: fromFixed(x) * w.scaleFactor(),
+		Y: fromFixed(y) * w.scaleFactor(),
 	}
 	w.w.Event(pointer.Event{
 		Kind:      pointer.Move,
@@ -1656,6 +2380,7 @@ func (w *window) updateOpaqueRegion() {
 
 func (w *window) updateOutputs() {
 	scale := 1
+	refresh := 0
 	var found bool
 	for _, conf := range w.disp.outputConfig {
 		for _, w2 := range conf.windows {
@@ -1664,12 +2389,21 @@ func (w *window) updateOutputs() {
 				if conf.scale > scale {
 					scale = conf.scale
 				}
+				if conf.refresh > refresh {
+					refresh = conf.refresh
+				}
 			}
 		}
 	}
+	w.refresh = 0
+	if refresh > 0 {
+		w.refresh = time.Duration(int64(time.Second) * 1000 / int64(refresh))
+	}
 	if found && scale != w.scale {
 		w.scale = scale
-		C.wl_surface_set_buffer_scale(w.surf, C.int32_t(w.scale))
+		if w.preferredScale == 0 {
+			C.wl_surface_set_buffer_scale(w.surf, C.int32_t(w.scale))
+		}
 		w.redraw = true
 	}
 	if !found {
@@ -1680,11 +2414,42 @@ func (w *window) updateOutputs() {
 	}
 }
 
+// scaleFactor returns the ratio between buffer pixels and surface-local
+// coordinates.
+func (w *window) scaleFactor() float32 {
+	if w.preferredScale != 0 {
+		return w.preferredScale
+	}
+	return float32(w.scale)
+}
+
+// bufferSize converts a size in surface-local coordinates to buffer pixels.
+func (w *window) bufferSize(sz image.Point) image.Point {
+	s := float64(w.scaleFactor())
+	return image.Pt(int(math.Round(float64(sz.X)*s)), int(math.Round(float64(sz.Y)*s)))
+}
+
+// surfaceSize converts a size in buffer pixels to surface-local coordinates.
+func (w *window) surfaceSize(sz image.Point) image.Point {
+	s := float64(w.scaleFactor())
+	return image.Pt(int(math.Round(float64(sz.X)/s)), int(math.Round(float64(sz.Y)/s)))
+}
+
+// updateViewport scales the buffer to the surface size when
+// fractional scaling is in effect.
+func (w *window) updateViewport() {
+	if w.preferredScale == 0 || w.size == w.viewportSize {
+		return
+	}
+	w.viewportSize = w.size
+	C.wp_viewport_set_destination(w.viewport, C.int32_t(w.size.X), C.int32_t(w.size.Y))
+}
+
 func (w *window) getConfig() (image.Point, unit.Metric) {
-	size := w.size.Mul(w.scale)
+	size := w.bufferSize(w.size)
 	return size, unit.Metric{
-		PxPerDp: w.ppdp * float32(w.scale),
-		PxPerSp: w.ppsp * float32(w.scale),
+		PxPerDp: w.ppdp * w.scaleFactor(),
+		PxPerSp: w.ppsp * w.scaleFactor(),
 	}
 }
 
@@ -1698,6 +2463,7 @@ func (w *window) draw() {
 		w.config.Size = size
 		w.w.Event(ConfigEvent{Config: w.config})
 	}
+	w.updateViewport()
 	anim := w.animating || w.fling.anim.Active()
 	sync := w.redraw
 	w.redraw = false
@@ -1713,9 +2479,10 @@ func (w *window) draw() {
 	}
 	w.w.Event(frameEvent{
 		FrameEvent: system.FrameEvent{
-			Now:    time.Now(),
-			Size:   w.config.Size,
-			Metric: cfg,
+			Now:             time.Now(),
+			RefreshInterval: w.refresh,
+			Size:            w.config.Size,
+			Metric:          cfg,
 		},
 		Sync: sync,
 	})
@@ -1738,6 +2505,14 @@ func (w *window) surface() (*C.struct_wl_surface, int, int) {
 	return w.surf, sz.X, sz.Y
 }
 
+func (w *window) handles(e ViewEvent) native.Handles {
+	wl, ok := e.(WaylandViewEvent)
+	if !ok || wl.Surface == nil {
+		return native.Handles{}
+	}
+	return native.Handles{Platform: native.Wayland, Display: wl.Display, Surface: wl.Surface}
+}
+
 func (w *window) ShowTextInput(show bool) {}
 
 func (w *window) SetInputHint(_ key.InputHint) {}
@@ -1843,6 +2618,27 @@ func (d *wlDisplay) destroy() {
 	if d.decor != nil {
 		C.zxdg_decoration_manager_v1_destroy(d.decor)
 	}
+	if d.activation != nil {
+		C.xdg_activation_v1_destroy(d.activation)
+	}
+	if d.fractionalScale != nil {
+		C.wp_fractional_scale_manager_v1_destroy(d.fractionalScale)
+	}
+	if d.viewporter != nil {
+		C.wp_viewporter_destroy(d.viewporter)
+	}
+	if d.exporter != nil {
+		C.zxdg_exporter_v2_destroy(d.exporter)
+	}
+	if d.importer != nil {
+		C.zxdg_importer_v2_destroy(d.importer)
+	}
+	if d.idleInhibit != nil {
+		C.zwp_idle_inhibit_manager_v1_destroy(d.idleInhibit)
+	}
+	if d.primaryManager != nil {
+		C.zwp_primary_selection_device_manager_v1_destroy(d.primaryManager)
+	}
 	if d.shm != nil {
 		C.wl_shm_destroy(d.shm)
 	}
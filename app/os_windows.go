@@ -6,8 +6,11 @@ import (
 	"errors"
 	"fmt"
 	"image"
+	"image/color"
+	"math"
 	"runtime"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -17,6 +20,7 @@ import (
 
 	syscall "golang.org/x/sys/windows"
 
+	"github.com/Seikaijyu/gio/app/internal/native"
 	"github.com/Seikaijyu/gio/app/internal/windows"
 	"github.com/Seikaijyu/gio/unit"
 	gowindows "golang.org/x/sys/windows"
@@ -25,6 +29,7 @@ import (
 	"github.com/Seikaijyu/gio/io/clipboard"
 	"github.com/Seikaijyu/gio/io/key"
 	"github.com/Seikaijyu/gio/io/pointer"
+	"github.com/Seikaijyu/gio/io/router"
 	"github.com/Seikaijyu/gio/io/system"
 )
 
@@ -36,6 +41,9 @@ type ViewEvent struct {
 // window 结构体定义了一个窗口的各种属性
 type window struct {
 	hwnd        syscall.Handle  // 窗口的句柄
+	parent      syscall.Handle  // 嵌入窗口的父窗口句柄，见 NewWindowFrom
+	owner       syscall.Handle  // 对话框的所有者窗口句柄，见 Owner
+	modal       bool            // 标记所有者窗口是否被模态对话框禁用
 	hdc         syscall.Handle  // 设备上下文的句柄
 	w           *callbacks      // 回调函数的集合
 	stage       system.Stage    // 系统的阶段
@@ -44,19 +52,42 @@ type window struct {
 	// cursorIn 标记鼠标光标是否在窗口内，根据最近的 WM_SETCURSOR 消息来判断
 	cursorIn bool
 	cursor   syscall.Handle // 光标的句柄
+	// imageCursors 缓存图像光标对应的原生光标
+	imageCursors map[*pointer.ImageCursor]syscall.Handle
+	// nativeViews 记录放置过的原生子视图，见 SetNativeViews
+	nativeViews map[syscall.Handle]bool
 
 	// placement 在全屏模式下保存上一次窗口的位置
 	placement *windows.WindowPlacement
 
 	animating bool // 标记窗口是否在动画中
 	focused   bool // 标记窗口是否被聚焦
+	// deadChar 标记编辑器中是否显示着待定的死键字符
+	deadChar bool
+
+	// gesture 保存当前缩放和旋转手势的上一次参数，用于计算变化量
+	gesture struct {
+		distance float64
+		angle    float64
+	}
 
 	borderSize image.Point // 窗口边框的大小
 	config     Config      // 窗口的配置信息
+
+	// uia 在 UI 自动化客户端第一次请求时创建，向其提供语义树
+	uia *uiaProvider
+
+	// monitor 和 refresh 缓存窗口所在的显示器及其刷新间隔，见 refreshInterval
+	monitor syscall.Handle
+	refresh time.Duration
 }
 
-// _WM_WAKEUP 是一个自定义的 Windows 消息，用于唤醒窗口
-const _WM_WAKEUP = windows.WM_USER + iota
+const (
+	// _WM_WAKEUP 是一个自定义的 Windows 消息，用于唤醒窗口
+	_WM_WAKEUP = windows.WM_USER + iota
+	// _WM_UIA 是一个自定义的 Windows 消息，用于在窗口线程上执行 UI 自动化的调用
+	_WM_UIA
+)
 
 // gpuAPI 结构体定义了一个 GPU API，包含了优先级和初始化函数
 type gpuAPI struct {
@@ -97,7 +128,13 @@ func newWindow(window *callbacks, options []Option) error {
 		// 因此，我们锁定线程，让窗口消息通过未经过滤的 GetMessage 调用到达。
 		runtime.LockOSThread()
 		// 创建一个原生窗口
-		w, err := createNativeWindow()
+		parent := syscall.Handle(embedParent(options))
+		var owner syscall.Handle
+		h, modal := dialogOwner(options)
+		if parent == 0 {
+			owner, _ = h.(syscall.Handle)
+		}
+		w, err := createNativeWindow(parent, owner)
 		// 如果创建窗口时出错，将错误发送到错误通道并返回
 		if err != nil {
 			cerr <- err
@@ -113,14 +150,28 @@ func newWindow(window *callbacks, options []Option) error {
 		w.w = window
 		// 设置窗口的驱动程序
 		w.w.SetDriver(w)
+		// 记录窗口句柄，供窗口的对话框使用
+		w.w.SetOwnerHandle(w.hwnd)
 		// 发送一个 ViewEvent 事件
 		w.w.Event(ViewEvent{HWND: uintptr(w.hwnd)})
 		// 配置窗口
 		w.Configure(options)
-		// 将窗口设置为前台窗口
-		windows.SetForegroundWindow(w.hwnd)
-		// 设置窗口的焦点
-		windows.SetFocus(w.hwnd)
+		if owner != 0 {
+			// 对话框显示在所有者窗口的中央
+			w.centerOnOwner()
+			if modal {
+				// 模态对话框在关闭之前禁用所有者窗口
+				w.modal = true
+				windows.EnableWindow(owner, false)
+			}
+		}
+		// 嵌入的窗口和 NoActivate 窗口不抢占焦点
+		if parent == 0 && !w.config.NoActivate {
+			// 将窗口设置为前台窗口
+			windows.SetForegroundWindow(w.hwnd)
+			// 设置窗口的焦点
+			windows.SetFocus(w.hwnd)
+		}
 		// 由于光标的窗口类是空的，
 		// 所以在这里设置它以显示光标。
 		w.SetCursor(pointer.CursorDefault)
@@ -184,7 +235,7 @@ const dwExStyle = windows.WS_EX_APPWINDOW | windows.WS_EX_WINDOWEDGE
 var HWND syscall.Handle = 0
 
 // createNativeWindow 函数用于创建一个本地窗口
-func createNativeWindow() (*window, error) {
+func createNativeWindow(parent, owner syscall.Handle) (*window, error) {
 	var resErr error
 	// 使用 sync.Once 确保全局的 resources 只被初始化一次
 	resources.once.Do(func() {
@@ -195,17 +246,32 @@ func createNativeWindow() (*window, error) {
 		return nil, resErr
 	}
 	// 定义窗口的样式
-	const dwStyle = windows.WS_OVERLAPPEDWINDOW
+	var dwStyle uint32 = windows.WS_OVERLAPPEDWINDOW
+	exStyle := uint32(dwExStyle)
+	x, y := int32(windows.CW_USEDEFAULT), int32(windows.CW_USEDEFAULT)
+	width, height := int32(windows.CW_USEDEFAULT), int32(windows.CW_USEDEFAULT)
+	if owner != 0 {
+		// 被所有的窗口总是在所有者之上，并且没有自己的任务栏按钮
+		exStyle &^= windows.WS_EX_APPWINDOW
+		parent = owner
+	}
+	if parent != 0 && owner == 0 {
+		// 嵌入的窗口是填满父窗口客户区的子窗口
+		dwStyle = windows.WS_CHILD | windows.WS_VISIBLE
+		r := windows.GetClientRect(parent)
+		x, y = 0, 0
+		width, height = r.Right-r.Left, r.Bottom-r.Top
+	}
 
 	// 调用 CreateWindowEx 函数创建窗口
 	hwnd, err := windows.CreateWindowEx(
-		dwExStyle,       // 窗口的扩展样式
+		exStyle,         // 窗口的扩展样式
 		resources.class, // 窗口类
 		"",              // 窗口标题
 		dwStyle|windows.WS_CLIPSIBLINGS|windows.WS_CLIPCHILDREN, // 窗口样式
-		windows.CW_USEDEFAULT, windows.CW_USEDEFAULT, // 窗口的初始位置
-		windows.CW_USEDEFAULT, windows.CW_USEDEFAULT, // 窗口的初始大小
-		0,                // 父窗口的句柄
+		x, y, // 窗口的初始位置
+		width, height, // 窗口的初始大小
+		parent,           // 父窗口的句柄
 		0,                // 菜单的句柄
 		resources.handle, // 应用程序实例的句柄
 		0)                // 创建窗口的参数
@@ -217,6 +283,11 @@ func createNativeWindow() (*window, error) {
 	w := &window{
 		hwnd: hwnd,
 	}
+	if owner != 0 {
+		w.owner = owner
+	} else {
+		w.parent = parent
+	}
 
 	HWND = hwnd
 	// 获取窗口的设备上下文
@@ -225,6 +296,8 @@ func createNativeWindow() (*window, error) {
 	if err != nil {
 		return nil, err
 	}
+	// 启用旋转等默认未启用的手势
+	windows.SetGestureConfig(hwnd)
 	// 如果所有操作都成功，返回创建的窗口
 	return w, nil
 }
@@ -256,6 +329,13 @@ func (w *window) update() {
 		Y: int(cr.Bottom - cr.Top),
 	}
 
+	// 最小化的窗口位于屏幕之外，保留之前的位置
+	if !windows.GetWindowPlacement(w.hwnd).IsMinimized() {
+		r := windows.GetWindowRect(w.hwnd)
+		w.config.Position = image.Pt(int(r.Left), int(r.Top))
+		w.config.monitor = rectFromWin(windows.GetMonitorInfo(w.hwnd).Monitor)
+	}
+
 	// 获取窗口边框的大小
 	w.borderSize = image.Pt(
 		// 边框的宽度为系统的 SM_CXSIZEFRAME 参数
@@ -302,21 +382,38 @@ func windowProc(hwnd syscall.Handle, msg uint32, wParam, lParam uintptr) uintptr
 		}
 		fallthrough
 	case windows.WM_CHAR:
-		// 如果接收到的是 WM_CHAR 消息
+		// 如果接收到的是 WM_CHAR 消息，先移除待定的死键字符。死键组合失败时，
+		// Windows 会分别发送重音符号和字符的 WM_CHAR 消息
+		w.endDeadChar()
 		if r := rune(wParam); unicode.IsPrint(r) {
 			// 如果参数是可打印的字符，那么在编辑器中插入该字符
 			w.w.EditorInsert(string(r))
 		}
 		// 消息已经被处理
 		return windows.TRUE
+	case windows.WM_DEADCHAR:
+		// 如果接收到的是 WM_DEADCHAR 消息，在编辑器中显示待定的重音符号，
+		// 直到随后的 WM_CHAR 消息插入组合后的字符
+		if r := rune(wParam); unicode.IsPrint(r) {
+			w.w.EditorCompose(string(r))
+			w.deadChar = true
+		}
+		return windows.TRUE
 	case windows.WM_DPICHANGED:
 		// 如果接收到的是 WM_DPICHANGED 消息，告诉 Windows 我们已经准备好进行运行时 DPI 的改变
 		return windows.TRUE
+	case windows.WM_DISPLAYCHANGE:
+		// 如果接收到的是 WM_DISPLAYCHANGE 消息，显示模式可能改变了刷新率，清除缓存
+		w.monitor = 0
 	case windows.WM_ERASEBKGND:
 		// 如果接收到的是 WM_ERASEBKGND 消息，为了避免 GPU 内容和背景颜色之间的闪烁，返回 TRUE
 		return windows.TRUE
 	case windows.WM_KEYDOWN, windows.WM_KEYUP, windows.WM_SYSKEYDOWN, windows.WM_SYSKEYUP:
 		// 如果接收到的是键盘按下或释放的消息
+		if (msg == windows.WM_KEYDOWN || msg == windows.WM_SYSKEYDOWN) && !isModifierKey(wParam) {
+			// 在处理按键之前移除待定的死键字符，以免按键（如退格键）作用于它
+			w.endDeadChar()
+		}
 		if n, ok := convertKeyCode(wParam); ok {
 			// 如果参数是有效的键码，那么创建一个键盘事件
 			e := key.Event{
@@ -401,13 +498,31 @@ func windowProc(hwnd syscall.Handle, msg uint32, wParam, lParam uintptr) uintptr
 			Time:      windows.GetMessageTime(),
 			Modifiers: getModifiers(),
 		})
+	case windows.WM_POINTERDOWN, windows.WM_POINTERUPDATE, windows.WM_POINTERUP, windows.WM_POINTERLEAVE:
+		// 如果接收到的是触控笔的指针消息，发出带有压力和倾斜信息的事件。
+		// 触控笔在感应范围内悬停时发出移动事件，离开感应范围时发出离开事件。
+		// 其他类型的指针交由系统转换为鼠标消息。
+		if w.penEvent(msg, uint32(wParam&0xffff)) {
+			return 0
+		}
+	case windows.WM_GESTURE:
+		// 如果接收到的是缩放或旋转手势消息，发出对应的手势事件
+		if w.gestureEvent(lParam) {
+			windows.CloseGestureInfoHandle(lParam)
+			return 0
+		}
 	case windows.WM_MOUSEWHEEL:
 		// 如果接收到的是 WM_MOUSEWHEEL 消息，处理鼠标滚轮事件
 		w.scrollEvent(wParam, lParam, false, getModifiers())
 	case windows.WM_MOUSEHWHEEL:
 		// 如果接收到的是 WM_MOUSEHWHEEL 消息，处理鼠标水平滚轮事件
 		w.scrollEvent(wParam, lParam, true, getModifiers())
+	case windows.WM_CLOSE:
+		// 在销毁模态对话框之前重新启用所有者窗口，否则所有者会失去激活
+		w.enableOwner()
 	case windows.WM_DESTROY:
+		w.enableOwner()
+		w.w.SetOwnerHandle(nil)
 		// 如果接收到的是 WM_DESTROY 消息，发出一个视图事件和一个销毁事件
 		w.w.Event(ViewEvent{})
 		w.w.Event(system.DestroyEvent{})
@@ -416,8 +531,19 @@ func windowProc(hwnd syscall.Handle, msg uint32, wParam, lParam uintptr) uintptr
 			windows.ReleaseDC(w.hdc)
 			w.hdc = 0
 		}
+		// 断开 UI 自动化提供程序与窗口的连接
+		if w.uia != nil {
+			w.uia.close()
+		}
 		// 系统会为我们销毁窗口句柄
 		w.hwnd = 0
+		// 销毁缓存的图像光标
+		for _, c := range w.imageCursors {
+			windows.DestroyCursor(c)
+		}
+		w.imageCursors = nil
+		// 允许系统再次关闭显示器
+		windows.SetThreadExecutionState(windows.ES_CONTINUOUS)
 		// 发送一个退出消息
 		windows.PostQuitMessage(0)
 	case windows.WM_NCCALCSIZE:
@@ -446,6 +572,9 @@ func windowProc(hwnd syscall.Handle, msg uint32, wParam, lParam uintptr) uintptr
 	case windows.WM_PAINT:
 		// 如果接收到的是 WM_PAINT 消息，执行绘制操作
 		w.draw(true)
+	case windows.WM_MOVE:
+		// 如果接收到的是 WM_MOVE 消息，更新窗口位置
+		w.update()
 	case windows.WM_SIZE:
 		// 如果接收到的是 WM_SIZE 消息，更新窗口大小
 		w.update()
@@ -502,6 +631,20 @@ func windowProc(hwnd syscall.Handle, msg uint32, wParam, lParam uintptr) uintptr
 	case _WM_WAKEUP:
 		// 如果接收到的是 _WM_WAKEUP 消息，触发唤醒事件
 		w.w.Event(wakeupEvent{})
+	case _WM_UIA:
+		// 执行 UI 自动化线程转发到窗口线程的调用
+		if w.uia != nil {
+			w.uia.runCall()
+		}
+		return 0
+	case windows.WM_GETOBJECT:
+		// UI 自动化客户端请求窗口的提供程序
+		if int32(lParam) == windows.UiaRootObjectId {
+			if w.uia == nil {
+				w.uia = newUIAProvider(w)
+			}
+			return w.uia.getObject(wParam, lParam)
+		}
 	case windows.WM_IME_STARTCOMPOSITION:
 		// 如果接收到的是 WM_IME_STARTCOMPOSITION 消息，开始输入法编辑
 		imc := windows.ImmGetContext(w.hwnd)
@@ -510,14 +653,8 @@ func windowProc(hwnd syscall.Handle, msg uint32, wParam, lParam uintptr) uintptr
 			return windows.TRUE
 		}
 		defer windows.ImmReleaseContext(w.hwnd, imc)
-		// 获取编辑器的选择状态
-		sel := w.w.EditorState().Selection
-		// 转换选择的光标位置
-		caret := sel.Transform.Transform(sel.Caret.Pos.Add(f32.Pt(0, sel.Caret.Descent)))
-		icaret := image.Pt(int(caret.X+.5), int(caret.Y+.5))
-		// 设置输入法的组合窗口和候选窗口位置
-		windows.ImmSetCompositionWindow(imc, icaret.X, icaret.Y)
-		windows.ImmSetCandidateWindow(imc, icaret.X, icaret.Y)
+		// 将组合窗口和候选窗口放置在编辑器的光标处
+		w.placeIMEWindows(imc, w.w.EditorState())
 	case windows.WM_IME_COMPOSITION:
 		// 如果接收到的是 WM_IME_COMPOSITION 消息，进行输入法编辑
 		imc := windows.ImmGetContext(w.hwnd)
@@ -576,6 +713,23 @@ func windowProc(hwnd syscall.Handle, msg uint32, wParam, lParam uintptr) uintptr
 	return windows.DefWindowProc(hwnd, msg, wParam, lParam)
 }
 
+// endDeadChar 移除编辑器中待定的死键字符（如果有）
+func (w *window) endDeadChar() {
+	if w.deadChar {
+		w.deadChar = false
+		w.w.EditorCompose("")
+	}
+}
+
+// isModifierKey 报告虚拟键码 code 是否为修饰键
+func isModifierKey(code uintptr) bool {
+	switch code {
+	case windows.VK_SHIFT, windows.VK_CONTROL, windows.VK_MENU, windows.VK_LWIN, windows.VK_RWIN:
+		return true
+	}
+	return false
+}
+
 // getModifiers 函数用于获取当前按下的修饰键（如Ctrl、Alt等）的状态
 func getModifiers() key.Modifiers {
 	var kmods key.Modifiers
@@ -683,6 +837,105 @@ func (w *window) pointerButton(btn pointer.Buttons, press bool, lParam uintptr,
 	})
 }
 
+// penEvent 函数处理触控笔的指针消息，如果指针不是触控笔则返回 false
+func (w *window) penEvent(msg uint32, id uint32) bool {
+	if typ, err := windows.GetPointerType(id); err != nil || typ != windows.PT_PEN {
+		return false
+	}
+	info, err := windows.GetPointerPenInfo(id)
+	if err != nil {
+		return false
+	}
+	// 如果窗口没有焦点，设置焦点到该窗口
+	if !w.focused && msg == windows.WM_POINTERDOWN {
+		windows.SetFocus(w.hwnd)
+	}
+	src := pointer.Stylus
+	if info.PenFlags&(windows.PEN_FLAG_INVERTED|windows.PEN_FLAG_ERASER) != 0 {
+		src = pointer.Eraser
+	}
+	var btns pointer.Buttons
+	if info.PointerFlags&windows.POINTER_FLAG_INCONTACT != 0 {
+		btns |= pointer.ButtonPrimary
+	}
+	if info.PenFlags&windows.PEN_FLAG_BARREL != 0 {
+		btns |= pointer.ButtonSecondary
+	}
+	var kind pointer.Kind
+	switch msg {
+	case windows.WM_POINTERDOWN:
+		kind = pointer.Press
+	case windows.WM_POINTERUP:
+		kind = pointer.Release
+		btns &^= pointer.ButtonPrimary
+	case windows.WM_POINTERLEAVE:
+		// 触控笔离开了窗口或感应范围，结束悬停
+		kind = pointer.Leave
+		btns = 0
+	default:
+		kind = pointer.Move
+	}
+	// 将屏幕坐标转换为客户区坐标
+	np := info.PtPixelLocation
+	windows.ScreenToClient(w.hwnd, &np)
+	w.w.Event(pointer.Event{
+		Kind:      kind,
+		Source:    src,
+		PointerID: pointer.ID(id),
+		Position:  f32.Point{X: float32(np.X), Y: float32(np.Y)},
+		Buttons:   btns,
+		Pressure:  float32(info.Pressure) / 1024,
+		TiltX:     float32(info.TiltX),
+		TiltY:     float32(info.TiltY),
+		Time:      windows.GetMessageTime(),
+		Modifiers: getModifiers(),
+	})
+	return true
+}
+
+// gestureEvent 函数处理缩放和旋转手势，如果手势未被处理则返回 false
+func (w *window) gestureEvent(lParam uintptr) bool {
+	info, err := windows.GetGestureInfo(lParam)
+	if err != nil {
+		return false
+	}
+	np := windows.Point{X: int32(info.Location[0]), Y: int32(info.Location[1])}
+	windows.ScreenToClient(w.hwnd, &np)
+	e := pointer.Event{
+		Source:    pointer.Touch,
+		Position:  f32.Point{X: float32(np.X), Y: float32(np.Y)},
+		Time:      windows.GetMessageTime(),
+		Modifiers: getModifiers(),
+	}
+	begin := info.Flags&windows.GF_BEGIN != 0
+	switch info.ID {
+	case windows.GID_ZOOM:
+		// 参数是两个触点之间的距离
+		dist := float64(info.Arguments & 0xffffffff)
+		prev := w.gesture.distance
+		w.gesture.distance = dist
+		if begin || prev == 0 {
+			return true
+		}
+		e.Kind = pointer.Pinch
+		e.Zoom = float32(dist/prev - 1)
+	case windows.GID_ROTATE:
+		// 参数是自手势开始以来逆时针旋转的角度
+		angle := float64(info.Arguments&0xffff)/65535*4*math.Pi - 2*math.Pi
+		prev := w.gesture.angle
+		w.gesture.angle = angle
+		if begin {
+			return true
+		}
+		e.Kind = pointer.Rotate
+		e.Rotation = float32(prev - angle)
+	default:
+		return false
+	}
+	w.w.Event(e)
+	return true
+}
+
 // coordsFromlParam 函数从 lParam 中解析出鼠标的坐标
 func coordsFromlParam(lParam uintptr) (int, int) {
 	x := int(int16(lParam & 0xffff))
@@ -698,8 +951,24 @@ func (w *window) scrollEvent(wParam, lParam uintptr, horizontal bool, kmods key.
 	np := windows.Point{X: int32(x), Y: int32(y)}
 	windows.ScreenToClient(w.hwnd, &np)
 	p := f32.Point{X: float32(np.X), Y: float32(np.Y)}
-	// 获取滚动的距离
-	dist := float32(int16(wParam >> 16))
+	// 获取滚动的格数，单位为 1/120 格（WHEEL_DELTA）。
+	// 高精度滚轮每次只报告一格的一部分，按比例换算，
+	// 因此一整格的距离不变，而部分滚动也能平滑地滚动。
+	ticks := float32(int16(wParam>>16)) / windows.WHEEL_DELTA
+	// 按系统设置将格数换算为行数或页数
+	mode := pointer.ScrollLines
+	var dist float32
+	if horizontal {
+		dist = ticks * float32(windows.SystemParametersInfoUint(windows.SPI_GETWHEELSCROLLCHARS, wheelLines))
+	} else {
+		lines := windows.SystemParametersInfoUint(windows.SPI_GETWHEELSCROLLLINES, wheelLines)
+		if lines == windows.WHEEL_PAGESCROLL {
+			mode = pointer.ScrollPages
+			dist = ticks
+		} else {
+			dist = ticks * float32(lines)
+		}
+	}
 	var sp f32.Point
 	// 如果是水平滚动
 	if horizontal {
@@ -713,15 +982,19 @@ func (w *window) scrollEvent(wParam, lParam uintptr, horizontal bool, kmods key.
 			sp.Y = -dist
 		}
 	}
+	// 按窗口的 DPI 将行数或页数换算为像素
+	metric := configForDPI(windows.GetWindowDPI(w.hwnd))
+	sp = scrollPixels(metric, mode, sp, w.config.Size)
 	// 发送鼠标滚轮事件
 	w.w.Event(pointer.Event{
-		Kind:      pointer.Scroll,
-		Source:    pointer.Mouse,
-		Position:  p,
-		Buttons:   w.pointerBtns,
-		Scroll:    sp,
-		Modifiers: kmods,
-		Time:      windows.GetMessageTime(),
+		Kind:       pointer.Scroll,
+		Source:     pointer.Mouse,
+		Position:   p,
+		Buttons:    w.pointerBtns,
+		Scroll:     sp,
+		ScrollMode: mode,
+		Modifiers:  kmods,
+		Time:       windows.GetMessageTime(),
 	})
 }
 
@@ -767,7 +1040,23 @@ func (w *window) EditorStateChanged(old, new editorState) {
 	if old.Selection.Range != new.Selection.Range || old.Snippet != new.Snippet {
 		// 取消当前的输入法编辑
 		windows.ImmNotifyIME(imc, windows.NI_COMPOSITIONSTR, windows.CPS_CANCEL, 0)
+		return
 	}
+	// 组合期间编辑器绘制预编辑文本后光标会移动，候选窗口需要跟随光标
+	if new.compose.Start != -1 && old.Selection != new.Selection {
+		w.placeIMEWindows(imc, new)
+	}
+}
+
+// placeIMEWindows 将输入法的组合窗口和候选窗口放置在编辑器光标的下方
+func (w *window) placeIMEWindows(imc syscall.Handle, state editorState) {
+	sel := state.Selection
+	// 转换选择的光标位置
+	caret := sel.Transform.Transform(sel.Caret.Pos.Add(f32.Pt(0, sel.Caret.Descent)))
+	icaret := image.Pt(int(caret.X+.5), int(caret.Y+.5))
+	// 设置输入法的组合窗口和候选窗口位置
+	windows.ImmSetCompositionWindow(imc, icaret.X, icaret.Y)
+	windows.ImmSetCandidateWindow(imc, icaret.X, icaret.Y)
 }
 
 // SetAnimating 方法用于设置窗口是否处于动画状态
@@ -775,6 +1064,14 @@ func (w *window) SetAnimating(anim bool) {
 	w.animating = anim
 }
 
+// reportsPosition 方法实现 positionDriver 接口
+func (w *window) reportsPosition() {}
+
+// renderThreaded 方法实现 threadedDriver 接口
+// Direct3D 11 和 EGL 上下文在加锁后可以在任意线程上绘制和呈现，
+// 因此窗口的帧在渲染线程上绘制，不会阻塞消息循环
+func (w *window) renderThreaded() {}
+
 // Wakeup 方法用于唤醒窗口
 // 它会向窗口发送一个 _WM_WAKEUP 消息
 func (w *window) Wakeup() {
@@ -803,12 +1100,31 @@ func (w *window) draw(sync bool) {
 	cfg := configForDPI(dpi)
 	w.w.Event(frameEvent{
 		FrameEvent: system.FrameEvent{
-			Now:    time.Now(),
-			Size:   w.config.Size,
-			Metric: cfg,
+			Now:             time.Now(),
+			RefreshInterval: w.refreshInterval(),
+			Size:            w.config.Size,
+			Metric:          cfg,
 		},
 		Sync: sync,
 	})
+	// 向 UI 自动化客户端报告语义树的变化
+	if w.uia != nil {
+		w.uia.update()
+	}
+}
+
+// refreshInterval 返回窗口所在显示器的刷新间隔，未知时返回 0
+// 查询显示模式的开销较大，因此结果按显示器缓存，直到显示设置改变
+func (w *window) refreshInterval() time.Duration {
+	hmon := windows.MonitorFromWindow(w.hwnd, windows.MONITOR_DEFAULTTONEAREST)
+	if hmon != w.monitor {
+		w.monitor = hmon
+		w.refresh = 0
+		if hz := windows.GetMonitorRefreshRate(hmon); hz > 0 {
+			w.refresh = time.Second / time.Duration(hz)
+		}
+	}
+	return w.refresh
 }
 
 // NewContext 方法用于创建一个新的上下文
@@ -879,10 +1195,27 @@ func (w *window) Configure(options []Option) {
 	dpi := windows.GetSystemDPI()
 	// 根据 DPI 创建一个配置
 	metric := configForDPI(dpi)
-	// 应用配置
+	// 应用配置，并记录选项是否改变了窗口的位置
+	pos := w.config.Position
 	w.config.apply(metric, options)
+	moved := w.config.Position != pos
+	if w.parent != 0 {
+		// 嵌入的窗口由宿主程序负责大小、模式和装饰
+		w.config.Mode = Windowed
+		w.config.Decorated = true
+		w.update()
+		return
+	}
 	// 设置窗口的标题
 	windows.SetWindowText(w.hwnd, w.config.Title)
+	// 设置标题栏的颜色和深色模式
+	w.setTitleBarStyle()
+	// 设置窗口内容能否被截屏
+	w.setContentProtection()
+	// 设置显示器是否保持常亮和是否禁用边缘手势
+	w.setKioskState()
+	// 设置窗口在显示和点击时是否激活
+	w.setNoActivate()
 
 	// 获取窗口的样式
 	style := windows.GetWindowLong(w.hwnd, windows.GWL_STYLE)
@@ -891,6 +1224,10 @@ func (w *window) Configure(options []Option) {
 	swpStyle := uintptr(windows.SWP_NOZORDER | windows.SWP_FRAMECHANGED)
 	winStyle := uintptr(windows.WS_OVERLAPPEDWINDOW)
 	style &^= winStyle
+	if moved && w.config.Mode != Windowed && !windows.GetWindowPlacement(w.hwnd).IsMaximized() {
+		// 先移动窗口，使其在目标位置所在的显示器上最大化或者全屏
+		w.moveTo(w.config.Position)
+	}
 	// 根据窗口的模式来设置窗口的样式和显示模式
 	switch w.config.Mode {
 	case Minimized:
@@ -923,6 +1260,10 @@ func (w *window) Configure(options []Option) {
 			width = r.Right - r.Left
 			height = r.Bottom - r.Top
 		}
+		if moved {
+			p := w.fitPosition(image.Rect(0, 0, int(width), int(height)).Add(w.config.Position))
+			x, y = int32(p.X), int32(p.Y)
+		}
 		if !w.config.Decorated {
 			// 当我们绘制装饰时，启用阴影效果
 			windows.DwmExtendFrameIntoClientArea(w.hwnd, windows.Margins{-1, -1, -1, -1})
@@ -936,6 +1277,16 @@ func (w *window) Configure(options []Option) {
 		height = mi.Monitor.Bottom - mi.Monitor.Top
 		showMode = windows.SW_SHOWMAXIMIZED
 	}
+	if w.config.NoActivate {
+		// 显示窗口但不激活它
+		swpStyle |= windows.SWP_NOACTIVATE
+		switch showMode {
+		case windows.SW_SHOWNORMAL:
+			showMode = windows.SW_SHOWNOACTIVATE
+		case windows.SW_SHOWMINIMIZED:
+			showMode = windows.SW_SHOWMINNOACTIVE
+		}
+	}
 	// 设置窗口的样式
 	windows.SetWindowLong(w.hwnd, windows.GWL_STYLE, style)
 	// 设置窗口的位置和大小
@@ -947,11 +1298,107 @@ func (w *window) Configure(options []Option) {
 	w.update()
 }
 
+// moveTo 方法将窗口移动到 p，并保证窗口可见
+func (w *window) moveTo(p image.Point) {
+	r := rectFromWin(windows.GetWindowRect(w.hwnd))
+	p = w.fitPosition(r.Add(p.Sub(r.Min)))
+	windows.SetWindowPos(w.hwnd, 0, int32(p.X), int32(p.Y), 0, 0, windows.SWP_NOSIZE|windows.SWP_NOZORDER|windows.SWP_NOACTIVATE)
+}
+
+// fitPosition 方法返回窗口矩形 r 在显示器上可见的位置。
+// 恢复的位置所在的显示器消失或者改变时，窗口位于最近的显示器的中央
+func (w *window) fitPosition(r image.Rectangle) image.Point {
+	c := r.Min.Add(r.Size().Div(2))
+	mi, found := windows.GetMonitorInfoFromPoint(windows.Point{X: int32(c.X), Y: int32(c.Y)})
+	saved := w.config.positionMonitor
+	w.config.positionMonitor = image.Rectangle{}
+	return fitToMonitor(r, saved, rectFromWin(mi.Monitor), rectFromWin(mi.WorkArea), found)
+}
+
+// rectFromWin 函数将 windows.Rect 转换为 image.Rectangle
+func rectFromWin(r windows.Rect) image.Rectangle {
+	return image.Rect(int(r.Left), int(r.Top), int(r.Right), int(r.Bottom))
+}
+
+// centerOnOwner 方法将对话框移动到所有者窗口的中央
+func (w *window) centerOnOwner() {
+	if w.config.Mode != Windowed {
+		return
+	}
+	or := windows.GetWindowRect(w.owner)
+	r := windows.GetWindowRect(w.hwnd)
+	width, height := r.Right-r.Left, r.Bottom-r.Top
+	x := or.Left + (or.Right-or.Left-width)/2
+	y := or.Top + (or.Bottom-or.Top-height)/2
+	windows.SetWindowPos(w.hwnd, 0, x, y, 0, 0, windows.SWP_NOSIZE|windows.SWP_NOZORDER|windows.SWP_NOACTIVATE)
+}
+
+// enableOwner 方法重新启用被模态对话框禁用的所有者窗口
+func (w *window) enableOwner() {
+	if w.modal {
+		w.modal = false
+		windows.EnableWindow(w.owner, true)
+	}
+}
+
+// setTitleBarStyle 方法根据配置设置标题栏的颜色和深色模式。
+// 旧版本的 Windows 不支持这些属性，因此忽略错误
+func (w *window) setTitleBarStyle() {
+	dark := uint32(0)
+	if w.config.DarkDecorations {
+		dark = 1
+	}
+	windows.DwmSetWindowAttribute(w.hwnd, windows.DWMWA_USE_IMMERSIVE_DARK_MODE, dark)
+	windows.DwmSetWindowAttribute(w.hwnd, windows.DWMWA_CAPTION_COLOR, colorRef(w.config.TitleBarColor))
+	windows.DwmSetWindowAttribute(w.hwnd, windows.DWMWA_TEXT_COLOR, colorRef(w.config.TitleColor))
+}
+
+// setContentProtection 方法根据配置设置窗口内容能否被截屏和录屏。
+// Windows 10 2004 之前不支持 WDA_EXCLUDEFROMCAPTURE，这时退回到 WDA_MONITOR，
+// 窗口在截屏中显示为黑色
+func (w *window) setContentProtection() {
+	if !w.config.ContentProtected {
+		windows.SetWindowDisplayAffinity(w.hwnd, windows.WDA_NONE)
+		return
+	}
+	if err := windows.SetWindowDisplayAffinity(w.hwnd, windows.WDA_EXCLUDEFROMCAPTURE); err != nil {
+		windows.SetWindowDisplayAffinity(w.hwnd, windows.WDA_MONITOR)
+	}
+}
+
+// setKioskState 方法根据配置设置显示器是否保持常亮，以及全屏时是否禁用触摸屏的边缘手势。
+// 执行状态属于窗口的线程，因此每个窗口各自设置
+func (w *window) setKioskState() {
+	state := uint32(windows.ES_CONTINUOUS)
+	if w.config.KeepScreenOn {
+		state |= windows.ES_DISPLAY_REQUIRED | windows.ES_SYSTEM_REQUIRED
+	}
+	windows.SetThreadExecutionState(state)
+	// 不支持边缘手势的旧版本 Windows 会返回错误，忽略即可
+	windows.SetEdgeGesturesDisabled(w.hwnd, w.config.SystemGesturesDisabled)
+}
+
+// colorRef 函数将颜色转换为 COLORREF，零值转换为 DWMWA_COLOR_DEFAULT
+func colorRef(c color.NRGBA) uint32 {
+	if c == (color.NRGBA{}) {
+		return windows.DWMWA_COLOR_DEFAULT
+	}
+	return uint32(c.B)<<16 | uint32(c.G)<<8 | uint32(c.R)
+}
+
 // WriteClipboard 方法将指定的字符串写入剪贴板
 func (w *window) WriteClipboard(s string) {
 	w.writeClipboard(s)
 }
 
+// ReadPrimary 方法发送一个空的主选区事件，因为 Windows 没有主选区
+func (w *window) ReadPrimary() {
+	w.w.Event(clipboard.Event{Primary: true})
+}
+
+// WritePrimary 方法被忽略，因为 Windows 没有主选区
+func (w *window) WritePrimary(s string) {}
+
 // writeClipboard 方法将指定的字符串写入剪贴板，如果出现错误则返回错误
 func (w *window) writeClipboard(s string) error {
 	// 打开剪贴板
@@ -995,6 +1442,164 @@ func (w *window) writeClipboard(s string) error {
 	return nil
 }
 
+// ReadClipboardContent 方法读取剪贴板的文本，以及 types 中剪贴板可以提供的格式
+func (w *window) ReadClipboardContent(types []string) {
+	w.readClipboardContent(types)
+}
+
+// readClipboardContent 方法读取剪贴板的文本和其他格式，然后发送一个剪贴板事件
+func (w *window) readClipboardContent(types []string) error {
+	if err := windows.OpenClipboard(w.hwnd); err != nil {
+		return err
+	}
+	defer windows.CloseClipboard()
+	var e clipboard.Event
+	if mem, err := windows.GetClipboardData(windows.CF_UNICODETEXT); err == nil {
+		if ptr, err := windows.GlobalLock(mem); err == nil {
+			e.Text = gowindows.UTF16PtrToString((*uint16)(ptr))
+			windows.GlobalUnlock(mem)
+		}
+	}
+	for _, t := range types {
+		format, ok := clipboardFormat(t)
+		if !ok {
+			continue
+		}
+		data, err := readClipboardBytes(format)
+		if err != nil {
+			continue
+		}
+		if t == "text/html" {
+			if data, ok = decodeCFHTML(data); !ok {
+				continue
+			}
+		}
+		e.Content = append(e.Content, clipboard.Content{Type: t, Data: data})
+	}
+	w.w.Event(e)
+	return nil
+}
+
+// WriteClipboardContent 方法将文本和它的其他格式写入剪贴板
+func (w *window) WriteClipboardContent(s string, content []clipboard.Content) {
+	if err := w.writeClipboard(s); err != nil {
+		return
+	}
+	// writeClipboard 清空了剪贴板，窗口仍是剪贴板的所有者，可以继续添加格式
+	if err := windows.OpenClipboard(w.hwnd); err != nil {
+		return
+	}
+	defer windows.CloseClipboard()
+	for _, c := range content {
+		format, ok := clipboardFormat(c.Type)
+		if !ok {
+			continue
+		}
+		data := c.Data
+		if c.Type == "text/html" {
+			data = encodeCFHTML(data)
+		}
+		writeClipboardBytes(format, data)
+	}
+}
+
+// clipboardFormat 返回 MIME 类型对应的剪贴板格式
+func clipboardFormat(mime string) (uint32, bool) {
+	var name string
+	switch mime {
+	case "text/html":
+		name = "HTML Format"
+	case "text/rtf":
+		name = "Rich Text Format"
+	default:
+		return 0, false
+	}
+	format, err := windows.RegisterClipboardFormat(name)
+	return format, err == nil
+}
+
+// readClipboardBytes 读取剪贴板中以 NUL 结尾的字节格式的数据。调用前需要打开剪贴板
+func readClipboardBytes(format uint32) (string, error) {
+	mem, err := windows.GetClipboardData(format)
+	if err != nil {
+		return "", err
+	}
+	ptr, err := windows.GlobalLock(mem)
+	if err != nil {
+		return "", err
+	}
+	defer windows.GlobalUnlock(mem)
+	data := unsafe.Slice((*byte)(ptr), windows.GlobalSize(mem))
+	if i := strings.IndexByte(string(data), 0); i != -1 {
+		data = data[:i]
+	}
+	return string(data), nil
+}
+
+// writeClipboardBytes 以 NUL 结尾的字节格式写入剪贴板数据。调用前需要打开剪贴板
+func writeClipboardBytes(format uint32, data string) error {
+	mem, err := windows.GlobalAlloc(len(data) + 1)
+	if err != nil {
+		return err
+	}
+	ptr, err := windows.GlobalLock(mem)
+	if err != nil {
+		windows.GlobalFree(mem)
+		return err
+	}
+	copy(unsafe.Slice((*byte)(ptr), len(data)), data)
+	windows.GlobalUnlock(mem)
+	if err := windows.SetClipboardData(format, mem); err != nil {
+		windows.GlobalFree(mem)
+		return err
+	}
+	return nil
+}
+
+// cfHTMLHeader 是 CF_HTML 格式的头部，其中的偏移量是 UTF-8 数据中的字节位置
+const cfHTMLHeader = "Version:0.9\r\nStartHTML:%010d\r\nEndHTML:%010d\r\nStartFragment:%010d\r\nEndFragment:%010d\r\n"
+
+// encodeCFHTML 将 HTML 片段编码为 CF_HTML 格式
+func encodeCFHTML(fragment string) string {
+	const (
+		prefix = "<html><body>\r\n<!--StartFragment-->"
+		suffix = "<!--EndFragment-->\r\n</body></html>"
+	)
+	startHTML := len(fmt.Sprintf(cfHTMLHeader, 0, 0, 0, 0))
+	startFrag := startHTML + len(prefix)
+	endFrag := startFrag + len(fragment)
+	endHTML := endFrag + len(suffix)
+	return fmt.Sprintf(cfHTMLHeader, startHTML, endHTML, startFrag, endFrag) + prefix + fragment + suffix
+}
+
+// decodeCFHTML 返回 CF_HTML 格式数据中的 HTML 片段，没有片段时返回整个 HTML
+func decodeCFHTML(data string) (string, bool) {
+	offsets := make(map[string]int)
+	for rest := data; rest != "" && rest[0] != '<'; {
+		line := rest
+		if i := strings.IndexByte(rest, '\n'); i != -1 {
+			line, rest = rest[:i], rest[i+1:]
+		} else {
+			rest = ""
+		}
+		name, val, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if !ok {
+			continue
+		}
+		if n, err := strconv.Atoi(val); err == nil {
+			offsets[name] = n
+		}
+	}
+	for _, r := range [][2]string{{"StartFragment", "EndFragment"}, {"StartHTML", "EndHTML"}} {
+		start, ok1 := offsets[r[0]]
+		end, ok2 := offsets[r[1]]
+		if ok1 && ok2 && 0 <= start && start <= end && end <= len(data) {
+			return data[start:end], true
+		}
+	}
+	return "", false
+}
+
 // SetCursor 方法设置窗口的光标
 func (w *window) SetCursor(cursor pointer.Cursor) {
 	// 加载光标
@@ -1010,6 +1615,112 @@ func (w *window) SetCursor(cursor pointer.Cursor) {
 	}
 }
 
+// gestureSettings 函数返回用户设置的双击间隔，以及鼠标的拖动阈值。
+// SM_CXDRAG 只适用于鼠标，不适用于触摸。拖动阈值以系统 DPI 下的像素为单位，这里换算为 Dp。
+func gestureSettings() system.Gestures {
+	dpi := windows.GetSystemDPI()
+	px := windows.GetSystemMetrics(windows.SM_CXDRAG)
+	return system.Gestures{
+		DoubleClick:   windows.GetDoubleClickTime(),
+		DragThreshold: unit.Dp(float32(px) * 96 / float32(dpi)),
+	}
+}
+
+// CursorCapabilities 方法报告 Windows 支持查询和移动光标
+func (w *window) CursorCapabilities() CursorCapability {
+	return CursorQuery | CursorWarp
+}
+
+// CursorPosition 方法返回光标在屏幕上的位置，单位为像素，与 Config.Position 一致
+func (w *window) CursorPosition() (image.Point, error) {
+	p, err := windows.GetCursorPos()
+	if err != nil {
+		return image.Point{}, err
+	}
+	return image.Pt(int(p.X), int(p.Y)), nil
+}
+
+// WarpCursor 方法将光标移动到屏幕上的位置 p
+func (w *window) WarpCursor(p image.Point) error {
+	return windows.SetCursorPos(int32(p.X), int32(p.Y))
+}
+
+// SetImageCursor 方法将窗口的光标设置为图像光标
+func (w *window) SetImageCursor(cursor *pointer.ImageCursor) {
+	c, ok := w.imageCursors[cursor]
+	if !ok {
+		sz := cursor.Image().Bounds().Size()
+		hot := cursor.Hotspot()
+		var err error
+		c, err = windows.CreateImageCursor(sz.X, sz.Y, imageCursorARGB(cursor, false), hot.X, hot.Y)
+		if err != nil {
+			// 无法创建光标（例如图像为空），使用默认光标
+			w.SetCursor(pointer.CursorDefault)
+			return
+		}
+		if w.imageCursors == nil {
+			w.imageCursors = make(map[*pointer.ImageCursor]syscall.Handle)
+		}
+		w.imageCursors[cursor] = c
+	}
+	w.cursor = c
+	if w.cursorIn {
+		windows.SetCursor(w.cursor)
+	}
+	if len(w.imageCursors) > maxImageCursors {
+		// 缓存已满，销毁除当前光标以外的所有光标
+		for k, v := range w.imageCursors {
+			if k != cursor {
+				windows.DestroyCursor(v)
+				delete(w.imageCursors, k)
+			}
+		}
+	}
+}
+
+// SetNativeViews 方法移动、裁剪并显示原生子视图，隐藏不再放置的视图
+func (w *window) SetNativeViews(views []router.NativeView) {
+	placed := make(map[syscall.Handle]bool, len(views))
+	for _, v := range views {
+		h := syscall.Handle(v.Handle)
+		placed[h] = true
+		if !w.nativeViews[h] {
+			if w.nativeViews == nil {
+				w.nativeViews = make(map[syscall.Handle]bool)
+				// 绘制父窗口时排除子窗口的区域
+				style := windows.GetWindowLong(w.hwnd, windows.GWL_STYLE)
+				windows.SetWindowLong(w.hwnd, windows.GWL_STYLE, style|windows.WS_CLIPCHILDREN)
+			}
+			style := windows.GetWindowLong(h, windows.GWL_STYLE)
+			windows.SetWindowLong(h, windows.GWL_STYLE, style&^windows.WS_POPUP|windows.WS_CHILD)
+			windows.SetParent(h, w.hwnd)
+			w.nativeViews[h] = true
+		}
+		b := v.Bounds
+		clip := v.Clip.Intersect(b)
+		if clip.Empty() {
+			windows.ShowWindow(h, windows.SW_HIDE)
+			continue
+		}
+		// 可见区域使用子窗口自身的坐标
+		clip = clip.Sub(b.Min)
+		windows.SetWindowRgn(h, windows.Rect{
+			Left:   int32(clip.Min.X),
+			Top:    int32(clip.Min.Y),
+			Right:  int32(clip.Max.X),
+			Bottom: int32(clip.Max.Y),
+		}, false)
+		// 按顺序放到最上层，使后放置的视图位于先放置的视图之上
+		windows.SetWindowPos(h, 0, int32(b.Min.X), int32(b.Min.Y), int32(b.Dx()), int32(b.Dy()),
+			windows.SWP_NOACTIVATE|windows.SWP_SHOWWINDOW)
+	}
+	for h := range w.nativeViews {
+		if !placed[h] {
+			windows.ShowWindow(h, windows.SW_HIDE)
+		}
+	}
+}
+
 // windowsCursor 包含从 pointer.Cursor 到 IDC 的映射
 var windowsCursor = [...]uint16{
 	pointer.CursorDefault:                  windows.IDC_ARROW,       // 默认光标，对应 Windows 的箭头光标
@@ -1052,6 +1763,14 @@ func loadCursor(cursor pointer.Cursor) (syscall.Handle, error) {
 	}
 }
 
+// handles 方法返回 e 的视图的原生句柄
+func (w *window) handles(e ViewEvent) native.Handles {
+	if e.HWND == 0 {
+		return native.Handles{}
+	}
+	return native.Handles{Platform: native.Windows, Window: e.HWND}
+}
+
 // ShowTextInput 方法用于显示或隐藏文本输入，此处为空实现
 func (w *window) ShowTextInput(show bool) {}
 
@@ -1070,6 +1789,10 @@ func (w *window) HWND() (syscall.Handle, int, int) {
 
 // Perform 方法用于执行一系列的系统动作
 func (w *window) Perform(acts system.Action) {
+	if w.parent != 0 {
+		// 嵌入的窗口由宿主程序管理
+		return
+	}
 	walkActions(acts, func(a system.Action) {
 		switch a {
 		case system.ActionCenter: // 窗口居中动作
@@ -1087,12 +1810,31 @@ func (w *window) Perform(acts system.Action) {
 			windows.SetWindowPos(w.hwnd, 0, x, y, dx, dy, windows.SWP_NOZORDER|windows.SWP_FRAMECHANGED)
 		case system.ActionRaise: // 窗口置顶动作
 			w.raise()
+		case system.ActionFocus: // 激活窗口并获取键盘焦点
+			windows.SetForegroundWindow(w.hwnd)
+			windows.SetFocus(w.hwnd)
+		case system.ActionRequestAttention: // 请求用户注意动作
+			if !w.focused {
+				windows.FlashWindow(w.hwnd)
+			}
 		case system.ActionClose: // 关闭窗口动作
 			windows.PostMessage(w.hwnd, windows.WM_CLOSE, 0, 0)
 		}
 	})
 }
 
+// setNoActivate 根据 Config.NoActivate 设置或清除 WS_EX_NOACTIVATE 扩展样式，
+// 带有该样式的窗口在显示和点击时不会被激活
+func (w *window) setNoActivate() {
+	exStyle := windows.GetWindowLong(w.hwnd, windows.GWL_EXSTYLE)
+	if w.config.NoActivate {
+		exStyle |= windows.WS_EX_NOACTIVATE
+	} else {
+		exStyle &^= windows.WS_EX_NOACTIVATE
+	}
+	windows.SetWindowLong(w.hwnd, windows.GWL_EXSTYLE, exStyle)
+}
+
 // raise 方法用于将窗口置顶
 func (w *window) raise() {
 	windows.SetForegroundWindow(w.hwnd) // 将窗口设置为前台窗口
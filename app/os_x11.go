@@ -9,8 +9,8 @@ package app
 /*
 #cgo freebsd openbsd CFLAGS: -I/usr/X11R6/include -I/usr/local/include
 #cgo freebsd openbsd LDFLAGS: -L/usr/X11R6/lib -L/usr/local/lib
-#cgo freebsd openbsd LDFLAGS: -lX11 -lxkbcommon -lxkbcommon-x11 -lX11-xcb -lXcursor -lXfixes
-#cgo linux pkg-config: x11 xkbcommon xkbcommon-x11 x11-xcb xcursor xfixes
+#cgo freebsd openbsd LDFLAGS: -lX11 -lxkbcommon -lxkbcommon-x11 -lX11-xcb -lXcursor -lXfixes -lXi
+#cgo linux pkg-config: x11 xkbcommon xkbcommon-x11 x11-xcb xcursor xfixes xi
 
 #include <stdlib.h>
 #include <locale.h>
@@ -21,24 +21,59 @@ package app
 #include <X11/XKBlib.h>
 #include <X11/Xlib-xcb.h>
 #include <X11/extensions/Xfixes.h>
+#include <X11/extensions/XInput2.h>
 #include <X11/Xcursor/Xcursor.h>
 #include <xkbcommon/xkbcommon-x11.h>
 
+// gio_x11SelectTablet selects the XInput2 pointer events of the
+// device, or the device hierarchy changes for XIAllDevices.
+static void gio_x11SelectTablet(Display *dpy, Window win, int deviceid) {
+	unsigned char bits[XIMaskLen(XI_LASTEVENT)] = {0};
+	XIEventMask mask = {deviceid, sizeof(bits), bits};
+	if (deviceid == XIAllDevices) {
+		XISetMask(bits, XI_HierarchyChanged);
+	} else {
+		XISetMask(bits, XI_ButtonPress);
+		XISetMask(bits, XI_ButtonRelease);
+		XISetMask(bits, XI_Motion);
+	}
+	XISelectEvents(dpy, win, &mask, 1);
+}
+
+// gio_x11Valuator stores the value of the valuator number in s, and
+// reports whether the event changed it.
+static int gio_x11Valuator(XIValuatorState *s, int number, double *value) {
+	if (number < 0 || number >= s->mask_len*8 || !XIMaskIsSet(s->mask, number)) {
+		return 0;
+	}
+	double *v = s->values;
+	for (int i = 0; i < number; i++) {
+		if (XIMaskIsSet(s->mask, i)) {
+			v++;
+		}
+	}
+	*value = *v;
+	return 1;
+}
 */
 import "C"
 import (
 	"errors"
 	"fmt"
 	"image"
+	"math"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 	"unsafe"
 
+	"github.com/Seikaijyu/gio/app/internal/native"
 	"github.com/Seikaijyu/gio/f32"
 	"github.com/Seikaijyu/gio/io/clipboard"
 	"github.com/Seikaijyu/gio/io/key"
 	"github.com/Seikaijyu/gio/io/pointer"
+	"github.com/Seikaijyu/gio/io/router"
 	"github.com/Seikaijyu/gio/io/system"
 	"github.com/Seikaijyu/gio/unit"
 
@@ -72,6 +107,8 @@ type x11Window struct {
 		primary C.Atom
 		// "CLIPBOARD_CONTENT", the clipboard destination property.
 		clipboardContent C.Atom
+		// "PRIMARY_CONTENT", the primary selection destination property.
+		primaryContent C.Atom
 		// "WM_DELETE_WINDOW"
 		evDelWindow C.Atom
 		// "ATOM"
@@ -99,18 +136,55 @@ type x11Window struct {
 	dead bool
 
 	animating bool
+	focused   bool
+	// urgent tracks whether the urgency hint is set.
+	urgent bool
 
 	pointerBtns pointer.Buttons
+	// tablet tracks the pens of XInput2 tablets.
+	tablet struct {
+		// opcode is the major opcode of the XInput extension, or
+		// zero if XInput2 is not available.
+		opcode C.int
+		// tools maps the XInput2 devices of pens to their state.
+		tools map[C.int]*x11Tool
+		// time is the time of the last pen event, for skipping the
+		// core pointer event the server sends after it.
+		time C.Time
+		// hover is the pen hovering the window, if any.
+		hover *x11Tool
+	}
 
 	clipboard struct {
 		content []byte
+		// primary is the content of the primary selection.
+		primary []byte
 	}
 	cursor pointer.Cursor
 	config Config
+	// imageCursors caches the X cursors of image cursors.
+	imageCursors map[*pointer.ImageCursor]C.Cursor
+	// parent is the window the window is embedded in, as given to
+	// NewWindowFrom.
+	parent C.Window
+	// nativeViews maps native child windows to the container
+	// windows that clip them.
+	nativeViews map[C.Window]C.Window
 
 	wakeups chan struct{}
+	// screenSaverReset is when the screensaver was last reset for
+	// KeepScreenOn.
+	screenSaverReset time.Time
+	// displayMu guards the display against closing while
+	// CursorPosition or WarpCursor use it from another goroutine.
+	displayMu sync.Mutex
+	closed    bool
 }
 
+// screenSaverInterval is the interval between screensaver resets of
+// windows with KeepScreenOn, well below the shortest idle timeouts.
+const screenSaverInterval = 30 * time.Second
+
 var (
 	newX11EGLContext    func(w *x11Window) (context, error)
 	newX11VulkanContext func(w *x11Window) (context, error)
@@ -154,16 +228,44 @@ func (w *x11Window) ReadClipboard() {
 func (w *x11Window) WriteClipboard(s string) {
 	w.clipboard.content = []byte(s)
 	C.XSetSelectionOwner(w.x, w.atoms.clipboard, w.xw, C.CurrentTime)
+}
+
+func (w *x11Window) ReadPrimary() {
+	C.XDeleteProperty(w.x, w.xw, w.atoms.primaryContent)
+	C.XConvertSelection(w.x, w.atoms.primary, w.atoms.utf8string, w.atoms.primaryContent, w.xw, C.CurrentTime)
+}
+
+func (w *x11Window) WritePrimary(s string) {
+	w.clipboard.primary = []byte(s)
 	C.XSetSelectionOwner(w.x, w.atoms.primary, w.xw, C.CurrentTime)
 }
 
 func (w *x11Window) Configure(options []Option) {
 	var shints C.XSizeHints
+	if w.parent == 0 {
+		w.updatePosition()
+	}
 	prev := w.config
 	cnf := w.config
 	cnf.apply(w.metric, options)
 	// Decorations are never disabled.
 	cnf.Decorated = true
+	w.config.KeepScreenOn = cnf.KeepScreenOn
+	if cnf.NoActivate != prev.NoActivate {
+		w.config.NoActivate = cnf.NoActivate
+		w.setInputHint(!cnf.NoActivate)
+	}
+	if w.parent != 0 {
+		// Embedded windows are sized and managed by the host.
+		w.config.Decorated = true
+		w.w.Event(ConfigEvent{Config: w.config})
+		return
+	}
+	if cnf.Position != prev.Position {
+		// Move before changing the mode, to maximize the window on
+		// the monitor of the position.
+		w.moveTo(cnf.Position, cnf.positionMonitor, cnf.Size)
+	}
 
 	switch cnf.Mode {
 	case Fullscreen:
@@ -235,6 +337,61 @@ func (w *x11Window) Configure(options []Option) {
 	w.w.Event(ConfigEvent{Config: w.config})
 }
 
+// updatePosition reads the position of the window frame. X11 doesn't
+// report monitors without the XRandR extension, so the monitor is the
+// screen.
+func (w *x11Window) updatePosition() {
+	var x, y C.int
+	var child C.Window
+	C.XTranslateCoordinates(w.x, w.xw, C.XDefaultRootWindow(w.x), 0, 0, &x, &y, &child)
+	left, top := w.frameExtents()
+	w.config.Position = image.Pt(int(x)-left, int(y)-top)
+	w.config.monitor = w.screenBounds()
+}
+
+// moveTo moves the frame of a window of the given size to p, keeping it
+// on the screen.
+func (w *x11Window) moveTo(p image.Point, saved image.Rectangle, size image.Point) {
+	bounds := w.screenBounds()
+	r := image.Rectangle{Min: p, Max: p.Add(size)}
+	found := r.Min.Add(r.Size().Div(2)).In(bounds)
+	p = fitToMonitor(r, saved, bounds, bounds, found)
+	w.config.Position = p
+	C.XMoveWindow(w.x, w.xw, C.int(p.X), C.int(p.Y))
+}
+
+func (w *x11Window) screenBounds() image.Rectangle {
+	screen := C.XDefaultScreen(w.x)
+	return image.Rect(0, 0, int(C.XDisplayWidth(w.x, screen)), int(C.XDisplayHeight(w.x, screen)))
+}
+
+// frameExtents returns the left and top extents of the decorations of
+// the window manager, or zero if the window manager doesn't report
+// them.
+func (w *x11Window) frameExtents() (left, top int) {
+	prop := w.atom("_NET_FRAME_EXTENTS", true)
+	if prop == C.None {
+		return 0, 0
+	}
+	var (
+		typ      C.Atom
+		format   C.int
+		n, after C.ulong
+		data     *C.uchar
+	)
+	if C.XGetWindowProperty(w.x, w.xw, prop, 0, 4, C.False, C.XA_CARDINAL, &typ, &format, &n, &after, &data) != C.Success || data == nil {
+		return 0, 0
+	}
+	defer C.XFree(unsafe.Pointer(data))
+	if format != 32 || n != 4 {
+		return 0, 0
+	}
+	// Format 32 properties are returned as longs: left, right, top
+	// and bottom.
+	ext := (*[4]C.long)(unsafe.Pointer(data))
+	return int(ext[0]), int(ext[2])
+}
+
 func (w *x11Window) setTitle(prev, cnf Config) {
 	if prev.Title != cnf.Title {
 		title := cnf.Title
@@ -254,12 +411,24 @@ func (w *x11Window) setTitle(prev, cnf Config) {
 }
 
 func (w *x11Window) Perform(acts system.Action) {
+	if w.parent != 0 {
+		// Embedded windows are managed by the host.
+		return
+	}
 	walkActions(acts, func(a system.Action) {
 		switch a {
 		case system.ActionCenter:
 			w.center()
 		case system.ActionRaise:
 			w.raise()
+		case system.ActionFocus:
+			// NoActivate windows accept focus until they lose it.
+			w.setInputHint(true)
+			w.raise()
+		case system.ActionRequestAttention:
+			if !w.focused {
+				w.setUrgent(true)
+			}
 		}
 	})
 	if acts&system.ActionClose != 0 {
@@ -267,6 +436,29 @@ func (w *x11Window) Perform(acts system.Action) {
 	}
 }
 
+// setUrgent sets or clears the urgency hint that window managers use
+// to draw the attention of the user to the window.
+func (w *x11Window) setUrgent(urgent bool) {
+	if w.urgent == urgent {
+		return
+	}
+	w.urgent = urgent
+	hints := C.XGetWMHints(w.x, w.xw)
+	if hints == nil {
+		hints = C.XAllocWMHints()
+		if hints == nil {
+			return
+		}
+	}
+	defer C.XFree(unsafe.Pointer(hints))
+	if urgent {
+		hints.flags |= C.XUrgencyHint
+	} else {
+		hints.flags &^= C.XUrgencyHint
+	}
+	C.XSetWMHints(w.x, w.xw, hints)
+}
+
 func (w *x11Window) center() {
 	screen := C.XDefaultScreen(w.x)
 	width := C.XDisplayWidth(w.x, screen)
@@ -284,6 +476,25 @@ func (w *x11Window) center() {
 	C.XMoveResizeWindow(w.x, w.xw, C.int(x), C.int(y), C.uint(sz.X), C.uint(sz.Y))
 }
 
+// setInputHint sets the input hint that tells the window manager
+// whether the window accepts keyboard focus.
+func (w *x11Window) setInputHint(input bool) {
+	hints := C.XGetWMHints(w.x, w.xw)
+	if hints == nil {
+		hints = C.XAllocWMHints()
+		if hints == nil {
+			return
+		}
+	}
+	defer C.XFree(unsafe.Pointer(hints))
+	hints.flags |= C.InputHint
+	hints.input = C.False
+	if input {
+		hints.input = C.True
+	}
+	C.XSetWMHints(w.x, w.xw, hints)
+}
+
 func (w *x11Window) raise() {
 	var xev C.XEvent
 	ev := (*C.XClientMessageEvent)(unsafe.Pointer(&xev))
@@ -304,6 +515,39 @@ func (w *x11Window) raise() {
 	C.XMapRaised(w.display(), w.xw)
 }
 
+func (w *x11Window) CursorCapabilities() CursorCapability {
+	return CursorQuery | CursorWarp
+}
+
+// CursorPosition returns the cursor position relative to the root
+// window, like Config.Position. The display is thread safe, see
+// XInitThreads in newX11Window.
+func (w *x11Window) CursorPosition() (image.Point, error) {
+	w.displayMu.Lock()
+	defer w.displayMu.Unlock()
+	if w.closed {
+		return image.Point{}, errors.New("x11: window closed")
+	}
+	var root, child C.Window
+	var x, y, winX, winY C.int
+	var mask C.uint
+	if C.XQueryPointer(w.x, C.XDefaultRootWindow(w.x), &root, &child, &x, &y, &winX, &winY, &mask) == 0 {
+		return image.Point{}, errors.New("x11: the cursor is on another screen")
+	}
+	return image.Pt(int(x), int(y)), nil
+}
+
+func (w *x11Window) WarpCursor(p image.Point) error {
+	w.displayMu.Lock()
+	defer w.displayMu.Unlock()
+	if w.closed {
+		return errors.New("x11: window closed")
+	}
+	C.XWarpPointer(w.x, C.None, C.XDefaultRootWindow(w.x), 0, 0, 0, 0, C.int(p.X), C.int(p.Y))
+	C.XFlush(w.x)
+	return nil
+}
+
 func (w *x11Window) SetCursor(cursor pointer.Cursor) {
 	if cursor == pointer.CursorNone {
 		w.cursor = cursor
@@ -324,6 +568,92 @@ func (w *x11Window) SetCursor(cursor pointer.Cursor) {
 	C.XDefineCursor(w.x, w.xw, c)
 }
 
+func (w *x11Window) SetImageCursor(cursor *pointer.ImageCursor) {
+	c, ok := w.imageCursors[cursor]
+	if !ok {
+		img := cursor.Image()
+		sz := img.Bounds().Size()
+		if sz.X == 0 || sz.Y == 0 {
+			w.SetCursor(pointer.CursorDefault)
+			return
+		}
+		ximg := C.XcursorImageCreate(C.int(sz.X), C.int(sz.Y))
+		if ximg == nil {
+			w.SetCursor(pointer.CursorDefault)
+			return
+		}
+		hot := cursor.Hotspot()
+		ximg.xhot = C.XcursorDim(hot.X)
+		ximg.yhot = C.XcursorDim(hot.Y)
+		pixels := unsafe.Slice(ximg.pixels, sz.X*sz.Y)
+		for i, p := range imageCursorARGB(cursor, true) {
+			pixels[i] = C.XcursorPixel(p)
+		}
+		c = C.XcursorImageLoadCursor(w.x, ximg)
+		C.XcursorImageDestroy(ximg)
+		if w.imageCursors == nil {
+			w.imageCursors = make(map[*pointer.ImageCursor]C.Cursor)
+		}
+		w.imageCursors[cursor] = c
+	}
+	if w.cursor == pointer.CursorNone {
+		C.XFixesShowCursor(w.x, w.xw)
+	}
+	w.cursor = pointer.CursorDefault
+	C.XDefineCursor(w.x, w.xw, c)
+	if len(w.imageCursors) > maxImageCursors {
+		for k, v := range w.imageCursors {
+			if k != cursor {
+				C.XFreeCursor(w.x, v)
+				delete(w.imageCursors, k)
+			}
+		}
+	}
+}
+
+func (w *x11Window) SetNativeViews(views []router.NativeView) {
+	placed := make(map[C.Window]bool, len(views))
+	for _, v := range views {
+		child := C.Window(v.Handle)
+		placed[child] = true
+		b := v.Bounds
+		clip := v.Clip.Intersect(b)
+		container, ok := w.nativeViews[child]
+		if !ok {
+			// The container clips the child to its bounds.
+			container = C.XCreateSimpleWindow(w.x, w.xw, 0, 0, 1, 1, 0, 0, 0)
+			C.XReparentWindow(w.x, child, container, 0, 0)
+			C.XMapWindow(w.x, child)
+			if w.nativeViews == nil {
+				w.nativeViews = make(map[C.Window]C.Window)
+			}
+			w.nativeViews[child] = container
+		}
+		if clip.Empty() {
+			C.XUnmapWindow(w.x, container)
+			continue
+		}
+		C.XMoveResizeWindow(w.x, container, C.int(clip.Min.X), C.int(clip.Min.Y), C.uint(clip.Dx()), C.uint(clip.Dy()))
+		off := b.Min.Sub(clip.Min)
+		C.XMoveResizeWindow(w.x, child, C.int(off.X), C.int(off.Y), C.uint(b.Dx()), C.uint(b.Dy()))
+		// Raise the containers in turn to stack them in the order of views.
+		C.XMapRaised(w.x, container)
+	}
+	for child, container := range w.nativeViews {
+		if !placed[child] {
+			C.XUnmapWindow(w.x, container)
+		}
+	}
+}
+
+func (w *x11Window) handles(e ViewEvent) native.Handles {
+	x, ok := e.(X11ViewEvent)
+	if !ok || x.Window == 0 {
+		return native.Handles{}
+	}
+	return native.Handles{Platform: native.X11, Display: x.Display, Window: x.Window}
+}
+
 func (w *x11Window) ShowTextInput(show bool) {}
 
 func (w *x11Window) SetInputHint(_ key.InputHint) {}
@@ -375,6 +705,9 @@ func (w *x11Window) sendWMStateEvent(action C.long, atom1, atom2 C.ulong) {
 
 var x11OneByte = make([]byte, 1)
 
+// reportsPosition implements positionDriver.
+func (w *x11Window) reportsPosition() {}
+
 func (w *x11Window) Wakeup() {
 	select {
 	case w.wakeups <- struct{}{}:
@@ -425,8 +758,16 @@ loop:
 			if !anim {
 				// Clear poll events.
 				*xEvents = 0
+				timeout := -1
+				if w.keepScreenOn() {
+					// Wake up for the next screensaver reset.
+					timeout = int(time.Until(w.screenSaverReset.Add(screenSaverInterval)) / time.Millisecond)
+					if timeout < 0 {
+						timeout = 0
+					}
+				}
 				// Wait for X event or gio notification.
-				if _, err := syscall.Poll(pollfds, -1); err != nil && err != syscall.EINTR {
+				if _, err := syscall.Poll(pollfds, timeout); err != nil && err != syscall.EINTR {
 					panic(fmt.Errorf("x11 loop: poll failed: %w", err))
 				}
 				switch {
@@ -455,6 +796,12 @@ loop:
 			w.w.Event(wakeupEvent{})
 		default:
 		}
+		if w.keepScreenOn() && time.Since(w.screenSaverReset) >= screenSaverInterval {
+			// Resetting the screensaver also postpones DPMS.
+			C.XResetScreenSaver(w.x)
+			C.XFlush(w.x)
+			w.screenSaverReset = time.Now()
+		}
 
 		if (anim || syn) && w.config.Size.X != 0 && w.config.Size.Y != 0 {
 			w.w.Event(frameEvent{
@@ -469,6 +816,11 @@ loop:
 	}
 }
 
+// keepScreenOn reports whether the screensaver should be suppressed.
+func (w *x11Window) keepScreenOn() bool {
+	return w.config.KeepScreenOn && w.stage >= system.StageInactive
+}
+
 func (w *x11Window) destroy() {
 	if w.notify.write != 0 {
 		syscall.Close(w.notify.write)
@@ -482,8 +834,18 @@ func (w *x11Window) destroy() {
 		w.xkb.Destroy()
 		w.xkb = nil
 	}
+	for _, c := range w.imageCursors {
+		C.XFreeCursor(w.x, c)
+	}
+	w.imageCursors = nil
+	// Destroying the window destroys the containers of the native
+	// views and the views themselves.
+	w.nativeViews = nil
 	C.XDestroyWindow(w.x, w.xw)
+	w.displayMu.Lock()
+	w.closed = true
 	C.XCloseDisplay(w.x)
+	w.displayMu.Unlock()
 }
 
 // atom is a wrapper around XInternAtom. Callers should cache the result
@@ -537,15 +899,21 @@ func (h *x11EventHandler) handleEvents() bool {
 			}
 			kevt := (*C.XKeyPressedEvent)(unsafe.Pointer(xev))
 			for _, e := range h.w.xkb.DispatchKey(uint32(kevt.keycode), ks) {
-				if ee, ok := e.(key.EditEvent); ok {
+				switch e := e.(type) {
+				case key.EditEvent:
 					// There's no support for IME yet.
-					w.w.EditorInsert(ee.Text)
-				} else {
+					w.w.EditorInsert(e.Text)
+				case xkb.ComposeEvent:
+					w.w.EditorCompose(e.Text)
+				default:
 					w.w.Event(e)
 				}
 			}
 		case C.ButtonPress, C.ButtonRelease:
 			bevt := (*C.XButtonEvent)(unsafe.Pointer(xev))
+			if w.fromTablet(bevt.time) {
+				continue
+			}
 			ev := pointer.Event{
 				Kind:   pointer.Press,
 				Source: pointer.Mouse,
@@ -560,7 +928,8 @@ func (h *x11EventHandler) handleEvents() bool {
 				ev.Kind = pointer.Release
 			}
 			var btn pointer.Buttons
-			const scrollScale = 10
+			// Each click of a wheel scrolls wheelLines lines.
+			const scrollScale = wheelLines
 			switch bevt.button {
 			case C.Button1:
 				btn = pointer.ButtonPrimary
@@ -596,6 +965,10 @@ func (h *x11EventHandler) handleEvents() bool {
 			default:
 				continue
 			}
+			if ev.Kind == pointer.Scroll {
+				ev.ScrollMode = pointer.ScrollLines
+				ev.Scroll = scrollPixels(w.metric, ev.ScrollMode, ev.Scroll, w.config.Size)
+			}
 			switch _type {
 			case C.ButtonPress:
 				w.pointerBtns |= btn
@@ -606,6 +979,9 @@ func (h *x11EventHandler) handleEvents() bool {
 			w.w.Event(ev)
 		case C.MotionNotify:
 			mevt := (*C.XMotionEvent)(unsafe.Pointer(xev))
+			if w.fromTablet(mevt.time) {
+				continue
+			}
 			w.w.Event(pointer.Event{
 				Kind:    pointer.Move,
 				Source:  pointer.Mouse,
@@ -617,16 +993,37 @@ func (h *x11EventHandler) handleEvents() bool {
 				Time:      time.Duration(mevt.time) * time.Millisecond,
 				Modifiers: w.xkb.Modifiers(),
 			})
+		case C.GenericEvent:
+			cookie := (*C.XGenericEventCookie)(unsafe.Pointer(xev))
+			if w.tablet.opcode == 0 || cookie.extension != w.tablet.opcode {
+				break
+			}
+			if C.XGetEventData(w.x, cookie) == C.True {
+				w.tabletEvent(cookie)
+				C.XFreeEventData(w.x, cookie)
+			}
 		case C.Expose: // update
 			// redraw only on the last expose event
 			redraw = (*C.XExposeEvent)(unsafe.Pointer(xev)).count == 0
 		case C.FocusIn:
+			w.focused = true
+			// Window managers leave clearing the urgency hint
+			// to the client.
+			w.setUrgent(false)
 			w.w.Event(key.FocusEvent{Focus: true})
 		case C.FocusOut:
+			w.focused = false
+			if w.config.NoActivate {
+				w.setInputHint(false)
+			}
 			w.w.Event(key.FocusEvent{Focus: false})
 		case C.ConfigureNotify: // window configuration change
 			cevt := (*C.XConfigureEvent)(unsafe.Pointer(xev))
-			if sz := image.Pt(int(cevt.width), int(cevt.height)); sz != w.config.Size {
+			pos := w.config.Position
+			if w.parent == 0 {
+				w.updatePosition()
+			}
+			if sz := image.Pt(int(cevt.width), int(cevt.height)); sz != w.config.Size || w.config.Position != pos {
 				w.config.Size = sz
 				w.w.Event(ConfigEvent{Config: w.config})
 			}
@@ -634,10 +1031,13 @@ func (h *x11EventHandler) handleEvents() bool {
 		case C.SelectionNotify:
 			cevt := (*C.XSelectionEvent)(unsafe.Pointer(xev))
 			prop := w.atoms.clipboardContent
-			if cevt.property != prop {
+			primary := cevt.selection == w.atoms.primary
+			if primary {
+				prop = w.atoms.primaryContent
+			} else if cevt.selection != w.atoms.clipboard {
 				break
 			}
-			if cevt.selection != w.atoms.clipboard {
+			if cevt.property != prop {
 				break
 			}
 			var text C.XTextProperty
@@ -650,7 +1050,7 @@ func (h *x11EventHandler) handleEvents() bool {
 				break
 			}
 			str := C.GoStringN((*C.char)(unsafe.Pointer(text.value)), C.int(text.nitems))
-			w.w.Event(clipboard.Event{Text: str})
+			w.w.Event(clipboard.Event{Text: str, Primary: primary})
 		case C.SelectionRequest:
 			cevt := (*C.XSelectionRequestEvent)(unsafe.Pointer(xev))
 			if (cevt.selection != w.atoms.clipboard && cevt.selection != w.atoms.primary) || cevt.property == C.None {
@@ -690,6 +1090,9 @@ func (h *x11EventHandler) handleEvents() bool {
 				notify()
 			case w.atoms.plaintext, w.atoms.utf8string, w.atoms.gtk_text_buffer_contents:
 				content := w.clipboard.content
+				if cevt.selection == w.atoms.primary {
+					content = w.clipboard.primary
+				}
 				var ptr *C.uchar
 				if len(content) > 0 {
 					ptr = (*C.uchar)(unsafe.Pointer(&content[0]))
@@ -773,7 +1176,19 @@ func newX11Window(gioWin *callbacks, options []Option) error {
 		background_pixmap: C.None,
 		override_redirect: C.False,
 	}
-	win := C.XCreateWindow(dpy, C.XDefaultRootWindow(dpy),
+	parent := C.XDefaultRootWindow(dpy)
+	if cnf.parent != 0 {
+		// Fill the parent window.
+		parent = C.Window(cnf.parent)
+		var attrs C.XWindowAttributes
+		if C.XGetWindowAttributes(dpy, parent, &attrs) == 0 {
+			xkb.Destroy()
+			C.XCloseDisplay(dpy)
+			return errors.New("x11: invalid parent window")
+		}
+		cnf.Size = image.Pt(int(attrs.width), int(attrs.height))
+	}
+	win := C.XCreateWindow(dpy, parent,
 		0, 0, C.uint(cnf.Size.X), C.uint(cnf.Size.Y),
 		0, C.CopyFromParent, C.InputOutput, nil,
 		C.CWEventMask|C.CWBackPixmap|C.CWOverrideRedirect, &swa)
@@ -786,6 +1201,9 @@ func newX11Window(gioWin *callbacks, options []Option) error {
 		wakeups:      make(chan struct{}, 1),
 		config:       Config{Size: cnf.Size},
 	}
+	if cnf.parent != 0 {
+		w.parent = parent
+	}
 	w.notify.read = pipe[0]
 	w.notify.write = pipe[1]
 
@@ -796,6 +1214,9 @@ func newX11Window(gioWin *callbacks, options []Option) error {
 
 	var hints C.XWMHints
 	hints.input = C.True
+	if cnf.NoActivate {
+		hints.input = C.False
+	}
 	hints.flags = C.InputHint
 	C.XSetWMHints(dpy, win, &hints)
 
@@ -811,6 +1232,7 @@ func newX11Window(gioWin *callbacks, options []Option) error {
 	w.atoms.clipboard = w.atom("CLIPBOARD", false)
 	w.atoms.primary = w.atom("PRIMARY", false)
 	w.atoms.clipboardContent = w.atom("CLIPBOARD_CONTENT", false)
+	w.atoms.primaryContent = w.atom("PRIMARY_CONTENT", false)
 	w.atoms.atom = w.atom("ATOM", false)
 	w.atoms.targets = w.atom("TARGETS", false)
 	w.atoms.wmName = w.atom("_NET_WM_NAME", false)
@@ -822,9 +1244,25 @@ func newX11Window(gioWin *callbacks, options []Option) error {
 
 	// extensions
 	C.XSetWMProtocols(dpy, win, &w.atoms.evDelWindow, 1)
+	w.initTablet()
+
+	if cnf.NoActivate {
+		// A zero user time asks the window manager not to focus the
+		// window when it is mapped.
+		var userTime C.long
+		C.XChangeProperty(dpy, win, w.atom("_NET_WM_USER_TIME", false), w.atom("CARDINAL", false), 32,
+			C.PropModeReplace, (*C.uchar)(unsafe.Pointer(&userTime)), 1)
+	}
+
+	if h, modal := dialogOwner(options); cnf.parent == 0 {
+		if owner, ok := h.(C.Window); ok {
+			w.setOwner(owner, cnf.Size, modal)
+		}
+	}
 
 	go func() {
 		w.w.SetDriver(w)
+		w.w.SetOwnerHandle(win)
 
 		// make the window visible on the screen
 		C.XMapWindow(dpy, win)
@@ -832,6 +1270,7 @@ func newX11Window(gioWin *callbacks, options []Option) error {
 		w.w.Event(X11ViewEvent{Display: unsafe.Pointer(dpy), Window: uintptr(win)})
 		w.setStage(system.StageRunning)
 		w.loop()
+		w.w.SetOwnerHandle(nil)
 		w.w.Event(X11ViewEvent{})
 		w.w.Event(system.DestroyEvent{Err: nil})
 		w.destroy()
@@ -839,6 +1278,229 @@ func newX11Window(gioWin *callbacks, options []Option) error {
 	return nil
 }
 
+// setOwner makes the window a dialog of owner, centered over it. It
+// must be called before the window is mapped.
+func (w *x11Window) setOwner(owner C.Window, size image.Point, modal bool) {
+	C.XSetTransientForHint(w.x, w.xw, owner)
+	if modal {
+		state := w.atom("_NET_WM_STATE_MODAL", false)
+		C.XChangeProperty(w.x, w.xw, w.atoms.wmState, w.atoms.atom, 32,
+			C.PropModeReplace, (*C.uchar)(unsafe.Pointer(&state)), 1)
+	}
+	var attrs C.XWindowAttributes
+	if C.XGetWindowAttributes(w.x, owner, &attrs) == 0 {
+		return
+	}
+	var x, y C.int
+	var child C.Window
+	C.XTranslateCoordinates(w.x, owner, C.XDefaultRootWindow(w.x), 0, 0, &x, &y, &child)
+	x += (attrs.width - C.int(size.X)) / 2
+	y += (attrs.height - C.int(size.Y)) / 2
+	C.XMoveWindow(w.x, w.xw, x, y)
+}
+
+// x11Tool is the pen of a tablet, reported by XInput2.
+type x11Tool struct {
+	id      pointer.ID
+	source  pointer.Source
+	buttons pointer.Buttons
+	// pressure and tilt are the valuators of the pen, and their last
+	// values.
+	pressure, tiltX, tiltY x11Valuator
+}
+
+// x11Valuator is an axis of an XInput2 device.
+type x11Valuator struct {
+	// number is the number of the valuator, or -1 if the device
+	// doesn't have it.
+	number   C.int
+	min, max float64
+	value    float64
+}
+
+// initTablet selects the pen events of XInput2 tablets, if the server
+// supports XInput2.
+func (w *x11Window) initTablet() {
+	name := C.CString("XInputExtension")
+	defer C.free(unsafe.Pointer(name))
+	var opcode, evBase, errBase C.int
+	if C.XQueryExtension(w.x, name, &opcode, &evBase, &errBase) != C.True {
+		return
+	}
+	major, minor := C.int(2), C.int(0)
+	if C.XIQueryVersion(w.x, &major, &minor) != C.Success {
+		return
+	}
+	w.tablet.opcode = opcode
+	C.gio_x11SelectTablet(w.x, w.xw, C.XIAllDevices)
+	w.selectTablets()
+}
+
+// selectTablets selects the pointer events of the pens of the tablets
+// attached to the server. Only the slave devices of pens are
+// selected, so the core pointer events of the mouse are unaffected.
+func (w *x11Window) selectTablets() {
+	pressure := w.atom("Abs Pressure", true)
+	tiltX := w.atom("Abs Tilt X", true)
+	tiltY := w.atom("Abs Tilt Y", true)
+	if pressure == C.None {
+		// No device has reported pressure.
+		return
+	}
+	var n C.int
+	infos := C.XIQueryDevice(w.x, C.XIAllDevices, &n)
+	if infos == nil {
+		return
+	}
+	defer C.XIFreeDeviceInfo(infos)
+	tools := make(map[C.int]*x11Tool)
+	for _, info := range unsafe.Slice(infos, n) {
+		if info.use != C.XISlavePointer && info.use != C.XIFloatingSlave {
+			continue
+		}
+		t := &x11Tool{
+			// Offset pen ids to tell them apart from the mouse.
+			id:       pointer.ID(0x8000 + info.deviceid),
+			source:   pointer.Stylus,
+			pressure: x11Valuator{number: -1},
+			tiltX:    x11Valuator{number: -1},
+			tiltY:    x11Valuator{number: -1},
+		}
+		for _, class := range unsafe.Slice(info.classes, info.num_classes) {
+			if class._type != C.XIValuatorClass {
+				continue
+			}
+			v := (*C.XIValuatorClassInfo)(unsafe.Pointer(class))
+			val := x11Valuator{number: v.number, min: float64(v.min), max: float64(v.max), value: float64(v.value)}
+			switch v.label {
+			case pressure:
+				t.pressure = val
+			case tiltX:
+				t.tiltX = val
+			case tiltY:
+				t.tiltY = val
+			}
+		}
+		if t.pressure.number == -1 {
+			// Not a pen.
+			continue
+		}
+		// Tablet drivers name the device of the inverted end of a
+		// pen after it.
+		if strings.Contains(strings.ToLower(C.GoString(info.name)), "eraser") {
+			t.source = pointer.Eraser
+		}
+		if old, ok := w.tablet.tools[info.deviceid]; ok {
+			t.buttons = old.buttons
+		}
+		tools[info.deviceid] = t
+		C.gio_x11SelectTablet(w.x, w.xw, info.deviceid)
+	}
+	w.tablet.tools = tools
+	if h := w.tablet.hover; h != nil && tools[C.int(h.id-0x8000)] == nil {
+		w.tablet.hover = nil
+	}
+}
+
+// tabletEvent handles an XInput2 event.
+func (w *x11Window) tabletEvent(cookie *C.XGenericEventCookie) {
+	if cookie.evtype == C.XI_HierarchyChanged {
+		// Tablets were plugged in or removed.
+		w.selectTablets()
+		return
+	}
+	e := (*C.XIDeviceEvent)(cookie.data)
+	t := w.tablet.tools[e.sourceid]
+	if t == nil {
+		return
+	}
+	var kind pointer.Kind
+	var btn pointer.Buttons
+	switch cookie.evtype {
+	case C.XI_Motion:
+		kind = pointer.Move
+	case C.XI_ButtonPress, C.XI_ButtonRelease:
+		kind = pointer.Press
+		if cookie.evtype == C.XI_ButtonRelease {
+			kind = pointer.Release
+		}
+		switch e.detail {
+		case C.Button1:
+			// The tip of the pen touches the tablet.
+			btn = pointer.ButtonPrimary
+		case C.Button2:
+			btn = pointer.ButtonTertiary
+		case C.Button3:
+			btn = pointer.ButtonSecondary
+		default:
+			// Leave the scroll buttons of tablets to the core
+			// pointer events.
+			return
+		}
+	default:
+		return
+	}
+	w.tablet.time = e.time
+	if kind == pointer.Press {
+		t.buttons |= btn
+	} else {
+		t.buttons &^= btn
+	}
+	for _, v := range []*x11Valuator{&t.pressure, &t.tiltX, &t.tiltY} {
+		var val C.double
+		if C.gio_x11Valuator(&e.valuators, v.number, &val) != 0 {
+			v.value = float64(val)
+		}
+	}
+	ev := pointer.Event{
+		Kind:      kind,
+		Source:    t.source,
+		PointerID: t.id,
+		Position:  f32.Point{X: float32(e.event_x), Y: float32(e.event_y)},
+		Buttons:   t.buttons,
+		Time:      time.Duration(e.time) * time.Millisecond,
+		Modifiers: w.xkb.Modifiers(),
+	}
+	if p := t.pressure; p.max > p.min {
+		ev.Pressure = float32((p.value - p.min) / (p.max - p.min))
+	}
+	// Tablet drivers report the tilt in degrees.
+	ev.TiltX = float32(math.Max(-90, math.Min(90, t.tiltX.value)))
+	ev.TiltY = float32(math.Max(-90, math.Min(90, t.tiltY.value)))
+	if h := w.tablet.hover; h != nil && h != t {
+		w.tabletLeave()
+	}
+	w.tablet.hover = t
+	w.w.Event(ev)
+}
+
+// fromTablet reports whether the core pointer event at time t repeats
+// the last pen event. Otherwise, the mouse took over the pointer from
+// the pen, and the pen stops hovering.
+func (w *x11Window) fromTablet(t C.Time) bool {
+	if w.tablet.time != 0 && t == w.tablet.time {
+		w.tablet.time = 0
+		return true
+	}
+	w.tabletLeave()
+	return false
+}
+
+// tabletLeave ends the hover of the pen, if any.
+func (w *x11Window) tabletLeave() {
+	t := w.tablet.hover
+	if t == nil {
+		return
+	}
+	w.tablet.hover = nil
+	w.w.Event(pointer.Event{
+		Kind:      pointer.Leave,
+		Source:    t.source,
+		PointerID: t.id,
+		Modifiers: w.xkb.Modifiers(),
+	})
+}
+
 // detectUIScale reports the system UI scale, or 1.0 if it fails.
 func x11DetectUIScale(dpy *C.Display) float32 {
 	// default fixed DPI value used in most desktop UI toolkits
@@ -0,0 +1,60 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package app
+
+import "runtime"
+
+// renderLoop renders the frames of a single window on a goroutine
+// locked to its own OS thread. At most one frame is in flight at a
+// time; the event thread waits for it before touching the GPU state of
+// the window again.
+type renderLoop struct {
+	jobs chan func() error
+	done chan error
+	// busy tracks whether a job is running.
+	busy bool
+}
+
+func newRenderLoop() *renderLoop {
+	r := &renderLoop{
+		jobs: make(chan func() error),
+		done: make(chan error, 1),
+	}
+	go r.run()
+	return r
+}
+
+func (r *renderLoop) run() {
+	// Contexts such as OpenGL are bound to the thread that made them
+	// current.
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	for f := range r.jobs {
+		r.done <- f()
+	}
+}
+
+// start runs f on the render thread and returns immediately. The
+// previous job must have been waited for.
+func (r *renderLoop) start(f func() error) {
+	if r.busy {
+		panic("app: render job already running")
+	}
+	r.busy = true
+	r.jobs <- f
+}
+
+// wait waits for the running job, if any, and returns its error.
+func (r *renderLoop) wait() error {
+	if !r.busy {
+		return nil
+	}
+	r.busy = false
+	return <-r.done
+}
+
+// close waits for the running job and stops the render thread.
+func (r *renderLoop) close() {
+	r.wait()
+	close(r.jobs)
+}
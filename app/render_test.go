@@ -0,0 +1,91 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package app
+
+import (
+	"errors"
+	"image"
+	"testing"
+
+	"github.com/Seikaijyu/gio/gpu"
+	"github.com/Seikaijyu/gio/io/system"
+	"github.com/Seikaijyu/gio/op"
+)
+
+// renderTestContext is a context whose frames fail with lockErr.
+type renderTestContext struct {
+	lockErr   error
+	refreshes int
+	released  bool
+}
+
+// renderTestDriver is a driver that renders on a renderLoop.
+type renderTestDriver struct {
+	driver
+	animating bool
+}
+
+func (c *renderTestContext) API() gpu.API                            { return nil }
+func (c *renderTestContext) RenderTarget() (gpu.RenderTarget, error) { return nil, nil }
+func (c *renderTestContext) Present() error                          { return nil }
+func (c *renderTestContext) Refresh() error                          { c.refreshes++; return nil }
+func (c *renderTestContext) Release()                                { c.released = true }
+func (c *renderTestContext) Lock() error                             { return c.lockErr }
+func (c *renderTestContext) Unlock()                                 {}
+
+func (d *renderTestDriver) SetAnimating(anim bool) { d.animating = anim }
+func (d *renderTestDriver) renderThreaded()        {}
+
+func TestRenderLoopRefresh(t *testing.T) {
+	ctx := &renderTestContext{lockErr: errOutOfDate}
+	d := new(renderTestDriver)
+	w := &Window{ctx: ctx, scheduler: systemScheduler{}, stage: system.StageRunning}
+	defer func() {
+		if w.render != nil {
+			w.render.close()
+		}
+	}()
+	var frame op.Ops
+	size := image.Pt(10, 10)
+	ack := make(chan struct{}, 1)
+	if err := w.validateAndProcess(d, size, false, &frame, ack); err != nil {
+		t.Fatal(err)
+	}
+	if w.render == nil {
+		t.Fatal("the frame of a threaded driver wasn't rendered on a render loop")
+	}
+	// The frame is in flight; the event thread waits for it before the
+	// next frame.
+	if err := w.waitRender(d); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-ack:
+	default:
+		t.Error("the client wasn't let continue after the frame")
+	}
+	// An out of date surface is refreshed and redrawn.
+	if !w.refresh || !d.animating {
+		t.Fatalf("refresh %v, redraw %v after an out of date frame, want both", w.refresh, d.animating)
+	}
+	if ctx.refreshes != 0 {
+		t.Fatalf("%d refreshes before the next frame", ctx.refreshes)
+	}
+	// A refreshing frame waits for the render loop.
+	ctx.lockErr = errors.New("render failed")
+	if err := w.validateAndProcess(d, size, false, &frame, nil); err != ctx.lockErr {
+		t.Errorf("refreshing frame failed with %v, want %v", err, ctx.lockErr)
+	}
+	if ctx.refreshes != 1 || w.refresh {
+		t.Errorf("%d refreshes, pending refresh %v; want 1 refresh", ctx.refreshes, w.refresh)
+	}
+	// A lost device is released, and the frame redrawn.
+	ctx.lockErr = gpu.ErrDeviceLost
+	d.animating, w.animating = false, false
+	if err := w.validateAndProcess(d, size, true, &frame, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !ctx.released || w.ctx != nil || !d.animating {
+		t.Errorf("context released %v, redraw %v after a lost device, want both", ctx.released, d.animating)
+	}
+}
@@ -0,0 +1,43 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package app
+
+import (
+	"image"
+	"runtime"
+
+	"github.com/Seikaijyu/gio/f32"
+	"github.com/Seikaijyu/gio/io/pointer"
+	"github.com/Seikaijyu/gio/unit"
+)
+
+// wheelLines is the number of lines a tick of a mouse wheel scrolls,
+// on platforms without a setting for it.
+const wheelLines = 3
+
+// scrollLine returns the distance of scrolling a line. Apple platforms
+// scroll lines of 10 points, as AppKit does; elsewhere a tick of
+// wheelLines lines scrolls about 100 dp, as browsers do.
+func scrollLine() unit.Dp {
+	if runtime.GOOS == "darwin" || runtime.GOOS == "ios" {
+		return 10
+	}
+	return 33
+}
+
+// scrollPixels converts the scroll distance d in the unit of mode to
+// pixels. Scrolling a page scrolls most of page, the size of the window
+// in pixels, keeping some of the previous content in view.
+func scrollPixels(m unit.Metric, mode pointer.ScrollMode, d f32.Point, page image.Point) f32.Point {
+	switch mode {
+	case pointer.ScrollLines:
+		return d.Mul(m.PxPerDp * float32(scrollLine()))
+	case pointer.ScrollPages:
+		return f32.Point{
+			X: d.X * float32(page.X) * 7 / 8,
+			Y: d.Y * float32(page.Y) * 7 / 8,
+		}
+	default:
+		return d
+	}
+}
@@ -0,0 +1,192 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Settings is a persistent store of application settings. Settings
+// are kept in a JSON file below DataDir and saved whenever they
+// change. Any value encodable by encoding/json can be stored, such as
+// a theme palette with material.SavePalette. Settings is safe for
+// concurrent use.
+type Settings struct {
+	path string
+
+	mu       sync.Mutex
+	values   map[string]json.RawMessage
+	watchers map[int]func(key string)
+	nextID   int
+}
+
+// settingGeometry is the prefix of the keys of window geometries.
+const settingGeometry = "app.window.geometry."
+
+// OpenSettings loads the settings named name from the application
+// directory in DataDir, identified by ID. Missing settings files
+// are not an error; they're created by the first change.
+func OpenSettings(name string) (*Settings, error) {
+	dir, err := DataDir()
+	if err != nil {
+		return nil, err
+	}
+	return openSettings(filepath.Join(dir, ID, name+".json"))
+}
+
+func openSettings(path string) (*Settings, error) {
+	s := &Settings{
+		path:     path,
+		values:   make(map[string]json.RawMessage),
+		watchers: make(map[int]func(key string)),
+	}
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return s, nil
+	case err != nil:
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &s.values); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Get decodes the setting for key into v and reports whether it
+// was present and valid.
+func (s *Settings) Get(key string, v interface{}) bool {
+	s.mu.Lock()
+	data, ok := s.values[key]
+	s.mu.Unlock()
+	return ok && json.Unmarshal(data, v) == nil
+}
+
+// Set changes the setting for key to v, saves the settings and
+// notifies watchers. v must be encodable by encoding/json.
+func (s *Settings) Set(key string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	if old, ok := s.values[key]; ok && string(old) == string(data) {
+		s.mu.Unlock()
+		return nil
+	}
+	s.values[key] = data
+	return s.saveAndNotify(key)
+}
+
+// Delete removes the setting for key.
+func (s *Settings) Delete(key string) error {
+	s.mu.Lock()
+	if _, ok := s.values[key]; !ok {
+		s.mu.Unlock()
+		return nil
+	}
+	delete(s.values, key)
+	return s.saveAndNotify(key)
+}
+
+// saveAndNotify saves the settings and notifies watchers of the
+// change to key. It must be called with s.mu held, and releases it.
+func (s *Settings) saveAndNotify(key string) error {
+	err := s.save()
+	watchers := make([]func(string), 0, len(s.watchers))
+	for _, f := range s.watchers {
+		watchers = append(watchers, f)
+	}
+	s.mu.Unlock()
+	for _, f := range watchers {
+		f(key)
+	}
+	return err
+}
+
+// String returns the string setting for key, or def if not set.
+func (s *Settings) String(key, def string) string {
+	v := def
+	s.Get(key, &v)
+	return v
+}
+
+// Bool returns the boolean setting for key, or def if not set.
+func (s *Settings) Bool(key string, def bool) bool {
+	v := def
+	s.Get(key, &v)
+	return v
+}
+
+// Int returns the integer setting for key, or def if not set.
+func (s *Settings) Int(key string, def int) int {
+	v := def
+	s.Get(key, &v)
+	return v
+}
+
+// Float returns the floating point setting for key, or def if
+// not set.
+func (s *Settings) Float(key string, def float64) float64 {
+	v := def
+	s.Get(key, &v)
+	return v
+}
+
+// Watch registers f to be called with the key of every changed
+// setting. Calling the returned function unregisters f. Note that f
+// is called from the goroutine that changed the setting; use
+// Window.Invalidate to refresh the user interface.
+func (s *Settings) Watch(f func(key string)) (cancel func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := s.nextID
+	s.nextID++
+	s.watchers[id] = f
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		delete(s.watchers, id)
+	}
+}
+
+// Geometry returns a Geometry that persists the geometry of a window
+// in the settings under name.
+func (s *Settings) Geometry(name string) *Geometry {
+	return NewGeometry(s, name)
+}
+
+// LoadGeometry implements GeometryStore.
+func (s *Settings) LoadGeometry(name string) (WindowGeometry, bool) {
+	var g WindowGeometry
+	ok := s.Get(settingGeometry+name, &g)
+	return g, ok
+}
+
+// SaveGeometry implements GeometryStore.
+func (s *Settings) SaveGeometry(name string, g WindowGeometry) error {
+	return s.Set(settingGeometry+name, g)
+}
+
+// save writes the settings to disk. It must be called with s.mu held.
+func (s *Settings) save() error {
+	data, err := json.MarshalIndent(s.values, "", "\t")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return err
+	}
+	// Write to a temporary file and rename it in place to avoid
+	// corrupting the settings if the program is interrupted.
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
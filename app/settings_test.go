@@ -0,0 +1,108 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package app
+
+import (
+	"image"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/Seikaijyu/gio/unit"
+)
+
+func TestSettings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app", "settings.json")
+	s, err := openSettings(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := s.String("name", "def"); got != "def" {
+		t.Errorf("missing setting is %q, want the default", got)
+	}
+	var changed []string
+	cancel := s.Watch(func(key string) { changed = append(changed, key) })
+	if err := s.Set("name", "gio"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Set("count", 3); err != nil {
+		t.Fatal(err)
+	}
+	// Setting an unchanged value neither saves nor notifies.
+	if err := s.Set("count", 3); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Set("enabled", true); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Delete("enabled"); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	if err := s.Set("ratio", 0.5); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"name", "count", "enabled", "enabled"}; !reflect.DeepEqual(changed, want) {
+		t.Errorf("notified %v, want %v", changed, want)
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Error("the temporary file of the atomic save was left behind")
+	}
+
+	// The settings are persisted.
+	s, err = openSettings(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := s.String("name", ""); got != "gio" {
+		t.Errorf("reloaded name %q, want %q", got, "gio")
+	}
+	if got := s.Int("count", 0); got != 3 {
+		t.Errorf("reloaded count %d, want 3", got)
+	}
+	if got := s.Bool("enabled", false); got {
+		t.Error("deleted setting was reloaded")
+	}
+	if got := s.Float("ratio", 0); got != 0.5 {
+		t.Errorf("reloaded ratio %v, want 0.5", got)
+	}
+	// Values of the wrong type are reported as missing.
+	if got := s.Int("name", 7); got != 7 {
+		t.Errorf("mistyped setting is %d, want the default", got)
+	}
+}
+
+func TestSettingsCorrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.json")
+	if err := os.WriteFile(path, []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := openSettings(path); err == nil {
+		t.Error("corrupt settings loaded without error")
+	}
+}
+
+func TestSettingsGeometry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.json")
+	s, err := openSettings(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := unit.Metric{PxPerDp: 1}
+	if err := s.Geometry("main").Update(Config{Size: image.Pt(640, 480), Position: image.Pt(10, 20), metric: m}); err != nil {
+		t.Fatal(err)
+	}
+	s, err = openSettings(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cnf Config
+	cnf.apply(m, s.Geometry("main").Options())
+	if cnf.Size != image.Pt(640, 480) || cnf.Position != image.Pt(10, 20) {
+		t.Errorf("restored size %v and position %v, want (640,480) and (10,20)", cnf.Size, cnf.Position)
+	}
+	if opts := s.Geometry("other").Options(); len(opts) != 0 {
+		t.Errorf("%d options restored for an unsaved window", len(opts))
+	}
+}
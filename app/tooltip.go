@@ -0,0 +1,66 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package app
+
+import (
+	"image"
+
+	"github.com/Seikaijyu/gio/f32"
+	"github.com/Seikaijyu/gio/io/system"
+	"github.com/Seikaijyu/gio/layout"
+	"github.com/Seikaijyu/gio/op"
+	"github.com/Seikaijyu/gio/op/clip"
+	"github.com/Seikaijyu/gio/op/paint"
+	"github.com/Seikaijyu/gio/widget/material"
+)
+
+// layoutTooltip adds the tooltip, if any, for the help text given by
+// semantic.HelpOp of the component under the pointer, for the frame e
+// of a window of size to o.
+func (w *Window) layoutTooltip(e system.FrameEvent, size image.Point, o *op.Ops) {
+	t := &w.tooltips
+	if !t.enabled {
+		return
+	}
+	t.Help = t.style
+	if t.Help == nil {
+		t.Help = w.helpTooltip
+	}
+	gtx := layout.Context{
+		Ops:         o,
+		Now:         e.Now,
+		Metric:      e.Metric,
+		Constraints: layout.Exact(size),
+		Queue:       &w.queue,
+	}
+	t.Layout(gtx)
+}
+
+// helpTooltip lays out the default tooltip for a help text, in the
+// colors of the window decorations.
+func (w *Window) helpTooltip(gtx layout.Context, text string) layout.Dimensions {
+	th := w.decorations.Theme
+	lbl := material.Body2(th, text)
+	lbl.Color = th.Palette.Bg
+	inset := layout.Inset{Top: 4, Bottom: 4, Left: 8, Right: 8}
+	gtx.Constraints.Min = image.Point{}
+	if max := gtx.Dp(320); gtx.Constraints.Max.X > max {
+		gtx.Constraints.Max.X = max
+	}
+	return layout.Background{}.Layout(gtx,
+		func(gtx layout.Context) layout.Dimensions {
+			sz := gtx.Constraints.Min
+			r := gtx.Dp(4)
+			paint.FillShape(gtx.Ops, th.Palette.Fg, clip.UniformRRect(image.Rectangle{Max: sz}, r).Op(gtx.Ops))
+			return layout.Dimensions{Size: sz}
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return inset.Layout(gtx, lbl.Layout)
+		},
+	)
+}
+
+// HelpAt implements the help text lookup of widget.Tooltips.
+func (q *queue) HelpAt(pos f32.Point) (string, image.Rectangle, bool) {
+	return q.q.HelpAt(pos)
+}
@@ -0,0 +1,897 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package app
+
+import (
+	"math"
+	"runtime"
+	"strings"
+	"sync"
+	"unicode/utf8"
+	"unsafe"
+
+	syscall "golang.org/x/sys/windows"
+
+	"github.com/Seikaijyu/gio/app/internal/windows"
+	"github.com/Seikaijyu/gio/f32"
+	"github.com/Seikaijyu/gio/io/key"
+	"github.com/Seikaijyu/gio/io/router"
+	"github.com/Seikaijyu/gio/io/semantic"
+)
+
+// uiaProvider exposes the semantic tree of a window to UI Automation
+// clients such as Narrator.
+//
+// UI Automation calls providers on its own threads, while the semantic
+// tree may only be accessed from the window thread. Provider methods
+// therefore forward their work to the window thread through run.
+type uiaProvider struct {
+	w *window
+	// tid is the id of the window thread.
+	tid uint32
+
+	mu   sync.Mutex
+	hwnd syscall.Handle
+	// root is the fragment root, representing the window itself.
+	root *uiaElement
+	// elems contains the elements referenced by UI Automation.
+	elems map[router.SemanticID]*uiaElement
+
+	// callMu serializes calls to the window thread.
+	callMu sync.Mutex
+	call   func()
+
+	// The remaining fields are only accessed on the window thread.
+	rootID router.SemanticID
+	focus  router.SemanticID
+	diffs  []router.SemanticID
+}
+
+// uiaElement is the provider for a semantic node. The zero id denotes
+// the semantic root.
+type uiaElement struct {
+	// The COM interfaces implemented by the element.
+	simple   uiaIface
+	fragment uiaIface
+	fragRoot uiaIface
+	invoke   uiaIface
+	toggle   uiaIface
+	value    uiaIface
+	selItem  uiaIface
+
+	p    *uiaProvider
+	id   router.SemanticID
+	refs int32
+	// desc is the description last reported to clients. It is only
+	// accessed on the window thread.
+	desc router.SemanticDesc
+}
+
+// uiaIface is the layout of a COM interface: a pointer to its method
+// table, followed by the implementing element.
+type uiaIface struct {
+	vtbl *uintptr
+	elem *uiaElement
+}
+
+var uiaVtbls struct {
+	once     sync.Once
+	simple   []uintptr
+	fragment []uintptr
+	fragRoot []uintptr
+	invoke   []uintptr
+	toggle   []uintptr
+	value    []uintptr
+	selItem  []uintptr
+}
+
+func newUIAProvider(w *window) *uiaProvider {
+	p := &uiaProvider{
+		w:     w,
+		tid:   syscall.GetCurrentThreadId(),
+		hwnd:  w.hwnd,
+		elems: make(map[router.SemanticID]*uiaElement),
+	}
+	p.root = p.newElement(0)
+	return p
+}
+
+func (p *uiaProvider) newElement(id router.SemanticID) *uiaElement {
+	uiaVtbls.once.Do(initUIAVtbls)
+	e := &uiaElement{p: p, id: id}
+	ifaces := []struct {
+		iface *uiaIface
+		vtbl  []uintptr
+	}{
+		{&e.simple, uiaVtbls.simple},
+		{&e.fragment, uiaVtbls.fragment},
+		{&e.fragRoot, uiaVtbls.fragRoot},
+		{&e.invoke, uiaVtbls.invoke},
+		{&e.toggle, uiaVtbls.toggle},
+		{&e.value, uiaVtbls.value},
+		{&e.selItem, uiaVtbls.selItem},
+	}
+	for _, i := range ifaces {
+		i.iface.vtbl = &i.vtbl[0]
+		i.iface.elem = e
+	}
+	return e
+}
+
+func initUIAVtbls() {
+	unknown := []uintptr{
+		syscall.NewCallback(uiaQueryInterface),
+		syscall.NewCallback(uiaAddRef),
+		syscall.NewCallback(uiaRelease),
+	}
+	vtbl := func(methods ...interface{}) []uintptr {
+		v := append([]uintptr{}, unknown...)
+		for _, m := range methods {
+			v = append(v, syscall.NewCallback(m))
+		}
+		return v
+	}
+	// ElementProviderFromPoint takes two doubles, which callbacks can
+	// only receive on 386. Elsewhere, the coordinates are passed in
+	// floating point registers and the cursor position is used instead.
+	var fromPoint interface{}
+	switch runtime.GOARCH {
+	case "386":
+		fromPoint = func(this *uiaIface, xlo, xhi, ylo, yhi uintptr, out **uiaIface) uintptr {
+			x := math.Float64frombits(uint64(xlo) | uint64(xhi)<<32)
+			y := math.Float64frombits(uint64(ylo) | uint64(yhi)<<32)
+			return this.elem.elementFromPoint(windows.Point{X: int32(x), Y: int32(y)}, out)
+		}
+	case "amd64":
+		fromPoint = func(this *uiaIface, _, _ uintptr, out **uiaIface) uintptr {
+			return this.elem.elementFromCursor(out)
+		}
+	default:
+		fromPoint = func(this *uiaIface, out **uiaIface) uintptr {
+			return this.elem.elementFromCursor(out)
+		}
+	}
+	uiaVtbls.simple = vtbl(
+		uiaGetProviderOptions,
+		uiaGetPatternProvider,
+		uiaGetPropertyValue,
+		uiaGetHostRawElementProvider,
+	)
+	uiaVtbls.fragment = vtbl(
+		uiaNavigate,
+		uiaGetRuntimeId,
+		uiaGetBoundingRectangle,
+		uiaGetEmbeddedFragmentRoots,
+		uiaSetFocus,
+		uiaGetFragmentRoot,
+	)
+	uiaVtbls.fragRoot = vtbl(
+		fromPoint,
+		uiaGetFocus,
+	)
+	uiaVtbls.invoke = vtbl(
+		uiaInvoke,
+	)
+	uiaVtbls.toggle = vtbl(
+		uiaToggle,
+		uiaGetToggleState,
+	)
+	uiaVtbls.value = vtbl(
+		uiaSetValue,
+		uiaGetValue,
+		uiaGetIsReadOnly,
+	)
+	uiaVtbls.selItem = vtbl(
+		uiaSelect,
+		uiaSelect,
+		uiaRemoveFromSelection,
+		uiaGetIsSelected,
+		uiaGetSelectionContainer,
+	)
+}
+
+// getObject handles WM_GETOBJECT.
+func (p *uiaProvider) getObject(wParam, lParam uintptr) uintptr {
+	return windows.UiaReturnRawElementProvider(p.hwnd, wParam, lParam, unsafe.Pointer(&p.root.simple))
+}
+
+// close disconnects the provider from the destroyed window.
+func (p *uiaProvider) close() {
+	windows.UiaReturnRawElementProvider(p.hwnd, 0, 0, nil)
+	p.mu.Lock()
+	p.hwnd = 0
+	p.mu.Unlock()
+}
+
+// run calls f on the window thread and reports whether it did. It
+// fails if the window is destroyed.
+func (p *uiaProvider) run(f func()) bool {
+	p.mu.Lock()
+	hwnd := p.hwnd
+	p.mu.Unlock()
+	if hwnd == 0 {
+		return false
+	}
+	if syscall.GetCurrentThreadId() == p.tid {
+		f()
+		return true
+	}
+	p.callMu.Lock()
+	defer p.callMu.Unlock()
+	ran := false
+	p.call = func() {
+		f()
+		ran = true
+	}
+	windows.SendMessage(hwnd, _WM_UIA, 0, 0)
+	p.call = nil
+	return ran
+}
+
+// runCall runs the pending call from run. It is called by the window
+// procedure.
+func (p *uiaProvider) runCall() {
+	if f := p.call; f != nil {
+		f()
+	}
+}
+
+// acquire returns the element for the semantic node id with an added
+// reference. Must be called on the window thread.
+func (p *uiaProvider) acquire(id router.SemanticID) *uiaElement {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	e := p.root
+	if id != 0 {
+		var ok bool
+		e, ok = p.elems[id]
+		if !ok {
+			e = p.newElement(id)
+			if n, ok := p.w.w.LookupSemantic(id); ok {
+				e.desc = n.Desc
+			}
+			p.elems[id] = e
+		}
+	}
+	e.refs++
+	return e
+}
+
+func (p *uiaProvider) addRef(e *uiaElement) int32 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	e.refs++
+	return e.refs
+}
+
+func (p *uiaProvider) release(e *uiaElement) int32 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	e.refs--
+	if e.refs == 0 && e != p.root {
+		delete(p.elems, e.id)
+	}
+	return e.refs
+}
+
+// lookup returns the element for id without adding a reference, if it
+// is referenced by UI Automation.
+func (p *uiaProvider) lookup(id router.SemanticID) (*uiaElement, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	e, ok := p.elems[id]
+	return e, ok
+}
+
+// elementID maps a semantic id to an element id. Must be called on the
+// window thread.
+func (p *uiaProvider) elementID(id router.SemanticID) router.SemanticID {
+	if id == p.w.w.SemanticRoot() {
+		return 0
+	}
+	return id
+}
+
+// update reports changes to the semantic tree after a frame. Must be
+// called on the window thread.
+func (p *uiaProvider) update() {
+	if !windows.UiaClientsAreListening() {
+		return
+	}
+	c := p.w.w
+	if r := c.SemanticRoot(); r != p.rootID {
+		p.rootID = r
+		p.structureChanged(p.root)
+	}
+	p.diffs = c.AppendSemanticDiffs(p.diffs[:0])
+	for _, id := range p.diffs {
+		if p.elementID(id) == 0 {
+			p.structureChanged(p.root)
+			continue
+		}
+		// Only report changes to elements known to clients.
+		e, ok := p.lookup(id)
+		if !ok {
+			continue
+		}
+		p.structureChanged(e)
+		if n, ok := c.LookupSemantic(id); ok {
+			e.propertiesChanged(n.Desc)
+		}
+	}
+	focus, ok := c.SemanticFocus()
+	if !ok || !p.w.focused {
+		focus = 0
+	}
+	focus = p.elementID(focus)
+	if focus != p.focus {
+		p.focus = focus
+		if focus != 0 {
+			e := p.acquire(focus)
+			windows.UiaRaiseAutomationEvent(unsafe.Pointer(&e.simple), windows.UIA_AutomationFocusChangedEventId)
+			p.release(e)
+		}
+	}
+}
+
+func (p *uiaProvider) structureChanged(e *uiaElement) {
+	var id []int32
+	if e.id != 0 {
+		// The window's runtime id is provided by the host provider.
+		id = []int32{windows.UiaAppendRuntimeId, int32(e.id)}
+	}
+	windows.UiaRaiseStructureChangedEvent(unsafe.Pointer(&e.simple), windows.StructureChangeType_ChildrenInvalidated, id)
+}
+
+// propertiesChanged reports changes in properties from the previously
+// reported description.
+func (e *uiaElement) propertiesChanged(d router.SemanticDesc) {
+	old := e.desc
+	e.desc = d
+	raise := func(prop int32, old, new windows.Variant) {
+		windows.UiaRaiseAutomationPropertyChangedEvent(unsafe.Pointer(&e.simple), prop, old, new)
+	}
+	if o, n := uiaName(old), uiaName(d); o != n {
+		raise(windows.UIA_NamePropertyId, uiaString(o), uiaString(n))
+	}
+	if old.Disabled != d.Disabled {
+		raise(windows.UIA_IsEnabledPropertyId, uiaBool(!old.Disabled), uiaBool(!d.Disabled))
+	}
+	if old.Selected != d.Selected {
+		switch d.Class {
+		case semantic.CheckBox, semantic.Switch:
+			raise(windows.UIA_ToggleToggleStatePropertyId, uiaToggleState(old), uiaToggleState(d))
+		case semantic.RadioButton:
+			raise(windows.UIA_SelectionItemIsSelectedPropertyId, uiaBool(old.Selected), uiaBool(d.Selected))
+		}
+	}
+	if old.Value != d.Value && d.Class == semantic.Editor {
+		raise(windows.UIA_ValueValuePropertyId, uiaString(old.Value), uiaString(d.Value))
+	}
+}
+
+// with calls f with the element's semantic node on the window thread.
+// It returns UIA_E_ELEMENTNOTAVAILABLE if the node no longer exists.
+func (e *uiaElement) with(f func(n router.SemanticNode) uintptr) uintptr {
+	res := uintptr(windows.UIA_E_ELEMENTNOTAVAILABLE)
+	e.p.run(func() {
+		c := e.p.w.w
+		id := e.id
+		if id == 0 {
+			id = c.SemanticRoot()
+		}
+		if n, ok := c.LookupSemantic(id); ok {
+			res = f(n)
+		}
+	})
+	return res
+}
+
+// out stores the element for the semantic node id in *out, or nil for
+// the zero id.
+func (p *uiaProvider) out(id router.SemanticID, iface func(e *uiaElement) *uiaIface, out **uiaIface) {
+	*out = nil
+	if id != 0 {
+		*out = iface(p.acquire(id))
+	}
+}
+
+func uiaFragment(e *uiaElement) *uiaIface { return &e.fragment }
+
+func (e *uiaElement) elementFromCursor(out **uiaIface) uintptr {
+	pt, err := windows.GetCursorPos()
+	if err != nil {
+		return windows.E_INVALIDARG
+	}
+	return e.elementFromPoint(pt, out)
+}
+
+func (e *uiaElement) elementFromPoint(pt windows.Point, out **uiaIface) uintptr {
+	if out == nil {
+		return windows.E_POINTER
+	}
+	*out = nil
+	return e.with(func(n router.SemanticNode) uintptr {
+		windows.ScreenToClient(e.p.hwnd, &pt)
+		if id, ok := e.p.w.w.SemanticAt(f32.Pt(float32(pt.X), float32(pt.Y))); ok {
+			e.p.out(e.p.elementID(id), uiaFragment, out)
+		}
+		return windows.S_OK
+	})
+}
+
+func uiaQueryInterface(this *uiaIface, riid *windows.GUID, out **uiaIface) uintptr {
+	if out == nil {
+		return windows.E_POINTER
+	}
+	e := this.elem
+	var iface *uiaIface
+	switch *riid {
+	case windows.IID_IUnknown, windows.IID_IRawElementProviderSimple:
+		iface = &e.simple
+	case windows.IID_IRawElementProviderFragment:
+		iface = &e.fragment
+	case windows.IID_IRawElementProviderFragmentRoot:
+		if e.id == 0 {
+			iface = &e.fragRoot
+		}
+	case windows.IID_IInvokeProvider:
+		iface = &e.invoke
+	case windows.IID_IToggleProvider:
+		iface = &e.toggle
+	case windows.IID_IValueProvider:
+		iface = &e.value
+	case windows.IID_ISelectionItemProvider:
+		iface = &e.selItem
+	}
+	*out = iface
+	if iface == nil {
+		return windows.E_NOINTERFACE
+	}
+	e.p.addRef(e)
+	return windows.S_OK
+}
+
+func uiaAddRef(this *uiaIface) uintptr {
+	e := this.elem
+	return uintptr(e.p.addRef(e))
+}
+
+func uiaRelease(this *uiaIface) uintptr {
+	e := this.elem
+	return uintptr(e.p.release(e))
+}
+
+func uiaGetProviderOptions(this *uiaIface, out *int32) uintptr {
+	if out == nil {
+		return windows.E_POINTER
+	}
+	*out = windows.ProviderOptions_ServerSideProvider
+	return windows.S_OK
+}
+
+func uiaGetPatternProvider(this *uiaIface, pattern uintptr, out **uiaIface) uintptr {
+	if out == nil {
+		return windows.E_POINTER
+	}
+	*out = nil
+	e := this.elem
+	return e.with(func(n router.SemanticNode) uintptr {
+		d := n.Desc
+		var iface *uiaIface
+		switch int32(pattern) {
+		case windows.UIA_InvokePatternId:
+			if uiaInvokable(d) {
+				iface = &e.invoke
+			}
+		case windows.UIA_TogglePatternId:
+			if d.Class == semantic.CheckBox || d.Class == semantic.Switch {
+				iface = &e.toggle
+			}
+		case windows.UIA_SelectionItemPatternId:
+			if d.Class == semantic.RadioButton {
+				iface = &e.selItem
+			}
+		case windows.UIA_ValuePatternId:
+			if d.Class == semantic.Editor {
+				iface = &e.value
+			}
+		}
+		if iface != nil {
+			e.p.addRef(e)
+			*out = iface
+		}
+		return windows.S_OK
+	})
+}
+
+func uiaGetPropertyValue(this *uiaIface, prop uintptr, out *windows.Variant) uintptr {
+	if out == nil {
+		return windows.E_POINTER
+	}
+	*out = windows.Variant{}
+	e := this.elem
+	return e.with(func(n router.SemanticNode) uintptr {
+		if e.id == 0 {
+			// The host provider describes the window.
+			if int32(prop) == windows.UIA_FrameworkIdPropertyId {
+				*out = uiaString("Gio")
+			}
+			return windows.S_OK
+		}
+		d := n.Desc
+		switch int32(prop) {
+		case windows.UIA_ControlTypePropertyId:
+			*out = windows.Variant{VT: windows.VT_I4, Val: uint64(uiaControlType(d))}
+		case windows.UIA_NamePropertyId:
+			if name := uiaName(d); name != "" {
+				*out = uiaString(name)
+			} else if uiaInvokable(d) {
+				// Label controls by the labels of their descendants.
+				if l := uiaLabels(n.Children); l != "" {
+					*out = uiaString(l)
+				}
+			}
+		case windows.UIA_HelpTextPropertyId:
+			if d.Help != "" {
+				*out = uiaString(d.Help)
+			}
+		case windows.UIA_IsEnabledPropertyId:
+			*out = uiaBool(!d.Disabled)
+		case windows.UIA_IsKeyboardFocusablePropertyId:
+			*out = uiaBool(d.Class == semantic.Editor || d.Gestures&router.ClickGesture != 0)
+		case windows.UIA_HasKeyboardFocusPropertyId:
+			focus, ok := e.p.w.w.SemanticFocus()
+			*out = uiaBool(ok && e.p.w.focused && focus == n.ID)
+		case windows.UIA_FrameworkIdPropertyId:
+			*out = uiaString("Gio")
+		}
+		return windows.S_OK
+	})
+}
+
+func uiaGetHostRawElementProvider(this *uiaIface, out *uintptr) uintptr {
+	if out == nil {
+		return windows.E_POINTER
+	}
+	*out = 0
+	e := this.elem
+	if e.id != 0 {
+		return windows.S_OK
+	}
+	e.p.mu.Lock()
+	hwnd := e.p.hwnd
+	e.p.mu.Unlock()
+	if hwnd == 0 {
+		return windows.UIA_E_ELEMENTNOTAVAILABLE
+	}
+	if err := windows.UiaHostProviderFromHwnd(hwnd, out); err != nil {
+		return windows.UIA_E_ELEMENTNOTAVAILABLE
+	}
+	return windows.S_OK
+}
+
+func uiaNavigate(this *uiaIface, dir uintptr, out **uiaIface) uintptr {
+	if out == nil {
+		return windows.E_POINTER
+	}
+	*out = nil
+	e := this.elem
+	return e.with(func(n router.SemanticNode) uintptr {
+		p := e.p
+		c := p.w.w
+		switch int32(dir) {
+		case windows.NavigateDirection_Parent:
+			if e.id != 0 {
+				// The root's parent is the host window, which is
+				// navigated by UI Automation itself.
+				parent := p.elementID(n.ParentID)
+				if parent == 0 {
+					*out = &p.root.fragment
+					p.addRef(p.root)
+				} else {
+					p.out(parent, uiaFragment, out)
+				}
+			}
+		case windows.NavigateDirection_FirstChild:
+			if len(n.Children) > 0 {
+				p.out(n.Children[0].ID, uiaFragment, out)
+			}
+		case windows.NavigateDirection_LastChild:
+			if len(n.Children) > 0 {
+				p.out(n.Children[len(n.Children)-1].ID, uiaFragment, out)
+			}
+		case windows.NavigateDirection_NextSibling, windows.NavigateDirection_PreviousSibling:
+			if e.id == 0 {
+				break
+			}
+			parent, ok := c.LookupSemantic(n.ParentID)
+			if !ok {
+				break
+			}
+			for i, ch := range parent.Children {
+				if ch.ID != n.ID {
+					continue
+				}
+				if int32(dir) == windows.NavigateDirection_NextSibling {
+					i++
+				} else {
+					i--
+				}
+				if i >= 0 && i < len(parent.Children) {
+					p.out(parent.Children[i].ID, uiaFragment, out)
+				}
+				break
+			}
+		}
+		return windows.S_OK
+	})
+}
+
+func uiaGetRuntimeId(this *uiaIface, out *uintptr) uintptr {
+	if out == nil {
+		return windows.E_POINTER
+	}
+	*out = 0
+	if e := this.elem; e.id != 0 {
+		// The runtime id of the root is provided by the host provider.
+		*out = windows.NewRuntimeId(int32(e.id))
+	}
+	return windows.S_OK
+}
+
+func uiaGetBoundingRectangle(this *uiaIface, out *windows.UiaRect) uintptr {
+	if out == nil {
+		return windows.E_POINTER
+	}
+	*out = windows.UiaRect{}
+	e := this.elem
+	if e.id == 0 {
+		// The host provider reports the window bounds.
+		return windows.S_OK
+	}
+	return e.with(func(n router.SemanticNode) uintptr {
+		b := n.Desc.Bounds
+		pt := windows.Point{X: int32(b.Min.X), Y: int32(b.Min.Y)}
+		windows.ClientToScreen(e.p.hwnd, &pt)
+		*out = windows.UiaRect{
+			Left:   float64(pt.X),
+			Top:    float64(pt.Y),
+			Width:  float64(b.Dx()),
+			Height: float64(b.Dy()),
+		}
+		return windows.S_OK
+	})
+}
+
+func uiaGetEmbeddedFragmentRoots(this *uiaIface, out *uintptr) uintptr {
+	if out == nil {
+		return windows.E_POINTER
+	}
+	*out = 0
+	return windows.S_OK
+}
+
+func uiaSetFocus(this *uiaIface) uintptr {
+	e := this.elem
+	return e.with(func(n router.SemanticNode) uintptr {
+		if e.id != 0 {
+			e.p.w.w.FocusSemantic(n.ID)
+		}
+		return windows.S_OK
+	})
+}
+
+func uiaGetFragmentRoot(this *uiaIface, out **uiaIface) uintptr {
+	if out == nil {
+		return windows.E_POINTER
+	}
+	p := this.elem.p
+	p.addRef(p.root)
+	*out = &p.root.fragRoot
+	return windows.S_OK
+}
+
+func uiaGetFocus(this *uiaIface, out **uiaIface) uintptr {
+	if out == nil {
+		return windows.E_POINTER
+	}
+	*out = nil
+	e := this.elem
+	return e.with(func(n router.SemanticNode) uintptr {
+		if id, ok := e.p.w.w.SemanticFocus(); ok {
+			e.p.out(e.p.elementID(id), uiaFragment, out)
+		}
+		return windows.S_OK
+	})
+}
+
+// uiaClick clicks the element on behalf of the Invoke, Toggle and
+// SelectionItem patterns.
+func uiaClick(this *uiaIface) uintptr {
+	e := this.elem
+	return e.with(func(n router.SemanticNode) uintptr {
+		if n.Desc.Disabled {
+			return windows.UIA_E_ELEMENTNOTENABLED
+		}
+		e.p.w.w.ClickSemantic(n.ID)
+		return windows.S_OK
+	})
+}
+
+func uiaInvoke(this *uiaIface) uintptr {
+	return uiaClick(this)
+}
+
+func uiaToggle(this *uiaIface) uintptr {
+	return uiaClick(this)
+}
+
+func uiaGetToggleState(this *uiaIface, out *int32) uintptr {
+	if out == nil {
+		return windows.E_POINTER
+	}
+	return this.elem.with(func(n router.SemanticNode) uintptr {
+		*out = int32(uiaToggleState(n.Desc).Val)
+		return windows.S_OK
+	})
+}
+
+func uiaSelect(this *uiaIface) uintptr {
+	e := this.elem
+	return e.with(func(n router.SemanticNode) uintptr {
+		if n.Desc.Disabled {
+			return windows.UIA_E_ELEMENTNOTENABLED
+		}
+		if !n.Desc.Selected {
+			e.p.w.w.ClickSemantic(n.ID)
+		}
+		return windows.S_OK
+	})
+}
+
+func uiaRemoveFromSelection(this *uiaIface) uintptr {
+	// Radio buttons can't be deselected.
+	return windows.UIA_E_INVALIDOPERATION
+}
+
+func uiaGetIsSelected(this *uiaIface, out *int32) uintptr {
+	if out == nil {
+		return windows.E_POINTER
+	}
+	return this.elem.with(func(n router.SemanticNode) uintptr {
+		*out = 0
+		if n.Desc.Selected {
+			*out = 1
+		}
+		return windows.S_OK
+	})
+}
+
+func uiaGetSelectionContainer(this *uiaIface, out **uiaIface) uintptr {
+	if out == nil {
+		return windows.E_POINTER
+	}
+	*out = nil
+	return windows.S_OK
+}
+
+// uiaSetValue replaces the content of an editor by focusing it and
+// editing it like an input method would.
+func uiaSetValue(this *uiaIface, val *uint16) uintptr {
+	if val == nil {
+		return windows.E_POINTER
+	}
+	text := syscall.UTF16PtrToString(val)
+	return this.elem.with(func(n router.SemanticNode) uintptr {
+		if n.Desc.Disabled {
+			return windows.UIA_E_ELEMENTNOTENABLED
+		}
+		c := this.elem.p.w.w
+		if focus, ok := c.SemanticFocus(); !ok || focus != n.ID {
+			if !c.FocusSemantic(n.ID) {
+				return windows.UIA_E_INVALIDOPERATION
+			}
+		}
+		c.EditorReplace(key.Range{Start: 0, End: utf8.RuneCountInString(n.Desc.Value)}, text)
+		return windows.S_OK
+	})
+}
+
+func uiaGetValue(this *uiaIface, out *uintptr) uintptr {
+	if out == nil {
+		return windows.E_POINTER
+	}
+	*out = 0
+	return this.elem.with(func(n router.SemanticNode) uintptr {
+		*out = windows.SysAllocString(n.Desc.Value)
+		return windows.S_OK
+	})
+}
+
+func uiaGetIsReadOnly(this *uiaIface, out *int32) uintptr {
+	if out == nil {
+		return windows.E_POINTER
+	}
+	return this.elem.with(func(n router.SemanticNode) uintptr {
+		*out = 0
+		if n.Desc.Disabled {
+			*out = 1
+		}
+		return windows.S_OK
+	})
+}
+
+// uiaInvokable reports whether a node is clicked through the Invoke
+// pattern. Check boxes, switches and radio buttons are clicked through
+// their Toggle and SelectionItem patterns.
+func uiaInvokable(d router.SemanticDesc) bool {
+	switch d.Class {
+	case semantic.CheckBox, semantic.Switch, semantic.RadioButton:
+		return false
+	}
+	return d.Gestures&router.ClickGesture != 0
+}
+
+func uiaControlType(d router.SemanticDesc) int32 {
+	switch d.Class {
+	case semantic.Button, semantic.Switch:
+		return windows.UIA_ButtonControlTypeId
+	case semantic.CheckBox:
+		return windows.UIA_CheckBoxControlTypeId
+	case semantic.Editor:
+		return windows.UIA_EditControlTypeId
+	case semantic.RadioButton:
+		return windows.UIA_RadioButtonControlTypeId
+	}
+	switch {
+	case d.Gestures&router.ClickGesture != 0:
+		return windows.UIA_ButtonControlTypeId
+	case d.Label != "":
+		return windows.UIA_TextControlTypeId
+	}
+	return windows.UIA_GroupControlTypeId
+}
+
+// uiaName returns the name of a node. The content of editors is
+// reported through the Value pattern.
+func uiaName(d router.SemanticDesc) string {
+	if d.Description != "" || d.Class == semantic.Editor {
+		return d.Description
+	}
+	return d.Label
+}
+
+// uiaLabels joins the labels of nodes and their descendants.
+func uiaLabels(nodes []router.SemanticNode) string {
+	var labels []string
+	for _, n := range nodes {
+		if l := n.Desc.Label; l != "" {
+			labels = append(labels, l)
+		} else if l := uiaLabels(n.Children); l != "" {
+			labels = append(labels, l)
+		}
+	}
+	return strings.Join(labels, " ")
+}
+
+func uiaToggleState(d router.SemanticDesc) windows.Variant {
+	v := windows.Variant{VT: windows.VT_I4, Val: windows.ToggleState_Off}
+	if d.Selected {
+		v.Val = windows.ToggleState_On
+	}
+	return v
+}
+
+func uiaBool(b bool) windows.Variant {
+	v := windows.Variant{VT: windows.VT_BOOL, Val: windows.VARIANT_FALSE}
+	if b {
+		v.Val = windows.VARIANT_TRUE
+	}
+	return v
+}
+
+// uiaString returns a variant holding a newly allocated copy of s.
+func uiaString(s string) windows.Variant {
+	return windows.Variant{VT: windows.VT_BSTR, Val: uint64(windows.SysAllocString(s))}
+}
@@ -0,0 +1,55 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+/*
+Package unsafeinterop exposes the native handles and GPU resources of
+app.Window windows, for integrating native SDKs such as for video, VR or
+screen capture.
+
+The handles are owned by package app. They are valid until the next
+ViewEvent of the window, and must only be used on the thread the
+platform requires, typically inside Window.Run. Releasing them or
+changing state Gio depends on, such as the pixel format of a surface or
+the current OpenGL context, is undefined behavior. Nothing in this
+package is covered by the compatibility promises of the rest of Gio.
+*/
+package unsafeinterop
+
+import (
+	"github.com/Seikaijyu/gio/app"
+	"github.com/Seikaijyu/gio/app/internal/native"
+	"github.com/Seikaijyu/gio/gpu"
+)
+
+// Platform is a windowing system.
+type Platform = native.Platform
+
+// Handles are the native handles of a window. Only the fields of its
+// Platform are set.
+type Handles = native.Handles
+
+const (
+	Unknown = native.Unknown
+	Windows = native.Windows
+	MacOS   = native.MacOS
+	IOS     = native.IOS
+	X11     = native.X11
+	Wayland = native.Wayland
+	Android = native.Android
+)
+
+// WindowHandles returns the native handles of w as of its most recent
+// ViewEvent. The handles are zero, with an Unknown Platform, while w has
+// no native view.
+func WindowHandles(w *app.Window) Handles {
+	h, _ := native.Lookup(w)
+	return h
+}
+
+// GPU returns the GPU API, such as a gpu.Direct3D11 with its device,
+// the window renders with. It returns nil before the first frame of
+// the window, after the loss of its GPU device, and for windows with a
+// custom renderer.
+func GPU(w *app.Window) gpu.API {
+	_, api := native.Lookup(w)
+	return api
+}
@@ -0,0 +1,286 @@
+/* Generated by wayland-scanner 1.19.0 */
+
+#ifndef XDG_FOREIGN_UNSTABLE_V2_CLIENT_PROTOCOL_H
+#define XDG_FOREIGN_UNSTABLE_V2_CLIENT_PROTOCOL_H
+
+#include <stdint.h>
+#include <stddef.h>
+#include "wayland-client.h"
+
+#ifdef  __cplusplus
+extern "C" {
+#endif
+
+struct wl_surface;
+struct zxdg_exported_v2;
+struct zxdg_exporter_v2;
+struct zxdg_imported_v2;
+struct zxdg_importer_v2;
+
+#ifndef ZXDG_EXPORTER_V2_INTERFACE
+#define ZXDG_EXPORTER_V2_INTERFACE
+extern const struct wl_interface zxdg_exporter_v2_interface;
+#endif
+#ifndef ZXDG_IMPORTER_V2_INTERFACE
+#define ZXDG_IMPORTER_V2_INTERFACE
+extern const struct wl_interface zxdg_importer_v2_interface;
+#endif
+#ifndef ZXDG_EXPORTED_V2_INTERFACE
+#define ZXDG_EXPORTED_V2_INTERFACE
+extern const struct wl_interface zxdg_exported_v2_interface;
+#endif
+#ifndef ZXDG_IMPORTED_V2_INTERFACE
+#define ZXDG_IMPORTED_V2_INTERFACE
+extern const struct wl_interface zxdg_imported_v2_interface;
+#endif
+
+#ifndef ZXDG_EXPORTER_V2_ERROR_ENUM
+#define ZXDG_EXPORTER_V2_ERROR_ENUM
+/**
+ * @ingroup iface_zxdg_exporter_v2
+ * error values
+ *
+ * These errors can be emitted in response to invalid xdg_exporter
+ * requests.
+ */
+enum zxdg_exporter_v2_error {
+	/**
+	 * surface is not an xdg_toplevel
+	 */
+	ZXDG_EXPORTER_V2_ERROR_INVALID_SURFACE = 0,
+};
+#endif /* ZXDG_EXPORTER_V2_ERROR_ENUM */
+
+#define ZXDG_EXPORTER_V2_DESTROY 0
+#define ZXDG_EXPORTER_V2_EXPORT_TOPLEVEL 1
+
+
+#define ZXDG_EXPORTER_V2_DESTROY_SINCE_VERSION 1
+#define ZXDG_EXPORTER_V2_EXPORT_TOPLEVEL_SINCE_VERSION 1
+
+static inline void
+zxdg_exporter_v2_set_user_data(struct zxdg_exporter_v2 *zxdg_exporter_v2, void *user_data)
+{
+	wl_proxy_set_user_data((struct wl_proxy *) zxdg_exporter_v2, user_data);
+}
+
+static inline void *
+zxdg_exporter_v2_get_user_data(struct zxdg_exporter_v2 *zxdg_exporter_v2)
+{
+	return wl_proxy_get_user_data((struct wl_proxy *) zxdg_exporter_v2);
+}
+
+static inline uint32_t
+zxdg_exporter_v2_get_version(struct zxdg_exporter_v2 *zxdg_exporter_v2)
+{
+	return wl_proxy_get_version((struct wl_proxy *) zxdg_exporter_v2);
+}
+
+static inline void
+zxdg_exporter_v2_destroy(struct zxdg_exporter_v2 *zxdg_exporter_v2)
+{
+	wl_proxy_marshal((struct wl_proxy *) zxdg_exporter_v2,
+			 ZXDG_EXPORTER_V2_DESTROY);
+
+	wl_proxy_destroy((struct wl_proxy *) zxdg_exporter_v2);
+}
+
+static inline struct zxdg_exported_v2 *
+zxdg_exporter_v2_export_toplevel(struct zxdg_exporter_v2 *zxdg_exporter_v2, struct wl_surface *surface)
+{
+	struct wl_proxy *id;
+
+	id = wl_proxy_marshal_constructor((struct wl_proxy *) zxdg_exporter_v2,
+			 ZXDG_EXPORTER_V2_EXPORT_TOPLEVEL, &zxdg_exported_v2_interface, NULL, surface);
+
+	return (struct zxdg_exported_v2 *) id;
+}
+
+#define ZXDG_IMPORTER_V2_DESTROY 0
+#define ZXDG_IMPORTER_V2_IMPORT_TOPLEVEL 1
+
+
+#define ZXDG_IMPORTER_V2_DESTROY_SINCE_VERSION 1
+#define ZXDG_IMPORTER_V2_IMPORT_TOPLEVEL_SINCE_VERSION 1
+
+static inline void
+zxdg_importer_v2_set_user_data(struct zxdg_importer_v2 *zxdg_importer_v2, void *user_data)
+{
+	wl_proxy_set_user_data((struct wl_proxy *) zxdg_importer_v2, user_data);
+}
+
+static inline void *
+zxdg_importer_v2_get_user_data(struct zxdg_importer_v2 *zxdg_importer_v2)
+{
+	return wl_proxy_get_user_data((struct wl_proxy *) zxdg_importer_v2);
+}
+
+static inline uint32_t
+zxdg_importer_v2_get_version(struct zxdg_importer_v2 *zxdg_importer_v2)
+{
+	return wl_proxy_get_version((struct wl_proxy *) zxdg_importer_v2);
+}
+
+static inline void
+zxdg_importer_v2_destroy(struct zxdg_importer_v2 *zxdg_importer_v2)
+{
+	wl_proxy_marshal((struct wl_proxy *) zxdg_importer_v2,
+			 ZXDG_IMPORTER_V2_DESTROY);
+
+	wl_proxy_destroy((struct wl_proxy *) zxdg_importer_v2);
+}
+
+static inline struct zxdg_imported_v2 *
+zxdg_importer_v2_import_toplevel(struct zxdg_importer_v2 *zxdg_importer_v2, const char *handle)
+{
+	struct wl_proxy *id;
+
+	id = wl_proxy_marshal_constructor((struct wl_proxy *) zxdg_importer_v2,
+			 ZXDG_IMPORTER_V2_IMPORT_TOPLEVEL, &zxdg_imported_v2_interface, NULL, handle);
+
+	return (struct zxdg_imported_v2 *) id;
+}
+
+struct zxdg_exported_v2_listener {
+	/**
+	 * the exported surface handle
+	 *
+	 * The handle event contains the unique handle of this exported
+	 * surface reference. It may be shared with any client, which then
+	 * can use it to import the surface by calling
+	 * xdg_importer.import_toplevel. A handle may be used to import the
+	 * surface multiple times.
+	 * @param handle the exported surface handle
+	 */
+	void (*handle)(void *data,
+		       struct zxdg_exported_v2 *zxdg_exported_v2,
+		       const char *handle);
+};
+
+static inline int
+zxdg_exported_v2_add_listener(struct zxdg_exported_v2 *zxdg_exported_v2,
+			      const struct zxdg_exported_v2_listener *listener, void *data)
+{
+	return wl_proxy_add_listener((struct wl_proxy *) zxdg_exported_v2,
+				     (void (**)(void)) listener, data);
+}
+
+#define ZXDG_EXPORTED_V2_DESTROY 0
+
+#define ZXDG_EXPORTED_V2_HANDLE_SINCE_VERSION 1
+
+#define ZXDG_EXPORTED_V2_DESTROY_SINCE_VERSION 1
+
+static inline void
+zxdg_exported_v2_set_user_data(struct zxdg_exported_v2 *zxdg_exported_v2, void *user_data)
+{
+	wl_proxy_set_user_data((struct wl_proxy *) zxdg_exported_v2, user_data);
+}
+
+static inline void *
+zxdg_exported_v2_get_user_data(struct zxdg_exported_v2 *zxdg_exported_v2)
+{
+	return wl_proxy_get_user_data((struct wl_proxy *) zxdg_exported_v2);
+}
+
+static inline uint32_t
+zxdg_exported_v2_get_version(struct zxdg_exported_v2 *zxdg_exported_v2)
+{
+	return wl_proxy_get_version((struct wl_proxy *) zxdg_exported_v2);
+}
+
+static inline void
+zxdg_exported_v2_destroy(struct zxdg_exported_v2 *zxdg_exported_v2)
+{
+	wl_proxy_marshal((struct wl_proxy *) zxdg_exported_v2,
+			 ZXDG_EXPORTED_V2_DESTROY);
+
+	wl_proxy_destroy((struct wl_proxy *) zxdg_exported_v2);
+}
+
+#ifndef ZXDG_IMPORTED_V2_ERROR_ENUM
+#define ZXDG_IMPORTED_V2_ERROR_ENUM
+/**
+ * @ingroup iface_zxdg_imported_v2
+ * error values
+ *
+ * These errors can be emitted in response to invalid xdg_imported
+ * requests.
+ */
+enum zxdg_imported_v2_error {
+	/**
+	 * surface is not an xdg_toplevel
+	 */
+	ZXDG_IMPORTED_V2_ERROR_INVALID_SURFACE = 0,
+};
+#endif /* ZXDG_IMPORTED_V2_ERROR_ENUM */
+
+struct zxdg_imported_v2_listener {
+	/**
+	 * the imported surface handle has been destroyed
+	 *
+	 * The imported surface handle has been destroyed and any
+	 * relationship set up has been invalidated. This may happen for
+	 * various reasons, for example if the exported surface or the
+	 * exported surface handle has been destroyed, if the handle used
+	 * for importing was invalid.
+	 */
+	void (*destroyed)(void *data,
+			  struct zxdg_imported_v2 *zxdg_imported_v2);
+};
+
+static inline int
+zxdg_imported_v2_add_listener(struct zxdg_imported_v2 *zxdg_imported_v2,
+			      const struct zxdg_imported_v2_listener *listener, void *data)
+{
+	return wl_proxy_add_listener((struct wl_proxy *) zxdg_imported_v2,
+				     (void (**)(void)) listener, data);
+}
+
+#define ZXDG_IMPORTED_V2_DESTROY 0
+#define ZXDG_IMPORTED_V2_SET_PARENT_OF 1
+
+#define ZXDG_IMPORTED_V2_DESTROYED_SINCE_VERSION 1
+
+#define ZXDG_IMPORTED_V2_DESTROY_SINCE_VERSION 1
+#define ZXDG_IMPORTED_V2_SET_PARENT_OF_SINCE_VERSION 1
+
+static inline void
+zxdg_imported_v2_set_user_data(struct zxdg_imported_v2 *zxdg_imported_v2, void *user_data)
+{
+	wl_proxy_set_user_data((struct wl_proxy *) zxdg_imported_v2, user_data);
+}
+
+static inline void *
+zxdg_imported_v2_get_user_data(struct zxdg_imported_v2 *zxdg_imported_v2)
+{
+	return wl_proxy_get_user_data((struct wl_proxy *) zxdg_imported_v2);
+}
+
+static inline uint32_t
+zxdg_imported_v2_get_version(struct zxdg_imported_v2 *zxdg_imported_v2)
+{
+	return wl_proxy_get_version((struct wl_proxy *) zxdg_imported_v2);
+}
+
+static inline void
+zxdg_imported_v2_destroy(struct zxdg_imported_v2 *zxdg_imported_v2)
+{
+	wl_proxy_marshal((struct wl_proxy *) zxdg_imported_v2,
+			 ZXDG_IMPORTED_V2_DESTROY);
+
+	wl_proxy_destroy((struct wl_proxy *) zxdg_imported_v2);
+}
+
+static inline void
+zxdg_imported_v2_set_parent_of(struct zxdg_imported_v2 *zxdg_imported_v2, struct wl_surface *surface)
+{
+	wl_proxy_marshal((struct wl_proxy *) zxdg_imported_v2,
+			 ZXDG_IMPORTED_V2_SET_PARENT_OF, surface);
+}
+
+#ifdef  __cplusplus
+}
+#endif
+
+#endif
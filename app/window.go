@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"image"
 	"image/color"
+	"io"
 	"runtime"
 	"time"
 	"unicode"
@@ -26,6 +27,7 @@ import (
 	"github.com/Seikaijyu/gio/io/system"
 	"github.com/Seikaijyu/gio/layout"
 	"github.com/Seikaijyu/gio/op"
+	"github.com/Seikaijyu/gio/op/clip"
 	"github.com/Seikaijyu/gio/text"
 	"github.com/Seikaijyu/gio/unit"
 	"github.com/Seikaijyu/gio/widget"
@@ -41,6 +43,10 @@ type Option func(unit.Metric, *Config)
 type Window struct {
 	ctx context
 	gpu gpu.GPU
+	// render renders the frames of windows with a threadedDriver.
+	render *renderLoop
+	// refresh forces a context refresh before the next frame.
+	refresh bool
 
 	// driverFuncs is a channel of functions to run when
 	// the Window has a valid driver.
@@ -54,8 +60,6 @@ type Window struct {
 	redraws chan struct{}
 	// immediateRedraws is like redraw but doesn't need a wakeup.
 	immediateRedraws chan struct{}
-	// scheduledRedraws is sent the most recent delayed redraw time.
-	scheduledRedraws chan time.Time
 	// options are the options waiting to be applied.
 	options chan []Option
 	// actions are the actions waiting to be performed.
@@ -72,13 +76,35 @@ type Window struct {
 	animating    bool
 	hasNextFrame bool
 	nextFrame    time.Time
+	// lastFrame is the animation time of the most recent frame.
+	lastFrame time.Time
 	// viewport is the latest frame size with insets applied.
 	viewport image.Rectangle
 	// metric is the metric from the most recent frame.
 	metric unit.Metric
+	// gestures are the gesture settings of the platform, reported in
+	// every FrameEvent.
+	gestures system.Gestures
+	// scheduler drives the frame timing, as set by the Scheduler
+	// or FrameClock options. It is never nil.
+	scheduler FrameScheduler
+	// stopRedraw cancels the scheduled redraw, if any.
+	stopRedraw func()
+	// instanceID is the SingleInstance id, if any.
+	instanceID string
+	// instance listens for other instances if the window belongs
+	// to the primary instance.
+	instance io.Closer
+	// gamepads delivers gamepad events, once started by a
+	// gamepad.InputOp.
+	gamepads io.Closer
 
 	queue       queue
 	cursor      pointer.Cursor
+	imageCursor *pointer.ImageCursor
+	// transform is the transformation of the frame content,
+	// as set by SetTransform.
+	transform   f32.Affine2D
 	decorations struct {
 		op.Ops
 		// enabled tracks the Decorated option as
@@ -86,6 +112,11 @@ type Window struct {
 		// from Config.Decorated depending on platform
 		// capability.
 		enabled bool
+		// barColor, titleColor and dark track the
+		// title bar options given to the Option method.
+		barColor   color.NRGBA
+		titleColor color.NRGBA
+		dark       bool
 		Config
 		height        unit.Dp
 		currentHeight int
@@ -97,6 +128,17 @@ type Window struct {
 
 	nocontext bool
 
+	// interop holds the native handles of the window for package
+	// unsafeinterop.
+	interop interop
+
+	// nativeViews are the native views placed in the last frame.
+	nativeViews []router.NativeView
+
+	// headless is the driver of windows created with the Headless
+	// option, for Screenshot.
+	headless *headlessWindow
+
 	// semantic data, lazily evaluated if requested by a backend to speed up
 	// the cases where semantic data is not needed.
 	semantic struct {
@@ -106,10 +148,31 @@ type Window struct {
 		prevTree []router.SemanticNode
 		tree     []router.SemanticNode
 		ids      map[router.SemanticID]router.SemanticNode
+		// changes is scratch space for AppendSemanticDiffs.
+		changes []router.SemanticChange
 	}
 
 	imeState editorState
 
+	dialog dialogState
+
+	// drag tracks drag-and-drop gestures leaving the window.
+	drag dragState
+
+	// tooltips shows the help texts of components, if enabled.
+	tooltips struct {
+		widget.Tooltips
+		enabled bool
+		// style is the layout given to the HelpTooltipStyle option.
+		style func(gtx layout.Context, text string) layout.Dimensions
+	}
+
+	// frameStats measures the frame times for FrameStats.
+	frameStats frameStats
+
+	// desktopCursor is the driver for CursorPosition and WarpCursor.
+	desktopCursor desktopCursor
+
 	// event stores the state required for processing and delivering events
 	// from NextEvent. If we had support for range over func, this would
 	// be the iterator state.
@@ -117,7 +180,6 @@ type Window struct {
 		created     bool
 		initialOpts []Option
 		wakeup      func()
-		timer       *time.Timer
 	}
 }
 
@@ -169,9 +231,13 @@ func NewWindow(options ...Option) *Window {
 		Size(800, 600),
 		Title("Ninki.UI"),
 		Decorated(true),
+		HelpTooltips(true),
 		decoHeightOpt(decoHeight),
 	}
 	options = append(defaultOptions, options...)
+	if o := headlessEnv(); o != nil {
+		options = append(options, o)
+	}
 	var cnf Config
 	cnf.apply(unit.Metric{}, options)
 
@@ -179,7 +245,6 @@ func NewWindow(options ...Option) *Window {
 		out:              make(chan event.Event),
 		immediateRedraws: make(chan struct{}),
 		redraws:          make(chan struct{}, 1),
-		scheduledRedraws: make(chan time.Time, 1),
 		frames:           make(chan *op.Ops),
 		frameAck:         make(chan struct{}),
 		driverFuncs:      make(chan func(d driver), 1),
@@ -188,20 +253,54 @@ func NewWindow(options ...Option) *Window {
 		destroy:          make(chan struct{}),
 		options:          make(chan []Option, 1),
 		actions:          make(chan system.Action, 1),
-		nocontext:        cnf.CustomRenderer,
+		nocontext:        cnf.CustomRenderer || cnf.headless > 0,
+		gestures:         gestureSettings(),
+		scheduler:        cnf.frameScheduler(),
+		instanceID:       cnf.instanceID,
 	}
 
 	w.decorations.Theme = theme
 	w.decorations.Decorations = deco
 	w.decorations.enabled = cnf.Decorated
+	w.decorations.barColor = cnf.TitleBarColor
+	w.decorations.titleColor = cnf.TitleColor
+	w.decorations.dark = cnf.DarkDecorations
 	w.decorations.height = decoHeight
+	w.tooltips.enabled = cnf.helpTooltips
+	w.tooltips.style = cnf.helpTooltip
 	w.imeState.compose = key.Range{Start: -1, End: -1}
+	w.dialog.owner = cnf.owner
+	w.dialog.modal = cnf.modal
 	w.semantic.ids = make(map[router.SemanticID]router.SemanticNode)
 	w.callbacks.w = w
 	w.eventState.initialOpts = options
 	return w
 }
 
+// NewWindowFrom is like NewWindow, but embeds the window in an existing
+// native window or view, parent, so Gio can be used as a component of
+// other applications, such as a plugin in a host application. The
+// window initially fills the parent. After that, the host is
+// responsible for its size, through the native handle reported by
+// ViewEvent. Options that change the window mode or decorations are
+// ignored.
+//
+// The parent is an HWND on Windows, an NSView on macOS and a Window on
+// X11. Wayland sessions embed through XWayland. On Android, a Gio window
+// is a GioView, which host applications embed by adding it to their
+// layouts like any other view; NewWindow then connects with it.
+// Embedding is not supported on other platforms or through
+// NewWindowFrom on Android, where the window is destroyed with an
+// error.
+func NewWindowFrom(parent uintptr, options ...Option) *Window {
+	if parent == 0 {
+		panic("app: nil parent")
+	}
+	return NewWindow(append(options, func(_ unit.Metric, cnf *Config) {
+		cnf.parent = parent
+	})...)
+}
+
 func decoHeightOpt(h unit.Dp) Option {
 	return func(m unit.Metric, c *Config) {
 		c.decoHeight = h
@@ -226,6 +325,10 @@ func (w *Window) validateAndProcess(d driver, size image.Point, sync bool, frame
 		}
 	}
 	defer signal()
+	if w.refresh {
+		w.refresh = false
+		sync = true
+	}
 	for {
 		if w.gpu == nil && !w.nocontext {
 			var err error
@@ -250,6 +353,30 @@ func (w *Window) validateAndProcess(d driver, size image.Point, sync bool, frame
 				}
 				return err
 			}
+			// Refreshes follow changes of the native surface.
+			w.interop.update(d, w.ctx)
+		}
+		if _, ok := d.(threadedDriver); ok {
+			if w.render == nil {
+				w.render = newRenderLoop()
+			}
+			w.queue.q.Frame(frame)
+			// The render loop lets the client continue.
+			ack := sigChan
+			sigChan = nil
+			w.render.start(func() error {
+				return w.renderFrame(size, frame, func() {
+					if ack != nil {
+						ack <- struct{}{}
+						ack = nil
+					}
+				})
+			})
+			// Profiling reads the GPU timings of this frame.
+			if sync || w.queue.q.Profiling() {
+				return w.waitRender(d)
+			}
+			return nil
 		}
 		if w.ctx != nil {
 			if err := w.ctx.Lock(); err != nil {
@@ -294,6 +421,52 @@ func (w *Window) validateAndProcess(d driver, size image.Point, sync bool, frame
 	}
 }
 
+// renderFrame draws and presents frame on the render loop. It calls
+// signal as soon as frame is no longer needed.
+func (w *Window) renderFrame(size image.Point, frame *op.Ops, signal func()) error {
+	defer signal()
+	if w.ctx == nil {
+		return nil
+	}
+	if err := w.ctx.Lock(); err != nil {
+		return err
+	}
+	defer w.ctx.Unlock()
+	if w.gpu == nil {
+		gpu, err := gpu.New(w.ctx.API())
+		if err != nil {
+			return err
+		}
+		w.gpu = gpu
+	}
+	if err := w.frame(frame, size); err != nil {
+		return err
+	}
+	signal()
+	return w.ctx.Present()
+}
+
+// waitRender waits for the frame in flight on the render loop, if any.
+// A frame that failed for transient reasons is redrawn; other errors
+// are returned.
+func (w *Window) waitRender(d driver) error {
+	if w.render == nil {
+		return nil
+	}
+	err := w.render.wait()
+	switch {
+	case errors.Is(err, errOutOfDate):
+		w.refresh = true
+	case errors.Is(err, gpu.ErrDeviceLost):
+		w.destroyGPU()
+	default:
+		return err
+	}
+	w.setNextFrame(time.Time{})
+	w.updateAnimation(d)
+	return nil
+}
+
 func (w *Window) frame(frame *op.Ops, viewport image.Point) error {
 	if runtime.GOOS == "js" {
 		// Use transparent black when Gio is embedded, to allow mixing of Gio and
@@ -302,11 +475,16 @@ func (w *Window) frame(frame *op.Ops, viewport image.Point) error {
 	} else {
 		w.gpu.Clear(color.NRGBA{A: 0xff, R: 0xff, G: 0xff, B: 0xff})
 	}
+	start := time.Now()
 	target, err := w.ctx.RenderTarget()
 	if err != nil {
 		return err
 	}
-	return w.gpu.Frame(frame, target, viewport)
+	if err := w.gpu.Frame(frame, target, viewport); err != nil {
+		return err
+	}
+	w.frameStats.endFrame(time.Since(start))
+	return nil
 }
 
 func (w *Window) processFrame(d driver, frameStart time.Time) {
@@ -324,11 +502,33 @@ func (w *Window) processFrame(d driver, frameStart time.Time) {
 	if hint, ok := q.TextInputHint(); ok {
 		d.SetInputHint(hint)
 	}
+	rd, rich := d.(richClipboardDriver)
 	if txt, ok := q.WriteClipboard(); ok {
-		d.WriteClipboard(txt)
+		if content := q.ClipboardContent(); rich && len(content) > 0 {
+			rd.WriteClipboardContent(txt, content)
+		} else {
+			d.WriteClipboard(txt)
+		}
 	}
 	if q.ReadClipboard() {
-		d.ReadClipboard()
+		if types := q.ClipboardTypes(); rich && len(types) > 0 {
+			rd.ReadClipboardContent(types)
+		} else {
+			d.ReadClipboard()
+		}
+	}
+	if txt, ok := q.WritePrimary(); ok {
+		d.WritePrimary(txt)
+	}
+	if q.ReadPrimary() {
+		d.ReadPrimary()
+	}
+	if q.GamepadInput() {
+		w.startGamepads()
+	}
+	if views := q.NativeViews(); !nativeViewsEqual(views, w.nativeViews) {
+		w.nativeViews = append(w.nativeViews[:0], views...)
+		d.SetNativeViews(w.nativeViews)
 	}
 	oldState := w.imeState
 	newState := oldState
@@ -371,6 +571,26 @@ func (w *Window) Invalidate() {
 	}
 }
 
+// SetTransform sets a transformation, such as a zoom or a pan, applied
+// to the window content in addition to the scaling for the display
+// density. The content is laid out for the untransformed window size
+// and the transformation is applied to the result, clipped to the
+// content area. Input is mapped to content coordinates through the
+// inverse transformation, so hit testing, pointer positions, input
+// method positions and accessibility bounds match what is displayed.
+//
+// Use the identity transformation, f32.Affine2D{}, to reset.
+func (w *Window) SetTransform(t f32.Affine2D) {
+	w.driverDefer(func(d driver) {
+		if w.transform == t {
+			return
+		}
+		w.transform = t
+		w.setNextFrame(time.Time{})
+		w.updateAnimation(d)
+	})
+}
+
 // Option applies the options to the window.
 func (w *Window) Option(opts ...Option) {
 	if len(opts) == 0 {
@@ -427,15 +647,10 @@ func (w *Window) driverDefer(f func(d driver)) {
 func (w *Window) updateAnimation(d driver) {
 	animate := false
 	if w.stage >= system.StageInactive && w.hasNextFrame {
-		if dt := time.Until(w.nextFrame); dt <= 0 {
+		if !w.nextFrame.After(w.scheduler.Now()) {
 			animate = true
 		} else {
-			// Schedule redraw.
-			select {
-			case <-w.scheduledRedraws:
-			default:
-			}
-			w.scheduledRedraws <- w.nextFrame
+			w.scheduleRedraw(w.nextFrame)
 		}
 	}
 	if animate != w.animating {
@@ -444,6 +659,26 @@ func (w *Window) updateAnimation(d driver) {
 	}
 }
 
+// scheduleRedraw replaces the scheduled redraw with a redraw at the
+// scheduler time at.
+func (w *Window) scheduleRedraw(at time.Time) {
+	w.cancelRedraw()
+	w.stopRedraw = w.scheduler.AfterFunc(at, func() {
+		select {
+		case w.redraws <- struct{}{}:
+			w.wakeup()
+		default:
+		}
+	})
+}
+
+func (w *Window) cancelRedraw() {
+	if w.stopRedraw != nil {
+		w.stopRedraw()
+		w.stopRedraw = nil
+	}
+}
+
 func (w *Window) wakeup() {
 	select {
 	case w.wakeups <- struct{}{}:
@@ -460,6 +695,7 @@ func (w *Window) setNextFrame(at time.Time) {
 
 func (c *callbacks) SetDriver(d driver) {
 	c.d = d
+	c.w.setCursorDriver(d)
 	var wakeup func()
 	if d != nil {
 		wakeup = d.Wakeup
@@ -493,11 +729,28 @@ func (c *callbacks) Event(e event.Event) bool {
 	if _, ok := e.(wakeupEvent); ok {
 		select {
 		case opts := <-c.w.options:
-			cnf := Config{Decorated: c.w.decorations.enabled}
+			deco := &c.w.decorations
+			cnf := Config{
+				Decorated:       deco.enabled,
+				TitleBarColor:   deco.barColor,
+				TitleColor:      deco.titleColor,
+				DarkDecorations: deco.dark,
+				helpTooltips:    c.w.tooltips.enabled,
+				helpTooltip:     c.w.tooltips.style,
+				scheduler:       c.w.scheduler,
+			}
 			for _, opt := range opts {
 				opt(c.w.metric, &cnf)
 			}
-			c.w.decorations.enabled = cnf.Decorated
+			deco.enabled = cnf.Decorated
+			deco.barColor, deco.titleColor = cnf.TitleBarColor, cnf.TitleColor
+			deco.dark = cnf.DarkDecorations
+			c.w.tooltips.enabled = cnf.helpTooltips
+			c.w.tooltips.style = cnf.helpTooltip
+			// Reschedule the next frame with the new scheduler.
+			c.w.cancelRedraw()
+			c.w.scheduler = cnf.frameScheduler()
+			c.w.updateAnimation(c.d)
 			decoHeight := c.w.decorations.height
 			if !c.w.decorations.enabled {
 				decoHeight = 0
@@ -530,8 +783,16 @@ func (c *callbacks) LookupSemantic(semID router.SemanticID) (router.SemanticNode
 
 func (c *callbacks) AppendSemanticDiffs(diffs []router.SemanticID) []router.SemanticID {
 	c.w.updateSemantics()
-	if tree := c.w.semantic.prevTree; len(tree) > 0 {
-		c.w.collectSemanticDiffs(&diffs, c.w.semantic.prevTree[0])
+	s := &c.w.semantic
+	if len(s.prevTree) == 0 {
+		return diffs
+	}
+	// Added and removed nodes are reported through their parents.
+	s.changes = router.AppendSemanticChanges(s.changes[:0], s.prevTree, s.tree)
+	for _, ch := range s.changes {
+		if ch.Kind == router.SemanticChanged {
+			diffs = append(diffs, ch.Node.ID)
+		}
 	}
 	return diffs
 }
@@ -546,7 +807,11 @@ func (c *callbacks) EditorState() editorState {
 }
 
 func (c *callbacks) SetComposingRegion(r key.Range) {
+	if c.w.imeState.compose == r {
+		return
+	}
 	c.w.imeState.compose = r
+	c.Event(key.CompositionEvent(r))
 }
 
 func (c *callbacks) EditorInsert(text string) {
@@ -561,6 +826,32 @@ func (c *callbacks) EditorInsert(text string) {
 	c.SetEditorSelection(sel)
 }
 
+// EditorCompose replaces the composing region, or the selection if there
+// is none, with text and marks it as the composing region. It is used
+// to show the pending text of dead keys and compose sequences on
+// platforms without input methods. An empty text removes the composing
+// region and its text.
+func (c *callbacks) EditorCompose(text string) {
+	rng := c.w.imeState.compose
+	if rng.Start == -1 {
+		if text == "" {
+			return
+		}
+		rng = c.w.imeState.Selection.Range
+	}
+	if rng.Start > rng.End {
+		rng.Start, rng.End = rng.End, rng.Start
+	}
+	c.EditorReplace(rng, text)
+	end := rng.Start + utf8.RuneCountInString(text)
+	comp := key.Range{Start: rng.Start, End: end}
+	if text == "" {
+		comp = key.Range{Start: -1, End: -1}
+	}
+	c.SetComposingRegion(comp)
+	c.SetEditorSelection(key.Range{Start: end, End: end})
+}
+
 func (c *callbacks) EditorReplace(r key.Range, text string) {
 	c.w.imeState.Replace(r, text)
 	c.Event(key.EditEvent{Range: r, Text: text})
@@ -609,6 +900,35 @@ func (c *callbacks) ClickFocus() {
 	c.w.updateAnimation(c.d)
 }
 
+// ClickSemantic clicks the semantic node id on behalf of an
+// accessibility service.
+func (c *callbacks) ClickSemantic(id router.SemanticID) bool {
+	if !c.w.queue.q.ClickSemantic(id) {
+		return false
+	}
+	c.w.setNextFrame(time.Time{})
+	c.w.updateAnimation(c.d)
+	return true
+}
+
+// FocusSemantic moves the keyboard focus to the semantic node id on
+// behalf of an accessibility service.
+func (c *callbacks) FocusSemantic(id router.SemanticID) bool {
+	if !c.w.queue.q.FocusSemantic(id) {
+		return false
+	}
+	c.w.queue.q.RevealFocus(c.w.viewport)
+	c.w.setNextFrame(time.Time{})
+	c.w.updateAnimation(c.d)
+	return true
+}
+
+// SemanticFocus returns the semantic node with keyboard focus, if any.
+func (c *callbacks) SemanticFocus() (router.SemanticID, bool) {
+	c.w.updateSemantics()
+	return c.w.queue.q.SemanticFocus()
+}
+
 func (c *callbacks) ActionAt(p f32.Point) (system.Action, bool) {
 	return c.w.queue.q.ActionAt(p)
 }
@@ -732,7 +1052,19 @@ func (w *Window) waitAck(d driver) {
 	}
 }
 
+func (w *Window) closeInstance() {
+	if w.instance != nil {
+		w.instance.Close()
+		w.instance = nil
+	}
+}
+
 func (w *Window) destroyGPU() {
+	if w.render != nil {
+		// The window is going away or the GPU is lost; the outcome
+		// of the frame in flight doesn't matter.
+		w.render.wait()
+	}
 	if w.gpu != nil {
 		w.ctx.Lock()
 		w.gpu.Release()
@@ -743,6 +1075,24 @@ func (w *Window) destroyGPU() {
 		w.ctx.Release()
 		w.ctx = nil
 	}
+	w.interop.releaseAPI()
+}
+
+// shutdown releases the resources of the window and delivers a
+// DestroyEvent with err.
+func (w *Window) shutdown(err error) {
+	w.cancelRedraw()
+	w.destroyGPU()
+	if w.render != nil {
+		w.render.close()
+		w.render = nil
+	}
+	w.closeInstance()
+	w.closeGamepads()
+	w.closeDialog()
+	w.removeScreenBounds()
+	w.out <- system.DestroyEvent{Err: err}
+	close(w.destroy)
 }
 
 // waitFrame waits for the client to either call FrameEvent.Frame
@@ -781,27 +1131,6 @@ func (w *Window) updateSemantics() {
 	}
 }
 
-// collectSemanticDiffs traverses the previous semantic tree, noting changed nodes.
-func (w *Window) collectSemanticDiffs(diffs *[]router.SemanticID, n router.SemanticNode) {
-	newNode, exists := w.semantic.ids[n.ID]
-	// Ignore deleted nodes, as their disappearance will be reported through an
-	// ancestor node.
-	if !exists {
-		return
-	}
-	diff := newNode.Desc != n.Desc || len(n.Children) != len(newNode.Children)
-	for i, ch := range n.Children {
-		if !diff {
-			newCh := newNode.Children[i]
-			diff = ch.ID != newCh.ID
-		}
-		w.collectSemanticDiffs(diffs, ch)
-	}
-	if diff {
-		*diffs = append(*diffs, n.ID)
-	}
-}
-
 func (w *Window) updateState(d driver) {
 	for {
 		select {
@@ -825,6 +1154,11 @@ func (w *Window) processEvent(d driver, e event.Event) bool {
 	switch e2 := e.(type) {
 	case system.StageEvent:
 		if e2.Stage < system.StageInactive {
+			if w.render != nil {
+				// The GPU is released regardless of the outcome of the
+				// frame in flight.
+				w.render.wait()
+			}
 			if w.gpu != nil {
 				w.ctx.Lock()
 				w.gpu.Release()
@@ -844,12 +1178,28 @@ func (w *Window) processEvent(d driver, e event.Event) bool {
 			// No drawing if not visible.
 			break
 		}
+		// The previous frame must be done with the decoration ops
+		// before they are reused.
+		if err := w.waitRender(d); err != nil {
+			w.shutdown(err)
+			break
+		}
 		w.metric = e2.Metric
+		e2.Gestures = w.gestures
 		var frameStart time.Time
 		if w.queue.q.Profiling() {
 			frameStart = time.Now()
 		}
 		w.hasNextFrame = false
+		if _, ok := w.scheduler.(systemScheduler); !ok {
+			e2.Now = w.scheduler.Now()
+		}
+		if !w.lastFrame.IsZero() {
+			if d := e2.Now.Sub(w.lastFrame); d > 0 {
+				e2.Elapsed = d
+			}
+		}
+		w.lastFrame = e2.Now
 		e2.Frame = w.update
 		e2.Queue = &w.queue
 
@@ -876,38 +1226,72 @@ func (w *Window) processEvent(d driver, e event.Event) bool {
 		size, offset := w.decorate(d, e2.FrameEvent, wrapper)
 		e2.FrameEvent.Size = size
 		deco := m.Stop()
+		layoutStart := time.Now()
 		w.out <- e2.FrameEvent
 		frame := w.waitFrame(d)
+		w.frameStats.startFrame(time.Since(layoutStart))
 		var signal chan<- struct{}
 		if frame != nil {
 			signal = w.frameAck
 			off := op.Offset(offset).Push(wrapper)
-			ops.AddCall(&wrapper.Internal, &frame.Internal, ops.PC{}, ops.PCFor(&frame.Internal))
+			if w.transform != (f32.Affine2D{}) {
+				// Keep transformed content clear of the decorations.
+				cl := clip.Rect{Max: size}.Push(wrapper)
+				t := op.Affine(w.transform).Push(wrapper)
+				ops.AddCall(&wrapper.Internal, &frame.Internal, ops.PC{}, ops.PCFor(&frame.Internal))
+				t.Pop()
+				cl.Pop()
+			} else {
+				ops.AddCall(&wrapper.Internal, &frame.Internal, ops.PC{}, ops.PCFor(&frame.Internal))
+			}
 			off.Pop()
 		}
 		deco.Add(wrapper)
+		w.layoutTooltip(e2.FrameEvent, viewSize, wrapper)
+		if hw, ok := d.(*headlessWindow); ok && frame != nil {
+			hw.render(wrapper, viewSize)
+		}
 		if err := w.validateAndProcess(d, viewSize, e2.Sync, wrapper, signal); err != nil {
-			w.destroyGPU()
-			w.out <- system.DestroyEvent{Err: err}
-			close(w.destroy)
+			w.shutdown(err)
 			break
 		}
 		w.processFrame(d, frameStart)
+		w.deliverDrop()
 		w.updateCursor(d)
 	case system.DestroyEvent:
-		w.destroyGPU()
-		w.out <- e2
-		close(w.destroy)
+		w.shutdown(e2.Err)
 	case ViewEvent:
+		w.interop.view = &e2
+		w.interop.update(d, w.ctx)
 		w.out <- e2
 		w.waitAck(d)
 	case ConfigEvent:
+		// Drivers don't track the focus; it is set from FocusEvents.
+		focused := w.decorations.Config.Focused
 		w.decorations.Config = e2.Config
+		w.decorations.Config.Focused = focused
+		w.updateScreenBounds(d)
 		e2.Config = w.effectiveConfig()
 		w.out <- e2
+	case InstanceEvent:
+		w.out <- e2
 	case wakeupEvent:
 	case event.Event:
+		if e, ok := e2.(key.FocusEvent); ok && w.decorations.Config.Focused != e.Focus {
+			w.decorations.Config.Focused = e.Focus
+			w.updateScreenBounds(d)
+			w.out <- ConfigEvent{Config: w.effectiveConfig()}
+		}
+		if w.blockedByModal(e2) {
+			break
+		}
+		if e, ok := e2.(pointer.Event); ok {
+			w.dropOver(e)
+		}
 		handled := w.queue.q.Queue(e2)
+		if e, ok := e2.(pointer.Event); ok {
+			w.trackDrag(e)
+		}
 		if e, ok := e.(key.Event); ok && !handled {
 			if e.State == key.Press {
 				handled = true
@@ -952,28 +1336,31 @@ func (w *Window) NextEvent() event.Event {
 	state := &w.eventState
 	if !state.created {
 		state.created = true
-		if err := newWindow(&w.callbacks, state.initialOpts); err != nil {
+		if id := w.instanceID; id != "" {
+			if err := w.claimInstance(id); err != nil {
+				close(w.destroy)
+				return system.DestroyEvent{Err: err}
+			}
+		}
+		var err error
+		if headlessInterval(state.initialOpts) > 0 {
+			w.headless, err = newHeadlessWindow(&w.callbacks, state.initialOpts)
+		} else {
+			err = newWindow(&w.callbacks, state.initialOpts)
+		}
+		if err != nil {
+			w.closeInstance()
 			close(w.destroy)
 			return system.DestroyEvent{Err: err}
 		}
+		w.openDialog()
 	}
 	for {
-		var (
-			wakeups <-chan struct{}
-			timeC   <-chan time.Time
-		)
+		var wakeups <-chan struct{}
 		if state.wakeup != nil {
 			wakeups = w.wakeups
-			if state.timer != nil {
-				timeC = state.timer.C
-			}
 		}
 		select {
-		case t := <-w.scheduledRedraws:
-			if state.timer != nil {
-				state.timer.Stop()
-			}
-			state.timer = time.NewTimer(time.Until(t))
 		case e := <-w.out:
 			// Receiving a flushEvent indicates to the platform backend that
 			// all previous events have been processed by the user program.
@@ -981,12 +1368,6 @@ func (w *Window) NextEvent() event.Event {
 				break
 			}
 			return e
-		case <-timeC:
-			select {
-			case w.redraws <- struct{}{}:
-				state.wakeup()
-			default:
-			}
 		case <-wakeups:
 			state.wakeup()
 		case state.wakeup = <-w.wakeupFuncs:
@@ -995,12 +1376,30 @@ func (w *Window) NextEvent() event.Event {
 }
 
 func (w *Window) updateCursor(d driver) {
-	if c := w.queue.q.Cursor(); c != w.cursor {
-		w.cursor = c
+	c, img := w.queue.q.Cursor(), w.queue.q.ImageCursor()
+	if c == w.cursor && img == w.imageCursor {
+		return
+	}
+	w.cursor, w.imageCursor = c, img
+	if img != nil {
+		d.SetImageCursor(img)
+	} else {
 		d.SetCursor(c)
 	}
 }
 
+func nativeViewsEqual(a, b []router.NativeView) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
 func (w *Window) fallbackDecorate() bool {
 	cnf := w.decorations.Config
 	return w.decorations.enabled && !cnf.Decorated && cnf.Mode != Fullscreen && !w.nocontext
@@ -1015,6 +1414,17 @@ func (w *Window) decorate(d driver, e system.FrameEvent, o *op.Ops) (size, offse
 	allActions := system.ActionMinimize | system.ActionMaximize | system.ActionUnmaximize |
 		system.ActionClose | system.ActionMove
 	style := material.Decorations(w.decorations.Theme, deco, allActions, w.decorations.Config.Title)
+	if w.decorations.dark {
+		style.Background = color.NRGBA{R: 0x30, G: 0x30, B: 0x30, A: 0xff}
+		style.Foreground = color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	}
+	if c := w.decorations.barColor; c != (color.NRGBA{}) {
+		style.Background = c
+	}
+	if c := w.decorations.titleColor; c != (color.NRGBA{}) {
+		style.Foreground = c
+	}
+	style.Title.Color = style.Foreground
 	// Update the decorations based on the current window mode.
 	var actions system.Action
 	switch m := w.decorations.Config.Mode; m {
@@ -1031,11 +1441,13 @@ func (w *Window) decorate(d driver, e system.FrameEvent, o *op.Ops) (size, offse
 	}
 	deco.Perform(actions)
 	gtx := layout.Context{
-		Ops:         o,
-		Now:         e.Now,
-		Queue:       e.Queue,
-		Metric:      e.Metric,
-		Constraints: layout.Exact(e.Size),
+		Ops:             o,
+		Now:             e.Now,
+		RefreshInterval: e.RefreshInterval,
+		Queue:           e.Queue,
+		Metric:          e.Metric,
+		Gestures:        e.Gestures,
+		Constraints:     layout.Exact(e.Size),
 	}
 	style.Layout(gtx)
 	// Update the window based on the actions on the decorations.
@@ -1054,6 +1466,7 @@ func (w *Window) effectiveConfig() Config {
 	cnf := w.decorations.Config
 	cnf.Size.Y -= w.decorations.currentHeight
 	cnf.Decorated = w.decorations.enabled || cnf.Decorated
+	cnf.metric = w.metric
 	return cnf
 }
 
@@ -1172,6 +1585,28 @@ func CustomRenderer(custom bool) Option {
 	}
 }
 
+// FrameClock replaces the source of FrameEvent.Now with now.
+// Scheduled redraws are measured relative to now as well, but are
+// delivered in real time. Use Scheduler to control their delivery too.
+// A nil now restores the system clock.
+func FrameClock(now func() time.Time) Option {
+	return func(_ unit.Metric, cnf *Config) {
+		cnf.scheduler = nil
+		if now != nil {
+			cnf.scheduler = &clockScheduler{now: now}
+		}
+	}
+}
+
+// Scheduler replaces the system clock as the driver of frame timing
+// with s, which determines FrameEvent.Now and when the frames requested
+// by op.InvalidateOp are delivered. A nil s restores the system clock.
+func Scheduler(s FrameScheduler) Option {
+	return func(_ unit.Metric, cnf *Config) {
+		cnf.scheduler = s
+	}
+}
+
 // Decorated controls whether Gio and/or the platform are responsible
 // for drawing window decorations. Providing false indicates that
 // the application will either be undecorated or will draw its own decorations.
@@ -1181,6 +1616,93 @@ func Decorated(enabled bool) Option {
 	}
 }
 
+// HelpTooltips controls whether the window shows the help texts given
+// by semantic.HelpOp in tooltips when the pointer rests on a component.
+// Help tooltips are enabled by default. Disable them for programs that
+// show help texts with their own widget.Tooltips.
+func HelpTooltips(enabled bool) Option {
+	return func(_ unit.Metric, cnf *Config) {
+		cnf.helpTooltips = enabled
+	}
+}
+
+// HelpTooltipStyle sets the layout of the tooltips shown by
+// HelpTooltips for a help text. A nil layout selects the default
+// tooltip, in the colors of the window decorations.
+func HelpTooltipStyle(help func(gtx layout.Context, text string) layout.Dimensions) Option {
+	return func(_ unit.Metric, cnf *Config) {
+		cnf.helpTooltip = help
+	}
+}
+
+// TitleBarColor sets the background and title colors of the title bar
+// of decorated windows, for example to match the Palette of a
+// material.Theme. Zero colors select the platform default.
+//
+// Title bar colors are supported on Windows 11, macOS and for the
+// decorations drawn by Gio. On macOS, only the background color is
+// used.
+func TitleBarColor(bg, title color.NRGBA) Option {
+	return func(_ unit.Metric, cnf *Config) {
+		cnf.TitleBarColor = bg
+		cnf.TitleColor = title
+	}
+}
+
+// DarkDecorations selects dark window decorations, regardless of the
+// system appearance. It is supported on Windows 10 and later, macOS
+// and for the decorations drawn by Gio, and ignored elsewhere.
+func DarkDecorations(dark bool) Option {
+	return func(_ unit.Metric, cnf *Config) {
+		cnf.DarkDecorations = dark
+	}
+}
+
+// ContentProtection controls whether the window content is protected
+// from screenshots and screen recordings, for windows that display
+// passwords or other sensitive content. Protection is best effort: it
+// is supported on Android, macOS and Windows, where capturing
+// applications see the window as black or not at all, and ignored
+// elsewhere.
+func ContentProtection(protect bool) Option {
+	return func(_ unit.Metric, cnf *Config) {
+		cnf.ContentProtected = protect
+	}
+}
+
+// KeepScreenOn controls whether the display is kept awake and the
+// screensaver suppressed while the window is visible, for example for
+// kiosk and point-of-sale applications. It is supported on Android,
+// iOS, macOS, Windows, Wayland and X11.
+func KeepScreenOn(on bool) Option {
+	return func(_ unit.Metric, cnf *Config) {
+		cnf.KeepScreenOn = on
+	}
+}
+
+// DisableSystemGestures controls whether system gestures such as
+// swipes from the screen edges are suppressed while the window is in
+// front, so they reach the window instead. The platform may still
+// honor a repeated gesture. It is supported on Android 10 and later,
+// iOS, and on Windows for touch screen edge gestures of Fullscreen
+// windows, and ignored elsewhere.
+func DisableSystemGestures(disable bool) Option {
+	return func(_ unit.Metric, cnf *Config) {
+		cnf.SystemGesturesDisabled = disable
+	}
+}
+
+// NoActivate controls whether the window declines activation when it
+// is shown or clicked, for tool windows such as popup palettes that
+// mustn't take keyboard focus from the main window. Use
+// system.ActionFocus to activate the window explicitly. NoActivate is
+// supported on macOS, Windows and X11, and ignored elsewhere.
+func NoActivate(noActivate bool) Option {
+	return func(_ unit.Metric, cnf *Config) {
+		cnf.NoActivate = noActivate
+	}
+}
+
 // flushEvent is sent to detect when the user program
 // has completed processing of all prior events. Its an
 // [io/event.Event] but only for internal use.
@@ -0,0 +1,62 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+/*
+Command gio creates Gio projects.
+
+Usage:
+
+	gio new [-title title] <module path> [directory]
+
+The new command creates a project for the module in the directory,
+which defaults to the last element of the module path. The project has
+a main.go that opens a window, and an assets directory that is embedded
+into the program and loaded with app.NewAssets. Translations are in
+assets/i18n, in the format of app.Assets.Catalog.
+
+Packaging tools copy the assets directory into the bundles of Android
+and iOS, where app.BundledAssets finds it. Programs that don't embed
+their assets use app.BundledAssets on every platform.
+*/
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: gio new [-title title] <module path> [directory]\n")
+		os.Exit(2)
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+	}
+	var err error
+	switch cmd := flag.Arg(0); cmd {
+	case "new":
+		err = runNew(flag.Args()[1:])
+	default:
+		err = fmt.Errorf("unknown command %q", cmd)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gio: %v\n", err)
+		var usage usageError
+		if errors.As(err, &usage) {
+			flag.Usage()
+		}
+		os.Exit(1)
+	}
+}
+
+// usageError is an error in the command line.
+type usageError struct {
+	msg string
+}
+
+func (e usageError) Error() string {
+	return e.msg
+}
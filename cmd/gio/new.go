@@ -0,0 +1,190 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"go/format"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime/debug"
+	"strings"
+	"text/template"
+)
+
+// gioModule is the module path of Gio.
+const gioModule = "github.com/Seikaijyu/gio"
+
+// project describes a project to create.
+type project struct {
+	// Module is the module path.
+	Module string
+	// Title is the title of the window.
+	Title string
+	// GioVersion is the version of Gio to require, or empty to leave
+	// it to go mod tidy.
+	GioVersion string
+}
+
+// projectFiles are the templates of the files of a project, by path
+// relative to the project directory.
+var projectFiles = map[string]string{
+	"go.mod":              goModTemplate,
+	"main.go":             mainTemplate,
+	"assets/i18n/en.json": translationTemplate,
+}
+
+func runNew(args []string) error {
+	flags := flag.NewFlagSet("new", flag.ContinueOnError)
+	title := flags.String("title", "", "window title (default the module name)")
+	if err := flags.Parse(args); err != nil {
+		return usageError{msg: err.Error()}
+	}
+	if n := flags.NArg(); n < 1 || n > 2 {
+		return usageError{msg: "new: expected a module path and an optional directory"}
+	}
+	p := project{Module: flags.Arg(0), Title: *title}
+	if p.Title == "" {
+		p.Title = path.Base(p.Module)
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Path == gioModule && info.Main.Version != "(devel)" {
+		p.GioVersion = info.Main.Version
+	}
+	dir := flags.Arg(1)
+	if dir == "" {
+		dir = path.Base(p.Module)
+	}
+	if err := p.write(dir); err != nil {
+		return err
+	}
+	fmt.Printf("Created %s. Run \"go mod tidy\" in it to resolve the dependencies.\n", dir)
+	return nil
+}
+
+// write creates the files of the project in dir. It fails without
+// writing anything if any of the files exist.
+func (p project) write(dir string) error {
+	files := make(map[string][]byte)
+	for name, tmpl := range projectFiles {
+		if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(name))); !errors.Is(err, fs.ErrNotExist) {
+			if err == nil {
+				err = fmt.Errorf("%s exists", name)
+			}
+			return err
+		}
+		content, err := p.execute(name, tmpl)
+		if err != nil {
+			return err
+		}
+		files[name] = content
+	}
+	for name, content := range files {
+		file := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(file, content, 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// execute runs the template of the file name, and formats Go source.
+func (p project) execute(name, tmpl string) ([]byte, error) {
+	t, err := template.New(name).Funcs(template.FuncMap{
+		"json": func(v interface{}) (string, error) {
+			b, err := json.Marshal(v)
+			return string(b), err
+		},
+	}).Parse(tmpl)
+	if err != nil {
+		return nil, err
+	}
+	var b bytes.Buffer
+	if err := t.Execute(&b, p); err != nil {
+		return nil, err
+	}
+	if strings.HasSuffix(name, ".go") {
+		return format.Source(b.Bytes())
+	}
+	return b.Bytes(), nil
+}
+
+const goModTemplate = `module {{.Module}}
+
+go 1.19
+{{if .GioVersion}}
+require github.com/Seikaijyu/gio {{.GioVersion}}
+{{end}}`
+
+const mainTemplate = `package main
+
+import (
+	"embed"
+	"io/fs"
+	"log"
+	"os"
+
+	"github.com/Seikaijyu/gio/app"
+	"github.com/Seikaijyu/gio/i18n"
+	"github.com/Seikaijyu/gio/io/system"
+	"github.com/Seikaijyu/gio/layout"
+	"github.com/Seikaijyu/gio/op"
+	"github.com/Seikaijyu/gio/widget/material"
+)
+
+// embedded are the assets of the program. Packaging tools also copy the
+// assets directory into the Android and iOS bundles, where
+// app.BundledAssets finds them.
+//
+//go:embed assets
+var embedded embed.FS
+
+func main() {
+	sub, err := fs.Sub(embedded, "assets")
+	if err != nil {
+		log.Fatal(err)
+	}
+	catalog, err := app.NewAssets(sub).Catalog("i18n", "en")
+	if err != nil {
+		log.Fatal(err)
+	}
+	go func() {
+		w := app.NewWindow(app.Title(catalog.Text("", "app.title")))
+		if err := run(w, catalog); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+func run(w *app.Window, catalog *i18n.Catalog) error {
+	th := material.NewTheme()
+	var ops op.Ops
+	for {
+		switch e := w.NextEvent().(type) {
+		case system.DestroyEvent:
+			return e.Err
+		case system.FrameEvent:
+			gtx := layout.NewContext(&ops, e)
+			gtx.Catalog = catalog
+			layout.Center.Layout(gtx, material.H3(th, gtx.Message("app.hello")).Layout)
+			e.Frame(gtx.Ops)
+		}
+	}
+}
+`
+
+const translationTemplate = `{
+	"app.title": {{json .Title}},
+	"app.hello": "Hello, Gio"
+}
+`
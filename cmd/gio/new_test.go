@@ -0,0 +1,57 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package main
+
+import (
+	"encoding/json"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewProject(t *testing.T) {
+	dir := t.TempDir()
+	p := project{Module: "example.com/notes", Title: `Café "Notes"`, GioVersion: "v0.1.0"}
+	if err := p.write(dir); err != nil {
+		t.Fatal(err)
+	}
+	mod, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"module example.com/notes\n", "require github.com/Seikaijyu/gio v0.1.0\n"} {
+		if !strings.Contains(string(mod), want) {
+			t.Errorf("go.mod lacks %q:\n%s", want, mod)
+		}
+	}
+	if _, err := parser.ParseFile(token.NewFileSet(), filepath.Join(dir, "main.go"), nil, 0); err != nil {
+		t.Errorf("main.go: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "assets", "i18n", "en.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var msgs map[string]string
+	if err := json.Unmarshal(data, &msgs); err != nil {
+		t.Fatalf("en.json: %v", err)
+	}
+	if got := msgs["app.title"]; got != p.Title {
+		t.Errorf("title %q, want %q", got, p.Title)
+	}
+
+	// Existing projects are left alone.
+	main := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(main, []byte("package main\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	os.Remove(filepath.Join(dir, "go.mod"))
+	if err := p.write(dir); err == nil {
+		t.Error("overwrote an existing project")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+		t.Error("wrote files of a project that exists")
+	}
+}
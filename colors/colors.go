@@ -0,0 +1,186 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+/*
+Package colors implements color space conversions and contrast helpers
+for sRGB colors.
+
+HSL and HSV are the cylindrical forms of sRGB, convenient for picking
+colors by hue. HCT (hue, chroma, tone) is the perceptual color space of
+Material Design, where tone is the CIE L* lightness and colors of equal
+tone have equal luminance, whatever their hue and chroma.
+
+The contrast helpers follow the Web Content Accessibility Guidelines
+(WCAG) 2: Luminance is the relative luminance of a color, ContrastRatio
+the ratio between the luminances of two colors, and OnColor picks a
+readable color for content drawn on a background.
+*/
+package colors
+
+import (
+	"image/color"
+	"math"
+)
+
+// HSL is a color in the hue, saturation, lightness form of sRGB.
+type HSL struct {
+	// H is the hue in degrees, in the range [0; 360).
+	H float32
+	// S is the saturation, in the range [0; 1].
+	S float32
+	// L is the lightness, in the range [0; 1].
+	L float32
+	// A is the alpha, in the range [0; 1].
+	A float32
+}
+
+// HSV is a color in the hue, saturation, value form of sRGB.
+type HSV struct {
+	// H is the hue in degrees, in the range [0; 360).
+	H float32
+	// S is the saturation, in the range [0; 1].
+	S float32
+	// V is the value, in the range [0; 1].
+	V float32
+	// A is the alpha, in the range [0; 1].
+	A float32
+}
+
+// ToHSL converts c to HSL.
+func ToHSL(c color.NRGBA) HSL {
+	r, g, b, a := components(c)
+	max, min := max3(r, g, b), min3(r, g, b)
+	l := (max + min) / 2
+	var s float32
+	if d := max - min; d > 0 {
+		s = d / (1 - abs(2*l-1))
+	}
+	return HSL{H: hue(r, g, b, max, min), S: s, L: l, A: a}
+}
+
+// NRGBA converts c to sRGB.
+func (c HSL) NRGBA() color.NRGBA {
+	s, l := clamp1(c.S), clamp1(c.L)
+	chroma := (1 - abs(2*l-1)) * s
+	return fromHue(c.H, chroma, l-chroma/2, c.A)
+}
+
+// ToHSV converts c to HSV.
+func ToHSV(c color.NRGBA) HSV {
+	r, g, b, a := components(c)
+	max, min := max3(r, g, b), min3(r, g, b)
+	var s float32
+	if max > 0 {
+		s = (max - min) / max
+	}
+	return HSV{H: hue(r, g, b, max, min), S: s, V: max, A: a}
+}
+
+// NRGBA converts c to sRGB.
+func (c HSV) NRGBA() color.NRGBA {
+	s, v := clamp1(c.S), clamp1(c.V)
+	chroma := v * s
+	return fromHue(c.H, chroma, v-chroma, c.A)
+}
+
+// components returns the components of c in the range [0; 1].
+func components(c color.NRGBA) (r, g, b, a float32) {
+	return float32(c.R) / 0xff, float32(c.G) / 0xff, float32(c.B) / 0xff, float32(c.A) / 0xff
+}
+
+// hue returns the hue in degrees of an sRGB color with the given
+// maximum and minimum components.
+func hue(r, g, b, max, min float32) float32 {
+	d := max - min
+	if d == 0 {
+		return 0
+	}
+	var h float32
+	switch max {
+	case r:
+		h = (g - b) / d
+	case g:
+		h = (b-r)/d + 2
+	default:
+		h = (r-g)/d + 4
+	}
+	return sanitizeDegrees(h * 60)
+}
+
+// fromHue returns the color with hue h, chroma and the minimum
+// component m.
+func fromHue(h, chroma, m, a float32) color.NRGBA {
+	h = sanitizeDegrees(h) / 60
+	x := chroma * (1 - abs(float32(math.Mod(float64(h), 2))-1))
+	var r, g, b float32
+	switch {
+	case h < 1:
+		r, g = chroma, x
+	case h < 2:
+		r, g = x, chroma
+	case h < 3:
+		g, b = chroma, x
+	case h < 4:
+		g, b = x, chroma
+	case h < 5:
+		r, b = x, chroma
+	default:
+		r, b = chroma, x
+	}
+	return color.NRGBA{
+		R: to8(r + m),
+		G: to8(g + m),
+		B: to8(b + m),
+		A: to8(a),
+	}
+}
+
+// sanitizeDegrees maps d to the range [0; 360).
+func sanitizeDegrees(d float32) float32 {
+	d = float32(math.Mod(float64(d), 360))
+	if d < 0 {
+		d += 360
+	}
+	return d
+}
+
+// to8 converts v in the range [0; 1] to 8 bits.
+func to8(v float32) uint8 {
+	return uint8(clamp1(v)*0xff + .5)
+}
+
+func clamp1(v float32) float32 {
+	switch {
+	case v < 0:
+		return 0
+	case v > 1:
+		return 1
+	}
+	return v
+}
+
+func abs(v float32) float32 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+func max3(a, b, c float32) float32 {
+	if b > a {
+		a = b
+	}
+	if c > a {
+		a = c
+	}
+	return a
+}
+
+func min3(a, b, c float32) float32 {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
@@ -0,0 +1,98 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package colors
+
+import (
+	"image/color"
+	"math"
+	"testing"
+)
+
+var testColors = []color.NRGBA{
+	{A: 0xff},
+	{R: 0xff, G: 0xff, B: 0xff, A: 0xff},
+	{R: 0xff, A: 0xff},
+	{G: 0xff, A: 0xff},
+	{B: 0xff, A: 0xff},
+	{R: 0x3f, G: 0x51, B: 0xb5, A: 0xff},
+	{R: 0x80, G: 0x80, B: 0x80, A: 0x80},
+	{R: 0xf4, G: 0xa2, B: 0x12, A: 0xff},
+}
+
+func TestHSLRoundTrip(t *testing.T) {
+	for _, c := range testColors {
+		if got := ToHSL(c).NRGBA(); got != c {
+			t.Errorf("HSL round trip of %v: got %v", c, got)
+		}
+		if got := ToHSV(c).NRGBA(); got != c {
+			t.Errorf("HSV round trip of %v: got %v", c, got)
+		}
+	}
+}
+
+func TestHSL(t *testing.T) {
+	c := color.NRGBA{R: 0x3f, G: 0x51, B: 0xb5, A: 0xff}
+	hsl, hsv := ToHSL(c), ToHSV(c)
+	if !near(hsl.H, 230.8, 0.1) || !near(hsl.S, 0.484, 0.001) || !near(hsl.L, 0.478, 0.001) {
+		t.Errorf("HSL of %v: got %+v", c, hsl)
+	}
+	if !near(hsv.H, 230.8, 0.1) || !near(hsv.S, 0.652, 0.001) || !near(hsv.V, 0.710, 0.001) {
+		t.Errorf("HSV of %v: got %+v", c, hsv)
+	}
+}
+
+func TestHCT(t *testing.T) {
+	tests := []struct {
+		c       color.NRGBA
+		h, C, t float32
+	}{
+		{color.NRGBA{R: 0xff, A: 0xff}, 27.408, 113.358, 53.233},
+		{color.NRGBA{G: 0xff, A: 0xff}, 142.140, 108.410, 87.737},
+		{color.NRGBA{B: 0xff, A: 0xff}, 282.788, 87.231, 32.303},
+	}
+	for _, test := range tests {
+		got := ToHCT(test.c)
+		if !near(got.H, test.h, 0.01) || !near(got.C, test.C, 0.01) || !near(got.T, test.t, 0.01) {
+			t.Errorf("HCT of %v: got %+v, want %v, %v, %v", test.c, got, test.h, test.C, test.t)
+		}
+	}
+	for _, c := range testColors {
+		got := ToHCT(c).NRGBA()
+		if d := ContrastRatio(got, c); d > 1.02 || got.A != c.A {
+			t.Errorf("HCT round trip of %v: got %v", c, got)
+		}
+	}
+	// Out of gamut chroma is reduced at constant tone.
+	c := HCT{H: 120, C: 200, T: 50, A: 1}
+	if tone := ToHCT(c.NRGBA()).T; !near(tone, 50, 0.5) {
+		t.Errorf("tone of %+v: got %v", c, tone)
+	}
+}
+
+func TestContrast(t *testing.T) {
+	if r := ContrastRatio(black, white); !near(r, 21, 0.01) {
+		t.Errorf("contrast of black and white: got %v", r)
+	}
+	if r := ContrastRatio(white, white); r != 1 {
+		t.Errorf("contrast of white and white: got %v", r)
+	}
+	indigo := color.NRGBA{R: 0x3f, G: 0x51, B: 0xb5, A: 0xff}
+	if c := OnColor(indigo); c != white {
+		t.Errorf("on %v: got %v, want white", indigo, c)
+	}
+	yellow := color.NRGBA{R: 0xff, G: 0xeb, B: 0x3b, A: 0xff}
+	if c := OnColor(yellow); c != black {
+		t.Errorf("on %v: got %v, want black", yellow, c)
+	}
+	for _, bg := range []color.NRGBA{white, black, indigo, yellow} {
+		fg := color.NRGBA{R: 0x90, G: 0x70, B: 0xe0, A: 0xff}
+		got := Readable(fg, bg, ContrastAA)
+		if r := ContrastRatio(got, bg); r < ContrastAA {
+			t.Errorf("readable %v on %v: got %v with contrast %v", fg, bg, got, r)
+		}
+	}
+}
+
+func near(v, want, eps float32) bool {
+	return math.Abs(float64(v-want)) <= float64(eps)
+}
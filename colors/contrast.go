@@ -0,0 +1,105 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package colors
+
+import (
+	"image/color"
+
+	"github.com/Seikaijyu/gio/internal/f32color"
+)
+
+// Minimum contrast ratios of the WCAG 2 conformance levels.
+const (
+	// ContrastAA is the minimum contrast ratio of normal text for
+	// level AA.
+	ContrastAA = 4.5
+	// ContrastAALarge is the minimum contrast ratio of large text and
+	// user interface components for level AA.
+	ContrastAALarge = 3
+	// ContrastAAA is the minimum contrast ratio of normal text for
+	// level AAA.
+	ContrastAAA = 7
+)
+
+var (
+	black = color.NRGBA{A: 0xff}
+	white = color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+)
+
+// Luminance returns the relative luminance of c, from 0 for black to 1
+// for white. The alpha of c is ignored.
+//
+// See https://www.w3.org/TR/WCAG20/#relativeluminancedef for more details.
+func Luminance(c color.NRGBA) float32 {
+	c.A = 0xff
+	return f32color.LinearFromSRGB(c).Luminance()
+}
+
+// ContrastRatio returns the contrast ratio between a and b, from 1 for
+// equal luminances to 21 for black and white. The order of a and b
+// doesn't matter and their alpha is ignored.
+//
+// See https://www.w3.org/TR/WCAG20/#contrast-ratiodef for more details.
+func ContrastRatio(a, b color.NRGBA) float32 {
+	la, lb := Luminance(a), Luminance(b)
+	if la < lb {
+		la, lb = lb, la
+	}
+	return (la + 0.05) / (lb + 0.05)
+}
+
+// OnColor returns the candidate with the highest contrast ratio to the
+// background bg, preferring earlier candidates on ties. Without
+// candidates, OnColor chooses between black and white.
+func OnColor(bg color.NRGBA, candidates ...color.NRGBA) color.NRGBA {
+	if len(candidates) == 0 {
+		candidates = []color.NRGBA{black, white}
+	}
+	best, bestRatio := candidates[0], ContrastRatio(bg, candidates[0])
+	for _, c := range candidates[1:] {
+		if r := ContrastRatio(bg, c); r > bestRatio {
+			best, bestRatio = c, r
+		}
+	}
+	return best
+}
+
+// Readable returns the color closest in tone to fg with at least the
+// contrast ratio min to the background bg, keeping the hue and chroma
+// of fg where the sRGB gamut allows. If no tone reaches min, Readable
+// returns the color with the highest contrast it found.
+func Readable(fg, bg color.NRGBA, min float32) color.NRGBA {
+	best, bestRatio := fg, ContrastRatio(fg, bg)
+	if bestRatio >= min {
+		return fg
+	}
+	c := ToHCT(fg)
+	// Search away from bg first, then past it. Contrast increases
+	// monotonically from the start tone to the end tone.
+	type dir struct{ start, end float32 }
+	dirs := [2]dir{{c.T, 100}, {ToHCT(bg).T, 0}}
+	if Luminance(fg) < Luminance(bg) {
+		dirs = [2]dir{{c.T, 0}, {ToHCT(bg).T, 100}}
+	}
+	for _, d := range dirs {
+		c.T = d.end
+		col := c.NRGBA()
+		if r := ContrastRatio(col, bg); r < min {
+			if r > bestRatio {
+				best, bestRatio = col, r
+			}
+			continue
+		}
+		from, to := d.start, d.end
+		for i := 0; i < 16; i++ {
+			c.T = (from + to) / 2
+			if mid := c.NRGBA(); ContrastRatio(mid, bg) >= min {
+				col, to = mid, c.T
+			} else {
+				from = c.T
+			}
+		}
+		return col
+	}
+	return best
+}
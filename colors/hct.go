@@ -0,0 +1,326 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package colors
+
+import (
+	"image/color"
+	"math"
+)
+
+// HCT is a color in the hue, chroma, tone color space of Material
+// Design. Hue and chroma are those of the CAM16 color appearance model
+// under the default sRGB viewing conditions, and tone is the CIE L*
+// lightness.
+type HCT struct {
+	// H is the hue in degrees, in the range [0; 360).
+	H float32
+	// C is the chroma. Its maximum depends on the hue and tone; it is
+	// rarely above 150.
+	C float32
+	// T is the tone, in the range [0; 100].
+	T float32
+	// A is the alpha, in the range [0; 1].
+	A float32
+}
+
+// ToHCT converts c to HCT.
+func ToHCT(c color.NRGBA) HCT {
+	xyz := xyzFromNRGBA(c)
+	cam := camFromXYZ(xyz)
+	return HCT{
+		H: float32(cam.h),
+		C: float32(cam.c),
+		T: float32(lstarFromY(xyz[1])),
+		A: float32(c.A) / 0xff,
+	}
+}
+
+// NRGBA converts c to sRGB. The chroma is reduced to the maximum
+// chroma the sRGB gamut allows for the hue and tone of c.
+func (c HCT) NRGBA() color.NRGBA {
+	a := to8(c.A)
+	h, chroma, tone := float64(sanitizeDegrees(c.H)), float64(c.C), float64(c.T)
+	if chroma < 1 || tone <= 0 || tone >= 100 {
+		return gray(tone, a)
+	}
+	// Binary search the highest chroma up to c.C within the gamut,
+	// starting with c.C itself.
+	lo, hi, mid := 0.0, chroma, chroma
+	var best *cam16
+	for first := true; hi-lo >= 0.4; first = false {
+		if cam := camByJ(h, mid, tone); cam != nil {
+			if first {
+				return cam.nrgba(a)
+			}
+			best, lo = cam, mid
+		} else {
+			hi = mid
+		}
+		mid = lo + (hi-lo)/2
+	}
+	if best == nil {
+		return gray(tone, a)
+	}
+	return best.nrgba(a)
+}
+
+// cam16 is a color in the CAM16 color appearance model.
+type cam16 struct {
+	// j is the lightness, c the chroma and h the hue in degrees.
+	j, c, h float64
+}
+
+// viewing are the CAM16 viewing conditions of sRGB: the D65 white
+// point, an adapting luminance of 200/π times the luminance of L* 50,
+// an L* 50 background and an average surround.
+var viewing struct {
+	n, aw, nbb, ncb, c, nc, fl, flRoot, z float64
+	rgbD                                  [3]float64
+}
+
+var (
+	whitePoint = [3]float64{95.047, 100, 108.883}
+	m16        = [3][3]float64{
+		{0.401288, 0.650173, -0.051461},
+		{-0.250268, 1.204414, 0.045854},
+		{-0.002079, 0.048952, 0.953127},
+	}
+	m16Inv = [3][3]float64{
+		{1.8620678, -1.0112547, 0.14918678},
+		{0.38752654, 0.62144744, -0.00897398},
+		{-0.01584150, -0.03412294, 1.0499644},
+	}
+	srgbToXYZ = [3][3]float64{
+		{0.41233895, 0.35762064, 0.18051042},
+		{0.2126, 0.7152, 0.0722},
+		{0.01932141, 0.11916382, 0.95034478},
+	}
+	xyzToSRGB = [3][3]float64{
+		{3.2413774792388685, -1.5376652402851851, -0.49885366846268053},
+		{-0.9691452513005321, 1.8758853451067872, 0.04156585616912061},
+		{0.05562093689691305, -0.20395524564742123, 1.0571799111220335},
+	}
+)
+
+func init() {
+	v := &viewing
+	la := 200 / math.Pi * yFromLstar(50) / 100
+	const surround = 2
+	f := 0.8 + surround/10.0
+	v.c = 0.59 + (0.69-0.59)*(f-0.9)*10
+	d := f * (1 - 1/3.6*math.Exp((-la-42)/92))
+	d = math.Max(0, math.Min(1, d))
+	v.nc = f
+	rgbW := mul(m16, whitePoint)
+	for i := range rgbW {
+		v.rgbD[i] = d*100/rgbW[i] + 1 - d
+	}
+	k := 1 / (5*la + 1)
+	k4 := k * k * k * k
+	k4F := 1 - k4
+	v.fl = k4*la + 0.1*k4F*k4F*math.Cbrt(5*la)
+	v.flRoot = math.Pow(v.fl, 0.25)
+	v.n = yFromLstar(50) / whitePoint[1]
+	v.z = 1.48 + math.Sqrt(v.n)
+	v.nbb = 0.725 / math.Pow(v.n, 0.2)
+	v.ncb = v.nbb
+	var rgbA [3]float64
+	for i := range rgbA {
+		af := math.Pow(v.fl*v.rgbD[i]*rgbW[i]/100, 0.42)
+		rgbA[i] = 400 * af / (af + 27.13)
+	}
+	v.aw = (2*rgbA[0] + rgbA[1] + 0.05*rgbA[2]) * v.nbb
+}
+
+// camFromXYZ converts a color in the XYZ color space to CAM16.
+func camFromXYZ(xyz [3]float64) cam16 {
+	v := &viewing
+	rgbC := mul(m16, xyz)
+	var rgbA [3]float64
+	for i, c := range rgbC {
+		d := v.rgbD[i] * c
+		af := math.Pow(v.fl*math.Abs(d)/100, 0.42)
+		rgbA[i] = math.Copysign(400*af/(af+27.13), d)
+	}
+	ra, ga, ba := rgbA[0], rgbA[1], rgbA[2]
+	a := (11*ra - 12*ga + ba) / 11
+	b := (ra + ga - 2*ba) / 9
+	u := (20*ra + 20*ga + 21*ba) / 20
+	p2 := (40*ra + 20*ga + ba) / 20
+	h := math.Atan2(b, a) * 180 / math.Pi
+	if h < 0 {
+		h += 360
+	} else if h >= 360 {
+		h -= 360
+	}
+	ac := p2 * v.nbb
+	j := 100 * math.Pow(ac/v.aw, v.c*v.z)
+	hp := h
+	if hp < 20.14 {
+		hp += 360
+	}
+	eHue := 0.25 * (math.Cos(hp*math.Pi/180+2) + 3.8)
+	p1 := 50000.0 / 13 * eHue * v.nc * v.ncb
+	t := p1 * math.Hypot(a, b) / (u + 0.305)
+	alpha := math.Pow(t, 0.9) * math.Pow(1.64-math.Pow(0.29, v.n), 0.73)
+	return cam16{j: j, c: alpha * math.Sqrt(j/100), h: h}
+}
+
+// xyz converts c to the XYZ color space.
+func (c cam16) xyz() [3]float64 {
+	v := &viewing
+	var alpha float64
+	if c.c != 0 && c.j != 0 {
+		alpha = c.c / math.Sqrt(c.j/100)
+	}
+	t := math.Pow(alpha/math.Pow(1.64-math.Pow(0.29, v.n), 0.73), 1/0.9)
+	hRad := c.h * math.Pi / 180
+	eHue := 0.25 * (math.Cos(hRad+2) + 3.8)
+	ac := v.aw * math.Pow(c.j/100, 1/v.c/v.z)
+	p1 := eHue * 50000 / 13 * v.nc * v.ncb
+	p2 := ac / v.nbb
+	hSin, hCos := math.Sincos(hRad)
+	gamma := 23 * (p2 + 0.305) * t / (23*p1 + 11*t*hCos + 108*t*hSin)
+	a, b := gamma*hCos, gamma*hSin
+	rgbA := [3]float64{
+		(460*p2 + 451*a + 288*b) / 1403,
+		(460*p2 - 891*a - 261*b) / 1403,
+		(460*p2 - 220*a - 6300*b) / 1403,
+	}
+	var rgbF [3]float64
+	for i, ca := range rgbA {
+		base := math.Max(0, 27.13*math.Abs(ca)/(400-math.Abs(ca)))
+		rgbF[i] = math.Copysign(100/v.fl*math.Pow(base, 1/0.42), ca) / v.rgbD[i]
+	}
+	return mul(m16Inv, rgbF)
+}
+
+// nrgba converts c to sRGB, clamping it to the gamut.
+func (c cam16) nrgba(a uint8) color.NRGBA {
+	return nrgbaFromXYZ(c.xyz(), a)
+}
+
+// distance returns the color difference between c and c2 in the
+// CAM16-UCS color space.
+func (c cam16) distance(c2 cam16) float64 {
+	j1, a1, b1 := c.ucs()
+	j2, a2, b2 := c2.ucs()
+	dj, da, db := j1-j2, a1-a2, b1-b2
+	return 1.41 * math.Pow(math.Sqrt(dj*dj+da*da+db*db), 0.63)
+}
+
+// ucs returns the CAM16-UCS coordinates of c.
+func (c cam16) ucs() (j, a, b float64) {
+	j = (1 + 100*0.007) * c.j / (1 + 0.007*c.j)
+	m := math.Log(1+0.0228*c.c*viewing.flRoot) / 0.0228
+	s, co := math.Sincos(c.h * math.Pi / 180)
+	return j, m * co, m * s
+}
+
+// camByJ searches the CAM16 lightness of the color with hue h, the
+// given chroma and tone. It returns nil if the color is outside the
+// sRGB gamut.
+func camByJ(h, chroma, tone float64) *cam16 {
+	lo, hi := 0.0, 100.0
+	bestDL, bestDE := 1000.0, 1000.0
+	var best *cam16
+	for hi-lo > 0.01 {
+		mid := lo + (hi-lo)/2
+		cam := cam16{j: mid, c: chroma, h: h}
+		col := cam.nrgba(0xff)
+		l := lstarFromY(xyzFromNRGBA(col)[1])
+		dL := math.Abs(tone - l)
+		if dL < 0.2 {
+			// The color is in gamut if clamping it to sRGB didn't
+			// change it much.
+			rec := camFromXYZ(xyzFromNRGBA(col))
+			dE := rec.distance(cam16{j: rec.j, c: rec.c, h: h})
+			if dE <= 1 && dE <= bestDE {
+				bestDL, bestDE = dL, dE
+				best = &rec
+			}
+		}
+		if bestDL == 0 && bestDE == 0 {
+			break
+		}
+		if l < tone {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return best
+}
+
+// gray returns the gray color of the given tone.
+func gray(tone float64, a uint8) color.NRGBA {
+	y := yFromLstar(tone)
+	return nrgbaFromXYZ([3]float64{y * whitePoint[0] / 100, y, y * whitePoint[2] / 100}, a)
+}
+
+// xyzFromNRGBA converts the color part of c to the XYZ color space,
+// in the range [0; 100].
+func xyzFromNRGBA(c color.NRGBA) [3]float64 {
+	rgb := [3]float64{linearize(c.R), linearize(c.G), linearize(c.B)}
+	return mul(srgbToXYZ, rgb)
+}
+
+// nrgbaFromXYZ converts xyz to sRGB with alpha a, clamping it to the
+// gamut.
+func nrgbaFromXYZ(xyz [3]float64, a uint8) color.NRGBA {
+	rgb := mul(xyzToSRGB, xyz)
+	return color.NRGBA{R: delinearize(rgb[0]), G: delinearize(rgb[1]), B: delinearize(rgb[2]), A: a}
+}
+
+// linearize converts an sRGB component to linear, in the range
+// [0; 100].
+func linearize(c uint8) float64 {
+	v := float64(c) / 0xff
+	if v <= 0.040449936 {
+		return v / 12.92 * 100
+	}
+	return math.Pow((v+0.055)/1.055, 2.4) * 100
+}
+
+// delinearize converts a linear component in the range [0; 100] to
+// sRGB.
+func delinearize(c float64) uint8 {
+	v := c / 100
+	if v <= 0.0031308 {
+		v *= 12.92
+	} else {
+		v = 1.055*math.Pow(v, 1/2.4) - 0.055
+	}
+	return uint8(math.Max(0, math.Min(1, v))*0xff + .5)
+}
+
+// lstarFromY returns the CIE L* lightness of the luminance y in the
+// range [0; 100].
+func lstarFromY(y float64) float64 {
+	const e = 216.0 / 24389
+	const kappa = 24389.0 / 27
+	t := y / 100
+	if t > e {
+		return 116*math.Cbrt(t) - 16
+	}
+	return kappa * t
+}
+
+// yFromLstar is the inverse of lstarFromY.
+func yFromLstar(l float64) float64 {
+	const e = 216.0 / 24389
+	const kappa = 24389.0 / 27
+	ft := (l + 16) / 116
+	if ft3 := ft * ft * ft; ft3 > e {
+		return ft3 * 100
+	}
+	return l / kappa * 100
+}
+
+func mul(m [3][3]float64, v [3]float64) [3]float64 {
+	return [3]float64{
+		m[0][0]*v[0] + m[0][1]*v[1] + m[0][2]*v[2],
+		m[1][0]*v[0] + m[1][1]*v[1] + m[1][2]*v[2],
+		m[2][0]*v[0] + m[2][1]*v[1] + m[2][2]*v[2],
+	}
+}
@@ -0,0 +1,65 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package gesture
+
+import (
+	"github.com/Seikaijyu/gio/f32"
+	"github.com/Seikaijyu/gio/io/event"
+	"github.com/Seikaijyu/gio/io/pointer"
+)
+
+// claim is the ownership a gesture negotiates for its pointers with the
+// gestures of other handlers, such as a Click inside a Scroll inside a
+// Drag. A gesture grabs its pointers once it recognizes its motion, and
+// yields them when they move farther than the touch slop in a way it
+// doesn't handle, leaving them to the others. Mouse pointers are never
+// yielded, because a mouse drag is deliberate. Among the gestures
+// grabbing a pointer, the router picks the one with the highest
+// precedence, and the foremost among equals; the others receive a
+// pointer.Cancel.
+type claim uint8
+
+const (
+	// claimNone is the claim of an undecided gesture.
+	claimNone claim = iota
+	claimGrab
+	claimYield
+)
+
+// inputOp returns the InputOp of the handler tag of a gesture with
+// claim c.
+func (c claim) inputOp(tag event.Tag, kinds pointer.Kind, precedence int32) pointer.InputOp {
+	return pointer.InputOp{
+		Tag:        tag,
+		Grab:       c == claimGrab,
+		Deny:       c == claimYield,
+		Precedence: precedence,
+		Kinds:      kinds,
+	}
+}
+
+// decideClaim returns the claim of a gesture along axis for the
+// movement d of a pointer from source src since it was pressed.
+// Movements within slop decide nothing. Farther movements along axis
+// grab the pointer, and touch and stylus movements across axis yield
+// it, so that nested gestures along different axes split the pointers
+// like native scroll views. Mouse movements always grab.
+func decideClaim(d f32.Point, slop float32, axis Axis, src pointer.Source) claim {
+	if d.X*d.X+d.Y*d.Y <= slop*slop {
+		return claimNone
+	}
+	if src == pointer.Mouse {
+		return claimGrab
+	}
+	switch axis {
+	case Horizontal:
+		if abs(d.X) < abs(d.Y) {
+			return claimYield
+		}
+	case Vertical:
+		if abs(d.Y) < abs(d.X) {
+			return claimYield
+		}
+	}
+	return claimGrab
+}
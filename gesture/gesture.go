@@ -6,6 +6,14 @@ Package gesture implements common pointer gestures.
 Gestures accept low level pointer Events from an event
 Queue and detect higher level actions such as clicks
 and scrolling.
+
+Gestures of nested handlers, such as a Click inside a Scroll inside a
+Drag, compete for the pointers. A gesture grabs a pointer once it
+recognizes its motion, and the other gestures receive a pointer.Cancel.
+Scroll and Drag along a single axis yield a pointer that moves farther
+than the touch slop across their axis, leaving it to the other
+gestures. When several gestures grab a pointer at once, the one with
+the highest Precedence wins, and the foremost among equals.
 */
 package gesture
 
@@ -24,23 +32,62 @@ import (
 	"github.com/Seikaijyu/gio/unit"
 )
 
-// The duration is somewhat arbitrary.
-const doubleClickDuration = 200 * time.Millisecond
+// Defaults of the gesture settings. The widgets of package widget
+// replace them with the settings of the platform in layout.Context.
+const (
+	// defaultDoubleClickDuration is the maximum interval between the
+	// clicks of a double click.
+	defaultDoubleClickDuration = 200 * time.Millisecond
+	// defaultTouchSlop is the distance a pointer must move before a
+	// gesture starts dragging, scrolling or pinching.
+	defaultTouchSlop = unit.Dp(3)
+)
 
 // Hover detects the hover gesture for a pointer area.
+//
+// Tooltips and hover previews can set EnterDelay and ExitDelay and
+// call UpdateDelayed, to appear only after the pointer rests on the
+// area and not to flicker when it briefly leaves.
 type Hover struct {
+	// EnterDelay is how long a pointer must stay inside the area before
+	// UpdateDelayed reports it as hovered.
+	EnterDelay time.Duration
+	// ExitDelay is how long a pointer must stay outside the area before
+	// UpdateDelayed reports it as no longer hovered.
+	ExitDelay time.Duration
+	// TrackPosition makes Position follow the pointer while it moves
+	// inside the area. Otherwise, Position is where the pointer entered,
+	// unless EnterDelay or ExitDelay is set.
+	TrackPosition bool
+
 	// entered tracks whether the pointer is inside the gesture.
 	entered bool
 	// pid is the pointer.ID.
 	pid pointer.ID
+	// pos is the last position of the pointer.
+	pos f32.Point
+	// active is the state reported by UpdateDelayed.
+	active bool
+	// changed is the frame time entered last changed.
+	changed time.Time
+	// deadline is the time the pending change of active is due, or
+	// zero.
+	deadline time.Time
 }
 
 // Add the gesture to detect hovering over the current pointer area.
 func (h *Hover) Add(ops *op.Ops) {
+	kinds := pointer.Enter | pointer.Leave
+	if h.TrackPosition || h.EnterDelay != 0 || h.ExitDelay != 0 {
+		kinds |= pointer.Move
+	}
 	pointer.InputOp{
 		Tag:   h,
-		Kinds: pointer.Enter | pointer.Leave,
+		Kinds: kinds,
 	}.Add(ops)
+	if !h.deadline.IsZero() {
+		op.InvalidateOp{At: h.deadline}.Add(ops)
+	}
 }
 
 // Update state and report whether a pointer is inside the area.
@@ -61,15 +108,58 @@ func (h *Hover) Update(q event.Queue) bool {
 			}
 			if h.pid == e.PointerID {
 				h.entered = true
+				h.pos = e.Position
+			}
+		case pointer.Move:
+			if h.entered && h.pid == e.PointerID {
+				h.pos = e.Position
 			}
 		}
 	}
 	return h.entered
 }
 
+// UpdateDelayed is like Update, but reports a pointer inside the area
+// only after it stayed inside for EnterDelay, and outside only after it
+// stayed outside for ExitDelay. The frame time t measures the delays.
+func (h *Hover) UpdateDelayed(q event.Queue, t time.Time) bool {
+	was := h.entered
+	if h.Update(q) != was {
+		h.changed = t
+	}
+	h.deadline = time.Time{}
+	if h.entered != h.active {
+		d := h.ExitDelay
+		if h.entered {
+			d = h.EnterDelay
+		}
+		if due := h.changed.Add(d); t.Before(due) {
+			h.deadline = due
+		} else {
+			h.active = h.entered
+		}
+	}
+	return h.active
+}
+
+// Position returns the last position of the hovering pointer, in the
+// coordinates of the handler.
+func (h *Hover) Position() f32.Point {
+	return h.pos
+}
+
 // Click detects click gestures in the form
 // of ClickEvents.
 type Click struct {
+	// DoubleClickDuration is the maximum interval between successive
+	// clicks, such as the system.Gestures setting of the platform. The
+	// zero value means 200ms.
+	DoubleClickDuration time.Duration
+	// Buttons is the set of mouse buttons that click, such as
+	// ButtonSecondary for context menus. The zero value selects
+	// ButtonPrimary. Other sources always click.
+	Buttons pointer.Buttons
+
 	// clickedAt is the timestamp at which
 	// the last click occurred.
 	clickedAt time.Duration
@@ -84,6 +174,8 @@ type Click struct {
 	entered bool
 	// pid is the pointer.ID.
 	pid pointer.ID
+	// button is the button of the press.
+	button pointer.Buttons
 }
 
 // ClickEvent represent a click action, either a
@@ -97,32 +189,428 @@ type ClickEvent struct {
 	// NumClicks records successive clicks occurring
 	// within a short duration of each other.
 	NumClicks int
+	// Button is the mouse button of the click, or ButtonPrimary for
+	// other sources.
+	Button pointer.Buttons
 }
 
 type ClickKind uint8
 
 // Drag detects drag gestures in the form of pointer.Drag events.
 type Drag struct {
+	// TouchSlop is how far a pointer must move before the gesture
+	// starts. The zero value means 3dp.
+	TouchSlop unit.Dp
+	// MouseSlop is how far a mouse pointer must move before the
+	// gesture starts, such as the system.Gestures drag threshold of
+	// the platform. The zero value selects TouchSlop.
+	MouseSlop unit.Dp
+	// Precedence orders the gesture among the gestures of other
+	// handlers claiming the same pointer. The highest wins, and the
+	// foremost among equals.
+	Precedence int32
+	// Threshold is how far a pointer must move before Update reports
+	// Drag events, which then start once the gesture claims the
+	// pointer. The zero value reports every movement, even before the
+	// gesture decides its claim.
+	Threshold unit.Dp
+	// LockAxis locks drags along Both axes onto the axis of their
+	// initial direction, once they move farther than the touch slop.
+	// Drags along a single axis yield movements across it to other
+	// gestures, such as the scrolling of a list.
+	LockAxis bool
+
 	dragging bool
 	pressed  bool
+	// started is set once the pointer moved farther than Threshold.
+	started bool
+	pid     pointer.ID
+	start   f32.Point
+	claim   claim
+	// locked is the axis the drag is locked onto, or Both.
+	locked Axis
+}
+
+// MultiDrag detects drag gestures of several pointers at once, such as
+// for two finger interactions, in the form of pointer events. Unlike
+// Drag, which ignores all but the first pointer, MultiDrag reports the
+// Press, Drag, and Release or Cancel events of every pointer pressed in
+// its area. The streams of the pointers are told apart by PointerID.
+type MultiDrag struct {
+	// TouchSlop is how far a pointer must move before the gesture
+	// starts. The zero value means 3dp.
+	TouchSlop unit.Dp
+	// Precedence orders the gesture among competing gestures, like
+	// Drag.Precedence.
+	Precedence int32
+
+	// pointers are the pressed pointers.
+	pointers []dragPointer
+	claim    claim
+}
+
+type dragPointer struct {
+	id    pointer.ID
+	start f32.Point
+}
+
+// Pinch detects two finger pinch gestures on touch screens, typically
+// used for zooming, in the form of PinchEvents. It also reports the
+// pinch gestures of trackpads delivered as pointer.Pinch events.
+type Pinch struct {
+	// TouchSlop is how far a pointer must move before the gesture
+	// starts. The zero value means 3dp.
+	TouchSlop unit.Dp
+	// Precedence orders the gesture among competing gestures, like
+	// Drag.Precedence.
+	Precedence int32
+
+	pair pointerPair
+	// pinching tracks whether the distance between the pointers
+	// changed more than the touch slop.
+	pinching bool
+	claim    claim
+	// dist and centroid are the distance between and midpoint of the
+	// pointers at the last PinchEvent, or when the second pointer was
+	// pressed.
+	dist     float32
+	centroid f32.Point
+	// rotations are the trackpad rotations of the last Update, for a
+	// Rotate following the Pinch.
+	rotations []pointer.Event
+}
+
+// PinchEvent reports the change of a pinch gesture since the previous
+// PinchEvent.
+type PinchEvent struct {
+	// Scale is the factor by which the distance between the pointers
+	// changed. Multiply a zoom level by Scale to follow the fingers.
+	Scale float32
+	// Centroid is the midpoint between the pointers. Zoom around it to
+	// keep the content under the fingers in place.
+	Centroid f32.Point
+	// Pan is the movement of the centroid.
+	Pan f32.Point
+}
+
+// Rotate detects two finger rotation gestures on touch screens in the
+// form of RotateEvents. It also reports the rotation gestures of
+// trackpads delivered as pointer.Rotate events.
+//
+// A Rotate and a Pinch added to the same area compete for the pointers,
+// and the first to recognize its gesture cancels the other. To rotate
+// and zoom at the same time, set Pinch to follow the pointers of a Pinch
+// instead.
+type Rotate struct {
+	// Pinch, if set, is the Pinch whose pointers the Rotate follows. The
+	// Rotate then adds no handler of its own, and must be updated after
+	// the Pinch in every frame.
+	Pinch *Pinch
+	// TouchSlop is how far the pointers must rotate, along their
+	// circle, before the gesture starts. The zero value means 3dp.
+	TouchSlop unit.Dp
+	// Precedence orders the gesture among competing gestures, like
+	// Drag.Precedence. A Rotate following a Pinch claims the pointers
+	// with the Precedence of the Pinch.
+	Precedence int32
+
+	pair     pointerPair
+	rotating bool
+	claim    claim
+	// pairs is the pointerPair.pairs of the current gesture.
+	pairs int
+	// angle is the angle of the line between the pointers at the last
+	// RotateEvent, or when the second pointer was pressed.
+	angle float32
+}
+
+// RotateEvent reports the change of a rotation gesture since the
+// previous RotateEvent.
+type RotateEvent struct {
+	// Angle is the change in angle, in radians. Positive angles rotate
+	// clockwise, from the positive x-axis towards the positive y-axis.
+	Angle float32
+	// Pivot is the midpoint between the pointers. Rotate around it to
+	// keep the content under the fingers in place.
+	Pivot f32.Point
+}
+
+// LongPress detects presses held in place for a duration, in the form
+// of LongPressEvents. Long presses typically open context menus on touch
+// screens, or start dragging.
+type LongPress struct {
+	// Duration is how long a press must be held. The zero value selects
+	// a platform neutral 500 milliseconds.
+	Duration time.Duration
+	// Slop is how far the pointer may move while held. The zero value
+	// selects 8 dp, more than the touch slop, because fingers held
+	// still wander.
+	Slop unit.Dp
+	// Precedence orders the triggered gesture among competing
+	// gestures, like Drag.Precedence.
+	Precedence int32
+
+	pressed   bool
+	triggered bool
+	claim     claim
+	pid       pointer.ID
+	source    pointer.Source
+	modifiers key.Modifiers
+	start     f32.Point
+	pos       f32.Point
+	// deadline is the time the press triggers.
+	deadline time.Time
+}
+
+// LongPressEvent reports the progress of a long press.
+type LongPressEvent struct {
+	Kind      LongPressKind
+	Position  image.Point
+	Source    pointer.Source
+	Modifiers key.Modifiers
+}
+
+type LongPressKind uint8
+
+// ForcePress detects presses of varying pressure, in the form of
+// ForcePressEvents, such as for previewing content with a deep press.
+// It relies on the pressure reported by styluses and force sensitive
+// devices; presses without pressure never press deeply. A ForcePress
+// grabs the pointer when pressed deeply.
+type ForcePress struct {
+	// Threshold is the pressure of a deep press, in the range (0, 1].
+	// The zero value selects 0.75.
+	Threshold float32
+	// Slop is how far the pointer may move before a deep press. The
+	// zero value selects 8 dp, like LongPress.
+	Slop unit.Dp
+	// Precedence orders the deep pressed gesture among competing
+	// gestures, like Drag.Precedence.
+	Precedence int32
+
+	pressed   bool
+	deep      bool
+	claim     claim
+	pid       pointer.ID
+	source    pointer.Source
+	modifiers key.Modifiers
+	start     f32.Point
+	pos       f32.Point
+	pressure  float32
+}
+
+// ForcePressEvent reports the progress of a force press.
+type ForcePressEvent struct {
+	Kind ForcePressKind
+	// Pressure is the normalized pressure of the pointer, in the range
+	// [0, 1].
+	Pressure  float32
+	Position  image.Point
+	Source    pointer.Source
+	Modifiers key.Modifiers
+}
+
+type ForcePressKind uint8
+
+// Swipe detects quick directional flicks of a pointer, in the form of
+// SwipeEvents, such as for dismissing items or navigating pages. A Swipe
+// doesn't grab the pointer, so it works alongside a Scroll along the
+// other axis.
+type Swipe struct {
+	// MinDistance is how far a flick must move. The zero value selects
+	// 24 dp.
+	MinDistance unit.Dp
+	// MinVelocity is how fast a flick must move when released, in dp per
+	// second. The zero value selects 300 dp per second.
+	MinVelocity unit.Dp
+
+	pressed bool
+	pid     pointer.ID
+	start   f32.Point
+	// xest and yest estimate the velocity of the pointer.
+	xest, yest fling.Extrapolation
+}
+
+// SwipeEvent reports a flick.
+type SwipeEvent struct {
+	Direction SwipeDirection
+	// Distance is the distance the pointer moved in Direction, in
+	// pixels.
+	Distance float32
+	// Velocity is the speed of the pointer in Direction when it was
+	// released, in pixels per second.
+	Velocity float32
+	// Position is where the pointer was pressed.
+	Position image.Point
+	Source   pointer.Source
+}
+
+// SwipeDirection is the direction of a flick, on the screen.
+type SwipeDirection uint8
+
+// EdgeSwipe detects swipes starting at an edge of an area, in the form
+// of EdgeSwipeEvents, such as for opening navigation drawers or
+// swiping back. An EdgeSwipe grabs the pointer once it moves away from
+// the edge farther than the touch slop, and yields it for other
+// movements.
+type EdgeSwipe struct {
+	// Edge is the edge swipes start from.
+	Edge Edge
+	// Width is how far from the edge a pointer may be pressed to start
+	// a swipe. The zero value selects 20 dp.
+	Width unit.Dp
+	// Distance is the distance of a complete swipe, such as the width
+	// of a drawer, that the progress is relative to. The zero value
+	// selects the size of the area.
+	Distance unit.Dp
+	// Threshold is the progress a slow swipe must reach when released
+	// to trigger. The zero value selects 0.5. Faster swipes trigger or
+	// cancel by their direction.
+	Threshold float32
+	// TouchSlop is how far a pointer must move before the gesture
+	// starts. The zero value means 3dp.
+	TouchSlop unit.Dp
+	// Precedence orders the gesture among competing gestures, like
+	// Drag.Precedence.
+	Precedence int32
+
+	pressed  bool
+	swiping  bool
+	claim    claim
 	pid      pointer.ID
 	start    f32.Point
-	grab     bool
+	progress float32
+	// est estimates the velocity of the pointer away from the edge.
+	est fling.Extrapolation
+}
+
+// EdgeSwipeEvent reports the progress of an edge swipe.
+type EdgeSwipeEvent struct {
+	Kind EdgeSwipeKind
+	// Progress is the distance the pointer moved away from the edge,
+	// relative to the Distance of the EdgeSwipe and clamped to [0, 1].
+	Progress float32
+	// Velocity is the speed of the pointer away from the edge when it
+	// was released, in pixels per second. It is only set for
+	// EdgeSwipeTrigger and EdgeSwipeCancel.
+	Velocity float32
+	Source   pointer.Source
+}
+
+type EdgeSwipeKind uint8
+
+// Edge is an edge of an area.
+type Edge uint8
+
+// Pan detects drag gestures along both axes in the form of PanEvents,
+// and estimates the velocity of the pointer when it is released, such
+// as for scrolling with momentum in custom scrollers. A Pan grabs the
+// pointer once it moves farther than the touch slop.
+type Pan struct {
+	// TouchSlop is how far a pointer must move before the gesture
+	// starts. The zero value means 3dp.
+	TouchSlop unit.Dp
+	// Precedence orders the gesture among competing gestures, like
+	// Drag.Precedence.
+	Precedence int32
+
+	pressed bool
+	panning bool
+	claim   claim
+	pid     pointer.ID
+	// last is the position of the previous event.
+	last f32.Point
+	// xest and yest estimate the velocity of the pointer.
+	xest, yest fling.Extrapolation
+}
+
+// PanEvent reports the progress of a pan gesture.
+type PanEvent struct {
+	Kind PanKind
+	// Delta is the distance the pointer moved since the previous
+	// PanEvent, or since it was pressed for PanStart, in pixels.
+	Delta f32.Point
+	// Velocity is the velocity of the pointer when it was released, in
+	// pixels per second. It is only set for PanEnd.
+	Velocity f32.Point
+	// Position is the position of the pointer.
+	Position f32.Point
+	Source   pointer.Source
+}
+
+type PanKind uint8
+
+// pointerPair tracks the first two touch pointers pressed in an area,
+// for the gestures of two fingers.
+type pointerPair struct {
+	// pointers are the pressed pointers, at most two.
+	pointers []pairPointer
+	// pairs counts the times a second pointer was pressed, to tell
+	// gestures apart.
+	pairs int
+}
+
+type pairPointer struct {
+	id  pointer.ID
+	pos f32.Point
 }
 
 // Scroll detects scroll gestures and reduces them to
 // scroll distances. Scroll recognizes mouse wheel
 // movements as well as drag and fling touch gestures.
+//
+// Precise scrolling, such as by trackpads, is applied immediately,
+// while the ticks of mouse wheels scroll smoothly over a few frames.
 type Scroll struct {
+	// TouchSlop is how far a pointer must move before the gesture
+	// starts. The zero value means 3dp.
+	TouchSlop unit.Dp
+	// Precedence orders the gesture among competing gestures, like
+	// Drag.Precedence.
+	Precedence int32
+	// Friction is the deceleration rate of flings: their velocity
+	// decays by the factor e^-Friction every second. The zero value
+	// selects the platform default, 2 on Apple platforms and 4.2
+	// elsewhere.
+	Friction float32
+	// Overscroll enables scrolling beyond the content by dragging and
+	// flinging, as reported by Overflow. The distance beyond the
+	// content is resisted, and settles back when the pointer is
+	// released; see OverscrollDistance.
+	Overscroll bool
+
 	dragging  bool
 	axis      Axis
 	estimator fling.Extrapolation
 	flinger   fling.Animation
 	pid       pointer.ID
-	grab      bool
-	last      int
+	claim     claim
+	// start is where the pointer was pressed.
+	start f32.Point
+	last  int
 	// Leftover scroll.
 	scroll float32
+	// now is the frame time of the last Update.
+	now time.Time
+	// over is the distance scrolled beyond the content, in pixels,
+	// before resistance.
+	over float32
+	// overMax is the limit of the resisted overscroll distance, in
+	// pixels.
+	overMax float32
+	// settle animates over back to zero.
+	settle struct {
+		active bool
+		t0     time.Time
+		x0, v0 float32
+	}
+	// wheel animates the distance of wheel ticks.
+	wheel struct {
+		// remaining is the distance left to scroll.
+		remaining float32
+		// t is the time of the last step.
+		t time.Time
+	}
 }
 
 type ScrollState uint8
@@ -153,11 +641,103 @@ const (
 	// StateDragging is reported during drag gestures.
 	StateDragging
 	// StateFlinging is reported when a fling is
-	// in progress.
+	// in progress, or an overscroll settles back.
 	StateFlinging
 )
 
-const touchSlop = unit.Dp(3)
+const (
+	// LongPressPressed is reported when a pointer is pressed.
+	LongPressPressed LongPressKind = iota
+	// LongPressTriggered is reported when the press was held for the
+	// duration. The LongPress then grabs the pointer.
+	LongPressTriggered
+	// LongPressCancelled is reported when the pointer is released, moves
+	// too far or is cancelled before the press triggers.
+	LongPressCancelled
+)
+
+const (
+	// ForcePressStart is reported when a pointer is pressed.
+	ForcePressStart ForcePressKind = iota
+	// ForcePressChange is reported when the pressure changes.
+	ForcePressChange
+	// ForcePressDeep is reported once per press, when the pressure
+	// reaches the threshold. The ForcePress then grabs the pointer.
+	ForcePressDeep
+	// ForcePressEnd is reported when the pointer is released.
+	ForcePressEnd
+	// ForcePressCancel is reported when the pointer moves too far
+	// before pressing deeply, or is cancelled.
+	ForcePressCancel
+)
+
+const defaultForcePressThreshold = 0.75
+
+const (
+	defaultLongPressDuration = 500 * time.Millisecond
+	defaultLongPressSlop     = unit.Dp(8)
+)
+
+const (
+	// maxOverscroll is the limit of the resisted overscroll distance.
+	maxOverscroll = unit.Dp(120)
+	// thresholdSettleVelocity is the velocity, in pixels per second,
+	// below which the overscroll distance settles.
+	thresholdSettleVelocity = 5
+	// wheelTimeConstant is the time constant of the exponential
+	// approach of wheel scrolling to its target.
+	wheelTimeConstant = 50 * time.Millisecond
+)
+
+const (
+	SwipeUp SwipeDirection = iota
+	SwipeDown
+	SwipeLeft
+	SwipeRight
+)
+
+const (
+	defaultSwipeDistance = unit.Dp(24)
+	defaultSwipeVelocity = unit.Dp(300)
+)
+
+const (
+	EdgeLeft Edge = iota
+	EdgeTop
+	EdgeRight
+	EdgeBottom
+)
+
+const (
+	// EdgeSwipeStart is reported when the pointer moves away from the
+	// edge farther than the touch slop.
+	EdgeSwipeStart EdgeSwipeKind = iota
+	// EdgeSwipeMove is reported for the following moves.
+	EdgeSwipeMove
+	// EdgeSwipeTrigger is reported when the pointer is released past
+	// the threshold, or flicked away from the edge.
+	EdgeSwipeTrigger
+	// EdgeSwipeCancel is reported when the pointer is released short of
+	// the threshold, flicked towards the edge, or cancelled.
+	EdgeSwipeCancel
+)
+
+const (
+	defaultEdgeSwipeWidth     = unit.Dp(20)
+	defaultEdgeSwipeThreshold = 0.5
+)
+
+const (
+	// PanStart is reported when the pointer moves farther than the
+	// touch slop.
+	PanStart PanKind = iota
+	// PanMove is reported for the following moves.
+	PanMove
+	// PanEnd is reported when the pointer is released.
+	PanEnd
+	// PanCancel is reported when the gesture is cancelled.
+	PanCancel
+)
 
 // Add the handler to the operation list to receive click events.
 func (c *Click) Add(ops *op.Ops) {
@@ -192,7 +772,7 @@ func (c *Click) Update(q event.Queue) []ClickEvent {
 			}
 			c.pressed = false
 			if !c.entered || c.hovered {
-				events = append(events, ClickEvent{Kind: KindClick, Position: e.Position.Round(), Source: e.Source, Modifiers: e.Modifiers, NumClicks: c.clicks})
+				events = append(events, ClickEvent{Kind: KindClick, Position: e.Position.Round(), Source: e.Source, Modifiers: e.Modifiers, NumClicks: c.clicks, Button: c.button})
 			} else {
 				events = append(events, ClickEvent{Kind: KindCancel})
 			}
@@ -208,8 +788,13 @@ func (c *Click) Update(q event.Queue) []ClickEvent {
 			if c.pressed {
 				break
 			}
-			if e.Source == pointer.Mouse && e.Buttons != pointer.ButtonPrimary {
-				break
+			btn := pointer.ButtonPrimary
+			if e.Source == pointer.Mouse {
+				// Only presses of a single button click.
+				btn = e.Buttons
+				if btn&(btn-1) != 0 || !c.buttons().Contain(btn) {
+					break
+				}
 			}
 			if !c.hovered {
 				c.pid = e.PointerID
@@ -218,13 +803,14 @@ func (c *Click) Update(q event.Queue) []ClickEvent {
 				break
 			}
 			c.pressed = true
-			if e.Time-c.clickedAt < doubleClickDuration {
+			if e.Time-c.clickedAt < c.doubleClickDuration() && btn == c.button {
 				c.clicks++
 			} else {
 				c.clicks = 1
 			}
+			c.button = btn
 			c.clickedAt = e.Time
-			events = append(events, ClickEvent{Kind: KindPress, Position: e.Position.Round(), Source: e.Source, Modifiers: e.Modifiers, NumClicks: c.clicks})
+			events = append(events, ClickEvent{Kind: KindPress, Position: e.Position.Round(), Source: e.Source, Modifiers: e.Modifiers, NumClicks: c.clicks, Button: btn})
 		case pointer.Leave:
 			if !c.pressed {
 				c.pid = e.PointerID
@@ -245,27 +831,38 @@ func (c *Click) Update(q event.Queue) []ClickEvent {
 	return events
 }
 
+func (c *Click) buttons() pointer.Buttons {
+	if c.Buttons == 0 {
+		return pointer.ButtonPrimary
+	}
+	return c.Buttons
+}
+
+func (c *Click) doubleClickDuration() time.Duration {
+	if c.DoubleClickDuration == 0 {
+		return defaultDoubleClickDuration
+	}
+	return c.DoubleClickDuration
+}
+
 func (ClickEvent) ImplementsEvent() {}
 
 // Add the handler to the operation list to receive scroll events.
 // The bounds variable refers to the scrolling boundaries
 // as defined in io/pointer.InputOp.
 func (s *Scroll) Add(ops *op.Ops, bounds image.Rectangle) {
-	oph := pointer.InputOp{
-		Tag:          s,
-		Grab:         s.grab,
-		Kinds:        pointer.Press | pointer.Drag | pointer.Release | pointer.Scroll,
-		ScrollBounds: bounds,
-	}
+	oph := s.claim.inputOp(s, pointer.Press|pointer.Drag|pointer.Release|pointer.Scroll, s.Precedence)
+	oph.ScrollBounds = bounds
 	oph.Add(ops)
-	if s.flinger.Active() {
+	if s.flinger.Active() || s.settle.active || s.wheel.remaining != 0 {
 		op.InvalidateOp{}.Add(ops)
 	}
 }
 
-// Stop any remaining fling movement.
+// Stop any remaining fling or wheel movement.
 func (s *Scroll) Stop() {
 	s.flinger = fling.Animation{}
+	s.wheel.remaining = 0
 }
 
 // Update state and report the scroll distance along axis.
@@ -274,6 +871,8 @@ func (s *Scroll) Update(cfg unit.Metric, q event.Queue, t time.Time, axis Axis)
 		s.axis = axis
 		return 0
 	}
+	s.now = t
+	s.overMax = float32(cfg.Dp(maxOverscroll))
 	total := 0
 	for _, evt := range q.Events(s) {
 		e, ok := evt.(pointer.Event)
@@ -291,57 +890,184 @@ func (s *Scroll) Update(cfg unit.Metric, q event.Queue, t time.Time, axis Axis)
 				break
 			}
 			s.Stop()
+			// Catch the content where it settled to.
+			s.settle.active = false
 			s.estimator = fling.Extrapolation{}
 			v := s.val(e.Position)
 			s.last = int(math.Round(float64(v)))
 			s.estimator.Sample(e.Time, v)
 			s.dragging = true
 			s.pid = e.PointerID
+			s.start = e.Position
+			s.claim = claimNone
 		case pointer.Release:
 			if s.pid != e.PointerID {
 				break
 			}
 			fling := s.estimator.Estimate()
-			if slop, d := float32(cfg.Dp(touchSlop)), fling.Distance; d < -slop || d > slop {
+			if slop, d := float32(cfg.Dp(touchSlop(s.TouchSlop))), fling.Distance; s.claim != claimYield && s.over == 0 && (d < -slop || d > slop) {
+				s.flinger.Friction = s.Friction
 				s.flinger.Start(cfg, t, fling.Velocity)
 			}
 			fallthrough
 		case pointer.Cancel:
 			s.dragging = false
-			s.grab = false
+			s.claim = claimNone
+			s.settleBack(t, 0)
 		case pointer.Scroll:
-			switch s.axis {
-			case Horizontal:
-				s.scroll += e.Scroll.X
-			case Vertical:
-				s.scroll += e.Scroll.Y
+			d := e.Scroll.Y
+			if s.axis == Horizontal {
+				d = e.Scroll.X
 			}
-			iscroll := int(s.scroll)
-			s.scroll -= float32(iscroll)
-			total += iscroll
+			if e.ScrollMode != pointer.ScrollPixels {
+				if s.wheel.remaining == 0 {
+					s.wheel.t = t
+				}
+				s.wheel.remaining += d
+				break
+			}
+			total += s.step(d)
 		case pointer.Drag:
-			if !s.dragging || s.pid != e.PointerID {
+			if !s.dragging || s.pid != e.PointerID || s.claim == claimYield {
 				continue
 			}
+			for _, h := range e.History {
+				s.estimator.Sample(h.Time, s.val(h.Position))
+			}
 			val := s.val(e.Position)
 			s.estimator.Sample(e.Time, val)
 			v := int(math.Round(float64(val)))
-			dist := s.last - v
 			if e.Priority < pointer.Grabbed {
-				slop := cfg.Dp(touchSlop)
-				if dist := dist; dist >= slop || -slop >= dist {
-					s.grab = true
+				if s.claim == claimNone {
+					slop := float32(cfg.Dp(touchSlop(s.TouchSlop)))
+					s.claim = decideClaim(e.Position.Sub(s.start), slop, s.axis, e.Source)
 				}
 			} else {
+				total += s.absorb(s.last - v)
 				s.last = v
-				total += dist
 			}
 		}
 	}
+	total += s.tickWheel(t)
 	total += s.flinger.Tick(t)
+	s.tickSettle(t)
 	return total
 }
 
+// step adds d to the leftover scroll distance, and returns its whole
+// pixels.
+func (s *Scroll) step(d float32) int {
+	s.scroll += d
+	iscroll := int(s.scroll)
+	s.scroll -= float32(iscroll)
+	return iscroll
+}
+
+// tickWheel advances the wheel animation to t, and returns the distance
+// to scroll.
+func (s *Scroll) tickWheel(t time.Time) int {
+	if s.wheel.remaining == 0 {
+		return 0
+	}
+	dt := t.Sub(s.wheel.t)
+	s.wheel.t = t
+	f := 1 - math.Exp(-float64(dt)/float64(wheelTimeConstant))
+	d := s.wheel.remaining * float32(f)
+	if r := s.wheel.remaining - d; -1 < r && r < 1 {
+		d = s.wheel.remaining
+	}
+	s.wheel.remaining -= d
+	return s.step(d)
+}
+
+// Overflow reports the part d of the distance returned by Update that
+// went beyond the content, because it is at its start (d < 0) or end
+// (d > 0). If Overscroll is set, the distance is added to the
+// overscroll distance, ending any fling, and the following drag
+// distances towards the content reduce the overscroll before
+// scrolling. Overflow during other scrolling, such as by mouse wheel,
+// is ignored.
+func (s *Scroll) Overflow(d int) {
+	if !s.Overscroll || d == 0 {
+		return
+	}
+	switch {
+	case s.dragging:
+		s.over += float32(d)
+	case s.flinger.Active():
+		// Bounce with the velocity of the fling.
+		v := s.flinger.Velocity(s.now)
+		s.flinger = fling.Animation{}
+		s.over += float32(d)
+		s.settleBack(s.now, v)
+	}
+}
+
+// OverscrollDistance returns the distance, in pixels, the content is
+// scrolled beyond its start (negative) or end (positive). The distance
+// is resisted such that it approaches but never exceeds 120 dp. Lists
+// offset their content by the distance for bounce effects, or draw a
+// glow of corresponding strength.
+func (s *Scroll) OverscrollDistance() float32 {
+	x, m := s.over, s.overMax
+	if x == 0 || m == 0 {
+		return 0
+	}
+	sign := float32(1)
+	if x < 0 {
+		x, sign = -x, -1
+	}
+	// The rubber band curve of iOS.
+	const c = 0.55
+	return sign * (1 - 1/(x*c/m+1)) * m
+}
+
+// absorb applies the drag distance d to the overscroll distance, and
+// returns the remaining distance to scroll the content.
+func (s *Scroll) absorb(d int) int {
+	if s.over == 0 {
+		return d
+	}
+	over := s.over + float32(d)
+	if over*s.over > 0 {
+		s.over = over
+		return 0
+	}
+	s.over = 0
+	return int(over)
+}
+
+// settleBack starts animating the overscroll distance back to zero,
+// from the velocity v.
+func (s *Scroll) settleBack(t time.Time, v float32) {
+	if s.over == 0 {
+		return
+	}
+	s.settle.active = true
+	s.settle.t0, s.settle.x0, s.settle.v0 = t, s.over, v
+}
+
+// tickSettle advances the settle animation to t.
+func (s *Scroll) tickSettle(t time.Time) {
+	if !s.settle.active {
+		return
+	}
+	// A critically damped spring, whose position is given by
+	//
+	// x(t) = (x0 + (v0 + w*x0)*t)*e^(-w*t)
+	//
+	const w = 12
+	st := float32(t.Sub(s.settle.t0).Seconds())
+	x0, v0 := s.settle.x0, s.settle.v0
+	e := float32(math.Exp(float64(-w * st)))
+	x := (x0 + (v0+w*x0)*st) * e
+	v := (v0 - w*(v0+w*x0)*st) * e
+	if -0.5 < x && x < 0.5 && -thresholdSettleVelocity < v && v < thresholdSettleVelocity {
+		x, s.settle.active = 0, false
+	}
+	s.over = x
+}
+
 func (s *Scroll) val(p f32.Point) float32 {
 	if s.axis == Horizontal {
 		return p.X
@@ -353,7 +1079,7 @@ func (s *Scroll) val(p f32.Point) float32 {
 // State reports the scroll state.
 func (s *Scroll) State() ScrollState {
 	switch {
-	case s.flinger.Active():
+	case s.flinger.Active(), s.settle.active, s.wheel.remaining != 0:
 		return StateFlinging
 	case s.dragging:
 		return StateDragging
@@ -364,11 +1090,7 @@ func (s *Scroll) State() ScrollState {
 
 // Add the handler to the operation list to receive drag events.
 func (d *Drag) Add(ops *op.Ops) {
-	pointer.InputOp{
-		Tag:   d,
-		Grab:  d.grab,
-		Kinds: pointer.Press | pointer.Drag | pointer.Release,
-	}.Add(ops)
+	d.claim.inputOp(d, pointer.Press|pointer.Drag|pointer.Release, d.Precedence).Add(ops)
 }
 
 // Update state and return the drag events.
@@ -382,7 +1104,7 @@ func (d *Drag) Update(cfg unit.Metric, q event.Queue, axis Axis) []pointer.Event
 
 		switch e.Kind {
 		case pointer.Press:
-			if !(e.Buttons == pointer.ButtonPrimary || e.Source == pointer.Touch) {
+			if e.Buttons&pointer.ButtonPrimary == 0 && e.Source != pointer.Touch {
 				continue
 			}
 			d.pressed = true
@@ -390,13 +1112,42 @@ func (d *Drag) Update(cfg unit.Metric, q event.Queue, axis Axis) []pointer.Event
 				continue
 			}
 			d.dragging = true
+			d.started = false
 			d.pid = e.PointerID
 			d.start = e.Position
+			d.claim = claimNone
+			d.locked = Both
 		case pointer.Drag:
-			if !d.dragging || e.PointerID != d.pid {
+			if !d.dragging || e.PointerID != d.pid || d.claim == claimYield {
 				continue
 			}
-			switch axis {
+			delta := e.Position.Sub(d.start)
+			slop := float32(cfg.Dp(d.slop(e.Source)))
+			undecided := e.Priority < pointer.Grabbed && d.claim == claimNone
+			if undecided {
+				if d.claim = decideClaim(delta, slop, axis, e.Source); d.claim == claimYield {
+					continue
+				}
+				undecided = d.claim == claimNone
+			}
+			if d.LockAxis && axis == Both && d.locked == Both && delta.X*delta.X+delta.Y*delta.Y > slop*slop {
+				d.locked = Horizontal
+				if abs(delta.Y) > abs(delta.X) {
+					d.locked = Vertical
+				}
+			}
+			if !d.started && d.Threshold > 0 {
+				t := float32(cfg.Dp(d.Threshold))
+				if undecided || delta.X*delta.X+delta.Y*delta.Y <= t*t {
+					continue
+				}
+			}
+			d.started = true
+			ax := axis
+			if d.locked != Both {
+				ax = d.locked
+			}
+			switch ax {
 			case Horizontal:
 				e.Position.Y = d.start.Y
 			case Vertical:
@@ -404,20 +1155,13 @@ func (d *Drag) Update(cfg unit.Metric, q event.Queue, axis Axis) []pointer.Event
 			case Both:
 				// Do nothing
 			}
-			if e.Priority < pointer.Grabbed {
-				diff := e.Position.Sub(d.start)
-				slop := cfg.Dp(touchSlop)
-				if diff.X*diff.X+diff.Y*diff.Y > float32(slop*slop) {
-					d.grab = true
-				}
-			}
 		case pointer.Release, pointer.Cancel:
 			d.pressed = false
 			if !d.dragging || e.PointerID != d.pid {
 				continue
 			}
 			d.dragging = false
-			d.grab = false
+			d.claim = claimNone
 		}
 
 		events = append(events, e)
@@ -429,30 +1173,974 @@ func (d *Drag) Update(cfg unit.Metric, q event.Queue, axis Axis) []pointer.Event
 // Dragging reports whether it is currently in use.
 func (d *Drag) Dragging() bool { return d.dragging }
 
+// LockedAxis returns the axis the drag in progress is locked onto, or
+// Both if it isn't locked.
+func (d *Drag) LockedAxis() Axis {
+	if !d.dragging {
+		return Both
+	}
+	return d.locked
+}
+
 // Pressed returns whether a pointer is pressing.
 func (d *Drag) Pressed() bool { return d.pressed }
 
-func (a Axis) String() string {
-	switch a {
-	case Horizontal:
-		return "Horizontal"
-	case Vertical:
-		return "Vertical"
-	default:
-		panic("invalid Axis")
+// Add the handler to the operation list to receive drag events.
+func (d *MultiDrag) Add(ops *op.Ops) {
+	d.claim.inputOp(d, pointer.Press|pointer.Drag|pointer.Release, d.Precedence).Add(ops)
+}
+
+// Update state and return the drag events of all pointers. A
+// cancellation is reported as a Cancel event for each pressed pointer.
+func (d *MultiDrag) Update(cfg unit.Metric, q event.Queue, axis Axis) []pointer.Event {
+	var events []pointer.Event
+	for _, e := range q.Events(d) {
+		e, ok := e.(pointer.Event)
+		if !ok {
+			continue
+		}
+
+		switch e.Kind {
+		case pointer.Press:
+			if e.Buttons&pointer.ButtonPrimary == 0 && e.Source != pointer.Touch {
+				continue
+			}
+			if d.index(e.PointerID) != -1 {
+				continue
+			}
+			d.pointers = append(d.pointers, dragPointer{id: e.PointerID, start: e.Position})
+		case pointer.Drag:
+			i := d.index(e.PointerID)
+			if i == -1 {
+				continue
+			}
+			start := d.pointers[i].start
+			if e.Priority < pointer.Grabbed && d.claim == claimNone {
+				// Other pointers may still move along axis, so
+				// don't yield.
+				slop := float32(cfg.Dp(touchSlop(d.TouchSlop)))
+				if decideClaim(e.Position.Sub(start), slop, axis, e.Source) == claimGrab {
+					d.claim = claimGrab
+				}
+			}
+			switch axis {
+			case Horizontal:
+				e.Position.Y = start.Y
+			case Vertical:
+				e.Position.X = start.X
+			}
+		case pointer.Release:
+			i := d.index(e.PointerID)
+			if i == -1 {
+				continue
+			}
+			d.pointers = append(d.pointers[:i], d.pointers[i+1:]...)
+			if len(d.pointers) == 0 {
+				d.claim = claimNone
+			}
+		case pointer.Cancel:
+			for _, p := range d.pointers {
+				c := e
+				c.PointerID = p.id
+				events = append(events, c)
+			}
+			d.pointers = d.pointers[:0]
+			d.claim = claimNone
+			continue
+		}
+
+		events = append(events, e)
 	}
+
+	return events
 }
 
-func (ct ClickKind) String() string {
-	switch ct {
-	case KindPress:
-		return "TypePress"
-	case KindClick:
-		return "TypeClick"
-	case KindCancel:
-		return "TypeCancel"
-	default:
-		panic("invalid ClickType")
+func (d *MultiDrag) index(id pointer.ID) int {
+	for i, p := range d.pointers {
+		if p.id == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// Dragging reports the number of pressed pointers.
+func (d *MultiDrag) Dragging() int { return len(d.pointers) }
+
+// Add the handler to the operation list to receive pinch events.
+func (p *Pinch) Add(ops *op.Ops) {
+	p.claim.inputOp(p, pointer.Press|pointer.Drag|pointer.Release|pointer.Pinch|pointer.Rotate, p.Precedence).Add(ops)
+}
+
+// Update state and return the pinch events.
+func (p *Pinch) Update(cfg unit.Metric, q event.Queue) []PinchEvent {
+	var events []PinchEvent
+	p.rotations = p.rotations[:0]
+	for _, e := range q.Events(p) {
+		e, ok := e.(pointer.Event)
+		if !ok {
+			continue
+		}
+		switch e.Kind {
+		case pointer.Press:
+			pairs := p.pair.pairs
+			p.pair.update(e)
+			if p.pair.pairs != pairs {
+				p.dist, p.centroid = p.pair.measure()
+			}
+		case pointer.Drag:
+			if !p.pair.update(e) {
+				continue
+			}
+			dist, centroid := p.pair.measure()
+			if !p.pinching {
+				slop := float32(cfg.Dp(touchSlop(p.TouchSlop)))
+				if d := dist - p.dist; d*d <= slop*slop {
+					continue
+				}
+				p.pinching = true
+				p.claim = claimGrab
+			}
+			scale := float32(1)
+			if p.dist > 0 {
+				scale = dist / p.dist
+			}
+			events = append(events, PinchEvent{
+				Scale:    scale,
+				Centroid: centroid,
+				Pan:      centroid.Sub(p.centroid),
+			})
+			p.dist, p.centroid = dist, centroid
+		case pointer.Release, pointer.Cancel:
+			if e.Kind == pointer.Release && p.pair.index(e.PointerID) == -1 {
+				continue
+			}
+			p.pair.update(e)
+			p.pinching = false
+			p.claim = claimNone
+		case pointer.Pinch:
+			events = append(events, PinchEvent{
+				Scale:    1 + e.Zoom,
+				Centroid: e.Position,
+			})
+		case pointer.Rotate:
+			p.rotations = append(p.rotations, e)
+		}
+	}
+	return events
+}
+
+func (PinchEvent) ImplementsEvent() {}
+
+// Pinching reports whether a pinch gesture is in progress.
+func (p *Pinch) Pinching() bool { return p.pinching }
+
+// Add the handler to the operation list to receive rotate events. Add
+// does nothing if the Rotate follows a Pinch.
+func (r *Rotate) Add(ops *op.Ops) {
+	if r.Pinch != nil {
+		return
+	}
+	r.claim.inputOp(r, pointer.Press|pointer.Drag|pointer.Release|pointer.Rotate, r.Precedence).Add(ops)
+}
+
+// Update state and return the rotate events.
+func (r *Rotate) Update(cfg unit.Metric, q event.Queue) []RotateEvent {
+	var events []RotateEvent
+	if p := r.Pinch; p != nil {
+		if e, ok := r.follow(cfg, &p.pair); ok {
+			events = append(events, e)
+			// Keep the pointers from the handlers behind the Pinch.
+			p.claim = claimGrab
+		}
+		for _, e := range p.rotations {
+			events = append(events, RotateEvent{Angle: e.Rotation, Pivot: e.Position})
+		}
+		return events
+	}
+	for _, e := range q.Events(r) {
+		e, ok := e.(pointer.Event)
+		if !ok {
+			continue
+		}
+		switch e.Kind {
+		case pointer.Press, pointer.Drag, pointer.Release, pointer.Cancel:
+			r.pair.update(e)
+			if e, ok := r.follow(cfg, &r.pair); ok {
+				events = append(events, e)
+			}
+			r.claim = claimNone
+			if r.rotating {
+				r.claim = claimGrab
+			}
+		case pointer.Rotate:
+			events = append(events, RotateEvent{Angle: e.Rotation, Pivot: e.Position})
+		}
+	}
+	return events
+}
+
+// follow updates the rotation from the pointers of pair, and returns the
+// change since the last RotateEvent, if any.
+func (r *Rotate) follow(cfg unit.Metric, pair *pointerPair) (RotateEvent, bool) {
+	if len(pair.pointers) < 2 {
+		r.rotating = false
+		return RotateEvent{}, false
+	}
+	if pair.pairs != r.pairs {
+		// A new gesture.
+		r.pairs = pair.pairs
+		r.rotating = false
+		r.angle = pair.angle()
+	}
+	angle := pair.angle()
+	delta := angle - r.angle
+	// Take the shorter way around.
+	if delta > math.Pi {
+		delta -= 2 * math.Pi
+	} else if delta < -math.Pi {
+		delta += 2 * math.Pi
+	}
+	dist, pivot := pair.measure()
+	if !r.rotating {
+		// Compare the distance the pointers moved along the circle
+		// to the touch slop.
+		slop := float32(cfg.Dp(touchSlop(r.TouchSlop)))
+		if arc := delta * dist * .5; arc*arc <= slop*slop {
+			return RotateEvent{}, false
+		}
+		r.rotating = true
+	}
+	if delta == 0 {
+		return RotateEvent{}, false
+	}
+	r.angle = angle
+	return RotateEvent{Angle: delta, Pivot: pivot}, true
+}
+
+func (RotateEvent) ImplementsEvent() {}
+
+// Rotating reports whether a rotation gesture is in progress.
+func (r *Rotate) Rotating() bool { return r.rotating }
+
+// Add the handler to the operation list to receive long press events.
+func (l *LongPress) Add(ops *op.Ops) {
+	l.claim.inputOp(l, pointer.Press|pointer.Drag|pointer.Release, l.Precedence).Add(ops)
+	if l.pressed && !l.triggered {
+		op.InvalidateOp{At: l.deadline}.Add(ops)
+	}
+}
+
+// Update state and return the long press events. The frame time t
+// decides whether a press was held for the duration.
+func (l *LongPress) Update(cfg unit.Metric, q event.Queue, t time.Time) []LongPressEvent {
+	var events []LongPressEvent
+	for _, evt := range q.Events(l) {
+		e, ok := evt.(pointer.Event)
+		if !ok {
+			continue
+		}
+		switch e.Kind {
+		case pointer.Press:
+			if l.pressed {
+				break
+			}
+			if e.Source == pointer.Mouse && e.Buttons != pointer.ButtonPrimary {
+				break
+			}
+			d := l.Duration
+			if d == 0 {
+				d = defaultLongPressDuration
+			}
+			l.pressed, l.triggered = true, false
+			l.claim = claimNone
+			l.pid, l.source, l.modifiers = e.PointerID, e.Source, e.Modifiers
+			l.start, l.pos = e.Position, e.Position
+			l.deadline = t.Add(d)
+			events = append(events, l.event(LongPressPressed))
+		case pointer.Drag:
+			if !l.pressed || l.pid != e.PointerID {
+				break
+			}
+			l.pos = e.Position
+			if l.triggered {
+				break
+			}
+			slop := l.Slop
+			if slop == 0 {
+				slop = defaultLongPressSlop
+			}
+			d, s := e.Position.Sub(l.start), float32(cfg.Dp(slop))
+			if d.X*d.X+d.Y*d.Y > s*s {
+				// Leave the pointer to the other gestures.
+				l.pressed, l.claim = false, claimYield
+				events = append(events, l.event(LongPressCancelled))
+			}
+		case pointer.Release, pointer.Cancel:
+			if !l.pressed || e.Kind == pointer.Release && l.pid != e.PointerID {
+				if e.Kind == pointer.Cancel {
+					l.claim = claimNone
+				}
+				break
+			}
+			l.pressed, l.claim = false, claimNone
+			if !l.triggered {
+				events = append(events, l.event(LongPressCancelled))
+			}
+		}
+	}
+	if l.pressed && !l.triggered && !t.Before(l.deadline) {
+		l.triggered, l.claim = true, claimGrab
+		events = append(events, l.event(LongPressTriggered))
+	}
+	return events
+}
+
+func (l *LongPress) event(kind LongPressKind) LongPressEvent {
+	return LongPressEvent{
+		Kind:      kind,
+		Position:  l.pos.Round(),
+		Source:    l.source,
+		Modifiers: l.modifiers,
+	}
+}
+
+// Pressed reports whether a pointer is held, including after the press
+// triggered.
+func (l *LongPress) Pressed() bool { return l.pressed }
+
+func (LongPressEvent) ImplementsEvent() {}
+
+// Add the handler to the operation list to receive force press events.
+func (f *ForcePress) Add(ops *op.Ops) {
+	f.claim.inputOp(f, pointer.Press|pointer.Drag|pointer.Release, f.Precedence).Add(ops)
+}
+
+// Update state and return the force press events.
+func (f *ForcePress) Update(cfg unit.Metric, q event.Queue) []ForcePressEvent {
+	var events []ForcePressEvent
+	for _, evt := range q.Events(f) {
+		e, ok := evt.(pointer.Event)
+		if !ok {
+			continue
+		}
+		switch e.Kind {
+		case pointer.Press:
+			if f.pressed {
+				break
+			}
+			if e.Source == pointer.Mouse && e.Buttons != pointer.ButtonPrimary {
+				break
+			}
+			f.pressed, f.deep = true, false
+			f.claim = claimNone
+			f.pid, f.source, f.modifiers = e.PointerID, e.Source, e.Modifiers
+			f.start, f.pos = e.Position, e.Position
+			f.pressure = e.Pressure
+			events = append(events, f.event(ForcePressStart))
+			events = f.checkDeep(events)
+		case pointer.Drag:
+			if !f.pressed || f.pid != e.PointerID {
+				break
+			}
+			f.pos = e.Position
+			if !f.deep {
+				slop := f.Slop
+				if slop == 0 {
+					slop = defaultLongPressSlop
+				}
+				d, s := e.Position.Sub(f.start), float32(cfg.Dp(slop))
+				if d.X*d.X+d.Y*d.Y > s*s {
+					// Leave the pointer to the other gestures.
+					f.pressed, f.claim = false, claimYield
+					events = append(events, f.event(ForcePressCancel))
+					break
+				}
+			}
+			if e.Pressure != f.pressure {
+				f.pressure = e.Pressure
+				events = append(events, f.event(ForcePressChange))
+				events = f.checkDeep(events)
+			}
+		case pointer.Release, pointer.Cancel:
+			if !f.pressed || e.Kind == pointer.Release && f.pid != e.PointerID {
+				if e.Kind == pointer.Cancel {
+					f.claim = claimNone
+				}
+				break
+			}
+			f.pressed, f.claim = false, claimNone
+			kind := ForcePressEnd
+			if e.Kind == pointer.Cancel {
+				kind = ForcePressCancel
+			}
+			f.pressure = 0
+			events = append(events, f.event(kind))
+		}
+	}
+	return events
+}
+
+// checkDeep appends a ForcePressDeep event to events if the pressure
+// reached the threshold.
+func (f *ForcePress) checkDeep(events []ForcePressEvent) []ForcePressEvent {
+	threshold := f.Threshold
+	if threshold == 0 {
+		threshold = defaultForcePressThreshold
+	}
+	if f.deep || f.pressure < threshold {
+		return events
+	}
+	f.deep, f.claim = true, claimGrab
+	return append(events, f.event(ForcePressDeep))
+}
+
+func (f *ForcePress) event(kind ForcePressKind) ForcePressEvent {
+	return ForcePressEvent{
+		Kind:      kind,
+		Pressure:  f.pressure,
+		Position:  f.pos.Round(),
+		Source:    f.source,
+		Modifiers: f.modifiers,
+	}
+}
+
+// Pressed reports whether a pointer is pressed.
+func (f *ForcePress) Pressed() bool { return f.pressed }
+
+// Deep reports whether the pressed pointer is pressed deeply.
+func (f *ForcePress) Deep() bool { return f.pressed && f.deep }
+
+// Pressure returns the pressure of the pressed pointer.
+func (f *ForcePress) Pressure() float32 { return f.pressure }
+
+func (ForcePressEvent) ImplementsEvent() {}
+
+// Add the handler to the operation list to receive swipe events.
+func (s *Swipe) Add(ops *op.Ops) {
+	pointer.InputOp{
+		Tag:   s,
+		Kinds: pointer.Press | pointer.Drag | pointer.Release,
+	}.Add(ops)
+}
+
+// Update state and return the swipe events.
+func (s *Swipe) Update(cfg unit.Metric, q event.Queue) []SwipeEvent {
+	var events []SwipeEvent
+	for _, evt := range q.Events(s) {
+		e, ok := evt.(pointer.Event)
+		if !ok {
+			continue
+		}
+		switch e.Kind {
+		case pointer.Press:
+			if s.pressed {
+				break
+			}
+			if e.Source == pointer.Mouse && e.Buttons != pointer.ButtonPrimary {
+				break
+			}
+			s.pressed = true
+			s.pid = e.PointerID
+			s.start = e.Position
+			s.xest, s.yest = fling.Extrapolation{}, fling.Extrapolation{}
+			s.xest.Sample(e.Time, e.Position.X)
+			s.yest.Sample(e.Time, e.Position.Y)
+		case pointer.Drag:
+			if !s.pressed || s.pid != e.PointerID {
+				break
+			}
+			for _, h := range e.History {
+				s.xest.Sample(h.Time, h.Position.X)
+				s.yest.Sample(h.Time, h.Position.Y)
+			}
+			s.xest.Sample(e.Time, e.Position.X)
+			s.yest.Sample(e.Time, e.Position.Y)
+		case pointer.Release:
+			if !s.pressed || s.pid != e.PointerID {
+				break
+			}
+			s.pressed = false
+			s.xest.Sample(e.Time, e.Position.X)
+			s.yest.Sample(e.Time, e.Position.Y)
+			if sw, ok := s.flick(cfg, e); ok {
+				events = append(events, sw)
+			}
+		case pointer.Cancel:
+			s.pressed = false
+		}
+	}
+	return events
+}
+
+// flick returns the flick that ended with the release e, if any.
+func (s *Swipe) flick(cfg unit.Metric, e pointer.Event) (SwipeEvent, bool) {
+	d := e.Position.Sub(s.start)
+	// The estimated velocities are negated.
+	v := f32.Pt(-s.xest.Estimate().Velocity, -s.yest.Estimate().Velocity)
+	var sw SwipeEvent
+	if abs(d.X) > abs(d.Y) {
+		sw.Direction, sw.Distance, sw.Velocity = SwipeRight, d.X, v.X
+		if d.X < 0 {
+			sw.Direction, sw.Distance, sw.Velocity = SwipeLeft, -d.X, -v.X
+		}
+	} else {
+		sw.Direction, sw.Distance, sw.Velocity = SwipeDown, d.Y, v.Y
+		if d.Y < 0 {
+			sw.Direction, sw.Distance, sw.Velocity = SwipeUp, -d.Y, -v.Y
+		}
+	}
+	minDist, minVel := s.MinDistance, s.MinVelocity
+	if minDist == 0 {
+		minDist = defaultSwipeDistance
+	}
+	if minVel == 0 {
+		minVel = defaultSwipeVelocity
+	}
+	if sw.Distance < float32(cfg.Dp(minDist)) || sw.Velocity < float32(cfg.Dp(minVel)) {
+		return SwipeEvent{}, false
+	}
+	sw.Position = s.start.Round()
+	sw.Source = e.Source
+	return sw, true
+}
+
+// Pressed reports whether a pointer is pressed.
+func (s *Swipe) Pressed() bool { return s.pressed }
+
+func (SwipeEvent) ImplementsEvent() {}
+
+// Add the handler to the operation list to receive edge swipe events.
+func (s *EdgeSwipe) Add(ops *op.Ops) {
+	s.claim.inputOp(s, pointer.Press|pointer.Drag|pointer.Release, s.Precedence).Add(ops)
+}
+
+// Update state and return the edge swipe events. The size is the size
+// of the area, which locates the edge.
+func (s *EdgeSwipe) Update(cfg unit.Metric, q event.Queue, size image.Point) []EdgeSwipeEvent {
+	var events []EdgeSwipeEvent
+	for _, evt := range q.Events(s) {
+		e, ok := evt.(pointer.Event)
+		if !ok {
+			continue
+		}
+		switch e.Kind {
+		case pointer.Press:
+			if s.pressed {
+				break
+			}
+			if e.Source == pointer.Mouse && e.Buttons != pointer.ButtonPrimary {
+				break
+			}
+			width := defaultEdgeSwipeWidth
+			if s.Width != 0 {
+				width = s.Width
+			}
+			if s.dist(size, e.Position) >= float32(cfg.Dp(width)) {
+				break
+			}
+			s.pressed = true
+			s.pid = e.PointerID
+			s.start = e.Position
+			s.progress = 0
+			s.est = fling.Extrapolation{}
+			s.est.Sample(e.Time, s.dist(size, e.Position))
+		case pointer.Drag:
+			if !s.pressed || s.pid != e.PointerID || s.claim == claimYield {
+				break
+			}
+			for _, h := range e.History {
+				s.est.Sample(h.Time, s.dist(size, h.Position))
+			}
+			d := s.dist(size, e.Position)
+			s.est.Sample(e.Time, d)
+			kind := EdgeSwipeMove
+			if !s.swiping {
+				slop := float32(cfg.Dp(touchSlop(s.TouchSlop)))
+				s.claim = decideClaim(e.Position.Sub(s.start), slop, s.axis(), e.Source)
+				if s.claim == claimGrab && d < s.dist(size, s.start) {
+					// Moving towards the edge.
+					s.claim = claimYield
+				}
+				if s.claim != claimGrab {
+					break
+				}
+				s.swiping = true
+				kind = EdgeSwipeStart
+			}
+			s.progress = s.progressAt(cfg, size, d)
+			events = append(events, EdgeSwipeEvent{Kind: kind, Progress: s.progress, Source: e.Source})
+		case pointer.Release:
+			if !s.pressed || s.pid != e.PointerID {
+				break
+			}
+			if s.swiping {
+				d := s.dist(size, e.Position)
+				s.est.Sample(e.Time, d)
+				s.progress = s.progressAt(cfg, size, d)
+				// The estimated velocity is negated.
+				v := -s.est.Estimate().Velocity
+				threshold := float32(defaultEdgeSwipeThreshold)
+				if s.Threshold != 0 {
+					threshold = s.Threshold
+				}
+				trigger := s.progress >= threshold
+				if minVel := float32(cfg.Dp(defaultSwipeVelocity)); v >= minVel || v <= -minVel {
+					trigger = v > 0
+				}
+				kind := EdgeSwipeCancel
+				if trigger {
+					kind = EdgeSwipeTrigger
+				}
+				events = append(events, EdgeSwipeEvent{Kind: kind, Progress: s.progress, Velocity: v, Source: e.Source})
+			}
+			s.pressed, s.swiping, s.claim = false, false, claimNone
+		case pointer.Cancel:
+			if s.swiping {
+				events = append(events, EdgeSwipeEvent{Kind: EdgeSwipeCancel, Progress: s.progress, Source: e.Source})
+			}
+			s.pressed, s.swiping, s.claim = false, false, claimNone
+		}
+	}
+	return events
+}
+
+// dist returns the distance of p from the edge of an area of size.
+func (s *EdgeSwipe) dist(size image.Point, p f32.Point) float32 {
+	switch s.Edge {
+	case EdgeTop:
+		return p.Y
+	case EdgeRight:
+		return float32(size.X) - p.X
+	case EdgeBottom:
+		return float32(size.Y) - p.Y
+	default:
+		return p.X
+	}
+}
+
+// progressAt returns the progress of the pointer at distance d from
+// the edge.
+func (s *EdgeSwipe) progressAt(cfg unit.Metric, size image.Point, d float32) float32 {
+	full := float32(cfg.Dp(s.Distance))
+	if s.Distance == 0 {
+		full = float32(size.X)
+		if s.axis() == Vertical {
+			full = float32(size.Y)
+		}
+	}
+	if full <= 0 {
+		return 0
+	}
+	p := (d - s.dist(size, s.start)) / full
+	if p < 0 {
+		p = 0
+	}
+	if p > 1 {
+		p = 1
+	}
+	return p
+}
+
+func (s *EdgeSwipe) axis() Axis {
+	if s.Edge == EdgeTop || s.Edge == EdgeBottom {
+		return Vertical
+	}
+	return Horizontal
+}
+
+// Swiping reports whether an edge swipe is in progress.
+func (s *EdgeSwipe) Swiping() bool { return s.swiping }
+
+// Progress returns the progress of the swipe in progress, or of the
+// last swipe.
+func (s *EdgeSwipe) Progress() float32 { return s.progress }
+
+func (EdgeSwipeEvent) ImplementsEvent() {}
+
+// Add the handler to the operation list to receive pan events.
+func (p *Pan) Add(ops *op.Ops) {
+	p.claim.inputOp(p, pointer.Press|pointer.Drag|pointer.Release, p.Precedence).Add(ops)
+}
+
+// Update state and return the pan events.
+func (p *Pan) Update(cfg unit.Metric, q event.Queue) []PanEvent {
+	var events []PanEvent
+	for _, evt := range q.Events(p) {
+		e, ok := evt.(pointer.Event)
+		if !ok {
+			continue
+		}
+		switch e.Kind {
+		case pointer.Press:
+			if p.pressed {
+				break
+			}
+			if e.Source == pointer.Mouse && e.Buttons != pointer.ButtonPrimary {
+				break
+			}
+			p.pressed = true
+			p.pid = e.PointerID
+			p.last = e.Position
+			p.xest, p.yest = fling.Extrapolation{}, fling.Extrapolation{}
+			p.xest.Sample(e.Time, e.Position.X)
+			p.yest.Sample(e.Time, e.Position.Y)
+		case pointer.Drag:
+			if !p.pressed || p.pid != e.PointerID {
+				break
+			}
+			for _, h := range e.History {
+				p.xest.Sample(h.Time, h.Position.X)
+				p.yest.Sample(h.Time, h.Position.Y)
+			}
+			p.xest.Sample(e.Time, e.Position.X)
+			p.yest.Sample(e.Time, e.Position.Y)
+			kind := PanMove
+			if !p.panning {
+				d := e.Position.Sub(p.last)
+				slop := float32(cfg.Dp(touchSlop(p.TouchSlop)))
+				if d.X*d.X+d.Y*d.Y <= slop*slop {
+					break
+				}
+				p.panning, p.claim = true, claimGrab
+				kind = PanStart
+			}
+			events = append(events, p.event(kind, e))
+		case pointer.Release:
+			if !p.pressed || p.pid != e.PointerID {
+				break
+			}
+			p.xest.Sample(e.Time, e.Position.X)
+			p.yest.Sample(e.Time, e.Position.Y)
+			if p.panning {
+				pe := p.event(PanEnd, e)
+				// The estimated velocities are negated.
+				pe.Velocity = f32.Pt(-p.xest.Estimate().Velocity, -p.yest.Estimate().Velocity)
+				events = append(events, pe)
+			}
+			p.pressed, p.panning, p.claim = false, false, claimNone
+		case pointer.Cancel:
+			if p.panning {
+				events = append(events, PanEvent{Kind: PanCancel, Position: p.last, Source: e.Source})
+			}
+			p.pressed, p.panning, p.claim = false, false, claimNone
+		}
+	}
+	return events
+}
+
+// event returns a PanEvent for the pointer event e, and updates the
+// last position.
+func (p *Pan) event(kind PanKind, e pointer.Event) PanEvent {
+	pe := PanEvent{
+		Kind:     kind,
+		Delta:    e.Position.Sub(p.last),
+		Position: e.Position,
+		Source:   e.Source,
+	}
+	p.last = e.Position
+	return pe
+}
+
+// Panning reports whether a pan gesture is in progress.
+func (p *Pan) Panning() bool { return p.panning }
+
+// Pressed reports whether a pointer is pressed.
+func (p *Pan) Pressed() bool { return p.pressed }
+
+func (PanEvent) ImplementsEvent() {}
+
+// slop returns the slop of the drag for pointers of src.
+func (d *Drag) slop(src pointer.Source) unit.Dp {
+	if src == pointer.Mouse && d.MouseSlop != 0 {
+		return d.MouseSlop
+	}
+	return touchSlop(d.TouchSlop)
+}
+
+// touchSlop returns slop, or the default touch slop if slop is zero.
+func touchSlop(slop unit.Dp) unit.Dp {
+	if slop == 0 {
+		return defaultTouchSlop
+	}
+	return slop
+}
+
+func abs(v float32) float32 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// update tracks the pointer of e, and reports whether a pointer of a
+// pair moved.
+func (t *pointerPair) update(e pointer.Event) bool {
+	switch e.Kind {
+	case pointer.Press:
+		if e.Source != pointer.Touch || len(t.pointers) == 2 {
+			break
+		}
+		t.pointers = append(t.pointers, pairPointer{id: e.PointerID, pos: e.Position})
+		if len(t.pointers) == 2 {
+			t.pairs++
+		}
+	case pointer.Drag:
+		i := t.index(e.PointerID)
+		if i == -1 {
+			break
+		}
+		t.pointers[i].pos = e.Position
+		return len(t.pointers) == 2
+	case pointer.Release:
+		if i := t.index(e.PointerID); i != -1 {
+			t.pointers = append(t.pointers[:i], t.pointers[i+1:]...)
+		}
+	case pointer.Cancel:
+		t.pointers = t.pointers[:0]
+	}
+	return false
+}
+
+// index returns the index of the pointer with the id, or -1.
+func (t *pointerPair) index(id pointer.ID) int {
+	for i, ptr := range t.pointers {
+		if ptr.id == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// measure returns the distance between and the midpoint of the two
+// pointers.
+func (t *pointerPair) measure() (float32, f32.Point) {
+	a, b := t.pointers[0].pos, t.pointers[1].pos
+	d := b.Sub(a)
+	dist := float32(math.Hypot(float64(d.X), float64(d.Y)))
+	return dist, a.Add(b).Mul(.5)
+}
+
+// angle returns the angle of the line from the first to the second
+// pointer.
+func (t *pointerPair) angle() float32 {
+	d := t.pointers[1].pos.Sub(t.pointers[0].pos)
+	return float32(math.Atan2(float64(d.Y), float64(d.X)))
+}
+
+func (a Axis) String() string {
+	switch a {
+	case Horizontal:
+		return "Horizontal"
+	case Vertical:
+		return "Vertical"
+	case Both:
+		return "Both"
+	default:
+		panic("invalid Axis")
+	}
+}
+
+func (ct ClickKind) String() string {
+	switch ct {
+	case KindPress:
+		return "TypePress"
+	case KindClick:
+		return "TypeClick"
+	case KindCancel:
+		return "TypeCancel"
+	default:
+		panic("invalid ClickType")
+	}
+}
+
+func (k LongPressKind) String() string {
+	switch k {
+	case LongPressPressed:
+		return "LongPressPressed"
+	case LongPressTriggered:
+		return "LongPressTriggered"
+	case LongPressCancelled:
+		return "LongPressCancelled"
+	default:
+		panic("invalid LongPressKind")
+	}
+}
+
+func (k ForcePressKind) String() string {
+	switch k {
+	case ForcePressStart:
+		return "ForcePressStart"
+	case ForcePressChange:
+		return "ForcePressChange"
+	case ForcePressDeep:
+		return "ForcePressDeep"
+	case ForcePressEnd:
+		return "ForcePressEnd"
+	case ForcePressCancel:
+		return "ForcePressCancel"
+	default:
+		panic("invalid ForcePressKind")
+	}
+}
+
+func (d SwipeDirection) String() string {
+	switch d {
+	case SwipeUp:
+		return "SwipeUp"
+	case SwipeDown:
+		return "SwipeDown"
+	case SwipeLeft:
+		return "SwipeLeft"
+	case SwipeRight:
+		return "SwipeRight"
+	default:
+		panic("invalid SwipeDirection")
+	}
+}
+
+func (k EdgeSwipeKind) String() string {
+	switch k {
+	case EdgeSwipeStart:
+		return "EdgeSwipeStart"
+	case EdgeSwipeMove:
+		return "EdgeSwipeMove"
+	case EdgeSwipeTrigger:
+		return "EdgeSwipeTrigger"
+	case EdgeSwipeCancel:
+		return "EdgeSwipeCancel"
+	default:
+		panic("invalid EdgeSwipeKind")
+	}
+}
+
+func (e Edge) String() string {
+	switch e {
+	case EdgeLeft:
+		return "EdgeLeft"
+	case EdgeTop:
+		return "EdgeTop"
+	case EdgeRight:
+		return "EdgeRight"
+	case EdgeBottom:
+		return "EdgeBottom"
+	default:
+		panic("invalid Edge")
+	}
+}
+
+func (k PanKind) String() string {
+	switch k {
+	case PanStart:
+		return "PanStart"
+	case PanMove:
+		return "PanMove"
+	case PanEnd:
+		return "PanEnd"
+	case PanCancel:
+		return "PanCancel"
+	default:
+		panic("invalid PanKind")
 	}
 }
 
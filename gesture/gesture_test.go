@@ -4,6 +4,8 @@ package gesture
 
 import (
 	"image"
+	"math"
+	"reflect"
 	"testing"
 	"time"
 
@@ -13,6 +15,7 @@ import (
 	"github.com/Seikaijyu/gio/io/router"
 	"github.com/Seikaijyu/gio/op"
 	"github.com/Seikaijyu/gio/op/clip"
+	"github.com/Seikaijyu/gio/unit"
 )
 
 func TestHover(t *testing.T) {
@@ -40,11 +43,88 @@ func TestHover(t *testing.T) {
 	}
 }
 
+func TestHoverTrackPosition(t *testing.T) {
+	for _, track := range []bool{false, true} {
+		ops := new(op.Ops)
+		h := Hover{TrackPosition: track}
+		stack := clip.Rect(image.Rect(20, 20, 40, 40)).Push(ops)
+		h.Add(ops)
+		stack.Pop()
+		r := new(router.Router)
+		r.Frame(ops)
+		r.Queue(
+			pointer.Event{Kind: pointer.Move, Position: f32.Pt(30, 30)},
+			pointer.Event{Kind: pointer.Move, Position: f32.Pt(32, 25)},
+		)
+		if !h.Update(r) {
+			t.Fatal("expected hovered")
+		}
+		want := f32.Pt(30, 30)
+		if track {
+			want = f32.Pt(32, 25)
+		}
+		if got := h.Position(); got != want {
+			t.Errorf("TrackPosition %v: position is %v, want %v", track, got, want)
+		}
+	}
+}
+
+func TestHoverDelayed(t *testing.T) {
+	ops := new(op.Ops)
+	h := Hover{EnterDelay: 500 * time.Millisecond, ExitDelay: 100 * time.Millisecond}
+	stack := clip.Rect(image.Rect(20, 20, 40, 40)).Push(ops)
+	h.Add(ops)
+	stack.Pop()
+	r := new(router.Router)
+	r.Frame(ops)
+
+	start := time.Now()
+	r.Queue(pointer.Event{Kind: pointer.Move, Position: f32.Pt(30, 30)})
+	if h.UpdateDelayed(r, start) {
+		t.Fatal("hovered before the enter delay")
+	}
+	r.Queue(pointer.Event{Kind: pointer.Move, Position: f32.Pt(32, 25)})
+	if h.UpdateDelayed(r, start.Add(400*time.Millisecond)) {
+		t.Fatal("hovered before the enter delay")
+	}
+	if got, want := h.Position(), f32.Pt(32, 25); got != want {
+		t.Errorf("position is %v, want %v", got, want)
+	}
+	ops.Reset()
+	stack = clip.Rect(image.Rect(20, 20, 40, 40)).Push(ops)
+	h.Add(ops)
+	stack.Pop()
+	r.Frame(ops)
+	if at, ok := r.WakeupTime(); !ok || !at.Equal(start.Add(500*time.Millisecond)) {
+		t.Errorf("wakeup at %v, want the end of the enter delay", at)
+	}
+	if !h.UpdateDelayed(r, start.Add(500*time.Millisecond)) {
+		t.Fatal("not hovered after the enter delay")
+	}
+
+	// Leaving briefly keeps the hover.
+	r.Queue(pointer.Event{Kind: pointer.Move, Position: f32.Pt(50, 50)})
+	if !h.UpdateDelayed(r, start.Add(550*time.Millisecond)) {
+		t.Fatal("not hovered during the exit delay")
+	}
+	r.Queue(pointer.Event{Kind: pointer.Move, Position: f32.Pt(30, 30)})
+	if !h.UpdateDelayed(r, start.Add(700*time.Millisecond)) {
+		t.Fatal("not hovered after re-entering")
+	}
+	r.Queue(pointer.Event{Kind: pointer.Move, Position: f32.Pt(50, 50)})
+	h.UpdateDelayed(r, start.Add(800*time.Millisecond))
+	if h.UpdateDelayed(r, start.Add(900*time.Millisecond)) {
+		t.Fatal("hovered after the exit delay")
+	}
+}
+
 func TestMouseClicks(t *testing.T) {
 	for _, tc := range []struct {
 		label  string
 		events []event.Event
 		clicks []int // number of combined clicks per click (single, double...)
+		// duration is the Click.DoubleClickDuration.
+		duration time.Duration
 	}{
 		{
 			label:  "single click",
@@ -55,19 +135,27 @@ func TestMouseClicks(t *testing.T) {
 			label: "double click",
 			events: mouseClickEvents(
 				100*time.Millisecond,
-				100*time.Millisecond+doubleClickDuration-1),
+				100*time.Millisecond+defaultDoubleClickDuration-1),
 			clicks: []int{1, 2},
 		},
 		{
 			label: "two single clicks",
 			events: mouseClickEvents(
 				100*time.Millisecond,
-				100*time.Millisecond+doubleClickDuration+1),
+				100*time.Millisecond+defaultDoubleClickDuration+1),
 			clicks: []int{1, 1},
 		},
+		{
+			label: "slow double click",
+			events: mouseClickEvents(
+				100*time.Millisecond,
+				600*time.Millisecond),
+			clicks:   []int{1, 2},
+			duration: time.Second,
+		},
 	} {
 		t.Run(tc.label, func(t *testing.T) {
-			var click Click
+			click := Click{DoubleClickDuration: tc.duration}
 			var ops op.Ops
 			click.Add(&ops)
 
@@ -90,6 +178,150 @@ func TestMouseClicks(t *testing.T) {
 	}
 }
 
+func TestClickButtons(t *testing.T) {
+	click := Click{Buttons: pointer.ButtonSecondary | pointer.ButtonTertiary}
+	var ops op.Ops
+	click.Add(&ops)
+	var r router.Router
+	r.Frame(&ops)
+	mouse := func(kind pointer.Kind, btns pointer.Buttons) pointer.Event {
+		return pointer.Event{Kind: kind, Source: pointer.Mouse, Buttons: btns}
+	}
+	r.Queue(
+		// Ignored button.
+		mouse(pointer.Press, pointer.ButtonPrimary),
+		mouse(pointer.Release, pointer.ButtonPrimary),
+		mouse(pointer.Press, pointer.ButtonSecondary),
+		mouse(pointer.Release, pointer.ButtonSecondary),
+		mouse(pointer.Press, pointer.ButtonTertiary),
+		mouse(pointer.Release, pointer.ButtonTertiary),
+	)
+	clicks := filterMouseClicks(click.Update(&r))
+	if len(clicks) != 2 {
+		t.Fatalf("got %d clicks, want 2", len(clicks))
+	}
+	if b := clicks[0].Button; b != pointer.ButtonSecondary {
+		t.Errorf("got first click of %v, want ButtonSecondary", b)
+	}
+	// Clicks of different buttons don't combine.
+	if c := clicks[1]; c.Button != pointer.ButtonTertiary || c.NumClicks != 1 {
+		t.Errorf("got second click %+v, want a single click of ButtonTertiary", c)
+	}
+}
+
+func TestMultiDrag(t *testing.T) {
+	ops := new(op.Ops)
+	var d MultiDrag
+	stack := clip.Rect(image.Rect(0, 0, 200, 200)).Push(ops)
+	d.Add(ops)
+	stack.Pop()
+	r := new(router.Router)
+	r.Frame(ops)
+
+	touch := func(kind pointer.Kind, id pointer.ID, x, y float32) pointer.Event {
+		return pointer.Event{Kind: kind, Source: pointer.Touch, PointerID: id, Position: f32.Pt(x, y)}
+	}
+	r.Queue(
+		touch(pointer.Press, 0, 50, 50),
+		touch(pointer.Press, 1, 150, 50),
+		touch(pointer.Move, 0, 60, 60),
+		touch(pointer.Move, 1, 140, 60),
+		touch(pointer.Release, 0, 60, 60),
+		touch(pointer.Move, 1, 130, 70),
+	)
+	evts := d.Update(unit.Metric{}, r, Both)
+	type step struct {
+		kind pointer.Kind
+		id   pointer.ID
+		pos  f32.Point
+	}
+	want := []step{
+		{pointer.Press, 0, f32.Pt(50, 50)},
+		{pointer.Press, 1, f32.Pt(150, 50)},
+		{pointer.Drag, 0, f32.Pt(60, 60)},
+		{pointer.Drag, 1, f32.Pt(140, 60)},
+		{pointer.Release, 0, f32.Pt(60, 60)},
+		{pointer.Drag, 1, f32.Pt(130, 70)},
+	}
+	var got []step
+	for _, e := range evts {
+		got = append(got, step{e.Kind, e.PointerID, e.Position})
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got events %v, want %v", got, want)
+	}
+	if n := d.Dragging(); n != 1 {
+		t.Errorf("%d pointers dragging, want 1", n)
+	}
+	r.Queue(touch(pointer.Cancel, 0, 0, 0))
+	evts = d.Update(unit.Metric{}, r, Both)
+	if len(evts) != 1 || evts[0].Kind != pointer.Cancel || evts[0].PointerID != 1 {
+		t.Errorf("got %v, want a cancel of pointer 1", evts)
+	}
+	if n := d.Dragging(); n != 0 {
+		t.Errorf("%d pointers dragging after cancel, want 0", n)
+	}
+}
+
+func TestPinch(t *testing.T) {
+	ops := new(op.Ops)
+	var p Pinch
+	stack := clip.Rect(image.Rect(0, 0, 200, 200)).Push(ops)
+	p.Add(ops)
+	stack.Pop()
+	r := new(router.Router)
+	r.Frame(ops)
+
+	touch := func(kind pointer.Kind, id pointer.ID, x, y float32) pointer.Event {
+		return pointer.Event{Kind: kind, Source: pointer.Touch, PointerID: id, Position: f32.Pt(x, y)}
+	}
+	r.Queue(
+		touch(pointer.Press, 0, 90, 100),
+		touch(pointer.Press, 1, 110, 100),
+		// Within the touch slop.
+		touch(pointer.Move, 1, 111, 100),
+	)
+	if evts := p.Update(unit.Metric{}, r); len(evts) > 0 || p.Pinching() {
+		t.Fatalf("pinch reported within the touch slop: %v", evts)
+	}
+	// Spread the fingers to twice the distance, and move them up.
+	r.Queue(
+		touch(pointer.Move, 0, 80, 90),
+		touch(pointer.Move, 1, 120, 90),
+	)
+	evts := p.Update(unit.Metric{}, r)
+	if !p.Pinching() {
+		t.Fatal("pinch not in progress")
+	}
+	scale := float32(1)
+	var pan f32.Point
+	for _, e := range evts {
+		scale *= e.Scale
+		pan = pan.Add(e.Pan)
+	}
+	if len(evts) == 0 || scale != 2 {
+		t.Errorf("pinch scaled by %v, want 2", scale)
+	}
+	if c := evts[len(evts)-1].Centroid; c != f32.Pt(100, 90) {
+		t.Errorf("centroid %v, want (100,90)", c)
+	}
+	if pan != f32.Pt(0, -10) {
+		t.Errorf("pan %v, want (0,-10)", pan)
+	}
+	r.Queue(touch(pointer.Release, 0, 80, 90))
+	p.Update(unit.Metric{}, r)
+	if p.Pinching() {
+		t.Error("pinch in progress after release")
+	}
+
+	// Trackpad pinches.
+	r.Queue(pointer.Event{Kind: pointer.Pinch, Source: pointer.Mouse, Position: f32.Pt(50, 60), Zoom: .5})
+	evts = p.Update(unit.Metric{}, r)
+	if len(evts) != 1 || evts[0].Scale != 1.5 || evts[0].Centroid != f32.Pt(50, 60) {
+		t.Errorf("trackpad pinch reported as %v", evts)
+	}
+}
+
 func mouseClickEvents(times ...time.Duration) []event.Event {
 	press := pointer.Event{
 		Kind:    pointer.Press,
@@ -116,3 +348,643 @@ func filterMouseClicks(events []ClickEvent) []ClickEvent {
 	}
 	return clicks
 }
+
+func TestRotate(t *testing.T) {
+	touch := func(kind pointer.Kind, id pointer.ID, x, y float32) pointer.Event {
+		return pointer.Event{Kind: kind, Source: pointer.Touch, PointerID: id, Position: f32.Pt(x, y)}
+	}
+	sum := func(evts []RotateEvent) float32 {
+		var angle float32
+		for _, e := range evts {
+			angle += e.Angle
+		}
+		return angle
+	}
+	ops := new(op.Ops)
+	var rot Rotate
+	stack := clip.Rect(image.Rect(0, 0, 200, 200)).Push(ops)
+	rot.Add(ops)
+	stack.Pop()
+	r := new(router.Router)
+	r.Frame(ops)
+
+	r.Queue(
+		touch(pointer.Press, 0, 90, 100),
+		touch(pointer.Press, 1, 110, 100),
+		// Within the touch slop.
+		touch(pointer.Move, 1, 110, 101),
+	)
+	if evts := rot.Update(unit.Metric{}, r); len(evts) > 0 || rot.Rotating() {
+		t.Fatalf("rotation reported within the touch slop: %v", evts)
+	}
+	// Turn the fingers a quarter clockwise around (100,100).
+	r.Queue(
+		touch(pointer.Move, 0, 100, 90),
+		touch(pointer.Move, 1, 100, 110),
+	)
+	evts := rot.Update(unit.Metric{}, r)
+	if !rot.Rotating() {
+		t.Fatal("rotation not in progress")
+	}
+	if a := sum(evts); math.Abs(float64(a)-math.Pi/2) > 1e-5 {
+		t.Errorf("rotated by %v, want %v", a, math.Pi/2)
+	}
+	if p := evts[len(evts)-1].Pivot; p != f32.Pt(100, 100) {
+		t.Errorf("pivot %v, want (100,100)", p)
+	}
+	r.Queue(touch(pointer.Release, 1, 100, 110))
+	rot.Update(unit.Metric{}, r)
+	if rot.Rotating() {
+		t.Error("rotation in progress after release")
+	}
+
+	// Trackpad rotations.
+	r.Queue(pointer.Event{Kind: pointer.Rotate, Source: pointer.Mouse, Position: f32.Pt(50, 60), Rotation: .5})
+	evts = rot.Update(unit.Metric{}, r)
+	if len(evts) != 1 || evts[0].Angle != .5 || evts[0].Pivot != f32.Pt(50, 60) {
+		t.Errorf("trackpad rotation reported as %v", evts)
+	}
+}
+
+func TestRotatePinch(t *testing.T) {
+	touch := func(kind pointer.Kind, id pointer.ID, x, y float32) pointer.Event {
+		return pointer.Event{Kind: kind, Source: pointer.Touch, PointerID: id, Position: f32.Pt(x, y)}
+	}
+	var p Pinch
+	rot := Rotate{Pinch: &p}
+	r := new(router.Router)
+	frame := func() {
+		ops := new(op.Ops)
+		stack := clip.Rect(image.Rect(0, 0, 200, 200)).Push(ops)
+		p.Add(ops)
+		rot.Add(ops)
+		stack.Pop()
+		r.Frame(ops)
+	}
+	frame()
+	r.Queue(
+		touch(pointer.Press, 0, 90, 100),
+		touch(pointer.Press, 1, 110, 100),
+	)
+	p.Update(unit.Metric{}, r)
+	rot.Update(unit.Metric{}, r)
+	frame()
+	// Spread the fingers to twice the distance while turning them a
+	// quarter clockwise.
+	r.Queue(
+		touch(pointer.Move, 0, 100, 80),
+		touch(pointer.Move, 1, 100, 120),
+	)
+	pevts := p.Update(unit.Metric{}, r)
+	revts := rot.Update(unit.Metric{}, r)
+	if len(pevts) == 0 || pevts[len(pevts)-1].Scale != 2 {
+		t.Errorf("pinch reported as %v", pevts)
+	}
+	if len(revts) != 1 || math.Abs(float64(revts[0].Angle)-math.Pi/2) > 1e-5 {
+		t.Errorf("rotation reported as %v", revts)
+	}
+	if !p.Pinching() || !rot.Rotating() {
+		t.Errorf("pinching %v, rotating %v, want both", p.Pinching(), rot.Rotating())
+	}
+}
+
+func TestLongPress(t *testing.T) {
+	var lp LongPress
+	r := new(router.Router)
+	frame := func() {
+		ops := new(op.Ops)
+		stack := clip.Rect(image.Rect(0, 0, 100, 100)).Push(ops)
+		lp.Add(ops)
+		stack.Pop()
+		r.Frame(ops)
+	}
+	kinds := func(evts []LongPressEvent) []LongPressKind {
+		var k []LongPressKind
+		for _, e := range evts {
+			k = append(k, e.Kind)
+		}
+		return k
+	}
+	touch := func(kind pointer.Kind, x, y float32) pointer.Event {
+		return pointer.Event{Kind: kind, Source: pointer.Touch, Position: f32.Pt(x, y)}
+	}
+	now := time.Now()
+	frame()
+
+	// Hold the press, wandering within the slop.
+	r.Queue(touch(pointer.Press, 10, 10))
+	if k := kinds(lp.Update(unit.Metric{}, r, now)); len(k) != 1 || k[0] != LongPressPressed {
+		t.Fatalf("got %v, want a press", k)
+	}
+	frame()
+	r.Queue(touch(pointer.Move, 14, 12))
+	if k := kinds(lp.Update(unit.Metric{}, r, now.Add(400*time.Millisecond))); len(k) != 0 {
+		t.Fatalf("got %v before the duration", k)
+	}
+	evts := lp.Update(unit.Metric{}, r, now.Add(500*time.Millisecond))
+	if len(evts) != 1 || evts[0].Kind != LongPressTriggered || evts[0].Position != image.Pt(14, 12) {
+		t.Fatalf("got %v, want a trigger at (14,12)", evts)
+	}
+	frame()
+	r.Queue(touch(pointer.Release, 14, 12))
+	if k := kinds(lp.Update(unit.Metric{}, r, now.Add(time.Second))); len(k) != 0 || lp.Pressed() {
+		t.Fatalf("got %v after releasing a triggered press", k)
+	}
+
+	// Moving too far cancels the press.
+	r.Queue(
+		touch(pointer.Press, 10, 10),
+		touch(pointer.Move, 30, 10),
+	)
+	k := kinds(lp.Update(unit.Metric{}, r, now))
+	if len(k) != 2 || k[0] != LongPressPressed || k[1] != LongPressCancelled {
+		t.Fatalf("got %v, want a press and a cancel", k)
+	}
+	if k := kinds(lp.Update(unit.Metric{}, r, now.Add(time.Second))); len(k) != 0 {
+		t.Fatalf("got %v after a cancel", k)
+	}
+	r.Queue(touch(pointer.Release, 30, 10))
+
+	// As does releasing early.
+	r.Queue(
+		touch(pointer.Press, 10, 10),
+		touch(pointer.Release, 10, 10),
+	)
+	k = kinds(lp.Update(unit.Metric{}, r, now))
+	if len(k) != 2 || k[0] != LongPressPressed || k[1] != LongPressCancelled {
+		t.Fatalf("got %v, want a press and a cancel", k)
+	}
+}
+
+func TestForcePress(t *testing.T) {
+	var fp ForcePress
+	ops := new(op.Ops)
+	stack := clip.Rect(image.Rect(0, 0, 100, 100)).Push(ops)
+	fp.Add(ops)
+	stack.Pop()
+	r := new(router.Router)
+	r.Frame(ops)
+	stylus := func(kind pointer.Kind, x, pressure float32) pointer.Event {
+		return pointer.Event{Kind: kind, Source: pointer.Stylus, Position: f32.Pt(x, 10), Pressure: pressure}
+	}
+	r.Queue(
+		stylus(pointer.Press, 10, 0.2),
+		stylus(pointer.Move, 12, 0.5),
+		stylus(pointer.Move, 12, 0.8),
+		stylus(pointer.Move, 12, 0.9),
+		stylus(pointer.Move, 12, 0.5),
+		stylus(pointer.Release, 12, 0),
+	)
+	var kinds []ForcePressKind
+	var pressures []float32
+	for _, e := range fp.Update(unit.Metric{}, r) {
+		kinds = append(kinds, e.Kind)
+		pressures = append(pressures, e.Pressure)
+	}
+	want := []ForcePressKind{
+		ForcePressStart,
+		ForcePressChange,
+		ForcePressChange, ForcePressDeep,
+		ForcePressChange,
+		ForcePressChange,
+		ForcePressEnd,
+	}
+	if !reflect.DeepEqual(kinds, want) {
+		t.Fatalf("got %v, want %v", kinds, want)
+	}
+	if p := pressures[3]; p != 0.8 {
+		t.Errorf("got deep press at pressure %v, want 0.8", p)
+	}
+
+	// Moving too far cancels the press.
+	r.Queue(
+		stylus(pointer.Press, 10, 0.2),
+		stylus(pointer.Move, 30, 0.9),
+	)
+	evts := fp.Update(unit.Metric{}, r)
+	if len(evts) != 2 || evts[1].Kind != ForcePressCancel || fp.Pressed() {
+		t.Errorf("got %v, want a cancelled press", evts)
+	}
+}
+
+func TestSwipe(t *testing.T) {
+	var s Swipe
+	ops := new(op.Ops)
+	stack := clip.Rect(image.Rect(0, 0, 400, 400)).Push(ops)
+	s.Add(ops)
+	stack.Pop()
+	r := new(router.Router)
+	r.Frame(ops)
+
+	// drag moves the pointer from (200,200) by d, in steps every 10
+	// milliseconds for the duration.
+	drag := func(d f32.Point, duration time.Duration) []SwipeEvent {
+		const step = 10 * time.Millisecond
+		n := int(duration / step)
+		start := f32.Pt(200, 200)
+		r.Queue(pointer.Event{Kind: pointer.Press, Source: pointer.Touch, Position: start})
+		for i := 1; i <= n; i++ {
+			pos := start.Add(d.Mul(float32(i) / float32(n)))
+			r.Queue(pointer.Event{Kind: pointer.Move, Source: pointer.Touch, Position: pos, Time: time.Duration(i) * step})
+		}
+		r.Queue(pointer.Event{Kind: pointer.Release, Source: pointer.Touch, Position: start.Add(d), Time: time.Duration(n) * step})
+		return s.Update(unit.Metric{PxPerDp: 1}, r)
+	}
+
+	// A quick flick to the left, 100 pixels in 50 milliseconds.
+	evts := drag(f32.Pt(-100, 10), 50*time.Millisecond)
+	if len(evts) != 1 {
+		t.Fatalf("got %v, want a swipe", evts)
+	}
+	e := evts[0]
+	if e.Direction != SwipeLeft || e.Distance != 100 || e.Position != image.Pt(200, 200) {
+		t.Errorf("got %v, want a swipe left by 100 from (200,200)", e)
+	}
+	if v := e.Velocity; v < 1900 || v > 2100 {
+		t.Errorf("got velocity %v, want about 2000", v)
+	}
+	if evts := drag(f32.Pt(5, -80), 40*time.Millisecond); len(evts) != 1 || evts[0].Direction != SwipeUp {
+		t.Errorf("got %v, want a swipe up", evts)
+	}
+
+	// Slow drags and short flicks are not swipes.
+	if evts := drag(f32.Pt(100, 0), time.Second); len(evts) != 0 {
+		t.Errorf("slow drag reported as %v", evts)
+	}
+	if evts := drag(f32.Pt(0, 10), 20*time.Millisecond); len(evts) != 0 {
+		t.Errorf("short flick reported as %v", evts)
+	}
+}
+
+func TestEdgeSwipe(t *testing.T) {
+	s := EdgeSwipe{Edge: EdgeRight, Distance: 200}
+	ops := new(op.Ops)
+	size := image.Pt(400, 400)
+	stack := clip.Rect(image.Rectangle{Max: size}).Push(ops)
+	s.Add(ops)
+	stack.Pop()
+	r := new(router.Router)
+	r.Frame(ops)
+
+	// drag moves the pointer from start by d, in steps every 10
+	// milliseconds for the duration.
+	drag := func(start, d f32.Point, duration time.Duration) []EdgeSwipeEvent {
+		const step = 10 * time.Millisecond
+		n := int(duration / step)
+		r.Queue(pointer.Event{Kind: pointer.Press, Source: pointer.Touch, Position: start})
+		for i := 1; i <= n; i++ {
+			pos := start.Add(d.Mul(float32(i) / float32(n)))
+			r.Queue(pointer.Event{Kind: pointer.Move, Source: pointer.Touch, Position: pos, Time: time.Duration(i) * step})
+		}
+		r.Queue(pointer.Event{Kind: pointer.Release, Source: pointer.Touch, Position: start.Add(d), Time: time.Duration(n) * step})
+		return s.Update(unit.Metric{PxPerDp: 1}, r, size)
+	}
+
+	// A slow swipe from the right edge past the threshold.
+	evts := drag(f32.Pt(395, 200), f32.Pt(-150, 0), time.Second)
+	if len(evts) < 3 || evts[0].Kind != EdgeSwipeStart {
+		t.Fatalf("got %v, want an edge swipe", evts)
+	}
+	if e := evts[len(evts)-1]; e.Kind != EdgeSwipeTrigger || e.Progress != 0.75 {
+		t.Errorf("got %v, want a trigger at progress 0.75", e)
+	}
+	// A slow swipe short of the threshold.
+	evts = drag(f32.Pt(395, 200), f32.Pt(-60, 0), time.Second)
+	if e := evts[len(evts)-1]; e.Kind != EdgeSwipeCancel {
+		t.Errorf("got %v, want a cancel", e)
+	}
+	// A short but quick flick triggers.
+	evts = drag(f32.Pt(395, 200), f32.Pt(-60, 0), 30*time.Millisecond)
+	if e := evts[len(evts)-1]; e.Kind != EdgeSwipeTrigger || e.Velocity < 1000 {
+		t.Errorf("got %v, want a quick trigger", e)
+	}
+	// Swipes away from the edge and across it are ignored.
+	if evts := drag(f32.Pt(200, 200), f32.Pt(-150, 0), time.Second); len(evts) != 0 {
+		t.Errorf("swipe away from the edge reported as %v", evts)
+	}
+	if evts := drag(f32.Pt(395, 200), f32.Pt(0, 150), time.Second); len(evts) != 0 {
+		t.Errorf("swipe along the edge reported as %v", evts)
+	}
+	if s.Swiping() {
+		t.Error("edge swipe in progress after release")
+	}
+}
+
+func TestPan(t *testing.T) {
+	var p Pan
+	ops := new(op.Ops)
+	stack := clip.Rect(image.Rect(0, 0, 400, 400)).Push(ops)
+	p.Add(ops)
+	stack.Pop()
+	r := new(router.Router)
+	r.Frame(ops)
+
+	// Pan diagonally by (100, 50) in 50 milliseconds.
+	const step = 10 * time.Millisecond
+	start := f32.Pt(200, 200)
+	r.Queue(pointer.Event{Kind: pointer.Press, Source: pointer.Touch, Position: start})
+	// A move within the slop doesn't start the gesture.
+	r.Queue(pointer.Event{Kind: pointer.Move, Source: pointer.Touch, Position: start.Add(f32.Pt(1, 1)), Time: step / 2})
+	for i := 1; i <= 5; i++ {
+		pos := start.Add(f32.Pt(20, 10).Mul(float32(i)))
+		r.Queue(pointer.Event{Kind: pointer.Move, Source: pointer.Touch, Position: pos, Time: time.Duration(i) * step})
+	}
+	r.Queue(pointer.Event{Kind: pointer.Release, Source: pointer.Touch, Position: start.Add(f32.Pt(100, 50)), Time: 5 * step})
+	evts := p.Update(unit.Metric{PxPerDp: 1}, r)
+	if len(evts) != 6 {
+		t.Fatalf("got %d events, want 6: %v", len(evts), evts)
+	}
+	if evts[0].Kind != PanStart || evts[len(evts)-1].Kind != PanEnd {
+		t.Errorf("got %v, want a pan from PanStart to PanEnd", evts)
+	}
+	var total f32.Point
+	for _, e := range evts {
+		total = total.Add(e.Delta)
+	}
+	if total != f32.Pt(100, 50) {
+		t.Errorf("got a total delta of %v, want (100,50)", total)
+	}
+	v := evts[len(evts)-1].Velocity
+	if v.X < 1900 || v.X > 2100 || v.Y < 900 || v.Y > 1100 {
+		t.Errorf("got velocity %v, want about (2000,1000)", v)
+	}
+	if p.Panning() || p.Pressed() {
+		t.Error("pan in progress after release")
+	}
+}
+
+func TestDragLockAxis(t *testing.T) {
+	d := Drag{LockAxis: true, Threshold: 10}
+	ops := new(op.Ops)
+	stack := clip.Rect(image.Rect(0, 0, 200, 200)).Push(ops)
+	d.Add(ops)
+	stack.Pop()
+	r := new(router.Router)
+	r.Frame(ops)
+	touch := func(kind pointer.Kind, x, y float32) pointer.Event {
+		return pointer.Event{Kind: kind, Source: pointer.Touch, Position: f32.Pt(x, y)}
+	}
+	r.Queue(
+		touch(pointer.Press, 50, 50),
+		// Within the threshold.
+		touch(pointer.Move, 55, 52),
+		// Mostly horizontal, which locks the drag.
+		touch(pointer.Move, 70, 55),
+		touch(pointer.Move, 80, 90),
+	)
+	var drags []f32.Point
+	for _, e := range d.Update(unit.Metric{PxPerDp: 1}, r, Both) {
+		if e.Kind == pointer.Drag {
+			drags = append(drags, e.Position)
+		}
+	}
+	want := []f32.Point{f32.Pt(70, 50), f32.Pt(80, 50)}
+	if !reflect.DeepEqual(drags, want) {
+		t.Errorf("got drags %v, want %v", drags, want)
+	}
+	if a := d.LockedAxis(); a != Horizontal {
+		t.Errorf("got locked axis %v, want Horizontal", a)
+	}
+}
+
+func TestArbitration(t *testing.T) {
+	var inner, outer Scroll
+	ops := new(op.Ops)
+	r := new(router.Router)
+	frame := func() {
+		ops.Reset()
+		outer.Add(ops, image.Rect(-100, -100, 100, 100))
+		inner.Add(ops, image.Rect(-100, -100, 100, 100))
+		r.Frame(ops)
+	}
+	update := func() (int, int) {
+		t := time.Time{}
+		return inner.Update(unit.Metric{PxPerDp: 1}, r, t, Vertical), outer.Update(unit.Metric{PxPerDp: 1}, r, t, Horizontal)
+	}
+	frame()
+	update()
+	touch := func(kind pointer.Kind, x, y float32) pointer.Event {
+		return pointer.Event{Kind: kind, Source: pointer.Touch, Position: f32.Pt(x, y)}
+	}
+	// A diagonal move, mostly across the inner Scroll.
+	r.Queue(touch(pointer.Press, 50, 50), touch(pointer.Move, 40, 45))
+	update()
+	frame()
+	// The inner Scroll yields, and the outer Scroll grabs.
+	update()
+	frame()
+	// The outer Scroll catches up with the moves before the grab.
+	r.Queue(touch(pointer.Move, 20, 40))
+	if in, out := update(); in != 0 || out != 30 {
+		t.Errorf("scrolled inner %d, outer %d, want 0 and 30", in, out)
+	}
+	r.Queue(touch(pointer.Release, 20, 40))
+	update()
+	frame()
+
+	// Precedence beats the foremost handler.
+	var back, front Drag
+	back.Precedence = 1
+	ops.Reset()
+	back.Add(ops)
+	front.Add(ops)
+	r.Frame(ops)
+	r.Queue(touch(pointer.Press, 50, 50), touch(pointer.Move, 60, 50))
+	front.Update(unit.Metric{PxPerDp: 1}, r, Both)
+	back.Update(unit.Metric{PxPerDp: 1}, r, Both)
+	ops.Reset()
+	back.Add(ops)
+	front.Add(ops)
+	r.Frame(ops)
+	var cancelled bool
+	for _, e := range front.Update(unit.Metric{PxPerDp: 1}, r, Both) {
+		cancelled = cancelled || e.Kind == pointer.Cancel
+	}
+	if !cancelled || !back.Dragging() {
+		t.Error("the gesture of higher precedence didn't win")
+	}
+}
+
+func TestMouseDragAcrossScroll(t *testing.T) {
+	// A horizontal Drag, such as of a slider, inside a vertical Scroll.
+	var scroll Scroll
+	var drag Drag
+	ops := new(op.Ops)
+	r := new(router.Router)
+	frame := func() {
+		ops.Reset()
+		scroll.Add(ops, image.Rect(-100, -100, 100, 100))
+		drag.Add(ops)
+		r.Frame(ops)
+	}
+	update := func() (cancelled bool) {
+		scroll.Update(unit.Metric{PxPerDp: 1}, r, time.Time{}, Vertical)
+		for _, e := range drag.Update(unit.Metric{PxPerDp: 1}, r, Horizontal) {
+			cancelled = cancelled || e.Kind == pointer.Cancel
+		}
+		return cancelled
+	}
+	frame()
+	update()
+	mouse := func(kind pointer.Kind, x, y float32) pointer.Event {
+		return pointer.Event{Kind: kind, Source: pointer.Mouse, Buttons: pointer.ButtonPrimary, Position: f32.Pt(x, y)}
+	}
+	// The first move past the slop is slightly more vertical.
+	r.Queue(mouse(pointer.Press, 50, 50), mouse(pointer.Move, 60, 61))
+	cancelled := update()
+	frame()
+	r.Queue(mouse(pointer.Move, 80, 62))
+	cancelled = update() || cancelled
+	if cancelled || !drag.Dragging() {
+		t.Error("the mouse drag was cancelled by a move across its axis")
+	}
+}
+
+func TestDragMouseSlop(t *testing.T) {
+	for _, src := range []pointer.Source{pointer.Mouse, pointer.Touch} {
+		// With a Threshold, drags competing with a Scroll are reported
+		// only once the gesture moved past its slop.
+		var scroll Scroll
+		drag := Drag{MouseSlop: 20, Threshold: 1}
+		ops := new(op.Ops)
+		r := new(router.Router)
+		frame := func() {
+			ops.Reset()
+			scroll.Add(ops, image.Rect(-100, -100, 100, 100))
+			drag.Add(ops)
+			r.Frame(ops)
+		}
+		update := func() (dragged bool) {
+			scroll.Update(unit.Metric{PxPerDp: 1}, r, time.Time{}, Vertical)
+			for _, e := range drag.Update(unit.Metric{PxPerDp: 1}, r, Both) {
+				dragged = dragged || e.Kind == pointer.Drag
+			}
+			return dragged
+		}
+		ev := func(kind pointer.Kind, x float32) pointer.Event {
+			return pointer.Event{Kind: kind, Source: src, Buttons: pointer.ButtonPrimary, Position: f32.Pt(x, 50)}
+		}
+		frame()
+		update()
+		r.Queue(ev(pointer.Press, 50), ev(pointer.Move, 60))
+		if got, want := update(), src != pointer.Mouse; got != want {
+			t.Errorf("%v: dragged %v within the mouse slop, want %v", src, got, want)
+		}
+		frame()
+		r.Queue(ev(pointer.Move, 80))
+		if !update() {
+			t.Errorf("%v: no drag past the mouse slop", src)
+		}
+	}
+}
+
+func TestDragStylusBarrel(t *testing.T) {
+	// A pen dragged with its barrel button held still drags.
+	var drag Drag
+	ops := new(op.Ops)
+	r := new(router.Router)
+	drag.Add(ops)
+	r.Frame(ops)
+	drag.Update(unit.Metric{PxPerDp: 1}, r, Both)
+	btns := pointer.ButtonPrimary | pointer.ButtonSecondary
+	r.Queue(
+		pointer.Event{Kind: pointer.Press, Source: pointer.Stylus, Buttons: btns, Position: f32.Pt(50, 50)},
+		pointer.Event{Kind: pointer.Move, Source: pointer.Stylus, Buttons: btns, Position: f32.Pt(80, 50)},
+	)
+	dragged := false
+	for _, e := range drag.Update(unit.Metric{PxPerDp: 1}, r, Both) {
+		dragged = dragged || e.Kind == pointer.Drag
+	}
+	if !dragged {
+		t.Error("no drag with the barrel button held")
+	}
+}
+
+func TestScrollOverscroll(t *testing.T) {
+	s := Scroll{Overscroll: true}
+	ops := new(op.Ops)
+	r := new(router.Router)
+	frame := func() {
+		ops.Reset()
+		s.Add(ops, image.Rect(0, -100, 0, 100))
+		r.Frame(ops)
+	}
+	start := time.Now()
+	update := func(d time.Duration) int {
+		return s.Update(unit.Metric{PxPerDp: 1}, r, start.Add(d), Vertical)
+	}
+	touch := func(kind pointer.Kind, y float32) pointer.Event {
+		return pointer.Event{Kind: kind, Source: pointer.Touch, Position: f32.Pt(50, y)}
+	}
+	frame()
+	update(0)
+	r.Queue(touch(pointer.Press, 50), touch(pointer.Move, 45))
+	update(0)
+	frame()
+	// The content is at its end, so all of the distance overflows.
+	r.Queue(touch(pointer.Move, 20))
+	d := update(0)
+	if d != 25 {
+		t.Fatalf("scrolled %d, want 25", d)
+	}
+	s.Overflow(d)
+	if o := s.OverscrollDistance(); o <= 0 || o >= 25*0.55 {
+		t.Errorf("overscroll distance %v is not resisted", o)
+	}
+	// Dragging back reduces the overscroll before scrolling.
+	r.Queue(touch(pointer.Move, 35))
+	if d := update(0); d != 0 {
+		t.Errorf("scrolled %d while overscrolled, want 0", d)
+	}
+	r.Queue(touch(pointer.Move, 50))
+	if d := update(0); d != -5 {
+		t.Errorf("scrolled %d after the overscroll, want -5", d)
+	}
+	r.Queue(touch(pointer.Move, 20))
+	s.Overflow(update(0))
+	r.Queue(touch(pointer.Release, 20))
+	update(0)
+	if s.State() != StateFlinging {
+		t.Errorf("overscroll isn't settling back")
+	}
+	update(time.Second)
+	if o := s.OverscrollDistance(); o != 0 || s.State() != StateIdle {
+		t.Errorf("overscroll distance %v after settling, state %v", o, s.State())
+	}
+}
+
+func TestScrollWheel(t *testing.T) {
+	var s Scroll
+	ops := new(op.Ops)
+	r := new(router.Router)
+	s.Add(ops, image.Rect(0, -100, 0, 100))
+	r.Frame(ops)
+	start := time.Now()
+	update := func(d time.Duration) int {
+		return s.Update(unit.Metric{PxPerDp: 1}, r, start.Add(d), Vertical)
+	}
+	wheel := func(mode pointer.ScrollMode, y float32) pointer.Event {
+		return pointer.Event{Kind: pointer.Scroll, Source: pointer.Mouse, Position: f32.Pt(50, 50), Scroll: f32.Pt(0, y), ScrollMode: mode}
+	}
+	update(0)
+	// Precise scrolling is immediate.
+	r.Queue(wheel(pointer.ScrollPixels, 30))
+	if d := update(0); d != 30 {
+		t.Errorf("scrolled %d pixels, want 30", d)
+	}
+	// Wheel ticks are animated.
+	r.Queue(wheel(pointer.ScrollLines, 100))
+	total := update(0)
+	if total != 0 || s.State() != StateFlinging {
+		t.Errorf("scrolled %d at the wheel tick, state %v", total, s.State())
+	}
+	d := update(50 * time.Millisecond)
+	if d <= 0 || d >= 100 {
+		t.Errorf("scrolled %d after a frame, want part of 100", d)
+	}
+	total += d
+	total += update(time.Second)
+	if total != 100 || s.State() != StateIdle {
+		t.Errorf("scrolled %d in total, state %v; want 100, idle", total, s.State())
+	}
+}
@@ -18,6 +18,7 @@ import (
 	"time"
 	"unsafe"
 
+	"gioui.org/cpu"
 	"gioui.org/shader"
 	"gioui.org/shader/gio"
 	"github.com/Seikaijyu/gio/gpu/internal/driver"
@@ -346,11 +347,25 @@ func New(api API) (GPU, error) {
 //
 // Note: for internal use only.
 func NewWithDevice(d driver.Device) (GPU, error) {
+	return newWithDevice(d, false)
+}
+
+// NewComputeWithDevice is like NewWithDevice, but prefers the compute
+// renderer if the device or the CPU can run it.
+//
+// Note: for internal use only.
+func NewComputeWithDevice(d driver.Device) (GPU, error) {
+	return newWithDevice(d, true)
+}
+
+func newWithDevice(d driver.Device, preferCompute bool) (GPU, error) {
 	d.BeginFrame(nil, false, image.Point{})
 	defer d.EndFrame()
 	forceCompute := os.Getenv("GIORENDERER") == "forcecompute"
 	feats := d.Caps().Features
 	switch {
+	case preferCompute && (feats.Has(driver.FeatureCompute) || cpu.Supported):
+		return newCompute(d)
 	case !forceCompute && feats.Has(driver.FeatureFloatRenderTargets) && feats.Has(driver.FeatureSRGB):
 		return newGPU(d)
 	}
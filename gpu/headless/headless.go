@@ -59,6 +59,33 @@ func newContext() (context, error) {
 
 // NewWindow creates a new headless window.
 func NewWindow(width, height int) (*Window, error) {
+	return newWindow(width, height, gpu.NewWithDevice)
+}
+
+// Rasterize renders frame to an image of the given size, such as for
+// generating thumbnails and previews on servers. It needs no window
+// system. Rasterize prefers the compute renderer, whose output depends
+// less on the GPU, and renders in software on machines where no GPU
+// device is available. The software renderer anti-aliases edges and
+// filters images slightly differently from the GPU renderers. The image
+// is transparent where frame doesn't paint.
+func Rasterize(frame *op.Ops, size image.Point) (*image.RGBA, error) {
+	w, err := newWindow(size.X, size.Y, gpu.NewComputeWithDevice)
+	if err != nil {
+		return rasterize(frame, size), nil
+	}
+	defer w.Release()
+	if err := w.Frame(frame); err != nil {
+		return nil, err
+	}
+	img := image.NewRGBA(image.Rectangle{Max: size})
+	if err := w.Screenshot(img); err != nil {
+		return nil, err
+	}
+	return img, nil
+}
+
+func newWindow(width, height int, newGPU func(driver.Device) (gpu.GPU, error)) (*Window, error) {
 	ctx, err := newContext()
 	if err != nil {
 		return nil, err
@@ -83,7 +110,7 @@ func NewWindow(width, height int) (*Window, error) {
 			return err
 		}
 		// Note that the gpu takes ownership of dev.
-		gp, err := gpu.NewWithDevice(dev)
+		gp, err := newGPU(dev)
 		if err != nil {
 			fboTex.Release()
 			return err
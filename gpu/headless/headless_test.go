@@ -0,0 +1,127 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package headless
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/Seikaijyu/gio/f32"
+	"github.com/Seikaijyu/gio/op"
+	"github.com/Seikaijyu/gio/op/clip"
+	"github.com/Seikaijyu/gio/op/paint"
+)
+
+func TestRasterize(t *testing.T) {
+	ops := new(op.Ops)
+	paint.FillShape(ops, color.NRGBA{R: 0xff, A: 0xff}, clip.Rect(image.Rect(10, 10, 30, 20)).Op())
+	img, err := Rasterize(ops, image.Pt(40, 40))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkRect(t, img, image.Rect(10, 10, 30, 20), color.RGBA{R: 0xff, A: 0xff})
+}
+
+func TestRasterizeSoftware(t *testing.T) {
+	red := color.NRGBA{R: 0xff, A: 0xff}
+	blue := color.NRGBA{B: 0xff, A: 0xff}
+	tests := []struct {
+		name string
+		draw func(ops *op.Ops)
+		rect image.Rectangle
+		want color.RGBA
+	}{
+		{
+			name: "rect",
+			draw: func(ops *op.Ops) {
+				paint.FillShape(ops, red, clip.Rect(image.Rect(10, 10, 30, 20)).Op())
+			},
+			rect: image.Rect(10, 10, 30, 20),
+			want: color.RGBA{R: 0xff, A: 0xff},
+		},
+		{
+			name: "transformed",
+			draw: func(ops *op.Ops) {
+				t := f32.Affine2D{}.Scale(f32.Point{}, f32.Pt(2, 3)).Offset(f32.Pt(4, 5))
+				defer op.Affine(t).Push(ops).Pop()
+				paint.FillShape(ops, blue, clip.Rect(image.Rect(1, 1, 5, 5)).Op())
+			},
+			rect: image.Rect(6, 8, 14, 20),
+			want: color.RGBA{B: 0xff, A: 0xff},
+		},
+		{
+			name: "path",
+			draw: func(ops *op.Ops) {
+				var p clip.Path
+				p.Begin(ops)
+				p.MoveTo(f32.Pt(5, 5))
+				p.LineTo(f32.Pt(25, 5))
+				p.LineTo(f32.Pt(25, 15))
+				p.LineTo(f32.Pt(5, 15))
+				p.Close()
+				paint.FillShape(ops, red, clip.Outline{Path: p.End()}.Op())
+			},
+			rect: image.Rect(5, 5, 25, 15),
+			want: color.RGBA{R: 0xff, A: 0xff},
+		},
+		{
+			// Overlapping shapes in an opacity layer blend as one.
+			name: "opacity",
+			draw: func(ops *op.Ops) {
+				defer paint.PushOpacity(ops, .5).Pop()
+				paint.FillShape(ops, red, clip.Rect(image.Rect(10, 10, 20, 20)).Op())
+				paint.FillShape(ops, red, clip.Rect(image.Rect(10, 10, 20, 20)).Op())
+			},
+			rect: image.Rect(10, 10, 20, 20),
+			want: color.RGBA{R: 0xbc, A: 0x80},
+		},
+		{
+			name: "image",
+			draw: func(ops *op.Ops) {
+				src := image.NewRGBA(image.Rect(0, 0, 4, 4))
+				for i := 0; i < len(src.Pix); i += 4 {
+					src.Pix[i+1], src.Pix[i+3] = 0xff, 0xff
+				}
+				defer op.Offset(image.Pt(8, 8)).Push(ops).Pop()
+				paint.NewImageOp(src).Add(ops)
+				paint.PaintOp{}.Add(ops)
+			},
+			rect: image.Rect(8, 8, 12, 12),
+			want: color.RGBA{G: 0xff, A: 0xff},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ops := new(op.Ops)
+			test.draw(ops)
+			checkRect(t, rasterize(ops, image.Pt(40, 40)), test.rect, test.want)
+		})
+	}
+}
+
+// checkRect checks that img is want inside r and transparent outside.
+func checkRect(t *testing.T, img *image.RGBA, r image.Rectangle, want color.RGBA) {
+	t.Helper()
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			exp := color.RGBA{}
+			if image.Pt(x, y).In(r) {
+				exp = want
+			}
+			if got := img.RGBAAt(x, y); !colorClose(got, exp) {
+				t.Fatalf("(%d,%d) = %v, want %v", x, y, got, exp)
+			}
+		}
+	}
+}
+
+func colorClose(c1, c2 color.RGBA) bool {
+	const tol = 2
+	near := func(a, b uint8) bool {
+		d := int(a) - int(b)
+		return -tol <= d && d <= tol
+	}
+	return near(c1.R, c2.R) && near(c1.G, c2.G) && near(c1.B, c2.B) && near(c1.A, c2.A)
+}
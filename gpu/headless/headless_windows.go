@@ -15,17 +15,23 @@ type d3d11Context struct {
 
 func init() {
 	newContextPrimary = func() (context, error) {
-		dev, ctx, _, err := d3d11.CreateDevice(
-			d3d11.DRIVER_TYPE_HARDWARE,
-			0,
-		)
-		if err != nil {
-			return nil, err
-		}
-		// Don't need it.
-		d3d11.IUnknownRelease(unsafe.Pointer(ctx), ctx.Vtbl.Release)
-		return &d3d11Context{dev: dev}, nil
+		return newD3D11Context(d3d11.DRIVER_TYPE_HARDWARE)
 	}
+	// Fall back to the WARP software rasterizer on machines without a
+	// GPU, such as servers.
+	newContextFallback = func() (context, error) {
+		return newD3D11Context(d3d11.DRIVER_TYPE_WARP)
+	}
+}
+
+func newD3D11Context(driverType uint32) (context, error) {
+	dev, ctx, _, err := d3d11.CreateDevice(driverType, 0)
+	if err != nil {
+		return nil, err
+	}
+	// Don't need it.
+	d3d11.IUnknownRelease(unsafe.Pointer(ctx), ctx.Vtbl.Release)
+	return &d3d11Context{dev: dev}, nil
 }
 
 func (c *d3d11Context) API() gpu.API {
@@ -0,0 +1,501 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package headless
+
+import (
+	"encoding/binary"
+	"image"
+	"image/color"
+	"math"
+
+	"golang.org/x/image/vector"
+
+	"github.com/Seikaijyu/gio/internal/f32"
+	"github.com/Seikaijyu/gio/internal/f32color"
+	"github.com/Seikaijyu/gio/internal/ops"
+	"github.com/Seikaijyu/gio/internal/scene"
+	"github.com/Seikaijyu/gio/internal/stroke"
+	"github.com/Seikaijyu/gio/op"
+)
+
+// rasterizer renders operation lists without a GPU. Like the GPU
+// renderers, it blends in linear color space and anti-aliases clip
+// paths by their coverage of each pixel.
+type rasterizer struct {
+	size   image.Point
+	reader ops.Reader
+	vec    vector.Rasterizer
+	// dst is the current layer, in premultiplied linear color.
+	dst []f32color.RGBA
+	// layers are the opacity layers below dst.
+	layers     []rasterLayer
+	transStack []f32.Affine2D
+	states     []f32.Affine2D
+	segs       []pathSegment
+	// srgb maps sRGB encoded bytes to linear values.
+	srgb [256]float32
+}
+
+type rasterLayer struct {
+	dst     []f32color.RGBA
+	opacity float32
+}
+
+// rasterClip is a clip area, represented by its coverage mask
+// intersected with the masks of its parents.
+type rasterClip struct {
+	parent *rasterClip
+	mask   *image.Alpha
+}
+
+type rasterState struct {
+	t     f32.Affine2D
+	clip  *rasterClip
+	mat   rasterMaterial
+	color f32color.RGBA
+	// Linear gradient stops and colors.
+	stop1, stop2   f32.Point
+	color1, color2 f32color.RGBA
+	// Image source and filter.
+	image  *image.RGBA
+	filter byte
+}
+
+type rasterMaterial uint8
+
+const (
+	rasterColor rasterMaterial = iota
+	rasterLinearGradient
+	rasterImage
+)
+
+type pathSegment struct {
+	op  scene.Op
+	pts [3]f32.Point
+	n   int
+}
+
+// rasterize renders frame to an image of the given size, in software.
+func rasterize(frame *op.Ops, size image.Point) *image.RGBA {
+	r := &rasterizer{size: size}
+	for i := range r.srgb {
+		r.srgb[i] = f32color.LinearFromSRGB(color.NRGBA{R: uint8(i), A: 0xff}).R
+	}
+	r.dst = make([]f32color.RGBA, size.X*size.Y)
+	r.reader.Reset(&frame.Internal)
+	r.collect()
+	img := image.NewRGBA(image.Rectangle{Max: size})
+	for i, c := range r.dst {
+		a := c.A
+		if a > 1 {
+			a = 1
+		}
+		s := f32color.RGBA{R: c.R, G: c.G, B: c.B, A: 1}.SRGB()
+		p := img.Pix[i*4 : i*4+4]
+		p[0], p[1], p[2], p[3] = s.R, s.G, s.B, uint8(a*0xff+.5)
+	}
+	return img
+}
+
+func (r *rasterizer) collect() {
+	var (
+		state       rasterState
+		path        []byte
+		strokeWidth float32
+	)
+	reset := func() {
+		state = rasterState{
+			color: f32color.RGBA{A: 1},
+		}
+	}
+	reset()
+	bo := binary.LittleEndian
+loop:
+	for encOp, ok := r.reader.Decode(); ok; encOp, ok = r.reader.Decode() {
+		switch ops.OpType(encOp.Data[0]) {
+		case ops.TypeTransform:
+			dop, push := ops.DecodeTransform(encOp.Data)
+			if push {
+				r.transStack = append(r.transStack, state.t)
+			}
+			state.t = state.t.Mul(dop)
+		case ops.TypePopTransform:
+			n := len(r.transStack)
+			state.t = r.transStack[n-1]
+			r.transStack = r.transStack[:n-1]
+
+		case ops.TypePushOpacity:
+			r.layers = append(r.layers, rasterLayer{
+				dst:     r.dst,
+				opacity: ops.DecodeOpacity(encOp.Data),
+			})
+			r.dst = make([]f32color.RGBA, len(r.dst))
+		case ops.TypePopOpacity:
+			n := len(r.layers)
+			l := r.layers[n-1]
+			r.layers = r.layers[:n-1]
+			for i, c := range r.dst {
+				d := &l.dst[i]
+				blend(d, c, l.opacity)
+			}
+			r.dst = l.dst
+
+		case ops.TypeStroke:
+			strokeWidth = math.Float32frombits(bo.Uint32(encOp.Data[1:]))
+
+		case ops.TypePath:
+			encOp, ok = r.reader.Decode()
+			if !ok {
+				break loop
+			}
+			path = encOp.Data[ops.TypeAuxLen:]
+
+		case ops.TypeClip:
+			var cop ops.ClipOp
+			cop.Decode(encOp.Data)
+			r.segs = r.segs[:0]
+			switch {
+			case len(path) > 0 && strokeWidth > 0:
+				r.strokeSegments(state.t, path, strokeWidth)
+			case len(path) > 0 && cop.Outline:
+				r.outlineSegments(state.t, path)
+			default:
+				r.rectSegments(state.t, f32.FRect(cop.Bounds))
+			}
+			state.clip = &rasterClip{
+				parent: state.clip,
+				mask:   r.mask(state.clip),
+			}
+			path = nil
+			strokeWidth = 0
+		case ops.TypePopClip:
+			state.clip = state.clip.parent
+
+		case ops.TypeColor:
+			state.mat = rasterColor
+			state.color = f32color.LinearFromSRGB(color.NRGBA{
+				R: encOp.Data[1], G: encOp.Data[2], B: encOp.Data[3], A: encOp.Data[4],
+			})
+		case ops.TypeLinearGradient:
+			state.mat = rasterLinearGradient
+			d := encOp.Data
+			pt := func(off int) f32.Point {
+				return f32.Point{
+					X: math.Float32frombits(bo.Uint32(d[off:])),
+					Y: math.Float32frombits(bo.Uint32(d[off+4:])),
+				}
+			}
+			col := func(off int) f32color.RGBA {
+				return f32color.LinearFromSRGB(color.NRGBA{R: d[off], G: d[off+1], B: d[off+2], A: d[off+3]})
+			}
+			state.stop1, state.stop2 = pt(1), pt(9)
+			state.color1, state.color2 = col(17), col(21)
+		case ops.TypeImage:
+			state.mat = rasterImage
+			state.image = nil
+			if encOp.Refs[1] != nil {
+				state.image = encOp.Refs[0].(*image.RGBA)
+			}
+			state.filter = encOp.Data[1]
+		case ops.TypePaint:
+			r.paint(&state)
+		case ops.TypeSave:
+			id := ops.DecodeSave(encOp.Data)
+			if extra := id - len(r.states) + 1; extra > 0 {
+				r.states = append(r.states, make([]f32.Affine2D, extra)...)
+			}
+			r.states[id] = state.t
+		case ops.TypeLoad:
+			reset()
+			state.t = r.states[ops.DecodeLoad(encOp.Data)]
+		}
+	}
+}
+
+// paint fills the clip area of state with its material.
+func (r *rasterizer) paint(state *rasterState) {
+	var area *image.Alpha
+	if state.clip != nil {
+		area = state.clip.mask
+	}
+	if state.mat == rasterImage {
+		if state.image == nil {
+			return
+		}
+		// Images are bounded by their transformed rectangle.
+		r.segs = r.segs[:0]
+		r.rectSegments(state.t, f32.FRect(image.Rectangle{Max: state.image.Rect.Size()}))
+		area = r.mask(state.clip)
+	}
+	bounds := image.Rectangle{Max: r.size}
+	if area != nil {
+		bounds = area.Rect
+	}
+	inv := state.t.Invert()
+	stop1 := state.t.Transform(state.stop1)
+	grad := state.t.Transform(state.stop2).Sub(stop1)
+	gradLen := grad.X*grad.X + grad.Y*grad.Y
+	// Average several samples per pixel for images scaled down, in place
+	// of the mipmaps of the GPU renderers.
+	sx, hx, _, hy, sy, _ := inv.Elems()
+	footprint := math.Max(math.Hypot(float64(sx), float64(hy)), math.Hypot(float64(hx), float64(sy)))
+	samples := clampInt(int(math.Ceil(footprint)), 1, 8)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			cov := float32(1)
+			if area != nil {
+				a := area.Pix[area.PixOffset(x, y)]
+				if a == 0 {
+					continue
+				}
+				cov = float32(a) / 0xff
+			}
+			center := f32.Pt(float32(x)+.5, float32(y)+.5)
+			var src f32color.RGBA
+			switch state.mat {
+			case rasterColor:
+				src = state.color
+			case rasterLinearGradient:
+				var t float32
+				if gradLen > 0 {
+					d := center.Sub(stop1)
+					t = (d.X*grad.X + d.Y*grad.Y) / gradLen
+				}
+				src = mix(state.color1, state.color2, t)
+			case rasterImage:
+				src = r.sampleArea(state.image, state.filter, inv, center, samples)
+			}
+			blend(&r.dst[y*r.size.X+x], src, cov)
+		}
+	}
+}
+
+// sampleArea averages n×n samples of img over the pixel at center.
+func (r *rasterizer) sampleArea(img *image.RGBA, filter byte, inv f32.Affine2D, center f32.Point, n int) f32color.RGBA {
+	if n == 1 {
+		return r.sample(img, filter, inv.Transform(center))
+	}
+	var sum f32color.RGBA
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			off := f32.Pt((float32(j)+.5)/float32(n)-.5, (float32(i)+.5)/float32(n)-.5)
+			c := r.sample(img, filter, inv.Transform(center.Add(off)))
+			sum.R += c.R
+			sum.G += c.G
+			sum.B += c.B
+			sum.A += c.A
+		}
+	}
+	w := 1 / float32(n*n)
+	return f32color.RGBA{R: sum.R * w, G: sum.G * w, B: sum.B * w, A: sum.A * w}
+}
+
+// sample the image at p, in image coordinates.
+func (r *rasterizer) sample(img *image.RGBA, filter byte, p f32.Point) f32color.RGBA {
+	sz := img.Rect.Size()
+	texel := func(x, y int) f32color.RGBA {
+		x = clampInt(x, 0, sz.X-1)
+		y = clampInt(y, 0, sz.Y-1)
+		o := img.PixOffset(img.Rect.Min.X+x, img.Rect.Min.Y+y)
+		c := img.Pix[o : o+4]
+		return f32color.RGBA{
+			R: r.srgb[c[0]],
+			G: r.srgb[c[1]],
+			B: r.srgb[c[2]],
+			A: float32(c[3]) / 0xff,
+		}
+	}
+	// Filter values match paint.FilterLinear and paint.FilterNearest.
+	if filter != 0 {
+		return texel(int(math.Floor(float64(p.X))), int(math.Floor(float64(p.Y))))
+	}
+	fx, fy := float64(p.X-.5), float64(p.Y-.5)
+	x0, y0 := math.Floor(fx), math.Floor(fy)
+	tx, ty := float32(fx-x0), float32(fy-y0)
+	x, y := int(x0), int(y0)
+	top := mix(texel(x, y), texel(x+1, y), tx)
+	bottom := mix(texel(x, y+1), texel(x+1, y+1), tx)
+	return mix(top, bottom, ty)
+}
+
+// mask rasterizes the current path segments into a coverage mask,
+// intersected with the clip area.
+func (r *rasterizer) mask(clip *rasterClip) *image.Alpha {
+	bounds := image.Rectangle{Max: r.size}
+	if clip != nil {
+		bounds = clip.mask.Rect
+	}
+	inf := float32(math.Inf(+1))
+	pb := f32.Rectangle{Min: f32.Pt(inf, inf), Max: f32.Pt(-inf, -inf)}
+	for _, s := range r.segs {
+		for _, p := range s.pts[:s.n] {
+			pb.Min.X = minf(pb.Min.X, p.X)
+			pb.Min.Y = minf(pb.Min.Y, p.Y)
+			pb.Max.X = maxf(pb.Max.X, p.X)
+			pb.Max.Y = maxf(pb.Max.Y, p.Y)
+		}
+	}
+	// Clip before rounding to avoid overflowing the integer range.
+	pb = pb.Intersect(f32.FRect(bounds))
+	if pb.Empty() {
+		return image.NewAlpha(image.Rectangle{})
+	}
+	bounds = pb.Round()
+	m := image.NewAlpha(bounds)
+	r.vec.Reset(bounds.Dx(), bounds.Dy())
+	off := f32.FPt(bounds.Min)
+	open := false
+	for _, s := range r.segs {
+		p := s.pts
+		for i := range p[:s.n] {
+			p[i] = p[i].Sub(off)
+		}
+		switch s.op {
+		case scene.OpGap:
+			if open {
+				r.vec.ClosePath()
+			}
+			r.vec.MoveTo(p[0].X, p[0].Y)
+			open = true
+		case scene.OpLine:
+			r.vec.LineTo(p[0].X, p[0].Y)
+		case scene.OpQuad:
+			r.vec.QuadTo(p[0].X, p[0].Y, p[1].X, p[1].Y)
+		case scene.OpCubic:
+			r.vec.CubeTo(p[0].X, p[0].Y, p[1].X, p[1].Y, p[2].X, p[2].Y)
+		}
+	}
+	if open {
+		r.vec.ClosePath()
+	}
+	r.vec.Draw(m, bounds, image.Opaque, image.Point{})
+	if clip != nil {
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			for x := bounds.Min.X; x < bounds.Max.X; x++ {
+				o := m.PixOffset(x, y)
+				c := clip.mask.Pix[clip.mask.PixOffset(x, y)]
+				m.Pix[o] = uint8((uint32(m.Pix[o])*uint32(c) + 0x7f) / 0xff)
+			}
+		}
+	}
+	return m
+}
+
+// rectSegments adds the outline of r transformed by t. The segments
+// reuse scene.OpGap for starting a new contour.
+func (r *rasterizer) rectSegments(t f32.Affine2D, rect f32.Rectangle) {
+	r.moveTo(t.Transform(rect.Min))
+	r.lineTo(t.Transform(f32.Pt(rect.Max.X, rect.Min.Y)))
+	r.lineTo(t.Transform(rect.Max))
+	r.lineTo(t.Transform(f32.Pt(rect.Min.X, rect.Max.Y)))
+}
+
+func (r *rasterizer) outlineSegments(t f32.Affine2D, path []byte) {
+	bo := binary.LittleEndian
+	var (
+		pen     f32.Point
+		contour uint32
+		started bool
+	)
+	start := func(c uint32, from f32.Point) {
+		if !started || c != contour || from != pen {
+			r.moveTo(t.Transform(from))
+		}
+		started, contour = true, c
+	}
+	for len(path) >= scene.CommandSize+4 {
+		c := bo.Uint32(path)
+		cmd := ops.DecodeCommand(path[4:])
+		switch cmd.Op() {
+		case scene.OpLine:
+			from, to := scene.DecodeLine(cmd)
+			start(c, from)
+			r.lineTo(t.Transform(to))
+			pen = to
+		case scene.OpGap:
+			from, to := scene.DecodeGap(cmd)
+			start(c, from)
+			r.lineTo(t.Transform(to))
+			pen = to
+		case scene.OpQuad:
+			from, ctrl, to := scene.DecodeQuad(cmd)
+			start(c, from)
+			r.segs = append(r.segs, pathSegment{op: scene.OpQuad, pts: [3]f32.Point{t.Transform(ctrl), t.Transform(to)}, n: 2})
+			pen = to
+		case scene.OpCubic:
+			from, ctrl0, ctrl1, to := scene.DecodeCubic(cmd)
+			start(c, from)
+			r.segs = append(r.segs, pathSegment{op: scene.OpCubic, pts: [3]f32.Point{t.Transform(ctrl0), t.Transform(ctrl1), t.Transform(to)}, n: 3})
+			pen = to
+		default:
+			panic("unsupported scene command")
+		}
+		path = path[scene.CommandSize+4:]
+	}
+}
+
+func (r *rasterizer) strokeSegments(t f32.Affine2D, path []byte, width float32) {
+	quads := stroke.StrokePathCommands(stroke.StrokeStyle{Width: width}, path)
+	var (
+		pen     f32.Point
+		contour uint32
+	)
+	for i, q := range quads {
+		if i == 0 || q.Contour != contour || q.Quad.From != pen {
+			r.moveTo(t.Transform(q.Quad.From))
+		}
+		contour, pen = q.Contour, q.Quad.To
+		r.segs = append(r.segs, pathSegment{op: scene.OpQuad, pts: [3]f32.Point{t.Transform(q.Quad.Ctrl), t.Transform(q.Quad.To)}, n: 2})
+	}
+}
+
+func (r *rasterizer) moveTo(p f32.Point) {
+	r.segs = append(r.segs, pathSegment{op: scene.OpGap, pts: [3]f32.Point{p}, n: 1})
+}
+
+func (r *rasterizer) lineTo(p f32.Point) {
+	r.segs = append(r.segs, pathSegment{op: scene.OpLine, pts: [3]f32.Point{p}, n: 1})
+}
+
+// blend src with coverage cov over dst.
+func blend(dst *f32color.RGBA, src f32color.RGBA, cov float32) {
+	a := 1 - src.A*cov
+	dst.R = src.R*cov + dst.R*a
+	dst.G = src.G*cov + dst.G*a
+	dst.B = src.B*cov + dst.B*a
+	dst.A = src.A*cov + dst.A*a
+}
+
+func mix(c1, c2 f32color.RGBA, t float32) f32color.RGBA {
+	t = minf(maxf(t, 0), 1)
+	return f32color.RGBA{
+		R: c1.R + (c2.R-c1.R)*t,
+		G: c1.G + (c2.G-c1.G)*t,
+		B: c1.B + (c2.B-c1.B)*t,
+		A: c1.A + (c2.A-c1.A)*t,
+	}
+}
+
+func clampInt(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
+
+func minf(a, b float32) float32 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxf(a, b float32) float32 {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -426,6 +426,89 @@ func TestImageRGBA_ScaleNearest(t *testing.T) {
 	})
 }
 
+func TestCheckerOp(t *testing.T) {
+	runPixels(t, func(o *op.Ops) {
+		paint.CheckerOp{Rect: image.Rect(-5, -5, 100, 60), Size: 16, Color1: red, Color2: blue}.Add(o)
+	}, func(r result) {
+		// The cell at the origin has Color1.
+		r.expect(0, 0, colornames.Red)
+		r.expect(15, 15, colornames.Red)
+		r.expect(16, 0, colornames.Blue)
+		r.expect(0, 16, colornames.Blue)
+		r.expect(16, 16, colornames.Red)
+		r.expect(99, 59, colornames.Blue)
+		r.expect(100, 0, transparent)
+		r.expect(0, 60, transparent)
+	})
+}
+
+func TestStripesOp(t *testing.T) {
+	// The stripes are aligned to the center of the rectangle, at 64.
+	runPixels(t, func(o *op.Ops) {
+		paint.StripesOp{Rect: image.Rect(0, 0, 128, 64), Width: 8, Color1: red, Color2: blue}.Add(o)
+	}, func(r result) {
+		r.expect(10, 4, colornames.Blue)
+		r.expect(10, 12, colornames.Red)
+		r.expect(100, 52, colornames.Blue)
+		r.expect(100, 60, colornames.Red)
+		r.expect(10, 64, transparent)
+	})
+	runPixels(t, func(o *op.Ops) {
+		paint.StripesOp{Rect: image.Rect(0, 0, 128, 128), Width: 8, Angle: math.Pi / 2, Color1: red, Color2: blue}.Add(o)
+	}, func(r result) {
+		r.expect(4, 10, colornames.Red)
+		r.expect(12, 10, colornames.Blue)
+		r.expect(52, 100, colornames.Red)
+		r.expect(60, 100, colornames.Blue)
+	})
+}
+
+func TestNoiseOp(t *testing.T) {
+	noise := paint.NoiseOp{Rect: image.Rect(0, 0, 100, 100), Scale: 10, Seed: 1, Color1: black, Color2: red}
+	var whole *image.RGBA
+	runPixels(t, func(o *op.Ops) {
+		noise.Add(o)
+	}, func(r result) {
+		whole = r.img
+		r.expect(100, 50, transparent)
+		r.expect(50, 100, transparent)
+		var first color.RGBA
+		varies := false
+		for y := 0; y < 100; y++ {
+			for x := 0; x < 100; x++ {
+				c := r.img.RGBAAt(x, y)
+				if c.G != 0 || c.B != 0 || c.A != 0xff {
+					t.Fatalf("noise at (%d, %d) is %v, want opaque colors between black and red", x, y, c)
+				}
+				if x == 0 && y == 0 {
+					first = c
+				}
+				varies = varies || c != first
+			}
+		}
+		if !varies {
+			t.Error("uniform noise")
+		}
+	})
+	if whole == nil {
+		return
+	}
+	// Noise painted in parts matches the noise painted at once.
+	runPixels(t, func(o *op.Ops) {
+		left, right := noise, noise
+		left.Rect.Max.X = 45
+		right.Rect.Min.X = 45
+		left.Add(o)
+		right.Add(o)
+	}, func(r result) {
+		for y := 0; y < 100; y += 7 {
+			for x := 0; x < 100; x += 3 {
+				r.expect(x, y, whole.RGBAAt(x, y))
+			}
+		}
+	})
+}
+
 func TestGapsInPath(t *testing.T) {
 	ops := new(op.Ops)
 	var p clip.Path
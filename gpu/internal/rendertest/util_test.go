@@ -94,6 +94,17 @@ func run(t *testing.T, f func(o *op.Ops), c func(r result)) {
 	}
 }
 
+// runPixels is like run, but checks only the pixels asserted by c, for
+// drawings without a reference image.
+func runPixels(t *testing.T, f func(o *op.Ops), c func(r result)) {
+	img, err := drawImage(t, 128, new(op.Ops), f)
+	if err != nil {
+		t.Error("error rendering:", err)
+		return
+	}
+	c(result{t: t, img: img})
+}
+
 func frame(f func(o *op.Ops), c func(r result)) frameT {
 	return frameT{f: f, c: c}
 }
@@ -0,0 +1,170 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+/*
+Package i18n implements message catalogs for localizing user
+interfaces.
+
+A Catalog maps message keys to their translations in each language,
+identified by BCP-47 tags such as "en" or "pt-BR". Messages may have
+plural forms, selected by the plural rules of the language, and
+arguments, formatted with fmt.Sprintf. Translations can reorder
+arguments with explicit indexes such as "%[2]s".
+
+Widgets look up messages through layout.Context, in the catalog of the
+context for its Locale. Switching the language of a program at runtime
+is a matter of changing the Locale of the contexts; the messages are
+looked up anew in every frame.
+
+The built-in strings of Gio, such as the labels of the window
+decoration buttons, are in the Default catalog, under keys starting with
+"gio.", with English messages. Programs localize them by adding
+translations to Default.
+*/
+package i18n
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Catalog holds messages by language and key. The zero value is an
+// empty catalog ready to use. A Catalog is safe for concurrent use, so
+// that translations can be loaded while windows are running.
+type Catalog struct {
+	mu    sync.RWMutex
+	langs map[string]map[string]Message
+	// fallback is the language of last resort.
+	fallback string
+}
+
+// Message is a translated message, with its plural forms. Forms that
+// are empty are replaced by Other.
+type Message struct {
+	Zero, One, Two, Few, Many, Other string
+}
+
+// Default is the catalog of the built-in strings of Gio, and the
+// catalog of contexts without one. Messages missing from other catalogs
+// are looked up in Default.
+var Default = newDefault()
+
+// NewCatalog returns an empty catalog whose messages for fallback are
+// used for languages that lack a message.
+func NewCatalog(fallback string) *Catalog {
+	return &Catalog{fallback: fallback}
+}
+
+// Set the message for key in lang, without plural forms.
+func (c *Catalog) Set(lang, key, text string) {
+	c.SetMessage(lang, key, Message{Other: text})
+}
+
+// SetMessage sets the message for key in lang.
+func (c *Catalog) SetMessage(lang, key string, m Message) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.langs == nil {
+		c.langs = make(map[string]map[string]Message)
+	}
+	lang = canonical(lang)
+	msgs := c.langs[lang]
+	if msgs == nil {
+		msgs = make(map[string]Message)
+		c.langs[lang] = msgs
+	}
+	msgs[key] = m
+}
+
+// Text returns the message for key in lang, formatted with args. The
+// key itself is returned if no message is found.
+func (c *Catalog) Text(lang, key string, args ...interface{}) string {
+	m, ok := c.lookup(lang, key)
+	if !ok {
+		return key
+	}
+	return format(m.Other, args)
+}
+
+// Plural is like Text, but selects the plural form of the message for
+// the count n, according to the plural rules of lang. The count is not
+// an argument; pass it among args to include it in the message.
+func (c *Catalog) Plural(lang, key string, n int, args ...interface{}) string {
+	m, ok := c.lookup(lang, key)
+	if !ok {
+		return key
+	}
+	var text string
+	switch PluralRuleFor(lang)(n) {
+	case Zero:
+		text = m.Zero
+	case One:
+		text = m.One
+	case Two:
+		text = m.Two
+	case Few:
+		text = m.Few
+	case Many:
+		text = m.Many
+	}
+	if text == "" {
+		text = m.Other
+	}
+	return format(text, args)
+}
+
+// lookup finds the message for key in lang, its base language, the
+// fallback language, and finally in Default.
+func (c *Catalog) lookup(lang, key string) (Message, bool) {
+	if c == nil {
+		c = Default
+	}
+	c.mu.RLock()
+	for tag := canonical(lang); ; {
+		if m, ok := c.langs[tag][key]; ok {
+			c.mu.RUnlock()
+			return m, true
+		}
+		i := strings.LastIndexByte(tag, '-')
+		if i == -1 {
+			break
+		}
+		tag = tag[:i]
+	}
+	m, ok := c.langs[canonical(c.fallback)][key]
+	c.mu.RUnlock()
+	if !ok && c != Default {
+		return Default.lookup(lang, key)
+	}
+	return m, ok
+}
+
+func newDefault() *Catalog {
+	c := NewCatalog("en")
+	for key, text := range builtin {
+		c.Set("en", key, text)
+	}
+	return c
+}
+
+// builtin are the English messages of the built-in strings.
+var builtin = map[string]string{
+	"gio.decorations.minimize": "Minimize",
+	"gio.decorations.maximize": "Maximize",
+	"gio.decorations.restore":  "Restore",
+	"gio.decorations.close":    "Close",
+	"gio.retry":                "Retry",
+}
+
+// canonical returns lang with underscores replaced by hyphens and in
+// lower case, because language tags are case insensitive.
+func canonical(lang string) string {
+	return strings.ToLower(strings.ReplaceAll(lang, "_", "-"))
+}
+
+func format(text string, args []interface{}) string {
+	if len(args) == 0 {
+		return text
+	}
+	return fmt.Sprintf(text, args...)
+}
@@ -0,0 +1,89 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package i18n
+
+import "testing"
+
+func TestCatalogLookup(t *testing.T) {
+	c := NewCatalog("en")
+	c.Set("en", "greeting", "Hello, %s")
+	c.Set("pt", "greeting", "Olá, %s")
+	c.Set("pt-BR", "bye", "Tchau")
+	c.Set("de", "order", "%[2]s von %[1]s")
+	tests := []struct {
+		lang, key string
+		args      []interface{}
+		want      string
+	}{
+		{"en", "greeting", []interface{}{"Gio"}, "Hello, Gio"},
+		{"pt_BR", "greeting", []interface{}{"Gio"}, "Olá, Gio"},
+		{"PT-br", "bye", nil, "Tchau"},
+		{"fr", "greeting", []interface{}{"Gio"}, "Hello, Gio"},
+		{"de", "order", []interface{}{"a", "b"}, "b von a"},
+		{"en", "missing", nil, "missing"},
+		{"de", "gio.decorations.close", nil, "Close"},
+	}
+	for _, tc := range tests {
+		if got := c.Text(tc.lang, tc.key, tc.args...); got != tc.want {
+			t.Errorf("Text(%q, %q) = %q, want %q", tc.lang, tc.key, got, tc.want)
+		}
+	}
+	var nilCatalog *Catalog
+	if got := nilCatalog.Text("en", "gio.decorations.minimize"); got != "Minimize" {
+		t.Errorf("nil catalog: got %q", got)
+	}
+}
+
+func TestCatalogPlural(t *testing.T) {
+	var c Catalog
+	c.SetMessage("en", "files", Message{One: "%d file", Other: "%d files"})
+	c.SetMessage("ru", "files", Message{One: "%d файл", Few: "%d файла", Many: "%d файлов"})
+	c.SetMessage("ja", "files", Message{Other: "%d ファイル"})
+	tests := []struct {
+		lang string
+		n    int
+		want string
+	}{
+		{"en", 1, "1 file"},
+		{"en", 0, "0 files"},
+		{"en-GB", 2, "2 files"},
+		{"ru", 1, "1 файл"},
+		{"ru", 3, "3 файла"},
+		{"ru", 12, "12 файлов"},
+		{"ru", 21, "21 файл"},
+		{"ja", 1, "1 ファイル"},
+	}
+	for _, tc := range tests {
+		if got := c.Plural(tc.lang, "files", tc.n, tc.n); got != tc.want {
+			t.Errorf("Plural(%q, %d) = %q, want %q", tc.lang, tc.n, got, tc.want)
+		}
+	}
+}
+
+func TestPluralRules(t *testing.T) {
+	tests := []struct {
+		lang string
+		n    int
+		want PluralForm
+	}{
+		{"fr", 0, One},
+		{"pl", 22, Few},
+		{"pl", 25, Many},
+		{"cs", 4, Few},
+		{"cs", 5, Other},
+		{"ar", 0, Zero},
+		{"ar", 2, Two},
+		{"ar", 105, Few},
+		{"ar", 111, Many},
+		{"ar", 100, Other},
+	}
+	for _, tc := range tests {
+		if got := PluralRuleFor(tc.lang)(tc.n); got != tc.want {
+			t.Errorf("%s plural of %d is %v, want %v", tc.lang, tc.n, got, tc.want)
+		}
+	}
+	RegisterPluralRule("xx", func(n int) PluralForm { return Many })
+	if got := PluralRuleFor("xx-YY")(1); got != Many {
+		t.Errorf("registered rule: got %v, want Many", got)
+	}
+}
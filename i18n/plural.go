@@ -0,0 +1,175 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package i18n
+
+import (
+	"strings"
+	"sync"
+)
+
+// PluralForm is a plural category of the Unicode CLDR.
+type PluralForm uint8
+
+// PluralRule returns the plural form of a language for the count n.
+type PluralRule func(n int) PluralForm
+
+// The plural forms. Languages use different subsets of them; all use
+// Other.
+const (
+	Other PluralForm = iota
+	Zero
+	One
+	Two
+	Few
+	Many
+)
+
+var plurals struct {
+	mu    sync.RWMutex
+	rules map[string]PluralRule
+}
+
+// RegisterPluralRule sets the plural rule of lang, replacing the
+// built-in rule, if any.
+func RegisterPluralRule(lang string, rule PluralRule) {
+	plurals.mu.Lock()
+	defer plurals.mu.Unlock()
+	if plurals.rules == nil {
+		plurals.rules = make(map[string]PluralRule)
+	}
+	plurals.rules[canonical(lang)] = rule
+}
+
+// PluralRuleFor returns the plural rule for lang, or for its base
+// language. Languages without a rule select One for 1 and Other for
+// other counts, as English does.
+func PluralRuleFor(lang string) PluralRule {
+	tag := canonical(lang)
+	plurals.mu.RLock()
+	for t := tag; ; {
+		if r, ok := plurals.rules[t]; ok {
+			plurals.mu.RUnlock()
+			return r
+		}
+		i := strings.LastIndexByte(t, '-')
+		if i == -1 {
+			break
+		}
+		t = t[:i]
+	}
+	plurals.mu.RUnlock()
+	if i := strings.IndexByte(tag, '-'); i != -1 {
+		tag = tag[:i]
+	}
+	switch tag {
+	case "ja", "zh", "ko", "th", "vi", "id", "ms", "lo", "my", "km":
+		return pluralNone
+	case "fr", "pt":
+		return pluralFrench
+	case "ru", "uk", "be", "sr", "hr", "bs":
+		return pluralSlavic
+	case "pl":
+		return pluralPolish
+	case "cs", "sk":
+		return pluralCzech
+	case "ar":
+		return pluralArabic
+	default:
+		return pluralEnglish
+	}
+}
+
+func pluralNone(n int) PluralForm { return Other }
+
+func pluralEnglish(n int) PluralForm {
+	if n == 1 {
+		return One
+	}
+	return Other
+}
+
+func pluralFrench(n int) PluralForm {
+	if n == 0 || n == 1 {
+		return One
+	}
+	return Other
+}
+
+func pluralSlavic(n int) PluralForm {
+	n = abs(n)
+	switch n10, n100 := n%10, n%100; {
+	case n10 == 1 && n100 != 11:
+		return One
+	case n10 >= 2 && n10 <= 4 && (n100 < 12 || n100 > 14):
+		return Few
+	default:
+		return Many
+	}
+}
+
+func pluralPolish(n int) PluralForm {
+	n = abs(n)
+	switch n10, n100 := n%10, n%100; {
+	case n == 1:
+		return One
+	case n10 >= 2 && n10 <= 4 && (n100 < 12 || n100 > 14):
+		return Few
+	default:
+		return Many
+	}
+}
+
+func pluralCzech(n int) PluralForm {
+	switch n {
+	case 1:
+		return One
+	case 2, 3, 4:
+		return Few
+	default:
+		return Other
+	}
+}
+
+func pluralArabic(n int) PluralForm {
+	n = abs(n)
+	switch n100 := n % 100; {
+	case n == 0:
+		return Zero
+	case n == 1:
+		return One
+	case n == 2:
+		return Two
+	case n100 >= 3 && n100 <= 10:
+		return Few
+	case n100 >= 11:
+		return Many
+	default:
+		return Other
+	}
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func (f PluralForm) String() string {
+	switch f {
+	case Other:
+		return "Other"
+	case Zero:
+		return "Zero"
+	case One:
+		return "One"
+	case Two:
+		return "Two"
+	case Few:
+		return "Few"
+	case Many:
+		return "Many"
+	default:
+		panic("invalid PluralForm")
+	}
+}
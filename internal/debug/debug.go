@@ -11,14 +11,18 @@ import (
 )
 
 const (
-	debugVariable = "GIODEBUG"
-	textSubsystem = "text"
-	silentFeature = "silent"
+	debugVariable      = "GIODEBUG"
+	textSubsystem      = "text"
+	shortcutsSubsystem = "shortcuts"
+	silentFeature      = "silent"
 )
 
 // Text controls whether the text subsystem has debug logging enabled.
 var Text atomic.Bool
 
+// Shortcuts controls whether conflicting keyboard shortcuts are logged.
+var Shortcuts atomic.Bool
+
 var parseOnce sync.Once
 
 // Parse processes the current value of GIODEBUG. If it is unset, it does nothing.
@@ -38,6 +42,8 @@ func Parse() {
 			switch part {
 			case textSubsystem:
 				Text.Store(true)
+			case shortcutsSubsystem:
+				Shortcuts.Store(true)
 			case silentFeature:
 				silent = true
 			default:
@@ -50,8 +56,9 @@ func Parse() {
 	A comma-delimited list of debug subsystems to enable. Currently recognized systems:
 
 	- %s: text debug info including system font resolution
+	- %s: conflicting keyboard shortcuts
 	- %s: silence this usage message even if GIODEBUG contains invalid content
-`, debugVariable, textSubsystem, silentFeature)
+`, debugVariable, textSubsystem, shortcutsSubsystem, silentFeature)
 		}
 	})
 }
@@ -11,6 +11,11 @@ import (
 )
 
 type Animation struct {
+	// Friction is the rate of deceleration, such that the velocity
+	// decays by the factor e^-Friction every second. The zero value
+	// selects the platform default.
+	Friction float32
+
 	// Current offset in pixels.
 	x float32
 	// Initial time.
@@ -60,12 +65,7 @@ func (f *Animation) Tick(now time.Time) int {
 	if !f.Active() {
 		return 0
 	}
-	var k float32
-	if runtime.GOOS == "darwin" {
-		k = -2 // iOS
-	} else {
-		k = -4.2 // Android and default
-	}
+	k := f.k()
 	t := now.Sub(f.t0)
 	// The acceleration x''(t) of a point mass with a drag
 	// force, f, proportional with velocity, x'(t), is
@@ -93,3 +93,25 @@ func (f *Animation) Tick(now time.Time) int {
 	}
 	return idist
 }
+
+// Velocity returns the velocity of the fling at now, in pixels per
+// second.
+func (f *Animation) Velocity(now time.Time) float32 {
+	if !f.Active() {
+		return 0
+	}
+	k := f.k()
+	return f.v0 * float32(math.Exp(float64(k)*now.Sub(f.t0).Seconds()))
+}
+
+// k returns the drag coefficient of the fling.
+func (f *Animation) k() float32 {
+	switch {
+	case f.Friction > 0:
+		return -f.Friction
+	case runtime.GOOS == "darwin":
+		return -2 // iOS
+	default:
+		return -4.2 // Android and default
+	}
+}
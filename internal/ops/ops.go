@@ -38,6 +38,14 @@ type Ops struct {
 
 	macroStack stack
 	stacks     [_StackKind]stack
+
+	// view is the transform and clip state for Visible, and
+	// transSaves, clipSaves and macroSaves the states saved by
+	// transform, clip and macro pushes.
+	view       view
+	transSaves []f32.Affine2D
+	clipSaves  []view
+	macroSaves []view
 }
 
 type OpType byte
@@ -71,6 +79,7 @@ const (
 	TypeKeyInput
 	TypeKeyFocus
 	TypeKeySoftKeyboard
+	TypeGamepadInput
 	TypeSave
 	TypeLoad
 	TypeAux
@@ -78,6 +87,7 @@ const (
 	TypePopClip
 	TypeProfile
 	TypeCursor
+	TypeImageCursor
 	TypePath
 	TypeStroke
 	TypeSemanticLabel
@@ -85,9 +95,16 @@ const (
 	TypeSemanticClass
 	TypeSemanticSelected
 	TypeSemanticEnabled
+	TypeSemanticValue
+	TypeSemanticRange
 	TypeSnippet
 	TypeSelection
 	TypeActionInput
+	TypeNativeView
+	TypeFocusScope
+	TypePopFocusScope
+	TypeSemanticHelp
+	TypeKeyShortcut
 )
 
 type StackID struct {
@@ -124,6 +141,7 @@ const (
 	TransStack
 	PassStack
 	OpacityStack
+	FocusScopeStack
 	_StackKind
 )
 
@@ -148,15 +166,16 @@ const (
 	TypeLinearGradientLen   = 1 + 8*2 + 4*2
 	TypePassLen             = 1
 	TypePopPassLen          = 1
-	TypePointerInputLen     = 1 + 1 + 1*2 + 2*4 + 2*4
-	TypeClipboardReadLen    = 1
-	TypeClipboardWriteLen   = 1
+	TypePointerInputLen     = 1 + 1 + 1*2 + 2*4 + 2*4 + 4 + 1
+	TypeClipboardReadLen    = 1 + 1
+	TypeClipboardWriteLen   = 1 + 1
 	TypeSourceLen           = 1
 	TypeTargetLen           = 1
 	TypeOfferLen            = 1
-	TypeKeyInputLen         = 1 + 1
+	TypeKeyInputLen         = 1 + 1 + 1
 	TypeKeyFocusLen         = 1 + 1
 	TypeKeySoftKeyboardLen  = 1 + 1
+	TypeGamepadInputLen     = 1
 	TypeSaveLen             = 1 + 4
 	TypeLoadLen             = 1 + 4
 	TypeAuxLen              = 1
@@ -164,6 +183,7 @@ const (
 	TypePopClipLen          = 1
 	TypeProfileLen          = 1
 	TypeCursorLen           = 2
+	TypeImageCursorLen      = 1
 	TypePathLen             = 8 + 1
 	TypeStrokeLen           = 1 + 4
 	TypeSemanticLabelLen    = 1
@@ -171,9 +191,16 @@ const (
 	TypeSemanticClassLen    = 2
 	TypeSemanticSelectedLen = 2
 	TypeSemanticEnabledLen  = 2
+	TypeSemanticValueLen    = 1
+	TypeSemanticRangeLen    = 1 + 4 + 4
 	TypeSnippetLen          = 1 + 4 + 4
 	TypeSelectionLen        = 1 + 2*4 + 2*4 + 4 + 4
 	TypeActionInputLen      = 1 + 1
+	TypeNativeViewLen       = 1 + 8 + 4
+	TypeFocusScopeLen       = 1
+	TypePopFocusScopeLen    = 1
+	TypeSemanticHelpLen     = 1
+	TypeKeyShortcutLen      = 1
 )
 
 func (op *ClipOp) Decode(data []byte) {
@@ -193,6 +220,10 @@ func (op *ClipOp) Decode(data []byte) {
 func Reset(o *Ops) {
 	o.macroStack = stack{}
 	o.stacks = [_StackKind]stack{}
+	o.view = view{}
+	o.transSaves = o.transSaves[:0]
+	o.clipSaves = o.clipSaves[:0]
+	o.macroSaves = o.macroSaves[:0]
 	// Leave references to the GC.
 	for i := range o.refs {
 		o.refs[i] = nil
@@ -238,11 +269,17 @@ func WriteMulti(o *Ops, n int) []byte {
 }
 
 func PushMacro(o *Ops) StackID {
+	// The position of the macro is unknown until it is called.
+	o.macroSaves = append(o.macroSaves, o.view)
+	o.view = view{}
 	return o.macroStack.push()
 }
 
 func PopMacro(o *Ops, id StackID) {
 	o.macroStack.pop(id)
+	n := len(o.macroSaves) - 1
+	o.view = o.macroSaves[n]
+	o.macroSaves = o.macroSaves[:n]
 }
 
 func FillMacro(o *Ops, startPC PC) {
@@ -267,6 +304,7 @@ func AddCall(o *Ops, callOps *Ops, pc PC, end PC) {
 }
 
 func PushOp(o *Ops, kind StackKind) (StackID, uint32) {
+	saveView(o, kind)
 	return o.stacks[kind].push(), o.macroStack.currentID
 }
 
@@ -275,6 +313,7 @@ func PopOp(o *Ops, kind StackKind, sid StackID, macroID uint32) {
 		panic("stack push and pop must not cross macro boundary")
 	}
 	o.stacks[kind].pop(sid)
+	restoreView(o, kind)
 }
 
 func Write1(o *Ops, n int, ref1 interface{}) []byte {
@@ -433,14 +472,15 @@ var opProps = [0x100]opProp{
 	TypePass:             {Size: TypePassLen, NumRefs: 0},
 	TypePopPass:          {Size: TypePopPassLen, NumRefs: 0},
 	TypePointerInput:     {Size: TypePointerInputLen, NumRefs: 1},
-	TypeClipboardRead:    {Size: TypeClipboardReadLen, NumRefs: 1},
-	TypeClipboardWrite:   {Size: TypeClipboardWriteLen, NumRefs: 1},
+	TypeClipboardRead:    {Size: TypeClipboardReadLen, NumRefs: 2},
+	TypeClipboardWrite:   {Size: TypeClipboardWriteLen, NumRefs: 2},
 	TypeSource:           {Size: TypeSourceLen, NumRefs: 2},
 	TypeTarget:           {Size: TypeTargetLen, NumRefs: 2},
 	TypeOffer:            {Size: TypeOfferLen, NumRefs: 3},
 	TypeKeyInput:         {Size: TypeKeyInputLen, NumRefs: 2},
 	TypeKeyFocus:         {Size: TypeKeyFocusLen, NumRefs: 1},
 	TypeKeySoftKeyboard:  {Size: TypeKeySoftKeyboardLen, NumRefs: 0},
+	TypeGamepadInput:     {Size: TypeGamepadInputLen, NumRefs: 1},
 	TypeSave:             {Size: TypeSaveLen, NumRefs: 0},
 	TypeLoad:             {Size: TypeLoadLen, NumRefs: 0},
 	TypeAux:              {Size: TypeAuxLen, NumRefs: 0},
@@ -448,6 +488,7 @@ var opProps = [0x100]opProp{
 	TypePopClip:          {Size: TypePopClipLen, NumRefs: 0},
 	TypeProfile:          {Size: TypeProfileLen, NumRefs: 1},
 	TypeCursor:           {Size: TypeCursorLen, NumRefs: 0},
+	TypeImageCursor:      {Size: TypeImageCursorLen, NumRefs: 1},
 	TypePath:             {Size: TypePathLen, NumRefs: 0},
 	TypeStroke:           {Size: TypeStrokeLen, NumRefs: 0},
 	TypeSemanticLabel:    {Size: TypeSemanticLabelLen, NumRefs: 1},
@@ -455,9 +496,16 @@ var opProps = [0x100]opProp{
 	TypeSemanticClass:    {Size: TypeSemanticClassLen, NumRefs: 0},
 	TypeSemanticSelected: {Size: TypeSemanticSelectedLen, NumRefs: 0},
 	TypeSemanticEnabled:  {Size: TypeSemanticEnabledLen, NumRefs: 0},
+	TypeSemanticValue:    {Size: TypeSemanticValueLen, NumRefs: 1},
+	TypeSemanticRange:    {Size: TypeSemanticRangeLen, NumRefs: 0},
 	TypeSnippet:          {Size: TypeSnippetLen, NumRefs: 2},
 	TypeSelection:        {Size: TypeSelectionLen, NumRefs: 1},
 	TypeActionInput:      {Size: TypeActionInputLen, NumRefs: 0},
+	TypeNativeView:       {Size: TypeNativeViewLen, NumRefs: 0},
+	TypeFocusScope:       {Size: TypeFocusScopeLen, NumRefs: 1},
+	TypePopFocusScope:    {Size: TypePopFocusScopeLen, NumRefs: 0},
+	TypeSemanticHelp:     {Size: TypeSemanticHelpLen, NumRefs: 1},
+	TypeKeyShortcut:      {Size: TypeKeyShortcutLen, NumRefs: 2},
 }
 
 func (t OpType) props() (size, numRefs uint32) {
@@ -521,6 +569,8 @@ func (t OpType) String() string {
 		return "KeyFocus"
 	case TypeKeySoftKeyboard:
 		return "KeySoftKeyboard"
+	case TypeGamepadInput:
+		return "GamepadInput"
 	case TypeSave:
 		return "Save"
 	case TypeLoad:
@@ -535,12 +585,24 @@ func (t OpType) String() string {
 		return "Profile"
 	case TypeCursor:
 		return "Cursor"
+	case TypeImageCursor:
+		return "ImageCursor"
 	case TypePath:
 		return "Path"
 	case TypeStroke:
 		return "Stroke"
 	case TypeSemanticLabel:
 		return "SemanticDescription"
+	case TypeNativeView:
+		return "NativeView"
+	case TypeFocusScope:
+		return "FocusScope"
+	case TypePopFocusScope:
+		return "PopFocusScope"
+	case TypeSemanticHelp:
+		return "SemanticHelp"
+	case TypeKeyShortcut:
+		return "KeyShortcut"
 	default:
 		panic("unknown OpType")
 	}
@@ -0,0 +1,110 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package ops
+
+import (
+	"image"
+
+	"github.com/Seikaijyu/gio/f32"
+	f32internal "github.com/Seikaijyu/gio/internal/f32"
+)
+
+// view is the transform and clip state of the operations added so far,
+// shadowing the state a router or renderer will compute from them.
+type view struct {
+	trans f32.Affine2D
+	// clip is the intersection of the clip bounds, in the coordinates of
+	// the root or the current macro. It is valid if clipped is set.
+	clip    f32internal.Rectangle
+	clipped bool
+}
+
+// unbounded is the bounds of an unclipped view.
+const unbounded = 1 << 30
+
+// ViewTransform applies t to the view transform, for transform
+// operations.
+func ViewTransform(o *Ops, t f32.Affine2D) {
+	o.view.trans = o.view.trans.Mul(t)
+}
+
+// ViewClip intersects the view clip with the bounds r, in the current
+// coordinates, for clip operations.
+func ViewClip(o *Ops, r image.Rectangle) {
+	c := transformBounds(o.view.trans, f32internal.FRect(r))
+	if o.view.clipped {
+		c = o.view.clip.Intersect(c)
+	}
+	o.view.clip, o.view.clipped = c, true
+}
+
+// Visible returns the view clip in the current coordinates, and the view
+// transform.
+func Visible(o *Ops) (image.Rectangle, f32.Affine2D) {
+	if !o.view.clipped {
+		return image.Rect(-unbounded, -unbounded, unbounded, unbounded), o.view.trans
+	}
+	r := transformBounds(o.view.trans.Invert(), o.view.clip).Round()
+	if r.Empty() {
+		r = image.Rectangle{}
+	}
+	return r, o.view.trans
+}
+
+// saveView saves the view state changed by a stack operation of kind.
+func saveView(o *Ops, kind StackKind) {
+	switch kind {
+	case TransStack:
+		o.transSaves = append(o.transSaves, o.view.trans)
+	case ClipStack:
+		o.clipSaves = append(o.clipSaves, o.view)
+	}
+}
+
+// restoreView restores the view state saved by saveView.
+func restoreView(o *Ops, kind StackKind) {
+	switch kind {
+	case TransStack:
+		n := len(o.transSaves) - 1
+		o.view.trans = o.transSaves[n]
+		o.transSaves = o.transSaves[:n]
+	case ClipStack:
+		n := len(o.clipSaves) - 1
+		s := o.clipSaves[n]
+		o.view.clip, o.view.clipped = s.clip, s.clipped
+		o.clipSaves = o.clipSaves[:n]
+	}
+}
+
+// transformBounds returns the bounds of r transformed by t.
+func transformBounds(t f32.Affine2D, r f32internal.Rectangle) f32internal.Rectangle {
+	if t == (f32.Affine2D{}) {
+		return r
+	}
+	corners := [4]f32.Point{
+		t.Transform(r.Min),
+		t.Transform(f32.Pt(r.Max.X, r.Min.Y)),
+		t.Transform(r.Max),
+		t.Transform(f32.Pt(r.Min.X, r.Max.Y)),
+	}
+	b := f32internal.Rectangle{Min: corners[0], Max: corners[0]}
+	for _, c := range corners[1:] {
+		b.Min.X, b.Max.X = min32(b.Min.X, c.X), max32(b.Max.X, c.X)
+		b.Min.Y, b.Max.Y = min32(b.Min.Y, c.Y), max32(b.Max.Y, c.Y)
+	}
+	return b
+}
+
+func min32(a, b float32) float32 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max32(a, b float32) float32 {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -11,27 +11,63 @@ import (
 // Event is generated when the clipboard content is requested.
 type Event struct {
 	Text string
+	// Primary is set for the content of the primary selection.
+	Primary bool
+	// Content is the clipboard content in the formats requested by
+	// ReadOp.Types that are available, in the order requested.
+	Content []Content
+}
+
+// Content is clipboard content in a format other than plain text.
+type Content struct {
+	// Type is the MIME type of Data, such as "text/html".
+	Type string
+	Data string
 }
 
 // ReadOp requests the text of the clipboard, delivered to
 // the current handler through an Event.
 type ReadOp struct {
 	Tag event.Tag
+	// Primary requests the primary selection instead of the
+	// clipboard. The primary selection is the text most recently
+	// selected on X11 and Wayland, pasted with the middle mouse
+	// button. Other platforms have no primary selection and deliver
+	// empty Events.
+	Primary bool
+	// Types are the MIME types of the formats to read in addition to
+	// text, such as "text/html" and "text/rtf". Platforms without
+	// support for a format omit it from the Event.
+	Types []string
 }
 
 // WriteOp copies Text to the clipboard.
 type WriteOp struct {
 	Text string
+	// Primary copies Text to the primary selection instead of the
+	// clipboard. It is ignored on platforms without a primary
+	// selection.
+	Primary bool
+	// Content is Text in other formats, such as HTML with the styles of
+	// the text. Formats not supported by the platform are ignored, as
+	// is Content for the primary selection.
+	Content []Content
 }
 
 func (h ReadOp) Add(o *op.Ops) {
-	data := ops.Write1(&o.Internal, ops.TypeClipboardReadLen, h.Tag)
+	data := ops.Write2(&o.Internal, ops.TypeClipboardReadLen, h.Tag, h.Types)
 	data[0] = byte(ops.TypeClipboardRead)
+	if h.Primary {
+		data[1] = 1
+	}
 }
 
 func (h WriteOp) Add(o *op.Ops) {
-	data := ops.Write1String(&o.Internal, ops.TypeClipboardWriteLen, h.Text)
+	data := ops.Write2String(&o.Internal, ops.TypeClipboardWriteLen, h.Content, h.Text)
 	data[0] = byte(ops.TypeClipboardWrite)
+	if h.Primary {
+		data[1] = 1
+	}
 }
 
 func (Event) ImplementsEvent() {}
@@ -0,0 +1,203 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+/*
+Package gamepad implements game controller input.
+
+Gamepad events are not tied to the pointer position or the keyboard
+focus; every handler registered with an InputOp receives the events of
+every connected controller. A Connect event is sent when a controller
+becomes available, followed by Press, Release and Move events as its
+buttons and analog axes change.
+
+Controllers are mapped to the layout of an Xbox controller: ButtonA is
+the bottom face button, ButtonY the top one, and so on.
+
+Gamepad input is supported on Windows (XInput), macOS and iOS (the
+GameController framework), Linux (evdev) and in browsers (the Gamepad
+API). Controllers are not polled until the first InputOp is added.
+*/
+package gamepad
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Seikaijyu/gio/internal/ops"
+	"github.com/Seikaijyu/gio/io/event"
+	"github.com/Seikaijyu/gio/op"
+)
+
+// InputOp declares a handler ready for gamepad events.
+type InputOp struct {
+	Tag event.Tag
+}
+
+// Event is a gamepad event.
+type Event struct {
+	Kind Kind
+	// ID identifies the controller for the duration of its
+	// connection.
+	ID ID
+	// Name is the name of the controller, as reported by the
+	// platform. It is only set for Connect events.
+	Name string
+	// Button is the button pressed or released for Press and
+	// Release events.
+	Button Button
+	// Axis is the analog axis that moved for Move events.
+	Axis Axis
+	// Value is the position of Axis for Move events. Sticks range
+	// from -1 to 1, with positive values towards the right and
+	// the bottom. Triggers range from 0 to 1.
+	Value float32
+	// Time is when the event was received. The
+	// timestamp is relative to an undefined base.
+	Time time.Duration
+}
+
+// ID identifies a controller.
+type ID uint32
+
+// Kind of an Event.
+type Kind uint8
+
+// Button is a gamepad button.
+type Button uint8
+
+// Axis is an analog gamepad axis.
+type Axis uint8
+
+const (
+	// Connect is sent when a controller is connected, and for
+	// controllers already connected when gamepad input starts.
+	Connect Kind = iota
+	// Disconnect is sent when a controller is disconnected.
+	Disconnect
+	// Press of a button.
+	Press
+	// Release of a button.
+	Release
+	// Move of an analog axis.
+	Move
+)
+
+const (
+	ButtonA Button = iota
+	ButtonB
+	ButtonX
+	ButtonY
+	ButtonLeftShoulder
+	ButtonRightShoulder
+	ButtonBack
+	ButtonStart
+	ButtonGuide
+	ButtonLeftThumb
+	ButtonRightThumb
+	ButtonDPadUp
+	ButtonDPadDown
+	ButtonDPadLeft
+	ButtonDPadRight
+)
+
+const (
+	AxisLeftX Axis = iota
+	AxisLeftY
+	AxisRightX
+	AxisRightY
+	AxisLeftTrigger
+	AxisRightTrigger
+)
+
+func (op InputOp) Add(o *op.Ops) {
+	if op.Tag == nil {
+		panic("Tag must be non-nil")
+	}
+	data := ops.Write1(&o.Internal, ops.TypeGamepadInputLen, op.Tag)
+	data[0] = byte(ops.TypeGamepadInput)
+}
+
+func (Event) ImplementsEvent() {}
+
+func (e Event) String() string {
+	switch e.Kind {
+	case Press, Release:
+		return fmt.Sprintf("%v %v %v", e.ID, e.Kind, e.Button)
+	case Move:
+		return fmt.Sprintf("%v %v %v %v", e.ID, e.Kind, e.Axis, e.Value)
+	default:
+		return fmt.Sprintf("%v %v %q", e.ID, e.Kind, e.Name)
+	}
+}
+
+func (k Kind) String() string {
+	switch k {
+	case Connect:
+		return "Connect"
+	case Disconnect:
+		return "Disconnect"
+	case Press:
+		return "Press"
+	case Release:
+		return "Release"
+	case Move:
+		return "Move"
+	default:
+		panic("unknown gamepad event kind")
+	}
+}
+
+func (b Button) String() string {
+	switch b {
+	case ButtonA:
+		return "ButtonA"
+	case ButtonB:
+		return "ButtonB"
+	case ButtonX:
+		return "ButtonX"
+	case ButtonY:
+		return "ButtonY"
+	case ButtonLeftShoulder:
+		return "ButtonLeftShoulder"
+	case ButtonRightShoulder:
+		return "ButtonRightShoulder"
+	case ButtonBack:
+		return "ButtonBack"
+	case ButtonStart:
+		return "ButtonStart"
+	case ButtonGuide:
+		return "ButtonGuide"
+	case ButtonLeftThumb:
+		return "ButtonLeftThumb"
+	case ButtonRightThumb:
+		return "ButtonRightThumb"
+	case ButtonDPadUp:
+		return "ButtonDPadUp"
+	case ButtonDPadDown:
+		return "ButtonDPadDown"
+	case ButtonDPadLeft:
+		return "ButtonDPadLeft"
+	case ButtonDPadRight:
+		return "ButtonDPadRight"
+	default:
+		panic("unknown gamepad button")
+	}
+}
+
+func (a Axis) String() string {
+	switch a {
+	case AxisLeftX:
+		return "AxisLeftX"
+	case AxisLeftY:
+		return "AxisLeftY"
+	case AxisRightX:
+		return "AxisRightX"
+	case AxisRightY:
+		return "AxisRightY"
+	case AxisLeftTrigger:
+		return "AxisLeftTrigger"
+	case AxisRightTrigger:
+		return "AxisRightTrigger"
+	default:
+		panic("unknown gamepad axis")
+	}
+}
@@ -33,6 +33,10 @@ type InputOp struct {
 	// As a special case, the topmost (first added) InputOp handler receives all
 	// unhandled events.
 	Keys Set
+	// States is the set of key states Tag can handle. Events in other
+	// states are passed on to other handlers as if rejected by Keys.
+	// The zero value accepts every state.
+	States StateFilter
 }
 
 // Set is an expression that describes a set of key combinations, in the form
@@ -65,6 +69,39 @@ type FocusOp struct {
 	Tag event.Tag
 }
 
+// FocusScopeOp confines focus moves to the key handlers added while it
+// is on the stack, such as the handlers of a menu, dialog or popover.
+// While a scope is the last one with handlers in a frame, moving the
+// focus forward, backward or in a direction only visits its handlers,
+// and the first move enters it. When the scope is no longer added, the
+// focus returns to the handler that had it when the scope appeared,
+// unless the focus was moved outside the scope in the meantime.
+//
+// A scope added while another is on the stack is part of the outer
+// scope. Overlays drawn with op.Defer are never inside other scopes.
+type FocusScopeOp struct {
+	// Tag identifies the scope across frames.
+	Tag event.Tag
+}
+
+// FocusScopeStack represents a FocusScopeOp on the focus scope stack.
+type FocusScopeStack struct {
+	ops     *ops.Ops
+	id      ops.StackID
+	macroID uint32
+}
+
+// ShortcutOp declares keyboard shortcuts of a handler, such as the
+// shortcut of a menu item. Unlike the keys of an InputOp, shortcuts are
+// delivered regardless of the focus: a key event not handled by the
+// focused handler or its ancestors goes to the foremost handler with a
+// matching shortcut.
+type ShortcutOp struct {
+	Tag event.Tag
+	// Keys is the set of shortcuts.
+	Keys Set
+}
+
 // SelectionOp updates the selection for an input handler.
 type SelectionOp struct {
 	Tag event.Tag
@@ -109,6 +146,12 @@ type SelectionEvent Range
 // input method.
 type SnippetEvent Range
 
+// CompositionEvent is generated when an input method changes the
+// composing region, the range of text not yet committed by the input
+// method. The region is usually displayed underlined. A range with
+// Start and End of -1 ends the composition.
+type CompositionEvent Range
+
 // A FocusEvent is generated when a handler gains or loses
 // focus.
 type FocusEvent struct {
@@ -170,6 +213,16 @@ const (
 	Release
 )
 
+// StateFilter is a bit-set of key States.
+type StateFilter uint8
+
+const (
+	// FilterPress accepts Press events.
+	FilterPress StateFilter = 1 << iota
+	// FilterRelease accepts Release events.
+	FilterRelease
+)
+
 // Modifiers
 type Modifiers uint32
 
@@ -232,6 +285,85 @@ func (m Modifiers) Contain(m2 Modifiers) bool {
 	return m&m2 == m2
 }
 
+// Overlaps reports whether a key combination is contained in both k and
+// k2, such as for detecting conflicting shortcuts.
+func (k Set) Overlaps(k2 Set) bool {
+	for ks := string(k); len(ks) > 0; {
+		var chord string
+		chord, ks, _ = cut(ks, "|")
+		mods1, keys1 := splitChord(chord)
+		for ks2 := string(k2); len(ks2) > 0; {
+			var chord2 string
+			chord2, ks2, _ = cut(ks2, "|")
+			mods2, keys2 := splitChord(chord2)
+			if !keySetsOverlap(keys1, keys2) {
+				continue
+			}
+			req1, opt1 := parseModSet(mods1)
+			req2, opt2 := parseModSet(mods2)
+			// The modifiers must agree where neither set is optional.
+			if (req1^req2)&^(opt1|opt2) == 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Label returns the first key combination of k in a form for display,
+// such as "Ctrl+S" for the set "Short-S".
+func (k Set) Label() string {
+	chord, _, _ := cut(string(k), "|")
+	modSet, keySet := splitChord(chord)
+	if len(keySet) >= 2 && keySet[0] == '[' && keySet[len(keySet)-1] == ']' {
+		keySet, _, _ = cut(keySet[1:len(keySet)-1], ",")
+	}
+	req, _ := parseModSet(modSet)
+	if req == 0 {
+		return keySet
+	}
+	return strings.ReplaceAll(req.String(), "-", "+") + "+" + keySet
+}
+
+// splitChord splits a key expression into its modifier set and key set.
+func splitChord(chord string) (modSet, keySet string) {
+	sep := strings.LastIndex(chord, "-")
+	if sep == -1 {
+		return "", chord
+	}
+	return chord[:sep], chord[sep+1:]
+}
+
+func keySetsOverlap(keySet1, keySet2 string) bool {
+	if len(keySet1) < 2 || keySet1[0] != '[' || keySet1[len(keySet1)-1] != ']' {
+		return keySetContains(keySet2, keySet1)
+	}
+	keys := keySet1[1 : len(keySet1)-1]
+	for len(keys) > 0 {
+		var key string
+		key, keys, _ = cut(keys, ",")
+		if keySetContains(keySet2, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseModSet returns the required and optional modifiers of a
+// modifier set.
+func parseModSet(modSet string) (req, opt Modifiers) {
+	for len(modSet) > 0 {
+		mod, rest, _ := cut(modSet, "-")
+		modSet = rest
+		if len(mod) >= 2 && mod[0] == '(' && mod[len(mod)-1] == ')' {
+			opt |= modFor(mod[1 : len(mod)-1])
+		} else {
+			req |= modFor(mod)
+		}
+	}
+	return req, opt
+}
+
 func (k Set) Contains(name string, mods Modifiers) bool {
 	ks := string(k)
 	for len(ks) > 0 {
@@ -290,6 +422,12 @@ func modSetContains(modSet string, mods Modifiers) bool {
 	return mods == smods
 }
 
+// Contains reports whether f accepts events in state s. The
+// zero filter accepts every state.
+func (f StateFilter) Contains(s State) bool {
+	return f == 0 || f&(1<<s) != 0
+}
+
 // cut is a copy of the standard library strings.Cut.
 // TODO: remove when Go 1.18 is our minimum.
 func cut(s, sep string) (before, after string, found bool) {
@@ -326,6 +464,15 @@ func (h InputOp) Add(o *op.Ops) {
 	data := ops.Write2String(&o.Internal, ops.TypeKeyInputLen, h.Tag, string(h.Keys))
 	data[0] = byte(ops.TypeKeyInput)
 	data[1] = byte(h.Hint)
+	data[2] = byte(h.States)
+}
+
+func (s ShortcutOp) Add(o *op.Ops) {
+	if s.Tag == nil {
+		panic("Tag must be non-nil")
+	}
+	data := ops.Write2String(&o.Internal, ops.TypeKeyShortcutLen, s.Tag, string(s.Keys))
+	data[0] = byte(ops.TypeKeyShortcut)
 }
 
 func (h SoftKeyboardOp) Add(o *op.Ops) {
@@ -341,6 +488,23 @@ func (h FocusOp) Add(o *op.Ops) {
 	data[0] = byte(ops.TypeKeyFocus)
 }
 
+// Push the scope on the focus scope stack.
+func (s FocusScopeOp) Push(o *op.Ops) FocusScopeStack {
+	if s.Tag == nil {
+		panic("Tag must be non-nil")
+	}
+	id, mid := ops.PushOp(&o.Internal, ops.FocusScopeStack)
+	data := ops.Write1(&o.Internal, ops.TypeFocusScopeLen, s.Tag)
+	data[0] = byte(ops.TypeFocusScope)
+	return FocusScopeStack{ops: &o.Internal, id: id, macroID: mid}
+}
+
+func (s FocusScopeStack) Pop() {
+	ops.PopOp(s.ops, ops.FocusScopeStack, s.id, s.macroID)
+	data := ops.Write(s.ops, ops.TypePopFocusScopeLen)
+	data[0] = byte(ops.TypePopFocusScope)
+}
+
 func (s SnippetOp) Add(o *op.Ops) {
 	data := ops.Write2String(&o.Internal, ops.TypeSnippetLen, s.Tag, s.Text)
 	data[0] = byte(ops.TypeSnippet)
@@ -367,6 +531,8 @@ func (FocusEvent) ImplementsEvent()     {}
 func (SnippetEvent) ImplementsEvent()   {}
 func (SelectionEvent) ImplementsEvent() {}
 
+func (CompositionEvent) ImplementsEvent() {}
+
 func (e Event) String() string {
 	return fmt.Sprintf("%v %v %v}", e.Name, e.Modifiers, e.State)
 }
@@ -391,6 +557,17 @@ func (m Modifiers) String() string {
 	return strings.Join(strs, "-")
 }
 
+func (f StateFilter) String() string {
+	var strs []string
+	if f&FilterPress != 0 {
+		strs = append(strs, "Press")
+	}
+	if f&FilterRelease != 0 {
+		strs = append(strs, "Release")
+	}
+	return strings.Join(strs, "|")
+}
+
 func (s State) String() string {
 	switch s {
 	case Press:
@@ -33,3 +33,42 @@ func TestKeySet(t *testing.T) {
 		}
 	}
 }
+
+func TestKeySetOverlaps(t *testing.T) {
+	tests := []struct {
+		Set1, Set2 Set
+		Overlaps   bool
+	}{
+		{"Short-S", "Short-S", true},
+		{"Short-S", "Short-Shift-S", false},
+		{"Short-(Shift)-S", "Short-Shift-S", true},
+		{"Short-[A,S]", "B|Short-S", true},
+		{"Short-[A,S]", "Short-[B,C]", false},
+		{"Alt-A", "(Alt)-(Shift)-A", true},
+	}
+	for _, tst := range tests {
+		if got := tst.Set1.Overlaps(tst.Set2); got != tst.Overlaps {
+			t.Errorf("%q overlaps %q: %v, want %v", tst.Set1, tst.Set2, got, tst.Overlaps)
+		}
+		if got := tst.Set2.Overlaps(tst.Set1); got != tst.Overlaps {
+			t.Errorf("%q overlaps %q: %v, want %v", tst.Set2, tst.Set1, got, tst.Overlaps)
+		}
+	}
+}
+
+func TestKeySetLabel(t *testing.T) {
+	tests := []struct {
+		Set   Set
+		Label string
+	}{
+		{"A", "A"},
+		{"Shift-[A,B]|C", "Shift+A"},
+		{"Ctrl-(Shift)-" + NameDeleteBackward, "Ctrl+" + NameDeleteBackward},
+		{"Short-S", ModShortcut.String() + "+S"},
+	}
+	for _, tst := range tests {
+		if got := tst.Set.Label(); got != tst.Label {
+			t.Errorf("label of %q is %q, want %q", tst.Set, got, tst.Label)
+		}
+	}
+}
@@ -101,14 +101,30 @@ and hit areas. Rather, handlers stay in the matching set until they
 no longer appear in a InputOp or when another handler in the set
 grabs the pointer.
 
-A handler can exclude all other handler from its matching sets
-by setting the Grab flag in its InputOp. The Grab flag is sticky
-and stays in effect until the handler no longer appears in any
-matching sets.
+The handlers in the matching sets of pressed pointers negotiate
+ownership of the pointers. A handler claims the pointers by setting
+the Grab flag in its InputOp, and excludes all other handlers from its
+matching sets. The Grab flag is sticky and stays in effect until the
+handler no longer appears in any matching sets.
 
-The losing handlers are notified by a Cancel event.
+A handler gives up the pointers by setting the Deny flag in its
+InputOp, and leaves the matching sets of pressed pointers. A handler
+that is alone in a matching set, because the others denied, owns the
+pointer as if it grabbed it.
 
-For multiple grabbing handlers, the foremost handler wins.
+Claims and denials are resolved when the next operation list is
+framed: first the denying handlers leave, and then the grabbing
+handler with the highest Precedence wins. Among grabbing handlers of
+equal Precedence, the foremost handler wins.
+
+The losing and denying handlers are notified by a Cancel event.
+
+# Ordering
+
+Each handler receives its events in the order they occurred. A Cancel
+ends the gesture in progress: a handler that leaves the matching set
+of a pressed pointer receives no further events from the pointer until
+it is released.
 
 # Priorities
 
@@ -131,5 +147,11 @@ determines that the gesture is a drag and sets its Grab flag. The
 click handler receives a Cancel (removing the highlight) and further
 movements for the scroll handler has priority Grabbed, scrolling the
 list.
+
+Conversely, a handler that recognizes that a gesture is not meant for
+it sets its Deny flag, for example a horizontal swipe handler when the
+finger moves vertically. Nested handlers that may claim the same
+gesture, such as a drag handler inside a scrollable list, order their
+claims with Precedence instead of relying on their stacking order.
 */
 package pointer
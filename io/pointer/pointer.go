@@ -6,6 +6,7 @@ import (
 	"encoding/binary"
 	"fmt"
 	"image"
+	"image/draw"
 	"strings"
 	"time"
 
@@ -37,11 +38,59 @@ type Event struct {
 	// coordinates to local coordinates is performed by the inverse of
 	// the effective transformation of the tag.
 	Position f32.Point
-	// Scroll is the scroll amount, if any.
+	// Scroll is the scroll amount, if any, in pixels. Scrolling by
+	// lines or pages is converted to pixels by the platform; see
+	// ScrollMode.
 	Scroll f32.Point
+	// ScrollMode is the unit the device scrolled in. Mouse wheels
+	// typically scroll in lines, while trackpads scroll precisely in
+	// pixels.
+	ScrollMode ScrollMode
+	// Phase is the phase of a Scroll event from a device with
+	// precise scrolling, such as a trackpad. It is PhaseNone for
+	// devices that don't report phases, such as mouse wheels.
+	// Phases are only reported on macOS; other platforms, including
+	// Windows with Precision Touchpads, deliver trackpad scrolling as
+	// wheel events without phases.
+	Phase Phase
 	// Modifiers is the set of active modifiers when
 	// the mouse button was pressed.
 	Modifiers key.Modifiers
+	// Pressure is the normalized pressure of a Stylus or Eraser
+	// in the range [0, 1], or 0 if the source doesn't report
+	// pressure.
+	Pressure float32
+	// TiltX and TiltY are the angles in degrees between the axis
+	// of a Stylus or Eraser and the normal of the screen, in the
+	// range [-90, 90]. TiltX is positive towards the right of the
+	// screen, TiltY towards its bottom.
+	TiltX, TiltY float32
+	// Zoom is the relative change in scale of a Pinch event, such
+	// that content should be scaled by a factor of 1 + Zoom.
+	Zoom float32
+	// Rotation is the change in angle of a Rotate event, in radians.
+	// Positive values rotate clockwise.
+	Rotation float32
+	// History is the samples of a Move or Drag event the platform
+	// coalesced into it, oldest first, not including the sample of
+	// the event itself. Digitizers sample pointers faster than the
+	// display refreshes, and drawing apps use History to draw smooth
+	// strokes at the full rate. History is only reported on platforms
+	// that batch pointer samples, such as Android.
+	History []Sample
+}
+
+// Sample is a sample of the position and pressure of a pointer.
+type Sample struct {
+	// Time is when the sample was taken, relative to the same base as
+	// Event.Time.
+	Time time.Duration
+	// Position is the position of the pointer, in the same coordinate
+	// system as Event.Position.
+	Position f32.Point
+	// Pressure, TiltX and TiltY are as for Event.
+	Pressure     float32
+	TiltX, TiltY float32
 }
 
 // PassOp sets the pass-through mode. InputOps added while the pass-through
@@ -63,8 +112,23 @@ type InputOp struct {
 	// Grab, if set, request that the handler get
 	// Grabbed priority.
 	Grab bool
+	// Deny, if set, withdraws the handler from the matching
+	// sets of pressed pointers, leaving them to the other
+	// handlers.
+	Deny bool
+	// Precedence orders the handlers that set Grab for the
+	// same pointer. The handler with the highest Precedence
+	// wins, and the foremost handler among equals.
+	Precedence int32
 	// Kinds is a bitwise-or of event types to receive.
 	Kinds Kind
+	// Buttons, if non-zero, is the set of mouse buttons whose presses
+	// and drags to receive. Press and Drag events of mouse pointers
+	// without any of the Buttons pressed are filtered out by the
+	// router, so that for example a handler of secondary clicks isn't
+	// woken by every primary drag. Events of other sources are not
+	// filtered.
+	Buttons Buttons
 	// ScrollBounds describe the maximum scrollable distances in both
 	// axes. Specifically, any Event e delivered to Tag will satisfy
 	//
@@ -87,10 +151,27 @@ type Source uint8
 // Buttons is a set of mouse buttons
 type Buttons uint8
 
+// Phase of a scroll gesture.
+type Phase uint8
+
+// ScrollMode is the unit of a Scroll event.
+type ScrollMode uint8
+
 // Cursor denotes a pre-defined cursor shape. Its Add method adds an
 // operation that sets the cursor shape for the current clip area.
 type Cursor byte
 
+// ImageCursor is a cursor shape from an image, such as for drawing
+// and game tools. Its Add method adds an operation that sets the cursor
+// for the current clip area, like Cursor.Add.
+//
+// Platforms cache the native cursor of an ImageCursor, so create it
+// once with CursorFromImage and reuse it between frames.
+type ImageCursor struct {
+	img     *image.NRGBA
+	hotspot image.Point
+}
+
 // The cursors correspond to CSS pointer naming.
 const (
 	// CursorDefault is the default cursor.
@@ -182,10 +263,22 @@ const (
 	Drag
 	// Pointer enters an area watching for pointer input
 	Enter
-	// Pointer leaves an area watching for pointer input
+	// Pointer leaves an area watching for pointer input. Drivers
+	// report a Leave for a pen that moves out of range of its
+	// tablet, which ends its hover.
 	Leave
 	// Scroll of a pointer.
 	Scroll
+	// Pinch of a trackpad or touch screen gesture, described by
+	// the Zoom field.
+	Pinch
+	// Rotate of a trackpad or touch screen gesture, described by
+	// the Rotation field.
+	Rotate
+	// SmartZoom is a request to toggle the zoom of the content
+	// under the pointer, such as from a double tap with two
+	// fingers on a trackpad.
+	SmartZoom
 )
 
 const (
@@ -193,6 +286,42 @@ const (
 	Mouse Source = iota
 	// Touch generated event.
 	Touch
+	// Stylus generated event, such as by the tip of a pen. The
+	// barrel buttons of a pen, if any, are reported as
+	// ButtonSecondary and ButtonTertiary.
+	Stylus
+	// Eraser generated event, such as by the inverted end of a
+	// pen.
+	Eraser
+)
+
+const (
+	// PhaseNone is for scroll events without phase information.
+	PhaseNone Phase = iota
+	// PhaseBegan is the start of a scroll by the user.
+	PhaseBegan
+	// PhaseChanged is a scroll by the user.
+	PhaseChanged
+	// PhaseEnded is the end of a scroll by the user, such as when
+	// lifting the fingers from a trackpad. It may be followed by
+	// momentum scrolling.
+	PhaseEnded
+	// PhaseMomentum is a scroll from the momentum of a previous
+	// scroll by the user.
+	PhaseMomentum
+	// PhaseMomentumEnded is the end of momentum scrolling.
+	PhaseMomentumEnded
+)
+
+const (
+	// ScrollPixels is for precise scrolling, such as by trackpads and
+	// touch screens.
+	ScrollPixels ScrollMode = iota
+	// ScrollLines is for scrolling in steps of lines, such as by the
+	// ticks of mouse wheels.
+	ScrollLines
+	// ScrollPages is for scrolling in steps of pages.
+	ScrollPages
 )
 
 const (
@@ -237,6 +366,33 @@ func (op Cursor) Add(o *op.Ops) {
 	data[1] = byte(op)
 }
 
+// CursorFromImage returns a cursor that shows img, with the hotspot
+// point of img at the pointer position. The image is copied and its
+// origin moved to (0, 0). Platforms may limit the size of cursors, and
+// fall back to CursorDefault for cursors too large.
+func CursorFromImage(img image.Image, hotspot image.Point) *ImageCursor {
+	b := img.Bounds()
+	dst := image.NewNRGBA(image.Rectangle{Max: b.Size()})
+	draw.Draw(dst, dst.Bounds(), img, b.Min, draw.Src)
+	return &ImageCursor{img: dst, hotspot: hotspot.Sub(b.Min)}
+}
+
+// Image returns the image of the cursor, with its origin at (0, 0).
+// It must not be modified.
+func (c *ImageCursor) Image() *image.NRGBA {
+	return c.img
+}
+
+// Hotspot returns the point of the image at the pointer position.
+func (c *ImageCursor) Hotspot() image.Point {
+	return c.hotspot
+}
+
+func (c *ImageCursor) Add(o *op.Ops) {
+	data := ops.Write1(&o.Internal, ops.TypeImageCursorLen, c)
+	data[0] = byte(ops.TypeImageCursor)
+}
+
 // Add panics if the scroll range does not contain zero.
 func (op InputOp) Add(o *op.Ops) {
 	if op.Tag == nil {
@@ -251,7 +407,10 @@ func (op InputOp) Add(o *op.Ops) {
 	data := ops.Write1(&o.Internal, ops.TypePointerInputLen, op.Tag)
 	data[0] = byte(ops.TypePointerInput)
 	if op.Grab {
-		data[1] = 1
+		data[1] |= 1
+	}
+	if op.Deny {
+		data[1] |= 2
 	}
 	bo := binary.LittleEndian
 	bo.PutUint16(data[2:], uint16(op.Kinds))
@@ -259,6 +418,8 @@ func (op InputOp) Add(o *op.Ops) {
 	bo.PutUint32(data[8:], uint32(op.ScrollBounds.Min.Y))
 	bo.PutUint32(data[12:], uint32(op.ScrollBounds.Max.X))
 	bo.PutUint32(data[16:], uint32(op.ScrollBounds.Max.Y))
+	bo.PutUint32(data[20:], uint32(op.Precedence))
+	data[24] = byte(op.Buttons)
 }
 
 func (t Kind) String() string {
@@ -295,6 +456,12 @@ func (t Kind) string() string {
 		return "Leave"
 	case Scroll:
 		return "Scroll"
+	case Pinch:
+		return "Pinch"
+	case Rotate:
+		return "Rotate"
+	case SmartZoom:
+		return "SmartZoom"
 	default:
 		panic("unknown Type")
 	}
@@ -319,11 +486,47 @@ func (s Source) String() string {
 		return "Mouse"
 	case Touch:
 		return "Touch"
+	case Stylus:
+		return "Stylus"
+	case Eraser:
+		return "Eraser"
 	default:
 		panic("unknown source")
 	}
 }
 
+func (p Phase) String() string {
+	switch p {
+	case PhaseNone:
+		return "None"
+	case PhaseBegan:
+		return "Began"
+	case PhaseChanged:
+		return "Changed"
+	case PhaseEnded:
+		return "Ended"
+	case PhaseMomentum:
+		return "Momentum"
+	case PhaseMomentumEnded:
+		return "MomentumEnded"
+	default:
+		panic("unknown phase")
+	}
+}
+
+func (m ScrollMode) String() string {
+	switch m {
+	case ScrollPixels:
+		return "Pixels"
+	case ScrollLines:
+		return "Lines"
+	case ScrollPages:
+		return "Pages"
+	default:
+		panic("unknown scroll mode")
+	}
+}
+
 // Contain reports whether the set b contains
 // all of the buttons.
 func (b Buttons) Contain(buttons Buttons) bool {
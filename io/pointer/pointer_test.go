@@ -3,6 +3,8 @@
 package pointer
 
 import (
+	"image"
+	"image/color"
 	"testing"
 )
 
@@ -31,3 +33,18 @@ func TestTypeString(t *testing.T) {
 		})
 	}
 }
+
+func TestCursorFromImage(t *testing.T) {
+	img := image.NewRGBA(image.Rect(10, 20, 14, 24))
+	img.Set(11, 21, color.RGBA{R: 0xff, A: 0xff})
+	c := CursorFromImage(img, image.Pt(11, 21))
+	if got, want := c.Image().Bounds(), image.Rect(0, 0, 4, 4); got != want {
+		t.Errorf("got bounds %v, want %v", got, want)
+	}
+	if got, want := c.Hotspot(), image.Pt(1, 1); got != want {
+		t.Errorf("got hotspot %v, want %v", got, want)
+	}
+	if got, want := c.Image().NRGBAAt(1, 1), (color.NRGBA{R: 0xff, A: 0xff}); got != want {
+		t.Errorf("got pixel %v, want %v", got, want)
+	}
+}
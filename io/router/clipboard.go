@@ -3,14 +3,19 @@
 package router
 
 import (
+	"github.com/Seikaijyu/gio/io/clipboard"
 	"github.com/Seikaijyu/gio/io/event"
 )
 
 type clipboardQueue struct {
 	receivers map[event.Tag]struct{}
+	// types are the formats requested by the receivers.
+	types []string
 	// request avoid read clipboard every frame while waiting.
 	requested bool
 	text      *string
+	// content is text in other formats.
+	content []clipboard.Content
 }
 
 // WriteClipboard returns the most recent text to be copied
@@ -24,6 +29,18 @@ func (q *clipboardQueue) WriteClipboard() (string, bool) {
 	return text, true
 }
 
+// Content returns the other formats of the text most recently
+// returned by WriteClipboard.
+func (q *clipboardQueue) Content() []clipboard.Content {
+	return q.content
+}
+
+// Types returns the formats requested by the receivers, in addition
+// to text.
+func (q *clipboardQueue) Types() []string {
+	return q.types
+}
+
 // ReadClipboard reports if any new handler is waiting
 // to read the clipboard.
 func (q *clipboardQueue) ReadClipboard() bool {
@@ -39,10 +56,12 @@ func (q *clipboardQueue) Push(e event.Event, events *handlerEvents) {
 		events.Add(r, e)
 		delete(q.receivers, r)
 	}
+	q.types = q.types[:0]
 }
 
 func (q *clipboardQueue) ProcessWriteClipboard(refs []interface{}) {
-	q.text = refs[0].(*string)
+	q.content = refs[0].([]clipboard.Content)
+	q.text = refs[1].(*string)
 }
 
 func (q *clipboardQueue) ProcessReadClipboard(refs []interface{}) {
@@ -54,4 +73,19 @@ func (q *clipboardQueue) ProcessReadClipboard(refs []interface{}) {
 		q.receivers[tag] = struct{}{}
 		q.requested = false
 	}
+	for _, t := range refs[1].([]string) {
+		if !q.hasType(t) {
+			q.types = append(q.types, t)
+			q.requested = false
+		}
+	}
+}
+
+func (q *clipboardQueue) hasType(typ string) bool {
+	for _, t := range q.types {
+		if t == typ {
+			return true
+		}
+	}
+	return false
 }
@@ -119,6 +119,30 @@ func assertClipboardEvent(t *testing.T, events []event.Event, expected bool) {
 	}
 }
 
+func TestPrimarySelection(t *testing.T) {
+	ops, router, handler := new(op.Ops), new(Router), make([]int, 2)
+
+	clipboard.ReadOp{Tag: &handler[0]}.Add(ops)
+	clipboard.ReadOp{Tag: &handler[1], Primary: true}.Add(ops)
+	clipboard.WriteOp{Text: "Primary", Primary: true}.Add(ops)
+	router.Frame(ops)
+
+	if !router.ReadPrimary() || !router.ReadClipboard() {
+		t.Error("missing requests")
+	}
+	if _, ok := router.WriteClipboard(); ok {
+		t.Error("primary selection written to the clipboard")
+	}
+	if text, ok := router.WritePrimary(); !ok || text != "Primary" {
+		t.Errorf("got primary selection %q, expected %q", text, "Primary")
+	}
+
+	// The primary selection is delivered to its readers only.
+	router.Queue(clipboard.Event{Text: "Test", Primary: true})
+	assertClipboardEvent(t, router.Events(&handler[0]), false)
+	assertClipboardEvent(t, router.Events(&handler[1]), true)
+}
+
 func assertClipboardReadOp(t *testing.T, router *Router, expected int) {
 	t.Helper()
 	if len(router.cqueue.receivers) != expected {
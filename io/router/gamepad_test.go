@@ -0,0 +1,47 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package router
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Seikaijyu/gio/io/event"
+	"github.com/Seikaijyu/gio/io/gamepad"
+	"github.com/Seikaijyu/gio/op"
+)
+
+func TestGamepadEvents(t *testing.T) {
+	ops, r, handler := new(op.Ops), new(Router), make([]int, 2)
+
+	r.Frame(ops)
+	if r.GamepadInput() {
+		t.Error("gamepad input without handlers")
+	}
+	if r.Queue(gamepad.Event{Kind: gamepad.Connect}) {
+		t.Error("gamepad event queued without handlers")
+	}
+
+	// Every handler receives every event.
+	gamepad.InputOp{Tag: &handler[0]}.Add(ops)
+	gamepad.InputOp{Tag: &handler[1]}.Add(ops)
+	r.Frame(ops)
+	if !r.GamepadInput() {
+		t.Error("no gamepad input with handlers")
+	}
+	press := gamepad.Event{Kind: gamepad.Press, ID: 1, Button: gamepad.ButtonA}
+	if !r.Queue(press) {
+		t.Error("gamepad event not queued")
+	}
+	for i := range handler {
+		if got, want := r.Events(&handler[i]), []event.Event{press}; !reflect.DeepEqual(got, want) {
+			t.Errorf("handler %d: got events %v, want %v", i, got, want)
+		}
+	}
+
+	ops.Reset()
+	r.Frame(ops)
+	if r.GamepadInput() {
+		t.Error("gamepad input after handlers were removed")
+	}
+}
@@ -4,9 +4,11 @@ package router
 
 import (
 	"image"
+	"log"
 	"sort"
 
 	"github.com/Seikaijyu/gio/f32"
+	"github.com/Seikaijyu/gio/internal/debug"
 	"github.com/Seikaijyu/gio/io/event"
 	"github.com/Seikaijyu/gio/io/key"
 )
@@ -31,6 +33,34 @@ type keyQueue struct {
 	state    TextInputState
 	hint     key.InputHint
 	content  EditorState
+	// scopes are the focus scopes by tag.
+	scopes map[event.Tag]*focusScope
+	// scope is the active focus scope, or nil.
+	scope event.Tag
+	// scopeOrder is dirOrder restricted to the handlers of scope.
+	scopeOrder []dirFocusEntry
+	scopeSeq   int
+	// shortcuts are the key.ShortcutOps of the frame, in order.
+	shortcuts []shortcut
+	// conflicts are the pairs of conflicting shortcuts reported.
+	conflicts map[[2]key.Set]bool
+}
+
+// shortcut is the state of a key.ShortcutOp.
+type shortcut struct {
+	tag  event.Tag
+	keys key.Set
+}
+
+// focusScope is the state of a key.FocusScopeOp.
+type focusScope struct {
+	tag     event.Tag
+	visible bool
+	new     bool
+	// seq orders scopes by appearance.
+	seq int
+	// restore is the focus at the appearance of the scope.
+	restore event.Tag
 }
 
 type keyHandler struct {
@@ -42,6 +72,9 @@ type keyHandler struct {
 	order    int
 	dirOrder int
 	filter   key.Set
+	states   key.StateFilter
+	// scope is the outermost focus scope of the handler, if any.
+	scope event.Tag
 }
 
 // keyCollector tracks state required to update a keyQueue
@@ -50,6 +83,11 @@ type keyCollector struct {
 	q       *keyQueue
 	focus   event.Tag
 	changed bool
+	// scopes is the focus scope stack.
+	scopes []event.Tag
+	// scope is the outermost focus scope of the last handler
+	// in a scope.
+	scope event.Tag
 }
 
 type dirFocusEntry struct {
@@ -102,12 +140,19 @@ func (q *keyQueue) Reset() {
 	if q.handlers == nil {
 		q.handlers = make(map[event.Tag]*keyHandler)
 	}
+	if q.scopes == nil {
+		q.scopes = make(map[event.Tag]*focusScope)
+	}
 	for _, h := range q.handlers {
 		h.visible, h.new = false, false
 		h.order = -1
 	}
+	for _, s := range q.scopes {
+		s.visible = false
+	}
 	q.order = q.order[:0]
 	q.dirOrder = q.dirOrder[:0]
+	q.shortcuts = q.shortcuts[:0]
 }
 
 func (q *keyQueue) Frame(events *handlerEvents, collector keyCollector) {
@@ -125,10 +170,72 @@ func (q *keyQueue) Frame(events *handlerEvents, collector keyCollector) {
 			events.AddNoRedraw(k, key.FocusEvent{Focus: false})
 		}
 	}
+	q.updateScopes(events, collector.scope)
 	if changed {
 		q.setFocus(focus, events)
 	}
 	q.updateFocusLayout()
+	if debug.Shortcuts.Load() {
+		q.reportConflicts()
+	}
+}
+
+// Shortcut returns the foremost handler with a shortcut matching e.
+func (q *keyQueue) Shortcut(e key.Event) (event.Tag, bool) {
+	for i := len(q.shortcuts) - 1; i >= 0; i-- {
+		if s := q.shortcuts[i]; s.keys.Contains(e.Name, e.Modifiers) {
+			return s.tag, true
+		}
+	}
+	return nil, false
+}
+
+// reportConflicts logs the shortcuts of different handlers that match
+// the same keys, once for every pair of shortcuts.
+func (q *keyQueue) reportConflicts() {
+	for i, s1 := range q.shortcuts {
+		for _, s2 := range q.shortcuts[i+1:] {
+			if s1.tag == s2.tag || !s1.keys.Overlaps(s2.keys) {
+				continue
+			}
+			pair := [2]key.Set{s1.keys, s2.keys}
+			if q.conflicts[pair] {
+				continue
+			}
+			if q.conflicts == nil {
+				q.conflicts = make(map[[2]key.Set]bool)
+			}
+			q.conflicts[pair] = true
+			log.Printf("gio: shortcut %q of %T conflicts with shortcut %q of %T", s1.keys, s1.tag, s2.keys, s2.tag)
+		}
+	}
+}
+
+// updateScopes makes active the active focus scope, records the focus
+// of appearing scopes and restores the focus of disappearing scopes.
+func (q *keyQueue) updateScopes(events *handlerEvents, active event.Tag) {
+	q.scope = active
+	var gone []*focusScope
+	for k, s := range q.scopes {
+		switch {
+		case !s.visible:
+			delete(q.scopes, k)
+			gone = append(gone, s)
+		case s.new:
+			s.new = false
+			s.restore = q.focus
+		}
+	}
+	// Restore the latest scopes first, so that the focus returns
+	// through overlays dismissed together.
+	sort.Slice(gone, func(i, j int) bool {
+		return gone[i].seq > gone[j].seq
+	})
+	for _, s := range gone {
+		if q.focus == nil || q.handlers[q.focus].scope == s.tag {
+			q.setFocus(s.restore, events)
+		}
+	}
 }
 
 // updateFocusLayout partitions input handlers handlers into rows
@@ -139,7 +246,24 @@ func (q *keyQueue) Frame(events *handlerEvents, collector keyCollector) {
 // and add to the row every handler whose center intersect it. Repeat
 // until no handlers remain.
 func (q *keyQueue) updateFocusLayout() {
-	order := q.dirOrder
+	layoutRows(q.dirOrder)
+	for i, o := range q.dirOrder {
+		q.handlers[o.tag].dirOrder = i
+	}
+	q.scopeOrder = q.scopeOrder[:0]
+	if q.scope == nil {
+		return
+	}
+	for _, o := range q.dirOrder {
+		if q.handlers[o.tag].scope == q.scope {
+			q.scopeOrder = append(q.scopeOrder, o)
+		}
+	}
+	layoutRows(q.scopeOrder)
+}
+
+// layoutRows sorts order and assigns the rows of its entries.
+func layoutRows(order []dirFocusEntry) {
 	// Sort by ascending y position.
 	sort.SliceStable(order, func(i, j int) bool {
 		return order[i].bounds.Min.Y < order[j].bounds.Min.Y
@@ -165,51 +289,64 @@ func (q *keyQueue) updateFocusLayout() {
 		order = order[end:]
 		row++
 	}
-	for i, o := range q.dirOrder {
-		q.handlers[o.tag].dirOrder = i
-	}
+}
+
+// inScope reports whether the handler of t is in the active focus
+// scope, if any.
+func (q *keyQueue) inScope(t event.Tag) bool {
+	return q.scope == nil || q.handlers[t].scope == q.scope
 }
 
 // MoveFocus attempts to move the focus in the direction of dir, returning true if it succeeds.
+// The focus only moves among the handlers of the active focus scope, if any.
 func (q *keyQueue) MoveFocus(dir FocusDirection, events *handlerEvents) bool {
-	if len(q.dirOrder) == 0 {
+	dirOrder := q.dirOrder
+	if q.scope != nil {
+		dirOrder = q.scopeOrder
+	}
+	if len(dirOrder) == 0 {
 		return false
 	}
-	order := 0
-	if q.focus != nil {
-		order = q.handlers[q.focus].dirOrder
+	order, focused := 0, false
+	if q.focus != nil && q.inScope(q.focus) {
+		for i, e := range dirOrder {
+			if e.tag == q.focus {
+				order, focused = i, true
+				break
+			}
+		}
 	}
-	focus := q.dirOrder[order]
+	focus := dirOrder[order]
 	switch dir {
 	case FocusForward, FocusBackward:
-		if len(q.order) == 0 {
+		n := len(q.order)
+		if n == 0 {
 			break
 		}
-		order := 0
+		step, order := 1, -1
 		if dir == FocusBackward {
-			order = -1
+			step, order = -1, n
 		}
-		if q.focus != nil {
+		if focused {
 			order = q.handlers[q.focus].order
-			if dir == FocusForward {
-				order++
-			} else {
-				order--
+		}
+		for i := 0; i < n; i++ {
+			order = (order + step + n) % n
+			if t := q.order[order]; q.inScope(t) {
+				q.setFocus(t, events)
+				return true
 			}
 		}
-		order = (order + len(q.order)) % len(q.order)
-		q.setFocus(q.order[order], events)
-		return true
 	case FocusRight, FocusLeft:
 		next := order
-		if q.focus != nil {
+		if focused {
 			next = order + 1
 			if dir == FocusLeft {
 				next = order - 1
 			}
 		}
-		if 0 <= next && next < len(q.dirOrder) {
-			newFocus := q.dirOrder[next]
+		if 0 <= next && next < len(dirOrder) {
+			newFocus := dirOrder[next]
 			if newFocus.row == focus.row {
 				q.setFocus(newFocus.tag, events)
 				return true
@@ -221,15 +358,15 @@ func (q *keyQueue) MoveFocus(dir FocusDirection, events *handlerEvents) bool {
 			delta = -1
 		}
 		nextRow := 0
-		if q.focus != nil {
+		if focused {
 			nextRow = focus.row + delta
 		}
 		var closest event.Tag
 		dist := int(1e6)
 		center := (focus.bounds.Min.X + focus.bounds.Max.X) / 2
 	loop:
-		for 0 <= order && order < len(q.dirOrder) {
-			next := q.dirOrder[order]
+		for 0 <= order && order < len(dirOrder) {
+			next := dirOrder[order]
 			switch next.row {
 			case nextRow:
 				nextCenter := (next.bounds.Min.X + next.bounds.Max.X) / 2
@@ -266,7 +403,8 @@ func (q *keyQueue) AreaFor(t event.Tag) int {
 }
 
 func (q *keyQueue) Accepts(t event.Tag, e key.Event) bool {
-	return q.handlers[t].filter.Contains(e.Name, e.Modifiers)
+	h := q.handlers[t]
+	return h.states.Contains(e.State) && h.filter.Contains(e.Name, e.Modifiers)
 }
 
 func (q *keyQueue) setFocus(focus event.Tag, events *handlerEvents) {
@@ -318,11 +456,40 @@ func (k *keyCollector) handlerFor(tag event.Tag, area int, bounds image.Rectangl
 	return h
 }
 
+func (k *keyCollector) pushFocusScope(tag event.Tag) {
+	if len(k.scopes) == 0 {
+		s, ok := k.q.scopes[tag]
+		if !ok {
+			k.q.scopeSeq++
+			s = &focusScope{tag: tag, new: true, seq: k.q.scopeSeq}
+			k.q.scopes[tag] = s
+		}
+		s.visible = true
+	}
+	k.scopes = append(k.scopes, tag)
+}
+
+func (k *keyCollector) popFocusScope() {
+	if n := len(k.scopes); n > 0 {
+		k.scopes = k.scopes[:n-1]
+	}
+}
+
 func (k *keyCollector) inputOp(op key.InputOp, area int, bounds image.Rectangle) {
 	h := k.handlerFor(op.Tag, area, bounds)
 	h.visible = true
+	h.scope = nil
+	if len(k.scopes) > 0 {
+		h.scope = k.scopes[0]
+		k.scope = h.scope
+	}
 	h.hint = op.Hint
 	h.filter = op.Keys
+	h.states = op.States
+}
+
+func (k *keyCollector) shortcutOp(op key.ShortcutOp) {
+	k.q.shortcuts = append(k.q.shortcuts, shortcut{tag: op.Tag, keys: op.Keys})
 }
 
 func (k *keyCollector) selectionOp(t f32.Affine2D, op key.SelectionOp) {
@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/Seikaijyu/gio/f32"
+	"github.com/Seikaijyu/gio/internal/debug"
 	"github.com/Seikaijyu/gio/io/event"
 	"github.com/Seikaijyu/gio/io/key"
 	"github.com/Seikaijyu/gio/io/pointer"
@@ -92,6 +93,26 @@ func TestKeySoftKeyboardNoFocus(t *testing.T) {
 	assertKeyboard(t, r, TextInputOpen)
 }
 
+func TestKeyStateFilter(t *testing.T) {
+	handlers := make([]int, 2)
+	ops := new(op.Ops)
+	r := new(Router)
+
+	key.InputOp{Tag: &handlers[0], Keys: "A"}.Add(ops)
+	key.InputOp{Tag: &handlers[1], Keys: "A", States: key.FilterRelease}.Add(ops)
+	key.FocusOp{Tag: &handlers[1]}.Add(ops)
+	r.Frame(ops)
+
+	press := event.Event(key.Event{Name: "A", State: key.Press})
+	release := event.Event(key.Event{Name: "A", State: key.Release})
+	r.Queue(press, release)
+
+	// The focused handler only accepts releases, so the
+	// press is passed to its ancestor.
+	assertKeyEvent(t, r.Events(&handlers[0]), false, press)
+	assertKeyEvent(t, r.Events(&handlers[1]), true, release)
+}
+
 func TestKeyRemoveFocus(t *testing.T) {
 	handlers := make([]int, 2)
 	ops := new(op.Ops)
@@ -264,6 +285,78 @@ func TestDirectionalFocus(t *testing.T) {
 	assertFocus(t, r, &handlers[0])
 }
 
+func TestFocusScope(t *testing.T) {
+	ops := new(op.Ops)
+	r := new(Router)
+	handlers := make([]int, 2)
+	menu, submenu, tooltip := new(int), new(int), new(int)
+	items := make([]int, 3)
+	frame := func(open int) {
+		ops.Reset()
+		for i := range handlers {
+			key.InputOp{Tag: &handlers[i]}.Add(ops)
+		}
+		if open >= 1 {
+			s := key.FocusScopeOp{Tag: menu}.Push(ops)
+			key.InputOp{Tag: &items[0]}.Add(ops)
+			key.InputOp{Tag: &items[1]}.Add(ops)
+			s.Pop()
+		}
+		if open >= 2 {
+			s := key.FocusScopeOp{Tag: submenu}.Push(ops)
+			key.InputOp{Tag: &items[2]}.Add(ops)
+			s.Pop()
+		}
+		// Scopes without handlers, such as of tooltips, don't
+		// confine the focus.
+		key.FocusScopeOp{Tag: tooltip}.Push(ops).Pop()
+		r.Frame(ops)
+	}
+	frame(0)
+	r.MoveFocus(FocusForward)
+	r.MoveFocus(FocusForward)
+	assertFocus(t, r, &handlers[1])
+
+	// Focus moves are confined to the menu.
+	frame(1)
+	assertFocus(t, r, &handlers[1])
+	r.MoveFocus(FocusForward)
+	assertFocus(t, r, &items[0])
+	r.MoveFocus(FocusForward)
+	assertFocus(t, r, &items[1])
+	r.MoveFocus(FocusForward)
+	assertFocus(t, r, &items[0])
+	r.MoveFocus(FocusBackward)
+	assertFocus(t, r, &items[1])
+	r.MoveFocus(FocusDown)
+	assertFocus(t, r, &items[1])
+
+	// Dismissing the menu restores the focus.
+	frame(0)
+	assertFocus(t, r, &handlers[1])
+
+	// Dismissing nested menus restores the focus from before the
+	// first.
+	frame(1)
+	r.MoveFocus(FocusForward)
+	frame(2)
+	r.MoveFocus(FocusForward)
+	assertFocus(t, r, &items[2])
+	frame(1)
+	assertFocus(t, r, &items[0])
+	frame(2)
+	r.MoveFocus(FocusForward)
+	frame(0)
+	assertFocus(t, r, &handlers[1])
+
+	// Moving the focus out of a menu is kept.
+	frame(1)
+	key.FocusOp{Tag: &handlers[0]}.Add(ops)
+	r.Frame(ops)
+	frame(0)
+	assertFocus(t, r, &handlers[0])
+}
+
 func TestFocusScroll(t *testing.T) {
 	ops := new(op.Ops)
 	r := new(Router)
@@ -366,6 +459,48 @@ func TestKeyRouting(t *testing.T) {
 	assertKeyEvent(t, r2.Events(&handlers[0]), false, A)
 }
 
+func TestKeyShortcut(t *testing.T) {
+	handlers := make([]int, 4)
+	ops := new(op.Ops)
+	r := new(Router)
+
+	rect := clip.Rect{Max: image.Pt(10, 10)}
+	cl1 := rect.Push(ops)
+	key.InputOp{Tag: &handlers[0], Keys: "Short-C"}.Add(ops)
+	key.FocusOp{Tag: &handlers[0]}.Add(ops)
+	cl1.Pop()
+	cl2 := rect.Push(ops)
+	key.ShortcutOp{Tag: &handlers[1], Keys: "Short-[C,S]"}.Add(ops)
+	cl2.Pop()
+	key.ShortcutOp{Tag: &handlers[2], Keys: "Short-S"}.Add(ops)
+	key.ShortcutOp{Tag: &handlers[3], Keys: "Short-O"}.Add(ops)
+	r.Frame(ops)
+	for i := range handlers {
+		r.Events(&handlers[i])
+	}
+
+	copyKey := key.Event{Name: "C", Modifiers: key.ModShortcut}
+	save := key.Event{Name: "S", Modifiers: key.ModShortcut}
+	open := key.Event{Name: "O", Modifiers: key.ModShortcut}
+	r.Queue(copyKey, save, open)
+	// The focused handler takes precedence, and shortcuts outside the
+	// branch of the focus are delivered to the foremost match.
+	want := [][]event.Event{{copyKey}, nil, {save}, {open}}
+	for i := range handlers {
+		if got := r.Events(&handlers[i]); !reflect.DeepEqual(got, want[i]) {
+			t.Errorf("handler %d received %v, want %v", i, got, want[i])
+		}
+	}
+
+	debug.Shortcuts.Store(true)
+	defer debug.Shortcuts.Store(false)
+	r.Frame(ops)
+	conflicts := map[[2]key.Set]bool{{"Short-[C,S]", "Short-S"}: true}
+	if got := r.key.queue.conflicts; !reflect.DeepEqual(got, conflicts) {
+		t.Errorf("reported conflicts %v, want %v", got, conflicts)
+	}
+}
+
 func assertKeyEvent(t *testing.T, events []event.Event, expectedFocus bool, expectedInputs ...event.Event) {
 	t.Helper()
 	var evtFocus int
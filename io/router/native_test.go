@@ -0,0 +1,57 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package router
+
+import (
+	"image"
+	"reflect"
+	"testing"
+
+	"github.com/Seikaijyu/gio/op"
+	"github.com/Seikaijyu/gio/op/clip"
+	"github.com/Seikaijyu/gio/op/native"
+)
+
+func TestNativeViews(t *testing.T) {
+	ops, r := new(op.Ops), new(Router)
+
+	outer := clip.Rect(image.Rect(0, 0, 100, 100)).Push(ops)
+	off := op.Offset(image.Pt(50, 20)).Push(ops)
+	inner := clip.Rect(image.Rect(0, 0, 80, 40)).Push(ops)
+	native.ViewOp{Handle: 1}.Add(ops)
+	inner.Pop()
+	off.Pop()
+	native.ViewOp{Handle: 2}.Add(ops)
+	outer.Pop()
+	r.Frame(ops)
+
+	want := []NativeView{
+		{Handle: 1, Bounds: image.Rect(50, 20, 130, 60), Clip: image.Rect(50, 20, 100, 60)},
+		{Handle: 2, Bounds: image.Rect(0, 0, 100, 100), Clip: image.Rect(0, 0, 100, 100)},
+	}
+	if got := r.NativeViews(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got views %v, want %v", got, want)
+	}
+
+	ops.Reset()
+	area := clip.Rect(image.Rect(0, 0, 10, 10)).Push(ops)
+	native.ViewOp{Handle: 1, Order: 1}.Add(ops)
+	native.ViewOp{Handle: 2}.Add(ops)
+	native.ViewOp{Handle: 3, Order: -1}.Add(ops)
+	native.ViewOp{Handle: 4}.Add(ops)
+	area.Pop()
+	r.Frame(ops)
+	var order []uintptr
+	for _, v := range r.NativeViews() {
+		order = append(order, v.Handle)
+	}
+	if want := []uintptr{3, 2, 4, 1}; !reflect.DeepEqual(order, want) {
+		t.Errorf("got stacking order %v, want %v", order, want)
+	}
+
+	ops.Reset()
+	r.Frame(ops)
+	if got := r.NativeViews(); len(got) != 0 {
+		t.Errorf("got views %v after they were removed", got)
+	}
+}
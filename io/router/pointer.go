@@ -5,6 +5,7 @@ package router
 import (
 	"image"
 	"io"
+	"strings"
 
 	"github.com/Seikaijyu/gio/f32"
 	f32internal "github.com/Seikaijyu/gio/internal/f32"
@@ -20,13 +21,16 @@ import (
 type pointerQueue struct {
 	hitTree   []hitNode
 	areas     []areaNode
-	cursor    pointer.Cursor
+	cursor    areaCursor
 	handlers  map[event.Tag]*pointerHandler
 	pointers  []pointerInfo
 	transfers []io.ReadCloser // pending data transfers
 
 	scratch []event.Tag
 
+	// external tracks transfers crossing the router boundary.
+	external externalTransfer
+
 	semantic struct {
 		idsAssigned bool
 		lastID      SemanticID
@@ -66,7 +70,14 @@ type pointerHandler struct {
 	area      int
 	active    bool
 	wantsGrab bool
-	types     pointer.Kind
+	wantsDeny bool
+	// precedence is the highest Precedence of the grabbing
+	// InputOps of the handler.
+	precedence int32
+	types      pointer.Kind
+	// buttons is the union of the Buttons filters of the InputOps of
+	// the handler, with every button for an InputOp without filter.
+	buttons pointer.Buttons
 	// min and max horizontal/vertical scroll
 	scrollRange image.Rectangle
 
@@ -81,11 +92,18 @@ type areaOp struct {
 	rect image.Rectangle
 }
 
+// areaCursor is the cursor of an area, either a named cursor or an
+// image.
+type areaCursor struct {
+	name  pointer.Cursor
+	image *pointer.ImageCursor
+}
+
 type areaNode struct {
 	trans f32.Affine2D
 	area  areaOp
 
-	cursor pointer.Cursor
+	cursor areaCursor
 
 	// Tree indices, with -1 being the sentinel.
 	parent     int
@@ -97,6 +115,10 @@ type areaNode struct {
 		valid   bool
 		id      SemanticID
 		content semanticContent
+		// value and selection describe editable text. They're not
+		// part of content to keep IDs stable while the text changes.
+		value     string
+		selection semantic.SelectionOp
 	}
 	action system.Action
 }
@@ -124,6 +146,7 @@ type semanticContent struct {
 	tag      event.Tag
 	label    string
 	desc     string
+	help     string
 	class    semantic.ClassOp
 	gestures SemanticGestures
 	selected bool
@@ -222,6 +245,18 @@ func (c *pointerCollector) currentAreaBounds() image.Rectangle {
 	return c.q.areas[a].bounds()
 }
 
+// nativeView returns the placement of the native view handle over the
+// current area, clipped by the areas containing it.
+func (c *pointerCollector) nativeView(handle uintptr) NativeView {
+	a := &c.q.areas[c.currentArea()]
+	v := NativeView{Handle: handle, Bounds: a.bounds()}
+	v.Clip = v.Bounds
+	for p := a.parent; p != -1; p = c.q.areas[p].parent {
+		v.Clip = v.Clip.Intersect(c.q.areas[p].bounds())
+	}
+	return v
+}
+
 func (c *pointerCollector) addHitNode(n hitNode) {
 	n.next = c.state.nodePlusOne - 1
 	c.q.hitTree = append(c.q.hitTree, n)
@@ -276,8 +311,17 @@ func (c *pointerCollector) inputOp(op pointer.InputOp, events *handlerEvents) {
 	}
 	area.semantic.valid = area.semantic.content.gestures != 0
 	h := c.newHandler(op.Tag, events)
+	if op.Grab && (!h.wantsGrab || op.Precedence > h.precedence) {
+		h.precedence = op.Precedence
+	}
 	h.wantsGrab = h.wantsGrab || op.Grab
+	h.wantsDeny = h.wantsDeny || op.Deny
 	h.types = h.types | op.Kinds
+	buttons := op.Buttons
+	if buttons == 0 {
+		buttons = ^pointer.Buttons(0)
+	}
+	h.buttons |= buttons
 	h.scrollRange = op.ScrollBounds
 }
 
@@ -295,6 +339,13 @@ func (c *pointerCollector) semanticDesc(desc string) {
 	area.semantic.content.desc = desc
 }
 
+func (c *pointerCollector) semanticHelp(help string) {
+	areaID := c.currentArea()
+	area := &c.q.areas[areaID]
+	area.semantic.valid = true
+	area.semantic.content.help = help
+}
+
 func (c *pointerCollector) semanticClass(class semantic.ClassOp) {
 	areaID := c.currentArea()
 	area := &c.q.areas[areaID]
@@ -316,7 +367,21 @@ func (c *pointerCollector) semanticEnabled(enabled bool) {
 	area.semantic.content.disabled = !enabled
 }
 
-func (c *pointerCollector) cursor(cursor pointer.Cursor) {
+func (c *pointerCollector) semanticValue(val string) {
+	areaID := c.currentArea()
+	area := &c.q.areas[areaID]
+	area.semantic.valid = true
+	area.semantic.value = val
+}
+
+func (c *pointerCollector) semanticSelection(sel semantic.SelectionOp) {
+	areaID := c.currentArea()
+	area := &c.q.areas[areaID]
+	area.semantic.valid = true
+	area.semantic.selection = sel
+}
+
+func (c *pointerCollector) cursor(cursor areaCursor) {
 	areaID := c.currentArea()
 	area := &c.q.areas[areaID]
 	area.cursor = cursor
@@ -385,9 +450,35 @@ func (q *pointerQueue) appendSemanticArea(nodes []SemanticNode, parentID Semanti
 	n := &nodes[nodeIdx]
 	n.ParentID = parentID
 	n.Children = nodes[childStart:childEnd]
+	if n.Desc.Label == "" && isControl(n.Desc) {
+		n.Desc.Label = strings.Join(appendDerivedLabels(nil, n.Children), " ")
+	}
 	return nodes
 }
 
+// isControl reports whether d describes a control, such as a button,
+// that is labeled by its content when it has no label of its own.
+func isControl(d SemanticDesc) bool {
+	switch d.Class {
+	case semantic.Button, semantic.CheckBox, semantic.RadioButton, semantic.Switch:
+		return true
+	}
+	return d.Gestures&ClickGesture != 0
+}
+
+// appendDerivedLabels appends the labels of the nodes to labels, in
+// order. The labels of descendants stand in for nodes without a label.
+func appendDerivedLabels(labels []string, nodes []SemanticNode) []string {
+	for _, n := range nodes {
+		if n.Desc.Label != "" {
+			labels = append(labels, n.Desc.Label)
+		} else {
+			labels = appendDerivedLabels(labels, n.Children)
+		}
+	}
+	return labels
+}
+
 func (q *pointerQueue) appendSemanticChildren(nodes []SemanticNode, areaIdx int) []SemanticNode {
 	if areaIdx == -1 {
 		return nodes
@@ -401,10 +492,13 @@ func (q *pointerQueue) appendSemanticChildren(nodes []SemanticNode, areaIdx int)
 				Bounds:      a.bounds(),
 				Label:       cnt.label,
 				Description: cnt.desc,
+				Help:        cnt.help,
 				Class:       cnt.class,
 				Gestures:    cnt.gestures,
 				Selected:    cnt.selected,
 				Disabled:    cnt.disabled,
+				Value:       a.semantic.value,
+				Selection:   a.semantic.selection,
 			},
 			areaIdx: areaIdx,
 		})
@@ -459,17 +553,91 @@ func (q *pointerQueue) SemanticAt(pos f32.Point) (semID SemanticID, hasSemID boo
 	return semID, hasSemID
 }
 
+func (q *pointerQueue) HelpAt(pos f32.Point) (help string, bounds image.Rectangle, ok bool) {
+	q.hitTest(pos, func(n *hitNode) bool {
+		area := q.areas[n.area]
+		if h := area.semantic.content.help; h != "" {
+			help, bounds, ok = h, area.bounds(), true
+			return false
+		}
+		return true
+	})
+	return help, bounds, ok
+}
+
+func (q *pointerQueue) AppendHits(hits []Hit, pos f32.Point) []Hit {
+	start := len(hits)
+	q.hitTest(pos, func(n *hitNode) bool {
+		if n.tag == nil {
+			return true
+		}
+		if _, exists := q.handlers[n.tag]; !exists {
+			return true
+		}
+		for _, h := range hits[start:] {
+			if h.Tag == n.tag {
+				return true
+			}
+		}
+		hits = append(hits, Hit{Tag: n.tag, Bounds: q.areas[n.area].bounds()})
+		return true
+	})
+	return hits
+}
+
+// semanticArea returns the index of the area described by the
+// semantic node id.
+func (q *pointerQueue) semanticArea(id SemanticID) (int, bool) {
+	q.assignSemIDs()
+	for i, a := range q.areas {
+		if a.semantic.valid && a.semantic.id == id {
+			return i, true
+		}
+	}
+	return -1, false
+}
+
+// semanticFor returns the ID of the semantic node closest to areaIdx,
+// searching towards the root.
+func (q *pointerQueue) semanticFor(areaIdx int) (SemanticID, bool) {
+	q.assignSemIDs()
+	for areaIdx != -1 {
+		a := &q.areas[areaIdx]
+		if a.semantic.valid {
+			return a.semantic.id, true
+		}
+		areaIdx = a.parent
+	}
+	return 0, false
+}
+
+// keyHandlerIn returns the first key handler in the area or its
+// descendants.
+func (q *pointerQueue) keyHandlerIn(areaIdx int) (event.Tag, bool) {
+	for _, n := range q.hitTree {
+		if n.ktag == nil {
+			continue
+		}
+		for a := n.area; a != -1; a = q.areas[a].parent {
+			if a == areaIdx {
+				return n.ktag, true
+			}
+		}
+	}
+	return nil, false
+}
+
 // hitTest searches the hit tree for nodes matching pos. Any node matching pos will
 // have the onNode func invoked on it to allow the caller to extract whatever information
 // is necessary for further processing. onNode may return false to terminate the walk of
 // the hit tree, or true to continue. Providing this algorithm in this generic way
 // allows normal event routing and system action event routing to share the same traversal
 // logic even though they are interested in different aspects of hit nodes.
-func (q *pointerQueue) hitTest(pos f32.Point, onNode func(*hitNode) bool) pointer.Cursor {
+func (q *pointerQueue) hitTest(pos f32.Point, onNode func(*hitNode) bool) areaCursor {
 	// Track whether we're passing through hits.
 	pass := true
 	idx := len(q.hitTree) - 1
-	cursor := pointer.CursorDefault
+	var cursor areaCursor
 	for idx >= 0 {
 		n := &q.hitTree[idx]
 		hit, c := q.hit(n.area, pos)
@@ -477,7 +645,7 @@ func (q *pointerQueue) hitTest(pos f32.Point, onNode func(*hitNode) bool) pointe
 			idx--
 			continue
 		}
-		if cursor == pointer.CursorDefault {
+		if cursor.isDefault() {
 			cursor = c
 		}
 		pass = pass && n.pass
@@ -493,7 +661,7 @@ func (q *pointerQueue) hitTest(pos f32.Point, onNode func(*hitNode) bool) pointe
 	return cursor
 }
 
-func (q *pointerQueue) opHit(pos f32.Point) ([]event.Tag, pointer.Cursor) {
+func (q *pointerQueue) opHit(pos f32.Point) ([]event.Tag, areaCursor) {
 	hits := q.scratch[:0]
 	cursor := q.hitTest(pos, func(n *hitNode) bool {
 		if n.tag != nil {
@@ -514,11 +682,26 @@ func (q *pointerQueue) invTransform(areaIdx int, p f32.Point) f32.Point {
 	return q.areas[areaIdx].trans.Invert().Transform(p)
 }
 
-func (q *pointerQueue) hit(areaIdx int, p f32.Point) (bool, pointer.Cursor) {
-	c := pointer.CursorDefault
+// localEvent returns e with its positions transformed to the local
+// coordinates of an area.
+func (q *pointerQueue) localEvent(areaIdx int, e pointer.Event) pointer.Event {
+	e.Position = q.invTransform(areaIdx, e.Position)
+	if len(e.History) > 0 {
+		hist := make([]pointer.Sample, len(e.History))
+		for i, s := range e.History {
+			s.Position = q.invTransform(areaIdx, s.Position)
+			hist[i] = s
+		}
+		e.History = hist
+	}
+	return e
+}
+
+func (q *pointerQueue) hit(areaIdx int, p f32.Point) (bool, areaCursor) {
+	var c areaCursor
 	for areaIdx != -1 {
 		a := &q.areas[areaIdx]
-		if c == pointer.CursorDefault {
+		if c.isDefault() {
 			c = a.cursor
 		}
 		p := a.trans.Invert().Transform(p)
@@ -530,6 +713,10 @@ func (q *pointerQueue) hit(areaIdx int, p f32.Point) (bool, pointer.Cursor) {
 	return true, c
 }
 
+func (c areaCursor) isDefault() bool {
+	return c.name == pointer.CursorDefault && c.image == nil
+}
+
 func (q *pointerQueue) reset() {
 	if q.handlers == nil {
 		q.handlers = make(map[event.Tag]*pointerHandler)
@@ -538,7 +725,10 @@ func (q *pointerQueue) reset() {
 		// Reset handler.
 		h.active = false
 		h.wantsGrab = false
+		h.wantsDeny = false
+		h.precedence = 0
 		h.types = 0
+		h.buttons = 0
 		h.sourceMimes = h.sourceMimes[:0]
 		h.targetMimes = h.targetMimes[:0]
 	}
@@ -573,31 +763,57 @@ func (q *pointerQueue) Frame(events *handlerEvents) {
 			q.dropHandler(nil, k)
 			delete(q.handlers, k)
 		}
-		if h.wantsGrab {
-			for _, p := range q.pointers {
-				if !p.pressed {
-					continue
-				}
-				for i, k2 := range p.handlers {
-					if k2 == k {
-						// Drop other handlers that lost their grab.
-						dropped := q.scratch[:0]
-						dropped = append(dropped, p.handlers[:i]...)
-						dropped = append(dropped, p.handlers[i+1:]...)
-						for _, tag := range dropped {
-							q.dropHandler(events, tag)
-						}
-						break
-					}
-				}
-			}
-		}
 	}
+	q.arbitrate(events)
 	for i := range q.pointers {
 		p := &q.pointers[i]
 		q.deliverEnterLeaveEvents(p, events, p.last)
 		q.deliverTransferDataEvent(p, events)
 	}
+	q.frameExternal(events)
+}
+
+// arbitrate resolves the matching sets of the pressed pointers.
+// Handlers that deny leave the sets first, and then the winner among
+// the handlers that grab a pointer excludes all other handlers.
+func (q *pointerQueue) arbitrate(events *handlerEvents) {
+	for i := range q.pointers {
+		p := &q.pointers[i]
+		if !p.pressed {
+			continue
+		}
+		dropped := q.scratch[:0]
+		for _, k := range p.handlers {
+			if q.handlers[k].wantsDeny {
+				dropped = append(dropped, k)
+			}
+		}
+		for _, k := range dropped {
+			q.dropHandler(events, k)
+		}
+		// p.handlers is ordered from foremost to rearmost.
+		var winner event.Tag
+		var best *pointerHandler
+		for _, k := range p.handlers {
+			h := q.handlers[k]
+			if h.wantsGrab && (best == nil || h.precedence > best.precedence) {
+				winner, best = k, h
+			}
+		}
+		if winner != nil {
+			// Drop the handlers that lost the grab.
+			dropped = dropped[:0]
+			for _, k := range p.handlers {
+				if k != winner {
+					dropped = append(dropped, k)
+				}
+			}
+			for _, k := range dropped {
+				q.dropHandler(events, k)
+			}
+		}
+		q.scratch = dropped[:0]
+	}
 }
 
 func (q *pointerQueue) dropHandler(events *handlerEvents, tag event.Tag) {
@@ -654,14 +870,14 @@ func (q *pointerQueue) Deliver(areaIdx int, e pointer.Event, events *handlerEven
 		}
 		e := e
 		if e.Kind == pointer.Scroll {
-			if sx == 0 && sy == 0 {
+			if sx == 0 && sy == 0 && e.Phase == pointer.PhaseNone {
 				break
 			}
 			// Distribute the scroll to the handler based on its ScrollRange.
 			sx, e.Scroll.X = setScrollEvent(sx, h.scrollRange.Min.X, h.scrollRange.Max.X)
 			sy, e.Scroll.Y = setScrollEvent(sy, h.scrollRange.Min.Y, h.scrollRange.Max.Y)
 		}
-		e.Position = q.invTransform(h.area, e.Position)
+		e = q.localEvent(h.area, e)
 		events.Add(n.tag, e)
 		if e.Kind != pointer.Scroll {
 			break
@@ -684,6 +900,8 @@ func (q *pointerQueue) SemanticArea(areaIdx int) (semanticContent, int) {
 
 func (q *pointerQueue) Push(e pointer.Event, events *handlerEvents) {
 	if e.Kind == pointer.Cancel {
+		// A cancelled drag is not dropped.
+		q.external.redirect = ""
 		q.pointers = q.pointers[:0]
 		for k := range q.handlers {
 			q.dropHandler(events, k)
@@ -713,9 +931,14 @@ func (q *pointerQueue) Push(e pointer.Event, events *handlerEvents) {
 		p.pressed = false
 		q.deliverEnterLeaveEvents(p, events, e)
 		q.deliverDropEvent(p, events)
-	case pointer.Scroll:
+	case pointer.Scroll, pointer.Pinch, pointer.Rotate, pointer.SmartZoom:
 		q.deliverEnterLeaveEvents(p, events, e)
 		q.deliverEvent(p, events, e)
+	case pointer.Leave:
+		// The pointer left the window, such as a pen moving out of
+		// range of its tablet.
+		p.pressed = false
+		q.deliverEnterLeaveEvents(p, events, e)
 	default:
 		panic("unsupported pointer event type")
 	}
@@ -726,6 +949,17 @@ func (q *pointerQueue) Push(e pointer.Event, events *handlerEvents) {
 	}
 }
 
+// acceptsButtons reports whether e passes the Buttons filter of h.
+func (h *pointerHandler) acceptsButtons(e pointer.Event) bool {
+	if h.buttons == ^pointer.Buttons(0) || e.Source != pointer.Mouse || e.Kind != pointer.Press && e.Kind != pointer.Drag {
+		return true
+	}
+	return e.Buttons&h.buttons != 0
+}
+
+// gestureKinds are the kinds of trackpad gesture events.
+const gestureKinds = pointer.Pinch | pointer.Rotate | pointer.SmartZoom
+
 func (q *pointerQueue) deliverEvent(p *pointerInfo, events *handlerEvents, e pointer.Event) {
 	foremost := true
 	if p.pressed && len(p.handlers) == 1 {
@@ -736,14 +970,17 @@ func (q *pointerQueue) deliverEvent(p *pointerInfo, events *handlerEvents, e poi
 	for _, k := range p.handlers {
 		h := q.handlers[k]
 		if e.Kind == pointer.Scroll {
-			if sx == 0 && sy == 0 {
+			// Events with phases are delivered to every handler,
+			// even without scroll left, so they can follow the
+			// progress of the gesture.
+			if sx == 0 && sy == 0 && e.Phase == pointer.PhaseNone {
 				return
 			}
 			// Distribute the scroll to the handler based on its ScrollRange.
 			sx, e.Scroll.X = setScrollEvent(sx, h.scrollRange.Min.X, h.scrollRange.Max.X)
 			sy, e.Scroll.Y = setScrollEvent(sy, h.scrollRange.Min.Y, h.scrollRange.Max.Y)
 		}
-		if e.Kind&h.types == 0 {
+		if e.Kind&h.types == 0 || !h.acceptsButtons(e) {
 			continue
 		}
 		e := e
@@ -751,15 +988,21 @@ func (q *pointerQueue) deliverEvent(p *pointerInfo, events *handlerEvents, e poi
 			foremost = false
 			e.Priority = pointer.Foremost
 		}
-		e.Position = q.invTransform(h.area, e.Position)
+		e = q.localEvent(h.area, e)
 		events.Add(k, e)
+		if e.Kind&gestureKinds != 0 {
+			// Only the foremost handler receives gestures, to
+			// avoid zooming nested content more than once.
+			return
+		}
 	}
 }
 
 func (q *pointerQueue) deliverEnterLeaveEvents(p *pointerInfo, events *handlerEvents, e pointer.Event) {
 	var hits []event.Tag
-	if e.Source != pointer.Mouse && !p.pressed && e.Kind != pointer.Press {
-		// Consider non-mouse pointers leaving when they're released.
+	if e.Kind == pointer.Leave || e.Source == pointer.Touch && !p.pressed && e.Kind != pointer.Press {
+		// Consider touch points leaving when they're released. Pens
+		// hover like a mouse until they leave.
 	} else {
 		hits, q.cursor = q.opHit(e.Position)
 		if p.pressed {
@@ -794,7 +1037,7 @@ func (q *pointerQueue) deliverEnterLeaveEvents(p *pointerInfo, events *handlerEv
 
 		if e.Kind&h.types != 0 {
 			e := e
-			e.Position = q.invTransform(h.area, e.Position)
+			e = q.localEvent(h.area, e)
 			events.Add(k, e)
 		}
 	}
@@ -808,7 +1051,7 @@ func (q *pointerQueue) deliverEnterLeaveEvents(p *pointerInfo, events *handlerEv
 
 		if e.Kind&h.types != 0 {
 			e := e
-			e.Position = q.invTransform(h.area, e.Position)
+			e = q.localEvent(h.area, e)
 			events.Add(k, e)
 		}
 	}
@@ -841,6 +1084,10 @@ func (q *pointerQueue) deliverDropEvent(p *pointerInfo, events *handlerEvents) {
 	if p.dataSource == nil {
 		return
 	}
+	if q.external.redirect != "" {
+		q.redirectDrop(p, events)
+		return
+	}
 	// Request data from the source.
 	src := q.handlers[p.dataSource]
 	for _, k := range p.entered {
@@ -929,8 +1176,13 @@ func addHandler(tags []event.Tag, tag event.Tag) []event.Tag {
 
 // firstMimeMatch returns the first type match between src and tgt.
 func firstMimeMatch(src, tgt *pointerHandler) (first string, matched bool) {
-	for _, m1 := range tgt.targetMimes {
-		for _, m2 := range src.sourceMimes {
+	return firstMatch(src.sourceMimes, tgt.targetMimes)
+}
+
+// firstMatch returns the first type in targets that is also in sources.
+func firstMatch(sources, targets []string) (first string, matched bool) {
+	for _, m1 := range targets {
+		for _, m2 := range sources {
 			if m1 == m2 {
 				return m1, true
 			}
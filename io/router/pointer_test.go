@@ -14,6 +14,7 @@ import (
 	"github.com/Seikaijyu/gio/io/event"
 	"github.com/Seikaijyu/gio/io/key"
 	"github.com/Seikaijyu/gio/io/pointer"
+	"github.com/Seikaijyu/gio/io/profile"
 	"github.com/Seikaijyu/gio/io/system"
 	"github.com/Seikaijyu/gio/io/transfer"
 	"github.com/Seikaijyu/gio/op"
@@ -146,6 +147,105 @@ func TestPointerGrabSameHandlerTwice(t *testing.T) {
 	assertEventPointerTypeSequence(t, r.Events(handler2), pointer.Cancel)
 }
 
+func TestPointerGrabPrecedence(t *testing.T) {
+	handler1 := new(int)
+	handler2 := new(int)
+	handler3 := new(int)
+	var ops op.Ops
+
+	types := pointer.Press | pointer.Release
+
+	// Handlers added later are in front.
+	pointer.InputOp{Tag: handler1, Kinds: types, Grab: true, Precedence: 1}.Add(&ops)
+	pointer.InputOp{Tag: handler2, Kinds: types, Grab: true}.Add(&ops)
+	pointer.InputOp{Tag: handler3, Kinds: types, Grab: true}.Add(&ops)
+
+	var r Router
+	r.Frame(&ops)
+	r.Queue(
+		pointer.Event{
+			Kind:     pointer.Press,
+			Position: f32.Pt(50, 50),
+		},
+	)
+	r.Events(handler1)
+	r.Events(handler2)
+	r.Events(handler3)
+	r.Frame(&ops)
+	r.Queue(
+		pointer.Event{
+			Kind:     pointer.Release,
+			Position: f32.Pt(50, 50),
+		},
+	)
+	assertEventPointerTypeSequence(t, r.Events(handler1), pointer.Release)
+	assertEventPointerTypeSequence(t, r.Events(handler2), pointer.Cancel)
+	assertEventPointerTypeSequence(t, r.Events(handler3), pointer.Cancel)
+
+	// Among equal precedences, the foremost handler wins.
+	ops.Reset()
+	pointer.InputOp{Tag: handler1, Kinds: types, Grab: true}.Add(&ops)
+	pointer.InputOp{Tag: handler2, Kinds: types, Grab: true}.Add(&ops)
+	pointer.InputOp{Tag: handler3, Kinds: types}.Add(&ops)
+	r.Frame(&ops)
+	r.Queue(
+		pointer.Event{
+			Kind:     pointer.Press,
+			Position: f32.Pt(50, 50),
+		},
+	)
+	r.Events(handler1)
+	r.Events(handler2)
+	r.Events(handler3)
+	r.Frame(&ops)
+	r.Queue(
+		pointer.Event{
+			Kind:     pointer.Release,
+			Position: f32.Pt(50, 50),
+		},
+	)
+	assertEventPointerTypeSequence(t, r.Events(handler1), pointer.Cancel)
+	assertEventPointerTypeSequence(t, r.Events(handler2), pointer.Release)
+	assertEventPointerTypeSequence(t, r.Events(handler3), pointer.Cancel)
+}
+
+func TestPointerDeny(t *testing.T) {
+	handler1 := new(int)
+	handler2 := new(int)
+	var ops op.Ops
+
+	types := pointer.Press | pointer.Release
+
+	pointer.InputOp{Tag: handler1, Kinds: types}.Add(&ops)
+	pointer.InputOp{Tag: handler2, Kinds: types}.Add(&ops)
+
+	var r Router
+	r.Frame(&ops)
+	r.Queue(
+		pointer.Event{
+			Kind:     pointer.Press,
+			Position: f32.Pt(50, 50),
+		},
+	)
+	r.Events(handler1)
+	r.Events(handler2)
+
+	ops.Reset()
+	pointer.InputOp{Tag: handler1, Kinds: types}.Add(&ops)
+	pointer.InputOp{Tag: handler2, Kinds: types, Deny: true}.Add(&ops)
+	r.Frame(&ops)
+	assertEventPointerTypeSequence(t, r.Events(handler2), pointer.Cancel)
+	r.Queue(
+		pointer.Event{
+			Kind:     pointer.Release,
+			Position: f32.Pt(50, 50),
+		},
+	)
+	// The remaining handler has the pointer to itself.
+	assertEventPriorities(t, r.Events(handler1), pointer.Grabbed)
+	assertEventPointerTypeSequence(t, r.Events(handler2))
+}
+
 func TestPointerMove(t *testing.T) {
 	handler1 := new(int)
 	handler2 := new(int)
@@ -188,6 +288,91 @@ func TestPointerMove(t *testing.T) {
 	assertEventPointerTypeSequence(t, r.Events(handler2), pointer.Cancel, pointer.Enter, pointer.Move, pointer.Leave, pointer.Cancel)
 }
 
+func TestPointerHistory(t *testing.T) {
+	handler := new(int)
+	var ops op.Ops
+	off := op.Offset(image.Pt(10, 20)).Push(&ops)
+	addPointerHandler(&ops, handler, image.Rect(0, 0, 100, 100))
+	off.Pop()
+
+	var r Router
+	r.Frame(&ops)
+	hist := []pointer.Sample{
+		{Time: 1, Position: f32.Pt(20, 30)},
+		{Time: 2, Position: f32.Pt(30, 40)},
+	}
+	r.Queue(pointer.Event{
+		Kind:     pointer.Move,
+		Position: f32.Pt(40, 50),
+		Time:     3,
+		History:  hist,
+	})
+	var got []pointer.Sample
+	for _, e := range r.Events(handler) {
+		if e, ok := e.(pointer.Event); ok && e.Kind == pointer.Move {
+			got = e.History
+		}
+	}
+	want := []pointer.Sample{
+		{Time: 1, Position: f32.Pt(10, 10)},
+		{Time: 2, Position: f32.Pt(20, 20)},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got history %v, want %v", got, want)
+	}
+	if hist[0].Position != f32.Pt(20, 30) {
+		t.Error("the history of the queued event was modified")
+	}
+}
+
+func TestTakeEvents(t *testing.T) {
+	handler, other, prof := new(int), new(int), new(int)
+	var ops op.Ops
+	addPointerHandler(&ops, handler, image.Rect(0, 0, 100, 100))
+	addPointerHandler(&ops, other, image.Rect(100, 0, 200, 100))
+	profile.Op{Tag: prof}.Add(&ops)
+
+	var r Router
+	r.Frame(&ops)
+	r.Queue(
+		pointer.Event{Kind: pointer.Move, Position: f32.Pt(10, 10)},
+		profile.Event{Timings: "frame"},
+	)
+	s := r.TakeEvents()
+	if evts := r.Events(handler); len(evts) != 0 {
+		t.Errorf("router kept %v after TakeEvents", evts)
+	}
+	if evts := r.Events(prof); len(evts) != 0 {
+		t.Errorf("router kept profile %v after TakeEvents", evts)
+	}
+	// Taken events are not delivered again after the next frame.
+	r.Frame(&ops)
+	if evts := r.Events(handler); len(evts) != 0 {
+		t.Errorf("router delivered %v again after a frame", evts)
+	}
+	r.Queue(pointer.Event{Kind: pointer.Move, Position: f32.Pt(20, 10)})
+	s2 := r.TakeEvents()
+	s.Merge(s2)
+	if evts := s2.Events(handler); len(evts) != 0 {
+		t.Errorf("merged snapshot kept %v", evts)
+	}
+	assertEventPointerTypeSequence(t, s.Events(handler), pointer.Cancel, pointer.Enter, pointer.Move, pointer.Move)
+	if evts := s.Events(handler); len(evts) != 0 {
+		t.Errorf("snapshot kept %v after Events", evts)
+	}
+	assertEventPointerTypeSequence(t, s.Events(other), pointer.Cancel)
+	// Profile handlers receive the timings once for every frame.
+	if evts := s.Events(prof); len(evts) != 2 || evts[0].(profile.Event).Timings != "frame" {
+		t.Errorf("snapshot has profile events %v, want the timings of 2 frames", evts)
+	}
+	// Events of a zero snapshot are empty.
+	var zero EventSnapshot
+	zero.Merge(r.TakeEvents())
+	if evts := zero.Events(handler); len(evts) != 0 {
+		t.Errorf("snapshot of no events has %v", evts)
+	}
+}
+
 func TestPointerTypes(t *testing.T) {
 	handler := new(int)
 	var ops op.Ops
@@ -217,6 +402,124 @@ func TestPointerTypes(t *testing.T) {
 	assertEventPointerTypeSequence(t, r.Events(handler), pointer.Cancel, pointer.Press, pointer.Release)
 }
 
+func TestPointerButtons(t *testing.T) {
+	handler := new(int)
+	var ops op.Ops
+	r1 := clip.Rect(image.Rect(0, 0, 100, 100)).Push(&ops)
+	pointer.InputOp{
+		Tag:     handler,
+		Kinds:   pointer.Press | pointer.Drag | pointer.Release,
+		Buttons: pointer.ButtonSecondary,
+	}.Add(&ops)
+	r1.Pop()
+
+	var r Router
+	r.Frame(&ops)
+	r.Queue(
+		// A primary drag is filtered out, except for its release.
+		pointer.Event{Kind: pointer.Press, Buttons: pointer.ButtonPrimary, Position: f32.Pt(50, 50)},
+		pointer.Event{Kind: pointer.Move, Buttons: pointer.ButtonPrimary, Position: f32.Pt(60, 50)},
+		pointer.Event{Kind: pointer.Release, Position: f32.Pt(60, 50)},
+		pointer.Event{Kind: pointer.Press, Buttons: pointer.ButtonSecondary, Position: f32.Pt(50, 50)},
+		pointer.Event{Kind: pointer.Move, Buttons: pointer.ButtonSecondary, Position: f32.Pt(60, 50)},
+		pointer.Event{Kind: pointer.Release, Position: f32.Pt(60, 50)},
+		// Touches are not filtered.
+		pointer.Event{Kind: pointer.Press, Source: pointer.Touch, Position: f32.Pt(50, 50)},
+	)
+	assertEventPointerTypeSequence(t, r.Events(handler), pointer.Cancel, pointer.Release, pointer.Press, pointer.Drag, pointer.Release, pointer.Press)
+}
+
+func TestPointerGestures(t *testing.T) {
+	outer, inner := new(int), new(int)
+	var ops op.Ops
+	r1 := clip.Rect(image.Rect(0, 0, 100, 100)).Push(&ops)
+	pointer.InputOp{
+		Tag:   outer,
+		Kinds: pointer.Pinch | pointer.Rotate | pointer.SmartZoom,
+	}.Add(&ops)
+	r2 := clip.Rect(image.Rect(0, 0, 50, 50)).Push(&ops)
+	pointer.InputOp{
+		Tag:   inner,
+		Kinds: pointer.Pinch,
+	}.Add(&ops)
+	r2.Pop()
+	r1.Pop()
+
+	var r Router
+	r.Frame(&ops)
+	r.Queue(
+		pointer.Event{
+			Kind:     pointer.Pinch,
+			Position: f32.Pt(25, 25),
+			Zoom:     .5,
+		},
+		pointer.Event{
+			Kind:     pointer.Rotate,
+			Position: f32.Pt(25, 25),
+			Rotation: 1,
+		},
+		pointer.Event{
+			Kind:     pointer.SmartZoom,
+			Position: f32.Pt(75, 75),
+		},
+	)
+	// The inner handler consumes the pinch, but not the rotation.
+	assertEventPointerTypeSequence(t, r.Events(inner), pointer.Cancel, pointer.Pinch)
+	assertEventPointerTypeSequence(t, r.Events(outer), pointer.Cancel, pointer.Rotate, pointer.SmartZoom)
+}
+
+func TestPointerScrollPhase(t *testing.T) {
+	outer, inner := new(int), new(int)
+	var ops op.Ops
+	r1 := clip.Rect(image.Rect(0, 0, 100, 100)).Push(&ops)
+	pointer.InputOp{
+		Tag:          outer,
+		Kinds:        pointer.Scroll,
+		ScrollBounds: image.Rect(0, -100, 0, 100),
+	}.Add(&ops)
+	pointer.InputOp{
+		Tag:          inner,
+		Kinds:        pointer.Scroll,
+		ScrollBounds: image.Rect(0, -100, 0, 100),
+	}.Add(&ops)
+	r1.Pop()
+
+	var r Router
+	r.Frame(&ops)
+	r.Queue(
+		pointer.Event{
+			Kind:     pointer.Scroll,
+			Position: f32.Pt(50, 50),
+			Scroll:   f32.Pt(0, 10),
+			Phase:    pointer.PhaseChanged,
+		},
+		pointer.Event{
+			Kind:     pointer.Scroll,
+			Position: f32.Pt(50, 50),
+			Phase:    pointer.PhaseMomentumEnded,
+		},
+		// Events without phase and scroll are dropped.
+		pointer.Event{
+			Kind:     pointer.Scroll,
+			Position: f32.Pt(50, 50),
+		},
+	)
+	for _, tag := range []event.Tag{inner, outer} {
+		var phases []pointer.Phase
+		for _, e := range r.Events(tag) {
+			if e, ok := e.(pointer.Event); ok && e.Kind == pointer.Scroll {
+				phases = append(phases, e.Phase)
+			}
+		}
+		// The outer handler receives the phase changes, even
+		// though the inner handler consumed the scroll.
+		want := []pointer.Phase{pointer.PhaseChanged, pointer.PhaseMomentumEnded}
+		if !reflect.DeepEqual(phases, want) {
+			t.Errorf("got phases %v, want %v", phases, want)
+		}
+	}
+}
+
 func TestPointerSystemAction(t *testing.T) {
 	t.Run("simple", func(t *testing.T) {
 		var ops op.Ops
@@ -424,6 +727,79 @@ func TestPointerEnterLeave(t *testing.T) {
 
 }
 
+func TestPointerEnterLeavePen(t *testing.T) {
+	handler := new(int)
+	var ops op.Ops
+	addPointerHandler(&ops, handler, image.Rect(0, 0, 100, 100))
+
+	var r Router
+	r.Frame(&ops)
+	const pen pointer.ID = 1
+	// A pen in range of its tablet hovers like a mouse.
+	r.Queue(
+		pointer.Event{
+			Kind:      pointer.Move,
+			Source:    pointer.Stylus,
+			PointerID: pen,
+			Position:  f32.Pt(50, 50),
+		},
+	)
+	assertEventPointerTypeSequence(t, r.Events(handler), pointer.Cancel, pointer.Enter, pointer.Move)
+
+	// The pen hovers after it lifts from the tablet.
+	r.Queue(
+		pointer.Event{
+			Kind:      pointer.Press,
+			Source:    pointer.Stylus,
+			PointerID: pen,
+			Position:  f32.Pt(50, 50),
+		},
+		pointer.Event{
+			Kind:      pointer.Release,
+			Source:    pointer.Stylus,
+			PointerID: pen,
+			Position:  f32.Pt(50, 50),
+		},
+		pointer.Event{
+			Kind:      pointer.Move,
+			Source:    pointer.Stylus,
+			PointerID: pen,
+			Position:  f32.Pt(60, 60),
+		},
+	)
+	assertEventPointerTypeSequence(t, r.Events(handler), pointer.Press, pointer.Release, pointer.Move)
+
+	// The hover ends when the pen moves out of range.
+	r.Queue(
+		pointer.Event{
+			Kind:      pointer.Leave,
+			Source:    pointer.Stylus,
+			PointerID: pen,
+			Position:  f32.Pt(60, 60),
+		},
+	)
+	assertEventPointerTypeSequence(t, r.Events(handler), pointer.Leave)
+	r.Frame(&ops)
+	assertEventPointerTypeSequence(t, r.Events(handler))
+
+	// Touch points leave when they're released.
+	r.Queue(
+		pointer.Event{
+			Kind:      pointer.Press,
+			Source:    pointer.Touch,
+			PointerID: 2,
+			Position:  f32.Pt(50, 50),
+		},
+		pointer.Event{
+			Kind:      pointer.Release,
+			Source:    pointer.Touch,
+			PointerID: 2,
+			Position:  f32.Pt(50, 50),
+		},
+	)
+	assertEventPointerTypeSequence(t, r.Events(handler), pointer.Enter, pointer.Press, pointer.Release, pointer.Leave)
+}
+
 func TestMultipleAreas(t *testing.T) {
 	handler := new(int)
 
@@ -723,6 +1099,42 @@ func TestCursor(t *testing.T) {
 	}
 }
 
+func TestImageCursor(t *testing.T) {
+	ops := new(op.Ops)
+	var r Router
+	img := image.NewNRGBA(image.Rect(0, 0, 4, 4))
+	cursor := pointer.CursorFromImage(img, image.Pt(2, 2))
+	outer := clip.Rect(image.Rect(0, 0, 100, 100)).Push(ops)
+	pointer.CursorText.Add(ops)
+	inner := clip.Rect(image.Rect(0, 0, 50, 50)).Push(ops)
+	cursor.Add(ops)
+	inner.Pop()
+	outer.Pop()
+	r.Frame(ops)
+
+	for _, tc := range []struct {
+		pos   f32.Point
+		name  pointer.Cursor
+		image *pointer.ImageCursor
+	}{
+		{pos: f32.Pt(25, 25), image: cursor},
+		{pos: f32.Pt(75, 75), name: pointer.CursorText},
+		{pos: f32.Pt(200, 200), name: pointer.CursorDefault},
+	} {
+		r.Queue(pointer.Event{
+			Kind:     pointer.Move,
+			Source:   pointer.Mouse,
+			Position: tc.pos,
+		})
+		if got := r.ImageCursor(); got != tc.image {
+			t.Errorf("%v: got image cursor %p, want %p", tc.pos, got, tc.image)
+		}
+		if got := r.Cursor(); got != tc.name {
+			t.Errorf("%v: got cursor %v, want %v", tc.pos, got, tc.name)
+		}
+	}
+}
+
 func TestPassOp(t *testing.T) {
 	var ops op.Ops
 
@@ -1130,6 +1542,107 @@ func TestTransfer(t *testing.T) {
 	})
 }
 
+func TestTransferBetweenRouters(t *testing.T) {
+	area := image.Rect(0, 0, 20, 20)
+	cancel := pointer.Event{Kind: pointer.Cancel}
+
+	// The source router, such as a window, has a data source.
+	srcOps := new(op.Ops)
+	src := new(int)
+	stack := clip.Rect(area).Push(srcOps)
+	transfer.SourceOp{Tag: src, Type: "file"}.Add(srcOps)
+	stack.Pop()
+	var srcRouter Router
+	srcRouter.Frame(srcOps)
+
+	// The target router has a data target.
+	tgtOps := new(op.Ops)
+	tgt := new(int)
+	stack = clip.Rect(area).Push(tgtOps)
+	transfer.TargetOp{Tag: tgt, Type: "file"}.Add(tgtOps)
+	stack.Pop()
+	var tgtRouter Router
+	tgtRouter.Frame(tgtOps)
+	assertEventSequence(t, tgtRouter.Events(tgt), cancel)
+
+	// Drag from the source.
+	srcRouter.Queue(
+		pointer.Event{Position: f32.Pt(10, 10), Kind: pointer.Press},
+		pointer.Event{Position: f32.Pt(10, 10), Kind: pointer.Move},
+	)
+	assertEventSequence(t, srcRouter.Events(src), cancel)
+	mimes, ok := srcRouter.DragSource()
+	if !ok || len(mimes) != 1 || mimes[0] != "file" {
+		t.Fatalf("got drag source %v, %v; want [file]", mimes, ok)
+	}
+
+	// The drag enters the target router.
+	tgtRouter.ExternalDrag(mimes)
+	assertEventSequence(t, tgtRouter.Events(tgt), transfer.InitiateEvent{})
+	// Hovering finds the targets without dropping.
+	if m, ok := tgtRouter.ExternalTarget(f32.Pt(30, 10)); ok {
+		t.Errorf("found target accepting %q outside the target area", m)
+	}
+	if m, ok := tgtRouter.ExternalTarget(f32.Pt(10, 10)); !ok || m != "file" {
+		t.Errorf("got target type %q, %v; want file", m, ok)
+	}
+	assertEventSequence(t, tgtRouter.Events(tgt))
+	mime, ok := tgtRouter.ExternalDrop(f32.Pt(10, 10))
+	if !ok || mime != "file" {
+		t.Fatalf("got drop type %q, %v; want file", mime, ok)
+	}
+
+	// Drop outside the source router.
+	srcRouter.RedirectDrop(mime)
+	srcRouter.Queue(pointer.Event{Position: f32.Pt(100, 10), Kind: pointer.Release})
+	assertEventSequence(t, srcRouter.Events(src), transfer.RequestEvent{Type: "file"})
+	ofr := &offer{data: "hello"}
+	transfer.OfferOp{Tag: src, Type: "file", Data: ofr}.Add(srcOps)
+	srcRouter.Frame(srcOps)
+	assertEventSequence(t, srcRouter.Events(src), transfer.CancelEvent{})
+	data, ok := srcRouter.ExternalOffer()
+	if !ok || data != ofr {
+		t.Fatalf("got offer %v, %v; want %v", data, ok, ofr)
+	}
+
+	// Deliver to the target.
+	tgtRouter.ExternalData(mime, data)
+	evs := tgtRouter.Events(tgt)
+	if len(evs) != 1 {
+		t.Fatalf("unexpected number of events: %d, want 1", len(evs))
+	}
+	dataEvent, ok := evs[0].(transfer.DataEvent)
+	if !ok {
+		t.Fatalf("unexpected event type: %T, want %T", evs[0], transfer.DataEvent{})
+	}
+	if got := dataEvent.Open(); got != ofr {
+		t.Fatalf("got %v; want %v", got, ofr)
+	}
+	tgtRouter.Frame(tgtOps)
+	assertEventSequence(t, tgtRouter.Events(tgt), transfer.CancelEvent{})
+	if ofr.closed {
+		t.Error("offer closed prematurely")
+	}
+
+	// A cancelled drag forgets its redirection.
+	srcRouter.Queue(
+		pointer.Event{Position: f32.Pt(10, 10), Kind: pointer.Press},
+		pointer.Event{Position: f32.Pt(10, 10), Kind: pointer.Move},
+	)
+	srcRouter.RedirectDrop(mime)
+	srcRouter.Queue(cancel)
+	srcRouter.Frame(srcOps)
+	srcRouter.Events(src)
+	srcRouter.Queue(
+		pointer.Event{Position: f32.Pt(10, 10), Kind: pointer.Press},
+		pointer.Event{Position: f32.Pt(10, 10), Kind: pointer.Move},
+		pointer.Event{Position: f32.Pt(10, 10), Kind: pointer.Release},
+	)
+	// Without a local target, the drop is cancelled rather than
+	// requested.
+	assertEventSequence(t, srcRouter.Events(src), transfer.CancelEvent{})
+}
+
 func TestDeferredInputOp(t *testing.T) {
 	var ops op.Ops
 
@@ -1321,3 +1834,41 @@ func BenchmarkRouterAdd(b *testing.B) {
 		})
 	}
 }
+
+func TestAppendHits(t *testing.T) {
+	var ops op.Ops
+	h1, h2, h3 := new(int), new(int), new(int)
+	addPointerHandler(&ops, h1, image.Rect(0, 0, 100, 100))
+	addPointerHandler(&ops, h2, image.Rect(50, 0, 100, 100))
+	pass := pointer.PassOp{}.Push(&ops)
+	addPointerHandler(&ops, h3, image.Rect(0, 50, 100, 100))
+	pass.Pop()
+
+	var r Router
+	r.Frame(&ops)
+	// Drain the initial cancel events.
+	r.Events(h1)
+	tests := []struct {
+		pos  f32.Point
+		want []Hit
+	}{
+		{f32.Pt(10, 10), []Hit{{Tag: h1, Bounds: image.Rect(0, 0, 100, 100)}}},
+		{f32.Pt(60, 10), []Hit{{Tag: h2, Bounds: image.Rect(50, 0, 100, 100)}}},
+		// h3 passes through to h2, which hides h1.
+		{f32.Pt(60, 60), []Hit{
+			{Tag: h3, Bounds: image.Rect(0, 50, 100, 100)},
+			{Tag: h2, Bounds: image.Rect(50, 0, 100, 100)},
+		}},
+		{f32.Pt(200, 200), nil},
+	}
+	for _, tc := range tests {
+		got := r.AppendHits(nil, tc.pos)
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("hits at %v are %v, want %v", tc.pos, got, tc.want)
+		}
+	}
+	// Querying doesn't deliver events.
+	if evts := r.Events(h1); len(evts) != 0 {
+		t.Errorf("AppendHits delivered %v", evts)
+	}
+}
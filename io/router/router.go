@@ -15,6 +15,7 @@ import (
 	"image"
 	"io"
 	"math"
+	"sort"
 	"strings"
 	"time"
 
@@ -23,6 +24,7 @@ import (
 	"github.com/Seikaijyu/gio/internal/ops"
 	"github.com/Seikaijyu/gio/io/clipboard"
 	"github.com/Seikaijyu/gio/io/event"
+	"github.com/Seikaijyu/gio/io/gamepad"
 	"github.com/Seikaijyu/gio/io/key"
 	"github.com/Seikaijyu/gio/io/pointer"
 	"github.com/Seikaijyu/gio/io/profile"
@@ -46,6 +48,8 @@ type Router struct {
 		collector keyCollector
 	}
 	cqueue clipboardQueue
+	// pqueue is the clipboardQueue of the primary selection.
+	pqueue clipboardQueue
 
 	handlers handlerEvents
 
@@ -58,6 +62,34 @@ type Router struct {
 	// ProfileOp summary.
 	profHandlers map[event.Tag]struct{}
 	profile      profile.Event
+
+	// gamepad.InputOp handlers.
+	gamepadHandlers map[event.Tag]struct{}
+
+	// native.ViewOp placements.
+	nativeViews []NativeView
+}
+
+// NativeView is the placement of a native child view in a frame, in
+// window coordinates.
+type NativeView struct {
+	Handle uintptr
+	// Bounds is the area reserved for the view.
+	Bounds image.Rectangle
+	// Clip is the visible part of Bounds.
+	Clip image.Rectangle
+	// Order is the stacking order of the view.
+	Order int32
+}
+
+// Hit is a pointer handler under a position, as reported by
+// Router.AppendHits.
+type Hit struct {
+	// Tag is the tag of the handler.
+	Tag event.Tag
+	// Bounds is the bounding box of the clip area of the handler, in
+	// window coordinates.
+	Bounds image.Rectangle
 }
 
 // SemanticNode represents a node in the tree describing the components
@@ -72,6 +104,8 @@ type SemanticNode struct {
 }
 
 // SemanticDesc provides a semantic description of a UI component.
+// Controls such as buttons without a Label are labeled by the labels of
+// their descendants, such as the text of a button.
 type SemanticDesc struct {
 	Class       semantic.ClassOp
 	Description string
@@ -80,6 +114,13 @@ type SemanticDesc struct {
 	Disabled    bool
 	Gestures    SemanticGestures
 	Bounds      image.Rectangle
+	// Value is the text content of an editable component.
+	Value string
+	// Selection is the text selection of an editable component.
+	Selection semantic.SelectionOp
+	// Help is the help text of the component, as given by
+	// semantic.HelpOp.
+	Help string
 }
 
 // SemanticGestures is a bit-set of supported gestures.
@@ -110,6 +151,48 @@ func (q *Router) Events(k event.Tag) []event.Event {
 	return events
 }
 
+// EventSnapshot is a queue of events taken from a Router by
+// TakeEvents, for handling on another goroutine. It is not safe for
+// concurrent use.
+type EventSnapshot struct {
+	events map[event.Tag][]event.Event
+}
+
+// TakeEvents removes the available events of all handlers from the
+// router, and returns them in a snapshot.
+func (q *Router) TakeEvents() *EventSnapshot {
+	s := &EventSnapshot{events: make(map[event.Tag][]event.Event)}
+	for k, evts := range q.handlers.handlers {
+		if len(evts) > 0 {
+			s.events[k] = append([]event.Event(nil), evts...)
+			q.handlers.handlers[k] = evts[:0]
+		}
+	}
+	for k := range q.profHandlers {
+		delete(q.profHandlers, k)
+		s.events[k] = append(s.events[k], q.profile)
+	}
+	return s
+}
+
+// Events returns and removes the events of the handler k.
+func (s *EventSnapshot) Events(k event.Tag) []event.Event {
+	evts := s.events[k]
+	delete(s.events, k)
+	return evts
+}
+
+// Merge adds the remaining events of o after those of s, and empties o.
+func (s *EventSnapshot) Merge(o *EventSnapshot) {
+	if s.events == nil {
+		s.events = make(map[event.Tag][]event.Event)
+	}
+	for k, evts := range o.events {
+		s.events[k] = append(s.events[k], evts...)
+		delete(o.events, k)
+	}
+}
+
 // Frame replaces the declared handlers from the supplied
 // operation list. The text input state, wakeup time and whether
 // there are active profile handlers is also saved.
@@ -119,12 +202,18 @@ func (q *Router) Frame(frame *op.Ops) {
 	for k := range q.profHandlers {
 		delete(q.profHandlers, k)
 	}
+	for k := range q.gamepadHandlers {
+		delete(q.gamepadHandlers, k)
+	}
 	var ops *ops.Ops
 	if frame != nil {
 		ops = &frame.Internal
 	}
 	q.reader.Reset(ops)
 	q.collect()
+	sort.SliceStable(q.nativeViews, func(i, j int) bool {
+		return q.nativeViews[i].Order < q.nativeViews[j].Order
+	})
 
 	q.pointer.queue.Frame(&q.handlers)
 	q.key.queue.Frame(&q.handlers, q.key.collector)
@@ -176,12 +265,20 @@ func (q *Router) Queue(events ...event.Event) bool {
 			if f := q.key.queue.focus; f != nil {
 				q.handlers.Add(f, e)
 			}
-		case key.EditEvent, key.FocusEvent, key.SelectionEvent:
+		case key.EditEvent, key.FocusEvent, key.SelectionEvent, key.CompositionEvent:
 			if f := q.key.queue.focus; f != nil {
 				q.handlers.Add(f, e)
 			}
 		case clipboard.Event:
-			q.cqueue.Push(e, &q.handlers)
+			if e.Primary {
+				q.pqueue.Push(e, &q.handlers)
+			} else {
+				q.cqueue.Push(e, &q.handlers)
+			}
+		case gamepad.Event:
+			for k := range q.gamepadHandlers {
+				q.handlers.Add(k, e)
+			}
 		}
 	}
 	return q.handlers.HadEvents()
@@ -229,9 +326,12 @@ func (q *Router) queueKeyEvent(e key.Event) {
 		}
 		if kq.Accepts(n.ktag, e) {
 			q.handlers.Add(n.ktag, e)
-			break
+			return
 		}
 	}
+	if tag, ok := kq.Shortcut(e); ok {
+		q.handlers.Add(tag, e)
+	}
 }
 
 func (q *Router) MoveFocus(dir FocusDirection) bool {
@@ -323,6 +423,114 @@ func (q *Router) ClickFocus() {
 	q.pointer.queue.Deliver(area, e, &q.handlers)
 }
 
+// ClickSemantic delivers a click to the center of the semantic node
+// id, for example on behalf of a screen reader. It reports whether
+// the node exists.
+func (q *Router) ClickSemantic(id SemanticID) bool {
+	pq := &q.pointer.queue
+	area, ok := pq.semanticArea(id)
+	if !ok {
+		return false
+	}
+	bounds := pq.areas[area].bounds()
+	center := bounds.Max.Add(bounds.Min).Div(2)
+	e := pointer.Event{
+		Position: f32.Pt(float32(center.X), float32(center.Y)),
+		Source:   pointer.Touch,
+	}
+	e.Kind = pointer.Press
+	pq.Deliver(area, e, &q.handlers)
+	e.Kind = pointer.Release
+	pq.Deliver(area, e, &q.handlers)
+	return true
+}
+
+// FocusSemantic moves the keyboard focus to the first key handler
+// contained in the semantic node id, and reports whether it succeeded.
+func (q *Router) FocusSemantic(id SemanticID) bool {
+	pq := &q.pointer.queue
+	area, ok := pq.semanticArea(id)
+	if !ok {
+		return false
+	}
+	tag, ok := pq.keyHandlerIn(area)
+	if !ok {
+		return false
+	}
+	q.key.queue.setFocus(tag, &q.handlers)
+	return true
+}
+
+// SemanticFocus returns the ID of the semantic node that contains the
+// key handler with keyboard focus, if any.
+func (q *Router) SemanticFocus() (SemanticID, bool) {
+	focus := q.key.queue.focus
+	if focus == nil {
+		return 0, false
+	}
+	return q.pointer.queue.semanticFor(q.key.queue.AreaFor(focus))
+}
+
+// DragSource returns the MIME types offered by the data source of
+// the active drag-and-drop gesture, if any. Together with RedirectDrop,
+// ExternalOffer and the ExternalDrag methods it allows transfers
+// between routers, such as between windows of the same program.
+func (q *Router) DragSource() ([]string, bool) {
+	return q.pointer.queue.DragSource()
+}
+
+// RedirectDrop sends the next drop of the active drag to a target
+// outside the router, such as a window other than the one where the
+// drag started. Upon drop, local targets are cancelled and the source
+// receives a RequestEvent for mime. Its offered data is then available
+// from ExternalOffer.
+func (q *Router) RedirectDrop(mime string) {
+	q.pointer.queue.RedirectDrop(mime)
+}
+
+// ExternalOffer returns the data offered in response to a drop
+// redirected by RedirectDrop, once the source has offered it. The
+// caller must close the data after use, typically by passing it
+// to ExternalData of the receiving router.
+func (q *Router) ExternalOffer() (io.ReadCloser, bool) {
+	return q.pointer.queue.ExternalOffer()
+}
+
+// ExternalDrag reports a drag-and-drop gesture entering the router
+// from the outside, offering data of the mimes types. Potential
+// targets receive an InitiateEvent.
+func (q *Router) ExternalDrag(mimes []string) {
+	q.pointer.queue.ExternalDrag(mimes, &q.handlers)
+}
+
+// CancelExternalDrag cancels the gesture reported by ExternalDrag, for
+// example when it leaves the router again.
+func (q *Router) CancelExternalDrag() {
+	q.pointer.queue.CancelExternalDrag(&q.handlers)
+}
+
+// ExternalTarget returns the type accepted by the target under pos for
+// the gesture reported by ExternalDrag, without dropping it. It is
+// suitable for choosing the type to request with RedirectDrop while
+// the gesture moves over the router.
+func (q *Router) ExternalTarget(pos f32.Point) (string, bool) {
+	return q.pointer.queue.ExternalTarget(pos)
+}
+
+// ExternalDrop drops the gesture reported by ExternalDrag at pos and
+// returns the type accepted by the target under pos. If there is no
+// such target, the gesture is cancelled. Otherwise, the data must be
+// delivered with ExternalData.
+func (q *Router) ExternalDrop(pos f32.Point) (string, bool) {
+	return q.pointer.queue.ExternalDrop(pos, &q.handlers)
+}
+
+// ExternalData delivers the data of a successful ExternalDrop to its
+// target in a transfer.DataEvent.
+func (q *Router) ExternalData(mime string, data io.ReadCloser) {
+	q.pointer.queue.ExternalData(mime, data, &q.handlers)
+}
+
 // TextInputState returns the input state from the most recent
 // call to Frame.
 func (q *Router) TextInputState() TextInputState {
@@ -346,9 +554,37 @@ func (q *Router) ReadClipboard() bool {
 	return q.cqueue.ReadClipboard()
 }
 
+// ClipboardContent returns the formats other than text of the text
+// most recently returned by WriteClipboard.
+func (q *Router) ClipboardContent() []clipboard.Content {
+	return q.cqueue.Content()
+}
+
+// ClipboardTypes returns the MIME types of the formats requested, in
+// addition to text, by the handlers waiting to read the clipboard.
+func (q *Router) ClipboardTypes() []string {
+	return q.cqueue.Types()
+}
+
+// WritePrimary is like WriteClipboard for the primary selection.
+func (q *Router) WritePrimary() (string, bool) {
+	return q.pqueue.WriteClipboard()
+}
+
+// ReadPrimary is like ReadClipboard for the primary selection.
+func (q *Router) ReadPrimary() bool {
+	return q.pqueue.ReadClipboard()
+}
+
 // Cursor returns the last cursor set.
 func (q *Router) Cursor() pointer.Cursor {
-	return q.pointer.queue.cursor
+	return q.pointer.queue.cursor.name
+}
+
+// ImageCursor returns the last cursor set, if it is an image. It
+// takes precedence over Cursor.
+func (q *Router) ImageCursor() *pointer.ImageCursor {
+	return q.pointer.queue.cursor.image
 }
 
 // SemanticAt returns the first semantic description under pos, if any.
@@ -356,6 +592,21 @@ func (q *Router) SemanticAt(pos f32.Point) (SemanticID, bool) {
 	return q.pointer.queue.SemanticAt(pos)
 }
 
+// HelpAt returns the help text of the foremost component under pos
+// that has help text, along with the bounds of the component.
+func (q *Router) HelpAt(pos f32.Point) (string, image.Rectangle, bool) {
+	return q.pointer.queue.HelpAt(pos)
+}
+
+// AppendHits appends the pointer handlers under pos to hits, foremost
+// first, and returns the result. The handlers are the ones that would
+// receive a pointer event at pos, in the order they would receive it,
+// as of the most recent frame. AppendHits doesn't deliver events, so it
+// is suitable for tooltips, inspectors and custom hover logic.
+func (q *Router) AppendHits(hits []Hit, pos f32.Point) []Hit {
+	return q.pointer.queue.AppendHits(hits, pos)
+}
+
 // AppendSemantics appends the semantic tree to nodes, and returns the result.
 // The root node is the first added.
 func (q *Router) AppendSemantics(nodes []SemanticNode) []SemanticNode {
@@ -378,6 +629,7 @@ func (q *Router) collect() {
 	kc := &q.key.collector
 	*kc = keyCollector{q: &q.key.queue}
 	q.key.queue.Reset()
+	q.nativeViews = q.nativeViews[:0]
 	var t f32.Affine2D
 	bo := binary.LittleEndian
 	for encOp, ok := q.reader.Decode(); ok; encOp, ok = q.reader.Decode() {
@@ -394,10 +646,28 @@ func (q *Router) collect() {
 				q.profHandlers = make(map[event.Tag]struct{})
 			}
 			q.profHandlers[op.Tag] = struct{}{}
+		case ops.TypeGamepadInput:
+			if q.gamepadHandlers == nil {
+				q.gamepadHandlers = make(map[event.Tag]struct{})
+			}
+			q.gamepadHandlers[encOp.Refs[0].(event.Tag)] = struct{}{}
+		case ops.TypeNativeView:
+			handle := uintptr(bo.Uint64(encOp.Data[1:]))
+			v := pc.nativeView(handle)
+			v.Order = int32(bo.Uint32(encOp.Data[9:]))
+			q.nativeViews = append(q.nativeViews, v)
 		case ops.TypeClipboardRead:
-			q.cqueue.ProcessReadClipboard(encOp.Refs)
+			if encOp.Data[1] != 0 {
+				q.pqueue.ProcessReadClipboard(encOp.Refs)
+			} else {
+				q.cqueue.ProcessReadClipboard(encOp.Refs)
+			}
 		case ops.TypeClipboardWrite:
-			q.cqueue.ProcessWriteClipboard(encOp.Refs)
+			if encOp.Data[1] != 0 {
+				q.pqueue.ProcessWriteClipboard(encOp.Refs)
+			} else {
+				q.cqueue.ProcessWriteClipboard(encOp.Refs)
+			}
 		case ops.TypeSave:
 			id := ops.DecodeSave(encOp.Data)
 			if extra := id - len(q.savedTrans) + 1; extra > 0 {
@@ -437,7 +707,8 @@ func (q *Router) collect() {
 		case ops.TypePointerInput:
 			op := pointer.InputOp{
 				Tag:   encOp.Refs[0].(event.Tag),
-				Grab:  encOp.Data[1] != 0,
+				Grab:  encOp.Data[1]&1 != 0,
+				Deny:  encOp.Data[1]&2 != 0,
 				Kinds: pointer.Kind(bo.Uint16(encOp.Data[2:])),
 				ScrollBounds: image.Rectangle{
 					Min: image.Point{
@@ -449,11 +720,16 @@ func (q *Router) collect() {
 						Y: int(int32(bo.Uint32(encOp.Data[16:]))),
 					},
 				},
+				Precedence: int32(bo.Uint32(encOp.Data[20:])),
+				Buttons:    pointer.Buttons(encOp.Data[24]),
 			}
 			pc.inputOp(op, &q.handlers)
 		case ops.TypeCursor:
 			name := pointer.Cursor(encOp.Data[1])
-			pc.cursor(name)
+			pc.cursor(areaCursor{name: name})
+		case ops.TypeImageCursor:
+			img := encOp.Refs[0].(*pointer.ImageCursor)
+			pc.cursor(areaCursor{image: img})
 		case ops.TypeSource:
 			op := transfer.SourceOp{
 				Tag:  encOp.Refs[0].(event.Tag),
@@ -484,6 +760,10 @@ func (q *Router) collect() {
 				Tag: tag,
 			}
 			kc.focusOp(op.Tag)
+		case ops.TypeFocusScope:
+			kc.pushFocusScope(encOp.Refs[0].(event.Tag))
+		case ops.TypePopFocusScope:
+			kc.popFocusScope()
 		case ops.TypeKeySoftKeyboard:
 			op := key.SoftKeyboardOp{
 				Show: encOp.Data[1] != 0,
@@ -492,14 +772,20 @@ func (q *Router) collect() {
 		case ops.TypeKeyInput:
 			filter := key.Set(*encOp.Refs[1].(*string))
 			op := key.InputOp{
-				Tag:  encOp.Refs[0].(event.Tag),
-				Hint: key.InputHint(encOp.Data[1]),
-				Keys: filter,
+				Tag:    encOp.Refs[0].(event.Tag),
+				Hint:   key.InputHint(encOp.Data[1]),
+				Keys:   filter,
+				States: key.StateFilter(encOp.Data[2]),
 			}
 			a := pc.currentArea()
 			b := pc.currentAreaBounds()
 			pc.keyInputOp(op)
 			kc.inputOp(op, a, b)
+		case ops.TypeKeyShortcut:
+			kc.shortcutOp(key.ShortcutOp{
+				Tag:  encOp.Refs[0].(event.Tag),
+				Keys: key.Set(*encOp.Refs[1].(*string)),
+			})
 		case ops.TypeSnippet:
 			op := key.SnippetOp{
 				Tag: encOp.Refs[0].(event.Tag),
@@ -537,6 +823,9 @@ func (q *Router) collect() {
 		case ops.TypeSemanticDesc:
 			desc := *encOp.Refs[0].(*string)
 			pc.semanticDesc(desc)
+		case ops.TypeSemanticHelp:
+			help := *encOp.Refs[0].(*string)
+			pc.semanticHelp(help)
 		case ops.TypeSemanticClass:
 			class := semantic.ClassOp(encOp.Data[1])
 			pc.semanticClass(class)
@@ -552,10 +841,31 @@ func (q *Router) collect() {
 			} else {
 				pc.semanticEnabled(false)
 			}
+		case ops.TypeSemanticValue:
+			val := *encOp.Refs[0].(*string)
+			pc.semanticValue(val)
+		case ops.TypeSemanticRange:
+			sel := semantic.SelectionOp{
+				Start: int(int32(bo.Uint32(encOp.Data[1:]))),
+				End:   int(int32(bo.Uint32(encOp.Data[5:]))),
+			}
+			pc.semanticSelection(sel)
 		}
 	}
 }
 
+// GamepadInput reports whether there were gamepad handlers in the
+// most recent frame.
+func (q *Router) GamepadInput() bool {
+	return len(q.gamepadHandlers) > 0
+}
+
+// NativeViews returns the native views placed in the most recent
+// frame, from the bottom to the top of the stack.
+func (q *Router) NativeViews() []NativeView {
+	return q.nativeViews
+}
+
 // Profiling reports whether there was profile handlers in the
 // most recent Frame call.
 func (q *Router) Profiling() bool {
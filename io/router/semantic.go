@@ -0,0 +1,85 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package router
+
+// SemanticChange describes the change of a node between two semantic
+// trees.
+type SemanticChange struct {
+	Kind SemanticChangeKind
+	// Node is the node in the new tree, or in the old tree for
+	// SemanticRemoved.
+	Node SemanticNode
+}
+
+// SemanticChangeKind is the kind of a SemanticChange.
+type SemanticChangeKind uint8
+
+const (
+	// SemanticAdded is reported for nodes only in the new tree.
+	SemanticAdded SemanticChangeKind = iota
+	// SemanticRemoved is reported for nodes only in the old tree.
+	SemanticRemoved
+	// SemanticChanged is reported for nodes in both trees whose
+	// description, parent or children differ.
+	SemanticChanged
+)
+
+// AppendSemanticChanges appends the changes from the semantic tree prev
+// to the semantic tree next, as returned by AppendSemantics, to changes
+// and returns the result. Bridges to accessibility hosts can forward the
+// changes instead of the whole tree each frame.
+//
+// The added and changed nodes are reported in the order of next, the
+// removed nodes in the order of prev, after the others. Since the
+// children of a node are part of its state, a node whose children were
+// added or removed is reported as changed.
+func AppendSemanticChanges(changes []SemanticChange, prev, next []SemanticNode) []SemanticChange {
+	old := make(map[SemanticID]int, len(prev))
+	for i, n := range prev {
+		old[n.ID] = i
+	}
+	for _, n := range next {
+		i, ok := old[n.ID]
+		if !ok {
+			changes = append(changes, SemanticChange{Kind: SemanticAdded, Node: n})
+			continue
+		}
+		delete(old, n.ID)
+		if semanticNodeChanged(prev[i], n) {
+			changes = append(changes, SemanticChange{Kind: SemanticChanged, Node: n})
+		}
+	}
+	for _, n := range prev {
+		if _, removed := old[n.ID]; removed {
+			changes = append(changes, SemanticChange{Kind: SemanticRemoved, Node: n})
+		}
+	}
+	return changes
+}
+
+// semanticNodeChanged reports whether the state of a node differs
+// between its versions o and n.
+func semanticNodeChanged(o, n SemanticNode) bool {
+	if o.Desc != n.Desc || o.ParentID != n.ParentID || len(o.Children) != len(n.Children) {
+		return true
+	}
+	for i, ch := range o.Children {
+		if ch.ID != n.Children[i].ID {
+			return true
+		}
+	}
+	return false
+}
+
+func (k SemanticChangeKind) String() string {
+	switch k {
+	case SemanticAdded:
+		return "SemanticAdded"
+	case SemanticRemoved:
+		return "SemanticRemoved"
+	case SemanticChanged:
+		return "SemanticChanged"
+	default:
+		panic("invalid SemanticChangeKind")
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/Seikaijyu/gio/f32"
+	"github.com/Seikaijyu/gio/io/key"
 	"github.com/Seikaijyu/gio/io/pointer"
 	"github.com/Seikaijyu/gio/io/semantic"
 	"github.com/Seikaijyu/gio/op"
@@ -98,6 +99,152 @@ func TestSemanticDescription(t *testing.T) {
 	}
 }
 
+func TestSemanticHelp(t *testing.T) {
+	var (
+		ops op.Ops
+		r   Router
+	)
+	outer := clip.Rect(image.Rect(0, 0, 100, 100)).Push(&ops)
+	semantic.HelpOp("outer help").Add(&ops)
+	inner := clip.Rect(image.Rect(10, 10, 50, 50)).Push(&ops)
+	semantic.Button.Add(&ops)
+	semantic.HelpOp("inner help").Add(&ops)
+	inner.Pop()
+	plain := clip.Rect(image.Rect(60, 60, 90, 90)).Push(&ops)
+	semantic.Button.Add(&ops)
+	plain.Pop()
+	outer.Pop()
+	r.Frame(&ops)
+	tests := []struct {
+		x, y   float32
+		help   string
+		bounds image.Rectangle
+	}{
+		{20, 20, "inner help", image.Rect(10, 10, 50, 50)},
+		{70, 70, "outer help", image.Rect(0, 0, 100, 100)},
+		{150, 150, "", image.Rectangle{}},
+	}
+	for _, tc := range tests {
+		help, bounds, _ := r.HelpAt(f32.Pt(tc.x, tc.y))
+		if help != tc.help || bounds != tc.bounds {
+			t.Errorf("help at (%v,%v) is %q in %v, want %q in %v", tc.x, tc.y, help, bounds, tc.help, tc.bounds)
+		}
+	}
+	tree := r.AppendSemantics(nil)
+	if got := tree[0].Children[0].Children[0].Desc.Help; got != "inner help" {
+		t.Errorf("got semantic help %q, want %q", got, "inner help")
+	}
+}
+
+func TestSemanticDerivedLabel(t *testing.T) {
+	var (
+		ops op.Ops
+		r   Router
+	)
+	// A button labeled by its content.
+	btn := clip.Rect(image.Rect(0, 0, 100, 50)).Push(&ops)
+	pointer.InputOp{Tag: new(int), Kinds: pointer.Press | pointer.Release}.Add(&ops)
+	icon := clip.Rect(image.Rect(0, 0, 20, 50)).Push(&ops)
+	icon.Pop()
+	lbl := clip.Rect(image.Rect(20, 0, 60, 50)).Push(&ops)
+	semantic.LabelOp("Save").Add(&ops)
+	lbl.Pop()
+	lbl = clip.Rect(image.Rect(60, 0, 100, 50)).Push(&ops)
+	semantic.LabelOp("all").Add(&ops)
+	lbl.Pop()
+	btn.Pop()
+	// A button with its own label.
+	btn = clip.Rect(image.Rect(0, 50, 100, 100)).Push(&ops)
+	semantic.Button.Add(&ops)
+	semantic.LabelOp("Close").Add(&ops)
+	lbl = clip.Rect(image.Rect(0, 50, 100, 100)).Push(&ops)
+	semantic.LabelOp("X").Add(&ops)
+	lbl.Pop()
+	btn.Pop()
+	// Plain containers are not labeled.
+	box := clip.Rect(image.Rect(0, 100, 100, 150)).Push(&ops)
+	semantic.DescriptionOp("box").Add(&ops)
+	lbl = clip.Rect(image.Rect(0, 100, 100, 150)).Push(&ops)
+	semantic.LabelOp("text").Add(&ops)
+	lbl.Pop()
+	box.Pop()
+	r.Frame(&ops)
+	tree := r.AppendSemantics(nil)
+	children := tree[0].Children
+	if len(children) != 3 {
+		t.Fatalf("got %d nodes, want 3", len(children))
+	}
+	for i, want := range []string{"Save all", "Close", ""} {
+		if got := children[i].Desc.Label; got != want {
+			t.Errorf("node %d labeled %q, want %q", i, got, want)
+		}
+	}
+}
+
+func TestSemanticEditorValue(t *testing.T) {
+	frame := func(r *Router, value string, sel semantic.SelectionOp) SemanticNode {
+		var ops op.Ops
+		cl := clip.Rect(image.Rect(0, 0, 100, 20)).Push(&ops)
+		semantic.Editor.Add(&ops)
+		semantic.ValueOp(value).Add(&ops)
+		sel.Add(&ops)
+		cl.Pop()
+		r.Frame(&ops)
+		tree := r.AppendSemantics(nil)
+		return tree[0].Children[0]
+	}
+	var r Router
+	n1 := frame(&r, "hello", semantic.SelectionOp{Start: 1, End: 4})
+	if got, want := n1.Desc.Value, "hello"; got != want {
+		t.Errorf("got value %q, want %q", got, want)
+	}
+	if got, want := n1.Desc.Selection, (semantic.SelectionOp{Start: 1, End: 4}); got != want {
+		t.Errorf("got selection %+v, want %+v", got, want)
+	}
+	n2 := frame(&r, "hello, world", semantic.SelectionOp{Start: 12, End: 12})
+	if n1.ID != n2.ID {
+		t.Errorf("editing changed the semantic ID from %d to %d", n1.ID, n2.ID)
+	}
+	if got, want := n2.Desc.Selection, (semantic.SelectionOp{Start: 12, End: 12}); got != want {
+		t.Errorf("got selection %+v, want %+v", got, want)
+	}
+}
+
+func TestSemanticChanges(t *testing.T) {
+	var r Router
+	frame := func(nodes ...string) []SemanticNode {
+		var ops op.Ops
+		for i, desc := range nodes {
+			if desc == "" {
+				continue
+			}
+			x := i * 10
+			cl := clip.Rect(image.Rect(x, 0, x+10, 10)).Push(&ops)
+			semantic.DescriptionOp(desc).Add(&ops)
+			cl.Pop()
+		}
+		r.Frame(&ops)
+		return r.AppendSemantics(nil)
+	}
+	prev := frame("a", "b")
+	next := frame("a", "", "c")
+	var got []string
+	for _, c := range AppendSemanticChanges(nil, prev, next) {
+		got = append(got, fmt.Sprintf("%v %q", c.Kind, c.Node.Desc.Description))
+	}
+	want := []string{
+		`SemanticChanged ""`,
+		`SemanticAdded "c"`,
+		`SemanticRemoved "b"`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got changes %q, want %q", got, want)
+	}
+	if c := AppendSemanticChanges(nil, next, next); len(c) != 0 {
+		t.Errorf("got changes %v between identical trees", c)
+	}
+}
+
 func lookupNode(tree []SemanticNode, id SemanticID) (SemanticNode, bool) {
 	for _, n := range tree {
 		if id == n.ID {
@@ -126,3 +273,41 @@ func printTree(indent int, n SemanticNode) {
 		printTree(indent+1, c)
 	}
 }
+
+func TestSemanticActions(t *testing.T) {
+	var (
+		ops op.Ops
+		r   Router
+	)
+	btn, field := new(int), new(int)
+	cl := clip.Rect(image.Rect(0, 0, 50, 50)).Push(&ops)
+	semantic.Button.Add(&ops)
+	pointer.InputOp{Tag: btn, Kinds: pointer.Press | pointer.Release}.Add(&ops)
+	cl.Pop()
+	cl = clip.Rect(image.Rect(50, 0, 100, 50)).Push(&ops)
+	semantic.Editor.Add(&ops)
+	key.InputOp{Tag: field}.Add(&ops)
+	cl.Pop()
+	r.Frame(&ops)
+	tree := r.AppendSemantics(nil)
+	if len(tree) != 3 {
+		t.Fatalf("expected 3 semantic nodes, got %d", len(tree))
+	}
+	btnID, fieldID := tree[1].ID, tree[2].ID
+
+	r.Events(btn)
+	if !r.ClickSemantic(btnID) {
+		t.Fatal("ClickSemantic failed")
+	}
+	assertEventPointerTypeSequence(t, r.Events(btn), pointer.Press, pointer.Release)
+
+	if r.FocusSemantic(btnID) {
+		t.Error("FocusSemantic succeeded for a node without key handlers")
+	}
+	if !r.FocusSemantic(fieldID) {
+		t.Fatal("FocusSemantic failed")
+	}
+	if id, ok := r.SemanticFocus(); !ok || id != fieldID {
+		t.Errorf("got semantic focus %d, want %d", id, fieldID)
+	}
+}
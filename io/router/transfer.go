@@ -0,0 +1,186 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package router
+
+import (
+	"io"
+
+	"github.com/Seikaijyu/gio/f32"
+	"github.com/Seikaijyu/gio/io/event"
+	"github.com/Seikaijyu/gio/io/transfer"
+)
+
+// externalTransfer tracks drag-and-drop transfers between a router and
+// the outside world, such as another window of the same program.
+type externalTransfer struct {
+	// redirect is the MIME type to request from the source of the
+	// active drag when it is dropped, as set by RedirectDrop.
+	redirect string
+	// source is the source asked to offer data for an outgoing drop.
+	source event.Tag
+	// offer is the data offered for an outgoing drop.
+	offer io.ReadCloser
+
+	// mimes are the types offered by an incoming drag, or nil if
+	// there is none.
+	mimes []string
+	// target is the target of an incoming drop.
+	target event.Tag
+	// done is set when the data of an incoming drop has been
+	// delivered.
+	done bool
+}
+
+// DragSource returns the MIME types offered by the source of the
+// active drag-and-drop gesture, if any.
+func (q *pointerQueue) DragSource() ([]string, bool) {
+	for _, p := range q.pointers {
+		if p.dataSource != nil {
+			return q.handlers[p.dataSource].sourceMimes, true
+		}
+	}
+	return nil, false
+}
+
+// RedirectDrop sends the next drop of the active drag to a target
+// outside the router.
+func (q *pointerQueue) RedirectDrop(mime string) {
+	q.external.redirect = mime
+}
+
+// redirectDrop cancels the local targets of the drag by p and requests
+// its data on behalf of an external target.
+func (q *pointerQueue) redirectDrop(p *pointerInfo, events *handlerEvents) {
+	mime := q.external.redirect
+	q.external.redirect = ""
+	src := q.handlers[p.dataSource]
+	for k, h := range q.handlers {
+		if k == p.dataSource {
+			continue
+		}
+		if _, ok := firstMimeMatch(src, h); ok {
+			events.Add(k, transfer.CancelEvent{})
+		}
+	}
+	q.external.source = p.dataSource
+	events.Add(p.dataSource, transfer.RequestEvent{Type: mime})
+	p.dataSource = nil
+	p.dataTarget = nil
+}
+
+// ExternalOffer returns the data offered by the source of a redirected
+// drop. The caller is responsible for closing it.
+func (q *pointerQueue) ExternalOffer() (io.ReadCloser, bool) {
+	data := q.external.offer
+	q.external.offer = nil
+	return data, data != nil
+}
+
+// ExternalDrag notifies the potential targets of an incoming drag
+// offering mimes.
+func (q *pointerQueue) ExternalDrag(mimes []string, events *handlerEvents) {
+	if q.external.mimes != nil {
+		return
+	}
+	q.external.mimes = append([]string{}, mimes...)
+	for k, h := range q.handlers {
+		if _, ok := firstMatch(mimes, h.targetMimes); ok {
+			events.Add(k, transfer.InitiateEvent{})
+		}
+	}
+}
+
+// CancelExternalDrag cancels the incoming drag, if any.
+func (q *pointerQueue) CancelExternalDrag(events *handlerEvents) {
+	if q.external.mimes == nil {
+		return
+	}
+	for k, h := range q.handlers {
+		if _, ok := firstMatch(q.external.mimes, h.targetMimes); ok {
+			events.Add(k, transfer.CancelEvent{})
+		}
+	}
+	q.external.mimes = nil
+	q.external.target = nil
+	q.external.done = false
+}
+
+// externalTarget returns the foremost target under pos for the
+// incoming drag, and the type it accepts.
+func (q *pointerQueue) externalTarget(pos f32.Point) (event.Tag, string, bool) {
+	if q.external.mimes == nil {
+		return nil, "", false
+	}
+	hits, _ := q.opHit(pos)
+	for _, k := range hits {
+		if m, ok := firstMatch(q.external.mimes, q.handlers[k].targetMimes); ok {
+			return k, m, true
+		}
+	}
+	return nil, "", false
+}
+
+// ExternalTarget returns the type accepted by the target under pos for
+// the incoming drag, without dropping it.
+func (q *pointerQueue) ExternalTarget(pos f32.Point) (string, bool) {
+	_, m, ok := q.externalTarget(pos)
+	return m, ok
+}
+
+// ExternalDrop drops the incoming drag at pos, and returns the type
+// accepted by the target under pos. The drag is cancelled if there is
+// no such target.
+func (q *pointerQueue) ExternalDrop(pos f32.Point, events *handlerEvents) (string, bool) {
+	k, m, ok := q.externalTarget(pos)
+	if !ok {
+		q.CancelExternalDrag(events)
+		return "", false
+	}
+	q.external.target = k
+	return m, true
+}
+
+// ExternalData delivers the data of an incoming drop to its target.
+func (q *pointerQueue) ExternalData(mime string, data io.ReadCloser, events *handlerEvents) {
+	tgt := q.external.target
+	if _, exists := q.handlers[tgt]; !exists {
+		data.Close()
+		q.CancelExternalDrag(events)
+		return
+	}
+	transferIdx := len(q.transfers)
+	events.Add(tgt, transfer.DataEvent{
+		Type: mime,
+		Open: func() io.ReadCloser {
+			q.transfers[transferIdx] = nil
+			return data
+		},
+	})
+	q.transfers = append(q.transfers, data)
+	q.external.target = nil
+	q.external.done = true
+}
+
+// frameExternal completes external transfers after a frame.
+func (q *pointerQueue) frameExternal(events *handlerEvents) {
+	if q.external.done {
+		q.CancelExternalDrag(events)
+	}
+	src, ok := q.handlers[q.external.source]
+	if !ok {
+		q.external.source = nil
+		return
+	}
+	if src.data == nil {
+		// Data not received yet.
+		return
+	}
+	if q.external.offer != nil {
+		q.external.offer.Close()
+	}
+	q.external.offer = src.data
+	events.Add(q.external.source, transfer.CancelEvent{})
+	src.offeredMime = ""
+	src.data = nil
+	q.external.source = nil
+}
@@ -10,6 +10,8 @@
 package semantic
 
 import (
+	"encoding/binary"
+
 	"github.com/Seikaijyu/gio/internal/ops"
 	"github.com/Seikaijyu/gio/op"
 )
@@ -20,6 +22,12 @@ type LabelOp string
 // DescriptionOp describes a component.
 type DescriptionOp string
 
+// HelpOp provides short help text for a component, such as what a
+// button does. The text is shown in a tooltip by widget.Tooltips when
+// the pointer rests on the component, and is available to accessibility services as its
+// description, so a single declaration serves both.
+type HelpOp string
+
 // ClassOp provides the component class.
 type ClassOp int
 
@@ -39,6 +47,18 @@ type SelectedOp bool
 // EnabledOp describes the enabled state.
 type EnabledOp bool
 
+// ValueOp provides the text content of an editable component, such as
+// an Editor.
+type ValueOp string
+
+// SelectionOp describes the text selection of an editable component.
+// Start and End are in runes, and End is the position of the caret.
+// Start may be greater than End, and the selection is empty when they
+// are equal.
+type SelectionOp struct {
+	Start, End int
+}
+
 func (l LabelOp) Add(o *op.Ops) {
 	data := ops.Write1String(&o.Internal, ops.TypeSemanticLabelLen, string(l))
 	data[0] = byte(ops.TypeSemanticLabel)
@@ -49,6 +69,11 @@ func (d DescriptionOp) Add(o *op.Ops) {
 	data[0] = byte(ops.TypeSemanticDesc)
 }
 
+func (h HelpOp) Add(o *op.Ops) {
+	data := ops.Write1String(&o.Internal, ops.TypeSemanticHelpLen, string(h))
+	data[0] = byte(ops.TypeSemanticHelp)
+}
+
 func (c ClassOp) Add(o *op.Ops) {
 	data := ops.Write(&o.Internal, ops.TypeSemanticClassLen)
 	data[0] = byte(ops.TypeSemanticClass)
@@ -71,6 +96,19 @@ func (e EnabledOp) Add(o *op.Ops) {
 	}
 }
 
+func (v ValueOp) Add(o *op.Ops) {
+	data := ops.Write1String(&o.Internal, ops.TypeSemanticValueLen, string(v))
+	data[0] = byte(ops.TypeSemanticValue)
+}
+
+func (s SelectionOp) Add(o *op.Ops) {
+	data := ops.Write(&o.Internal, ops.TypeSemanticRangeLen)
+	data[0] = byte(ops.TypeSemanticRange)
+	bo := binary.LittleEndian
+	bo.PutUint32(data[1:], uint32(s.Start))
+	bo.PutUint32(data[5:], uint32(s.End))
+}
+
 func (c ClassOp) String() string {
 	switch c {
 	case Unknown:
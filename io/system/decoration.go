@@ -38,6 +38,23 @@ const (
 	ActionClose
 	// ActionMove moves a window directed by the user.
 	ActionMove
+	// ActionRequestAttention notifies the user that a window in the
+	// background needs attention, by flashing its taskbar entry on
+	// Windows, bouncing the dock icon on macOS and setting the
+	// urgency hint on X11. On Wayland, which has no urgency hint,
+	// it requests activation through the xdg-activation protocol;
+	// most compositors mark the window as demanding attention
+	// rather than activating it. The notification stops when the
+	// window is activated. It does nothing if the window is
+	// focused.
+	// Only applicable on macOS, Windows, X11 and Wayland.
+	ActionRequestAttention
+	// ActionFocus activates the window and gives it keyboard focus,
+	// including windows created with the NoActivate option. Like
+	// ActionRaise, some platforms only allow it while another window
+	// of the application is active.
+	// Only applicable on macOS, Windows, X11, Wayland and js.
+	ActionFocus
 )
 
 func (op ActionInputOp) Add(o *op.Ops) {
@@ -72,6 +89,10 @@ func (a Action) string() string {
 		return "ActionClose"
 	case ActionMove:
 		return "ActionMove"
+	case ActionRequestAttention:
+		return "ActionRequestAttention"
+	case ActionFocus:
+		return "ActionFocus"
 	}
 	return ""
 }
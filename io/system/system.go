@@ -20,12 +20,25 @@ type FrameEvent struct {
 	// Now is the current animation. Use Now instead of time.Now to
 	// synchronize animation and to avoid the time.Now call overhead.
 	Now time.Time
+	// Elapsed is the animation time since the previous FrameEvent, or
+	// zero for the first FrameEvent.
+	Elapsed time.Duration
+	// RefreshInterval is the time between refreshes of the display
+	// showing the window, or zero if unknown. Animations that step
+	// once per frame should scale their steps by Elapsed or use
+	// RefreshInterval to run at the same speed on every display.
+	RefreshInterval time.Duration
 	// Metric converts device independent dp and sp to device pixels.
 	Metric unit.Metric
 	// Size is the dimensions of the window.
 	Size image.Point
 	// Insets represent the space occupied by system decorations and controls.
 	Insets Insets
+	// Locale is the language and text direction of the system, if
+	// known.
+	Locale Locale
+	// Gestures are the gesture settings of the platform.
+	Gestures Gestures
 	// Frame completes the FrameEvent by drawing the graphical operations
 	// from ops into the window.
 	Frame func(frame *op.Ops)
@@ -33,6 +46,18 @@ type FrameEvent struct {
 	Queue event.Queue
 }
 
+// Gestures are the gesture settings chosen by the user of the
+// platform. Zero fields are unknown, and select the defaults of package
+// gesture.
+type Gestures struct {
+	// DoubleClick is the maximum interval between the clicks of a
+	// double click.
+	DoubleClick time.Duration
+	// DragThreshold is how far a mouse pointer must move with a button
+	// pressed before a drag starts. It doesn't apply to touch input.
+	DragThreshold unit.Dp
+}
+
 // DestroyEvent is the last event sent through
 // a window event channel.
 type DestroyEvent struct {
@@ -15,6 +15,11 @@
 // to the source and all potential targets.
 //
 // Note that the RequestEvent is sent to the source upon drop.
+//
+// Transfers may cross the boundary between routers, such as between
+// windows of the same program: the router of the source requests the
+// data and the router of the target delivers it. Sources and targets
+// see the same sequence of events as for transfers within one router.
 package transfer
 
 import (
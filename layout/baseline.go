@@ -0,0 +1,72 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package layout
+
+import (
+	"image"
+
+	"github.com/Seikaijyu/gio/op"
+	"github.com/Seikaijyu/gio/unit"
+)
+
+// BaselineGrid snaps blocks of text to a baseline grid, a series of
+// horizontal lines Rhythm apart, to give the text of a layout a
+// consistent vertical rhythm.
+//
+// Layout moves a block down until its baseline is on a grid line, and
+// extends it until its height is a multiple of Rhythm. Blocks laid out
+// by the same BaselineGrid and stacked vertically, such as by a
+// vertical Flex, or overlaid by a Stack, thus share a grid that starts
+// at the top of the first block.
+type BaselineGrid struct {
+	// Rhythm is the distance between grid lines, typically the line
+	// height of the body text. Zero disables snapping.
+	Rhythm unit.Sp
+}
+
+// Layout a widget on the grid. The widget is laid out with the minimum
+// height cleared and the maximum height reduced by the room for
+// snapping, at most twice the rhythm.
+func (b BaselineGrid) Layout(gtx Context, w Widget) Dimensions {
+	rhythm := gtx.Sp(b.Rhythm)
+	if rhythm <= 0 {
+		return w(gtx)
+	}
+	cs := gtx.Constraints
+	gtx.Constraints = cs.SubMax(image.Pt(0, 2*(rhythm-1)))
+	gtx.Constraints.Min.Y = 0
+	macro := op.Record(gtx.Ops)
+	dims := w(gtx)
+	call := macro.Stop()
+	baseline := dims.Size.Y - dims.Baseline
+	top := roundUp(baseline, rhythm) - baseline
+	height := roundUp(top+dims.Size.Y, rhythm)
+	trans := op.Offset(image.Pt(0, top)).Push(gtx.Ops)
+	call.Add(gtx.Ops)
+	trans.Pop()
+	size := cs.Constrain(image.Pt(dims.Size.X, height))
+	return Dimensions{
+		Size:     size,
+		Baseline: size.Y - top - baseline,
+	}
+}
+
+// Snap returns the position of the first grid line at or below y, for
+// aligning custom layouts to the grid.
+func (b BaselineGrid) Snap(gtx Context, y int) int {
+	rhythm := gtx.Sp(b.Rhythm)
+	if rhythm <= 0 {
+		return y
+	}
+	return roundUp(y, rhythm)
+}
+
+// roundUp rounds y up to a multiple of n.
+func roundUp(y, n int) int {
+	if r := y % n; r > 0 {
+		return y + n - r
+	} else if r < 0 {
+		return y - r
+	}
+	return y
+}
@@ -0,0 +1,43 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package layout
+
+import (
+	"image"
+	"testing"
+
+	"github.com/Seikaijyu/gio/op"
+	"github.com/Seikaijyu/gio/unit"
+)
+
+func TestBaselineGrid(t *testing.T) {
+	gtx := Context{
+		Ops:         new(op.Ops),
+		Metric:      unit.Metric{PxPerDp: 1, PxPerSp: 1},
+		Constraints: Constraints{Max: image.Pt(100, 100)},
+	}
+	grid := BaselineGrid{Rhythm: 8}
+	// A block 13 high with its baseline 10 from the top.
+	text := func(gtx Context) Dimensions {
+		return Dimensions{Size: image.Pt(50, 13), Baseline: 3}
+	}
+	dims := grid.Layout(gtx, text)
+	if want := (Dimensions{Size: image.Pt(50, 24), Baseline: 8}); dims != want {
+		t.Errorf("snapped %+v, want %+v", dims, want)
+	}
+	// Every baseline of stacked blocks is on the grid.
+	y := 0
+	for i := 0; i < 3; i++ {
+		dims := grid.Layout(gtx, text)
+		if baseline := y + dims.Size.Y - dims.Baseline; baseline%8 != 0 {
+			t.Errorf("block %d: baseline %d is off the grid", i, baseline)
+		}
+		y += dims.Size.Y
+	}
+	if got := grid.Snap(gtx, 17); got != 24 {
+		t.Errorf("Snap(17) = %d, want 24", got)
+	}
+	if dims := (BaselineGrid{}).Layout(gtx, text); dims.Size.Y != 13 {
+		t.Errorf("zero rhythm snapped the block to %v", dims.Size)
+	}
+}
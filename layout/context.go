@@ -6,6 +6,9 @@ import (
 	"image"
 	"time"
 
+	"github.com/Seikaijyu/gio/f32"
+	"github.com/Seikaijyu/gio/i18n"
+	"github.com/Seikaijyu/gio/internal/ops"
 	"github.com/Seikaijyu/gio/io/event"
 	"github.com/Seikaijyu/gio/io/system"
 	"github.com/Seikaijyu/gio/op"
@@ -26,11 +29,21 @@ type Context struct {
 	Queue event.Queue
 	// Now is the animation time.
 	Now time.Time
+	// RefreshInterval is the time between display refreshes, or zero
+	// if unknown.
+	RefreshInterval time.Duration
 
 	// Locale provides information on the system's language preferences.
-	// BUG(whereswaldon): this field is not currently populated automatically.
-	// Interested users must look up and populate these values manually.
+	// BUG(whereswaldon): this field is only populated automatically for
+	// windows simulating a Device. Interested users must look up and
+	// populate these values manually.
 	Locale system.Locale
+	// Gestures are the gesture settings of the platform, for widgets
+	// to configure their gestures.
+	Gestures system.Gestures
+	// Catalog provides the messages for Locale. A nil Catalog selects
+	// i18n.Default.
+	Catalog *i18n.Catalog
 
 	*op.Ops
 }
@@ -40,14 +53,19 @@ type Context struct {
 //	Context{
 //	  Ops: ops,
 //	  Now: e.Now,
+//	  RefreshInterval: e.RefreshInterval,
 //	  Queue: e.Queue,
-//	  Config: e.Config,
+//	  Metric: e.Metric,
+//	  Locale: e.Locale,
+//	  Gestures: e.Gestures,
 //	  Constraints: Exact(e.Size),
 //	}
 //
 // NewContext calls ops.Reset and adjusts ops for e.Insets.
-func NewContext(ops *op.Ops, e system.FrameEvent) Context {
-	ops.Reset()
+func NewContext(o *op.Ops, e system.FrameEvent) Context {
+	o.Reset()
+	// The window clips its content.
+	ops.ViewClip(&o.Internal, image.Rectangle{Max: e.Size})
 
 	size := e.Size
 
@@ -57,18 +75,21 @@ func NewContext(ops *op.Ops, e system.FrameEvent) Context {
 		op.Offset(image.Point{
 			X: left,
 			Y: top,
-		}).Add(ops)
+		}).Add(o)
 
 		size.X -= left + e.Metric.Dp(e.Insets.Right)
 		size.Y -= top + e.Metric.Dp(e.Insets.Bottom)
 	}
 
 	return Context{
-		Ops:         ops,
-		Now:         e.Now,
-		Queue:       e.Queue,
-		Metric:      e.Metric,
-		Constraints: Exact(size),
+		Ops:             o,
+		Now:             e.Now,
+		RefreshInterval: e.RefreshInterval,
+		Queue:           e.Queue,
+		Metric:          e.Metric,
+		Locale:          e.Locale,
+		Gestures:        e.Gestures,
+		Constraints:     Exact(size),
 	}
 }
 
@@ -82,6 +103,16 @@ func (c Context) Sp(v unit.Sp) int {
 	return c.Metric.Sp(v)
 }
 
+// Message 返回 Catalog 中 Locale 语言的 key 消息，并以 args 格式化。
+func (c Context) Message(key string, args ...interface{}) string {
+	return c.Catalog.Text(c.Locale.Language, key, args...)
+}
+
+// PluralMessage 与 Message 类似，但按照 Locale 语言的复数规则为数量 n 选择消息的复数形式。
+func (c Context) PluralMessage(key string, n int, args ...interface{}) string {
+	return c.Catalog.Plural(c.Locale.Language, key, n, args...)
+}
+
 // Events 返回可用于键的事件。如果没有配置队列，Events 返回 nil。
 func (c Context) Events(k event.Tag) []event.Event {
 	if c.Queue == nil {
@@ -90,6 +121,20 @@ func (c Context) Events(k event.Tag) []event.Event {
 	return c.Queue.Events(k)
 }
 
+// Visible 返回当前裁剪区域在局部坐标中的边界，以及从局部坐标到窗口坐标的累积变换，
+// 由 clip 和 op.Offset 等变换的 Push 与 Pop 维护。自定义绘制的小部件可以据此剔除不可见的内容，
+// 或按缩放选择细节层次。
+//
+// 在 op.Record 录制的宏中，宏最终的位置尚不确定：变换相对于录制的起点，裁剪区域只包括录制期间
+// 压入的裁剪。没有裁剪时，返回的边界是无界的。
+func (c Context) Visible() (image.Rectangle, f32.Affine2D) {
+	if c.Ops == nil {
+		r, _ := ops.Visible(new(ops.Ops))
+		return r, f32.Affine2D{}
+	}
+	return ops.Visible(&c.Ops.Internal)
+}
+
 // Disabled 返回此上下文的一个副本，副本中的队列为 nil，可以阻止事件传递到使用它的小部件。
 //
 // 按照惯例，nil 队列是指示小部件以禁用状态绘制自身的信号。
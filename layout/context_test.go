@@ -0,0 +1,51 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package layout
+
+import (
+	"image"
+	"testing"
+
+	"github.com/Seikaijyu/gio/f32"
+	"github.com/Seikaijyu/gio/io/system"
+	"github.com/Seikaijyu/gio/op"
+	"github.com/Seikaijyu/gio/op/clip"
+)
+
+func TestContextVisible(t *testing.T) {
+	var ops op.Ops
+	gtx := NewContext(&ops, system.FrameEvent{Size: image.Pt(100, 80)})
+	if r, tr := gtx.Visible(); r != image.Rect(0, 0, 100, 80) || tr != (f32.Affine2D{}) {
+		t.Errorf("window visible %v, %v", r, tr)
+	}
+	off := op.Offset(image.Pt(30, 20)).Push(gtx.Ops)
+	cl := clip.Rect{Max: image.Pt(200, 10)}.Push(gtx.Ops)
+	if r, _ := gtx.Visible(); r != image.Rect(0, 0, 70, 10) {
+		t.Errorf("clipped visible %v, want (0,0)-(70,10)", r)
+	}
+	scale := op.Affine(f32.Affine2D{}.Scale(f32.Point{}, f32.Pt(2, 2))).Push(gtx.Ops)
+	if r, tr := gtx.Visible(); r != image.Rect(0, 0, 35, 5) || tr.Transform(f32.Pt(1, 1)) != f32.Pt(32, 22) {
+		t.Errorf("scaled visible %v, %v", r, tr)
+	}
+	scale.Pop()
+	cl.Pop()
+	off.Pop()
+	if r, _ := gtx.Visible(); r != image.Rect(0, 0, 100, 80) {
+		t.Errorf("popped visible %v", r)
+	}
+
+	// The position of a recording is unknown.
+	m := op.Record(gtx.Ops)
+	if r, _ := gtx.Visible(); r.Dx() < 1e6 {
+		t.Errorf("recorded visible %v, want unbounded", r)
+	}
+	cl = clip.Rect{Min: image.Pt(5, 5), Max: image.Pt(10, 10)}.Push(gtx.Ops)
+	if r, _ := gtx.Visible(); r != image.Rect(5, 5, 10, 10) {
+		t.Errorf("recorded clip visible %v", r)
+	}
+	cl.Pop()
+	m.Stop()
+	if r, _ := gtx.Visible(); r != image.Rect(0, 0, 100, 80) {
+		t.Errorf("visible after recording %v", r)
+	}
+}
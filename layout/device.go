@@ -0,0 +1,89 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package layout
+
+import (
+	"image"
+	"time"
+
+	"github.com/Seikaijyu/gio/io/system"
+	"github.com/Seikaijyu/gio/op"
+	"github.com/Seikaijyu/gio/unit"
+)
+
+// Device describes a simulated device, for exercising adaptive layouts
+// in tests across device profiles without the devices. Pass it to
+// app.Simulate for headless windows, or use its Context directly.
+type Device struct {
+	// Scale is the number of pixels per dp. The zero value selects 1.
+	Scale float32
+	// FontScale is the size of sp relative to dp, as set by the text
+	// size setting of the platform. The zero value selects 1.
+	FontScale float32
+	// Insets are the safe area insets, such as of translucent system
+	// bars and rounded display corners.
+	Insets system.Insets
+	// Cutouts are the areas of the display taken up by cameras and
+	// sensors, in pixels. A cutout touching an edge of the display
+	// extends the inset of the edge to cover it.
+	Cutouts []image.Rectangle
+	// Locale is the language and text direction of the device.
+	Locale system.Locale
+}
+
+// Metric returns the metric of the device.
+func (d Device) Metric() unit.Metric {
+	scale, fontScale := d.Scale, d.FontScale
+	if scale == 0 {
+		scale = 1
+	}
+	if fontScale == 0 {
+		fontScale = 1
+	}
+	return unit.Metric{PxPerDp: scale, PxPerSp: scale * fontScale}
+}
+
+// SafeInsets returns the insets of a display of size, extended to cover
+// its cutouts.
+func (d Device) SafeInsets(size image.Point) system.Insets {
+	in := d.Insets
+	m := d.Metric()
+	extend := func(inset *unit.Dp, px int) {
+		if dp := unit.Dp(float32(px) / m.PxPerDp); dp > *inset {
+			*inset = dp
+		}
+	}
+	for _, c := range d.Cutouts {
+		if c.Min.Y <= 0 {
+			extend(&in.Top, c.Max.Y)
+		}
+		if c.Max.Y >= size.Y {
+			extend(&in.Bottom, size.Y-c.Min.Y)
+		}
+		if c.Min.X <= 0 {
+			extend(&in.Left, c.Max.X)
+		}
+		if c.Max.X >= size.X {
+			extend(&in.Right, size.X-c.Min.X)
+		}
+	}
+	return in
+}
+
+// Frame returns the FrameEvent of a frame of size at time now on the
+// device. The event has no Queue.
+func (d Device) Frame(now time.Time, size image.Point) system.FrameEvent {
+	return system.FrameEvent{
+		Now:    now,
+		Metric: d.Metric(),
+		Size:   size,
+		Insets: d.SafeInsets(size),
+		Locale: d.Locale,
+	}
+}
+
+// Context returns the Context of a frame of size on the device, as
+// NewContext returns for its Frame.
+func (d Device) Context(ops *op.Ops, size image.Point) Context {
+	return NewContext(ops, d.Frame(time.Time{}, size))
+}
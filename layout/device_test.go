@@ -0,0 +1,40 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package layout
+
+import (
+	"image"
+	"testing"
+
+	"github.com/Seikaijyu/gio/io/system"
+	"github.com/Seikaijyu/gio/op"
+)
+
+func TestDeviceContext(t *testing.T) {
+	d := Device{
+		Scale:     2,
+		FontScale: 1.5,
+		Insets:    system.Insets{Top: 10, Bottom: 20},
+		// A notch at the top, and a hole at the left in landscape.
+		Cutouts: []image.Rectangle{
+			image.Rect(300, 0, 500, 60),
+			image.Rect(0, 200, 40, 260),
+		},
+		Locale: system.Locale{Language: "ar", Direction: system.RTL},
+	}
+	size := image.Pt(800, 600)
+	want := system.Insets{Top: 30, Bottom: 20, Left: 20}
+	if got := d.SafeInsets(size); got != want {
+		t.Errorf("got insets %+v, want %+v", got, want)
+	}
+	gtx := d.Context(new(op.Ops), size)
+	if got, want := gtx.Constraints.Max, image.Pt(800-40, 600-60-40); got != want {
+		t.Errorf("got constraints %v, want %v", got, want)
+	}
+	if gtx.Dp(10) != 20 || gtx.Sp(10) != 30 {
+		t.Errorf("got metric %+v, want scales 2 and 3", gtx.Metric)
+	}
+	if gtx.Locale != d.Locale {
+		t.Errorf("got locale %+v, want %+v", gtx.Locale, d.Locale)
+	}
+}
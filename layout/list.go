@@ -28,6 +28,15 @@ type List struct {
 	ScrollToEnd bool
 	// Alignment is the cross axis alignment of list elements.
 	Alignment Alignment
+	// Key, if set, returns a comparable key identifying the item at an
+	// index, such as the ID of its data. A List with Key anchors its
+	// scroll position to the first item whose leading edge is visible:
+	// when items before the anchor are inserted, removed or change size,
+	// the anchor keeps its place in the viewport instead of the content
+	// jumping. Anchoring is suspended while the list is scrolled to its
+	// end with ScrollToEnd, and for a frame after Position is modified
+	// outside Layout.
+	Key func(index int) interface{}
 
 	cs          Constraints
 	scroll      gesture.Scroll
@@ -45,6 +54,19 @@ type List struct {
 	maxSize  int
 	children []scrollChild
 	dir      iterationDir
+	anchor   listAnchor
+}
+
+// listAnchor is the item a List with a Key is anchored to.
+type listAnchor struct {
+	valid bool
+	key   interface{}
+	index int
+	// edge is the distance from the leading edge of the viewport to the
+	// leading edge of the item.
+	edge int
+	// pos is the position of the list the anchor was taken at.
+	pos Position
 }
 
 // ListElement is a function that computes the dimensions of
@@ -95,6 +117,7 @@ func (l *List) init(gtx Context, len int) {
 	l.maxSize = 0
 	l.children = l.children[:0]
 	l.len = len
+	l.restoreAnchor()
 	l.update(gtx)
 	if l.Position.First < 0 {
 		l.Position.Offset = 0
@@ -353,9 +376,64 @@ func (l *List) layout(ops *op.Ops, macro op.MacroOp) Dimensions {
 	l.scroll.Add(ops, scrollRange)
 
 	call.Add(ops)
+	l.setAnchor(children)
 	return Dimensions{Size: dims}
 }
 
+// setAnchor anchors the list to the first of its visible children whose
+// leading edge is in the viewport.
+func (l *List) setAnchor(visible []scrollChild) {
+	l.anchor = listAnchor{}
+	if l.Key == nil || len(visible) == 0 {
+		return
+	}
+	index, edge := l.Position.First, -l.Position.Offset
+	if edge < 0 && len(visible) > 1 {
+		index++
+		edge += l.Axis.Convert(visible[0].size).X
+	}
+	l.anchor = listAnchor{
+		valid: true,
+		key:   l.Key(index),
+		index: index,
+		edge:  edge,
+		pos:   l.Position,
+	}
+}
+
+// restoreAnchor moves the position of the list to keep its anchor in
+// place, after the items before it changed.
+func (l *List) restoreAnchor() {
+	a := l.anchor
+	if l.Key == nil || !a.valid || l.Position != a.pos || l.scrollToEnd() {
+		return
+	}
+	index := l.findKey(a.key, a.index)
+	if index == -1 {
+		// The anchor is gone.
+		return
+	}
+	l.Position.First = index
+	l.Position.Offset = -a.edge
+}
+
+// findKey returns the index of the item with key, searching outwards
+// from index, its previous index. It returns -1 if there is no such item.
+func (l *List) findKey(key interface{}, index int) int {
+	if index >= l.len {
+		index = l.len - 1
+	}
+	for d := 0; index-d >= 0 || index+d < l.len; d++ {
+		if i := index - d; i >= 0 && l.Key(i) == key {
+			return i
+		}
+		if i := index + d; d > 0 && i < l.len && l.Key(i) == key {
+			return i
+		}
+	}
+	return -1
+}
+
 // ScrollBy scrolls the list by a relative amount of items.
 //
 // Fractional scrolling may be inaccurate for items of differing
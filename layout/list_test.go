@@ -195,3 +195,67 @@ func TestExtraChildren(t *testing.T) {
 		t.Errorf("laid out %d of %d children", count, all)
 	}
 }
+
+func TestListAnchor(t *testing.T) {
+	keys := make([]int, 20)
+	sizes := make(map[int]int)
+	for i := range keys {
+		keys[i] = i
+		sizes[i] = 20
+	}
+	l := List{
+		Axis: Vertical,
+		Key: func(i int) interface{} {
+			return keys[i]
+		},
+	}
+	gtx := Context{
+		Ops:         new(op.Ops),
+		Constraints: Exact(image.Pt(20, 100)),
+	}
+	layout := func() {
+		l.Layout(gtx, len(keys), func(_ Context, i int) Dimensions {
+			return Dimensions{Size: image.Pt(20, sizes[keys[i]])}
+		})
+	}
+	// edge returns the distance from the top of the viewport to the item
+	// with key, visible right after the first.
+	edge := func(key int) int {
+		if k := keys[l.Position.First+1]; k != key {
+			t.Fatalf("item %d follows the first, want %d", k, key)
+		}
+		return sizes[keys[l.Position.First]] - l.Position.Offset
+	}
+	l.Position = Position{First: 5, Offset: 10}
+	layout()
+	layout()
+	if e := edge(6); e != 10 {
+		t.Fatalf("item 6 at %d, want 10", e)
+	}
+
+	// Insert items before the anchor, and grow the item partially above
+	// the viewport.
+	keys = append([]int{100, 101, 102}, keys...)
+	sizes[100], sizes[101], sizes[102] = 20, 20, 20
+	sizes[5] = 50
+	layout()
+	if e := edge(6); e != 10 {
+		t.Errorf("after insertion, item 6 at %d, want 10", e)
+	}
+
+	// Remove items before the anchor.
+	keys = keys[4:]
+	layout()
+	if e := edge(6); e != 10 {
+		t.Errorf("after removal, item 6 at %d, want 10", e)
+	}
+
+	// Modifying the position outside Layout scrolls as usual.
+	l.ScrollTo(0)
+	keys = append([]int{200}, keys...)
+	sizes[200] = 20
+	layout()
+	if l.Position.First != 0 || l.Position.Offset != 0 {
+		t.Errorf("ScrollTo(0) scrolled to %+v", l.Position)
+	}
+}
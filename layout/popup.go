@@ -0,0 +1,186 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package layout
+
+import (
+	"image"
+
+	"github.com/Seikaijyu/gio/io/event"
+	"github.com/Seikaijyu/gio/io/key"
+	"github.com/Seikaijyu/gio/op"
+	"github.com/Seikaijyu/gio/unit"
+)
+
+// Popup positions floating widgets such as tooltips, menus and
+// dropdowns next to an anchor rectangle, keeping them inside a
+// bounding rectangle. The anchor and bounds are in the same
+// coordinate space, for example the window area or the area of a
+// single monitor.
+//
+// The popup is placed on the preferred side of the anchor, or flipped
+// to the opposite side if it doesn't fit and there is more space
+// there. It is then moved along the side and, if neither side has
+// room, over the anchor to stay inside the bounds.
+type Popup struct {
+	// Side is the preferred side of the anchor, one of N, E, S and W.
+	// Other directions are treated as S.
+	Side Direction
+	// Alignment aligns the popup along the side of the anchor. Start
+	// aligns the top or left edges, End the bottom or right edges, and
+	// Middle or Baseline centers the popup.
+	Alignment Alignment
+	// Gap is the space between the anchor and the popup.
+	Gap unit.Dp
+	// Margin is the minimum space between the popup and the edges of
+	// the bounds.
+	Margin unit.Dp
+	// FocusScope, if non-nil, identifies a key.FocusScopeOp around
+	// the popup added by Layout, which confines focus moves to the
+	// popup and restores the focus when it is dismissed.
+	FocusScope event.Tag
+}
+
+// Placement is the result of positioning a popup.
+type Placement struct {
+	// Rect is the area of the popup.
+	Rect image.Rectangle
+	// Side is the side of the anchor the popup was placed on, after
+	// flipping.
+	Side Direction
+	// Arrow is the point on the edge of the popup facing the anchor
+	// that is closest to the center of the anchor, relative to
+	// Rect.Min. It is where an arrow pointing at the anchor should be
+	// drawn.
+	Arrow image.Point
+}
+
+// Position places a popup of the given size next to anchor, inside
+// bounds.
+func (p Popup) Position(gtx Context, anchor, bounds image.Rectangle, size image.Point) Placement {
+	gap := gtx.Dp(p.Gap)
+	avail := bounds.Inset(gtx.Dp(p.Margin))
+	side := p.Side
+	switch side {
+	case N, E, S, W:
+	default:
+		side = S
+	}
+	// space returns the room for the popup on side s of the anchor.
+	space := func(s Direction) int {
+		switch s {
+		case N:
+			return anchor.Min.Y - gap - avail.Min.Y
+		case W:
+			return anchor.Min.X - gap - avail.Min.X
+		case E:
+			return avail.Max.X - anchor.Max.X - gap
+		default:
+			return avail.Max.Y - anchor.Max.Y - gap
+		}
+	}
+	axis := Vertical
+	if side == E || side == W {
+		axis = Horizontal
+	}
+	need := axis.Convert(size).X
+	if opp := opposite(side); space(side) < need && space(opp) > space(side) {
+		side = opp
+	}
+
+	var pos image.Point
+	switch side {
+	case N:
+		pos.Y = anchor.Min.Y - gap - size.Y
+	case S:
+		pos.Y = anchor.Max.Y + gap
+	case W:
+		pos.X = anchor.Min.X - gap - size.X
+	case E:
+		pos.X = anchor.Max.X + gap
+	}
+	// Align along the side, in the cross axis.
+	amin, amax := axis.Convert(anchor.Min).Y, axis.Convert(anchor.Max).Y
+	cross := axis.Convert(size).Y
+	var c int
+	switch p.Alignment {
+	case Start:
+		c = amin
+	case End:
+		c = amax - cross
+	default:
+		c = amin + (amax-amin-cross)/2
+	}
+	if axis == Vertical {
+		pos.X = c
+	} else {
+		pos.Y = c
+	}
+	pos.X = clampPopup(pos.X, avail.Min.X, avail.Max.X-size.X)
+	pos.Y = clampPopup(pos.Y, avail.Min.Y, avail.Max.Y-size.Y)
+
+	r := image.Rectangle{Min: pos, Max: pos.Add(size)}
+	center := anchor.Min.Add(anchor.Max).Div(2)
+	var arrow image.Point
+	switch side {
+	case N:
+		arrow = image.Pt(center.X-r.Min.X, size.Y)
+	case S:
+		arrow = image.Pt(center.X-r.Min.X, 0)
+	case W:
+		arrow = image.Pt(size.X, center.Y-r.Min.Y)
+	case E:
+		arrow = image.Pt(0, center.Y-r.Min.Y)
+	}
+	arrow.X = clampPopup(arrow.X, 0, size.X)
+	arrow.Y = clampPopup(arrow.Y, 0, size.Y)
+	return Placement{Rect: r, Side: side, Arrow: arrow}
+}
+
+// Layout a popup widget next to anchor. The widget is laid out with
+// the size of the bounds minus margins as its maximum constraints and
+// drawn on top of other content using op.Defer. To draw content that
+// depends on the placement, such as an arrow, use Position instead.
+func (p Popup) Layout(gtx Context, anchor, bounds image.Rectangle, w Widget) Placement {
+	gtx.Constraints = Constraints{Max: bounds.Inset(gtx.Dp(p.Margin)).Size()}
+	macro := op.Record(gtx.Ops)
+	dims := w(gtx)
+	call := macro.Stop()
+	pl := p.Position(gtx, anchor, bounds, dims.Size)
+	macro = op.Record(gtx.Ops)
+	op.Offset(pl.Rect.Min).Add(gtx.Ops)
+	if p.FocusScope != nil {
+		s := key.FocusScopeOp{Tag: p.FocusScope}.Push(gtx.Ops)
+		call.Add(gtx.Ops)
+		s.Pop()
+	} else {
+		call.Add(gtx.Ops)
+	}
+	op.Defer(gtx.Ops, macro.Stop())
+	return pl
+}
+
+// opposite returns the side opposite to s.
+func opposite(s Direction) Direction {
+	switch s {
+	case N:
+		return S
+	case E:
+		return W
+	case W:
+		return E
+	default:
+		return N
+	}
+}
+
+// clampPopup clamps v to [min; max], preferring min if the range is
+// empty.
+func clampPopup(v, min, max int) int {
+	if v > max {
+		v = max
+	}
+	if v < min {
+		v = min
+	}
+	return v
+}
@@ -0,0 +1,70 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package layout
+
+import (
+	"image"
+	"testing"
+
+	"github.com/Seikaijyu/gio/op"
+)
+
+func TestPopupPosition(t *testing.T) {
+	gtx := Context{Ops: new(op.Ops)}
+	bounds := image.Rect(0, 0, 200, 200)
+	size := image.Pt(40, 20)
+	tests := []struct {
+		name   string
+		popup  Popup
+		anchor image.Rectangle
+		want   Placement
+	}{
+		{
+			name:   "below",
+			popup:  Popup{Side: S, Gap: 2},
+			anchor: image.Rect(80, 50, 120, 60),
+			want:   Placement{Rect: image.Rect(80, 62, 120, 82), Side: S, Arrow: image.Pt(20, 0)},
+		},
+		{
+			name:   "flip above",
+			popup:  Popup{Side: S},
+			anchor: image.Rect(80, 185, 120, 195),
+			want:   Placement{Rect: image.Rect(80, 165, 120, 185), Side: N, Arrow: image.Pt(20, 20)},
+		},
+		{
+			name:   "flip left",
+			popup:  Popup{Side: E, Alignment: Start},
+			anchor: image.Rect(170, 10, 190, 20),
+			want:   Placement{Rect: image.Rect(130, 10, 170, 30), Side: W, Arrow: image.Pt(40, 5)},
+		},
+		{
+			name:   "clamp along side",
+			popup:  Popup{Side: S, Margin: 4},
+			anchor: image.Rect(0, 0, 10, 10),
+			want:   Placement{Rect: image.Rect(4, 10, 44, 30), Side: S, Arrow: image.Pt(1, 0)},
+		},
+		{
+			name:   "end alignment",
+			popup:  Popup{Side: N, Alignment: End},
+			anchor: image.Rect(100, 100, 180, 110),
+			want:   Placement{Rect: image.Rect(140, 80, 180, 100), Side: N, Arrow: image.Pt(0, 20)},
+		},
+	}
+	for _, test := range tests {
+		got := test.popup.Position(gtx, test.anchor, bounds, size)
+		if got != test.want {
+			t.Errorf("%s: got %+v, want %+v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestPopupOverlapsAnchor(t *testing.T) {
+	gtx := Context{Ops: new(op.Ops)}
+	// Neither side has room; the popup stays inside the bounds.
+	bounds := image.Rect(0, 0, 100, 30)
+	anchor := image.Rect(10, 10, 20, 20)
+	got := Popup{Side: S}.Position(gtx, anchor, bounds, image.Pt(30, 25))
+	if !got.Rect.In(bounds) {
+		t.Errorf("popup %v outside bounds %v", got.Rect, bounds)
+	}
+}
@@ -42,11 +42,12 @@ func init() {
 // state to the intersection of the current p.
 func (p Op) Push(o *op.Ops) Stack {
 	id, macroID := ops.PushOp(&o.Internal, ops.ClipStack)
-	p.add(o)
+	ops.ViewClip(&o.Internal, p.add(o))
 	return Stack{ops: &o.Internal, id: id, macroID: macroID}
 }
 
-func (p Op) add(o *op.Ops) {
+// add the clip operation and return its bounds.
+func (p Op) add(o *op.Ops) image.Rectangle {
 	path := p.path
 
 	if !path.hasSegments && p.width > 0 {
@@ -99,6 +100,7 @@ func (p Op) add(o *op.Ops) {
 		data[17] = byte(1)
 	}
 	data[18] = byte(path.shape)
+	return bounds
 }
 
 func (s Stack) Pop() {
@@ -0,0 +1,59 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+/*
+Package native reserves areas of a window for native child views, such
+as web views, video surfaces and map controls.
+
+Gio draws the content of a window to a single surface, so it can't draw
+native views itself. Instead, a ViewOp reserves the current clip area for
+a native view, and the window moves, clips and shows the view over that
+area after every frame. Views not placed in a frame are hidden, but stay
+children of the window and are destroyed along with it.
+
+Native views are stacked in the order of their ViewOps, unless their
+Order fields say otherwise. All views are above the Gio content of the
+window, because the platforms composite child views over the surface of
+their parent. Views receive input directly from the platform; Gio
+doesn't receive pointer events for the area covered by a view. Only the
+bounds of the clip areas are used for clipping a view, and
+transformations other than offsets and scales are ignored.
+
+The view handle is platform specific:
+
+  - Windows: a HWND. It is made a child window of the Gio window.
+  - macOS: a NSView. It is added as a subview of the Gio view.
+  - iOS: a UIView. It is added as a subview of the Gio view.
+  - X11: a Window. It is reparented to the Gio window.
+
+Native views are not supported on Android, where the Gio view is a
+SurfaceView without child views; on Wayland, where a view would have to
+be a subsurface of the Gio surface; and in browsers. ViewOps are ignored
+there, leaving their areas to the Gio content.
+*/
+package native
+
+import (
+	"encoding/binary"
+
+	"github.com/Seikaijyu/gio/internal/ops"
+	"github.com/Seikaijyu/gio/op"
+)
+
+// ViewOp places a native view over the current clip area.
+type ViewOp struct {
+	// Handle is the platform handle of the view.
+	Handle uintptr
+	// Order stacks the view relative to the other views of the frame.
+	// Views with a higher Order are above views with a lower Order,
+	// and views with equal Orders are stacked in the order of their
+	// ViewOps.
+	Order int32
+}
+
+func (v ViewOp) Add(o *op.Ops) {
+	data := ops.Write(&o.Internal, ops.TypeNativeViewLen)
+	data[0] = byte(ops.TypeNativeView)
+	bo := binary.LittleEndian
+	bo.PutUint64(data[1:], uint64(v.Handle))
+	bo.PutUint32(data[9:], uint32(v.Order))
+}
@@ -60,6 +60,9 @@ The MacroOp records a list of operations to be executed later:
 
 	// replay the recorded operations:
 	call.Add(ops)
+
+Splice turns the operations of a separate Ops into a CallOp, for replaying
+the cached operations of a widget without encoding them again.
 */
 package op
 
@@ -171,6 +174,18 @@ func (m MacroOp) Stop() CallOp {
 	}
 }
 
+// Splice returns a CallOp for replaying the operations currently in o,
+// such as the operations of a widget kept across frames while its state
+// is unchanged. Like other CallOps, the operations are referenced, not
+// copied, so o must not be reset while the CallOp is in use. Operations
+// added to o after Splice are not included.
+func Splice(o *Ops) CallOp {
+	return CallOp{
+		ops: &o.Internal,
+		end: ops.PCFor(&o.Internal),
+	}
+}
+
 // Add the recorded list of operations. Add
 // panics if the Ops containing the recording
 // has been reset.
@@ -220,6 +235,7 @@ func (t TransformOp) Add(o *Ops) {
 }
 
 func (t TransformOp) add(o *Ops, push bool) {
+	ops.ViewTransform(&o.Internal, t.t)
 	data := ops.Write(&o.Internal, ops.TypeTransformLen)
 	data[0] = byte(ops.TypeTransform)
 	if push {
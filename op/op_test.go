@@ -34,3 +34,59 @@ func TestIncompleteMacroReader(t *testing.T) {
 		t.Error("decoded an operation from a semantically empty Ops")
 	}
 }
+
+func TestSplice(t *testing.T) {
+	var src, dst Ops
+	InvalidateOp{}.Add(&src)
+	Offset(image.Pt(1, 2)).Add(&src)
+	call := Splice(&src)
+	// Operations added after Splice are not replayed.
+	InvalidateOp{}.Add(&src)
+	call.Add(&dst)
+
+	var r ops.Reader
+	r.Reset(&dst.Internal)
+	var types []ops.OpType
+	for {
+		e, more := r.Decode()
+		if !more {
+			break
+		}
+		types = append(types, ops.OpType(e.Data[0]))
+	}
+	want := []ops.OpType{ops.TypeInvalidate, ops.TypeTransform}
+	if len(types) != len(want) {
+		t.Fatalf("spliced ops are %v, want %v", types, want)
+	}
+	for i, typ := range types {
+		if typ != want[i] {
+			t.Errorf("spliced op %d is %v, want %v", i, typ, want[i])
+		}
+	}
+}
+
+// addWidget adds the operations of a widget of many parts.
+func addWidget(o *Ops) {
+	for i := 0; i < 100; i++ {
+		Offset(image.Pt(i, i)).Push(o).Pop()
+	}
+}
+
+func BenchmarkRerecord(b *testing.B) {
+	var o Ops
+	for i := 0; i < b.N; i++ {
+		o.Reset()
+		addWidget(&o)
+	}
+}
+
+func BenchmarkSplice(b *testing.B) {
+	var cache, o Ops
+	addWidget(&cache)
+	call := Splice(&cache)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		o.Reset()
+		call.Add(&o)
+	}
+}
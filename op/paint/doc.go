@@ -10,6 +10,12 @@ ignored.
 The current brush is set by either a ColorOp for a constant color, or
 ImageOp for an image, or LinearGradientOp for gradients.
 
+CheckerOp, StripesOp and NoiseOp paint procedural patterns over a rectangle,
+for placeholders, transparency grids and textures without bitmap assets.
+
+FillShape and Fill paint a color in a single shape. FillRects paints a color
+in many rectangles, encoding them in bulk for widgets that draw grids of cells.
+
 All color.NRGBA values are in the sRGB color space.
 */
 package paint
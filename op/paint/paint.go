@@ -167,6 +167,36 @@ func Fill(ops *op.Ops, c color.NRGBA) {
 	PaintOp{}.Add(ops)
 }
 
+// FillRects fills rectangles with a color. It is equivalent to FillShape
+// with a clip.Rect for each rectangle, but encodes the operations in bulk,
+// which is faster for large numbers of rectangles such as the cells of a
+// grid.
+func FillRects(o *op.Ops, c color.NRGBA, rects ...image.Rectangle) {
+	if len(rects) == 0 {
+		return
+	}
+	ColorOp{Color: c}.Add(o)
+	// Each rectangle is a clip push, a paint and a clip pop. The clips
+	// are balanced within the batch, so the clip stack is left alone.
+	const n = ops.TypeClipLen + ops.TypePaintLen + ops.TypePopClipLen
+	data := ops.Write(&o.Internal, n*len(rects))
+	bo := binary.LittleEndian
+	for _, r := range rects {
+		data[0] = byte(ops.TypeClip)
+		bo.PutUint32(data[1:], uint32(r.Min.X))
+		bo.PutUint32(data[5:], uint32(r.Min.Y))
+		bo.PutUint32(data[9:], uint32(r.Max.X))
+		bo.PutUint32(data[13:], uint32(r.Max.Y))
+		data[17] = 1
+		data[18] = byte(ops.Rect)
+		data = data[ops.TypeClipLen:]
+		data[0] = byte(ops.TypePaint)
+		data = data[ops.TypePaintLen:]
+		data[0] = byte(ops.TypePopClip)
+		data = data[ops.TypePopClipLen:]
+	}
+}
+
 // PushOpacity creates a drawing layer with an opacity in the range [0;1].
 // The layer includes every subsequent drawing operation until [OpacityStack.Pop]
 // is called.
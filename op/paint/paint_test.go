@@ -0,0 +1,81 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package paint
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/Seikaijyu/gio/internal/ops"
+	"github.com/Seikaijyu/gio/op"
+	"github.com/Seikaijyu/gio/op/clip"
+)
+
+func gridRects(n int) []image.Rectangle {
+	rects := make([]image.Rectangle, 0, n*n)
+	for y := 0; y < n; y++ {
+		for x := 0; x < n; x++ {
+			rects = append(rects, image.Rect(x*10, y*10, x*10+9, y*10+9))
+		}
+	}
+	return rects
+}
+
+func decodeAll(o *op.Ops) [][]byte {
+	var r ops.Reader
+	r.Reset(&o.Internal)
+	var res [][]byte
+	for {
+		e, more := r.Decode()
+		if !more {
+			return res
+		}
+		res = append(res, e.Data)
+	}
+}
+
+func TestFillRects(t *testing.T) {
+	red := color.NRGBA{R: 0xff, A: 0xff}
+	rects := gridRects(3)
+	var bulk, single op.Ops
+	FillRects(&bulk, red, rects...)
+	ColorOp{Color: red}.Add(&single)
+	for _, r := range rects {
+		st := clip.Rect(r).Push(&single)
+		PaintOp{}.Add(&single)
+		st.Pop()
+	}
+	got, want := decodeAll(&bulk), decodeAll(&single)
+	if len(got) != len(want) {
+		t.Fatalf("FillRects encoded %d ops, want %d", len(got), len(want))
+	}
+	for i := range got {
+		if !bytes.Equal(got[i], want[i]) {
+			t.Errorf("op %d is %v, want %v", i, got[i], want[i])
+		}
+	}
+	// The clip stack is balanced.
+	clip.Rect{}.Push(&bulk).Pop()
+}
+
+func BenchmarkFillShapeRects(b *testing.B) {
+	rects := gridRects(32)
+	var o op.Ops
+	for i := 0; i < b.N; i++ {
+		o.Reset()
+		for _, r := range rects {
+			FillShape(&o, color.NRGBA{A: 0xff}, clip.Rect(r).Op())
+		}
+	}
+}
+
+func BenchmarkFillRects(b *testing.B) {
+	rects := gridRects(32)
+	var o op.Ops
+	for i := 0; i < b.N; i++ {
+		o.Reset()
+		FillRects(&o, color.NRGBA{A: 0xff}, rects...)
+	}
+}
@@ -0,0 +1,205 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package paint
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"sync"
+
+	"github.com/Seikaijyu/gio/f32"
+	"github.com/Seikaijyu/gio/op"
+	"github.com/Seikaijyu/gio/op/clip"
+)
+
+// CheckerOp paints a checkerboard of square cells over a rectangle, such
+// as the transparency grid behind images in editors. The cells are
+// aligned to the origin of the current transformation, so that the
+// pattern stays in place when the rectangle changes.
+type CheckerOp struct {
+	Rect image.Rectangle
+	// Size is the side of the cells, in pixels.
+	Size int
+	// Color1 fills the cells at even sums of their indices, including
+	// the cell at the origin, and Color2 the others.
+	Color1, Color2 color.NRGBA
+}
+
+// StripesOp paints parallel stripes of alternating colors over a
+// rectangle.
+type StripesOp struct {
+	Rect image.Rectangle
+	// Width is the width of each stripe, in pixels.
+	Width float32
+	// Angle is the direction of the stripes, in radians clockwise from
+	// horizontal.
+	Angle float32
+	// Color1 and Color2 are the colors of the alternating stripes.
+	Color1, Color2 color.NRGBA
+}
+
+// NoiseOp paints smooth value noise over a rectangle, for subtle
+// textures. The noise is repeatable: equal seeds give equal patterns,
+// aligned to the origin of the current transformation.
+type NoiseOp struct {
+	Rect image.Rectangle
+	// Scale is the distance between the random values of the noise,
+	// in pixels. The colors are interpolated between them.
+	Scale float32
+	// Seed selects the pattern.
+	Seed uint32
+	// Color1 and Color2 are the colors at the extremes of the noise.
+	Color1, Color2 color.NRGBA
+}
+
+// noiseKey identifies the lattice image of a NoiseOp.
+type noiseKey struct {
+	seed           uint32
+	min, max       image.Point
+	color1, color2 color.NRGBA
+}
+
+// maxNoiseImages bounds the number of cached noise images.
+const maxNoiseImages = 32
+
+// noiseImages caches the images of recent NoiseOps, so that repeated
+// painting doesn't upload new textures.
+var noiseImages struct {
+	mu     sync.Mutex
+	images map[noiseKey]ImageOp
+}
+
+func (c CheckerOp) Add(o *op.Ops) {
+	if c.Size <= 0 || c.Rect.Empty() {
+		return
+	}
+	FillShape(o, c.Color1, clip.Rect(c.Rect).Op())
+	defer clip.Rect(c.Rect).Push(o).Pop()
+	var p clip.Path
+	p.Begin(o)
+	i0, j0 := floorDiv(c.Rect.Min.X, c.Size), floorDiv(c.Rect.Min.Y, c.Size)
+	for j := j0; j*c.Size < c.Rect.Max.Y; j++ {
+		i := i0
+		if (i+j)&1 == 0 {
+			i++
+		}
+		for ; i*c.Size < c.Rect.Max.X; i += 2 {
+			x, y := float32(i*c.Size), float32(j*c.Size)
+			s := float32(c.Size)
+			p.MoveTo(f32.Pt(x, y))
+			p.LineTo(f32.Pt(x+s, y))
+			p.LineTo(f32.Pt(x+s, y+s))
+			p.LineTo(f32.Pt(x, y+s))
+			p.Close()
+		}
+	}
+	FillShape(o, c.Color2, clip.Outline{Path: p.End()}.Op())
+}
+
+func (s StripesOp) Add(o *op.Ops) {
+	if s.Width <= 0 || s.Rect.Empty() {
+		return
+	}
+	FillShape(o, s.Color1, clip.Rect(s.Rect).Op())
+	defer clip.Rect(s.Rect).Push(o).Pop()
+	// Lay out horizontal stripes over a square covering the rectangle
+	// in every direction, and rotate them around its center.
+	center := f32.Pt(float32(s.Rect.Min.X+s.Rect.Max.X)/2, float32(s.Rect.Min.Y+s.Rect.Max.Y)/2)
+	sz := s.Rect.Size()
+	r := float32(math.Hypot(float64(sz.X), float64(sz.Y)))/2 + s.Width
+	defer op.Affine(f32.Affine2D{}.Rotate(center, s.Angle)).Push(o).Pop()
+	var p clip.Path
+	p.Begin(o)
+	// Align the stripes to the center for a stable pattern.
+	period := 2 * s.Width
+	first := float32(math.Floor(float64(-r/period))) * period
+	for y := first; y < r; y += period {
+		p.MoveTo(center.Add(f32.Pt(-r, y)))
+		p.LineTo(center.Add(f32.Pt(r, y)))
+		p.LineTo(center.Add(f32.Pt(r, y+s.Width)))
+		p.LineTo(center.Add(f32.Pt(-r, y+s.Width)))
+		p.Close()
+	}
+	FillShape(o, s.Color2, clip.Outline{Path: p.End()}.Op())
+}
+
+func (n NoiseOp) Add(o *op.Ops) {
+	if n.Scale <= 0 || n.Rect.Empty() {
+		return
+	}
+	// The noise is an image of a random value per lattice point, scaled
+	// up with linear filtering. Texel centers are at the lattice points.
+	lo := image.Pt(
+		int(math.Floor(float64(float32(n.Rect.Min.X)/n.Scale))),
+		int(math.Floor(float64(float32(n.Rect.Min.Y)/n.Scale))),
+	)
+	hi := image.Pt(
+		int(math.Ceil(float64(float32(n.Rect.Max.X)/n.Scale)))+1,
+		int(math.Ceil(float64(float32(n.Rect.Max.Y)/n.Scale)))+1,
+	)
+	img := noiseImage(noiseKey{seed: n.Seed, min: lo, max: hi, color1: n.Color1, color2: n.Color2})
+	defer clip.Rect(n.Rect).Push(o).Pop()
+	origin := f32.Pt((float32(lo.X)-.5)*n.Scale, (float32(lo.Y)-.5)*n.Scale)
+	tr := f32.Affine2D{}.Scale(f32.Point{}, f32.Pt(n.Scale, n.Scale)).Offset(origin)
+	defer op.Affine(tr).Push(o).Pop()
+	img.Add(o)
+	PaintOp{}.Add(o)
+}
+
+// noiseImage returns the cached image for k, creating it if needed.
+func noiseImage(k noiseKey) ImageOp {
+	noiseImages.mu.Lock()
+	defer noiseImages.mu.Unlock()
+	if img, ok := noiseImages.images[k]; ok {
+		return img
+	}
+	if len(noiseImages.images) >= maxNoiseImages || noiseImages.images == nil {
+		noiseImages.images = make(map[noiseKey]ImageOp)
+	}
+	sz := k.max.Sub(k.min)
+	dst := image.NewRGBA(image.Rectangle{Max: sz})
+	for y := 0; y < sz.Y; y++ {
+		for x := 0; x < sz.X; x++ {
+			v := noiseValue(k.seed, k.min.X+x, k.min.Y+y)
+			dst.Set(x, y, lerpNRGBA(k.color1, k.color2, v))
+		}
+	}
+	img := NewImageOp(dst)
+	img.Filter = FilterLinear
+	noiseImages.images[k] = img
+	return img
+}
+
+// noiseValue returns the random value in [0, 1] of the lattice point
+// (x, y) for a seed.
+func noiseValue(seed uint32, x, y int) float32 {
+	h := seed ^ uint32(x)*0x9e3779b1 ^ uint32(y)*0x85ebca77
+	h ^= h >> 16
+	h *= 0x7feb352d
+	h ^= h >> 15
+	h *= 0x846ca68b
+	h ^= h >> 16
+	return float32(h) / math.MaxUint32
+}
+
+func lerpNRGBA(c1, c2 color.NRGBA, t float32) color.NRGBA {
+	lerp := func(a, b uint8) uint8 {
+		return uint8(float32(a) + (float32(b)-float32(a))*t + .5)
+	}
+	return color.NRGBA{
+		R: lerp(c1.R, c2.R),
+		G: lerp(c1.G, c2.G),
+		B: lerp(c1.B, c2.B),
+		A: lerp(c1.A, c2.A),
+	}
+}
+
+// floorDiv returns x/y rounded toward negative infinity.
+func floorDiv(x, y int) int {
+	q := x / y
+	if (x%y != 0) && ((x < 0) != (y < 0)) {
+		q--
+	}
+	return q
+}
@@ -0,0 +1,257 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package paint
+
+import (
+	"encoding/binary"
+	"image"
+	"image/color"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/Seikaijyu/gio/f32"
+	"github.com/Seikaijyu/gio/internal/ops"
+	"github.com/Seikaijyu/gio/internal/scene"
+	"github.com/Seikaijyu/gio/op"
+)
+
+var (
+	patternColor1 = color.NRGBA{R: 0xff, A: 0xff}
+	patternColor2 = color.NRGBA{B: 0xff, A: 0xff}
+)
+
+// decodeOps returns the ops of o.
+func decodeOps(o *op.Ops) []ops.EncodedOp {
+	var r ops.Reader
+	r.Reset(&o.Internal)
+	var res []ops.EncodedOp
+	for {
+		e, more := r.Decode()
+		if !more {
+			return res
+		}
+		res = append(res, e)
+	}
+}
+
+// opTypes returns the types of the ops in encs.
+func opTypes(encs []ops.EncodedOp) []ops.OpType {
+	var types []ops.OpType
+	for _, e := range encs {
+		types = append(types, ops.OpType(e.Data[0]))
+	}
+	return types
+}
+
+// decodeColors returns the colors of the color ops in encs.
+func decodeColors(encs []ops.EncodedOp) []color.NRGBA {
+	var cols []color.NRGBA
+	for _, e := range encs {
+		if ops.OpType(e.Data[0]) == ops.TypeColor {
+			cols = append(cols, color.NRGBA{R: e.Data[1], G: e.Data[2], B: e.Data[3], A: e.Data[4]})
+		}
+	}
+	return cols
+}
+
+// decodeContours returns the minimum point of every contour of the
+// path in encs, which is the corner of a cell of a CheckerOp.
+func decodeContours(encs []ops.EncodedOp) []f32.Point {
+	var mins []f32.Point
+	for _, e := range encs {
+		if ops.OpType(e.Data[0]) != ops.TypeAux {
+			continue
+		}
+		data := e.Data[ops.TypeAuxLen:]
+		contour := uint32(0)
+		for len(data) > 0 {
+			c := binary.LittleEndian.Uint32(data)
+			cmd := ops.DecodeCommand(data[4:])
+			data = data[4+scene.CommandSize:]
+			if cmd.Op() != scene.OpLine {
+				continue
+			}
+			from, to := scene.DecodeLine(cmd)
+			if c != contour {
+				contour = c
+				mins = append(mins, from)
+			}
+			m := &mins[len(mins)-1]
+			m.X = float32(math.Min(float64(m.X), math.Min(float64(from.X), float64(to.X))))
+			m.Y = float32(math.Min(float64(m.Y), math.Min(float64(from.Y), float64(to.Y))))
+		}
+	}
+	return mins
+}
+
+func TestPatternOpsEmpty(t *testing.T) {
+	r := image.Rect(0, 0, 40, 20)
+	for _, p := range []interface{ Add(o *op.Ops) }{
+		CheckerOp{Rect: r},
+		CheckerOp{Size: 10},
+		StripesOp{Rect: r},
+		StripesOp{Width: 4},
+		NoiseOp{Rect: r},
+		NoiseOp{Scale: 8},
+	} {
+		var o op.Ops
+		p.Add(&o)
+		if encs := decodeOps(&o); len(encs) > 0 {
+			t.Errorf("%+v encoded %v, want no ops", p, opTypes(encs))
+		}
+	}
+}
+
+func TestCheckerOp(t *testing.T) {
+	tests := []struct {
+		rect image.Rectangle
+		// cells of Color2, in units of cells.
+		cells []image.Point
+	}{
+		{
+			rect:  image.Rect(0, 0, 40, 20),
+			cells: []image.Point{{1, 0}, {3, 0}, {0, 1}, {2, 1}},
+		},
+		// The cells are aligned to the origin, where Color1 is.
+		{
+			rect:  image.Rect(-15, -5, 5, 5),
+			cells: []image.Point{{-2, -1}, {0, -1}, {-1, 0}},
+		},
+	}
+	for _, tc := range tests {
+		var o op.Ops
+		CheckerOp{Rect: tc.rect, Size: 10, Color1: patternColor1, Color2: patternColor2}.Add(&o)
+		encs := decodeOps(&o)
+		if got, want := decodeColors(encs), []color.NRGBA{patternColor1, patternColor2}; !reflect.DeepEqual(got, want) {
+			t.Errorf("%v: colors %v, want %v", tc.rect, got, want)
+		}
+		var cells []image.Point
+		for _, m := range decodeContours(encs) {
+			cells = append(cells, image.Pt(int(m.X)/10, int(m.Y)/10))
+		}
+		if !reflect.DeepEqual(cells, tc.cells) {
+			t.Errorf("%v: Color2 cells %v, want %v", tc.rect, cells, tc.cells)
+		}
+		// Every fill is clipped to the rectangle.
+		for _, e := range encs {
+			if ops.OpType(e.Data[0]) != ops.TypeClip {
+				continue
+			}
+			var c ops.ClipOp
+			c.Decode(e.Data)
+			if c.Bounds != tc.rect {
+				t.Errorf("%v: first clip bounds %v", tc.rect, c.Bounds)
+			}
+			break
+		}
+	}
+}
+
+func TestStripesOp(t *testing.T) {
+	var o op.Ops
+	s := StripesOp{Rect: image.Rect(0, 0, 40, 20), Width: 4, Angle: math.Pi / 4, Color1: patternColor1, Color2: patternColor2}
+	s.Add(&o)
+	encs := decodeOps(&o)
+	if got, want := decodeColors(encs), []color.NRGBA{patternColor1, patternColor2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("colors %v, want %v", got, want)
+	}
+	var tr f32.Affine2D
+	for _, e := range encs {
+		if ops.OpType(e.Data[0]) == ops.TypeTransform {
+			tr, _ = ops.DecodeTransform(e.Data)
+		}
+	}
+	if want := (f32.Affine2D{}).Rotate(f32.Pt(20, 10), s.Angle); tr != want {
+		t.Errorf("stripes transformed by %v, want a rotation around the center %v", tr, want)
+	}
+	// The stripes, before rotation, are Width apart and cover the
+	// rectangle in every direction.
+	mins := decodeContours(encs)
+	if len(mins) < 2 {
+		t.Fatalf("%d stripes, want several", len(mins))
+	}
+	for i := 1; i < len(mins); i++ {
+		if d := mins[i].Y - mins[i-1].Y; d != 2*s.Width {
+			t.Errorf("stripe %d is %v below the previous, want %v", i, d, 2*s.Width)
+		}
+	}
+	if r := float32(math.Hypot(20, 10)); mins[0].Y > 10-r || mins[len(mins)-1].Y+s.Width < 10+r {
+		t.Errorf("stripes span %v to %v, want at least %v to %v", mins[0].Y, mins[len(mins)-1].Y+s.Width, 10-r, 10+r)
+	}
+}
+
+func TestNoiseOp(t *testing.T) {
+	n := NoiseOp{Rect: image.Rect(-10, 0, 30, 20), Scale: 8, Seed: 42, Color1: patternColor1, Color2: patternColor2}
+	noise := func(n NoiseOp) (*image.RGBA, f32.Affine2D) {
+		var o op.Ops
+		n.Add(&o)
+		var img *image.RGBA
+		var tr f32.Affine2D
+		for _, e := range decodeOps(&o) {
+			switch ops.OpType(e.Data[0]) {
+			case ops.TypeImage:
+				img = e.Refs[0].(*image.RGBA)
+				if f := ImageFilter(e.Data[1]); f != FilterLinear {
+					t.Errorf("noise image filter %v, want FilterLinear", f)
+				}
+			case ops.TypeTransform:
+				tr, _ = ops.DecodeTransform(e.Data)
+			}
+		}
+		if img == nil {
+			t.Fatal("no noise image")
+		}
+		return img, tr
+	}
+	img, tr := noise(n)
+	// The lattice spans the rectangle with a margin for interpolation,
+	// from (-2, 0) to (4, 3) inclusive.
+	if got, want := img.Bounds().Size(), image.Pt(7, 4); got != want {
+		t.Errorf("noise image size %v, want %v", got, want)
+	}
+	// Texel centers are at the lattice points.
+	if got, want := tr.Transform(f32.Pt(.5, .5)), f32.Pt(-16, 0); got != want {
+		t.Errorf("first texel center at %v, want %v", got, want)
+	}
+	for y := 0; y < img.Bounds().Dy(); y++ {
+		for x := 0; x < img.Bounds().Dx(); x++ {
+			want := lerpNRGBA(n.Color1, n.Color2, noiseValue(n.Seed, x-2, y))
+			if got := color.NRGBAModel.Convert(img.At(x, y)); got != want {
+				t.Errorf("noise at lattice point (%d, %d) is %v, want %v", x-2, y, got, want)
+			}
+		}
+	}
+	if img2, _ := noise(n); img2 != img {
+		t.Error("equal NoiseOps didn't share the cached image")
+	}
+	n.Seed++
+	if img2, _ := noise(n); reflect.DeepEqual(img2.Pix, img.Pix) {
+		t.Error("different seeds gave equal noise")
+	}
+}
+
+func TestNoiseValue(t *testing.T) {
+	for y := -4; y < 4; y++ {
+		for x := -4; x < 4; x++ {
+			v := noiseValue(1, x, y)
+			if v < 0 || v > 1 {
+				t.Errorf("noise value %v at (%d, %d) is outside [0, 1]", v, x, y)
+			}
+			if v2 := noiseValue(1, x, y); v2 != v {
+				t.Errorf("noise value at (%d, %d) changed from %v to %v", x, y, v, v2)
+			}
+		}
+	}
+}
+
+func TestFloorDiv(t *testing.T) {
+	tests := []struct{ x, y, q int }{
+		{7, 10, 0}, {10, 10, 1}, {0, 10, 0}, {-1, 10, -1}, {-10, 10, -1}, {-11, 10, -2},
+	}
+	for _, tc := range tests {
+		if q := floorDiv(tc.x, tc.y); q != tc.q {
+			t.Errorf("floorDiv(%d, %d) = %d, want %d", tc.x, tc.y, q, tc.q)
+		}
+	}
+}
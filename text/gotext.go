@@ -15,6 +15,7 @@ import (
 	"github.com/go-text/typesetting/language"
 	"github.com/go-text/typesetting/opentype/api"
 	"github.com/go-text/typesetting/opentype/api/metadata"
+	"github.com/go-text/typesetting/opentype/loader"
 	"github.com/go-text/typesetting/shaping"
 	"golang.org/x/exp/slices"
 	"golang.org/x/image/math/fixed"
@@ -43,9 +44,10 @@ type document struct {
 // append adds the lines of other to the end of l and ensures they
 // are aligned to the same width.
 func (l *document) append(other document) {
+	start := len(l.lines)
 	l.lines = append(l.lines, other.lines...)
 	l.alignWidth = max(l.alignWidth, other.alignWidth)
-	calculateYOffsets(l.lines)
+	calculateYOffsets(l.lines, start)
 }
 
 // reset empties the document in preparation to reuse its memory.
@@ -176,6 +178,8 @@ type glyph struct {
 	// bounds describes the visual bounding box of the glyph relative to
 	// its dot.
 	bounds fixed.Rectangle26_6
+	// upright is set for glyphs that stay upright in vertical text.
+	upright bool
 }
 
 type runLayout struct {
@@ -226,9 +230,9 @@ type shaperImpl struct {
 
 	// Scratch buffers used to avoid re-allocating slices during routine internal
 	// shaping operations.
-	splitScratch1, splitScratch2 []shaping.Input
-	outScratchBuf                []shaping.Output
-	scratchRunes                 []rune
+	splitScratch1, splitScratch2, splitScratch3 []shaping.Input
+	outScratchBuf                               []shaping.Output
+	scratchRunes                                []rune
 
 	// bitmapGlyphCache caches extracted bitmap glyph images.
 	bitmapGlyphCache bitmapCache
@@ -341,6 +345,44 @@ func splitByScript(inputs []shaping.Input, documentDir di.Direction, buf []shapi
 	return splitInputs
 }
 
+// verticalFeatures are the OpenType features for glyphs that stay
+// upright in vertical text. They substitute the vertical alternates of
+// glyphs such as U+3001, U+3002 and the small kana, which UAX #50 classes
+// as Tu: upright, but positioned differently than in horizontal text.
+var verticalFeatures = []shaping.FontFeature{
+	{Tag: loader.MustNewTag("vert"), Value: 1},
+	{Tag: loader.MustNewTag("vrt2"), Value: 1},
+}
+
+// splitUpright divides the inputs on the boundaries between runes that
+// stay upright in vertical text and runes that are rotated with their
+// line, and enables verticalFeatures for the upright runs. The rotated
+// runs are shaped like horizontal text, which they are once rotated. It
+// uses buf as the backing memory for the returned slice.
+func splitUpright(inputs []shaping.Input, buf []shaping.Input) []shaping.Input {
+	for _, input := range inputs {
+		if input.RunStart == input.RunEnd {
+			buf = append(buf, input)
+			continue
+		}
+		for start := input.RunStart; start < input.RunEnd; {
+			upright := isUpright(input.Text[start])
+			end := start + 1
+			for end < input.RunEnd && isUpright(input.Text[end]) == upright {
+				end++
+			}
+			run := input
+			run.RunStart, run.RunEnd = start, end
+			if upright {
+				run.FontFeatures = verticalFeatures
+			}
+			buf = append(buf, run)
+			start = end
+		}
+	}
+	return buf
+}
+
 func (s *shaperImpl) splitBidi(input shaping.Input) []shaping.Input {
 	var splitInputs []shaping.Input
 	if input.Direction.Axis() != di.Horizontal || input.RunStart == input.RunEnd {
@@ -406,7 +448,9 @@ func (s *shaperImpl) splitByFaces(inputs []shaping.Input, buf []shaping.Input) [
 
 // shapeText invokes the text shaper and returns the raw text data in the shaper's native
 // format. It does not wrap lines.
-func (s *shaperImpl) shapeText(ppem fixed.Int26_6, lc system.Locale, txt []rune) []shaping.Output {
+// If vertical is set, the runs of runes that stay upright in vertical
+// text are shaped with their vertical alternates.
+func (s *shaperImpl) shapeText(ppem fixed.Int26_6, lc system.Locale, vertical bool, txt []rune) []shaping.Output {
 	lcfg := langConfig{
 		Language:  language.NewLanguage(lc.Language),
 		Direction: mapDirection(lc.Direction),
@@ -423,6 +467,9 @@ func (s *shaperImpl) shapeText(ppem fixed.Int26_6, lc system.Locale, txt []rune)
 	inputs := s.splitBidi(input)
 	inputs = s.splitByFaces(inputs, s.splitScratch1[:0])
 	inputs = splitByScript(inputs, lcfg.Direction, s.splitScratch2[:0])
+	if vertical {
+		inputs = splitUpright(inputs, s.splitScratch3[:0])
+	}
 	// Shape all inputs.
 	if needed := len(inputs) - len(s.outScratchBuf); needed > 0 {
 		s.outScratchBuf = slices.Grow(s.outScratchBuf, needed)
@@ -512,10 +559,10 @@ func (s *shaperImpl) shapeAndWrapText(params Parameters, txt []rune) (_ []shapin
 		}
 		// We only permit a single run as the truncator, regardless of whether more were generated.
 		// Just use the first one.
-		wc.Truncator = s.shapeText(params.PxPerEm, params.Locale, []rune(params.Truncator))[0]
+		wc.Truncator = s.shapeText(params.PxPerEm, params.Locale, params.Vertical, []rune(params.Truncator))[0]
 	}
 	// Wrap outputs into lines.
-	return s.wrapper.WrapParagraph(wc, params.MaxWidth, txt, shaping.NewSliceIterator(s.shapeText(params.PxPerEm, params.Locale, txt)))
+	return s.wrapper.WrapParagraph(wc, params.MaxWidth, txt, shaping.NewSliceIterator(s.shapeText(params.PxPerEm, params.Locale, params.Vertical, txt)))
 }
 
 // replaceControlCharacters replaces problematic unicode
@@ -557,15 +604,23 @@ func (s *shaperImpl) Layout(params Parameters, txt io.RuneReader) document {
 	return s.LayoutRunes(params, s.scratchRunes)
 }
 
-func calculateYOffsets(lines []line) {
-	if len(lines) < 1 {
+// calculateYOffsets computes the baselines of lines[from:], following the
+// baselines of the lines before them. Only computing the new lines keeps
+// appending paragraph by paragraph linear in the number of lines.
+func calculateYOffsets(lines []line, from int) {
+	if len(lines) <= from {
 		return
 	}
-	// Ceil the first value to ensure that we don't baseline it too close to the top of the
-	// viewport and cut off the top pixel.
-	currentY := lines[0].ascent.Ceil()
-	for i := range lines {
-		if i > 0 {
+	var currentY int
+	if from == 0 {
+		// Ceil the first value to ensure that we don't baseline it too close to the top of the
+		// viewport and cut off the top pixel.
+		currentY = lines[0].ascent.Ceil()
+	} else {
+		currentY = lines[from-1].yOffset + lines[from].lineHeight.Round()
+	}
+	for i := from; i < len(lines); i++ {
+		if i > from {
 			currentY += lines[i].lineHeight.Round()
 		}
 		lines[i].yOffset = currentY
@@ -611,6 +666,9 @@ func (s *shaperImpl) LayoutRunes(params Parameters, txt []rune) document {
 				otLine.setTruncatedCount(truncated)
 			}
 		}
+		if params.Vertical {
+			otLine.markUpright(txt)
+		}
 		textLines[i] = otLine
 	}
 	if params.LineHeight != 0 {
@@ -624,7 +682,7 @@ func (s *shaperImpl) LayoutRunes(params Parameters, txt []rune) document {
 	for i := range textLines {
 		textLines[i].lineHeight = maxHeight
 	}
-	calculateYOffsets(textLines)
+	calculateYOffsets(textLines, 0)
 	return document{
 		lines:      textLines,
 		alignment:  params.Alignment,
@@ -838,6 +896,49 @@ func unmapDirection(d di.Direction) system.TextDirection {
 	return system.LTR
 }
 
+// markUpright marks the glyphs of the line that stay upright in vertical
+// text. txt is the text of the paragraph of the line.
+func (l *line) markUpright(txt []rune) {
+	for i := range l.runs {
+		run := &l.runs[i]
+		if run.truncator {
+			continue
+		}
+		for j := range run.Glyphs {
+			g := &run.Glyphs[j]
+			if g.clusterIndex < len(txt) {
+				g.upright = isUpright(txt[g.clusterIndex])
+			}
+		}
+	}
+}
+
+// isUpright reports whether r is set upright in vertical text, following
+// the Vertical_Orientation property of Unicode (UAX #50) for the common
+// cases: the CJK scripts and symbols, and full width forms. Brackets and
+// the long vowel mark are rotated with the line instead.
+func isUpright(r rune) bool {
+	switch {
+	case r >= 0x3008 && r <= 0x3011, r >= 0x3014 && r <= 0x301f, r == 0x3030, r == 0x30fc,
+		r == 0xff08, r == 0xff09, r == 0xff1c, r == 0xff1e, r == 0xff3b, r == 0xff3d,
+		r == 0xff5b, r == 0xff5d, r == 0xff5f, r == 0xff60:
+		return false
+	case r >= 0x1100 && r <= 0x11ff, // Hangul Jamo
+		r >= 0x2e80 && r <= 0xa4cf, // CJK radicals to Yi
+		r >= 0xa960 && r <= 0xa97f, // Hangul Jamo extended A
+		r >= 0xac00 && r <= 0xd7ff, // Hangul syllables
+		r >= 0xf900 && r <= 0xfaff, // CJK compatibility ideographs
+		r >= 0xfe10 && r <= 0xfe1f, // Vertical forms
+		r >= 0xfe30 && r <= 0xfe4f, // CJK compatibility forms
+		r >= 0xff01 && r <= 0xff60, // Full width forms
+		r >= 0xffe0 && r <= 0xffe6,
+		r >= 0x1f000 && r <= 0x1faff, // Tiles, cards and emoji
+		r >= 0x20000 && r <= 0x3fffd: // CJK extensions
+		return true
+	}
+	return false
+}
+
 // toGioGlyphs converts text shaper glyphs into the minimal representation
 // that Gio needs.
 func toGioGlyphs(in []shaping.Glyph, ppem fixed.Int26_6, faceIdx int) []glyph {
@@ -0,0 +1,33 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package text
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-text/typesetting/shaping"
+)
+
+func TestSplitUpright(t *testing.T) {
+	// Brackets are rotated with the line, while the ideographs, the kana
+	// and the ideographic comma and full stop stay upright.
+	txt := []rune("縦「ぁ」、Go。")
+	inputs := []shaping.Input{{Text: txt, RunStart: 0, RunEnd: len(txt)}}
+	type run struct {
+		start, end int
+		upright    bool
+	}
+	want := []run{{0, 1, true}, {1, 2, false}, {2, 3, true}, {3, 4, false}, {4, 5, true}, {5, 7, false}, {7, 8, true}}
+	var got []run
+	for _, in := range splitUpright(inputs, nil) {
+		upright := len(in.FontFeatures) > 0
+		if upright && len(in.FontFeatures) != len(verticalFeatures) {
+			t.Errorf("run %d-%d has features %v", in.RunStart, in.RunEnd, in.FontFeatures)
+		}
+		got = append(got, run{in.RunStart, in.RunEnd, upright})
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("runs %v, want %v", got, want)
+	}
+}
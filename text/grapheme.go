@@ -0,0 +1,113 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package text
+
+import (
+	"strings"
+
+	"github.com/go-text/typesetting/segmenter"
+)
+
+// GraphemeSegmenter finds the boundaries of extended grapheme clusters,
+// the units of text that users perceive as single characters, such as
+// letters with combining marks, flags and emoji ZWJ sequences. Widgets
+// should move carets, select and delete whole clusters.
+//
+// Text is segmented one paragraph at a time, and clusters never span a
+// newline.
+//
+// The zero value is ready to use. Reusing a GraphemeSegmenter avoids
+// allocations.
+type GraphemeSegmenter struct {
+	seg   segmenter.Segmenter
+	runes []rune
+	// offsets are the byte offsets of runes.
+	offsets    []int
+	boundaries []int
+}
+
+// Boundaries appends the byte offsets of the cluster boundaries of s to
+// dst and returns the extended slice. The boundaries include 0 and
+// len(s), unless s is empty.
+func (g *GraphemeSegmenter) Boundaries(dst []int, s string) []int {
+	for off := 0; off < len(s); {
+		end := paragraphEnd(s, off)
+		b := g.paragraph(s[off:end])
+		if off > 0 {
+			// The start of the paragraph ends the previous one.
+			b = b[1:]
+		}
+		for _, b := range b {
+			dst = append(dst, off+b)
+		}
+		off = end
+	}
+	return dst
+}
+
+// Next returns the byte offset of the first cluster boundary after off
+// in s, or len(s) if there is none.
+func (g *GraphemeSegmenter) Next(s string, off int) int {
+	if off >= len(s) {
+		return len(s)
+	}
+	if off < 0 {
+		off = 0
+	}
+	start := strings.LastIndexByte(s[:off], '\n') + 1
+	for _, b := range g.paragraph(s[start:paragraphEnd(s, off)]) {
+		if start+b > off {
+			return start + b
+		}
+	}
+	return len(s)
+}
+
+// Prev returns the byte offset of the last cluster boundary before off
+// in s, or 0 if there is none.
+func (g *GraphemeSegmenter) Prev(s string, off int) int {
+	if off <= 0 {
+		return 0
+	}
+	if off > len(s) {
+		off = len(s)
+	}
+	start := strings.LastIndexByte(s[:off-1], '\n') + 1
+	b := g.paragraph(s[start:paragraphEnd(s, off-1)])
+	for i := len(b) - 1; i >= 0; i-- {
+		if start+b[i] < off {
+			return start + b[i]
+		}
+	}
+	return 0
+}
+
+// paragraph returns the byte offsets of the cluster boundaries of the
+// paragraph p. The result is valid until the next call.
+func (g *GraphemeSegmenter) paragraph(p string) []int {
+	g.runes = g.runes[:0]
+	g.offsets = g.offsets[:0]
+	for off, r := range p {
+		g.runes = append(g.runes, r)
+		g.offsets = append(g.offsets, off)
+	}
+	g.boundaries = g.boundaries[:0]
+	g.seg.Init(g.runes)
+	iter := g.seg.GraphemeIterator()
+	for iter.Next() {
+		g.boundaries = append(g.boundaries, g.offsets[iter.Grapheme().Offset])
+	}
+	if len(p) > 0 {
+		g.boundaries = append(g.boundaries, len(p))
+	}
+	return g.boundaries
+}
+
+// paragraphEnd returns the byte offset following the paragraph of s
+// containing off.
+func paragraphEnd(s string, off int) int {
+	if i := strings.IndexByte(s[off:], '\n'); i != -1 {
+		return off + i + 1
+	}
+	return len(s)
+}
@@ -0,0 +1,50 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package text
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGraphemeSegmenter(t *testing.T) {
+	const (
+		family = "\U0001F468\u200D\U0001F469\u200D\U0001F467" // ZWJ sequence.
+		flag   = "\U0001F1E9\U0001F1F0"                       // Regional indicator pair.
+		accent = "e\u0301"                                    // Combining acute accent.
+	)
+	s := "a" + family + flag + accent + "\r\n" + "b"
+	want := []int{
+		0,
+		1,
+		1 + len(family),
+		1 + len(family) + len(flag),
+		1 + len(family) + len(flag) + len(accent),
+		1 + len(family) + len(flag) + len(accent) + 2,
+		len(s),
+	}
+	var g GraphemeSegmenter
+	if got := g.Boundaries(nil, s); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Boundaries(%q) = %v, want %v", s, got, want)
+	}
+	if got := g.Boundaries(nil, ""); len(got) != 0 {
+		t.Errorf("Boundaries of empty text = %v, want none", got)
+	}
+	for i := 0; i < len(want)-1; i++ {
+		// Move from every byte of the cluster.
+		for off := want[i]; off < want[i+1]; off++ {
+			if got := g.Next(s, off); got != want[i+1] {
+				t.Errorf("Next(%d) = %d, want %d", off, got, want[i+1])
+			}
+			if got := g.Prev(s, off+1); got != want[i] {
+				t.Errorf("Prev(%d) = %d, want %d", off+1, got, want[i])
+			}
+		}
+	}
+	if got := g.Next(s, len(s)); got != len(s) {
+		t.Errorf("Next at end = %d, want %d", got, len(s))
+	}
+	if got := g.Prev(s, 0); got != 0 {
+		t.Errorf("Prev at start = %d, want 0", got)
+	}
+}
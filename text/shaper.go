@@ -72,6 +72,14 @@ type Parameters struct {
 	// should set LineHeightScale to 1.
 	LineHeight fixed.Int26_6
 
+	// Vertical prepares the text for vertical layout, where the lines are
+	// rotated clockwise into columns running right to left. The text is
+	// still shaped and wrapped horizontally, with MaxWidth as the height of
+	// the columns, but the glyphs of scripts set upright in vertical text,
+	// such as CJK ideographs and kana, are marked with FlagUpright and
+	// shaped with the vertical alternates of the font.
+	Vertical bool
+
 	// forceTruncate controls whether the truncator string is inserted on the final line of
 	// text with a MaxLines. It is unexported because this behavior only makes sense for the
 	// shaper to control when it iterates paragraphs of text.
@@ -155,6 +163,11 @@ const (
 	// FlagTruncator and FlagClusterBreak will have a Runes field accounting for all
 	// runes truncated.
 	FlagTruncator
+	// FlagUpright is set for glyphs of text shaped with Parameters.Vertical
+	// that stay upright when their line is rotated into a column. Such
+	// glyphs must be rotated back around the center of their logical
+	// bounds when painted.
+	FlagUpright
 )
 
 func (f Flags) String() string {
@@ -194,6 +207,11 @@ func (f Flags) String() string {
 	} else {
 		b.WriteString("_")
 	}
+	if f&FlagUpright != 0 {
+		b.WriteString("U")
+	} else {
+		b.WriteString("_")
+	}
 	return b.String()
 }
 
@@ -390,6 +408,7 @@ func (l *Shaper) layoutParagraph(params Parameters, asStr string, asBytes []byte
 		str:             asStr,
 		lineHeight:      params.LineHeight,
 		lineHeightScale: params.LineHeightScale,
+		vertical:        params.Vertical,
 	}
 	if l, ok := l.layoutCache.Get(lk); ok {
 		return l
@@ -479,6 +498,9 @@ func (l *Shaper) NextGlyph() (_ Glyph, ok bool) {
 		if run.truncator {
 			glyph.Flags |= FlagTruncator
 		}
+		if g.upright {
+			glyph.Flags |= FlagUpright
+		}
 		l.glyph++
 		if !rtl {
 			l.advance += g.xAdvance
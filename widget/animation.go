@@ -0,0 +1,234 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package widget
+
+import (
+	"image/color"
+	"time"
+
+	"github.com/Seikaijyu/gio/internal/f32color"
+	"github.com/Seikaijyu/gio/layout"
+	"github.com/Seikaijyu/gio/op"
+)
+
+// Easing maps the elapsed fraction t of a transition, in the [0; 1]
+// range, to the fraction of the change of its value.
+type Easing func(t float32) float32
+
+// Linear is the Easing of transitions at constant speed.
+func Linear(t float32) float32 {
+	return t
+}
+
+// EaseInOut is the Easing of transitions that start and end slowly.
+func EaseInOut(t float32) float32 {
+	return t * t * (3 - 2*t)
+}
+
+// EaseOut is the Easing of transitions that start fast and end
+// slowly.
+func EaseOut(t float32) float32 {
+	return 1 - (1-t)*(1-t)
+}
+
+// AnimatedFloat is a value that transitions smoothly to its target,
+// for example the opacity of a hover highlight. The zero value has no
+// transitions.
+type AnimatedFloat struct {
+	// Duration of the transitions.
+	Duration time.Duration
+	// Easing of the transitions. A nil Easing means EaseInOut.
+	Easing Easing
+
+	from, to float32
+	valid    bool
+	trans    transition
+}
+
+// AnimatedColor is a color that transitions smoothly to its target, for
+// example the background of a selected item. Colors are interpolated
+// in linear color space. The zero value has no transitions.
+type AnimatedColor struct {
+	// Duration of the transitions.
+	Duration time.Duration
+	// Easing of the transitions. A nil Easing means EaseInOut.
+	Easing Easing
+
+	from, to color.NRGBA
+	valid    bool
+	trans    transition
+}
+
+// transition tracks the progress of a transition.
+type transition struct {
+	start  time.Time
+	active bool
+}
+
+// Update starts a transition to target if it differs from the current
+// target, and returns the current value. The first Update sets the
+// value without a transition.
+func (a *AnimatedFloat) Update(gtx layout.Context, target float32) float32 {
+	if !a.valid {
+		a.from, a.to, a.valid = target, target, true
+	}
+	if target != a.to {
+		a.from = a.Value(gtx)
+		a.to = target
+		a.trans.restart(gtx)
+	}
+	return a.Value(gtx)
+}
+
+// Value returns the current value. The frame is invalidated while a
+// transition is in progress.
+func (a *AnimatedFloat) Value(gtx layout.Context) float32 {
+	t := a.trans.progress(gtx, a.Duration, a.Easing)
+	return a.from + (a.to-a.from)*t
+}
+
+// Animating reports whether a transition is in progress.
+func (a *AnimatedFloat) Animating() bool {
+	return a.trans.active
+}
+
+// Update starts a transition to target if it differs from the current
+// target, and returns the current color. The first Update sets the
+// color without a transition.
+func (a *AnimatedColor) Update(gtx layout.Context, target color.NRGBA) color.NRGBA {
+	if !a.valid {
+		a.from, a.to, a.valid = target, target, true
+	}
+	if target != a.to {
+		a.from = a.Value(gtx)
+		a.to = target
+		a.trans.restart(gtx)
+	}
+	return a.Value(gtx)
+}
+
+// Value returns the current color. The frame is invalidated while a
+// transition is in progress.
+func (a *AnimatedColor) Value(gtx layout.Context) color.NRGBA {
+	t := a.trans.progress(gtx, a.Duration, a.Easing)
+	switch t {
+	case 0:
+		return a.from
+	case 1:
+		return a.to
+	}
+	from, to := f32color.LinearFromSRGB(a.from), f32color.LinearFromSRGB(a.to)
+	return f32color.RGBA{
+		R: from.R + (to.R-from.R)*t,
+		G: from.G + (to.G-from.G)*t,
+		B: from.B + (to.B-from.B)*t,
+		A: from.A + (to.A-from.A)*t,
+	}.SRGB()
+}
+
+// Animating reports whether a transition is in progress.
+func (a *AnimatedColor) Animating() bool {
+	return a.trans.active
+}
+
+func (t *transition) restart(gtx layout.Context) {
+	t.start = gtx.Now
+	t.active = true
+}
+
+// progress returns the eased progress of the transition, and
+// invalidates the frame if the transition is not done.
+func (t *transition) progress(gtx layout.Context, d time.Duration, easing Easing) float32 {
+	if !t.active {
+		return 1
+	}
+	elapsed := gtx.Now.Sub(t.start)
+	if d <= 0 || elapsed >= d {
+		t.active = false
+		return 1
+	}
+	op.InvalidateOp{}.Add(gtx.Ops)
+	if elapsed <= 0 {
+		return 0
+	}
+	if easing == nil {
+		easing = EaseInOut
+	}
+	return easing(float32(elapsed) / float32(d))
+}
+
+// FixedStep runs animations that advance in discrete steps, such as
+// simulations, at a fixed rate of wall-clock time. Unlike stepping
+// once per frame, the speed of the animation doesn't depend on the
+// display refresh rate, and dropped frames are caught up. The zero
+// value steps 60 times per second.
+type FixedStep struct {
+	// Rate is the number of steps per second. A zero Rate means 60.
+	Rate int
+
+	last    time.Time
+	running bool
+}
+
+// maxCatchUp bounds the steps caught up in a single frame, so an
+// animation resumed after a long pause doesn't run all the steps it
+// missed.
+const maxCatchUp = time.Second
+
+// Steps returns the number of steps due since the previous call, and
+// invalidates the frame. The first call after Stop starts the
+// animation and returns zero.
+func (s *FixedStep) Steps(gtx layout.Context) int {
+	op.InvalidateOp{}.Add(gtx.Ops)
+	if !s.running {
+		s.last = gtx.Now
+		s.running = true
+		return 0
+	}
+	elapsed := gtx.Now.Sub(s.last)
+	if elapsed <= 0 {
+		return 0
+	}
+	if elapsed > maxCatchUp {
+		s.last = gtx.Now.Add(-maxCatchUp)
+		elapsed = maxCatchUp
+	}
+	step := s.Step()
+	n := int(elapsed / step)
+	// Carry the remainder over to the next frame.
+	s.last = s.last.Add(time.Duration(n) * step)
+	return n
+}
+
+// Fraction returns the progress towards the next step in the [0; 1)
+// range, for interpolating between the two most recent steps.
+func (s *FixedStep) Fraction(gtx layout.Context) float32 {
+	if !s.running {
+		return 0
+	}
+	elapsed := gtx.Now.Sub(s.last)
+	step := s.Step()
+	if elapsed <= 0 || elapsed >= step {
+		return 0
+	}
+	return float32(elapsed) / float32(step)
+}
+
+// Step returns the duration of a step.
+func (s *FixedStep) Step() time.Duration {
+	rate := s.Rate
+	if rate <= 0 {
+		rate = 60
+	}
+	return time.Second / time.Duration(rate)
+}
+
+// Stop stops the animation.
+func (s *FixedStep) Stop() {
+	s.running = false
+}
+
+// Running reports whether the animation is running.
+func (s *FixedStep) Running() bool {
+	return s.running
+}
@@ -0,0 +1,91 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package widget
+
+import (
+	"image/color"
+	"testing"
+	"time"
+
+	"github.com/Seikaijyu/gio/layout"
+	"github.com/Seikaijyu/gio/op"
+)
+
+func TestAnimatedFloat(t *testing.T) {
+	gtx := layout.Context{Ops: new(op.Ops), Now: time.Unix(0, 0)}
+	a := &AnimatedFloat{Duration: time.Second, Easing: Linear}
+	if v := a.Update(gtx, 1); v != 1 || a.Animating() {
+		t.Errorf("initial value %v, animating %v", v, a.Animating())
+	}
+	a.Update(gtx, 3)
+	gtx.Now = gtx.Now.Add(time.Second / 2)
+	if v := a.Update(gtx, 3); v != 2 || !a.Animating() {
+		t.Errorf("value %v halfway, animating %v", v, a.Animating())
+	}
+	// Retargeting starts from the current value.
+	a.Update(gtx, 0)
+	gtx.Now = gtx.Now.Add(time.Second / 2)
+	if v := a.Value(gtx); v != 1 {
+		t.Errorf("value %v halfway after retarget", v)
+	}
+	gtx.Now = gtx.Now.Add(time.Second)
+	if v := a.Value(gtx); v != 0 || a.Animating() {
+		t.Errorf("final value %v, animating %v", v, a.Animating())
+	}
+}
+
+func TestAnimatedColor(t *testing.T) {
+	gtx := layout.Context{Ops: new(op.Ops), Now: time.Unix(0, 0)}
+	black := color.NRGBA{A: 0xff}
+	white := color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	a := &AnimatedColor{Duration: time.Second}
+	a.Update(gtx, black)
+	a.Update(gtx, white)
+	if c := a.Value(gtx); c != black {
+		t.Errorf("color %v at start, want %v", c, black)
+	}
+	gtx.Now = gtx.Now.Add(time.Second / 2)
+	if c := a.Value(gtx); c == black || c == white {
+		t.Errorf("color %v halfway", c)
+	}
+	gtx.Now = gtx.Now.Add(time.Second)
+	if c := a.Value(gtx); c != white {
+		t.Errorf("color %v at end, want %v", c, white)
+	}
+}
+
+func TestFixedStep(t *testing.T) {
+	gtx := layout.Context{Ops: new(op.Ops), Now: time.Unix(0, 0)}
+	s := &FixedStep{Rate: 100}
+	if n := s.Steps(gtx); n != 0 || !s.Running() {
+		t.Errorf("%d steps at start, running %v", n, s.Running())
+	}
+	// A 144Hz display advances as many steps per second as a 60Hz one.
+	total := 0
+	for i := 0; i < 144; i++ {
+		gtx.Now = gtx.Now.Add(time.Second / 144)
+		total += s.Steps(gtx)
+	}
+	if total < 99 || total > 100 {
+		t.Errorf("%d steps in a second of 144Hz frames, want 100", total)
+	}
+	// Dropped frames are caught up.
+	gtx.Now = gtx.Now.Add(time.Second / 4)
+	if n := s.Steps(gtx); n < 25 || n > 26 {
+		t.Errorf("%d steps after a dropped quarter second, want 25", n)
+	}
+	// Long pauses are not.
+	gtx.Now = gtx.Now.Add(time.Minute)
+	if n := s.Steps(gtx); n != 100 {
+		t.Errorf("%d steps after a pause, want 100", n)
+	}
+	gtx.Now = gtx.Now.Add(s.Step() / 2)
+	if f := s.Fraction(gtx); f != .5 {
+		t.Errorf("fraction %v halfway to the next step", f)
+	}
+	s.Stop()
+	gtx.Now = gtx.Now.Add(time.Second)
+	if n := s.Steps(gtx); n != 0 {
+		t.Errorf("%d steps after restart", n)
+	}
+}
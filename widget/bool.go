@@ -32,6 +32,11 @@ func (b *Bool) Hovered() bool {
 }
 
 // Pressed reports whether pointer is pressing the element.
+func (b *Bool) Pressed() bool {
+	return b.clk.Pressed()
+}
+
+// OnChecked sets a function to call when Value is changed by a click.
 func (b *Bool) OnChecked(fn func(bool)) {
 	b._checked = fn
 }
@@ -3,29 +3,54 @@
 package widget
 
 import (
+	"bytes"
 	"io"
 	"unicode/utf8"
 
+	"golang.org/x/exp/slices"
 	"golang.org/x/text/runes"
 )
 
-// editBuffer implements a gap buffer for text editing.
+// editBuffer implements a piece table for text editing. The text is a
+// sequence of pieces, each referring to a span of an append-only buffer,
+// so edits never move the existing text regardless of the size of the
+// document.
 type editBuffer struct {
-	// pos is the byte position for Read and ReadRune.
-	pos int
-
-	// The gap start and end in bytes.
-	gapstart, gapend int
-	text             []byte
+	// buf holds the inserted text. It is only ever appended to,
+	// except during compaction.
+	buf []byte
+	// pieces are the spans of buf making up the text, in order.
+	pieces []piece
+	// size is the length of the text in bytes.
+	size int
+
+	// lastPiece and lastOff cache the index and text offset of the most
+	// recently accessed piece, to make sequential reads cheap.
+	lastPiece, lastOff int
 
 	// changed tracks whether the buffer content
 	// has changed since the last call to Changed.
 	changed bool
 }
 
+// piece is the span [start, end) of an editBuffer's buf.
+type piece struct {
+	start, end int
+}
+
 var _ textSource = (*editBuffer)(nil)
 
-const minSpace = 5
+const (
+	// minCompactSize is the buffer size below which the buffer is never
+	// compacted.
+	minCompactSize = 64 << 10
+	// maxPieces is the number of pieces that triggers compaction.
+	maxPieces = 4096
+)
+
+func (p piece) len() int {
+	return p.end - p.start
+}
 
 func (e *editBuffer) Changed() bool {
 	c := e.changed
@@ -33,99 +58,174 @@ func (e *editBuffer) Changed() bool {
 	return c
 }
 
-func (e *editBuffer) deleteRunes(caret, count int) (bytes int, runes int) {
-	e.moveGap(caret, 0)
-	for ; count < 0 && e.gapstart > 0; count++ {
-		_, s := utf8.DecodeLastRune(e.text[:e.gapstart])
-		e.gapstart -= s
-		bytes += s
-		runes++
-		e.changed = e.changed || s > 0
-	}
-	for ; count > 0 && e.gapend < len(e.text); count-- {
-		_, s := utf8.DecodeRune(e.text[e.gapend:])
-		e.gapend += s
-		e.changed = e.changed || s > 0
-	}
-	return
+func (e *editBuffer) Size() int64 {
+	return int64(e.size)
 }
 
-// moveGap moves the gap to the caret position. After returning,
-// the gap is guaranteed to be at least space bytes long.
-func (e *editBuffer) moveGap(caret, space int) {
-	if e.gapLen() < space {
-		if space < minSpace {
-			space = minSpace
-		}
-		txt := make([]byte, int(e.Size())+space)
-		// Expand to capacity.
-		txt = txt[:cap(txt)]
-		gaplen := len(txt) - int(e.Size())
-		if caret > e.gapstart {
-			copy(txt, e.text[:e.gapstart])
-			copy(txt[caret+gaplen:], e.text[caret:])
-			copy(txt[e.gapstart:], e.text[e.gapend:caret+e.gapLen()])
-		} else {
-			copy(txt, e.text[:caret])
-			copy(txt[e.gapstart+gaplen:], e.text[e.gapend:])
-			copy(txt[caret+gaplen:], e.text[caret:e.gapstart])
-		}
-		e.text = txt
-		e.gapstart = caret
-		e.gapend = e.gapstart + gaplen
-	} else {
-		if caret > e.gapstart {
-			copy(e.text[e.gapstart:], e.text[e.gapend:caret+e.gapLen()])
-		} else {
-			copy(e.text[caret+e.gapLen():], e.text[caret:e.gapstart])
+// find returns the index and text offset of the piece containing the
+// byte offset off. If off is at or past the end of the text, find
+// returns len(e.pieces) and the size of the text.
+func (e *editBuffer) find(off int) (int, int) {
+	if off >= e.size {
+		return len(e.pieces), e.size
+	}
+	i, start := 0, 0
+	if e.lastPiece < len(e.pieces) && e.lastOff <= off {
+		i, start = e.lastPiece, e.lastOff
+	}
+	for ; i < len(e.pieces); i++ {
+		if end := start + e.pieces[i].len(); off < end {
+			break
 		}
-		l := e.gapLen()
-		e.gapstart = caret
-		e.gapend = e.gapstart + l
+		start += e.pieces[i].len()
 	}
+	e.lastPiece, e.lastOff = i, start
+	return i, start
 }
 
-func (e *editBuffer) Size() int64 {
-	return int64(len(e.text) - e.gapLen())
-}
-
-func (e *editBuffer) gapLen() int {
-	return e.gapend - e.gapstart
+// split ensures a piece starts at the byte offset off, and returns its
+// index. If off is at the end of the text, split returns len(e.pieces).
+func (e *editBuffer) split(off int) int {
+	i, start := e.find(off)
+	if i == len(e.pieces) || start == off {
+		return i
+	}
+	p := e.pieces[i]
+	mid := p.start + off - start
+	e.pieces[i].end = mid
+	e.pieces = slices.Insert(e.pieces, i+1, piece{start: mid, end: p.end})
+	return i + 1
 }
 
 func (e *editBuffer) ReadAt(p []byte, offset int64) (int, error) {
 	if len(p) == 0 {
 		return 0, nil
 	}
-	if offset == e.Size() {
+	if offset >= int64(e.size) {
 		return 0, io.EOF
 	}
+	off := int(offset)
+	i, start := e.find(off)
 	var total int
-	if offset < int64(e.gapstart) {
-		n := copy(p, e.text[offset:e.gapstart])
+	for ; i < len(e.pieces) && len(p) > 0; i++ {
+		pc := e.pieces[i]
+		n := copy(p, e.buf[pc.start+off-start:pc.end])
 		p = p[n:]
 		total += n
-		offset += int64(n)
+		off += n
+		start += pc.len()
 	}
-	if offset >= int64(e.gapstart) {
-		n := copy(p, e.text[offset+int64(e.gapLen()):])
-		total += n
+	if len(p) > 0 {
+		// Short reads are at the end of the text.
+		return total, io.EOF
 	}
 	return total, nil
 }
 
+// readFrom replaces the text with the content of r, read directly into
+// a new buffer. The text is unchanged if reading fails.
+func (e *editBuffer) readFrom(r io.Reader) (int64, error) {
+	var b bytes.Buffer
+	n, err := b.ReadFrom(r)
+	if err != nil {
+		return n, err
+	}
+	buf := b.Bytes()
+	if !utf8.Valid(buf) {
+		buf = runes.ReplaceIllFormed().Bytes(buf)
+	}
+	e.buf = buf
+	e.pieces = e.pieces[:0]
+	if len(buf) > 0 {
+		e.pieces = append(e.pieces, piece{start: 0, end: len(buf)})
+	}
+	e.size = len(buf)
+	e.lastPiece, e.lastOff = 0, 0
+	e.changed = true
+	return n, nil
+}
+
+// runeBytes returns the length in bytes of the count runes following the
+// byte offset off, or of the remaining text if it's shorter.
+func (e *editBuffer) runeBytes(off, count int) int {
+	i, start := e.find(off)
+	n := 0
+	for ; count > 0 && i < len(e.pieces); i++ {
+		pc := e.pieces[i]
+		b := e.buf[pc.start+off+n-start : pc.end]
+		// Pieces always start and end at rune boundaries.
+		for ; count > 0 && len(b) > 0; count-- {
+			_, s := utf8.DecodeRune(b)
+			b = b[s:]
+			n += s
+		}
+		start += pc.len()
+	}
+	return n
+}
+
 func (e *editBuffer) ReplaceRunes(byteOffset, runeCount int64, s string) {
-	e.deleteRunes(int(byteOffset), int(runeCount))
-	e.prepend(int(byteOffset), s)
+	off := int(byteOffset)
+	if runeCount > 0 {
+		e.delete(off, e.runeBytes(off, int(runeCount)))
+	}
+	e.insert(off, s)
+	e.compact()
+}
+
+// delete removes n bytes starting at off.
+func (e *editBuffer) delete(off, n int) {
+	if n == 0 {
+		return
+	}
+	i := e.split(off)
+	j := e.split(off + n)
+	e.pieces = slices.Delete(e.pieces, i, j)
+	e.size -= n
+	e.lastPiece, e.lastOff = 0, 0
+	e.changed = true
 }
 
-func (e *editBuffer) prepend(caret int, s string) {
+// insert inserts s at the byte offset off.
+func (e *editBuffer) insert(off int, s string) {
+	if len(s) == 0 {
+		return
+	}
 	if !utf8.ValidString(s) {
 		s = runes.ReplaceIllFormed().String(s)
 	}
+	start := len(e.buf)
+	e.buf = append(e.buf, s...)
+	e.size += len(s)
+	e.changed = true
+	i := e.split(off)
+	// Extend the preceding piece if it ends where s was appended, which is
+	// the common case of typing.
+	if i > 0 && e.pieces[i-1].end == start {
+		e.pieces[i-1].end += len(s)
+	} else {
+		e.pieces = slices.Insert(e.pieces, i, piece{start: start, end: start + len(s)})
+	}
+	e.lastPiece, e.lastOff = 0, 0
+}
 
-	e.moveGap(caret, len(s))
-	copy(e.text[caret:], s)
-	e.gapstart += len(s)
-	e.changed = e.changed || len(s) > 0
+// compact copies the text into a fresh buffer when the buffer is mostly
+// deleted text, or the text is split into too many pieces.
+func (e *editBuffer) compact() {
+	if e.size == 0 {
+		e.buf = e.buf[:0]
+		e.pieces = e.pieces[:0]
+		return
+	}
+	wasted := len(e.buf) > minCompactSize && len(e.buf) > 2*e.size
+	if !wasted && len(e.pieces) <= maxPieces {
+		return
+	}
+	buf := make([]byte, 0, e.size+e.size/2)
+	for _, p := range e.pieces {
+		buf = append(buf, e.buf[p.start:p.end]...)
+	}
+	e.buf = buf
+	e.pieces = append(e.pieces[:0], piece{start: 0, end: len(buf)})
+	e.lastPiece, e.lastOff = 0, 0
 }
@@ -0,0 +1,100 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package widget
+
+import (
+	"io"
+	"math/rand"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestEditBuffer(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	words := []string{"", "a", "héllo", "世界", "\n", "tab\tand\nnewline", "🚀"}
+	b := new(editBuffer)
+	var want []rune
+	for i := 0; i < 2000; i++ {
+		start := r.Intn(len(want) + 1)
+		count := r.Intn(len(want) - start + 1)
+		if count > 8 {
+			count = r.Intn(8)
+		}
+		s := words[r.Intn(len(words))]
+		off := len(string(want[:start]))
+		b.ReplaceRunes(int64(off), int64(count), s)
+		want = append(want[:start], append([]rune(s), want[start+count:]...)...)
+
+		if got, exp := b.Size(), int64(len(string(want))); got != exp {
+			t.Fatalf("edit %d: size %d, want %d", i, got, exp)
+		}
+		got, err := io.ReadAll(io.NewSectionReader(b, 0, b.Size()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != string(want) {
+			t.Fatalf("edit %d: text %q, want %q", i, got, string(want))
+		}
+	}
+	// Read across piece boundaries one rune at a time.
+	var got []rune
+	for off := int64(0); off < b.Size(); {
+		var buf [utf8.UTFMax]byte
+		n, _ := b.ReadAt(buf[:], off)
+		r, s := utf8.DecodeRune(buf[:n])
+		got = append(got, r)
+		off += int64(s)
+	}
+	if string(got) != string(want) {
+		t.Errorf("rune reads %q, want %q", string(got), string(want))
+	}
+	if _, err := b.ReadAt(make([]byte, 1), b.Size()); err != io.EOF {
+		t.Errorf("read at end returned %v, want EOF", err)
+	}
+}
+
+func TestEditBufferCompaction(t *testing.T) {
+	b := new(editBuffer)
+	line := strings.Repeat("x", 1023) + "\n"
+	for i := 0; i < 1000; i++ {
+		b.ReplaceRunes(0, int64(b.Size()), line)
+	}
+	if len(b.buf) > 2*minCompactSize {
+		t.Errorf("buffer grew to %d bytes for %d bytes of text", len(b.buf), b.Size())
+	}
+	b.ReplaceRunes(0, int64(b.Size()), "")
+	if len(b.buf) != 0 || len(b.pieces) != 0 {
+		t.Errorf("empty buffer retains %d bytes in %d pieces", len(b.buf), len(b.pieces))
+	}
+}
+
+// BenchmarkEditBufferInsert measures typing at random positions in a
+// large document.
+func BenchmarkEditBufferInsert(b *testing.B) {
+	buf := new(editBuffer)
+	buf.ReplaceRunes(0, 0, largeText(8<<20))
+	r := rand.New(rand.NewSource(42))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		// Type a few runes at a random position.
+		off := int64(r.Intn(int(buf.Size())/4) * 4)
+		for j := int64(0); j < 4; j++ {
+			buf.ReplaceRunes(off+j, 0, "a")
+		}
+	}
+}
+
+// largeText returns a document of roughly size bytes of ASCII
+// paragraphs.
+func largeText(size int) string {
+	var sb strings.Builder
+	line := "The quick brown fox jumps over the lazy dog. "
+	for i := 0; sb.Len() < size; i++ {
+		sb.WriteString(line)
+		if i%4 == 3 {
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
@@ -17,6 +17,11 @@ import (
 
 // Clickable represents a clickable area.
 type Clickable struct {
+	// Shortcut, if set, clicks the element when its keys are pressed
+	// and the focused widget doesn't handle them, such as "Short-S"
+	// for a save button. See key.ShortcutOp.
+	Shortcut key.Set
+
 	click gesture.Click
 	// clicks is for saved clicks to support Clicked.
 	clicks  []Click
@@ -27,6 +32,7 @@ type Clickable struct {
 	requestClicks int
 	focused       bool
 	pressedKey    string
+	shortcutTag   struct{}
 }
 
 // Click represents a click.
@@ -110,6 +116,9 @@ func (b *Clickable) Layout(gtx layout.Context, w layout.Widget) layout.Dimension
 			keys = ""
 		}
 		key.InputOp{Tag: &b.keyTag, Keys: keys}.Add(gtx.Ops)
+		if b.Shortcut != "" {
+			key.ShortcutOp{Tag: &b.shortcutTag, Keys: b.Shortcut}.Add(gtx.Ops)
+		}
 	}
 	c.Add(gtx.Ops)
 	return dims
@@ -141,6 +150,7 @@ func (b *Clickable) Update(gtx layout.Context) []Click {
 			NumClicks: c,
 		})
 	}
+	b.click.DoubleClickDuration = gtx.Gestures.DoubleClick
 	for _, e := range b.click.Update(gtx) {
 		switch e.Kind {
 		case gesture.KindClick:
@@ -198,5 +208,13 @@ func (b *Clickable) Update(gtx layout.Context) []Click {
 			}
 		}
 	}
+	for _, e := range gtx.Events(&b.shortcutTag) {
+		if e, ok := e.(key.Event); ok && e.State == key.Press {
+			clicks = append(clicks, Click{
+				Modifiers: e.Modifiers,
+				NumClicks: 1,
+			})
+		}
+	}
 	return clicks
 }
@@ -0,0 +1,367 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package widget
+
+import (
+	"fmt"
+	"html"
+	"image/color"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Seikaijyu/gio/font"
+	"github.com/Seikaijyu/gio/io/clipboard"
+)
+
+// A ClipboardSanitizer converts rich clipboard content of a MIME type,
+// such as text/html, to the plain text pasted into an Editor. It returns
+// false to ignore the content.
+type ClipboardSanitizer func(mime, data string) (string, bool)
+
+// richPasteTypes are the clipboard types requested by an Editor with a
+// Sanitizer, in order of preference.
+var richPasteTypes = []string{"text/html", "text/rtf"}
+
+// SanitizeRichText is a ClipboardSanitizer that converts text/html with
+// HTMLToText and text/rtf with RTFToText.
+func SanitizeRichText(mime, data string) (string, bool) {
+	switch mime {
+	case "text/html":
+		return HTMLToText(data), true
+	case "text/rtf":
+		return RTFToText(data), true
+	}
+	return "", false
+}
+
+// HTMLToText returns the text of an HTML document or fragment. Tags,
+// comments, scripts and styles are removed, whitespace is collapsed
+// outside of pre elements, block elements such as p and li start new
+// lines, and character references are unescaped.
+func HTMLToText(s string) string {
+	var b strings.Builder
+	pre := 0
+	space := false
+	newline := func() {
+		if b.Len() > 0 && !strings.HasSuffix(b.String(), "\n") {
+			b.WriteByte('\n')
+		}
+		space = false
+	}
+	for s != "" {
+		if strings.HasPrefix(s, "<!--") {
+			end := strings.Index(s, "-->")
+			if end == -1 {
+				break
+			}
+			s = s[end+len("-->"):]
+			continue
+		}
+		if s[0] != '<' {
+			end := strings.IndexByte(s, '<')
+			if end == -1 {
+				end = len(s)
+			}
+			txt := html.UnescapeString(s[:end])
+			s = s[end:]
+			if pre > 0 {
+				b.WriteString(txt)
+				continue
+			}
+			for _, r := range txt {
+				if unicode.IsSpace(r) && r != ' ' {
+					space = true
+					continue
+				}
+				if space && b.Len() > 0 && !strings.HasSuffix(b.String(), "\n") {
+					b.WriteByte(' ')
+				}
+				space = false
+				b.WriteRune(r)
+			}
+			continue
+		}
+		end := strings.IndexByte(s, '>')
+		if end == -1 {
+			break
+		}
+		tag := s[1:end]
+		s = s[end+1:]
+		closing := strings.HasPrefix(tag, "/")
+		tag = strings.TrimPrefix(tag, "/")
+		name := tag
+		if i := strings.IndexFunc(tag, func(r rune) bool { return unicode.IsSpace(r) || r == '/' }); i != -1 {
+			name = tag[:i]
+		}
+		name = strings.ToLower(name)
+		switch name {
+		case "script", "style", "head", "title":
+			if closing {
+				break
+			}
+			// Skip the content of the element.
+			endTag := "</" + name
+			i := strings.Index(strings.ToLower(s), endTag)
+			if i == -1 {
+				return strings.TrimRight(b.String(), "\n")
+			}
+			s = s[i:]
+		case "br":
+			b.WriteByte('\n')
+			space = false
+		case "pre":
+			newline()
+			if closing {
+				pre = max(pre-1, 0)
+			} else {
+				pre++
+			}
+		case "p", "div", "li", "ul", "ol", "tr", "table", "blockquote",
+			"h1", "h2", "h3", "h4", "h5", "h6", "hr":
+			newline()
+		case "td", "th":
+			if !closing {
+				space = true
+			}
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// RTFToText returns the text of an RTF document. Control words other than
+// paragraph, line and tab breaks are removed, as are destinations such as
+// font and color tables. Unicode and hexadecimal characters are decoded,
+// the latter as Windows-1252.
+func RTFToText(s string) string {
+	var b strings.Builder
+	// skips tracks for every open group whether its text is skipped.
+	skips := []bool{false}
+	skip := func() bool { return skips[len(skips)-1] }
+	// uc is the number of fallback characters that follow \u.
+	uc, fallback := 1, 0
+	emit := func(r rune) {
+		if fallback > 0 {
+			fallback--
+			return
+		}
+		if !skip() {
+			b.WriteRune(r)
+		}
+	}
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch c {
+		case '{':
+			skips = append(skips, skip())
+			i++
+			continue
+		case '}':
+			if len(skips) > 1 {
+				skips = skips[:len(skips)-1]
+			}
+			i++
+			continue
+		case '\r', '\n':
+			i++
+			continue
+		case '\\':
+		default:
+			r, n := utf8.DecodeRuneInString(s[i:])
+			emit(r)
+			i += n
+			continue
+		}
+		i++
+		if i == len(s) {
+			break
+		}
+		switch c := s[i]; {
+		case c == '\\' || c == '{' || c == '}':
+			emit(rune(c))
+			i++
+		case c == '~':
+			emit(' ')
+			i++
+		case c == '*':
+			skips[len(skips)-1] = true
+			i++
+		case c == '\'':
+			if i+3 <= len(s) {
+				if v, err := strconv.ParseUint(s[i+1:i+3], 16, 8); err == nil {
+					emit(decodeWindows1252(byte(v)))
+				}
+			}
+			i += 3
+		case c == '\r' || c == '\n':
+			emit('\n')
+			i++
+		case 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z':
+			start := i
+			for i < len(s) && ('a' <= s[i] && s[i] <= 'z' || 'A' <= s[i] && s[i] <= 'Z') {
+				i++
+			}
+			word := s[start:i]
+			start = i
+			if i < len(s) && s[i] == '-' {
+				i++
+			}
+			for i < len(s) && '0' <= s[i] && s[i] <= '9' {
+				i++
+			}
+			param, hasParam := 0, i > start
+			if hasParam {
+				param, _ = strconv.Atoi(s[start:i])
+			}
+			// A space delimits the control word.
+			if i < len(s) && s[i] == ' ' {
+				i++
+			}
+			switch word {
+			case "par", "line", "row":
+				emit('\n')
+			case "tab", "cell":
+				emit('\t')
+			case "uc":
+				uc = param
+			case "u":
+				if param < 0 {
+					param += 0x10000
+				}
+				emit(rune(param))
+				fallback = uc
+			case "fonttbl", "colortbl", "stylesheet", "info", "pict",
+				"header", "footer", "footnote", "object", "themedata",
+				"listtable", "listoverridetable", "rsidtbl", "generator":
+				skips[len(skips)-1] = true
+			}
+		default:
+			i++
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// decodeWindows1252 decodes a byte of the Windows-1252 encoding, the
+// encoding of hexadecimal characters in most RTF documents.
+func decodeWindows1252(c byte) rune {
+	if c < 0x80 || c >= 0xa0 {
+		return rune(c)
+	}
+	const table = "€�‚ƒ„…†‡ˆ‰Š‹Œ�Ž��‘’“”•–—˜™š›œ�žŸ"
+	return []rune(table)[c-0x80]
+}
+
+// pasteText returns the text to paste from a clipboard event, preferring
+// rich content converted by the Sanitizer.
+func (e *Editor) pasteText(ev clipboard.Event) string {
+	if e.Sanitizer == nil {
+		return ev.Text
+	}
+	for _, t := range richPasteTypes {
+		for _, c := range ev.Content {
+			if c.Type != t {
+				continue
+			}
+			if s, ok := e.Sanitizer(c.Type, c.Data); ok {
+				return s
+			}
+		}
+	}
+	return ev.Text
+}
+
+// styledContent returns the selection styled by the spans of Highlight
+// as text/html, or nil if the text is not highlighted or more than one
+// caret has a selection.
+func (e *Editor) styledContent() []clipboard.Content {
+	if e.Highlight == nil || !e.spansValid || e.Mask != 0 || len(e.spans) == 0 {
+		return nil
+	}
+	var sel Caret
+	for _, c := range e.Carets() {
+		if c.Start == c.End {
+			continue
+		}
+		if sel.Start != sel.End {
+			return nil
+		}
+		sel = c
+	}
+	start, end := min(sel.Start, sel.End), max(sel.Start, sel.End)
+	if start == end {
+		return nil
+	}
+	bstart, bend := e.text.ByteOffset(start), e.text.ByteOffset(end)
+	buf := make([]byte, bend-bstart)
+	n, _ := e.text.ReadAt(buf, bstart)
+	return []clipboard.Content{{
+		Type: "text/html",
+		Data: spansHTML(string(buf[:n]), start, e.spans),
+	}}
+}
+
+// spansHTML formats txt, starting at rune offset start of the text styled
+// by spans, as a pre element of styled span elements.
+func spansHTML(txt string, start int, spans []TextSpan) string {
+	var b strings.Builder
+	b.WriteString("<pre>")
+	for txt != "" {
+		for len(spans) > 0 && spans[0].End <= start {
+			spans = spans[1:]
+		}
+		// n is the number of runes of txt with the same style.
+		n := len(txt)
+		var style string
+		switch {
+		case len(spans) == 0:
+		case spans[0].Start > start:
+			n = spans[0].Start - start
+		default:
+			n = spans[0].End - start
+			style = spanStyle(spans[0])
+		}
+		end := len(txt)
+		for i := range txt {
+			if n == 0 {
+				end = i
+				break
+			}
+			n--
+		}
+		if style != "" {
+			fmt.Fprintf(&b, `<span style="%s">`, style)
+		}
+		b.WriteString(html.EscapeString(txt[:end]))
+		if style != "" {
+			b.WriteString("</span>")
+		}
+		start += utf8.RuneCountInString(txt[:end])
+		txt = txt[end:]
+	}
+	b.WriteString("</pre>")
+	return b.String()
+}
+
+// spanStyle returns the CSS declarations of the style of s.
+func spanStyle(s TextSpan) string {
+	var decls []string
+	if s.Color != (color.NRGBA{}) {
+		decls = append(decls, "color:"+cssColor(s.Color))
+	}
+	if s.Background.A > 0 {
+		decls = append(decls, "background-color:"+cssColor(s.Background))
+	}
+	if s.Weight > font.Normal {
+		decls = append(decls, fmt.Sprintf("font-weight:%d", 400+int(s.Weight)))
+	}
+	return strings.Join(decls, ";")
+}
+
+func cssColor(c color.NRGBA) string {
+	if c.A == 0xff {
+		return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+	}
+	return fmt.Sprintf("rgba(%d,%d,%d,%.3g)", c.R, c.G, c.B, float32(c.A)/0xff)
+}
@@ -0,0 +1,130 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package widget
+
+import (
+	"image"
+	"image/color"
+	"reflect"
+	"testing"
+
+	"github.com/Seikaijyu/gio/font"
+	"github.com/Seikaijyu/gio/font/gofont"
+	"github.com/Seikaijyu/gio/io/clipboard"
+	"github.com/Seikaijyu/gio/io/key"
+	"github.com/Seikaijyu/gio/io/router"
+	"github.com/Seikaijyu/gio/layout"
+	"github.com/Seikaijyu/gio/op"
+	"github.com/Seikaijyu/gio/text"
+)
+
+func TestHTMLToText(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"plain", "plain"},
+		{"<b>bold</b> &amp; <i>italic</i>", "bold & italic"},
+		{"<p>one</p><p>two<br>three</p>", "one\ntwo\nthree"},
+		{"<ul>\n  <li>a</li>\n  <li>b</li>\n</ul>", "a\nb"},
+		{"<style>p { color: red }</style><script>x()</script>text", "text"},
+		{"a<!-- comment -->b", "ab"},
+		{"<pre>x  =\n  1</pre>", "x  =\n  1"},
+	}
+	for _, test := range tests {
+		if got := HTMLToText(test.in); got != test.want {
+			t.Errorf("HTMLToText(%q) = %q, want %q", test.in, got, test.want)
+		}
+	}
+}
+
+func TestRTFToText(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{`{\rtf1\ansi{\fonttbl{\f0 Arial;}}{\colortbl;\red255\green0\blue0;}\f0 Hello\par World}`, "Hello\nWorld"},
+		{`{\rtf1 a\tab b\line c}`, "a\tb\nc"},
+		{`{\rtf1 caf\'e9 \'80}`, "café €"},
+		{`{\rtf1\uc1 \u20320?\u22909?}`, "你好"},
+		{`{\rtf1{\*\generator Writer;}text \{\}}`, "text {}"},
+	}
+	for _, test := range tests {
+		if got := RTFToText(test.in); got != test.want {
+			t.Errorf("RTFToText(%q) = %q, want %q", test.in, got, test.want)
+		}
+	}
+}
+
+func TestEditorRichClipboard(t *testing.T) {
+	lt := text.NewShaper(text.NoSystemFonts(), text.WithCollection(gofont.Collection()))
+	var (
+		ops op.Ops
+		r   router.Router
+	)
+	e := &Editor{
+		Sanitizer: SanitizeRichText,
+		Highlight: func(txt string, spans []TextSpan) []TextSpan {
+			return append(spans, TextSpan{
+				Start: 0, End: 4,
+				Color:  color.NRGBA{R: 0xff, A: 0xff},
+				Weight: font.Bold,
+			})
+		},
+	}
+	e.Focus()
+	frame := func() {
+		layoutFrame(&r, &ops, image.Pt(300, 100), func(gtx layout.Context) {
+			e.Layout(gtx, lt, font.Font{}, 14, op.CallOp{}, op.CallOp{})
+		})
+	}
+	frame()
+	frame()
+	r.Queue(key.Event{Name: "V", Modifiers: key.ModShortcut, State: key.Press})
+	frame()
+	if !r.ReadClipboard() {
+		t.Fatal("paste didn't read the clipboard")
+	}
+	if got := r.ClipboardTypes(); !reflect.DeepEqual(got, richPasteTypes) {
+		t.Errorf("paste requested types %v, want %v", got, richPasteTypes)
+	}
+	r.Queue(clipboard.Event{
+		Text: "plain text",
+		Content: []clipboard.Content{
+			{Type: "text/rtf", Data: `{\rtf1 rtf text}`},
+			{Type: "text/html", Data: "<b>html</b> <i>text</i>"},
+		},
+	})
+	frame()
+	if got, want := e.Text(), "html text"; got != want {
+		t.Errorf("pasted %q, want %q", got, want)
+	}
+
+	e.SetCaret(0, e.Len())
+	frame()
+	r.Queue(key.Event{Name: "C", Modifiers: key.ModShortcut, State: key.Press})
+	frame()
+	if txt, ok := r.WriteClipboard(); !ok || txt != "html text" {
+		t.Fatalf("copied %q, %v", txt, ok)
+	}
+	content := r.ClipboardContent()
+	want := `<pre><span style="color:#ff0000;font-weight:700">html</span> text</pre>`
+	if len(content) != 1 || content[0].Type != "text/html" || content[0].Data != want {
+		t.Errorf("copied content %v, want text/html %q", content, want)
+	}
+
+	// Without a Sanitizer, paste ignores rich content.
+	e.Sanitizer = nil
+	e.SetText("")
+	r.Queue(key.Event{Name: "V", Modifiers: key.ModShortcut, State: key.Press})
+	frame()
+	if got := r.ClipboardTypes(); len(got) > 0 {
+		t.Errorf("paste without a Sanitizer requested types %v", got)
+	}
+	r.Queue(clipboard.Event{
+		Text:    "plain text",
+		Content: []clipboard.Content{{Type: "text/html", Data: "<b>html</b>"}},
+	})
+	frame()
+	if got, want := e.Text(), "plain text"; got != want {
+		t.Errorf("pasted %q without a Sanitizer, want %q", got, want)
+	}
+}
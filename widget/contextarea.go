@@ -0,0 +1,136 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package widget
+
+import (
+	"image"
+
+	"github.com/Seikaijyu/gio/gesture"
+	"github.com/Seikaijyu/gio/io/key"
+	"github.com/Seikaijyu/gio/io/pointer"
+	"github.com/Seikaijyu/gio/layout"
+	"github.com/Seikaijyu/gio/op"
+	"github.com/Seikaijyu/gio/op/clip"
+)
+
+// ContextArea opens a context menu when the user clicks a widget with
+// the secondary mouse button, or long presses it on a touch screen. The
+// menu is drawn above all other content at the position of the click,
+// and dismissed by a press outside the menu, by the escape key or by
+// Dismiss.
+//
+// The menu is kept inside the visible area of the ContextArea as
+// reported by layout.Context.Visible, which is unbounded when the
+// ContextArea is laid out in a recorded macro, such as by a List.
+type ContextArea struct {
+	// Popup positions the menu next to the position of the click. The
+	// zero value places the menu below and to the right of the click,
+	// moved or flipped to stay inside the visible area. The focus moves
+	// to the menu when it opens and returns when it is dismissed.
+	Popup layout.Popup
+
+	click gesture.Click
+	long  gesture.LongPress
+	// pos is the position of the click that opened the menu.
+	pos    image.Point
+	active bool
+	// focus is set to focus the menu in the next Layout.
+	focus bool
+	// menuTag is the tag of the menu, for the escape key and to keep
+	// presses in the menu from dismissing it.
+	menuTag struct{}
+}
+
+// dismissBounds is the extent of the area that dismisses an active
+// ContextArea when pressed. It covers any window.
+const dismissBounds = 1 << 24
+
+// Update the state of the area, and report whether its menu is open.
+func (c *ContextArea) Update(gtx layout.Context) bool {
+	for _, e := range c.click.Update(gtx) {
+		if e.Kind == gesture.KindPress && e.Source == pointer.Mouse && e.Button == pointer.ButtonSecondary {
+			c.open(e.Position)
+		}
+	}
+	for _, e := range c.long.Update(gtx.Metric, gtx, gtx.Now) {
+		if e.Kind == gesture.LongPressTriggered && e.Source != pointer.Mouse {
+			c.open(e.Position)
+		}
+	}
+	for _, e := range gtx.Events(c) {
+		if e, ok := e.(pointer.Event); ok && e.Kind == pointer.Press {
+			c.Dismiss()
+		}
+	}
+	for _, e := range gtx.Events(&c.menuTag) {
+		if e, ok := e.(key.Event); ok && e.Name == key.NameEscape && e.State == key.Press {
+			c.Dismiss()
+		}
+	}
+	return c.active
+}
+
+// open the menu at pos.
+func (c *ContextArea) open(pos image.Point) {
+	c.pos, c.active, c.focus = pos, true, true
+}
+
+// Active reports whether the menu is open.
+func (c *ContextArea) Active() bool {
+	return c.active
+}
+
+// Dismiss closes the menu, such as after the user chose an item.
+func (c *ContextArea) Dismiss() {
+	c.active, c.focus = false, false
+}
+
+// Layout the widget w, and the menu over all other content while it is
+// open.
+func (c *ContextArea) Layout(gtx layout.Context, w, menu layout.Widget) layout.Dimensions {
+	c.Update(gtx)
+	m := op.Record(gtx.Ops)
+	dims := w(gtx)
+	call := m.Stop()
+	area := clip.Rect(image.Rectangle{Max: dims.Size}).Push(gtx.Ops)
+	c.click.Buttons = pointer.ButtonSecondary
+	c.click.Add(gtx.Ops)
+	c.long.Add(gtx.Ops)
+	call.Add(gtx.Ops)
+	area.Pop()
+	if c.active {
+		c.layoutMenu(gtx, menu)
+	}
+	return dims
+}
+
+// layoutMenu lays out the open menu, and the area that dismisses it
+// below the menu.
+func (c *ContextArea) layoutMenu(gtx layout.Context, menu layout.Widget) {
+	m := op.Record(gtx.Ops)
+	d := clip.Rect(image.Rect(-dismissBounds, -dismissBounds, dismissBounds, dismissBounds)).Push(gtx.Ops)
+	pointer.InputOp{Tag: c, Kinds: pointer.Press}.Add(gtx.Ops)
+	d.Pop()
+	op.Defer(gtx.Ops, m.Stop())
+
+	p := c.Popup
+	if p.FocusScope == nil {
+		p.FocusScope = &c.menuTag
+	}
+	bounds, _ := gtx.Visible()
+	anchor := image.Rectangle{Min: c.pos, Max: c.pos}
+	p.Layout(gtx, anchor, bounds, func(gtx layout.Context) layout.Dimensions {
+		m := op.Record(gtx.Ops)
+		dims := menu(gtx)
+		call := m.Stop()
+		defer clip.Rect(image.Rectangle{Max: dims.Size}).Push(gtx.Ops).Pop()
+		pointer.InputOp{Tag: &c.menuTag, Kinds: pointer.Press}.Add(gtx.Ops)
+		key.InputOp{Tag: &c.menuTag, Keys: key.NameEscape}.Add(gtx.Ops)
+		if c.focus {
+			c.focus = false
+			key.FocusOp{Tag: &c.menuTag}.Add(gtx.Ops)
+		}
+		call.Add(gtx.Ops)
+		return dims
+	})
+}
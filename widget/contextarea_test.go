@@ -0,0 +1,84 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package widget
+
+import (
+	"image"
+	"testing"
+	"time"
+
+	"github.com/Seikaijyu/gio/f32"
+	"github.com/Seikaijyu/gio/io/key"
+	"github.com/Seikaijyu/gio/io/pointer"
+	"github.com/Seikaijyu/gio/io/router"
+	"github.com/Seikaijyu/gio/layout"
+	"github.com/Seikaijyu/gio/op"
+)
+
+func TestContextArea(t *testing.T) {
+	var (
+		ops  op.Ops
+		r    router.Router
+		area ContextArea
+	)
+	now := time.Now()
+	menuShown := false
+	frame := func() {
+		menuShown = false
+		layoutFrame(&r, &ops, image.Pt(200, 100), func(gtx layout.Context) {
+			gtx.Now = now
+			area.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return layout.Dimensions{Size: image.Pt(100, 100)}
+			}, func(gtx layout.Context) layout.Dimensions {
+				menuShown = true
+				return layout.Dimensions{Size: image.Pt(50, 40)}
+			})
+		})
+	}
+	press := func(btn pointer.Buttons, src pointer.Source, x, y float32) {
+		r.Queue(
+			pointer.Event{Kind: pointer.Press, Source: src, Buttons: btn, Position: f32.Pt(x, y)},
+			pointer.Event{Kind: pointer.Release, Source: src, Position: f32.Pt(x, y)},
+		)
+	}
+	frame()
+	press(pointer.ButtonPrimary, pointer.Mouse, 10, 10)
+	frame()
+	if area.Active() {
+		t.Fatal("primary click opened the menu")
+	}
+	press(pointer.ButtonSecondary, pointer.Mouse, 80, 90)
+	frame()
+	if !area.Active() || !menuShown {
+		t.Fatal("secondary click didn't open the menu")
+	}
+	// The menu is moved inside the window.
+	r.Queue(pointer.Event{Kind: pointer.Move, Source: pointer.Mouse, Position: f32.Pt(100, 50)})
+	press(pointer.ButtonPrimary, pointer.Mouse, 100, 70)
+	frame()
+	if !area.Active() {
+		t.Error("press inside the menu dismissed it")
+	}
+	press(pointer.ButtonPrimary, pointer.Mouse, 180, 10)
+	frame()
+	if area.Active() {
+		t.Error("press outside the menu didn't dismiss it")
+	}
+
+	// A long press opens the menu, and escape dismisses it.
+	r.Queue(pointer.Event{Kind: pointer.Press, Source: pointer.Touch, Position: f32.Pt(20, 20)})
+	frame()
+	now = now.Add(time.Second)
+	frame()
+	if !area.Active() {
+		t.Fatal("long press didn't open the menu")
+	}
+	r.Queue(pointer.Event{Kind: pointer.Release, Source: pointer.Touch, Position: f32.Pt(20, 20)})
+	frame()
+	frame()
+	r.Queue(key.Event{Name: key.NameEscape, State: key.Press})
+	frame()
+	if area.Active() {
+		t.Error("escape didn't dismiss the menu")
+	}
+}
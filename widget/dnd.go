@@ -56,6 +56,7 @@ func (d *Draggable) Dragging() bool {
 // requested to offer data, if any
 func (d *Draggable) Update(gtx layout.Context) (mime string, requested bool) {
 	pos := d.pos
+	d.drag.MouseSlop = gtx.Gestures.DragThreshold
 	for _, ev := range d.drag.Update(gtx.Metric, gtx.Queue, gesture.Both) {
 		switch ev.Kind {
 		case pointer.Press:
@@ -0,0 +1,111 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package widget
+
+// Draft implements apply and cancel semantics for forms such as
+// settings dialogs. It binds input widgets to committed values: the
+// widgets hold the draft being edited, while the values stay unchanged
+// until Commit copies the draft to them. Revert discards the draft by
+// loading the committed values into the widgets.
+//
+// A widget is loaded with its committed value when first bound. Binding
+// it again, such as in every layout, only updates the value it is bound
+// to.
+//
+// The zero value is ready to use.
+type Draft struct {
+	fields []draftField
+}
+
+type draftField struct {
+	// widget is the bound widget pointer.
+	widget interface{}
+	commit func()
+	revert func()
+	dirty  func() bool
+}
+
+// Editor binds an Editor to a committed text value.
+func (d *Draft) Editor(e *Editor, value *string) {
+	d.bind(draftField{
+		widget: e,
+		commit: func() { *value = e.Text() },
+		revert: func() { e.SetText(*value) },
+		dirty:  func() bool { return e.Text() != *value },
+	})
+}
+
+// Bool binds a Bool to a committed value.
+func (d *Draft) Bool(b *Bool, value *bool) {
+	d.bind(draftField{
+		widget: b,
+		commit: func() { *value = b.Value },
+		revert: func() { b.Value = *value },
+		dirty:  func() bool { return b.Value != *value },
+	})
+}
+
+// Float binds a Float to a committed value.
+func (d *Draft) Float(f *Float, value *float32) {
+	d.bind(draftField{
+		widget: f,
+		commit: func() { *value = f.Value },
+		revert: func() { f.Value = *value },
+		dirty:  func() bool { return f.Value != *value },
+	})
+}
+
+// Enum binds an Enum to a committed value.
+func (d *Draft) Enum(e *Enum, value *string) {
+	d.bind(draftField{
+		widget: e,
+		commit: func() { *value = e.Value },
+		revert: func() { e.Value = *value },
+		dirty:  func() bool { return e.Value != *value },
+	})
+}
+
+func (d *Draft) bind(f draftField) {
+	for i := range d.fields {
+		if d.fields[i].widget == f.widget {
+			d.fields[i] = f
+			return
+		}
+	}
+	d.fields = append(d.fields, f)
+	f.revert()
+}
+
+// Dirty reports whether any bound widget differs from its committed
+// value.
+func (d *Draft) Dirty() bool {
+	for _, f := range d.fields {
+		if f.dirty() {
+			return true
+		}
+	}
+	return false
+}
+
+// Commit copies the state of the bound widgets to their committed
+// values.
+func (d *Draft) Commit() {
+	for _, f := range d.fields {
+		f.commit()
+	}
+}
+
+// Revert loads the committed values into the bound widgets, discarding
+// their edits.
+func (d *Draft) Revert() {
+	for _, f := range d.fields {
+		if f.dirty() {
+			f.revert()
+		}
+	}
+}
+
+// Reset unbinds all widgets.
+func (d *Draft) Reset() {
+	d.fields = d.fields[:0]
+}
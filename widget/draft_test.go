@@ -0,0 +1,53 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package widget
+
+import "testing"
+
+func TestDraft(t *testing.T) {
+	settings := struct {
+		Name    string
+		Enabled bool
+		Volume  float32
+		Mode    string
+	}{"gio", true, .5, "dark"}
+	var (
+		d       Draft
+		name    Editor
+		enabled Bool
+		volume  Float
+		mode    Enum
+	)
+	bind := func() {
+		d.Editor(&name, &settings.Name)
+		d.Bool(&enabled, &settings.Enabled)
+		d.Float(&volume, &settings.Volume)
+		d.Enum(&mode, &settings.Mode)
+	}
+	bind()
+	if name.Text() != "gio" || !enabled.Value || volume.Value != .5 || mode.Value != "dark" {
+		t.Fatal("widgets not loaded with the committed values")
+	}
+	if d.Dirty() {
+		t.Error("draft dirty after binding")
+	}
+
+	name.SetText("draft")
+	enabled.Value = false
+	// Binding again keeps the edits.
+	bind()
+	if !d.Dirty() || name.Text() != "draft" {
+		t.Fatal("edits lost")
+	}
+	d.Revert()
+	if d.Dirty() || name.Text() != "gio" || !enabled.Value {
+		t.Error("edits not reverted")
+	}
+
+	volume.Value = 1
+	mode.Value = "light"
+	d.Commit()
+	if d.Dirty() || settings.Volume != 1 || settings.Mode != "light" || settings.Name != "gio" {
+		t.Errorf("edits not committed: %+v", settings)
+	}
+}
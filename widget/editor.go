@@ -7,6 +7,7 @@ import (
 	"image"
 	"io"
 	"math"
+	"sort"
 	"strings"
 	"time"
 	"unicode"
@@ -24,8 +25,10 @@ import (
 	"github.com/Seikaijyu/gio/layout"
 	"github.com/Seikaijyu/gio/op"
 	"github.com/Seikaijyu/gio/op/clip"
+	"github.com/Seikaijyu/gio/op/paint"
 	"github.com/Seikaijyu/gio/text"
 	"github.com/Seikaijyu/gio/unit"
+	"golang.org/x/exp/slices"
 )
 
 // Editor implements an editable and scrollable text area.
@@ -49,6 +52,11 @@ type Editor struct {
 	// user interaction. If set to true, the editor will allow selecting text
 	// and copying it interactively, but not modifying it.
 	ReadOnly bool
+	// MultiCaret enables editing with several carets: Short-D selects the
+	// next occurrence of the selection, alt-clicks add carets, alt-shift
+	// drags select rectangles, and escape returns to a single caret.
+	// AddCaret and SelectNextOccurrence work regardless.
+	MultiCaret bool
 	// Submit enabled translation of carriage return keys to SubmitEvents.
 	// If not enabled, carriage returns are inserted as newlines in the text.
 	Submit bool
@@ -63,13 +71,68 @@ type Editor struct {
 	// Filter is the list of characters allowed in the Editor. If Filter is empty,
 	// all characters are allowed.
 	Filter string
+	// FilterFunc, if set, transforms or rejects every edit of the text,
+	// including by SetText, after MaxLen and Filter are applied. Undo and
+	// redo restore the filtered text.
+	FilterFunc InputFilter
+	// Sanitizer, if set, makes paste prefer the text/html and text/rtf
+	// content of the clipboard, converted to plain text by Sanitizer.
+	// Paste falls back to the plain text of the clipboard if there is no
+	// such content or Sanitizer rejects it. SanitizeRichText is a
+	// Sanitizer for both types.
+	Sanitizer ClipboardSanitizer
 	// WrapPolicy configures how displayed text will be broken into lines.
 	WrapPolicy text.WrapPolicy
+	// NoWrap disables wrapping lines. Long lines scroll horizontally
+	// instead.
+	NoWrap bool
+	// WrapIndent indents the continuation lines of wrapped lines. The
+	// width of lines is reduced by the indentation.
+	WrapIndent unit.Sp
+	// Vertical lays out the text in columns from top to bottom, running
+	// from right to left, like Label.Vertical. The up and down arrows
+	// move the caret along a column, and the left and right arrows to
+	// the next and previous column. Vertical is meant for reading, such
+	// as with ReadOnly; input methods are not positioned for vertical
+	// text.
+	Vertical bool
+	// Gutter, if set, lays out the gutter of a visible line of the text,
+	// such as its line number or a breakpoint marker. The gutter is a
+	// column GutterWidth wide on the leading side of the text, scrolled
+	// with it. Gutter is called for every line, with the constraints of
+	// the gutter of the line.
+	Gutter func(gtx layout.Context, line EditorLine) layout.Dimensions
+	// GutterWidth is the width of the gutter.
+	GutterWidth unit.Dp
+	// Highlight, if set, returns the styled spans of the text, such as
+	// for syntax highlighting. Layout calls it after every change to the
+	// text, with the spans of the previous call for reuse. The spans
+	// must be sorted by Start and not overlap.
+	Highlight func(text string, spans []TextSpan) []TextSpan
+	// TouchSelection configures the handles and the loupe for adjusting
+	// selections by touch.
+	TouchSelection TouchSelection
 
 	buffer *editBuffer
+	// spans are the spans returned by Highlight, valid until the text
+	// changes.
+	spans      []TextSpan
+	spansValid bool
+	// version counts the changes of the text.
+	version int
+	// semantic is the value described to screen readers, valid while
+	// the version of the text and the mask match.
+	semantic struct {
+		value   string
+		version int
+		mask    rune
+		valid   bool
+	}
 	// scratch is a byte buffer that is reused to efficiently read portions of text
 	// from the textView.
-	scratch      []byte
+	scratch []byte
+	// graphemes segments inserted text.
+	graphemes    text.GraphemeSegmenter
 	eventKey     int
 	blinkStart   time.Time
 	focused      bool
@@ -84,10 +147,25 @@ type Editor struct {
 	dragging    bool
 	dragger     gesture.Drag
 	scroller    gesture.Scroll
+	hscroller   gesture.Scroll
 	scrollCaret bool
 	showCaret   bool
+	// primaryPending is set when the selection changed since it was
+	// last copied to the primary selection.
+	primaryPending bool
+	// boxSelecting is set while dragging a rectangular selection from
+	// boxFrom, in document coordinates.
+	boxSelecting bool
+	boxFrom      image.Point
+	// clicks is the number of clicks of the last multi-click.
+	clicks int
+	// textOffset is the offset of the text from the origin of the
+	// editor, past the gutter.
+	textOffset image.Point
 
 	clicker gesture.Click
+	// handles adjust selections made by touch.
+	handles selectionHandles
 
 	// events is the list of events not yet processed.
 	events []EditorEvent
@@ -99,6 +177,34 @@ type Editor struct {
 	// is only not len(history) immediately after undo operations occur. It is framed as the "next" value
 	// to make the zero value consistent.
 	nextHistoryIdx int
+	// batching is set while an edit applies at every caret, and chained
+	// once it recorded a modification. Chained modifications are undone
+	// and redone together.
+	batching, chained bool
+}
+
+// EditorLine describes a visible line of the text of an Editor, for
+// laying out its gutter.
+type EditorLine struct {
+	// Number is the number of the line, starting at 1. The continuation
+	// lines of a wrapped line have its number.
+	Number int
+	// Continued is set for the continuation lines of a wrapped line.
+	Continued bool
+	// Start is the offset of the start of the line, in runes.
+	Start int
+	// Caret is set for the line of the caret.
+	Caret bool
+	// Baseline is the distance from the top of the gutter of the line to
+	// the baseline of the line.
+	Baseline int
+}
+
+// Caret is a caret of an Editor and its selection, as rune offsets.
+// Start is the position of the caret, and End the other end of the
+// selection. Start can be > End.
+type Caret struct {
+	Start, End int
 }
 
 type offEntry struct {
@@ -113,6 +219,9 @@ type imeState struct {
 	}
 	snippet    key.Snippet
 	start, end int
+	// compose is the composing region of the input method, in text
+	// order. It is empty when there is no composition.
+	compose key.Range
 }
 
 type maskReader struct {
@@ -169,8 +278,12 @@ type EditorEvent interface {
 	isEditorEvent()
 }
 
-// A ChangeEvent is generated for every user change to the text.
-type ChangeEvent struct{}
+// A ChangeEvent is generated for every change to the text.
+type ChangeEvent struct {
+	// Programmatic is set for changes made by calling methods such as
+	// SetText, Insert or Delete, and not by the user.
+	Programmatic bool
+}
 
 // A SubmitEvent is generated when Submit is set
 // and a carriage return key is pressed.
@@ -184,7 +297,15 @@ type SubmitEvent struct {
 // it could be a relatively expensive operation (for a large editor), most
 // applications won't actually care about it, and those that do can call
 // Editor.SelectedText() (which can be empty).
-type SelectEvent struct{}
+type SelectEvent struct {
+	// NumClicks is the number of clicks of the multi-click that made
+	// the selection, 2 for a double click selecting a word and 3 or more
+	// for a triple click. It is zero for other selections.
+	NumClicks int
+	// Programmatic is set for selections made by Selectable.SetSelection
+	// and Selectable.SelectAll, and not by the user.
+	Programmatic bool
+}
 
 const (
 	blinksPerSecond  = 1
@@ -206,12 +327,29 @@ func (e *Editor) processEvents(gtx layout.Context) {
 	e.prevEvents = n
 
 	oldStart, oldLen := min(e.text.Selection()), e.text.SelectionLen()
+	e.clicks = 0
 	e.processPointer(gtx)
 	e.processKey(gtx)
 	// Queue a SelectEvent if the selection changed, including if it went away.
 	if newStart, newLen := min(e.text.Selection()), e.text.SelectionLen(); oldStart != newStart || oldLen != newLen {
-		e.events = append(e.events, SelectEvent{})
+		e.events = append(e.events, SelectEvent{NumClicks: e.clicks})
+		e.primaryPending = true
 	}
+	e.writePrimary(gtx)
+}
+
+// writePrimary copies a new selection to the primary selection once
+// the user is done selecting. Masked text is never copied.
+func (e *Editor) writePrimary(gtx layout.Context) {
+	if !e.primaryPending || e.dragging {
+		return
+	}
+	e.primaryPending = false
+	if e.Mask != 0 || e.text.SelectionLen() == 0 {
+		return
+	}
+	e.scratch = e.text.SelectedText(e.scratch)
+	clipboard.WriteOp{Text: string(e.scratch), Primary: true}.Add(gtx.Ops)
 }
 
 func (e *Editor) processPointer(gtx layout.Context) {
@@ -225,6 +363,10 @@ func (e *Editor) processPointer(gtx layout.Context) {
 		axis = gesture.Vertical
 		smin, smax = sbounds.Min.Y, sbounds.Max.Y
 	}
+	if e.handles.update(gtx, &e.text) {
+		e.blinkStart = gtx.Now
+		e.scrollCaret = true
+	}
 	sdist := e.scroller.Update(gtx.Metric, gtx, gtx.Now, axis)
 	var soff int
 	if e.SingleLine {
@@ -233,14 +375,41 @@ func (e *Editor) processPointer(gtx layout.Context) {
 	} else {
 		e.text.ScrollRel(0, sdist)
 		soff = e.text.ScrollOff().Y
+		if e.NoWrap {
+			e.text.ScrollRel(e.hscroller.Update(gtx.Metric, gtx, gtx.Now, gesture.Horizontal), 0)
+		}
 	}
 	for _, evt := range e.clickDragEvents(gtx) {
 		switch evt := evt.(type) {
 		case gesture.ClickEvent:
 			switch {
+			case evt.Kind == gesture.KindPress && evt.Source == pointer.Mouse && e.MultiCaret && evt.Modifiers == key.ModAlt|key.ModShift:
+				// Select a rectangle of text.
+				e.blinkStart = gtx.Now
+				pos := image.Pt(int(math.Round(float64(evt.Position.X))), int(math.Round(float64(evt.Position.Y))))
+				e.boxFrom = pos.Add(e.text.ScrollOff())
+				e.text.SelectBox(e.boxFrom, e.boxFrom)
+				e.requestFocus = true
+				e.dragging = true
+				e.boxSelecting = true
+				e.handles.active = false
+			case evt.Kind == gesture.KindPress && evt.Source == pointer.Mouse && e.MultiCaret && evt.Modifiers == key.ModAlt:
+				// Add a caret, and keep the previous one.
+				prev := e.text.caret
+				e.blinkStart = gtx.Now
+				e.text.MoveCoord(image.Point{
+					X: int(math.Round(float64(evt.Position.X))),
+					Y: int(math.Round(float64(evt.Position.Y))),
+				})
+				e.text.ClearSelection()
+				e.text.AddCaret(prev.start, prev.end)
+				e.requestFocus = true
+				e.dragging = true
+				e.handles.active = false
 			case evt.Kind == gesture.KindPress && evt.Source == pointer.Mouse,
 				evt.Kind == gesture.KindClick && evt.Source != pointer.Mouse:
 				prevCaretPos, _ := e.text.Selection()
+				e.text.ClearCarets()
 				e.blinkStart = gtx.Now
 				e.text.MoveCoord(image.Point{
 					X: int(math.Round(float64(evt.Position.X))),
@@ -262,8 +431,10 @@ func (e *Editor) processPointer(gtx layout.Context) {
 					e.text.ClearSelection()
 				}
 				e.dragging = true
+				e.handles.active = evt.Source != pointer.Mouse
 
 				// Process multi-clicks.
+				e.clicks = evt.NumClicks
 				switch {
 				case evt.NumClicks == 2:
 					e.text.MoveWord(-1, selectionClear)
@@ -275,6 +446,13 @@ func (e *Editor) processPointer(gtx layout.Context) {
 					e.dragging = false
 				}
 			}
+		case gesture.LongPressEvent:
+			if evt.Kind == gesture.LongPressTriggered && evt.Source != pointer.Mouse {
+				e.blinkStart = gtx.Now
+				e.handles.selectWord(&e.text, evt.Position)
+				e.requestFocus = true
+				e.dragging = false
+			}
 		case pointer.Event:
 			release := false
 			switch {
@@ -284,14 +462,21 @@ func (e *Editor) processPointer(gtx layout.Context) {
 			case evt.Kind == pointer.Drag && evt.Source == pointer.Mouse:
 				if e.dragging {
 					e.blinkStart = gtx.Now
-					e.text.MoveCoord(image.Point{
+					pos := image.Point{
 						X: int(math.Round(float64(evt.Position.X))),
 						Y: int(math.Round(float64(evt.Position.Y))),
-					})
+					}
+					if e.boxSelecting {
+						e.text.SelectBox(e.boxFrom, pos.Add(e.text.ScrollOff()))
+					} else {
+						e.text.MoveCoord(pos)
+						e.text.mergeCarets()
+					}
 					e.scrollCaret = true
 
 					if release {
 						e.dragging = false
+						e.boxSelecting = false
 					}
 				}
 			}
@@ -305,18 +490,24 @@ func (e *Editor) processPointer(gtx layout.Context) {
 
 func (e *Editor) clickDragEvents(gtx layout.Context) []event.Event {
 	var combinedEvents []event.Event
+	e.clicker.DoubleClickDuration = gtx.Gestures.DoubleClick
+	e.dragger.MouseSlop = gtx.Gestures.DragThreshold
 	for _, evt := range e.clicker.Update(gtx) {
 		combinedEvents = append(combinedEvents, evt)
 	}
 	for _, evt := range e.dragger.Update(gtx.Metric, gtx, gesture.Both) {
 		combinedEvents = append(combinedEvents, evt)
 	}
+	for _, evt := range e.handles.longPress.Update(gtx.Metric, gtx, gtx.Now) {
+		combinedEvents = append(combinedEvents, evt)
+	}
 	return combinedEvents
 }
 
 func (e *Editor) processKey(gtx layout.Context) {
+	// Changes before the processing of events are not by the user.
 	if e.text.Changed() {
-		e.events = append(e.events, ChangeEvent{})
+		e.events = append(e.events, ChangeEvent{Programmatic: true})
 	}
 	// adjust keeps track of runes dropped because of MaxLen.
 	var adjust int
@@ -364,10 +555,17 @@ func (e *Editor) processKey(gtx layout.Context) {
 			case e.SingleLine:
 				s = strings.ReplaceAll(s, "\n", " ")
 			}
-			moves += e.replace(ke.Range.Start, ke.Range.End, s, true)
-			adjust += utf8.RuneCountInString(ke.Text) - moves
-			// Reset caret xoff.
-			e.text.MoveCaret(0, 0)
+			if start, end := e.text.Selection(); len(e.text.carets) > 0 &&
+				min(start, end) == min(ke.Range.Start, ke.Range.End) &&
+				max(start, end) == max(ke.Range.Start, ke.Range.End) {
+				// Type at every caret.
+				e.editCarets(func() { e.insert(s) })
+			} else {
+				moves += e.replace(ke.Range.Start, ke.Range.End, s, true)
+				adjust += utf8.RuneCountInString(ke.Text) - moves
+				// Reset caret xoff.
+				e.text.MoveCaret(0, 0)
+			}
 			if submit {
 				if e.text.Changed() {
 					e.events = append(e.events, ChangeEvent{})
@@ -381,7 +579,15 @@ func (e *Editor) processKey(gtx layout.Context) {
 		case clipboard.Event:
 			e.scrollCaret = true
 			e.scroller.Stop()
-			e.Insert(ke.Text)
+			e.Insert(e.pasteText(ke))
+		case key.CompositionEvent:
+			e.ime.compose = key.Range{}
+			if ke.Start == -1 {
+				break
+			}
+			start := max(min(min(ke.Start, ke.End)-adjust, e.text.Len()), 0)
+			end := max(min(max(ke.Start, ke.End)-adjust, e.text.Len()), 0)
+			e.ime.compose = key.Range{Start: start, End: end}
 		case key.SelectionEvent:
 			e.scrollCaret = true
 			e.scroller.Stop()
@@ -412,20 +618,29 @@ func (e *Editor) command(gtx layout.Context, k key.Event) {
 		// half is in Editor.processKey() under clipboard.Event.
 		case "V":
 			if !e.ReadOnly {
-				clipboard.ReadOp{Tag: &e.eventKey}.Add(gtx.Ops)
+				read := clipboard.ReadOp{Tag: &e.eventKey}
+				if e.Sanitizer != nil {
+					read.Types = richPasteTypes
+				}
+				read.Add(gtx.Ops)
 			}
-		// Copy or Cut selection -- ignored if nothing selected.
+		// Copy or Cut selection -- ignored if nothing selected. Highlighted
+		// text is offered as HTML as well.
 		case "C", "X":
-			e.scratch = e.text.SelectedText(e.scratch)
-			if text := string(e.scratch); text != "" {
-				clipboard.WriteOp{Text: text}.Add(gtx.Ops)
+			if text := e.selectedTexts(); text != "" {
+				clipboard.WriteOp{Text: text, Content: e.styledContent()}.Add(gtx.Ops)
 				if k.Name == "X" && !e.ReadOnly {
 					e.Delete(1)
 				}
 			}
 		// Select all
 		case "A":
+			e.text.ClearCarets()
 			e.text.SetCaret(0, e.text.Len())
+		case "D":
+			if e.MultiCaret {
+				e.SelectNextOccurrence()
+			}
 		case "Z":
 			if !e.ReadOnly {
 				if k.Modifiers.Contain(key.ModShift) {
@@ -445,7 +660,7 @@ func (e *Editor) command(gtx layout.Context, k key.Event) {
 	case key.NameDeleteBackward:
 		if !e.ReadOnly {
 			if moveByWord {
-				e.deleteWord(-1)
+				e.editCarets(func() { e.deleteWord(-1) })
 			} else {
 				e.Delete(-1)
 			}
@@ -453,11 +668,29 @@ func (e *Editor) command(gtx layout.Context, k key.Event) {
 	case key.NameDeleteForward:
 		if !e.ReadOnly {
 			if moveByWord {
-				e.deleteWord(1)
+				e.editCarets(func() { e.deleteWord(1) })
 			} else {
 				e.Delete(1)
 			}
 		}
+	case key.NameEscape:
+		if e.MultiCaret {
+			e.text.ClearCarets()
+		}
+	default:
+		name := k.Name
+		if e.Vertical {
+			name, direction = verticalKey(name), 1
+		}
+		e.text.eachCaret(func() {
+			e.navigate(name, selAct, moveByWord, direction)
+		})
+	}
+}
+
+// navigate moves the caret for the navigation key name.
+func (e *Editor) navigate(name string, selAct selectionAction, moveByWord bool, direction int) {
+	switch name {
 	case key.NameUpArrow:
 		e.text.MoveLines(-1, selAct)
 	case key.NameDownArrow:
@@ -515,6 +748,9 @@ func (e *Editor) initBuffer() {
 	e.text.SingleLine = e.SingleLine
 	e.text.Mask = e.Mask
 	e.text.WrapPolicy = e.WrapPolicy
+	e.text.NoWrap = e.NoWrap
+	e.text.WrapIndent = e.WrapIndent
+	e.text.Vertical = e.Vertical
 }
 
 // Update the state of the editor in response to input events.
@@ -530,6 +766,7 @@ func (e *Editor) Update(gtx layout.Context) {
 			End:   end,
 		}
 		caretPos, carAsc, carDesc := e.text.CaretInfo()
+		caretPos = caretPos.Add(e.textOffset)
 		newSel.caret = key.Caret{
 			Pos:     layout.FPt(caretPos),
 			Ascent:  float32(carAsc),
@@ -553,9 +790,78 @@ func (e *Editor) Update(gtx layout.Context) {
 // selection rectangle.
 func (e *Editor) Layout(gtx layout.Context, lt *text.Shaper, font font.Font, size unit.Sp, textMaterial, selectMaterial op.CallOp) layout.Dimensions {
 	e.Update(gtx)
+	if e.Vertical {
+		gtx.Constraints = verticalConstraints(gtx.Constraints)
+	}
 
-	e.text.Layout(gtx, lt, font, size)
-	return e.layout(gtx, textMaterial, selectMaterial)
+	gutter := 0
+	if e.Gutter != nil {
+		gutter = gtx.Dp(e.GutterWidth)
+	}
+	tgtx := gtx
+	tgtx.Constraints.Min.X = max(gtx.Constraints.Min.X-gutter, 0)
+	tgtx.Constraints.Max.X = max(gtx.Constraints.Max.X-gutter, 0)
+	e.text.Layout(tgtx, lt, font, size)
+	if e.Vertical {
+		defer verticalTransform(e.text.Dimensions().Size.Y).Push(gtx.Ops).Pop()
+	}
+	e.textOffset = image.Pt(gutter, 0)
+	gutterX := 0
+	if gtx.Locale.Direction.Progression() == system.TowardOrigin {
+		e.textOffset = image.Point{}
+		gutterX = e.text.Dimensions().Size.X
+	}
+	t := op.Offset(e.textOffset).Push(gtx.Ops)
+	dims := e.layout(tgtx, textMaterial, selectMaterial)
+	if e.focused {
+		e.handles.layout(tgtx, &e.text, e.TouchSelection, textMaterial, selectMaterial)
+	}
+	t.Pop()
+	if gutter > 0 {
+		e.layoutGutter(gtx, gutterX, gutter)
+		dims.Size.X += gutter
+	}
+	if e.Vertical {
+		dims = layout.Dimensions{Size: image.Pt(dims.Size.Y, dims.Size.X)}
+	}
+	return dims
+}
+
+// layoutGutter lays out the gutters of the visible lines in the column
+// of width at x.
+func (e *Editor) layoutGutter(gtx layout.Context, x, width int) {
+	view := e.text.viewSize
+	defer clip.Rect{Min: image.Pt(x, 0), Max: image.Pt(x+width, view.Y)}.Push(gtx.Ops).Pop()
+	glyphs := e.text.index.glyphs
+	caretLine, _ := e.text.CaretPos()
+	scroll := e.text.ScrollOff().Y
+	number, g := 0, 0
+	for i, l := range e.text.index.lines {
+		continued := i > 0 && g < len(glyphs) && glyphs[g].Flags&text.FlagParagraphStart == 0
+		g += l.glyphs
+		if !continued {
+			number++
+		}
+		top := l.yOff - l.ascent.Ceil() - scroll
+		bottom := l.yOff + l.descent.Ceil() - scroll
+		if bottom < 0 {
+			continue
+		}
+		if top > view.Y {
+			break
+		}
+		lgtx := gtx
+		lgtx.Constraints = layout.Exact(image.Pt(width, bottom-top))
+		t := op.Offset(image.Pt(x, top)).Push(gtx.Ops)
+		e.Gutter(lgtx, EditorLine{
+			Number:    number,
+			Continued: continued,
+			Start:     l.runes,
+			Caret:     i == caretLine,
+			Baseline:  l.yOff - scroll - top,
+		})
+		t.Pop()
+	}
 }
 
 // updateSnippet adds a key.SnippetOp if the snippet content or position
@@ -623,10 +929,17 @@ func (e *Editor) layout(gtx layout.Context, textMaterial, selectMaterial op.Call
 		const keyFilterNoRightDown = "(ShortAlt)-(Shift)-[←,↑]|(Shift)-[⏎,⌤]|(ShortAlt)-(Shift)-[⌫,⌦]|(Shift)-[⇞,⇟,⇱,⇲]|Short-[C,V,X,A]|Short-(Shift)-Z"
 		const keyFilterNoArrows = "(Shift)-[⏎,⌤]|(ShortAlt)-(Shift)-[⌫,⌦]|(Shift)-[⇞,⇟,⇱,⇲]|Short-[C,V,X,A]|Short-(Shift)-Z"
 		const keyFilterAllArrows = "(ShortAlt)-(Shift)-[←,→,↑,↓]|(Shift)-[⏎,⌤]|(ShortAlt)-(Shift)-[⌫,⌦]|(Shift)-[⇞,⇟,⇱,⇲]|Short-[C,V,X,A]|Short-(Shift)-Z"
+		const keyFilterNoUpRight = "(ShortAlt)-(Shift)-[←,↓]|(Shift)-[⏎,⌤]|(ShortAlt)-(Shift)-[⌫,⌦]|(Shift)-[⇞,⇟,⇱,⇲]|Short-[C,V,X,A]|Short-(Shift)-Z"
+		const keyFilterNoLeftDown = "(ShortAlt)-(Shift)-[→,↑]|(Shift)-[⏎,⌤]|(ShortAlt)-(Shift)-[⌫,⌦]|(Shift)-[⇞,⇟,⇱,⇲]|Short-[C,V,X,A]|Short-(Shift)-Z"
 		caret, _ := e.text.Selection()
 		switch {
 		case caret == 0 && caret == e.text.Len():
 			keys = keyFilterNoArrows
+		case e.Vertical && caret == 0:
+			// In vertical text, the up and right arrows move backward.
+			keys = keyFilterNoUpRight
+		case e.Vertical && caret == e.text.Len():
+			keys = keyFilterNoLeftDown
 		case caret == 0:
 			if gtx.Locale.Direction.Progression() == system.FromOrigin {
 				keys = keyFilterNoLeftUp
@@ -642,6 +955,12 @@ func (e *Editor) layout(gtx layout.Context, textMaterial, selectMaterial op.Call
 		default:
 			keys = keyFilterAllArrows
 		}
+		if e.MultiCaret && len(e.text.carets) > 0 {
+			keys = keyFilterAllArrows + "|⎋"
+		}
+		if e.MultiCaret {
+			keys += "|Short-D"
+		}
 	}
 	key.InputOp{Tag: &e.eventKey, Hint: e.InputHint, Keys: keys}.Add(gtx.Ops)
 	if e.requestFocus {
@@ -661,9 +980,19 @@ func (e *Editor) layout(gtx layout.Context, textMaterial, selectMaterial op.Call
 		scrollRange.Max.Y = max(0, textDims.Size.Y-(scrollOffY+visibleDims.Size.Y))
 	}
 	e.scroller.Add(gtx.Ops, scrollRange)
+	if e.NoWrap && !e.SingleLine {
+		sb := e.text.ScrollBounds()
+		scrollOffX := e.text.ScrollOff().X
+		hscrollRange := image.Rectangle{
+			Min: image.Pt(min(sb.Min.X-scrollOffX, 0), 0),
+			Max: image.Pt(max(0, sb.Max.X-scrollOffX), 0),
+		}
+		e.hscroller.Add(gtx.Ops, hscrollRange)
+	}
 
 	e.clicker.Add(gtx.Ops)
 	e.dragger.Add(gtx.Ops)
+	e.handles.longPress.Add(gtx.Ops)
 	e.showCaret = false
 	if e.focused {
 		now := gtx.Now
@@ -680,9 +1009,12 @@ func (e *Editor) layout(gtx layout.Context, textMaterial, selectMaterial op.Call
 	disabled := gtx.Queue == nil
 
 	semantic.Editor.Add(gtx.Ops)
+	e.addSemantics(gtx)
 	if e.Len() > 0 {
+		e.paintSpans(gtx)
 		e.paintSelection(gtx, selectMaterial)
 		e.paintText(gtx, textMaterial)
+		e.paintComposition(gtx, textMaterial)
 	}
 	if !disabled {
 		e.paintCaret(gtx, textMaterial)
@@ -690,6 +1022,51 @@ func (e *Editor) layout(gtx layout.Context, textMaterial, selectMaterial op.Call
 	return visibleDims
 }
 
+// addSemantics describes the content and selection of the editor to
+// accessibility services. Masked content is reported masked.
+func (e *Editor) addSemantics(gtx layout.Context) {
+	// Reading the text copies the whole document, so it is only done
+	// when the text or the mask changed.
+	sem := &e.semantic
+	if !sem.valid || sem.version != e.version || sem.mask != e.Mask {
+		val := e.Text()
+		if e.Mask != 0 {
+			val = strings.Map(func(r rune) rune {
+				if r == '\n' {
+					return r
+				}
+				return e.Mask
+			}, val)
+		}
+		sem.value, sem.version, sem.mask, sem.valid = val, e.version, e.Mask, true
+	}
+	semantic.ValueOp(sem.value).Add(gtx.Ops)
+	caret, anchor := e.Selection()
+	semantic.SelectionOp{Start: anchor, End: caret}.Add(gtx.Ops)
+}
+
+// paintSpans paints the backgrounds of the spans returned by Highlight,
+// calling it if the text changed.
+func (e *Editor) paintSpans(gtx layout.Context) {
+	e.initBuffer()
+	if e.Highlight == nil {
+		e.text.Spans = nil
+		return
+	}
+	if !e.spansValid {
+		e.spans = e.Highlight(e.Text(), e.spans[:0])
+		e.spansValid = true
+	}
+	e.text.Spans = e.spans
+	e.text.PaintSpans(gtx)
+}
+
+// Restyle makes the next Layout call Highlight even if the text didn't
+// change, such as after changing the language of a syntax highlighter.
+func (e *Editor) Restyle() {
+	e.spansValid = false
+}
+
 // paintSelection paints the contrasting background for selected text using the provided
 // material to set the painting material for the selection.
 func (e *Editor) paintSelection(gtx layout.Context, material op.CallOp) {
@@ -707,6 +1084,25 @@ func (e *Editor) paintText(gtx layout.Context, material op.CallOp) {
 	e.text.PaintText(gtx, material)
 }
 
+// paintComposition underlines the composing region of the input method
+// using the provided material.
+func (e *Editor) paintComposition(gtx layout.Context, material op.CallOp) {
+	e.initBuffer()
+	c := e.ime.compose
+	if !e.focused || c.Start == c.End {
+		return
+	}
+	thick := max(1, gtx.Dp(1))
+	for _, r := range e.text.Regions(c.Start, c.End, nil) {
+		top := r.Bounds.Max.Y - r.Baseline + max(1, r.Baseline/3)
+		line := image.Rect(r.Bounds.Min.X, top, r.Bounds.Max.X, top+thick)
+		stack := clip.Rect(line).Push(gtx.Ops)
+		material.Add(gtx.Ops)
+		paint.PaintOp{}.Add(gtx.Ops)
+		stack.Pop()
+	}
+}
+
 // paintCaret paints the text glyphs using the provided material to set the fill material
 // of the caret rectangle.
 func (e *Editor) paintCaret(gtx layout.Context, material op.CallOp) {
@@ -737,6 +1133,7 @@ func (e *Editor) SetText(s string) {
 	}
 	e.replace(0, e.text.Len(), s, true)
 	// Reset xoff and move the caret to the beginning.
+	e.text.ClearCarets()
 	e.SetCaret(0, 0)
 }
 
@@ -750,7 +1147,7 @@ func (e *Editor) CaretPos() (line, col int) {
 // editor itself.
 func (e *Editor) CaretCoords() f32.Point {
 	e.initBuffer()
-	return e.text.CaretCoords()
+	return e.text.CaretCoords().Add(layout.FPt(e.textOffset))
 }
 
 // Delete runes from the caret position. The sign of the argument specifies the
@@ -763,7 +1160,11 @@ func (e *Editor) Delete(graphemeClusters int) {
 	if graphemeClusters == 0 {
 		return
 	}
+	e.editCarets(func() { e.delete(graphemeClusters) })
+}
 
+// delete is like Delete for the primary caret only.
+func (e *Editor) delete(graphemeClusters int) {
 	start, end := e.text.Selection()
 	if start != end {
 		graphemeClusters -= sign(graphemeClusters)
@@ -773,17 +1174,26 @@ func (e *Editor) Delete(graphemeClusters int) {
 	e.text.MoveCaret(0, graphemeClusters)
 	// Get the new rune offsets of the selection.
 	start, end = e.text.Selection()
-	e.replace(start, end, "", true)
+	moves := e.replace(start, end, "", true)
 	// Reset xoff.
 	e.text.MoveCaret(0, 0)
-	e.ClearSelection()
+	start = min(start, end)
+	e.text.SetCaret(start+moves, start+moves)
 }
 
+// Insert replaces the selection of every caret with s.
 func (e *Editor) Insert(s string) {
 	e.initBuffer()
 	if e.SingleLine {
 		s = strings.ReplaceAll(s, "\n", " ")
 	}
+	e.editCarets(func() { e.insert(s) })
+	e.scrollCaret = true
+	e.scroller.Stop()
+}
+
+// insert is like Insert for the primary caret only.
+func (e *Editor) insert(s string) {
 	start, end := e.text.Selection()
 	moves := e.replace(start, end, s, true)
 	if end < start {
@@ -791,8 +1201,15 @@ func (e *Editor) Insert(s string) {
 	}
 	// Reset xoff.
 	e.text.MoveCaret(0, 0)
-	e.SetCaret(start+moves, start+moves)
-	e.scrollCaret = true
+	e.text.SetCaret(start+moves, start+moves)
+}
+
+// editCarets applies edit at every caret, as a single step of the undo
+// history.
+func (e *Editor) editCarets(edit func()) {
+	e.batching, e.chained = true, false
+	e.text.eachCaret(edit)
+	e.batching, e.chained = false, false
 }
 
 // modification represents a change to the contents of the editor buffer.
@@ -808,36 +1225,45 @@ type modification struct {
 	// ReverseContent is the data inserted at StartRune to
 	// apply this operation. It overwrites len([]rune(ApplyContent)) runes.
 	ReverseContent string
+	// Chained modifications are undone and redone together with the
+	// previous modification.
+	Chained bool
 }
 
 // undo applies the modification at e.history[e.historyIdx] and decrements
 // e.historyIdx.
 func (e *Editor) undo() {
 	e.initBuffer()
-	if len(e.history) < 1 || e.nextHistoryIdx == 0 {
-		return
+	e.text.ClearCarets()
+	for e.nextHistoryIdx > 0 {
+		mod := e.history[e.nextHistoryIdx-1]
+		replaceEnd := mod.StartRune + utf8.RuneCountInString(mod.ApplyContent)
+		e.replace(mod.StartRune, replaceEnd, mod.ReverseContent, false)
+		caretEnd := mod.StartRune + utf8.RuneCountInString(mod.ReverseContent)
+		e.SetCaret(caretEnd, mod.StartRune)
+		e.nextHistoryIdx--
+		if !mod.Chained {
+			break
+		}
 	}
-	mod := e.history[e.nextHistoryIdx-1]
-	replaceEnd := mod.StartRune + utf8.RuneCountInString(mod.ApplyContent)
-	e.replace(mod.StartRune, replaceEnd, mod.ReverseContent, false)
-	caretEnd := mod.StartRune + utf8.RuneCountInString(mod.ReverseContent)
-	e.SetCaret(caretEnd, mod.StartRune)
-	e.nextHistoryIdx--
 }
 
 // redo applies the modification at e.history[e.historyIdx] and increments
 // e.historyIdx.
 func (e *Editor) redo() {
 	e.initBuffer()
-	if len(e.history) < 1 || e.nextHistoryIdx == len(e.history) {
-		return
+	e.text.ClearCarets()
+	for e.nextHistoryIdx < len(e.history) {
+		mod := e.history[e.nextHistoryIdx]
+		end := mod.StartRune + utf8.RuneCountInString(mod.ReverseContent)
+		e.replace(mod.StartRune, end, mod.ApplyContent, false)
+		caretEnd := mod.StartRune + utf8.RuneCountInString(mod.ApplyContent)
+		e.SetCaret(caretEnd, mod.StartRune)
+		e.nextHistoryIdx++
+		if e.nextHistoryIdx == len(e.history) || !e.history[e.nextHistoryIdx].Chained {
+			break
+		}
 	}
-	mod := e.history[e.nextHistoryIdx]
-	end := mod.StartRune + utf8.RuneCountInString(mod.ReverseContent)
-	e.replace(mod.StartRune, end, mod.ApplyContent, false)
-	caretEnd := mod.StartRune + utf8.RuneCountInString(mod.ApplyContent)
-	e.SetCaret(caretEnd, mod.StartRune)
-	e.nextHistoryIdx++
 }
 
 // replace the text between start and end with s. Indices are in runes.
@@ -858,7 +1284,8 @@ func (e *Editor) replace(start, end int, s string, addHistory bool) int {
 	idx := 0
 	for idx < len(s) {
 		if e.MaxLen > 0 && el-replaceSize+sc >= e.MaxLen {
-			s = s[:idx]
+			// Don't split a grapheme cluster.
+			s = s[:e.graphemes.Prev(s, idx+1)]
 			break
 		}
 		_, n := utf8.DecodeRuneInString(s[idx:])
@@ -869,6 +1296,21 @@ func (e *Editor) replace(start, end int, s string, addHistory bool) int {
 		idx += n
 		sc++
 	}
+	// moves is the offset of the caret after the edit from start.
+	moves := -1
+	if e.FilterFunc != nil && addHistory {
+		filtStart, filtEnd, filtS, caret, ok := e.filterEdit(start, end, s)
+		if !ok {
+			return 0
+		}
+		moves = caret - start
+		if filtStart == filtEnd && filtS == "" {
+			// The filter undid the edit.
+			return moves
+		}
+		start, end, s = filtStart, filtEnd, filtS
+		replaceSize = end - start
+	}
 
 	if addHistory {
 		deleted := make([]rune, 0, replaceSize)
@@ -885,11 +1327,15 @@ func (e *Editor) replace(start, end int, s string, addHistory bool) int {
 			StartRune:      start,
 			ApplyContent:   s,
 			ReverseContent: string(deleted),
+			Chained:        e.chained,
 		})
 		e.nextHistoryIdx++
+		e.chained = e.batching
 	}
 
 	sc = e.text.Replace(start, end, s)
+	e.spansValid = false
+	e.version++
 	newEnd := start + sc
 	adjust := func(pos int) int {
 		switch {
@@ -903,9 +1349,33 @@ func (e *Editor) replace(start, end int, s string, addHistory bool) int {
 	}
 	e.ime.start = adjust(e.ime.start)
 	e.ime.end = adjust(e.ime.end)
+	e.ime.compose.Start = adjust(e.ime.compose.Start)
+	e.ime.compose.End = adjust(e.ime.compose.End)
+	if moves != -1 {
+		return moves
+	}
 	return sc
 }
 
+// filterEdit applies FilterFunc to the replacement of the runes between
+// start and end with s. It returns the filtered edit as the smallest
+// replacement, the position of the caret after it, and false if the
+// edit is rejected.
+func (e *Editor) filterEdit(start, end int, s string) (newStart, newEnd int, newS string, caret int, ok bool) {
+	e.scratch = e.text.Text(e.scratch)
+	old := string(e.scratch)
+	startOff, endOff := e.text.ByteOffset(start), e.text.ByteOffset(end)
+	edited := old[:startOff] + s + old[endOff:]
+	filtered, caret, ok := e.FilterFunc(edited, start+utf8.RuneCountInString(s))
+	if !ok {
+		return 0, 0, "", 0, false
+	}
+	prefix, suffix := commonAffixes(old, filtered)
+	newStart = utf8.RuneCountInString(old[:prefix])
+	newEnd = newStart + utf8.RuneCountInString(old[prefix:len(old)-suffix])
+	return newStart, newEnd, filtered[prefix : len(filtered)-suffix], caret, true
+}
+
 // MoveCaret moves the caret (aka selection start) and the selection end
 // relative to their current positions. Positive distances moves forward,
 // negative distances moves backward. Distances are in grapheme clusters,
@@ -928,7 +1398,7 @@ func (e *Editor) deleteWord(distance int) {
 
 	start, end := e.text.Selection()
 	if start != end {
-		e.Delete(1)
+		e.delete(1)
 		distance -= sign(distance)
 	}
 	if distance == 0 {
@@ -942,37 +1412,46 @@ func (e *Editor) deleteWord(distance int) {
 		words, direction = distance*-1, -1
 	}
 	caret, _ := e.text.Selection()
-	// atEnd if offset is at or beyond either side of the buffer.
-	atEnd := func(runes int) bool {
-		idx := caret + runes*direction
-		return idx <= 0 || idx >= e.Len()
-	}
-	// next returns the appropriate rune given the direction and offset in runes).
-	next := func(runes int) rune {
-		idx := caret + runes*direction
-		if idx < 0 {
-			idx = 0
-		} else if idx > e.Len() {
-			idx = e.Len()
-		}
-		off := e.text.ByteOffset(idx)
+	txt := e.Text()
+	// Segment the text once, and walk its cluster boundaries from the
+	// caret.
+	bounds := e.graphemes.Boundaries(nil, txt)
+	if len(bounds) == 0 {
+		return
+	}
+	from, _ := slices.BinarySearch(bounds, int(e.text.ByteOffset(caret)))
+	// pos returns the byte offset the given number of grapheme clusters
+	// from the caret.
+	pos := func(clusters int) int {
+		i := min(max(from+clusters*direction, 0), len(bounds)-1)
+		return bounds[i]
+	}
+	// atEnd if the position is at either side of the buffer.
+	atEnd := func(clusters int) bool {
+		off := pos(clusters)
+		return off <= 0 || off >= len(txt)
+	}
+	// next returns the appropriate rune given the direction and offset in
+	// grapheme clusters.
+	next := func(clusters int) rune {
+		off := pos(clusters)
 		var r rune
 		if direction < 0 {
-			r, _, _ = e.text.ReadRuneBefore(int64(off))
+			r, _ = utf8.DecodeLastRuneInString(txt[:off])
 		} else {
-			r, _, _ = e.text.ReadRuneAt(int64(off))
+			r, _ = utf8.DecodeRuneInString(txt[off:])
 		}
 		return r
 	}
-	runes := 1
+	clusters := 1
 	for ii := 0; ii < words; ii++ {
-		r := next(runes)
+		r := next(clusters)
 		wantSpace := unicode.IsSpace(r)
-		for r := next(runes); unicode.IsSpace(r) == wantSpace && !atEnd(runes); r = next(runes) {
-			runes += 1
+		for r := next(clusters); unicode.IsSpace(r) == wantSpace && !atEnd(clusters); r = next(clusters) {
+			clusters += 1
 		}
 	}
-	e.Delete(runes * direction)
+	e.delete(clusters * direction)
 }
 
 // SelectionLen returns the length of the selection, in runes; it is
@@ -998,6 +1477,103 @@ func (e *Editor) SetCaret(start, end int) {
 	e.scroller.Stop()
 }
 
+// Carets returns the carets of the editor, starting with the primary
+// caret whose selection is returned by Selection. Editing applies to
+// every caret.
+func (e *Editor) Carets() []Caret {
+	e.initBuffer()
+	start, end := e.text.Selection()
+	carets := []Caret{{Start: start, End: end}}
+	for _, c := range e.text.carets {
+		carets = append(carets, Caret{Start: c.start, End: c.end})
+	}
+	return carets
+}
+
+// AddCaret adds a caret at start with a selection ending at end, in
+// addition to the existing carets. Carets that overlap are merged. start
+// and end are in runes.
+func (e *Editor) AddCaret(start, end int) {
+	e.initBuffer()
+	e.text.AddCaret(start, end)
+}
+
+// ClearCarets removes every caret but the primary caret.
+func (e *Editor) ClearCarets() {
+	e.initBuffer()
+	e.text.ClearCarets()
+}
+
+// SelectNextOccurrence adds a caret selecting the next occurrence of the
+// selected text after the last caret, wrapping around the end of the
+// text, and makes it the primary caret. Without a selection it selects
+// the word at the caret instead. It reports whether the selections
+// changed.
+func (e *Editor) SelectNextOccurrence() bool {
+	e.initBuffer()
+	if e.text.SelectionLen() == 0 {
+		e.text.MoveWord(-1, selectionClear)
+		e.text.MoveWord(1, selectionExtend)
+		return e.text.SelectionLen() > 0
+	}
+	needle := e.SelectedText()
+	txt := e.Text()
+	carets := e.Carets()
+	from := int(e.text.ByteOffset(max(carets[0].Start, carets[0].End)))
+	at, wrapped := from, false
+	for {
+		i := strings.Index(txt[at:], needle)
+		if i == -1 {
+			if wrapped {
+				return false
+			}
+			at, wrapped = 0, true
+			continue
+		}
+		i += at
+		if wrapped && i >= from {
+			return false
+		}
+		start := utf8.RuneCountInString(txt[:i])
+		occ := textCaret{start: start + utf8.RuneCountInString(needle), end: start}
+		selected := false
+		for _, c := range carets {
+			selected = selected || occ.overlaps(textCaret{start: c.Start, end: c.End})
+		}
+		if !selected {
+			e.text.carets = append(e.text.carets, e.text.caret)
+			e.text.SetCaret(occ.start, occ.end)
+			e.scrollCaret = true
+			e.scroller.Stop()
+			return true
+		}
+		at = i + len(needle)
+	}
+}
+
+// selectedTexts returns the selected text of every caret in text order,
+// separated by newlines.
+func (e *Editor) selectedTexts() string {
+	carets := e.Carets()
+	sort.Slice(carets, func(i, j int) bool {
+		return min(carets[i].Start, carets[i].End) < min(carets[j].Start, carets[j].End)
+	})
+	var b strings.Builder
+	for _, c := range carets {
+		if c.Start == c.End {
+			continue
+		}
+		start, end := e.text.ByteOffset(min(c.Start, c.End)), e.text.ByteOffset(max(c.Start, c.End))
+		buf := make([]byte, end-start)
+		n, _ := e.text.ReadAt(buf, start)
+		if b.Len() > 0 {
+			b.WriteByte('\n')
+		}
+		b.Write(buf[:n])
+	}
+	return b.String()
+}
+
 // SelectedText returns the currently selected text (if any) from the editor.
 func (e *Editor) SelectedText() string {
 	e.initBuffer()
@@ -1030,6 +1606,53 @@ func (e *Editor) Read(p []byte) (int, error) {
 	return e.text.Read(p)
 }
 
+// ReadAt implements io.ReaderAt. It reads the text at a byte offset
+// without moving the position of Read and Seek, for streaming large texts
+// in parts.
+func (e *Editor) ReadAt(p []byte, off int64) (int, error) {
+	e.initBuffer()
+	return e.text.ReadAt(p, off)
+}
+
+// Size returns the length of the text in bytes.
+func (e *Editor) Size() int64 {
+	e.initBuffer()
+	return e.buffer.Size()
+}
+
+// ReadFrom implements io.ReaderFrom. It replaces the text with the
+// content of r and clears the undo history. Unlike SetText, ReadFrom
+// reads the content directly into the buffer of the editor, which
+// suits loading large documents. The text is unchanged if reading
+// fails.
+func (e *Editor) ReadFrom(r io.Reader) (int64, error) {
+	e.initBuffer()
+	if e.SingleLine || e.Filter != "" || e.MaxLen > 0 || e.FilterFunc != nil {
+		// The content is processed like edits.
+		var b strings.Builder
+		n, err := io.Copy(&b, r)
+		if err != nil {
+			return n, err
+		}
+		e.SetText(b.String())
+		e.history, e.nextHistoryIdx = e.history[:0], 0
+		return n, nil
+	}
+	n, err := e.buffer.readFrom(r)
+	if err != nil {
+		return n, err
+	}
+	e.text.SetSource(e.buffer)
+	e.history, e.nextHistoryIdx = e.history[:0], 0
+	e.spansValid = false
+	e.version++
+	e.ime.start, e.ime.end = 0, 0
+	e.ime.compose = key.Range{}
+	e.text.ClearCarets()
+	e.SetCaret(0, 0)
+	return n, nil
+}
+
 // Regions returns visible regions covering the rune range [start,end).
 func (e *Editor) Regions(start, end int, regions []Region) []Region {
 	e.initBuffer()
@@ -0,0 +1,675 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package widget
+
+import (
+	"image"
+	"image/color"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Seikaijyu/gio/f32"
+	"github.com/Seikaijyu/gio/font"
+	"github.com/Seikaijyu/gio/font/gofont"
+	"github.com/Seikaijyu/gio/io/key"
+	"github.com/Seikaijyu/gio/io/pointer"
+	"github.com/Seikaijyu/gio/io/router"
+	"github.com/Seikaijyu/gio/layout"
+	"github.com/Seikaijyu/gio/op"
+	"github.com/Seikaijyu/gio/text"
+)
+
+func TestEditorHighlight(t *testing.T) {
+	lt := text.NewShaper(text.NoSystemFonts(), text.WithCollection(gofont.Collection()))
+	var (
+		ops op.Ops
+		r   router.Router
+	)
+	calls := 0
+	var highlighted string
+	e := &Editor{
+		Highlight: func(txt string, spans []TextSpan) []TextSpan {
+			calls++
+			highlighted = txt
+			// Highlight every "func".
+			for off := 0; ; {
+				i := strings.Index(txt[off:], "func")
+				if i == -1 {
+					break
+				}
+				start := len([]rune(txt[:off+i]))
+				spans = append(spans, TextSpan{
+					Start:      start,
+					End:        start + 4,
+					Color:      color.NRGBA{R: 0xff, A: 0xff},
+					Background: color.NRGBA{B: 0xff, A: 0x40},
+					Weight:     font.Bold,
+				})
+				off += i + 4
+			}
+			return spans
+		},
+	}
+	e.SetText("func main() {\n\tfunc() {}()\n}\n")
+	frame := func() {
+		layoutFrame(&r, &ops, image.Pt(100, 1000), func(gtx layout.Context) {
+			e.Layout(gtx, lt, font.Font{}, 14, op.CallOp{}, op.CallOp{})
+		})
+	}
+	frame()
+	if calls != 1 {
+		t.Fatalf("Highlight called %d times, want 1", calls)
+	}
+	if got := len(e.text.Spans); got != 2 {
+		t.Errorf("got %d spans, want 2", got)
+	}
+	frame()
+	if calls != 1 {
+		t.Errorf("Highlight called for unchanged text")
+	}
+	e.SetCaret(0, 0)
+	e.Insert("// ")
+	frame()
+	if calls != 2 || highlighted != e.Text() {
+		t.Errorf("Highlight not called with the edited text")
+	}
+	e.Restyle()
+	frame()
+	if calls != 3 {
+		t.Errorf("Highlight not called after Restyle")
+	}
+}
+
+// TestLineRunes verifies that the rune offsets of lines match the runes
+// of the glyphs before them.
+func TestLineRunes(t *testing.T) {
+	lt := text.NewShaper(text.NoSystemFonts(), text.WithCollection(gofont.Collection()))
+	gtx := layout.Context{
+		Ops:         new(op.Ops),
+		Constraints: layout.Exact(image.Pt(100, 1000)),
+	}
+	v := new(textView)
+	v.SetSource(newStringSource("A line long enough to wrap, with\nhéllo 世界 and עברית\n\nend"))
+	v.Layout(gtx, lt, font.Font{}, 14)
+	runes, glyph := 0, 0
+	for i, line := range v.index.lines {
+		if line.runes != runes {
+			t.Errorf("line %d starts at rune %d, want %d", i, line.runes, runes)
+		}
+		for _, g := range v.index.glyphs[glyph : glyph+line.glyphs] {
+			if g.Flags&text.FlagClusterBreak != 0 {
+				runes += int(g.Runes)
+			}
+		}
+		glyph += line.glyphs
+	}
+}
+
+func TestEditorTouchSelection(t *testing.T) {
+	lt := text.NewShaper(text.NoSystemFonts(), text.WithCollection(gofont.Collection()))
+	var (
+		ops op.Ops
+		r   router.Router
+	)
+	now := time.Now()
+	e := &Editor{TouchSelection: TouchSelection{Color: color.NRGBA{A: 0xff}}}
+	e.SetText("hello world")
+	frame := func() {
+		layoutFrame(&r, &ops, image.Pt(300, 100), func(gtx layout.Context) {
+			gtx.Now = now
+			gtx.Constraints.Min = image.Point{}
+			e.Layout(gtx, lt, font.Font{}, 14, op.CallOp{}, op.CallOp{})
+		})
+	}
+	frame()
+	// Long press "world".
+	pos, _, _ := e.text.runeInfo(8)
+	r.Queue(pointer.Event{
+		Kind:     pointer.Press,
+		Source:   pointer.Touch,
+		Position: layout.FPt(pos.Sub(image.Pt(0, 5))),
+	})
+	frame()
+	now = now.Add(time.Second)
+	frame()
+	if got := e.SelectedText(); got != "world" {
+		t.Fatalf("long press selected %q, want %q", got, "world")
+	}
+	r.Queue(pointer.Event{
+		Kind:   pointer.Release,
+		Source: pointer.Touch,
+	})
+	frame()
+
+	// Drag the start handle to the start of the text.
+	start, _, descent := e.text.runeInfo(6)
+	knob := layout.FPt(start.Add(image.Pt(-10, descent+10)))
+	to, _, _ := e.text.runeInfo(0)
+	drag := f32.Pt(float32(to.X-start.X), 0)
+	r.Queue(
+		pointer.Event{Kind: pointer.Press, Source: pointer.Touch, Position: knob},
+		pointer.Event{Kind: pointer.Move, Source: pointer.Touch, Position: knob.Add(drag.Mul(.5))},
+		pointer.Event{Kind: pointer.Move, Source: pointer.Touch, Position: knob.Add(drag)},
+	)
+	frame()
+	if !e.handles.dragging {
+		t.Fatal("handle not dragged")
+	}
+	if got := e.SelectedText(); got != "hello world" {
+		t.Errorf("dragging the start handle selected %q, want %q", got, "hello world")
+	}
+	r.Queue(pointer.Event{Kind: pointer.Release, Source: pointer.Touch, Position: knob.Add(drag)})
+	frame()
+	if e.handles.dragging {
+		t.Error("handle dragged after release")
+	}
+}
+
+func TestEditorMultipleCarets(t *testing.T) {
+	lt := text.NewShaper(text.NoSystemFonts(), text.WithCollection(gofont.Collection()))
+	var (
+		ops op.Ops
+		r   router.Router
+	)
+	e := new(Editor)
+	frame := func() {
+		layoutFrame(&r, &ops, image.Pt(300, 1000), func(gtx layout.Context) {
+			e.Layout(gtx, lt, font.Font{}, 14, op.CallOp{}, op.CallOp{})
+		})
+	}
+	e.SetText("one two one two one")
+	frame()
+	e.SetCaret(0, 0)
+	if !e.SelectNextOccurrence() || e.SelectedText() != "one" {
+		t.Fatalf("selected %q, want the word at the caret", e.SelectedText())
+	}
+	for i := 0; i < 2; i++ {
+		if !e.SelectNextOccurrence() {
+			t.Fatalf("occurrence %d not selected", i+2)
+		}
+	}
+	if e.SelectNextOccurrence() {
+		t.Error("selected an occurrence twice")
+	}
+	if n := len(e.Carets()); n != 3 {
+		t.Fatalf("%d carets, want 3", n)
+	}
+	e.Insert("1")
+	if got, want := e.Text(), "1 two 1 two 1"; got != want {
+		t.Fatalf("typing at every caret gave %q, want %q", got, want)
+	}
+	e.Delete(-1)
+	if got, want := e.Text(), " two  two "; got != want {
+		t.Fatalf("deleting at every caret gave %q, want %q", got, want)
+	}
+	e.undo()
+	if got, want := e.Text(), "1 two 1 two 1"; got != want {
+		t.Errorf("undo gave %q, want %q", got, want)
+	}
+	e.undo()
+	if got, want := e.Text(), "one two one two one"; got != want {
+		t.Errorf("undo gave %q, want %q", got, want)
+	}
+	e.redo()
+	if got, want := e.Text(), "1 two 1 two 1"; got != want {
+		t.Errorf("redo gave %q, want %q", got, want)
+	}
+
+	// Select columns 1 to 3 of every line.
+	e.SetText("abcd\nabcd\nab\nabcd")
+	frame()
+	from, to := e.text.closestToRune(1), e.text.closestToRune(16)
+	e.text.SelectBox(image.Pt(from.x.Round(), from.y), image.Pt(to.x.Round(), to.y))
+	if n := len(e.Carets()); n != 4 {
+		t.Fatalf("rectangular selection of 4 lines has %d carets", n)
+	}
+	e.Insert("X")
+	if got, want := e.Text(), "aXd\naXd\naX\naXd"; got != want {
+		t.Errorf("typing in a rectangular selection gave %q, want %q", got, want)
+	}
+
+	// Carets that meet merge.
+	e.SetText("abc")
+	e.SetCaret(2, 2)
+	e.AddCaret(3, 3)
+	e.text.eachCaret(func() { e.text.MoveCaret(1, 1) })
+	if n := len(e.Carets()); n != 1 {
+		t.Errorf("carets moved to the end: %d carets, want 1", n)
+	}
+}
+
+func TestEditorMultiCaretKeys(t *testing.T) {
+	lt := text.NewShaper(text.NoSystemFonts(), text.WithCollection(gofont.Collection()))
+	var (
+		ops op.Ops
+		r   router.Router
+		e   Editor
+	)
+	frame := func() {
+		layoutFrame(&r, &ops, image.Pt(300, 100), func(gtx layout.Context) {
+			e.Layout(gtx, lt, font.Font{}, 14, op.CallOp{}, op.CallOp{})
+		})
+	}
+	selectNext := func() string {
+		e.SetCaret(0, 0)
+		e.ClearCarets()
+		frame()
+		r.Queue(key.Event{Name: "D", Modifiers: key.ModShortcut, State: key.Press})
+		frame()
+		return e.SelectedText()
+	}
+	e.SetText("one one")
+	e.Focus()
+	frame()
+	// Short-D is left to the application without MultiCaret.
+	if got := selectNext(); got != "" {
+		t.Errorf("Short-D selected %q without MultiCaret", got)
+	}
+	e.MultiCaret = true
+	if got := selectNext(); got != "one" {
+		t.Errorf("Short-D selected %q with MultiCaret, want %q", got, "one")
+	}
+}
+
+func TestEditorGutter(t *testing.T) {
+	lt := text.NewShaper(text.NoSystemFonts(), text.WithCollection(gofont.Collection()))
+	var (
+		ops op.Ops
+		r   router.Router
+	)
+	var lines []EditorLine
+	e := &Editor{
+		GutterWidth: 40,
+		Gutter: func(gtx layout.Context, line EditorLine) layout.Dimensions {
+			lines = append(lines, line)
+			return layout.Dimensions{Size: gtx.Constraints.Min}
+		},
+	}
+	frame := func() (dims layout.Dimensions) {
+		lines = lines[:0]
+		layoutFrame(&r, &ops, image.Pt(140, 1000), func(gtx layout.Context) {
+			dims = e.Layout(gtx, lt, font.Font{}, 14, op.CallOp{}, op.CallOp{})
+		})
+		return dims
+	}
+	e.SetText("first\na line long enough to wrap in the narrow editor\n")
+	dims := frame()
+	if dims.Size.X != 140 {
+		t.Errorf("editor with gutter is %d wide, want 140", dims.Size.X)
+	}
+	if n := len(lines); n < 4 {
+		t.Fatalf("gutter of %d lines, want a wrapped line", n)
+	}
+	last := lines[len(lines)-1]
+	if last.Number != 3 || last.Continued {
+		t.Errorf("last line is %+v, want line 3", last)
+	}
+	for i, l := range lines[1 : len(lines)-1] {
+		if want := i > 0; l.Number != 2 || l.Continued != want {
+			t.Errorf("line %+v, want number 2 continued %v", l, want)
+		}
+	}
+	if !lines[0].Caret {
+		t.Error("caret not on the first line")
+	}
+	if c := e.CaretCoords(); c.X < 40 {
+		t.Errorf("caret at %v, want past the gutter", c)
+	}
+
+	// Indent continuation lines.
+	e.WrapIndent = 20
+	frame()
+	idx := e.text.index
+	for i, l := range idx.lines[2 : len(idx.lines)-1] {
+		if x := l.xOff.Round(); x != 20 {
+			t.Errorf("continuation line %d at %d, want 20", i+1, x)
+		}
+	}
+
+	// Disable wrapping.
+	e.NoWrap = true
+	frame()
+	if n := len(lines); n != 3 {
+		t.Errorf("%d lines without wrapping, want 3", n)
+	}
+	e.SetCaret(e.Len()-1, e.Len()-1)
+	frame()
+	if x := e.text.ScrollOff().X; x <= 0 {
+		t.Errorf("scrolled to %d, want the end of the long line in view", x)
+	}
+}
+
+func TestEditorComposition(t *testing.T) {
+	lt := text.NewShaper(text.NoSystemFonts(), text.WithCollection(gofont.Collection()))
+	var (
+		ops op.Ops
+		r   router.Router
+	)
+	e := new(Editor)
+	e.SetText("ab")
+	e.SetCaret(1, 1)
+	e.Focus()
+	frame := func() {
+		layoutFrame(&r, &ops, image.Pt(300, 100), func(gtx layout.Context) {
+			e.Layout(gtx, lt, font.Font{}, 14, op.CallOp{}, op.CallOp{})
+		})
+	}
+	frame()
+	frame()
+	// An input method composes "xy" at the caret.
+	r.Queue(
+		key.EditEvent{Range: key.Range{Start: 1, End: 1}, Text: "xy"},
+		key.CompositionEvent{Start: 1, End: 3},
+		key.SelectionEvent{Start: 3, End: 3},
+	)
+	frame()
+	if got, want := e.ime.compose, (key.Range{Start: 1, End: 3}); got != want {
+		t.Fatalf("composing region %v, want %v", got, want)
+	}
+	// Edits before the region move it.
+	e.SetCaret(0, 0)
+	e.Insert("_")
+	if got, want := e.ime.compose, (key.Range{Start: 2, End: 4}); got != want {
+		t.Errorf("composing region after insert %v, want %v", got, want)
+	}
+	r.Queue(key.CompositionEvent{Start: -1, End: -1})
+	frame()
+	if c := e.ime.compose; c.Start != c.End {
+		t.Errorf("composing region %v after the end of composition", c)
+	}
+	if got, want := e.Text(), "_axyb"; got != want {
+		t.Errorf("text %q, want %q", got, want)
+	}
+}
+
+func TestEditorFilterFunc(t *testing.T) {
+	lt := text.NewShaper(text.NoSystemFonts(), text.WithCollection(gofont.Collection()))
+	var (
+		ops op.Ops
+		r   router.Router
+	)
+	e := &Editor{FilterFunc: MaskFilter("(###) ###-####")}
+	var changes []ChangeEvent
+	frame := func() {
+		layoutFrame(&r, &ops, image.Pt(300, 100), func(gtx layout.Context) {
+			e.Layout(gtx, lt, font.Font{}, 14, op.CallOp{}, op.CallOp{})
+			for _, ev := range e.Events() {
+				if c, ok := ev.(ChangeEvent); ok {
+					changes = append(changes, c)
+				}
+			}
+		})
+	}
+	e.SetText("5551234")
+	if got, want := e.Text(), "(555) 123-4"; got != want {
+		t.Errorf("masked text %q, want %q", got, want)
+	}
+	frame()
+	if len(changes) != 1 || !changes[0].Programmatic {
+		t.Errorf("SetText generated %v, want a programmatic change", changes)
+	}
+
+	// Typing at the end continues the mask.
+	e.SetCaret(e.Len(), e.Len())
+	e.Focus()
+	frame()
+	changes = changes[:0]
+	// Like the window, follow the edit by moving the caret past it.
+	end := e.Len()
+	r.Queue(
+		key.EditEvent{Range: key.Range{Start: end, End: end}, Text: "56x"},
+		key.SelectionEvent{Start: end + 3, End: end + 3},
+	)
+	frame()
+	if got, want := e.Text(), "(555) 123-456"; got != want {
+		t.Errorf("masked text %q, want %q", got, want)
+	}
+	if start, end := e.Selection(); start != e.Len() || end != e.Len() {
+		t.Errorf("caret at %d-%d, want at the end %d", start, end, e.Len())
+	}
+	if len(changes) != 1 || changes[0].Programmatic {
+		t.Errorf("typing generated %v, want a user change", changes)
+	}
+	// Undo restores the filtered text.
+	e.undo()
+	if got, want := e.Text(), "(555) 123-4"; got != want {
+		t.Errorf("undone text %q, want %q", got, want)
+	}
+
+	// Rejected edits leave the text alone.
+	e.FilterFunc = func(text string, caret int) (string, int, bool) {
+		return text, caret, !strings.Contains(text, "!")
+	}
+	e.SetText("hi")
+	e.SetCaret(2, 2)
+	e.Insert("!")
+	if got := e.Text(); got != "hi" {
+		t.Errorf("text %q after rejected edit, want %q", got, "hi")
+	}
+
+	// Grapheme clusters count as one.
+	e.FilterFunc = MaxGraphemesFilter(2)
+	e.SetText("")
+	e.Insert("e\u0301a\u0301b")
+	if got, want := e.Text(), "e\u0301a\u0301"; got != want {
+		t.Errorf("limited text %q, want %q", got, want)
+	}
+	// Inserting into a full text keeps the existing text.
+	e.FilterFunc = MaxGraphemesFilter(5)
+	e.SetText("abcde")
+	e.SetCaret(1, 1)
+	e.Insert("X")
+	if got, want := e.Text(), "abcde"; got != want {
+		t.Errorf("text %q after inserting into a full text, want %q", got, want)
+	}
+	e.SetText("abc")
+	e.SetCaret(1, 1)
+	e.Insert("XYZ")
+	if got, want := e.Text(), "aXYbc"; got != want {
+		t.Errorf("text %q after inserting past the limit, want %q", got, want)
+	}
+	if start, end := e.Selection(); start != 3 || end != 3 {
+		t.Errorf("caret at %d-%d, want after the inserted text at 3", start, end)
+	}
+}
+
+func TestMaxGraphemesFilter(t *testing.T) {
+	f := MaxGraphemesFilter(3)
+	tests := []struct {
+		txt       string
+		caret     int
+		want      string
+		wantCaret int
+	}{
+		{"abc", 3, "abc", 3},
+		// Clusters just before the caret are dropped.
+		{"aXbc", 2, "abc", 1},
+		{"aXYbc", 3, "abc", 1},
+		{"abXYc", 4, "abc", 2},
+		{"ae\u0301\u0301Xb", 3, "aXb", 1},
+		// Without room before the caret, the clusters after it are
+		// dropped.
+		{"abcd", 0, "bcd", 0},
+	}
+	for _, tc := range tests {
+		got, caret, ok := f(tc.txt, tc.caret)
+		if !ok || got != tc.want || caret != tc.wantCaret {
+			t.Errorf("filter(%q, %d) = %q, %d, %v; want %q, %d", tc.txt, tc.caret, got, caret, ok, tc.want, tc.wantCaret)
+		}
+	}
+}
+
+func TestEditorReadFrom(t *testing.T) {
+	lt := text.NewShaper(text.NoSystemFonts(), text.WithCollection(gofont.Collection()))
+	gtx := layout.Context{
+		Ops:         new(op.Ops),
+		Constraints: layout.Exact(image.Pt(300, 300)),
+	}
+	doc := strings.Repeat("a line of a large document\n", 10000)
+	e := new(Editor)
+	e.SetText("old")
+	n, err := e.ReadFrom(strings.NewReader(doc))
+	if err != nil || n != int64(len(doc)) {
+		t.Fatalf("ReadFrom read %d bytes, %v; want %d", n, err, len(doc))
+	}
+	e.Layout(gtx, lt, font.Font{}, 14, op.CallOp{}, op.CallOp{})
+	if e.Size() != int64(len(doc)) || e.Len() != len(doc) {
+		t.Errorf("size %d and length %d, want %d", e.Size(), e.Len(), len(doc))
+	}
+	// ReadFrom clears the history.
+	e.undo()
+	if e.Size() != int64(len(doc)) {
+		t.Error("undo restored the text from before ReadFrom")
+	}
+	// Stream the text in parts after an edit.
+	e.SetCaret(5, 5)
+	e.Insert("X")
+	want := doc[:5] + "X" + doc[5:]
+	var got strings.Builder
+	buf := make([]byte, 4096)
+	for off := int64(0); ; {
+		n, err := e.ReadAt(buf, off)
+		got.Write(buf[:n])
+		off += int64(n)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got.String() != want {
+		t.Error("streamed text differs from the edited document")
+	}
+}
+
+func TestEditorSemanticValue(t *testing.T) {
+	lt := text.NewShaper(text.NoSystemFonts(), text.WithCollection(gofont.Collection()))
+	gtx := layout.Context{
+		Ops:         new(op.Ops),
+		Constraints: layout.Exact(image.Pt(300, 300)),
+	}
+	e := new(Editor)
+	e.SetText("secret")
+	e.Layout(gtx, lt, font.Font{}, 14, op.CallOp{}, op.CallOp{})
+	if got := e.semantic.value; got != "secret" {
+		t.Errorf("described value %q, want %q", got, "secret")
+	}
+	// The text isn't read again while it doesn't change.
+	e.scratch = nil
+	e.Layout(gtx, lt, font.Font{}, 14, op.CallOp{}, op.CallOp{})
+	if e.scratch != nil {
+		t.Error("the text was read again without changes")
+	}
+	e.Mask = '*'
+	e.Layout(gtx, lt, font.Font{}, 14, op.CallOp{}, op.CallOp{})
+	if got := e.semantic.value; got != "******" {
+		t.Errorf("described masked value %q, want %q", got, "******")
+	}
+	e.Insert("!")
+	e.Layout(gtx, lt, font.Font{}, 14, op.CallOp{}, op.CallOp{})
+	if got := e.semantic.value; got != "*******" {
+		t.Errorf("described value %q after an edit, want %q", got, "*******")
+	}
+}
+
+func TestEditorDeleteWord(t *testing.T) {
+	lt := text.NewShaper(text.NoSystemFonts(), text.WithCollection(gofont.Collection()))
+	gtx := layout.Context{
+		Ops:         new(op.Ops),
+		Constraints: layout.Exact(image.Pt(300, 300)),
+	}
+	const txt = "one  twe\u0301  three"
+	tests := []struct {
+		caret    int
+		distance int
+		want     string
+	}{
+		{caret: 0, distance: 1, want: "  twe\u0301  three"},
+		{caret: 0, distance: 2, want: "twe\u0301  three"},
+		{caret: 3, distance: 2, want: "one  three"},
+		{caret: 9, distance: -1, want: "one    three"},
+		{caret: 9, distance: -2, want: "one  three"},
+		{caret: 9, distance: -10, want: "  three"},
+		{caret: 11, distance: 10, want: "one  twe\u0301  "},
+		{caret: 16, distance: 1, want: txt},
+		{caret: 0, distance: -1, want: txt},
+	}
+	for _, tc := range tests {
+		e := new(Editor)
+		e.SetText(txt)
+		e.Layout(gtx, lt, font.Font{}, 14, op.CallOp{}, op.CallOp{})
+		e.SetCaret(tc.caret, tc.caret)
+		e.deleteWord(tc.distance)
+		if got := e.Text(); got != tc.want {
+			t.Errorf("delete %d words at %d: got %q, want %q", tc.distance, tc.caret, got, tc.want)
+		}
+	}
+}
+
+func TestEditorFind(t *testing.T) {
+	e := new(Editor)
+	e.SetText("Gopher go GO. Gö go")
+	matches, err := e.FindAll("go", FindOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Match{{0, 2}, {7, 9}, {10, 12}, {17, 19}}
+	if len(matches) != len(want) {
+		t.Fatalf("found %v, want %v", matches, want)
+	}
+	for i, m := range matches {
+		if m != want[i] {
+			t.Errorf("match %d is %v, want %v", i, m, want[i])
+		}
+	}
+	if matches, _ := e.FindAll("go", FindOptions{MatchCase: true}); len(matches) != 2 {
+		t.Errorf("case sensitive search found %v, want 2 matches", matches)
+	}
+
+	// Searches wrap around the text.
+	e.SetCaret(12, 12)
+	for _, w := range []Match{{17, 19}, {0, 2}} {
+		if ok, _ := e.FindNext("go", FindOptions{}); !ok {
+			t.Fatal("FindNext found no match")
+		}
+		if start, end := e.Selection(); start != w.End || end != w.Start {
+			t.Errorf("FindNext selected [%d,%d), want %v", end, start, w)
+		}
+	}
+	e.FindPrev("go", FindOptions{})
+	if start, end := e.Selection(); start != 19 || end != 17 {
+		t.Errorf("FindPrev selected [%d,%d), want [17,19)", end, start)
+	}
+
+	if _, err := e.FindAll("(", FindOptions{Regexp: true}); err == nil {
+		t.Error("invalid regexp didn't fail")
+	}
+
+	// Replace the selected match, then the next.
+	e.SetText("a1 b2 c3")
+	opts := FindOptions{Regexp: true}
+	e.FindNext(`(\w)(\d)`, opts)
+	if ok, _ := e.ReplaceNext(`(\w)(\d)`, "$2$1", opts); !ok {
+		t.Fatal("selected match not replaced")
+	}
+	if got := e.Text(); got != "1a b2 c3" {
+		t.Errorf("replaced text is %q", got)
+	}
+	if got := e.SelectedText(); got != "b2" {
+		t.Errorf("selected %q after replacing, want the next match", got)
+	}
+	if n, _ := e.ReplaceAll(`(\w)(\d)`, "$2$1", opts); n != 2 {
+		t.Errorf("replaced %d matches, want 2", n)
+	}
+	if got := e.Text(); got != "1a 2b 3c" {
+		t.Errorf("replaced all text is %q", got)
+	}
+	// Replacing all is undone in one step.
+	e.undo()
+	if got := e.Text(); got != "1a b2 c3" {
+		t.Errorf("undone text is %q", got)
+	}
+}
@@ -0,0 +1,162 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package widget
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Seikaijyu/gio/text"
+)
+
+// An InputFilter transforms or rejects edits of the text of an Editor as
+// they happen. It is given the text as it would be after an edit, and the
+// position of the caret after the edit in runes. It returns the text and
+// caret position to use instead, or false to reject the edit.
+type InputFilter func(text string, caret int) (string, int, bool)
+
+// FilterRunes returns an InputFilter that removes the runes not allowed,
+// such as unicode.IsDigit for numeric input.
+func FilterRunes(allowed func(r rune) bool) InputFilter {
+	return func(txt string, caret int) (string, int, bool) {
+		var b strings.Builder
+		newCaret := 0
+		i := 0
+		for _, r := range txt {
+			if allowed(r) {
+				b.WriteRune(r)
+				if i < caret {
+					newCaret++
+				}
+			}
+			i++
+		}
+		return b.String(), newCaret, true
+	}
+}
+
+// MaskFilter returns an InputFilter that formats text by a pattern, such
+// as "(###) ###-####" for phone numbers. In the pattern, '#' stands for a
+// digit, '?' for a letter and '*' for any rune, and other runes are
+// inserted as is. Runes that don't fit the pattern are removed.
+func MaskFilter(pattern string) InputFilter {
+	mask := []rune(pattern)
+	fits := func(p, r rune) bool {
+		switch p {
+		case '#':
+			return unicode.IsDigit(r)
+		case '?':
+			return unicode.IsLetter(r)
+		case '*':
+			return true
+		}
+		return false
+	}
+	return func(txt string, caret int) (string, int, bool) {
+		var b strings.Builder
+		newCaret := -1
+		if caret == 0 {
+			newCaret = 0
+		}
+		runes := []rune(txt)
+		j := 0
+		for i := 0; i < len(runes) && j < len(mask); {
+			r, p := runes[i], mask[j]
+			switch {
+			case p == '#' || p == '?' || p == '*':
+				if fits(p, r) {
+					b.WriteRune(r)
+					j++
+				}
+				i++
+			case r == p:
+				b.WriteRune(r)
+				i++
+				j++
+			default:
+				// Insert a literal of the pattern.
+				b.WriteRune(p)
+				j++
+				continue
+			}
+			if i == caret {
+				newCaret = utf8.RuneCountInString(b.String())
+			}
+		}
+		res := b.String()
+		if newCaret == -1 {
+			newCaret = utf8.RuneCountInString(res)
+		}
+		return res, newCaret, true
+	}
+}
+
+// MaxGraphemesFilter returns an InputFilter that limits text to n
+// grapheme clusters, the characters as perceived by users. The clusters
+// that don't fit are dropped from the edit, those just before the caret,
+// so that inserting into a full text leaves the text as it was.
+func MaxGraphemesFilter(n int) InputFilter {
+	return func(txt string, caret int) (string, int, bool) {
+		var seg text.GraphemeSegmenter
+		bounds := seg.Boundaries(nil, txt)
+		excess := len(bounds) - 1 - n
+		if excess <= 0 {
+			return txt, caret, true
+		}
+		// Find the first cluster boundary at or after the caret.
+		caretOff := len(txt)
+		for i := range txt {
+			if caret == 0 {
+				caretOff = i
+				break
+			}
+			caret--
+		}
+		c := sort.SearchInts(bounds, caretOff)
+		from := c - excess
+		if from < 0 {
+			from = 0
+		}
+		start, end := bounds[from], bounds[from+excess]
+		return txt[:start] + txt[end:], utf8.RuneCountInString(txt[:start]), true
+	}
+}
+
+// ChainFilters returns an InputFilter that applies filters in order. It
+// rejects edits rejected by any of them.
+func ChainFilters(filters ...InputFilter) InputFilter {
+	return func(txt string, caret int) (string, int, bool) {
+		for _, f := range filters {
+			var ok bool
+			txt, caret, ok = f(txt, caret)
+			if !ok {
+				return "", 0, false
+			}
+		}
+		return txt, caret, true
+	}
+}
+
+// commonAffixes returns the lengths in bytes of the longest common prefix
+// and suffix of a and b that end at rune boundaries and don't overlap.
+func commonAffixes(a, b string) (prefix, suffix int) {
+	for prefix < len(a) && prefix < len(b) {
+		ra, n := utf8.DecodeRuneInString(a[prefix:])
+		rb, _ := utf8.DecodeRuneInString(b[prefix:])
+		if ra != rb {
+			break
+		}
+		prefix += n
+	}
+	for suffix < len(a)-prefix && suffix < len(b)-prefix {
+		ra, n := utf8.DecodeLastRuneInString(a[:len(a)-suffix])
+		rb, _ := utf8.DecodeLastRuneInString(b[:len(b)-suffix])
+		if ra != rb {
+			break
+		}
+		suffix += n
+	}
+	return prefix, suffix
+}
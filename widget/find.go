@@ -0,0 +1,184 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package widget
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// FindOptions control how the Editor search methods match text.
+type FindOptions struct {
+	// MatchCase makes matching case sensitive.
+	MatchCase bool
+	// Regexp interprets the query as a regular expression in the syntax
+	// of package regexp. Replacements may then refer to submatches, such
+	// as $1.
+	Regexp bool
+}
+
+// Match is a range of text matched by a search, as rune offsets. Use
+// Editor.Regions to highlight it.
+type Match struct {
+	Start, End int
+}
+
+// FindAll returns the matches of query in the text, in order. Matches
+// don't overlap, and empty matches are ignored. The error is non-nil if
+// query is an invalid regular expression.
+func (e *Editor) FindAll(query string, opts FindOptions) ([]Match, error) {
+	_, txt, locs, err := e.findAll(query, opts)
+	if err != nil {
+		return nil, err
+	}
+	return runeMatches(txt, locs), nil
+}
+
+// FindNext selects the first match of query after the selection,
+// wrapping around the end of the text, and scrolls it into view. It
+// reports whether a match was found.
+func (e *Editor) FindNext(query string, opts FindOptions) (bool, error) {
+	return e.find(query, opts, 1)
+}
+
+// FindPrev is like FindNext, but selects the last match before the
+// selection.
+func (e *Editor) FindPrev(query string, opts FindOptions) (bool, error) {
+	return e.find(query, opts, -1)
+}
+
+// ReplaceNext replaces the selection with replacement if the selection
+// is a match of query, and selects the next match. It reports whether
+// the selection was replaced.
+func (e *Editor) ReplaceNext(query, replacement string, opts FindOptions) (bool, error) {
+	re, txt, locs, err := e.findAll(query, opts)
+	if err != nil {
+		return false, err
+	}
+	start, end := e.text.Selection()
+	start, end = min(start, end), max(start, end)
+	matches := runeMatches(txt, locs)
+	replaced := false
+	for i, m := range matches {
+		if m.Start == start && m.End == end {
+			s := e.expand(re, replacement, txt, locs[i], opts)
+			e.text.ClearCarets()
+			moves := e.replace(start, end, s, true)
+			e.text.SetCaret(start+moves, start+moves)
+			replaced = true
+			break
+		}
+	}
+	if _, err := e.find(query, opts, 1); err != nil {
+		return replaced, err
+	}
+	e.scrollCaret = true
+	e.scroller.Stop()
+	return replaced, nil
+}
+
+// ReplaceAll replaces every match of query with replacement, as a
+// single step of the undo history. It returns the number of matches
+// replaced.
+func (e *Editor) ReplaceAll(query, replacement string, opts FindOptions) (int, error) {
+	re, txt, locs, err := e.findAll(query, opts)
+	if err != nil || len(locs) == 0 {
+		return 0, err
+	}
+	matches := runeMatches(txt, locs)
+	e.batching, e.chained = true, false
+	// Replace from the end, to keep the offsets of earlier matches.
+	for i := len(matches) - 1; i >= 0; i-- {
+		m := matches[i]
+		e.replace(m.Start, m.End, e.expand(re, replacement, txt, locs[i], opts), true)
+	}
+	e.batching, e.chained = false, false
+	return len(matches), nil
+}
+
+// find selects the next match of query in direction dir.
+func (e *Editor) find(query string, opts FindOptions, dir int) (bool, error) {
+	_, txt, locs, err := e.findAll(query, opts)
+	if err != nil || len(locs) == 0 {
+		return false, err
+	}
+	matches := runeMatches(txt, locs)
+	start, end := e.text.Selection()
+	start, end = min(start, end), max(start, end)
+	var m Match
+	if dir > 0 {
+		m = matches[0]
+		for _, cand := range matches {
+			if cand.Start >= end {
+				m = cand
+				break
+			}
+		}
+	} else {
+		m = matches[len(matches)-1]
+		for i := len(matches) - 1; i >= 0; i-- {
+			if cand := matches[i]; cand.End <= start {
+				m = cand
+				break
+			}
+		}
+	}
+	e.text.ClearCarets()
+	e.SetCaret(m.End, m.Start)
+	return true, nil
+}
+
+// findAll returns the compiled query, the text and the byte offsets of
+// the non-empty matches of the query with their submatches.
+func (e *Editor) findAll(query string, opts FindOptions) (*regexp.Regexp, string, [][]int, error) {
+	e.initBuffer()
+	if query == "" {
+		return nil, "", nil, nil
+	}
+	pattern := query
+	if !opts.Regexp {
+		pattern = regexp.QuoteMeta(query)
+	}
+	if !opts.MatchCase {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	txt := e.Text()
+	var locs [][]int
+	for _, loc := range re.FindAllStringSubmatchIndex(txt, -1) {
+		if loc[0] < loc[1] {
+			locs = append(locs, loc)
+		}
+	}
+	return re, txt, locs, nil
+}
+
+// expand returns the replacement of the match at loc in txt.
+func (e *Editor) expand(re *regexp.Regexp, replacement, txt string, loc []int, opts FindOptions) string {
+	if opts.Regexp {
+		replacement = string(re.ExpandString(nil, replacement, txt, loc))
+	}
+	if e.SingleLine {
+		replacement = strings.ReplaceAll(replacement, "\n", " ")
+	}
+	return replacement
+}
+
+// runeMatches converts the byte offsets of matches in txt to rune
+// offsets.
+func runeMatches(txt string, locs [][]int) []Match {
+	matches := make([]Match, 0, len(locs))
+	off, runes := 0, 0
+	for _, loc := range locs {
+		runes += utf8.RuneCountInString(txt[off:loc[0]])
+		start := runes
+		runes += utf8.RuneCountInString(txt[loc[0]:loc[1]])
+		off = loc[1]
+		matches = append(matches, Match{Start: start, End: runes})
+	}
+	return matches
+}
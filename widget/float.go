@@ -4,6 +4,7 @@ package widget
 
 import (
 	"image"
+	"math"
 
 	"github.com/Seikaijyu/gio/gesture"
 	"github.com/Seikaijyu/gio/io/pointer"
@@ -14,14 +15,128 @@ import (
 
 // Float is for selecting a value in a range.
 type Float struct {
-	// Value is the value of the Float, in the [0; 1] range.
+	// Value is the value of the Float, in the [0; 1] range, or
+	// the range of Scale if set.
 	Value float32
+	// Scale maps positions along the Float to values. A nil
+	// Scale maps positions directly to values.
+	Scale Scale
 
 	drag   gesture.Drag
 	axis   layout.Axis
 	length float32
 }
 
+// Scale maps between positions along a Float and values.
+type Scale interface {
+	// Value returns the value at position pos, in the [0; 1] range.
+	Value(pos float32) float32
+	// Pos returns the position of the value v, in the [0; 1] range.
+	Pos(v float32) float32
+}
+
+// LinearScale returns a Scale that maps positions linearly to values
+// in the [min; max] range.
+func LinearScale(min, max float32) Scale {
+	return linearScale{min: min, max: max}
+}
+
+// LogScale returns a Scale that maps positions to values in the
+// [min; max] range such that equal distances correspond to equal
+// ratios of values, as is common for zoom levels, frequencies and
+// gains. Both min and max must be positive.
+func LogScale(min, max float32) Scale {
+	return logScale{min: min, max: max}
+}
+
+// PowScale returns a Scale that maps positions to values in the
+// [min; max] range along the curve pos^exp. An exp larger than 1 gives
+// finer control of values near min, as is common for volume controls.
+func PowScale(min, max, exp float32) Scale {
+	return powScale{min: min, max: max, exp: exp}
+}
+
+type linearScale struct {
+	min, max float32
+}
+
+type logScale struct {
+	min, max float32
+}
+
+type powScale struct {
+	min, max, exp float32
+}
+
+func (s linearScale) Value(pos float32) float32 {
+	return s.min + pos*(s.max-s.min)
+}
+
+func (s linearScale) Pos(v float32) float32 {
+	if s.max == s.min {
+		return 0
+	}
+	return (v - s.min) / (s.max - s.min)
+}
+
+func (s logScale) Value(pos float32) float32 {
+	return s.min * float32(math.Pow(float64(s.max/s.min), float64(pos)))
+}
+
+func (s logScale) Pos(v float32) float32 {
+	if v <= 0 || s.max == s.min {
+		return 0
+	}
+	return float32(math.Log(float64(v/s.min)) / math.Log(float64(s.max/s.min)))
+}
+
+func (s powScale) Value(pos float32) float32 {
+	return s.min + float32(math.Pow(float64(pos), float64(s.exp)))*(s.max-s.min)
+}
+
+func (s powScale) Pos(v float32) float32 {
+	if s.max == s.min || s.exp == 0 {
+		return 0
+	}
+	t := (v - s.min) / (s.max - s.min)
+	if t <= 0 {
+		return 0
+	}
+	return float32(math.Pow(float64(t), 1/float64(s.exp)))
+}
+
+// Pos returns the position of Value along f, in the [0; 1] range.
+func (f *Float) Pos() float32 {
+	pos := f.Value
+	if f.Scale != nil {
+		pos = f.Scale.Pos(f.Value)
+	}
+	return clampPos(pos)
+}
+
+// SetPos sets Value to the value at position pos along f. Stepping
+// the position, for example in response to key presses, moves the
+// value in steps that are even along the Scale.
+func (f *Float) SetPos(pos float32) {
+	pos = clampPos(pos)
+	if f.Scale != nil {
+		f.Value = f.Scale.Value(pos)
+	} else {
+		f.Value = pos
+	}
+}
+
+func clampPos(pos float32) float32 {
+	switch {
+	case pos < 0:
+		return 0
+	case pos > 1:
+		return 1
+	default:
+		return pos
+	}
+}
+
 // Dragging returns whether the value is being interacted with.
 func (f *Float) Dragging() bool { return f.drag.Dragging() }
 
@@ -48,18 +163,14 @@ func (f *Float) Layout(gtx layout.Context, axis layout.Axis, pointerMargin unit.
 // The range of f is set by the minimum constraints main axis value.
 func (f *Float) Update(gtx layout.Context) bool {
 	changed := false
+	f.drag.MouseSlop = gtx.Gestures.DragThreshold
 	for _, e := range f.drag.Update(gtx.Metric, gtx, gesture.Axis(f.axis)) {
 		if f.length > 0 && (e.Kind == pointer.Press || e.Kind == pointer.Drag) {
 			pos := e.Position.X
 			if f.axis == layout.Vertical {
 				pos = f.length - e.Position.Y
 			}
-			f.Value = pos / f.length
-			if f.Value < 0 {
-				f.Value = 0
-			} else if f.Value > 1 {
-				f.Value = 1
-			}
+			f.SetPos(pos / f.length)
 			changed = true
 		}
 	}
@@ -0,0 +1,41 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package widget
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFloatScales(t *testing.T) {
+	scales := []struct {
+		name  string
+		scale Scale
+		mid   float32
+	}{
+		{"linear", LinearScale(-10, 10), 0},
+		{"log", LogScale(0.1, 10), 1},
+		{"pow", PowScale(0, 100, 2), 25},
+	}
+	for _, s := range scales {
+		f := &Float{Scale: s.scale}
+		f.SetPos(.5)
+		if !near(f.Value, s.mid) {
+			t.Errorf("%s: value at middle is %v, want %v", s.name, f.Value, s.mid)
+		}
+		for _, pos := range []float32{0, .25, .5, 1} {
+			f.SetPos(pos)
+			if got := f.Pos(); !near(got, pos) {
+				t.Errorf("%s: position of value %v is %v, want %v", s.name, f.Value, got, pos)
+			}
+		}
+		f.SetPos(2)
+		if got := f.Pos(); got != 1 {
+			t.Errorf("%s: position %v out of range", s.name, got)
+		}
+	}
+}
+
+func near(a, b float32) bool {
+	return math.Abs(float64(a-b)) < 1e-4
+}
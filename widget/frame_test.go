@@ -0,0 +1,26 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package widget
+
+import (
+	"image"
+
+	"github.com/Seikaijyu/gio/io/router"
+	"github.com/Seikaijyu/gio/io/system"
+	"github.com/Seikaijyu/gio/layout"
+	"github.com/Seikaijyu/gio/op"
+	"github.com/Seikaijyu/gio/unit"
+)
+
+// layoutFrame lays out a frame of the given size with w, at one pixel
+// per dp, and routes the resulting ops through r.
+func layoutFrame(r *router.Router, ops *op.Ops, size image.Point, w func(gtx layout.Context)) {
+	ops.Reset()
+	gtx := layout.NewContext(ops, system.FrameEvent{
+		Metric: unit.Metric{PxPerDp: 1, PxPerSp: 1},
+		Size:   size,
+		Queue:  r,
+	})
+	w(gtx)
+	r.Frame(ops)
+}
@@ -0,0 +1,229 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package widget
+
+import (
+	"image"
+	"math"
+
+	"github.com/Seikaijyu/gio/gesture"
+	"github.com/Seikaijyu/gio/layout"
+	"github.com/Seikaijyu/gio/op"
+	"github.com/Seikaijyu/gio/op/clip"
+	"github.com/Seikaijyu/gio/unit"
+)
+
+// Grid is the state of a grid of cells that scrolls along both axes,
+// such as a spreadsheet. The grid is virtualized: only the visible cells
+// are laid out, so it handles very large numbers of rows and columns.
+//
+// The leading FrozenRows rows and FrozenColumns columns don't scroll,
+// such as for headers.
+type Grid struct {
+	// Horizontal and Vertical are the scrollbars of the columns and the
+	// rows, for drawing scrollbars such as with material.Grid.
+	Horizontal, Vertical Scrollbar
+	// Columns and Rows are the scroll positions along the columns and
+	// the rows. First is the index of the first visible row or column
+	// after the frozen ones, and Count the number of visible rows or
+	// columns after the frozen ones. Length estimates the size of the
+	// rows or columns that scroll.
+	Columns, Rows layout.Position
+	// FrozenRows and FrozenColumns are the number of leading rows and
+	// columns that don't scroll.
+	FrozenRows, FrozenColumns int
+
+	hscroll, vscroll gesture.Scroll
+	// rows and cols are the number of rows and columns, and viewport the
+	// size of the scrolling part of the grid, as of the last Layout.
+	rows, cols int
+	viewport   image.Point
+	// rowSpans and colSpans are scratch space for the visible rows and
+	// columns.
+	rowSpans, colSpans []gridSpan
+}
+
+// GridSize returns the size of a row or column of a Grid: the height of
+// row index along the vertical axis and the width of column index along
+// the horizontal axis.
+type GridSize func(axis layout.Axis, index int) unit.Dp
+
+// GridCell lays out the cell of a Grid at a row and column. The
+// constraints of a cell are the exact size of its row and column.
+type GridCell func(gtx layout.Context, row, col int) layout.Dimensions
+
+// gridSpan is a visible row or column of a Grid.
+type gridSpan struct {
+	index int
+	// off and size are the position and size along the axis.
+	off, size int
+}
+
+// ScrollTo scrolls the row and column into view at the leading edges of
+// the scrolling part of the grid. Use -1 to keep the row or column.
+func (g *Grid) ScrollTo(row, col int) {
+	if row >= 0 {
+		g.Rows.First, g.Rows.Offset = row, 0
+	}
+	if col >= 0 {
+		g.Columns.First, g.Columns.Offset = col, 0
+	}
+}
+
+// ScrollBy scrolls the rows along the vertical axis, or the columns along
+// the horizontal axis, by num of them, estimated from their average
+// size. Use it to apply the ScrollDistance of the scrollbars.
+func (g *Grid) ScrollBy(axis layout.Axis, num float32) {
+	pos, n := &g.Rows, g.rows-g.FrozenRows
+	if axis == layout.Horizontal {
+		pos, n = &g.Columns, g.cols-g.FrozenColumns
+	}
+	if n <= 0 {
+		return
+	}
+	i, f := math.Modf(float64(num))
+	pos.First += int(i)
+	size := float64(pos.Length) / float64(n)
+	pos.Offset += int(math.Round(size * f))
+}
+
+// Viewport returns the size of the scrolling part of the grid, without
+// the frozen rows and columns, as of the last Layout.
+func (g *Grid) Viewport() image.Point {
+	return g.viewport
+}
+
+// Layout the visible cells of a grid of rows and columns, filling the
+// maximum constraints.
+func (g *Grid) Layout(gtx layout.Context, rows, cols int, size GridSize, cell GridCell) layout.Dimensions {
+	g.rows, g.cols = rows, cols
+	hdelta := g.hscroll.Update(gtx.Metric, gtx, gtx.Now, gesture.Horizontal)
+	vdelta := g.vscroll.Update(gtx.Metric, gtx, gtx.Now, gesture.Vertical)
+	view := gtx.Constraints.Max
+	var frozen, end image.Point
+	g.colSpans, frozen.X, end.X = g.layoutAxis(gtx, layout.Horizontal, &g.Columns, g.colSpans[:0], cols, g.FrozenColumns, view.X, hdelta, size)
+	g.rowSpans, frozen.Y, end.Y = g.layoutAxis(gtx, layout.Vertical, &g.Rows, g.rowSpans[:0], rows, g.FrozenRows, view.Y, vdelta, size)
+	dims := gtx.Constraints.Constrain(image.Pt(min(end.X, view.X), min(end.Y, view.Y)))
+	g.viewport = image.Pt(max(dims.X-frozen.X, 0), max(dims.Y-frozen.Y, 0))
+	defer clip.Rect{Max: dims}.Push(gtx.Ops).Pop()
+	g.hscroll.Add(gtx.Ops, scrollRange(layout.Horizontal, g.Columns, g.FrozenColumns, cols))
+	g.vscroll.Add(gtx.Ops, scrollRange(layout.Vertical, g.Rows, g.FrozenRows, rows))
+
+	nrows, ncols := min(g.FrozenRows, rows), min(g.FrozenColumns, cols)
+	// Lay out the scrolling cells first, and then the frozen cells over
+	// them.
+	quadrants := [...]struct {
+		rows, cols []gridSpan
+		clip       image.Rectangle
+	}{
+		{g.rowSpans[nrows:], g.colSpans[ncols:], image.Rectangle{Min: frozen, Max: dims}},
+		{g.rowSpans[:nrows], g.colSpans[ncols:], image.Rect(frozen.X, 0, dims.X, frozen.Y)},
+		{g.rowSpans[nrows:], g.colSpans[:ncols], image.Rect(0, frozen.Y, frozen.X, dims.Y)},
+		{g.rowSpans[:nrows], g.colSpans[:ncols], image.Rectangle{Max: frozen}},
+	}
+	for _, q := range quadrants {
+		cl := clip.Rect(q.clip).Push(gtx.Ops)
+		for _, r := range q.rows {
+			for _, c := range q.cols {
+				cgtx := gtx
+				cgtx.Constraints = layout.Exact(image.Pt(c.size, r.size))
+				off := op.Offset(image.Pt(c.off, r.off)).Push(gtx.Ops)
+				cell(cgtx, r.index, c.index)
+				off.Pop()
+			}
+		}
+		cl.Pop()
+	}
+	return layout.Dimensions{Size: dims}
+}
+
+// layoutAxis updates the scroll position pos of n rows or columns, of
+// which the leading frozen don't scroll, in a viewport of length view,
+// and appends their visible spans to spans. It returns the spans, the
+// length of the frozen spans, and the end of the last span.
+func (g *Grid) layoutAxis(gtx layout.Context, axis layout.Axis, pos *layout.Position, spans []gridSpan, n, frozen, view, delta int, size GridSize) ([]gridSpan, int, int) {
+	px := func(i int) int {
+		return gtx.Dp(size(axis, i))
+	}
+	frozen = max(min(frozen, n), 0)
+	off := 0
+	for i := 0; i < frozen; i++ {
+		s := px(i)
+		spans = append(spans, gridSpan{index: i, off: off, size: s})
+		off += s
+	}
+	frozenLen := off
+	if pos.First < frozen {
+		pos.First, pos.Offset = frozen, 0
+	}
+	if pos.First >= n {
+		pos.First, pos.Offset = max(n-1, frozen), 0
+	}
+	pos.Offset += delta
+	for pos.Offset < 0 && pos.First > frozen {
+		pos.First--
+		pos.Offset += px(pos.First)
+	}
+	for pos.First < n-1 && pos.Offset >= px(pos.First) {
+		pos.Offset -= px(pos.First)
+		pos.First++
+	}
+	if pos.Offset < 0 || pos.First >= n {
+		pos.Offset = 0
+	}
+	// visible appends the visible scrolling spans and returns their end.
+	visible := func() ([]gridSpan, int) {
+		spans := spans[:frozen]
+		off := frozenLen - pos.Offset
+		for i := pos.First; i < n && off < view; i++ {
+			s := px(i)
+			spans = append(spans, gridSpan{index: i, off: off, size: s})
+			off += s
+		}
+		return spans, off
+	}
+	spans, end := visible()
+	if last := len(spans) - 1; end < view && (last < 0 || spans[last].index == n-1) && (pos.First > frozen || pos.Offset > 0) {
+		// Scroll back to fill the viewport.
+		gap := view - end
+		d := min(gap, pos.Offset)
+		pos.Offset -= d
+		gap -= d
+		for gap > 0 && pos.First > frozen {
+			pos.First--
+			s := px(pos.First)
+			if s > gap {
+				pos.Offset = s - gap
+			}
+			gap -= s
+		}
+		spans, end = visible()
+	}
+	scrolled := spans[frozen:]
+	pos.Count = len(scrolled)
+	pos.OffsetLast = view - end
+	pos.Length = 0
+	if len(scrolled) > 0 {
+		laidOut := scrolled[len(scrolled)-1].off + scrolled[len(scrolled)-1].size - scrolled[0].off
+		pos.Length = laidOut * (n - frozen) / len(scrolled)
+	}
+	return spans, frozenLen, end
+}
+
+// scrollRange returns the scroll range along axis of the scroll position
+// pos of n rows or columns after frozen ones, limited to the hidden parts
+// at the ends.
+func scrollRange(axis layout.Axis, pos layout.Position, frozen, n int) image.Rectangle {
+	const inf = 1e6
+	lo, hi := int(-inf), int(inf)
+	if pos.First <= frozen {
+		lo = min(-pos.Offset, 0)
+	}
+	if pos.First+pos.Count >= n {
+		hi = max(-pos.OffsetLast, 0)
+	}
+	return image.Rectangle{
+		Min: axis.Convert(image.Pt(lo, 0)),
+		Max: axis.Convert(image.Pt(hi, 0)),
+	}
+}
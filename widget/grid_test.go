@@ -0,0 +1,85 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package widget
+
+import (
+	"image"
+	"testing"
+
+	"github.com/Seikaijyu/gio/f32"
+	"github.com/Seikaijyu/gio/io/pointer"
+	"github.com/Seikaijyu/gio/io/router"
+	"github.com/Seikaijyu/gio/layout"
+	"github.com/Seikaijyu/gio/op"
+	"github.com/Seikaijyu/gio/unit"
+)
+
+func TestGrid(t *testing.T) {
+	g := &Grid{FrozenRows: 1, FrozenColumns: 1}
+	var (
+		ops   op.Ops
+		r     router.Router
+		cells map[image.Point]image.Point
+	)
+	const rows, cols = 100000, 1000
+	size := func(axis layout.Axis, i int) unit.Dp {
+		if axis == layout.Horizontal {
+			return 20
+		}
+		return 10
+	}
+	frame := func() (dims layout.Dimensions) {
+		layoutFrame(&r, &ops, image.Pt(100, 50), func(gtx layout.Context) {
+			cells = make(map[image.Point]image.Point)
+			dims = g.Layout(gtx, rows, cols, size, func(gtx layout.Context, row, col int) layout.Dimensions {
+				cells[image.Pt(col, row)] = gtx.Constraints.Max
+				return layout.Dimensions{Size: gtx.Constraints.Max}
+			})
+		})
+		return dims
+	}
+	if dims := frame(); dims.Size != image.Pt(100, 50) {
+		t.Errorf("grid size %v, want the constraints", dims.Size)
+	}
+	// Only the visible 5 columns of 5 rows are laid out.
+	if len(cells) != 25 {
+		t.Errorf("laid out %d cells, want 25", len(cells))
+	}
+	if got := cells[image.Pt(4, 4)]; got != image.Pt(20, 10) {
+		t.Errorf("cell size %v, want (20,10)", got)
+	}
+	if g.Rows.First != 1 || g.Columns.First != 1 || g.Rows.Count != 4 {
+		t.Errorf("got rows %+v and columns %+v", g.Rows, g.Columns)
+	}
+
+	// Scrolling moves the rows below the frozen row.
+	r.Queue(
+		pointer.Event{Kind: pointer.Move, Source: pointer.Mouse, Position: f32.Pt(50, 30)},
+		pointer.Event{Kind: pointer.Scroll, Source: pointer.Mouse, Position: f32.Pt(50, 30), Scroll: f32.Pt(45, 25)},
+	)
+	frame()
+	if g.Rows.First != 3 || g.Rows.Offset != 5 || g.Columns.First != 3 || g.Columns.Offset != 5 {
+		t.Errorf("scrolled to rows %+v and columns %+v", g.Rows, g.Columns)
+	}
+	for _, c := range []image.Point{{0, 0}, {0, 3}, {3, 0}, {3, 3}} {
+		if _, ok := cells[c]; !ok {
+			t.Errorf("cell %v not laid out", c)
+		}
+	}
+	if _, ok := cells[image.Pt(1, 1)]; ok {
+		t.Errorf("scrolled cell laid out")
+	}
+
+	// Scrolling past the end fills the viewport with the last rows.
+	g.ScrollTo(rows+10, cols-1)
+	frame()
+	if g.Rows.First != rows-4 || g.Rows.Offset != 0 || g.Rows.OffsetLast != 0 {
+		t.Errorf("scrolled past the end to rows %+v", g.Rows)
+	}
+	if g.Columns.First != cols-4 {
+		t.Errorf("scrolled to the last column to columns %+v", g.Columns)
+	}
+	if _, ok := cells[image.Pt(cols-1, rows-1)]; !ok {
+		t.Errorf("last cell not laid out")
+	}
+}
@@ -3,9 +3,12 @@
 package widget
 
 import (
+	"container/list"
+	"hash/maphash"
 	"image"
 	"image/color"
 	"image/draw"
+	"sync"
 
 	"github.com/Seikaijyu/gio/internal/f32color"
 	"github.com/Seikaijyu/gio/layout"
@@ -18,13 +21,61 @@ import (
 
 type Icon struct {
 	src []byte
+	// hash identifies src in the icon cache.
+	hash uint64
 	// Cached values.
 	op       paint.ImageOp
 	imgSize  int
 	imgColor color.NRGBA
 }
 
-const defaultIconSize = unit.Dp(24)
+// iconKey identifies a rasterized icon in the icon cache.
+type iconKey struct {
+	hash  uint64
+	size  int
+	color color.NRGBA
+}
+
+// iconEntry is a rasterized icon in the icon cache.
+type iconEntry struct {
+	key   iconKey
+	op    paint.ImageOp
+	bytes int
+}
+
+// iconCache is a least-recently-used cache of rasterized icons, shared
+// by every Icon with the same data.
+type iconCache struct {
+	mu      sync.Mutex
+	entries map[iconKey]*list.Element
+	lru     list.List
+	bytes   int
+	limit   int
+}
+
+const (
+	defaultIconSize = unit.Dp(24)
+	// defaultIconCacheSize is the default memory bound of the icon
+	// cache, in bytes.
+	defaultIconCacheSize = 8 << 20
+)
+
+var (
+	iconSeed   = maphash.MakeSeed()
+	iconImages = &iconCache{limit: defaultIconCacheSize}
+)
+
+// SetIconCacheSize sets the maximum number of bytes of rasterized icons
+// kept by the application-wide icon cache. Icons with the same data,
+// size and color share their images through the cache, regardless of
+// the Icon or theme that rasterized them. A size of zero disables the
+// cache.
+func SetIconCacheSize(bytes int) {
+	iconImages.mu.Lock()
+	defer iconImages.mu.Unlock()
+	iconImages.limit = bytes
+	iconImages.evict()
+}
 
 // NewIcon returns a new Icon from IconVG data.
 func NewIcon(data []byte) (*Icon, error) {
@@ -32,7 +83,7 @@ func NewIcon(data []byte) (*Icon, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &Icon{src: data}, nil
+	return &Icon{src: data, hash: maphash.Bytes(iconSeed, data)}, nil
 }
 
 // Layout displays the icon with its size set to the X minimum constraint.
@@ -52,10 +103,26 @@ func (ic *Icon) Layout(gtx layout.Context, color color.NRGBA) layout.Dimensions
 	}
 }
 
+// Prerender rasterizes the icon in color at each of the sizes, in
+// pixels, ahead of layout. The images are kept in the icon cache.
+func (ic *Icon) Prerender(color color.NRGBA, sizes ...int) {
+	for _, sz := range sizes {
+		iconImages.get(ic, sz, color)
+	}
+}
+
 func (ic *Icon) image(sz int, color color.NRGBA) paint.ImageOp {
 	if sz == ic.imgSize && color == ic.imgColor {
 		return ic.op
 	}
+	ic.op = iconImages.get(ic, sz, color)
+	ic.imgSize = sz
+	ic.imgColor = color
+	return ic.op
+}
+
+// rasterize draws the icon at size sz in color.
+func (ic *Icon) rasterize(sz int, color color.NRGBA) *image.RGBA {
 	m, _ := iconvg.DecodeMetadata(ic.src)
 	dx, dy := m.ViewBox.AspectRatio()
 	img := image.NewRGBA(image.Rectangle{Max: image.Point{X: sz, Y: int(float32(sz) * dy / dx)}})
@@ -65,8 +132,46 @@ func (ic *Icon) image(sz int, color color.NRGBA) paint.ImageOp {
 	iconvg.Decode(&ico, ic.src, &iconvg.DecodeOptions{
 		Palette: &m.Palette,
 	})
-	ic.op = paint.NewImageOp(img)
-	ic.imgSize = sz
-	ic.imgColor = color
-	return ic.op
+	return img
+}
+
+// get returns the image of ic at size sz in color, rasterizing it if
+// it is not in the cache.
+func (c *iconCache) get(ic *Icon, sz int, color color.NRGBA) paint.ImageOp {
+	k := iconKey{hash: ic.hash, size: sz, color: color}
+	c.mu.Lock()
+	if e, ok := c.entries[k]; ok {
+		c.lru.MoveToFront(e)
+		op := e.Value.(*iconEntry).op
+		c.mu.Unlock()
+		return op
+	}
+	c.mu.Unlock()
+	// Rasterize outside the lock; concurrent misses for the same key
+	// rasterize the same image.
+	img := ic.rasterize(sz, color)
+	op := paint.NewImageOp(img)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[k]; ok {
+		return op
+	}
+	if c.entries == nil {
+		c.entries = make(map[iconKey]*list.Element)
+	}
+	e := &iconEntry{key: k, op: op, bytes: len(img.Pix)}
+	c.entries[k] = c.lru.PushFront(e)
+	c.bytes += e.bytes
+	c.evict()
+	return op
+}
+
+// evict removes the least recently used images until the cache is
+// within its limit.
+func (c *iconCache) evict() {
+	for c.bytes > c.limit && c.lru.Len() > 0 {
+		e := c.lru.Remove(c.lru.Back()).(*iconEntry)
+		delete(c.entries, e.key)
+		c.bytes -= e.bytes
+	}
 }
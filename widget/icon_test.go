@@ -29,6 +29,30 @@ func TestIcon_Alpha(t *testing.T) {
 	_ = icon.Layout(gtx, col)
 }
 
+func TestIconCache(t *testing.T) {
+	defer SetIconCacheSize(defaultIconCacheSize)
+	SetIconCacheSize(defaultIconCacheSize)
+	ic1, _ := NewIcon(icons.ToggleCheckBox)
+	ic2, _ := NewIcon(icons.ToggleCheckBox)
+	col := color.NRGBA{R: 0xff, A: 0xff}
+	ic1.Prerender(col, 24, 48)
+	if op1, op2 := ic1.image(48, col), ic2.image(48, col); op1 != op2 {
+		t.Error("icons with the same data, size and color don't share images")
+	}
+	if op1, op2 := ic1.image(24, col), ic2.image(48, col); op1 == op2 {
+		t.Error("icons of different sizes share images")
+	}
+	// Only the most recently used 24x24 image fits.
+	SetIconCacheSize(24 * 24 * 4)
+	if n := iconImages.lru.Len(); n != 1 {
+		t.Errorf("%d images cached after shrinking the cache, want 1", n)
+	}
+	SetIconCacheSize(0)
+	if n := iconImages.lru.Len(); n != 0 {
+		t.Errorf("%d images cached in a disabled cache", n)
+	}
+}
+
 // TestWidgetConstraints tests that widgets returns dimensions within their constraints.
 func TestWidgetConstraints(t *testing.T) {
 	_cs := func(v ...layout.Constraints) []layout.Constraints { return v }
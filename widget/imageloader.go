@@ -0,0 +1,195 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package widget
+
+import (
+	"image"
+	"sync"
+
+	"github.com/Seikaijyu/gio/layout"
+	"github.com/Seikaijyu/gio/op"
+	"github.com/Seikaijyu/gio/op/paint"
+)
+
+// ImageLoader converts images to ImageOps on a background goroutine,
+// so that converting large images doesn't stall frames the way calling
+// paint.NewImageOp during layout does. Converted images are cached
+// within a memory budget, and keep their ImageOp so the GPU copy of
+// the image is reused across frames.
+//
+// Images given to an ImageLoader must not be modified, and must be
+// comparable, such as pointers to the image types of the standard
+// library.
+//
+// The zero value is ready to use. An ImageLoader must not be copied
+// after first use.
+type ImageLoader struct {
+	// Budget is the maximum size in bytes of the converted images
+	// kept by the loader. The least recently used images are dropped
+	// when the budget is exceeded. A zero Budget means 64 MiB.
+	Budget int
+	// Invalidate is called when a conversion completes, typically
+	// with the Invalidate method of the window. If Invalidate is nil,
+	// Load invalidates every frame until its image is converted.
+	Invalidate func()
+
+	mu      sync.Mutex
+	images  map[image.Image]*loadedImage
+	queue   []image.Image
+	running bool
+	// size is the total size of the converted images.
+	size int
+	// clock orders the uses of images for eviction.
+	clock uint64
+}
+
+type loadedImage struct {
+	op    paint.ImageOp
+	ready bool
+	size  int
+	used  uint64
+	done  []func(paint.ImageOp)
+}
+
+// defaultImageBudget is the memory budget of an ImageLoader with a zero
+// Budget.
+const defaultImageBudget = 64 << 20
+
+// Load returns the ImageOp of src and true if src is converted.
+// Otherwise, Load starts the conversion if it's not already running,
+// and returns false. Use a placeholder until the conversion completes.
+func (l *ImageLoader) Load(gtx layout.Context, src image.Image) (paint.ImageOp, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if e := l.lookup(src); e.ready {
+		return e.op, true
+	}
+	if l.Invalidate == nil {
+		op.InvalidateOp{}.Add(gtx.Ops)
+	}
+	return paint.ImageOp{}, false
+}
+
+// Convert starts converting src, and calls done with its ImageOp when
+// the conversion completes. If src is already converted, done is called
+// before Convert returns. Otherwise, done is called from the background
+// goroutine.
+func (l *ImageLoader) Convert(src image.Image, done func(paint.ImageOp)) {
+	l.mu.Lock()
+	e := l.lookup(src)
+	if !e.ready {
+		e.done = append(e.done, done)
+		l.mu.Unlock()
+		return
+	}
+	imgOp := e.op
+	l.mu.Unlock()
+	done(imgOp)
+}
+
+// Forget drops src from the loader. The callbacks of a pending
+// conversion of src are not called.
+func (l *ImageLoader) Forget(src image.Image) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if e, ok := l.images[src]; ok {
+		l.size -= e.size
+		delete(l.images, src)
+	}
+}
+
+// lookup returns the entry of src, and queues its conversion if it is
+// new. It must be called with l.mu held.
+func (l *ImageLoader) lookup(src image.Image) *loadedImage {
+	l.clock++
+	if e, ok := l.images[src]; ok {
+		e.used = l.clock
+		return e
+	}
+	if l.images == nil {
+		l.images = make(map[image.Image]*loadedImage)
+	}
+	e := &loadedImage{used: l.clock}
+	l.images[src] = e
+	l.queue = append(l.queue, src)
+	if !l.running {
+		l.running = true
+		go l.run()
+	}
+	return e
+}
+
+// run converts the queued images until the queue is empty.
+func (l *ImageLoader) run() {
+	for {
+		l.mu.Lock()
+		if len(l.queue) == 0 {
+			l.running = false
+			l.mu.Unlock()
+			return
+		}
+		src := l.queue[0]
+		l.queue[0] = nil
+		l.queue = l.queue[1:]
+		if _, ok := l.images[src]; !ok {
+			// Forgotten before its conversion started.
+			l.mu.Unlock()
+			continue
+		}
+		l.mu.Unlock()
+
+		imgOp := paint.NewImageOp(src)
+
+		l.mu.Lock()
+		e, ok := l.images[src]
+		if !ok {
+			l.mu.Unlock()
+			continue
+		}
+		sz := imgOp.Size()
+		e.op, e.ready = imgOp, true
+		e.size = sz.X * sz.Y * 4
+		l.size += e.size
+		done := e.done
+		e.done = nil
+		l.evict(src)
+		invalidate := l.Invalidate
+		l.mu.Unlock()
+
+		for _, f := range done {
+			f(imgOp)
+		}
+		if invalidate != nil {
+			invalidate()
+		}
+	}
+}
+
+// evict drops the least recently used images other than keep until
+// the converted images fit the budget. It must be called with l.mu
+// held.
+func (l *ImageLoader) evict(keep image.Image) {
+	budget := l.Budget
+	if budget <= 0 {
+		budget = defaultImageBudget
+	}
+	for l.size > budget {
+		var (
+			victim image.Image
+			oldest *loadedImage
+		)
+		for src, e := range l.images {
+			if src == keep || !e.ready {
+				continue
+			}
+			if oldest == nil || e.used < oldest.used {
+				victim, oldest = src, e
+			}
+		}
+		if oldest == nil {
+			return
+		}
+		l.size -= oldest.size
+		delete(l.images, victim)
+	}
+}
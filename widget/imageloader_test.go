@@ -0,0 +1,65 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package widget
+
+import (
+	"image"
+	"testing"
+
+	"github.com/Seikaijyu/gio/layout"
+	"github.com/Seikaijyu/gio/op"
+	"github.com/Seikaijyu/gio/op/paint"
+)
+
+func TestImageLoader(t *testing.T) {
+	gtx := layout.Context{Ops: new(op.Ops)}
+	converted := make(chan struct{}, 10)
+	l := &ImageLoader{
+		// Room for two 10x10 images.
+		Budget: 2 * 10 * 10 * 4,
+		Invalidate: func() {
+			converted <- struct{}{}
+		},
+	}
+	load := func(img image.Image) paint.ImageOp {
+		t.Helper()
+		if _, ok := l.Load(gtx, img); !ok {
+			<-converted
+		}
+		imgOp, ok := l.Load(gtx, img)
+		if !ok {
+			t.Fatal("image not converted after invalidation")
+		}
+		return imgOp
+	}
+	img1 := image.NewNRGBA(image.Rect(0, 0, 10, 10))
+	op1 := load(img1)
+	if op1.Size() != img1.Bounds().Size() {
+		t.Errorf("converted size %v, want %v", op1.Size(), img1.Bounds().Size())
+	}
+	if again := load(img1); again != op1 {
+		t.Error("converted image not reused")
+	}
+	// Convert calls done right away for converted images.
+	called := false
+	l.Convert(img1, func(imgOp paint.ImageOp) {
+		called = imgOp == op1
+	})
+	if !called {
+		t.Error("Convert didn't call done with the converted image")
+	}
+
+	img2 := image.NewNRGBA(image.Rect(0, 0, 10, 10))
+	img3 := image.NewNRGBA(image.Rect(0, 0, 10, 10))
+	load(img2)
+	// Use img1 so img2 is the least recently used image.
+	load(img1)
+	load(img3)
+	if _, ok := l.Load(gtx, img1); !ok {
+		t.Error("recently used image evicted")
+	}
+	if _, ok := l.Load(gtx, img2); ok {
+		t.Error("least recently used image not evicted")
+	}
+	<-converted
+}
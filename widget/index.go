@@ -20,6 +20,8 @@ type lineInfo struct {
 	width           fixed.Int26_6
 	ascent, descent fixed.Int26_6
 	glyphs          int
+	// runes is the rune offset of the start of the line.
+	runes int
 }
 
 type glyphIndex struct {
@@ -37,6 +39,9 @@ type glyphIndex struct {
 	// currentLineGlyphs tracks how many glyphs are contained within the
 	// line that is being indexed.
 	currentLineGlyphs int
+	// currentLineRunes is the rune offset of the start of the line that
+	// is being indexed.
+	currentLineRunes int
 	// pos tracks attributes of the next valid cursor position within the indexed
 	// text.
 	pos combinedPos
@@ -59,6 +64,7 @@ func (g *glyphIndex) reset() {
 	g.currentLineMin = 0
 	g.currentLineMax = 0
 	g.currentLineGlyphs = 0
+	g.currentLineRunes = 0
 	g.pos = combinedPos{}
 	g.prog = 0
 	g.clusterAdvance = 0
@@ -220,6 +226,7 @@ func (g *glyphIndex) Glyph(gl text.Glyph) {
 			ascent:  g.positions[len(g.positions)-1].ascent,
 			descent: g.positions[len(g.positions)-1].descent,
 			glyphs:  g.currentLineGlyphs,
+			runes:   g.currentLineRunes,
 		})
 		g.pos.lineCol.line++
 		g.pos.lineCol.col = 0
@@ -227,7 +234,58 @@ func (g *glyphIndex) Glyph(gl text.Glyph) {
 		g.currentLineMin = math.MaxInt32
 		g.currentLineMax = 0
 		g.currentLineGlyphs = 0
+		g.currentLineRunes = g.pos.runes
+	}
+}
+
+// truncate drops the index past the given lengths, which must be at a
+// paragraph boundary, to prepare for appendIndex.
+func (g *glyphIndex) truncate(glyphs, positions, lines, runes int) {
+	g.glyphs = g.glyphs[:glyphs]
+	g.positions = g.positions[:positions]
+	g.lines = g.lines[:lines]
+	g.pos.runes = runes
+	g.pos.lineCol = screenPos{line: lines}
+	g.currentLineRunes = runes
+	g.truncated = false
+}
+
+// appendIndex appends the index of a paragraph, as if its glyphs were
+// moved down by y and indexed by g. The index must end at a paragraph
+// boundary.
+func (g *glyphIndex) appendIndex(p *glyphIndex, y int) {
+	runes, lines := g.pos.runes, g.pos.lineCol.line
+	n := len(g.glyphs)
+	g.glyphs = append(g.glyphs, p.glyphs...)
+	for i := range g.glyphs[n:] {
+		g.glyphs[n+i].Y += int32(y)
+	}
+	n = len(g.positions)
+	g.positions = append(g.positions, p.positions...)
+	for i := range g.positions[n:] {
+		pos := &g.positions[n+i]
+		pos.runes += runes
+		pos.lineCol.line += lines
+		pos.y += y
+	}
+	n = len(g.lines)
+	g.lines = append(g.lines, p.lines...)
+	for i := range g.lines[n:] {
+		g.lines[n+i].yOff += y
+		g.lines[n+i].runes += runes
 	}
+	g.currentLineMin = p.currentLineMin
+	g.currentLineMax = p.currentLineMax
+	g.currentLineGlyphs = p.currentLineGlyphs
+	g.currentLineRunes = p.currentLineRunes + runes
+	g.pos = p.pos
+	g.pos.runes += runes
+	g.pos.lineCol.line += lines
+	g.pos.y += y
+	g.prog = p.prog
+	g.clusterAdvance = p.clusterAdvance
+	g.truncated = g.truncated || p.truncated
+	g.midCluster = p.midCluster
 }
 
 func (g *glyphIndex) closestToRune(runeIdx int) (combinedPos, int) {
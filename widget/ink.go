@@ -0,0 +1,189 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package widget
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/Seikaijyu/gio/f32"
+	"github.com/Seikaijyu/gio/io/pointer"
+	"github.com/Seikaijyu/gio/op"
+	"github.com/Seikaijyu/gio/op/clip"
+	"github.com/Seikaijyu/gio/op/paint"
+)
+
+// Ink builds a smoothed stroke of variable width from the samples of a
+// pointer, such as for drawing apps. Feed it the events of a drag,
+// including their coalesced History, and Paint it every frame.
+//
+// The stroke is built incrementally: its completed parts are cached as
+// clip paths, and only its end is rebuilt each frame.
+type Ink struct {
+	// Width is the width of the stroke at full pressure, in pixels.
+	Width float32
+	// Thinning is how much the stroke thins with less pressure, in the
+	// range [0, 1]. At 0 the width is constant, and at 1 it is
+	// proportional to the pressure. Samples without pressure, such as
+	// of mice, count as full pressure.
+	Thinning float32
+	// Smoothing is how much the stroke lags behind the samples to
+	// smooth out jitter, in the range [0, 1). Zero disables smoothing.
+	Smoothing float32
+
+	points []inkPoint
+	// raw is the last sample, which End adds unsmoothed.
+	raw inkPoint
+	// cache holds the paths of chunks.
+	cache op.Ops
+	// chunks are the cached paths of the completed parts of the
+	// stroke, and baked is the number of points they cover. The last
+	// point of a chunk starts the next.
+	chunks []clip.PathSpec
+	baked  int
+}
+
+type inkPoint struct {
+	pos f32.Point
+	// r is half the width of the stroke at pos.
+	r float32
+}
+
+const (
+	// inkChunk is the number of segments in a cached chunk.
+	inkChunk = 32
+	// inkMinDistance is the distance, in pixels, a sample must move
+	// away from the previous point to add a point.
+	inkMinDistance = 0.5
+)
+
+// Add a sample to the stroke.
+func (k *Ink) Add(s pointer.Sample) {
+	pressure := s.Pressure
+	if pressure <= 0 {
+		pressure = 1
+	}
+	raw := inkPoint{
+		pos: s.Position,
+		r:   k.Width * (1 - k.Thinning*(1-pressure)) / 2,
+	}
+	k.raw = raw
+	if len(k.points) == 0 {
+		k.points = append(k.points, raw)
+		return
+	}
+	prev := k.points[len(k.points)-1]
+	f := 1 - k.Smoothing
+	p := inkPoint{
+		pos: prev.pos.Add(raw.pos.Sub(prev.pos).Mul(f)),
+		r:   prev.r + (raw.r-prev.r)*f,
+	}
+	if d := p.pos.Sub(prev.pos); d.X*d.X+d.Y*d.Y < inkMinDistance*inkMinDistance {
+		return
+	}
+	k.points = append(k.points, p)
+}
+
+// AddEvent adds the samples of a pointer event to the stroke, the
+// samples of its History followed by its own.
+func (k *Ink) AddEvent(e pointer.Event) {
+	for _, s := range e.History {
+		k.Add(s)
+	}
+	k.Add(pointer.Sample{
+		Time:     e.Time,
+		Position: e.Position,
+		Pressure: e.Pressure,
+		TiltX:    e.TiltX,
+		TiltY:    e.TiltY,
+	})
+}
+
+// End the stroke, catching up with the last sample the smoothing lags
+// behind.
+func (k *Ink) End() {
+	if len(k.points) == 0 {
+		return
+	}
+	if last := k.points[len(k.points)-1]; last != k.raw {
+		k.points = append(k.points, k.raw)
+	}
+}
+
+// Reset the stroke to empty.
+func (k *Ink) Reset() {
+	k.points = k.points[:0]
+	k.chunks = k.chunks[:0]
+	k.baked = 0
+	k.cache.Reset()
+}
+
+// Empty reports whether the stroke has no samples.
+func (k *Ink) Empty() bool {
+	return len(k.points) == 0
+}
+
+// Paint the stroke with color c. Translucent strokes are painted in a
+// layer, so their overlaps don't show.
+func (k *Ink) Paint(ops *op.Ops, c color.NRGBA) {
+	if len(k.points) == 0 {
+		return
+	}
+	for len(k.points)-1-k.baked >= inkChunk {
+		pts := k.points[k.baked : k.baked+inkChunk+1]
+		k.chunks = append(k.chunks, inkPath(&k.cache, pts, k.baked == 0))
+		k.baked += inkChunk
+	}
+	if c.A < 0xff {
+		defer paint.PushOpacity(ops, float32(c.A)/0xff).Pop()
+		c.A = 0xff
+	}
+	for _, spec := range k.chunks {
+		paint.FillShape(ops, c, clip.Outline{Path: spec}.Op())
+	}
+	if tail := k.points[k.baked:]; len(tail) > 1 || k.baked == 0 {
+		paint.FillShape(ops, c, clip.Outline{Path: inkPath(ops, tail, k.baked == 0)}.Op())
+	}
+}
+
+// inkPath returns the outline of the stroke through pts, with a round
+// cap at the first point if first is set. The outline is the union of
+// the segments and the circles at their ends, all wound the same way
+// for the non-zero winding rule.
+func inkPath(ops *op.Ops, pts []inkPoint, first bool) clip.PathSpec {
+	var p clip.Path
+	p.Begin(ops)
+	if first {
+		inkCircle(&p, pts[0])
+	}
+	for i := 1; i < len(pts); i++ {
+		a, b := pts[i-1], pts[i]
+		d := b.pos.Sub(a.pos)
+		l := float32(math.Hypot(float64(d.X), float64(d.Y)))
+		if l > 0 {
+			n := f32.Pt(-d.Y/l, d.X/l)
+			p.MoveTo(a.pos.Add(n.Mul(a.r)))
+			p.LineTo(b.pos.Add(n.Mul(b.r)))
+			p.LineTo(b.pos.Sub(n.Mul(b.r)))
+			p.LineTo(a.pos.Sub(n.Mul(a.r)))
+			p.Close()
+		}
+		inkCircle(&p, b)
+	}
+	return p.End()
+}
+
+// inkCircle adds a circle around pt to p, approximated by a polygon.
+func inkCircle(p *clip.Path, pt inkPoint) {
+	n := int(pt.r) + 8
+	if n > 32 {
+		n = 32
+	}
+	p.MoveTo(pt.pos.Add(f32.Pt(pt.r, 0)))
+	for i := 1; i < n; i++ {
+		// Clockwise, like the segments.
+		a := -2 * math.Pi * float64(i) / float64(n)
+		p.LineTo(pt.pos.Add(f32.Pt(float32(math.Cos(a)), float32(math.Sin(a))).Mul(pt.r)))
+	}
+	p.Close()
+}
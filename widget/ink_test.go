@@ -0,0 +1,55 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package widget
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/Seikaijyu/gio/f32"
+	"github.com/Seikaijyu/gio/io/pointer"
+	"github.com/Seikaijyu/gio/op"
+)
+
+func TestInk(t *testing.T) {
+	k := Ink{Width: 10, Thinning: 1, Smoothing: 0.5}
+	k.AddEvent(pointer.Event{
+		Position: f32.Pt(100, 0),
+		Pressure: 0.5,
+		History: []pointer.Sample{
+			{Position: f32.Pt(0, 0), Pressure: 0.5},
+			// Too close to the previous sample.
+			{Position: f32.Pt(0.1, 0), Pressure: 0.5},
+		},
+	})
+	if n := len(k.points); n != 2 {
+		t.Fatalf("got %d points, want 2", n)
+	}
+	if p := k.points[1]; p.pos != f32.Pt(50, 0) || p.r != 2.5 {
+		t.Errorf("got smoothed point %+v, want (50,0) with radius 2.5", p)
+	}
+	k.End()
+	if p := k.points[len(k.points)-1]; p.pos != f32.Pt(100, 0) {
+		t.Errorf("got last point %v after End, want (100,0)", p.pos)
+	}
+
+	k.Reset()
+	k.Smoothing = 0
+	for i := 0; i < 2*inkChunk+5; i++ {
+		k.Add(pointer.Sample{Position: f32.Pt(float32(i), 0)})
+	}
+	ops := new(op.Ops)
+	k.Paint(ops, color.NRGBA{A: 0x80})
+	if n := len(k.chunks); n != 2 {
+		t.Errorf("got %d cached chunks, want 2", n)
+	}
+	// Painting again reuses the chunks.
+	ops.Reset()
+	k.Paint(ops, color.NRGBA{A: 0xff})
+	if n := len(k.chunks); n != 2 {
+		t.Errorf("got %d cached chunks after repaint, want 2", n)
+	}
+	if p := k.points[0]; p.r != 5 {
+		t.Errorf("got radius %v without pressure, want 5", p.r)
+	}
+}
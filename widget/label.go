@@ -4,6 +4,7 @@ package widget
 
 import (
 	"image"
+	"math"
 
 	"github.com/Seikaijyu/gio/f32"
 	"github.com/Seikaijyu/gio/font"
@@ -36,6 +37,12 @@ type Label struct {
 	// LineHeightScale applies a scaling factor to the LineHeight. If zero, a
 	// sensible default will be used.
 	LineHeightScale float32
+	// Vertical lays out the text in columns from top to bottom, running
+	// from right to left, as in traditional CJK typography. CJK glyphs
+	// stay upright while other glyphs are rotated clockwise. The
+	// constraints limit the height of the columns, and Alignment aligns
+	// the text vertically.
+	Vertical bool
 }
 
 // Layout the label with the given shaper, font, size, text, and material.
@@ -53,6 +60,9 @@ type TextInfo struct {
 
 // Layout the label with the given shaper, font, size, text, and material, returning metadata about the shaped text.
 func (l Label) LayoutDetailed(gtx layout.Context, lt *text.Shaper, font font.Font, size unit.Sp, txt string, textMaterial op.CallOp) (layout.Dimensions, TextInfo) {
+	if l.Vertical {
+		gtx.Constraints = verticalConstraints(gtx.Constraints)
+	}
 	cs := gtx.Constraints
 	textSize := fixed.I(gtx.Sp(size))
 	lineHeight := fixed.I(gtx.Sp(l.LineHeight))
@@ -68,6 +78,7 @@ func (l Label) LayoutDetailed(gtx layout.Context, lt *text.Shaper, font font.Fon
 		Locale:          gtx.Locale,
 		LineHeight:      lineHeight,
 		LineHeightScale: l.LineHeightScale,
+		Vertical:        l.Vertical,
 	}, txt)
 	m := op.Record(gtx.Ops)
 	viewport := image.Rectangle{Max: cs.Max}
@@ -86,13 +97,17 @@ func (l Label) LayoutDetailed(gtx layout.Context, lt *text.Shaper, font font.Fon
 		}
 	}
 	call := m.Stop()
+	dims := layout.Dimensions{Size: it.bounds.Size()}
+	dims.Size = cs.Constrain(dims.Size)
+	dims.Baseline = dims.Size.Y - it.baseline
+	if l.Vertical {
+		defer verticalTransform(dims.Size.Y).Push(gtx.Ops).Pop()
+		dims = layout.Dimensions{Size: image.Pt(dims.Size.Y, dims.Size.X)}
+	}
 	viewport.Min = viewport.Min.Add(it.padding.Min)
 	viewport.Max = viewport.Max.Add(it.padding.Max)
 	clipStack := clip.Rect(viewport).Push(gtx.Ops)
 	call.Add(gtx.Ops)
-	dims := layout.Dimensions{Size: it.bounds.Size()}
-	dims.Size = cs.Constrain(dims.Size)
-	dims.Baseline = dims.Size.Y - it.baseline
 	clipStack.Pop()
 	return dims, TextInfo{Truncated: it.truncated}
 }
@@ -130,6 +145,9 @@ type textIterator struct {
 	first bool
 	// baseline tracks the location of the first line of text's baseline.
 	baseline int
+	// embolden is the width, in pixels, of the stroke that emboldens the
+	// glyphs. Zero disables emboldening.
+	embolden float32
 }
 
 // processGlyph checks whether the glyph is visible within the iterator's configured
@@ -214,17 +232,59 @@ func (it *textIterator) paintGlyph(gtx layout.Context, shaper *text.Shaper, glyp
 		line = append(line, glyph)
 	}
 	if glyph.Flags&text.FlagLineBreak != 0 || cap(line)-len(line) == 0 || !visibleOrBefore {
-		t := op.Affine(f32.Affine2D{}.Offset(it.lineOff)).Push(gtx.Ops)
-		path := shaper.Shape(line)
-		outline := clip.Outline{Path: path}.Op().Push(gtx.Ops)
+		line = it.paintLine(gtx, shaper, line)
+	}
+	return line, visibleOrBefore
+}
+
+// paintLine paints the glyphs of line, which must share a line of text,
+// and returns line emptied.
+func (it *textIterator) paintLine(gtx layout.Context, shaper *text.Shaper, line []text.Glyph) []text.Glyph {
+	if len(line) == 0 {
+		return line
+	}
+	// Upright glyphs of vertical text are painted one at a time, rotated
+	// back around the center of their logical bounds.
+	start := 0
+	for i, g := range line {
+		if g.Flags&text.FlagUpright == 0 {
+			continue
+		}
+		if start < i {
+			it.paintGlyphs(gtx, shaper, f32.Affine2D{}.Offset(it.glyphOff(line, start)), line[start:i])
+		}
+		off := it.glyphOff(line, i)
+		center := f32.Pt(fixedToFloat(g.Advance)/2, fixedToFloat(g.Descent-g.Ascent)/2)
+		t := f32.Affine2D{}.Rotate(center, -math.Pi/2).Offset(off)
+		it.paintGlyphs(gtx, shaper, t, line[i:i+1])
+		start = i + 1
+	}
+	if start < len(line) {
+		it.paintGlyphs(gtx, shaper, f32.Affine2D{}.Offset(it.glyphOff(line, start)), line[start:])
+	}
+	return line[:0]
+}
+
+// glyphOff returns the offset of the dot of line[i].
+func (it *textIterator) glyphOff(line []text.Glyph, i int) f32.Point {
+	return it.lineOff.Add(f32.Pt(fixedToFloat(line[i].X-line[0].X), 0))
+}
+
+// paintGlyphs paints glyphs transformed by t.
+func (it *textIterator) paintGlyphs(gtx layout.Context, shaper *text.Shaper, t f32.Affine2D, glyphs []text.Glyph) {
+	defer op.Affine(t).Push(gtx.Ops).Pop()
+	path := shaper.Shape(glyphs)
+	outline := clip.Outline{Path: path}.Op().Push(gtx.Ops)
+	it.material.Add(gtx.Ops)
+	paint.PaintOp{}.Add(gtx.Ops)
+	outline.Pop()
+	if it.embolden > 0 {
+		stroke := clip.Stroke{Path: path, Width: it.embolden}.Op().Push(gtx.Ops)
 		it.material.Add(gtx.Ops)
 		paint.PaintOp{}.Add(gtx.Ops)
-		outline.Pop()
-		if call := shaper.Bitmaps(line); call != (op.CallOp{}) {
-			call.Add(gtx.Ops)
-		}
-		t.Pop()
-		line = line[:0]
+		stroke.Pop()
+	}
+	if call := shaper.Bitmaps(glyphs); call != (op.CallOp{}) {
+		call.Add(gtx.Ops)
 	}
-	return line, visibleOrBefore
 }
@@ -4,6 +4,7 @@ package widget
 
 import (
 	"image"
+	"time"
 
 	"github.com/Seikaijyu/gio/gesture"
 	"github.com/Seikaijyu/gio/io/key"
@@ -19,7 +20,8 @@ import (
 // or by clicks within the display area.
 //
 // Scrollbar additionally detects when a scroll indicator region is
-// hovered.
+// hovered, and records the time of the last activity for scrollbars
+// that hide while idle.
 type Scrollbar struct {
 	track, indicator gesture.Click
 	drag             gesture.Drag
@@ -27,6 +29,11 @@ type Scrollbar struct {
 
 	dragging   bool
 	oldDragPos float32
+
+	// start is the viewport start of the previous Update, and
+	// activity the time of the last activity.
+	start    float32
+	activity time.Time
 }
 
 // Update updates the internal state of the scrollbar based on events
@@ -61,6 +68,7 @@ func (s *Scrollbar) Update(gtx layout.Context, axis layout.Axis, viewportStart,
 	}
 
 	// Jump to a click in the track.
+	s.track.DoubleClickDuration = gtx.Gestures.DoubleClick
 	for _, event := range s.track.Update(gtx) {
 		if event.Kind != gesture.KindClick ||
 			event.Modifiers != key.Modifiers(0) ||
@@ -80,6 +88,7 @@ func (s *Scrollbar) Update(gtx layout.Context, axis layout.Axis, viewportStart,
 	}
 
 	// Offset to account for any drags.
+	s.drag.MouseSlop = gtx.Gestures.DragThreshold
 	for _, event := range s.drag.Update(gtx.Metric, gtx, gesture.Axis(axis)) {
 		switch event.Kind {
 		case pointer.Drag:
@@ -137,6 +146,13 @@ func (s *Scrollbar) Update(gtx layout.Context, axis layout.Axis, viewportStart,
 	// Process events from the indicator so that hover is
 	// detected properly.
 	_ = s.indicator.Update(gtx)
+
+	// Movement of the viewport, such as by a mouse wheel, counts as
+	// activity as much as interacting with the scrollbar.
+	if s.Active() || s.delta != 0 || viewportStart != s.start {
+		s.activity = gtx.Now
+	}
+	s.start = viewportStart
 }
 
 // AddTrack configures the track click listener for the scrollbar to use
@@ -169,6 +185,24 @@ func (s *Scrollbar) TrackHovered() bool {
 	return s.track.Hovered()
 }
 
+// Hovered reports whether the track or the indicator of the scrollbar
+// is hovered by the pointer.
+func (s *Scrollbar) Hovered() bool {
+	return s.track.Hovered() || s.indicator.Hovered()
+}
+
+// Active reports whether the scrollbar is hovered or dragged.
+func (s *Scrollbar) Active() bool {
+	return s.dragging || s.Hovered()
+}
+
+// LastActivity returns the time, as of the last call to Update, the
+// scrollbar was last active or its viewport last moved. Overlay
+// scrollbars use it to hide while idle.
+func (s *Scrollbar) LastActivity() time.Time {
+	return s.activity
+}
+
 // ScrollDistance returns the normalized distance that the scrollbar
 // moved during the last call to Layout as a value in the range [-1,1].
 func (s *Scrollbar) ScrollDistance() float32 {
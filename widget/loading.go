@@ -0,0 +1,65 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package widget
+
+import (
+	"time"
+
+	"github.com/Seikaijyu/gio/layout"
+	"github.com/Seikaijyu/gio/op"
+)
+
+// Loading tracks an operation in progress, such as a network request,
+// and whether it takes longer than a timeout.
+type Loading struct {
+	// Timeout is how long the operation may take before Update reports
+	// it timed out. The zero value disables the timeout.
+	Timeout time.Duration
+
+	active   bool
+	timedOut bool
+	// start is the frame time the operation started, or zero if not
+	// known yet.
+	start time.Time
+}
+
+// Start the operation. The timeout counts from the next Update.
+func (l *Loading) Start() {
+	l.active, l.timedOut = true, false
+	l.start = time.Time{}
+}
+
+// Stop the operation.
+func (l *Loading) Stop() {
+	l.active, l.timedOut = false, false
+}
+
+// Active reports whether the operation is in progress.
+func (l *Loading) Active() bool {
+	return l.active
+}
+
+// TimedOut reports whether the operation in progress took longer than
+// the timeout.
+func (l *Loading) TimedOut() bool {
+	return l.timedOut
+}
+
+// Update the state and report whether the operation timed out since
+// the last call. Update schedules a frame for the end of the timeout,
+// so it must be called every frame while the operation is in progress.
+func (l *Loading) Update(gtx layout.Context) bool {
+	if !l.active || l.timedOut || l.Timeout <= 0 {
+		return false
+	}
+	if l.start.IsZero() {
+		l.start = gtx.Now
+	}
+	deadline := l.start.Add(l.Timeout)
+	if gtx.Now.Before(deadline) {
+		op.InvalidateOp{At: deadline}.Add(gtx.Ops)
+		return false
+	}
+	l.timedOut = true
+	return true
+}
@@ -0,0 +1,39 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package widget
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Seikaijyu/gio/layout"
+	"github.com/Seikaijyu/gio/op"
+)
+
+func TestLoadingTimeout(t *testing.T) {
+	l := Loading{Timeout: time.Second}
+	start := time.Now()
+	gtx := layout.Context{Ops: new(op.Ops), Now: start}
+	if l.Update(gtx) {
+		t.Fatal("inactive loading timed out")
+	}
+	l.Start()
+	if l.Update(gtx) || !l.Active() {
+		t.Fatal("loading timed out at start")
+	}
+	gtx.Now = start.Add(999 * time.Millisecond)
+	if l.Update(gtx) {
+		t.Fatal("loading timed out early")
+	}
+	gtx.Now = start.Add(time.Second)
+	if !l.Update(gtx) || !l.TimedOut() {
+		t.Fatal("loading didn't time out")
+	}
+	if l.Update(gtx) {
+		t.Error("timeout reported twice")
+	}
+	l.Stop()
+	if l.Active() || l.TimedOut() {
+		t.Error("stopped loading is active")
+	}
+}
@@ -57,10 +57,10 @@ func Button(th *Theme, button *widget.Clickable, txt string) ButtonStyle {
 		CornerRadius: 4,
 		Background:   th.Palette.ContrastBg,
 		TextSize:     th.TextSize * 14.0 / 16.0,
-		Inset: layout.Inset{
+		Inset: th.Density.Inset(layout.Inset{
 			Top: 10, Bottom: 10,
 			Left: 12, Right: 12,
-		},
+		}),
 		Button: button,
 		shaper: th.Shaper,
 	}
@@ -82,7 +82,7 @@ func IconButton(th *Theme, button *widget.Clickable, icon *widget.Icon, descript
 		Color:       th.Palette.ContrastFg,
 		Icon:        icon,
 		Size:        24,
-		Inset:       layout.UniformInset(12),
+		Inset:       layout.UniformInset(th.Density.Scale(12)),
 		Button:      button,
 		Description: description,
 	}
@@ -96,9 +96,7 @@ func Clickable(gtx layout.Context, button *widget.Clickable, w layout.Widget) la
 		return layout.Background{}.Layout(gtx,
 			func(gtx layout.Context) layout.Dimensions {
 				defer clip.Rect{Max: gtx.Constraints.Min}.Push(gtx.Ops).Pop()
-				if button.Hovered() || button.Focused() {
-					paint.Fill(gtx.Ops, f32color.Hovered(color.NRGBA{}))
-				}
+				stateLayer(button, stateColor(color.NRGBA{})).Layout(gtx)
 				for _, c := range button.History() {
 					drawInk(gtx, c)
 				}
@@ -132,13 +130,11 @@ func (b ButtonLayoutStyle) Layout(gtx layout.Context, w layout.Widget) layout.Di
 				rr := gtx.Dp(b.CornerRadius)
 				defer clip.UniformRRect(image.Rectangle{Max: gtx.Constraints.Min}, rr).Push(gtx.Ops).Pop()
 				background := b.Background
-				switch {
-				case gtx.Queue == nil:
+				if gtx.Queue == nil {
 					background = f32color.Disabled(b.Background)
-				case b.Button.Hovered() || b.Button.Focused():
-					background = f32color.Hovered(b.Background)
 				}
 				paint.Fill(gtx.Ops, background)
+				stateLayer(b.Button, stateColor(b.Background)).Layout(gtx)
 				for _, c := range b.Button.History() {
 					drawInk(gtx, c)
 				}
@@ -164,13 +160,11 @@ func (b IconButtonStyle) Layout(gtx layout.Context) layout.Dimensions {
 				rr := (gtx.Constraints.Min.X + gtx.Constraints.Min.Y) / 4
 				defer clip.UniformRRect(image.Rectangle{Max: gtx.Constraints.Min}, rr).Push(gtx.Ops).Pop()
 				background := b.Background
-				switch {
-				case gtx.Queue == nil:
+				if gtx.Queue == nil {
 					background = f32color.Disabled(b.Background)
-				case b.Button.Hovered() || b.Button.Focused():
-					background = f32color.Hovered(b.Background)
 				}
 				paint.Fill(gtx.Ops, background)
+				stateLayer(b.Button, b.Color).Layout(gtx)
 				for _, c := range b.Button.History() {
 					drawInk(gtx, c)
 				}
@@ -10,6 +10,7 @@ import (
 	"github.com/Seikaijyu/gio/internal/f32color"
 	"github.com/Seikaijyu/gio/layout"
 	"github.com/Seikaijyu/gio/op"
+	"github.com/Seikaijyu/gio/op/clip"
 	"github.com/Seikaijyu/gio/op/paint"
 	"github.com/Seikaijyu/gio/text"
 	"github.com/Seikaijyu/gio/unit"
@@ -28,7 +29,7 @@ type checkable struct {
 	uncheckedStateIcon *widget.Icon
 }
 
-func (c *checkable) layout(gtx layout.Context, checked, hovered bool) layout.Dimensions {
+func (c *checkable) layout(gtx layout.Context, checked bool, state StateLayer) layout.Dimensions {
 	var icon *widget.Icon
 	if checked {
 		icon = c.checkedStateIcon
@@ -44,6 +45,11 @@ func (c *checkable) layout(gtx layout.Context, checked, hovered bool) layout.Dim
 				layout.Stacked(func(gtx layout.Context) layout.Dimensions {
 					defer op.Offset(image.Pt(0, 2)).Push(gtx.Ops).Pop()
 					size := gtx.Dp(c.Size)
+					// Draw the state layer in a circle around the icon.
+					pad := size / 4
+					layer := clip.Ellipse(image.Rect(-pad, -pad, size+pad, size+pad)).Push(gtx.Ops)
+					state.Layout(gtx)
+					layer.Pop()
 					col := c.IconColor
 					if gtx.Queue == nil {
 						col = f32color.Disabled(col)
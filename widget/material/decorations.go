@@ -71,23 +71,25 @@ func (d DecorationsStyle) layoutDecorations(gtx layout.Context) layout.Dimension
 				}
 				actions &^= a
 				var w layout.Widget
+				var label string
 				switch a {
 				case system.ActionMinimize:
-					w = minimizeWindow
+					w, label = minimizeWindow, "gio.decorations.minimize"
 				case system.ActionMaximize:
 					if d.Decorations.Maximized() {
-						w = maximizedWindow
+						w, label = maximizedWindow, "gio.decorations.restore"
 					} else {
-						w = maximizeWindow
+						w, label = maximizeWindow, "gio.decorations.maximize"
 					}
 				case system.ActionClose:
-					w = closeWindow
+					w, label = closeWindow, "gio.decorations.close"
 				default:
 					continue
 				}
 				cl := d.Decorations.Clickable(a)
 				dims := cl.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
 					semantic.Button.Add(gtx.Ops)
+					semantic.DescriptionOp(gtx.Message(label)).Add(gtx.Ops)
 					return layout.Background{}.Layout(gtx,
 						func(gtx layout.Context) layout.Dimensions {
 							defer clip.Rect{Max: gtx.Constraints.Min}.Push(gtx.Ops).Pop()
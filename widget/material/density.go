@@ -0,0 +1,59 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package material
+
+import (
+	"github.com/Seikaijyu/gio/layout"
+	"github.com/Seikaijyu/gio/unit"
+)
+
+// Density is the spacing of the components of a Theme. Denser themes
+// have smaller paddings, touch targets and component heights, while the
+// sizes of text and icons stay the same.
+type Density uint8
+
+const (
+	// Comfortable is the default density, for pointer and touch input
+	// alike.
+	Comfortable Density = iota
+	// Compact is for data-dense applications operated by mouse.
+	Compact
+	// Touch is for applications operated by touch, with larger touch
+	// targets.
+	Touch
+)
+
+// Scale converts a padding or size of the Comfortable density to d.
+// Styles of other packages can use it to follow the density of a theme.
+func (d Density) Scale(v unit.Dp) unit.Dp {
+	switch d {
+	case Compact:
+		return v * 2 / 3
+	case Touch:
+		return v * 4 / 3
+	}
+	return v
+}
+
+// Inset scales every side of in like Scale.
+func (d Density) Inset(in layout.Inset) layout.Inset {
+	return layout.Inset{
+		Top:    d.Scale(in.Top),
+		Bottom: d.Scale(in.Bottom),
+		Left:   d.Scale(in.Left),
+		Right:  d.Scale(in.Right),
+	}
+}
+
+func (d Density) String() string {
+	switch d {
+	case Comfortable:
+		return "Comfortable"
+	case Compact:
+		return "Compact"
+	case Touch:
+		return "Touch"
+	default:
+		panic("invalid Density")
+	}
+}
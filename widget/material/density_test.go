@@ -0,0 +1,44 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package material_test
+
+import (
+	"image"
+	"testing"
+
+	"github.com/Seikaijyu/gio/font/gofont"
+	"github.com/Seikaijyu/gio/io/system"
+	"github.com/Seikaijyu/gio/layout"
+	"github.com/Seikaijyu/gio/op"
+	"github.com/Seikaijyu/gio/text"
+	"github.com/Seikaijyu/gio/unit"
+	"github.com/Seikaijyu/gio/widget"
+	"github.com/Seikaijyu/gio/widget/material"
+)
+
+func TestDensity(t *testing.T) {
+	th := material.NewTheme()
+	th.Shaper = text.NewShaper(text.NoSystemFonts(), text.WithCollection(gofont.Collection()))
+	var (
+		ops op.Ops
+		btn widget.Clickable
+	)
+	height := func(d material.Density) int {
+		ops.Reset()
+		gtx := layout.NewContext(&ops, system.FrameEvent{
+			Metric: unit.Metric{PxPerDp: 1, PxPerSp: 1},
+			Size:   image.Pt(500, 500),
+		})
+		gtx.Constraints.Min = image.Point{}
+		th.Density = d
+		return material.Button(th, &btn, "Button").Layout(gtx).Size.Y
+	}
+	compact, comfortable, touch := height(material.Compact), height(material.Comfortable), height(material.Touch)
+	if !(compact < comfortable && comfortable < touch) {
+		t.Errorf("button heights %d, %d and %d for the compact, comfortable and touch densities, want increasing", compact, comfortable, touch)
+	}
+	th.Density = material.Touch
+	if got, want := material.Slider(th, new(widget.Float)).FingerSize, th.FingerSize*4/3; got != want {
+		t.Errorf("touch slider finger size %v, want %v", got, want)
+	}
+}
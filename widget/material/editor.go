@@ -3,7 +3,9 @@
 package material
 
 import (
+	"image"
 	"image/color"
+	"strconv"
 
 	"github.com/Seikaijyu/gio/font"
 	"github.com/Seikaijyu/gio/internal/f32color"
@@ -32,6 +34,9 @@ type EditorStyle struct {
 	HintColor color.NRGBA
 	// SelectionColor is the color of the background for selected text.
 	SelectionColor color.NRGBA
+	// TouchSelection configures the handles and the loupe for adjusting
+	// selections by touch.
+	TouchSelection widget.TouchSelection
 	Editor         *widget.Editor
 
 	shaper *text.Shaper
@@ -49,6 +54,16 @@ func Editor(th *Theme, editor *widget.Editor, hint string) EditorStyle {
 		Hint:           hint,
 		HintColor:      f32color.MulAlpha(th.Palette.Fg, 0xbb),
 		SelectionColor: f32color.MulAlpha(th.Palette.ContrastBg, 0x60),
+		TouchSelection: touchSelection(th),
+	}
+}
+
+// touchSelection returns the selection handles of the theme, colored
+// like its selections.
+func touchSelection(th *Theme) widget.TouchSelection {
+	return widget.TouchSelection{
+		Color:           th.Palette.ContrastBg,
+		LoupeBackground: th.Palette.Bg,
 	}
 }
 
@@ -87,6 +102,7 @@ func (e EditorStyle) Layout(gtx layout.Context) layout.Dimensions {
 	}
 	e.Editor.LineHeight = e.LineHeight
 	e.Editor.LineHeightScale = e.LineHeightScale
+	e.Editor.TouchSelection = e.TouchSelection
 	dims = e.Editor.Layout(gtx, e.shaper, e.Font, e.TextSize, textColor, selectionColor)
 	if e.Editor.Len() == 0 {
 		call.Add(gtx.Ops)
@@ -100,3 +116,31 @@ func blendDisabledColor(disabled bool, c color.NRGBA) color.NRGBA {
 	}
 	return c
 }
+
+// LineNumbers returns a widget.Editor Gutter that lays out the numbers of
+// lines in text of size, aligned to the end of the gutter. The number of
+// the line of the caret is emphasized. Set the GutterWidth of the editor
+// to fit the numbers.
+func LineNumbers(th *Theme, size unit.Sp) func(gtx layout.Context, line widget.EditorLine) layout.Dimensions {
+	return func(gtx layout.Context, line widget.EditorLine) layout.Dimensions {
+		box := gtx.Constraints.Min
+		if line.Continued {
+			return layout.Dimensions{Size: box}
+		}
+		l := Label(th, size, strconv.Itoa(line.Number))
+		l.Alignment = text.End
+		l.MaxLines = 1
+		if !line.Caret {
+			l.Color = f32color.MulAlpha(l.Color, 0x80)
+		}
+		// Align the baselines of the number and of the line.
+		m := op.Record(gtx.Ops)
+		gtx.Constraints.Min.Y = 0
+		dims := l.Layout(gtx)
+		call := m.Stop()
+		top := line.Baseline - (dims.Size.Y - dims.Baseline)
+		defer op.Offset(image.Pt(0, top)).Push(gtx.Ops).Pop()
+		call.Add(gtx.Ops)
+		return layout.Dimensions{Size: box, Baseline: box.Y - line.Baseline}
+	}
+}
@@ -0,0 +1,54 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package material
+
+import (
+	"github.com/Seikaijyu/gio/layout"
+	"github.com/Seikaijyu/gio/widget"
+)
+
+// GridStyle configures the presentation of a widget.Grid with a
+// horizontal and a vertical scrollbar.
+type GridStyle struct {
+	state *widget.Grid
+	ScrollbarsStyle
+}
+
+// Grid constructs a GridStyle using the provided theme and state.
+func Grid(th *Theme, state *widget.Grid) GridStyle {
+	return GridStyle{
+		state:           state,
+		ScrollbarsStyle: Scrollbars(th, &state.Horizontal, &state.Vertical),
+	}
+}
+
+// Layout the grid and its scrollbars. The scrollbars cover the rows and
+// columns that scroll, and are shown only if there are more of them than
+// fit.
+func (g GridStyle) Layout(gtx layout.Context, rows, cols int, size widget.GridSize, cell widget.GridCell) layout.Dimensions {
+	st := g.state
+	// The viewport ranges are those of the previous layout.
+	view := st.Viewport()
+	hStart, hEnd := gridRange(st.Columns, cols-st.FrozenColumns, st.FrozenColumns, view.X)
+	vStart, vEnd := gridRange(st.Rows, rows-st.FrozenRows, st.FrozenRows, view.Y)
+	dims := g.ScrollbarsStyle.Layout(gtx, hStart, hEnd, vStart, vEnd, func(gtx layout.Context) layout.Dimensions {
+		return st.Layout(gtx, rows, cols, size, cell)
+	})
+	if delta := st.Horizontal.ScrollDistance(); delta != 0 {
+		st.ScrollBy(layout.Horizontal, delta*float32(cols-st.FrozenColumns))
+	}
+	if delta := st.Vertical.ScrollDistance(); delta != 0 {
+		st.ScrollBy(layout.Vertical, delta*float32(rows-st.FrozenRows))
+	}
+	return dims
+}
+
+// gridRange returns the viewport range of the scroll position of n rows
+// or columns after frozen ones, in a viewport of size pixels.
+func gridRange(pos layout.Position, n, frozen, size int) (start, end float32) {
+	if n <= 0 || pos.Length <= 0 {
+		return 0, 1
+	}
+	pos.First -= frozen
+	return fromListPosition(pos, n, size)
+}
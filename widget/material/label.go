@@ -25,6 +25,9 @@ type LabelStyle struct {
 	Color color.NRGBA
 	// SelectionColor is the color of the background for selected text.
 	SelectionColor color.NRGBA
+	// TouchSelection configures the handles and the loupe for adjusting
+	// selections by touch, if State is set.
+	TouchSelection widget.TouchSelection
 	// Alignment specify the text alignment.
 	Alignment text.Alignment
 	// MaxLines limits the number of lines. Zero means no limit.
@@ -115,6 +118,7 @@ func Label(th *Theme, size unit.Sp, txt string) LabelStyle {
 		Text:           txt,
 		Color:          th.Palette.Fg,
 		SelectionColor: f32color.MulAlpha(th.Palette.ContrastBg, 0x60),
+		TouchSelection: touchSelection(th),
 		TextSize:       size,
 		Shaper:         th.Shaper,
 	}
@@ -140,6 +144,7 @@ func (l LabelStyle) Layout(gtx layout.Context) layout.Dimensions {
 		l.State.WrapPolicy = l.WrapPolicy
 		l.State.LineHeight = l.LineHeight
 		l.State.LineHeightScale = l.LineHeightScale
+		l.State.TouchSelection = l.TouchSelection
 		return l.State.Layout(gtx, l.Shaper, l.Font, l.TextSize, textColor, selectColor)
 	}
 	tl := widget.Label{
@@ -6,6 +6,7 @@ import (
 	"image"
 	"image/color"
 	"math"
+	"time"
 
 	"github.com/Seikaijyu/gio/io/pointer"
 	"github.com/Seikaijyu/gio/layout"
@@ -72,6 +73,9 @@ type ScrollTrackStyle struct {
 	MajorPadding, MinorPadding unit.Dp
 	// Color of the track background.
 	Color color.NRGBA
+	// HoverColor is the color of the track background while the
+	// scrollbar is hovered. The zero value keeps Color.
+	HoverColor color.NRGBA
 }
 
 // ScrollIndicatorStyle configures the presentation of a scroll indicator.
@@ -84,6 +88,9 @@ type ScrollIndicatorStyle struct {
 	// Color and HoverColor are the normal and hovered colors of the scroll
 	// indicator.
 	Color, HoverColor color.NRGBA
+	// DragColor is the color of the indicator while dragged. The zero
+	// value keeps HoverColor.
+	DragColor color.NRGBA
 	// CornerRadius is the corner radius of the rectangular indicator. 0
 	// will produce square corners. 0.5*MinorWidth will produce perfectly
 	// round corners.
@@ -95,8 +102,16 @@ type ScrollbarStyle struct {
 	Scrollbar *widget.Scrollbar
 	Track     ScrollTrackStyle
 	Indicator ScrollIndicatorStyle
+	// AutoHide, if positive, fades out the scrollbar when it has been
+	// idle for AutoHide, such as for scrollbars floating over content.
+	// The scrollbar shows again when its viewport moves or the pointer
+	// hovers it.
+	AutoHide time.Duration
 }
 
+// scrollbarFade is the duration of the fade out of an idle scrollbar.
+const scrollbarFade = 250 * time.Millisecond
+
 // Scrollbar configures the presentation of a scrollbar using the provided
 // theme and state.
 func Scrollbar(th *Theme, state *widget.Scrollbar) ScrollbarStyle {
@@ -112,11 +127,12 @@ func Scrollbar(th *Theme, state *widget.Scrollbar) ScrollbarStyle {
 			MinorPadding: 2,
 		},
 		Indicator: ScrollIndicatorStyle{
-			MajorMinLen:  th.FingerSize,
+			MajorMinLen:  th.Density.Scale(th.FingerSize),
 			MinorWidth:   6,
 			CornerRadius: 3,
 			Color:        lightFg,
 			HoverColor:   darkFg,
+			DragColor:    darkFg,
 		},
 	}
 }
@@ -146,10 +162,41 @@ func (s ScrollbarStyle) Layout(gtx layout.Context, axis layout.Axis, viewportSta
 	if s.Scrollbar.IndicatorHovered() {
 		s.Indicator.Color = s.Indicator.HoverColor
 	}
+	if s.Scrollbar.Dragging() && s.Indicator.DragColor != (color.NRGBA{}) {
+		s.Indicator.Color = s.Indicator.DragColor
+	}
+	if s.Scrollbar.Hovered() && s.Track.HoverColor != (color.NRGBA{}) {
+		s.Track.Color = s.Track.HoverColor
+	}
 
+	// Hidden scrollbars keep their pointer areas, so that hovering
+	// them shows them again.
+	if alpha := s.visibility(gtx); alpha < 1 {
+		defer paint.PushOpacity(gtx.Ops, alpha).Pop()
+	}
 	return s.layout(gtx, axis, viewportStart, viewportEnd)
 }
 
+// visibility returns the opacity of the scrollbar, and schedules the
+// frames of its fade out.
+func (s ScrollbarStyle) visibility(gtx layout.Context) float32 {
+	if s.AutoHide <= 0 || s.Scrollbar.Active() {
+		return 1
+	}
+	last := s.Scrollbar.LastActivity()
+	idle := gtx.Now.Sub(last)
+	switch {
+	case idle < s.AutoHide:
+		op.InvalidateOp{At: last.Add(s.AutoHide)}.Add(gtx.Ops)
+		return 1
+	case idle < s.AutoHide+scrollbarFade:
+		op.InvalidateOp{}.Add(gtx.Ops)
+		return 1 - float32(idle-s.AutoHide)/float32(scrollbarFade)
+	default:
+		return 0
+	}
+}
+
 // layout the scroll track and indicator.
 func (s ScrollbarStyle) layout(gtx layout.Context, axis layout.Axis, viewportStart, viewportEnd float32) layout.Dimensions {
 	inset := layout.Inset{
@@ -310,3 +357,83 @@ func (l ListStyle) Layout(gtx layout.Context, length int, w layout.ListElement)
 
 	return listDims
 }
+
+// ScrollbarsStyle configures the presentation of a horizontal and a
+// vertical scrollbar of content scrollable along both axes. The
+// scrollbars are anchored to the bottom and right edges of the content
+// and stop short of the corner they share, so that they never overlap.
+type ScrollbarsStyle struct {
+	Horizontal, Vertical ScrollbarStyle
+	AnchorStrategy
+	// CornerColor fills the corner between the scrollbars, if both are
+	// shown and occupy space.
+	CornerColor color.NRGBA
+}
+
+// Scrollbars constructs a ScrollbarsStyle using the provided theme and
+// the states of the horizontal and vertical scrollbars.
+func Scrollbars(th *Theme, horizontal, vertical *widget.Scrollbar) ScrollbarsStyle {
+	return ScrollbarsStyle{
+		Horizontal: Scrollbar(th, horizontal),
+		Vertical:   Scrollbar(th, vertical),
+	}
+}
+
+// Layout the content w and the scrollbars of its viewport. hStart and
+// hEnd are the viewport range of the content along the horizontal axis,
+// and vStart and vEnd along the vertical axis, as in
+// ScrollbarStyle.Layout. Only the scrollbars of scrollable ranges are
+// shown, and the Occupy strategy reserves space for those only. Apply
+// the ScrollDistance of the scrollbars to the content after Layout.
+func (s ScrollbarsStyle) Layout(gtx layout.Context, hStart, hEnd, vStart, vEnd float32, w layout.Widget) layout.Dimensions {
+	var bars image.Point
+	if rangeIsScrollable(hStart, hEnd) {
+		bars.Y = gtx.Dp(s.Horizontal.Width())
+	}
+	if rangeIsScrollable(vStart, vEnd) {
+		bars.X = gtx.Dp(s.Vertical.Width())
+	}
+
+	cgtx := gtx
+	if s.AnchorStrategy == Occupy {
+		cgtx.Constraints.Max = nonNegative(cgtx.Constraints.Max.Sub(bars))
+		cgtx.Constraints.Min = nonNegative(cgtx.Constraints.Min.Sub(bars))
+	}
+	dims := w(cgtx)
+
+	size := dims.Size
+	if s.AnchorStrategy == Occupy {
+		size = size.Add(bars)
+	}
+	// Both scrollbars end where the other begins.
+	if bars.X > 0 {
+		bgtx := gtx
+		bgtx.Constraints = layout.Exact(nonNegative(image.Pt(bars.X, size.Y-bars.Y)))
+		off := op.Offset(image.Pt(size.X-bars.X, 0)).Push(gtx.Ops)
+		s.Vertical.Layout(bgtx, layout.Vertical, vStart, vEnd)
+		off.Pop()
+	}
+	if bars.Y > 0 {
+		bgtx := gtx
+		bgtx.Constraints = layout.Exact(nonNegative(image.Pt(size.X-bars.X, bars.Y)))
+		off := op.Offset(image.Pt(0, size.Y-bars.Y)).Push(gtx.Ops)
+		s.Horizontal.Layout(bgtx, layout.Horizontal, hStart, hEnd)
+		off.Pop()
+	}
+	if bars.X > 0 && bars.Y > 0 && s.AnchorStrategy == Occupy {
+		corner := image.Rectangle{Min: size.Sub(bars), Max: size}
+		paint.FillShape(gtx.Ops, s.CornerColor, clip.Rect(corner).Op())
+	}
+	return layout.Dimensions{Size: size, Baseline: dims.Baseline}
+}
+
+// nonNegative clamps the coordinates of p to zero or more.
+func nonNegative(p image.Point) image.Point {
+	if p.X < 0 {
+		p.X = 0
+	}
+	if p.Y < 0 {
+		p.Y = 0
+	}
+	return p
+}
@@ -68,3 +68,74 @@ func TestListAnchorStrategies(t *testing.T) {
 			overlayConstraints.Max.X, occupyConstraints.Max.X, indicatorWidth)
 	}
 }
+
+func TestScrollbarsCorner(t *testing.T) {
+	var ops op.Ops
+	gtx := layout.NewContext(&ops, system.FrameEvent{
+		Metric: unit.Metric{PxPerDp: 1, PxPerSp: 1},
+		Size:   image.Pt(500, 400),
+	})
+	var h, v widget.Scrollbar
+	bars := material.Scrollbars(material.NewTheme(), &h, &v)
+	hw, vw := gtx.Dp(bars.Horizontal.Width()), gtx.Dp(bars.Vertical.Width())
+
+	var content layout.Constraints
+	w := func(gtx layout.Context) layout.Dimensions {
+		content = gtx.Constraints
+		return layout.Dimensions{Size: gtx.Constraints.Max}
+	}
+	dims := bars.Layout(gtx, 0, .5, .25, .75, w)
+	if want := image.Pt(500-vw, 400-hw); content.Max != want {
+		t.Errorf("content max constraints %v, want %v", content.Max, want)
+	}
+	if want := image.Pt(500, 400); dims.Size != want {
+		t.Errorf("dims %v, want %v", dims.Size, want)
+	}
+
+	// Only scrollable ranges reserve space.
+	bars.Layout(gtx, 0, 1, .25, .75, w)
+	if want := image.Pt(500-vw, 400); content.Max != want {
+		t.Errorf("content max constraints %v, want %v", content.Max, want)
+	}
+
+	bars.AnchorStrategy = material.Overlay
+	bars.Layout(gtx, 0, .5, .25, .75, w)
+	if want := image.Pt(500, 400); content.Max != want {
+		t.Errorf("overlay content max constraints %v, want %v", content.Max, want)
+	}
+}
+
+func TestScrollbarActivity(t *testing.T) {
+	var ops op.Ops
+	now := time.Now()
+	var state widget.Scrollbar
+	bar := material.Scrollbar(material.NewTheme(), &state)
+	bar.AutoHide = time.Second
+	frame := func(start float32) {
+		ops.Reset()
+		gtx := layout.NewContext(&ops, system.FrameEvent{
+			Metric: unit.Metric{PxPerDp: 1, PxPerSp: 1},
+			Now:    now,
+			Size:   image.Pt(10, 100),
+		})
+		bar.Layout(gtx, layout.Vertical, start, start+.5)
+	}
+	frame(0)
+	if !state.LastActivity().IsZero() {
+		t.Errorf("idle scrollbar has activity at %v", state.LastActivity())
+	}
+	now = now.Add(time.Second)
+	frame(.25)
+	if got := state.LastActivity(); !got.Equal(now) {
+		t.Errorf("moved scrollbar has activity at %v, want %v", got, now)
+	}
+	last := now
+	now = now.Add(time.Second)
+	frame(.25)
+	if got := state.LastActivity(); !got.Equal(last) {
+		t.Errorf("idle scrollbar has activity at %v, want %v", got, last)
+	}
+	if state.Active() {
+		t.Error("idle scrollbar is active")
+	}
+}
@@ -0,0 +1,82 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package material
+
+import (
+	"github.com/Seikaijyu/gio/internal/f32color"
+	"github.com/Seikaijyu/gio/io/semantic"
+	"github.com/Seikaijyu/gio/layout"
+	"github.com/Seikaijyu/gio/op/clip"
+	"github.com/Seikaijyu/gio/unit"
+	"github.com/Seikaijyu/gio/widget"
+)
+
+// MenuItemStyle lays out an item of a menu: a label on the leading side
+// and the shortcut of its Clickable, if any, as a hint on the trailing
+// side.
+type MenuItemStyle struct {
+	State *widget.Clickable
+	Label LabelStyle
+	// Hint is the style of the shortcut hint. Its text is set from the
+	// shortcut of State.
+	Hint LabelStyle
+	// HintInset is the space between the label and the hint.
+	HintInset unit.Dp
+	Inset     layout.Inset
+}
+
+// MenuItem styles a menu item with a label.
+func MenuItem(th *Theme, state *widget.Clickable, label string) MenuItemStyle {
+	hint := Body2(th, "")
+	hint.Color = f32color.MulAlpha(th.Palette.Fg, 0xaa)
+	hint.MaxLines = 1
+	l := Body1(th, label)
+	l.MaxLines = 1
+	return MenuItemStyle{
+		State:     state,
+		Label:     l,
+		Hint:      hint,
+		HintInset: th.Density.Scale(24),
+		Inset: th.Density.Inset(layout.Inset{
+			Top: 8, Bottom: 8,
+			Left: 16, Right: 16,
+		}),
+	}
+}
+
+func (m MenuItemStyle) Layout(gtx layout.Context) layout.Dimensions {
+	return m.State.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		semantic.Button.Add(gtx.Ops)
+		return layout.Background{}.Layout(gtx,
+			func(gtx layout.Context) layout.Dimensions {
+				defer clip.Rect{Max: gtx.Constraints.Min}.Push(gtx.Ops).Pop()
+				stateLayer(m.State, m.Label.Color).Layout(gtx)
+				return layout.Dimensions{Size: gtx.Constraints.Min}
+			},
+			func(gtx layout.Context) layout.Dimensions {
+				return m.Inset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return m.layoutContent(gtx)
+				})
+			},
+		)
+	})
+}
+
+func (m MenuItemStyle) layoutContent(gtx layout.Context) layout.Dimensions {
+	if gtx.Queue == nil {
+		m.Label.Color = f32color.Disabled(m.Label.Color)
+		m.Hint.Color = f32color.Disabled(m.Hint.Color)
+	}
+	m.Hint.Text = m.State.Shortcut.Label()
+	if m.Hint.Text == "" {
+		return m.Label.Layout(gtx)
+	}
+	// The label takes the remaining width, aligning the hint to the
+	// trailing side.
+	return layout.Flex{Alignment: layout.Baseline}.Layout(gtx,
+		layout.Flexed(1, m.Label.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Inset{Left: m.HintInset}.Layout(gtx, m.Hint.Layout)
+		}),
+	)
+}
@@ -7,14 +7,35 @@ import (
 	"image/color"
 	"math"
 
+	"github.com/Seikaijyu/gio/f32"
+	"github.com/Seikaijyu/gio/internal/f32color"
 	"github.com/Seikaijyu/gio/layout"
 	"github.com/Seikaijyu/gio/op"
+	"github.com/Seikaijyu/gio/op/clip"
 	"github.com/Seikaijyu/gio/op/paint"
+	"github.com/Seikaijyu/gio/unit"
 )
 
 type ProgressCircleStyle struct {
 	Color    color.NRGBA
 	Progress float32
+	// StartAngle is where the indicator starts, in radians clockwise
+	// from the top of the circle.
+	StartAngle float32
+	// Sweep is the angle covered by the indicator at full progress,
+	// in radians. Zero means a full circle.
+	Sweep float32
+	// Width of the indicator. Zero means a quarter of the radius.
+	Width unit.Dp
+	// RoundCaps rounds the ends of the indicator and the track.
+	RoundCaps bool
+	// TrackColor is the color of the track along the full sweep. The
+	// track is not drawn if TrackColor is transparent.
+	TrackColor color.NRGBA
+	// Ticks is the number of tick marks spaced evenly along the sweep,
+	// inside the track. TickColor is their color.
+	Ticks     int
+	TickColor color.NRGBA
 }
 
 func ProgressCircle(th *Theme, progress float32) ProgressCircleStyle {
@@ -24,6 +45,21 @@ func ProgressCircle(th *Theme, progress float32) ProgressCircleStyle {
 	}
 }
 
+// Gauge returns a ProgressCircleStyle in the style of a dashboard gauge:
+// an open arc with rounded caps over a track.
+func Gauge(th *Theme, progress float32) ProgressCircleStyle {
+	return ProgressCircleStyle{
+		Color:      th.Palette.ContrastBg,
+		Progress:   progress,
+		StartAngle: -math.Pi * 3 / 4,
+		Sweep:      math.Pi * 3 / 2,
+		Width:      6,
+		RoundCaps:  true,
+		TrackColor: f32color.MulAlpha(th.Palette.Fg, 0x88),
+		TickColor:  f32color.MulAlpha(th.Palette.Fg, 0x88),
+	}
+}
+
 func (p ProgressCircleStyle) Layout(gtx layout.Context) layout.Dimensions {
 	diam := gtx.Constraints.Min.X
 	if minY := gtx.Constraints.Min.Y; minY > diam {
@@ -36,12 +72,104 @@ func (p ProgressCircleStyle) Layout(gtx layout.Context) layout.Dimensions {
 	radius := sz.X / 2
 	defer op.Offset(image.Pt(radius, radius)).Push(gtx.Ops).Pop()
 
-	defer clipLoader(gtx.Ops, -math.Pi/2, -math.Pi/2+math.Pi*2*p.Progress, float32(radius)).Push(gtx.Ops).Pop()
-	paint.ColorOp{
-		Color: p.Color,
-	}.Add(gtx.Ops)
-	paint.PaintOp{}.Add(gtx.Ops)
+	r := float32(radius)
+	width := float32(gtx.Dp(p.Width))
+	if width == 0 {
+		width = r * .25
+	}
+	start, sweep, progress := p.angles()
+	if p.TrackColor.A != 0 {
+		paint.FillShape(gtx.Ops, p.TrackColor, clipArc(gtx.Ops, start, sweep, r, width, p.RoundCaps))
+	}
+	if p.Ticks > 0 && p.TickColor.A != 0 {
+		p.layoutTicks(gtx, start, sweep, r-width)
+	}
+	if progress > 0 {
+		paint.FillShape(gtx.Ops, p.Color, clipArc(gtx.Ops, start, progress, r, width, p.RoundCaps))
+	}
 	return layout.Dimensions{
 		Size: sz,
 	}
 }
+
+// angles returns the start of the track and the indicator, clockwise
+// from the positive x axis, the sweep of the track, and the sweep of the
+// indicator for the progress clamped to [0, 1].
+func (p ProgressCircleStyle) angles() (start, sweep, progress float32) {
+	start = p.StartAngle - math.Pi/2
+	sweep = p.Sweep
+	if sweep == 0 {
+		sweep = math.Pi * 2
+	}
+	progress = p.Progress
+	if progress > 1 {
+		progress = 1
+	}
+	if progress < 0 {
+		progress = 0
+	}
+	return start, sweep, sweep * progress
+}
+
+// tickAngle returns the angle of tick i along the sweep.
+func (p ProgressCircleStyle) tickAngle(i int, start, sweep float32) float32 {
+	// Ticks of a full circle don't repeat the first tick at the end.
+	n := p.Ticks - 1
+	if sweep >= math.Pi*2 || n == 0 {
+		n++
+	}
+	return start + sweep*float32(i)/float32(n)
+}
+
+// layoutTicks draws the tick marks inside the circle of radius r.
+func (p ProgressCircleStyle) layoutTicks(gtx layout.Context, start, sweep, r float32) {
+	gap := float32(gtx.Dp(2))
+	length := float32(gtx.Dp(4))
+	var path clip.Path
+	path.Begin(gtx.Ops)
+	for i := 0; i < p.Ticks; i++ {
+		a := p.tickAngle(i, start, sweep)
+		vy, vx := math.Sincos(float64(a))
+		v := f32.Pt(float32(vx), float32(vy))
+		path.MoveTo(v.Mul(r - gap - length))
+		path.LineTo(v.Mul(r - gap))
+	}
+	paint.FillShape(gtx.Ops, p.TickColor, clip.Stroke{
+		Path:  path.End(),
+		Width: float32(gtx.Dp(1)),
+	}.Op())
+}
+
+// clipArc returns the clip of an arc of the given width along the inside
+// of the circle of radius r centered at the origin. The arc starts at
+// angle start, clockwise from the positive x axis, and covers sweep
+// radians.
+func clipArc(ops *op.Ops, start, sweep, r, width float32, roundCaps bool) clip.Op {
+	outer, inner := r, r-width
+	center := r - width/2
+	dir := func(a float32) f32.Point {
+		vy, vx := math.Sincos(float64(a))
+		return f32.Pt(float32(vx), float32(vy))
+	}
+	end := start + sweep
+
+	var p clip.Path
+	p.Begin(ops)
+	p.MoveTo(dir(start).Mul(outer))
+	p.ArcTo(f32.Point{}, f32.Point{}, sweep)
+	if roundCaps {
+		c := dir(end).Mul(center)
+		p.ArcTo(c, c, math.Pi)
+	} else {
+		p.LineTo(dir(end).Mul(inner))
+	}
+	p.ArcTo(f32.Point{}, f32.Point{}, -sweep)
+	if roundCaps {
+		c := dir(start).Mul(center)
+		p.ArcTo(c, c, math.Pi)
+	} else {
+		p.LineTo(dir(start).Mul(outer))
+	}
+	p.Close()
+	return clip.Outline{Path: p.End()}.Op()
+}
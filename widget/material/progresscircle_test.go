@@ -0,0 +1,117 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package material
+
+import (
+	"bytes"
+	"image"
+	"math"
+	"testing"
+
+	"github.com/Seikaijyu/gio/internal/ops"
+	"github.com/Seikaijyu/gio/io/system"
+	"github.com/Seikaijyu/gio/layout"
+	"github.com/Seikaijyu/gio/op"
+)
+
+// angleEq reports whether the angles a and b are equal, up to rounding.
+func angleEq(a, b float32) bool {
+	return math.Abs(float64(a-b)) < 1e-5
+}
+
+func TestProgressCircleAngles(t *testing.T) {
+	th := NewTheme()
+	custom := ProgressCircle(th, .5)
+	custom.StartAngle, custom.Sweep = math.Pi/2, math.Pi
+	tests := []struct {
+		name                   string
+		style                  ProgressCircleStyle
+		start, sweep, progress float32
+	}{
+		// A full circle starts at the top.
+		{"circle", ProgressCircle(th, .25), -math.Pi / 2, 2 * math.Pi, math.Pi / 2},
+		{"gauge", Gauge(th, .5), -math.Pi * 5 / 4, math.Pi * 3 / 2, math.Pi * 3 / 4},
+		{"custom", custom, 0, math.Pi, math.Pi / 2},
+		// The progress is clamped to [0, 1].
+		{"empty", Gauge(th, 0), -math.Pi * 5 / 4, math.Pi * 3 / 2, 0},
+		{"below", Gauge(th, -.5), -math.Pi * 5 / 4, math.Pi * 3 / 2, 0},
+		{"full", Gauge(th, 1), -math.Pi * 5 / 4, math.Pi * 3 / 2, math.Pi * 3 / 2},
+		{"above", Gauge(th, 1.5), -math.Pi * 5 / 4, math.Pi * 3 / 2, math.Pi * 3 / 2},
+	}
+	for _, tc := range tests {
+		start, sweep, progress := tc.style.angles()
+		if !angleEq(start, tc.start) || !angleEq(sweep, tc.sweep) || !angleEq(progress, tc.progress) {
+			t.Errorf("%s: angles (%v, %v, %v), want (%v, %v, %v)", tc.name, start, sweep, progress, tc.start, tc.sweep, tc.progress)
+		}
+	}
+}
+
+func TestProgressCircleTicks(t *testing.T) {
+	th := NewTheme()
+	tests := []struct {
+		name   string
+		style  ProgressCircleStyle
+		angles []float32
+	}{
+		// The ticks of the open 270° arc of a gauge include both ends.
+		{"gauge", Gauge(th, 0), []float32{0, math.Pi / 2, math.Pi, math.Pi * 3 / 2}},
+		// The ticks of a full circle don't repeat the start at the end.
+		{"circle", ProgressCircle(th, 0), []float32{0, math.Pi / 2, math.Pi, math.Pi * 3 / 2}},
+		{"single", Gauge(th, 0), []float32{0}},
+	}
+	for _, tc := range tests {
+		tc.style.Ticks = len(tc.angles)
+		start, sweep, _ := tc.style.angles()
+		for i, want := range tc.angles {
+			if got := tc.style.tickAngle(i, start, sweep) - start; !angleEq(got, want) {
+				t.Errorf("%s: tick %d at %v from the start, want %v", tc.name, i, got, want)
+			}
+		}
+	}
+}
+
+func TestProgressCircleLayout(t *testing.T) {
+	th := NewTheme()
+	// draw lays out p and returns its size and encoded ops.
+	draw := func(p ProgressCircleStyle) (image.Point, [][]byte) {
+		o := new(op.Ops)
+		gtx := layout.NewContext(o, system.FrameEvent{Size: image.Pt(100, 100)})
+		gtx.Constraints.Min = image.Point{}
+		dims := p.Layout(gtx)
+		var r ops.Reader
+		r.Reset(&o.Internal)
+		var encs [][]byte
+		for {
+			e, more := r.Decode()
+			if !more {
+				return dims.Size, encs
+			}
+			encs = append(encs, e.Data)
+		}
+	}
+	eq := func(a, b [][]byte) bool {
+		if len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !bytes.Equal(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	size, full := draw(Gauge(th, 1))
+	if want := image.Pt(24, 24); size != want {
+		t.Errorf("size %v without constraints, want %v", size, want)
+	}
+	if _, above := draw(Gauge(th, 3)); !eq(above, full) {
+		t.Error("progress above 1 drew differently from full progress")
+	}
+	_, empty := draw(Gauge(th, 0))
+	if _, below := draw(Gauge(th, -1)); !eq(below, empty) {
+		t.Error("progress below 0 drew differently from no progress")
+	}
+	if len(empty) >= len(full) {
+		t.Error("no progress drew the indicator")
+	}
+}
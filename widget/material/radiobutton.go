@@ -43,7 +43,11 @@ func (r RadioButtonStyle) Layout(gtx layout.Context) layout.Dimensions {
 	focus, focused := r.Group.Focused()
 	return r.Group.Layout(gtx, r.Key, func(gtx layout.Context) layout.Dimensions {
 		semantic.RadioButton.Add(gtx.Ops)
-		highlight := hovering && hovered == r.Key || focused && focus == r.Key
-		return r.layout(gtx, r.Group.Value == r.Key, highlight)
+		state := StateLayer{
+			Color:   r.IconColor,
+			Hovered: hovering && hovered == r.Key,
+			Focused: focused && focus == r.Key,
+		}
+		return r.layout(gtx, r.Group.Value == r.Key, state)
 	})
 }
@@ -0,0 +1,50 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package material
+
+import (
+	"image/color"
+
+	"github.com/Seikaijyu/gio/layout"
+	"github.com/Seikaijyu/gio/text"
+	"github.com/Seikaijyu/gio/widget"
+)
+
+// RichTextStyle lays out spans of rich text in the style of a theme.
+type RichTextStyle struct {
+	State  *widget.RichText
+	Spans  []widget.RichSpan
+	Shaper *text.Shaper
+}
+
+// RichText styles spans as text of the theme: spans without a size,
+// color or typeface take those of the theme, and links are underlined in
+// the contrast color.
+func RichText(th *Theme, state *widget.RichText, spans ...widget.RichSpan) RichTextStyle {
+	styled := make([]widget.RichSpan, len(spans))
+	for i, s := range spans {
+		if s.Size == 0 {
+			s.Size = th.TextSize
+		}
+		if s.Font.Typeface == "" {
+			s.Font.Typeface = th.Face
+		}
+		if s.Color == (color.NRGBA{}) {
+			s.Color = th.Palette.Fg
+			if s.Link != "" {
+				s.Color = th.Palette.ContrastBg
+				s.Underline = true
+			}
+		}
+		styled[i] = s
+	}
+	return RichTextStyle{
+		State:  state,
+		Spans:  styled,
+		Shaper: th.Shaper,
+	}
+}
+
+func (r RichTextStyle) Layout(gtx layout.Context) layout.Dimensions {
+	return r.State.Layout(gtx, r.Shaper, r.Spans...)
+}
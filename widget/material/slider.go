@@ -20,7 +20,7 @@ func Slider(th *Theme, float *widget.Float) SliderStyle {
 	return SliderStyle{
 		Color:      th.Palette.ContrastBg,
 		Float:      float,
-		FingerSize: th.FingerSize,
+		FingerSize: th.Density.Scale(th.FingerSize),
 	}
 }
 
@@ -52,7 +52,7 @@ func (s SliderStyle) Layout(gtx layout.Context) layout.Dimensions {
 	gtx.Constraints.Min = axis.Convert(image.Pt(sizeMain-2*tr, sizeCross))
 	dims := s.Float.Layout(gtx, axis, thumbRadius)
 	gtx.Constraints.Min = gtx.Constraints.Min.Add(axis.Convert(image.Pt(0, sizeCross)))
-	thumbPos := tr + int(s.Float.Value*float32(axis.Convert(dims.Size).X))
+	thumbPos := tr + int(s.Float.Pos()*float32(axis.Convert(dims.Size).X))
 	trans.Pop()
 
 	color := s.Color
@@ -0,0 +1,98 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package material
+
+import (
+	"image/color"
+
+	"github.com/Seikaijyu/gio/internal/f32color"
+	"github.com/Seikaijyu/gio/layout"
+	"github.com/Seikaijyu/gio/op/paint"
+)
+
+// StateLayer is the translucent overlay that indicates the interaction
+// state of a component, such as hovered, focused or pressed. It is drawn
+// over the container of the component and below its content.
+//
+// Because the layer is translucent, it works on any container color,
+// including surfaces tinted to indicate their elevation. Use the
+// content color of the component, for example the text color of a
+// button, as Color.
+type StateLayer struct {
+	// Color of the layer. Its alpha is scaled by the opacity of the
+	// state.
+	Color   color.NRGBA
+	Hovered bool
+	Focused bool
+	Pressed bool
+}
+
+// Opacities of the state layer, from the Material Design guidelines.
+const (
+	stateHoverAlpha = 0x14 // 8%
+	stateFocusAlpha = 0x1f // 12%
+	statePressAlpha = 0x1f // 12%
+)
+
+// interactive is implemented by widgets that track their interaction
+// state, such as widget.Clickable and widget.Bool.
+type interactive interface {
+	Hovered() bool
+	Focused() bool
+	Pressed() bool
+}
+
+// stateLayer returns the state layer for w, in color col.
+func stateLayer(w interactive, col color.NRGBA) StateLayer {
+	return StateLayer{
+		Color:   col,
+		Hovered: w.Hovered(),
+		Focused: w.Focused(),
+		Pressed: w.Pressed(),
+	}
+}
+
+// Layout fills the current clip area with the layer. Nothing is drawn
+// for disabled components, where gtx.Queue is nil.
+func (s StateLayer) Layout(gtx layout.Context) layout.Dimensions {
+	dims := layout.Dimensions{Size: gtx.Constraints.Min}
+	if gtx.Queue == nil {
+		return dims
+	}
+	alpha := s.alpha()
+	if alpha == 0 {
+		return dims
+	}
+	paint.Fill(gtx.Ops, f32color.MulAlpha(s.Color, alpha))
+	return dims
+}
+
+// alpha returns the opacity of the layer, the largest opacity of its
+// states.
+func (s StateLayer) alpha() uint8 {
+	var alpha uint8
+	if s.Hovered {
+		alpha = stateHoverAlpha
+	}
+	if s.Focused && alpha < stateFocusAlpha {
+		alpha = stateFocusAlpha
+	}
+	if s.Pressed && alpha < statePressAlpha {
+		alpha = statePressAlpha
+	}
+	return alpha
+}
+
+// stateColor returns a state layer color for components without
+// content color: white for dark backgrounds, black for light
+// backgrounds and gray for transparent backgrounds.
+func stateColor(bg color.NRGBA) color.NRGBA {
+	switch {
+	case bg.A == 0:
+		return color.NRGBA{R: 0x88, G: 0x88, B: 0x88, A: 0xff}
+	case f32color.LinearFromSRGB(bg).Luminance() > 0.18:
+		return color.NRGBA{A: 0xff}
+	default:
+		return color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	}
+}
@@ -0,0 +1,104 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package material
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/Seikaijyu/gio/internal/ops"
+	"github.com/Seikaijyu/gio/io/router"
+	"github.com/Seikaijyu/gio/io/system"
+	"github.com/Seikaijyu/gio/layout"
+	"github.com/Seikaijyu/gio/op"
+)
+
+func TestStateLayerAlpha(t *testing.T) {
+	tests := []struct {
+		layer StateLayer
+		alpha uint8
+	}{
+		{StateLayer{}, 0},
+		{StateLayer{Hovered: true}, stateHoverAlpha},
+		{StateLayer{Focused: true}, stateFocusAlpha},
+		{StateLayer{Pressed: true}, statePressAlpha},
+		// The strongest state wins.
+		{StateLayer{Hovered: true, Focused: true}, stateFocusAlpha},
+		{StateLayer{Hovered: true, Pressed: true}, statePressAlpha},
+		{StateLayer{Focused: true, Pressed: true}, statePressAlpha},
+		{StateLayer{Hovered: true, Focused: true, Pressed: true}, statePressAlpha},
+	}
+	for _, tc := range tests {
+		if got := tc.layer.alpha(); got != tc.alpha {
+			t.Errorf("%+v: alpha %#x, want %#x", tc.layer, got, tc.alpha)
+		}
+	}
+}
+
+func TestStateLayerLayout(t *testing.T) {
+	var o op.Ops
+	// colors returns the colors painted by l.
+	colors := func(l StateLayer, enabled bool) []color.NRGBA {
+		o.Reset()
+		gtx := layout.NewContext(&o, system.FrameEvent{Size: image.Pt(100, 100)})
+		if enabled {
+			gtx.Queue = new(router.Router)
+		}
+		if dims := l.Layout(gtx); dims.Size != gtx.Constraints.Min {
+			t.Errorf("%+v: size %v, want the minimum constraint %v", l, dims.Size, gtx.Constraints.Min)
+		}
+		var r ops.Reader
+		r.Reset(&o.Internal)
+		var cols []color.NRGBA
+		for {
+			e, more := r.Decode()
+			if !more {
+				return cols
+			}
+			if ops.OpType(e.Data[0]) == ops.TypeColor {
+				cols = append(cols, color.NRGBA{R: e.Data[1], G: e.Data[2], B: e.Data[3], A: e.Data[4]})
+			}
+		}
+	}
+	col := color.NRGBA{R: 0x10, G: 0x20, B: 0x30, A: 0xff}
+	if got := colors(StateLayer{Color: col}, true); len(got) > 0 {
+		t.Errorf("layer without state painted %v", got)
+	}
+	if got := colors(StateLayer{Color: col, Pressed: true}, false); len(got) > 0 {
+		t.Errorf("disabled layer painted %v", got)
+	}
+	want := col
+	want.A = statePressAlpha
+	if got := colors(StateLayer{Color: col, Pressed: true}, true); len(got) != 1 || got[0] != want {
+		t.Errorf("pressed layer painted %v, want %v", got, want)
+	}
+	// The alpha of the color is scaled by the opacity of the state.
+	col.A = 0x80
+	want = col
+	want.A = 0x80 * stateHoverAlpha / 0xff
+	if got := colors(StateLayer{Color: col, Hovered: true}, true); len(got) != 1 || got[0] != want {
+		t.Errorf("hovered layer of translucent color painted %v, want %v", got, want)
+	}
+}
+
+func TestStateColor(t *testing.T) {
+	var (
+		black = color.NRGBA{A: 0xff}
+		white = color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	)
+	tests := []struct {
+		bg, want color.NRGBA
+	}{
+		{color.NRGBA{}, color.NRGBA{R: 0x88, G: 0x88, B: 0x88, A: 0xff}},
+		{white, black},
+		{color.NRGBA{R: 0xff, G: 0xeb, B: 0x3b, A: 0xff}, black},
+		{black, white},
+		{color.NRGBA{R: 0x3f, G: 0x51, B: 0xb5, A: 0xff}, white},
+	}
+	for _, tc := range tests {
+		if got := stateColor(tc.bg); got != tc.want {
+			t.Errorf("state color on %v is %v, want %v", tc.bg, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package material
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/Seikaijyu/gio/internal/f32color"
+	"github.com/Seikaijyu/gio/layout"
+	"github.com/Seikaijyu/gio/op/clip"
+	"github.com/Seikaijyu/gio/op/paint"
+	"github.com/Seikaijyu/gio/text"
+	"github.com/Seikaijyu/gio/unit"
+	"github.com/Seikaijyu/gio/widget"
+)
+
+// EmptyStateStyle lays out the placeholder for content that is empty,
+// such as a list without items or a search without results.
+type EmptyStateStyle struct {
+	// Icon is an optional illustration above the title.
+	Icon      *widget.Icon
+	IconColor color.NRGBA
+	IconSize  unit.Dp
+	Title     LabelStyle
+	Message   LabelStyle
+	// Action is an optional button below the message, such as for
+	// creating the first item. It is laid out if its Button is set.
+	Action ButtonStyle
+	// MaxWidth limits the width of the text.
+	MaxWidth unit.Dp
+}
+
+// ErrorBannerStyle lays out an inline banner for an error, with an
+// optional button to retry the failed operation.
+type ErrorBannerStyle struct {
+	Message      LabelStyle
+	Background   color.NRGBA
+	CornerRadius unit.Dp
+	Inset        layout.Inset
+	// Retry is laid out if its Button is set. An empty Text selects
+	// the message "gio.retry" of the context.
+	Retry ButtonStyle
+}
+
+// LoadingStateStyle lays out a loading indicator and message over the
+// whole area, in place of content being loaded. Use a widget.Loading to
+// track the timeout of the operation.
+type LoadingStateStyle struct {
+	Loader LoaderStyle
+	// LoaderSize is the diameter of the loader.
+	LoaderSize unit.Dp
+	// Message is an optional message below the loader.
+	Message    LabelStyle
+	Background color.NRGBA
+}
+
+// errorColor is the background of error banners, the error color of
+// the Material Design baseline theme.
+var errorColor = color.NRGBA{R: 0xb3, G: 0x26, B: 0x1e, A: 0xff}
+
+func EmptyState(th *Theme, title, message string) EmptyStateStyle {
+	s := EmptyStateStyle{
+		IconColor: f32color.MulAlpha(th.Palette.Fg, 0x99),
+		IconSize:  48,
+		Title:     H6(th, title),
+		Message:   Body2(th, message),
+		MaxWidth:  360,
+	}
+	s.Title.Alignment = text.Middle
+	s.Message.Alignment = text.Middle
+	s.Message.Color = f32color.MulAlpha(th.Palette.Fg, 0xbb)
+	return s
+}
+
+func ErrorBanner(th *Theme, retry *widget.Clickable, message string) ErrorBannerStyle {
+	fg := color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	s := ErrorBannerStyle{
+		Message:      Body2(th, message),
+		Background:   errorColor,
+		CornerRadius: 4,
+		Inset:        th.Density.Inset(layout.Inset{Top: 8, Bottom: 8, Left: 16, Right: 8}),
+		Retry:        Button(th, retry, ""),
+	}
+	s.Message.Color = fg
+	s.Retry.Background = color.NRGBA{}
+	s.Retry.Color = fg
+	return s
+}
+
+func LoadingState(th *Theme, message string) LoadingStateStyle {
+	s := LoadingStateStyle{
+		Loader:     Loader(th),
+		LoaderSize: 48,
+		Message:    Body1(th, message),
+		Background: th.Palette.Bg,
+	}
+	s.Message.Alignment = text.Middle
+	return s
+}
+
+// Layout the empty state centered in the constraints.
+func (s EmptyStateStyle) Layout(gtx layout.Context) layout.Dimensions {
+	gap := layout.Spacer{Height: 8}
+	var children []layout.FlexChild
+	if s.Icon != nil {
+		children = append(children,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				gtx.Constraints.Min = image.Pt(gtx.Dp(s.IconSize), gtx.Dp(s.IconSize))
+				return s.Icon.Layout(gtx, s.IconColor)
+			}),
+			layout.Rigid(gap.Layout),
+		)
+	}
+	children = append(children,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return s.text(gtx, s.Title)
+		}),
+	)
+	if s.Message.Text != "" {
+		children = append(children,
+			layout.Rigid(gap.Layout),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return s.text(gtx, s.Message)
+			}),
+		)
+	}
+	if s.Action.Button != nil {
+		children = append(children,
+			layout.Rigid(layout.Spacer{Height: 16}.Layout),
+			layout.Rigid(s.Action.Layout),
+		)
+	}
+	return layout.Center.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		gtx.Constraints.Min = image.Point{}
+		return layout.Flex{Axis: layout.Vertical, Alignment: layout.Middle}.Layout(gtx, children...)
+	})
+}
+
+func (s EmptyStateStyle) text(gtx layout.Context, l LabelStyle) layout.Dimensions {
+	if w := gtx.Dp(s.MaxWidth); w > 0 && gtx.Constraints.Max.X > w {
+		gtx.Constraints.Max.X = w
+	}
+	return l.Layout(gtx)
+}
+
+// Layout the banner across the width of the constraints.
+func (b ErrorBannerStyle) Layout(gtx layout.Context) layout.Dimensions {
+	gtx.Constraints.Min.Y = 0
+	retry := b.Retry
+	if retry.Text == "" {
+		retry.Text = gtx.Message("gio.retry")
+	}
+	return layout.Background{}.Layout(gtx,
+		func(gtx layout.Context) layout.Dimensions {
+			sz := gtx.Constraints.Min
+			rr := gtx.Dp(b.CornerRadius)
+			paint.FillShape(gtx.Ops, b.Background, clip.UniformRRect(image.Rectangle{Max: sz}, rr).Op(gtx.Ops))
+			return layout.Dimensions{Size: sz}
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return b.Inset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+					layout.Flexed(1, b.Message.Layout),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						if retry.Button == nil {
+							return layout.Dimensions{}
+						}
+						return layout.Inset{Left: 8}.Layout(gtx, retry.Layout)
+					}),
+				)
+			})
+		},
+	)
+}
+
+// Layout the loading state over the maximum constraints.
+func (l LoadingStateStyle) Layout(gtx layout.Context) layout.Dimensions {
+	size := gtx.Constraints.Max
+	paint.FillShape(gtx.Ops, l.Background, clip.Rect{Max: size}.Op())
+	gtx.Constraints = layout.Exact(size)
+	layout.Center.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		gtx.Constraints.Min = image.Point{}
+		return layout.Flex{Axis: layout.Vertical, Alignment: layout.Middle}.Layout(gtx,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				d := gtx.Dp(l.LoaderSize)
+				gtx.Constraints = layout.Exact(gtx.Constraints.Constrain(image.Pt(d, d)))
+				return l.Loader.Layout(gtx)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				if l.Message.Text == "" {
+					return layout.Dimensions{}
+				}
+				return layout.Inset{Top: 16}.Layout(gtx, l.Message.Layout)
+			}),
+		)
+	})
+	return layout.Dimensions{Size: size}
+}
@@ -23,6 +23,8 @@ type SwitchStyle struct {
 		Track    color.NRGBA
 	}
 	Switch *widget.Bool
+
+	density Density
 }
 
 // Switch is for selecting a boolean value.
@@ -30,6 +32,7 @@ func Switch(th *Theme, swtch *widget.Bool, description string) SwitchStyle {
 	sw := SwitchStyle{
 		Switch:      swtch,
 		Description: description,
+		density:     th.Density,
 	}
 	sw.Color.Enabled = th.Palette.ContrastBg
 	sw.Color.Disabled = th.Palette.Bg
@@ -67,7 +70,7 @@ func (s SwitchStyle) Layout(gtx layout.Context) layout.Dimensions {
 	t.Pop()
 
 	// Draw thumb ink.
-	inkSize := gtx.Dp(44)
+	inkSize := gtx.Dp(s.density.Scale(44))
 	rr := inkSize / 2
 	inkOff := image.Point{
 		X: trackWidth/2 - rr,
@@ -92,12 +95,10 @@ func (s SwitchStyle) Layout(gtx layout.Context) layout.Dimensions {
 		}
 		return clip.Ellipse(b).Op(gtx.Ops)
 	}
-	// Draw hover.
-	if s.Switch.Hovered() || s.Switch.Focused() {
-		r := thumbRadius * 10 / 17
-		background := f32color.MulAlpha(s.Color.Enabled, 70)
-		paint.FillShape(gtx.Ops, background, circle(thumbRadius, thumbRadius, r))
-	}
+	// Draw the state layer around the thumb.
+	layer := circle(thumbRadius, thumbRadius, gtx.Dp(s.density.Scale(20))).Push(gtx.Ops)
+	stateLayer(s.Switch, s.Color.Enabled).Layout(gtx)
+	layer.Pop()
 
 	// Draw thumb shadow, a translucent disc slightly larger than the
 	// thumb itself.
@@ -108,7 +109,7 @@ func (s SwitchStyle) Layout(gtx layout.Context) layout.Dimensions {
 	paint.FillShape(gtx.Ops, col, circle(thumbRadius, thumbRadius, thumbRadius))
 
 	// Set up click area.
-	clickSize := gtx.Dp(40)
+	clickSize := gtx.Dp(s.density.Scale(40))
 	clickOff := image.Point{
 		X: (thumbSize - clickSize) / 2,
 		Y: (trackHeight-clickSize)/2 + trackOff,
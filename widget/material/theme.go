@@ -7,6 +7,7 @@ import (
 
 	"golang.org/x/exp/shiny/materialdesign/icons"
 
+	"github.com/Seikaijyu/gio/colors"
 	"github.com/Seikaijyu/gio/font"
 	"github.com/Seikaijyu/gio/text"
 	"github.com/Seikaijyu/gio/unit"
@@ -32,6 +33,45 @@ type Palette struct {
 	ContrastFg color.NRGBA
 }
 
+// NewPalette returns a palette with the background bg and the contrast
+// background contrastBg. The foreground colors are black or white,
+// whichever is more readable on their background.
+func NewPalette(bg, contrastBg color.NRGBA) Palette {
+	return Palette{
+		Bg:         bg,
+		Fg:         colors.OnColor(bg),
+		ContrastBg: contrastBg,
+		ContrastFg: colors.OnColor(contrastBg),
+	}
+}
+
+// SettingsStore is a store of persistent settings, such as
+// app.Settings.
+type SettingsStore interface {
+	// Get decodes the setting for key into v and reports whether it
+	// was present and valid.
+	Get(key string, v interface{}) bool
+	// Set changes the setting for key to v.
+	Set(key string, v interface{}) error
+}
+
+// settingPalette is the key of the palette saved by SavePalette.
+const settingPalette = "material.palette"
+
+// LoadPalette returns the palette saved in s by SavePalette, or def if
+// none is saved.
+func LoadPalette(s SettingsStore, def Palette) Palette {
+	p := def
+	s.Get(settingPalette, &p)
+	return p
+}
+
+// SavePalette saves the palette p in s, such as after the user chose a
+// theme.
+func SavePalette(s SettingsStore, p Palette) error {
+	return s.Set(settingPalette, p)
+}
+
 type Theme struct {
 	Shaper *text.Shaper
 	Palette
@@ -47,17 +87,15 @@ type Theme struct {
 
 	// FingerSize is the minimum touch target size.
 	FingerSize unit.Dp
+	// Density scales the paddings, touch targets and component heights
+	// of the styles created from the theme.
+	Density Density
 }
 
 // NewTheme constructs a theme (and underlying text shaper).
 func NewTheme() *Theme {
 	t := &Theme{Shaper: &text.Shaper{}}
-	t.Palette = Palette{
-		Fg:         rgb(0x000000),
-		Bg:         rgb(0xffffff),
-		ContrastBg: rgb(0x3f51b5),
-		ContrastFg: rgb(0xffffff),
-	}
+	t.Palette = NewPalette(rgb(0xffffff), rgb(0x3f51b5))
 	t.TextSize = 16
 
 	t.Icon.CheckBoxChecked = mustIcon(widget.NewIcon(icons.ToggleCheckBox))
@@ -0,0 +1,38 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package material
+
+import (
+	"encoding/json"
+	"image/color"
+	"testing"
+)
+
+// jsonStore is a SettingsStore that encodes settings like app.Settings.
+type jsonStore map[string][]byte
+
+func (s jsonStore) Get(key string, v interface{}) bool {
+	data, ok := s[key]
+	return ok && json.Unmarshal(data, v) == nil
+}
+
+func (s jsonStore) Set(key string, v interface{}) error {
+	data, err := json.Marshal(v)
+	s[key] = data
+	return err
+}
+
+func TestSavePalette(t *testing.T) {
+	store := make(jsonStore)
+	def := NewPalette(color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}, color.NRGBA{R: 0x3f, G: 0x51, B: 0xb5, A: 0xff})
+	if got := LoadPalette(store, def); got != def {
+		t.Errorf("loaded %+v without a saved palette, want the default", got)
+	}
+	dark := NewPalette(color.NRGBA{R: 0x12, G: 0x12, B: 0x12, A: 0xff}, color.NRGBA{R: 0xbb, G: 0x86, B: 0xfc, A: 0xff})
+	if err := SavePalette(store, dark); err != nil {
+		t.Fatal(err)
+	}
+	if got := LoadPalette(store, def); got != dark {
+		t.Errorf("loaded %+v, want the saved %+v", got, dark)
+	}
+}
@@ -0,0 +1,511 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package widget
+
+import (
+	"bytes"
+	"image"
+	"io"
+	"math"
+	"sort"
+	"unicode/utf8"
+
+	"github.com/Seikaijyu/gio/io/system"
+	"github.com/Seikaijyu/gio/layout"
+	"github.com/Seikaijyu/gio/text"
+	"golang.org/x/exp/slices"
+	"golang.org/x/image/math/fixed"
+)
+
+// incrementalLayoutSize is the text size in bytes from which textView
+// shapes and caches paragraphs individually. Smaller texts are shaped as
+// a whole. It is a variable for testing.
+var incrementalLayoutSize int64 = 32 << 10
+
+// paragraphLayout is the cached layout of a paragraph of text.
+type paragraphLayout struct {
+	// bytes and runes are the length of the paragraph, including
+	// its terminating newline, if any.
+	bytes, runes int
+	// shaped reports whether the layout below is valid. If only
+	// estimated is set, the layout is an estimate of the glyphs of a
+	// paragraph away from the viewport, made from the metrics of a
+	// single shaped glyph.
+	shaped, estimated bool
+	// index of the glyphs of the paragraph. Coordinates are relative to
+	// the baseline of the first line.
+	index glyphIndex
+	// bounds are the logical bounds of the glyphs.
+	bounds image.Rectangle
+	// trailer is the synthetic glyph following a terminating newline,
+	// if trailing is set. It is only part of the text if the paragraph
+	// is the last.
+	trailer  text.Glyph
+	trailing bool
+	// top is the baseline of the first line if the paragraph starts the
+	// text.
+	top int
+	// lead is the distance from the last baseline of the previous
+	// paragraph to the first baseline of this paragraph.
+	lead int
+	// bottom is the baseline of the last line, relative to the first.
+	bottom int
+	// graphemes are the cluster boundaries of the paragraph, in runes
+	// relative to its start.
+	graphemes []int
+	// end is the layout state following the paragraph, excluding the
+	// trailer.
+	end paragraphEnd
+	// y is the first baseline of the paragraph in the last layout, if
+	// laid is set.
+	y    int
+	laid bool
+}
+
+// paragraphEstimate is the shaped glyph estimated paragraphs are made of.
+type paragraphEstimate struct {
+	// glyph is a shaped digit, whose advance stands for every rune.
+	glyph text.Glyph
+	// lead is the distance between two baselines.
+	lead int
+}
+
+// paragraphEnd is the state of a paragraph layout following a paragraph.
+type paragraphEnd struct {
+	// bytes and runes are the offset of the following paragraph.
+	bytes, runes int
+	// y is the last baseline.
+	y int
+	// glyphs, positions, lines and graphemes are the lengths of the
+	// corresponding slices of the text index.
+	glyphs, positions, lines, graphemes int
+	// bounds of the text so far.
+	bounds image.Rectangle
+}
+
+// paragraphKey is the configuration of a paragraph layout.
+type paragraphKey struct {
+	params text.Parameters
+	shaper *text.Shaper
+	indent fixed.Int26_6
+}
+
+// incremental reports whether the text is large enough to be laid out by
+// paragraph, and the layout of a paragraph doesn't depend on the rest of
+// the text. Truncation depends on the lines before a paragraph, and
+// alignments other than the start depend on the widest paragraph.
+func (e *textView) incremental() bool {
+	size := e.rr.Size()
+	return size > 0 && size >= incrementalLayoutSize &&
+		e.Mask == 0 &&
+		e.params.MaxLines == 0 &&
+		e.params.Alignment == text.Start &&
+		e.params.Locale.Direction.Progression() == system.FromOrigin
+}
+
+// layoutParagraphs lays out the text from its cached paragraphs,
+// reshaping only the paragraphs that changed since the last layout, and
+// reindexing from the first of them. Only the paragraphs near the
+// viewport and the paragraphs of carets are shaped; the layout of the
+// others is estimated, and the viewport is moved to keep its content in
+// place when estimates are replaced by shaped paragraphs.
+func (e *textView) layoutParagraphs(lt *text.Shaper) {
+	key := paragraphKey{params: e.params, shaper: lt, indent: e.indent}
+	size := e.rr.Size()
+	var total int64
+	for _, p := range e.paragraphs {
+		total += int64(p.bytes)
+	}
+	if key != e.paragraphKey || total != size || len(e.paragraphs) == 0 {
+		e.paragraphKey = key
+		buf := make([]byte, size)
+		n, _ := io.ReadFull(io.NewSectionReader(e.rr, 0, size), buf)
+		e.paragraphs = e.splitParagraphs(e.paragraphs[:0], buf[:n])
+		e.laidOut = 0
+		e.estimate = estimateParagraphs(lt, e.params)
+	}
+	anchor, offset := e.shapeVisible(lt)
+	if e.laidOut == len(e.paragraphs) {
+		return
+	}
+	var end paragraphEnd
+	if e.laidOut == 0 {
+		e.index.reset()
+		e.graphemes = e.graphemes[:0]
+	} else {
+		// Keep the index of the paragraphs before the first changed one.
+		end = e.paragraphs[e.laidOut-1].end
+		e.index.truncate(end.glyphs, end.positions, end.lines, end.runes)
+		e.graphemes = e.graphemes[:end.graphemes]
+	}
+	for i := e.laidOut; i < len(e.paragraphs); i++ {
+		p := &e.paragraphs[i]
+		y := end.y + p.lead
+		if i == 0 {
+			y = p.top
+			end.bounds = p.bounds.Add(image.Pt(0, y))
+		} else {
+			end.bounds = extend(end.bounds, p.bounds.Add(image.Pt(0, y)))
+		}
+		p.y, p.laid = y, true
+		e.index.appendIndex(&p.index, y)
+		if i == 0 && len(e.index.glyphs) > 0 {
+			// The first paragraph doesn't follow a paragraph break.
+			e.index.glyphs[0].Flags &^= text.FlagParagraphStart
+		}
+		g := p.graphemes
+		if len(e.graphemes) > 0 && len(g) > 0 && g[0]+end.runes == e.graphemes[len(e.graphemes)-1] {
+			g = g[1:]
+		}
+		for _, g := range g {
+			e.graphemes = append(e.graphemes, g+end.runes)
+		}
+		end.bytes += p.bytes
+		end.runes += p.runes
+		end.y = y + p.bottom
+		end.glyphs = len(e.index.glyphs)
+		end.positions = len(e.index.positions)
+		end.lines = len(e.index.lines)
+		end.graphemes = len(e.graphemes)
+		p.end = end
+	}
+	e.laidOut = len(e.paragraphs)
+	bounds := end.bounds
+	if last := e.paragraphs[len(e.paragraphs)-1]; last.trailing {
+		g := last.trailer
+		g.Y += int32(end.y - last.bottom)
+		bounds = extend(bounds, glyphBounds(g))
+		e.index.Glyph(g)
+	}
+	// Like textIterator, include the origin in the bounds.
+	bounds = extend(bounds, image.Rectangle{})
+	dims := layout.Dimensions{Size: bounds.Size()}
+	dims.Baseline = dims.Size.Y - e.paragraphs[0].top
+	e.dims = dims
+	if anchor != -1 {
+		e.scrollOff.Y = e.paragraphs[anchor].y + offset
+	}
+	// Record the span of shaped paragraphs around the viewport, so that
+	// scrolling out of it shapes the paragraphs scrolled into view.
+	e.shapedSpan = [2]int{math.MinInt, math.MaxInt}
+	for _, p := range e.paragraphs {
+		if p.shaped {
+			continue
+		}
+		if p.y+p.bounds.Max.Y <= e.scrollOff.Y {
+			e.shapedSpan[0] = p.y + p.bounds.Max.Y
+		} else {
+			e.shapedSpan[1] = p.y + p.bounds.Min.Y
+			break
+		}
+	}
+}
+
+// shapeVisible shapes the paragraphs that intersect the viewport,
+// extended by its height in both directions, and the paragraphs of the
+// carets. It estimates the layout of the other paragraphs that have none.
+// It returns the index of the laid out paragraph whose position the
+// viewport should keep, and the offset of the viewport from its first
+// baseline, or -1 if there is none.
+func (e *textView) shapeVisible(lt *text.Shaper) (anchor, offset int) {
+	viewTop, viewBottom := e.scrollOff.Y, e.scrollOff.Y+e.viewSize.Y
+	lo, hi := math.MinInt, math.MaxInt
+	if h := e.maxHeight; h < math.MaxInt/4 {
+		lo, hi = viewTop-h, viewTop+2*h
+	}
+	carets := e.caretRunes(nil)
+	slices.Sort(carets)
+	anchor, anchorCaret := -1, false
+	// The paragraphs from edited on were split anew since the last
+	// layout. Paragraphs following them may keep their position on
+	// screen only if the edit is above the viewport.
+	edited := e.laidOut
+	editAbove := false
+	var off, runes, y int
+	for i := range e.paragraphs {
+		p := &e.paragraphs[i]
+		if !p.shaped && !p.estimated {
+			e.estimateParagraph(p, off)
+		}
+		// The position of p in the last layout, or estimated from the
+		// preceding paragraph.
+		top := p.y
+		if !p.laid {
+			top = y + p.lead
+			if i == 0 {
+				top = p.top
+			}
+		}
+		minY, maxY := top+p.bounds.Min.Y, top+p.bounds.Max.Y
+		if i == edited {
+			editAbove = minY < viewTop
+		}
+		// Carets at the end of the text belong to the last paragraph.
+		endRunes := runes + p.runes
+		if i == len(e.paragraphs)-1 {
+			endRunes++
+		}
+		if p.laid && (i < edited || editAbove) && minY < viewBottom && maxY > viewTop {
+			primary := e.caret.start >= runes && e.caret.start < endRunes
+			if anchor == -1 || primary && !anchorCaret {
+				anchor, anchorCaret = i, primary
+				offset = e.scrollOff.Y - top
+			}
+		}
+		y = top + p.bottom
+		first, _ := slices.BinarySearch(carets, runes)
+		hasCaret := first < len(carets) && carets[first] < endRunes
+		if !p.shaped && (minY < hi && maxY > lo || hasCaret) {
+			e.shapeParagraph(lt, p, off)
+			e.laidOut = min(e.laidOut, i)
+		}
+		off += p.bytes
+		runes += p.runes
+	}
+	return anchor, offset
+}
+
+// caretRunes appends the rune offsets of the ends of every caret and
+// selection to dst.
+func (e *textView) caretRunes(dst []int) []int {
+	dst = append(dst, e.caret.start, e.caret.end)
+	for _, c := range e.carets {
+		dst = append(dst, c.start, c.end)
+	}
+	return dst
+}
+
+// caretsShaped reports whether the paragraphs of every caret are shaped.
+func (e *textView) caretsShaped() bool {
+	for _, r := range e.caretRunes(nil) {
+		i := sort.Search(len(e.paragraphs), func(i int) bool {
+			return e.paragraphs[i].end.runes > r
+		})
+		i = min(i, len(e.paragraphs)-1)
+		if !e.paragraphs[i].shaped {
+			return false
+		}
+	}
+	return true
+}
+
+// estimateParagraphs shapes a digit with params to estimate the layout
+// of paragraphs.
+func estimateParagraphs(lt *text.Shaper, params text.Parameters) paragraphEstimate {
+	lt.LayoutString(params, "\n0")
+	sentinel, _ := lt.NextGlyph()
+	g, _ := lt.NextGlyph()
+	return paragraphEstimate{glyph: g, lead: int(g.Y - sentinel.Y)}
+}
+
+// estimateParagraph estimates the layout of the paragraph p starting at
+// the byte offset off by laying out every rune as the estimate glyph.
+// Like shapeParagraph, every line ends with a glyph with the line break
+// flags, and a trailing newline is a glyph of its own.
+func (e *textView) estimateParagraph(p *paragraphLayout, off int) {
+	var last [1]byte
+	if n, _ := e.rr.ReadAt(last[:], int64(off+p.bytes-1)); n == 1 {
+		p.trailing = last[0] == '\n'
+	}
+	est := e.estimate
+	content := p.runes
+	if p.trailing {
+		content--
+	}
+	perLine := content
+	if adv := int64(est.glyph.Advance); adv > 0 && e.params.MaxWidth != math.MaxInt {
+		perLine = max(int(int64(e.params.MaxWidth)*64/adv), 1)
+	}
+	p.index.reset()
+	p.top, p.lead, p.bottom = est.glyph.Ascent.Ceil(), est.lead, 0
+	add := func(g text.Glyph) {
+		if len(p.index.glyphs) == 0 {
+			p.bounds = glyphBounds(g)
+		} else {
+			p.bounds = extend(p.bounds, glyphBounds(g))
+		}
+		p.index.Glyph(g)
+	}
+	var x fixed.Int26_6
+	line, col := 0, 0
+	for r := 0; r < content; {
+		n := min(min(content-r, perLine-col), math.MaxUint16)
+		g := est.glyph
+		g.X = x
+		g.Y = int32(line * est.lead)
+		g.Advance = fixed.Int26_6(n) * est.glyph.Advance
+		g.Runes = uint16(n)
+		g.Flags = text.FlagClusterBreak
+		if r == 0 {
+			g.Flags |= text.FlagParagraphStart
+		}
+		r += n
+		col += n
+		x += g.Advance
+		wrap := col == perLine && r < content
+		if wrap || r == content && !p.trailing {
+			g.Flags |= text.FlagLineBreak | text.FlagRunBreak
+		}
+		add(g)
+		if wrap {
+			line++
+			col = 0
+			x = e.indent
+		}
+	}
+	if p.trailing {
+		g := est.glyph
+		g.X = x
+		g.Y = int32(line * est.lead)
+		g.Advance = 0
+		g.Runes = 1
+		g.Flags = text.FlagParagraphBreak | text.FlagLineBreak | text.FlagRunBreak | text.FlagClusterBreak
+		if content == 0 {
+			g.Flags |= text.FlagParagraphStart
+		}
+		add(g)
+		g.X = 0
+		g.Y = int32((line + 1) * est.lead)
+		g.Runes = 0
+		g.Flags = text.FlagParagraphStart | text.FlagLineBreak | text.FlagRunBreak | text.FlagClusterBreak
+		p.trailer = g
+	}
+	p.bottom = line * est.lead
+	p.estimated = true
+}
+
+// shapeParagraph shapes and indexes the paragraph p starting at the byte
+// offset off.
+func (e *textView) shapeParagraph(lt *text.Shaper, p *paragraphLayout, off int) {
+	// Shape the paragraph after an empty one to learn the distance
+	// between its first baseline and the last baseline of a preceding
+	// paragraph.
+	buf := make([]byte, p.bytes+1)
+	buf[0] = '\n'
+	n, _ := e.rr.ReadAt(buf[1:], int64(off))
+	buf = buf[:n+1]
+	p.trailing = buf[len(buf)-1] == '\n'
+	p.top, p.lead, p.bottom = 0, 0, 0
+	p.bounds = image.Rectangle{}
+	p.index.reset()
+	lt.LayoutString(e.params, string(buf))
+	w := e.wrapIndenter()
+	sentinel, _ := lt.NextGlyph()
+	w.indentGlyph(sentinel)
+	var firstY int32
+	for g, ok := lt.NextGlyph(); ok; g, ok = lt.NextGlyph() {
+		g = w.indentGlyph(g)
+		if len(p.index.glyphs) == 0 {
+			firstY = g.Y
+			p.top = g.Ascent.Ceil()
+			p.lead = int(g.Y - sentinel.Y)
+		}
+		g.Y -= firstY
+		if p.trailing && g.Flags&text.FlagParagraphStart != 0 && g.Runes == 0 {
+			p.trailer = g
+			break
+		}
+		if len(p.index.glyphs) == 0 {
+			p.bounds = glyphBounds(g)
+		} else {
+			p.bounds = extend(p.bounds, glyphBounds(g))
+		}
+		p.bottom = int(g.Y)
+		p.index.Glyph(g)
+	}
+	p.shaped = true
+}
+
+// glyphBounds returns the logical bounds of g, as computed by
+// textIterator.
+func glyphBounds(g text.Glyph) image.Rectangle {
+	return image.Rectangle{
+		Min: image.Pt(g.X.Floor(), int(g.Y)-g.Ascent.Ceil()),
+		Max: image.Pt((g.X + g.Advance).Ceil(), int(g.Y)+g.Descent.Ceil()),
+	}
+}
+
+// invalidateParagraphs updates the cached paragraphs after the bytes
+// [start, end) of the text were replaced, changing its size by delta
+// bytes. The paragraphs touching the replaced bytes are split anew and
+// marked for reshaping.
+func (e *textView) invalidateParagraphs(start, end, delta int) {
+	if len(e.paragraphs) == 0 {
+		return
+	}
+	first, last := -1, len(e.paragraphs)-1
+	var firstOff, off int
+	for i, p := range e.paragraphs {
+		next := off + p.bytes
+		if first == -1 && start < next {
+			first, firstOff = i, off
+		}
+		if end < next {
+			last = i
+			break
+		}
+		off = next
+	}
+	if first == -1 {
+		// The edit is at the end of the text.
+		first = len(e.paragraphs) - 1
+		firstOff = off - e.paragraphs[first].bytes
+	}
+	lastEnd := firstOff
+	for _, p := range e.paragraphs[first : last+1] {
+		lastEnd += p.bytes
+	}
+	size := lastEnd + delta - firstOff
+	buf := make([]byte, size)
+	n, _ := io.ReadFull(io.NewSectionReader(e.rr, int64(firstOff), int64(size)), buf)
+	e.laidOut = min(e.laidOut, first)
+	e.paragraphs = slices.Delete(e.paragraphs, first, last+1)
+	e.paragraphs = slices.Insert(e.paragraphs, first, e.splitParagraphs(nil, buf[:n])...)
+}
+
+// extend returns the smallest rectangle containing both r and s. Unlike
+// image.Rectangle.Union it doesn't ignore empty rectangles, because the
+// bounds of zero width glyphs such as newlines still count.
+func extend(r, s image.Rectangle) image.Rectangle {
+	r.Min.X = min(r.Min.X, s.Min.X)
+	r.Min.Y = min(r.Min.Y, s.Min.Y)
+	r.Max.X = max(r.Max.X, s.Max.X)
+	r.Max.Y = max(r.Max.Y, s.Max.Y)
+	return r
+}
+
+// splitParagraphs appends the paragraphs of b, and their grapheme
+// cluster boundaries, to dst.
+func (e *textView) splitParagraphs(dst []paragraphLayout, b []byte) []paragraphLayout {
+	for len(b) > 0 {
+		n := bytes.IndexByte(b, '\n') + 1
+		if n == 0 {
+			n = len(b)
+		}
+		p := paragraphLayout{bytes: n, runes: utf8.RuneCount(b[:n])}
+		if p.runes == n {
+			p.graphemes = asciiGraphemes(b[:n])
+		} else {
+			e.paragraphReader.SetSource(bytes.NewReader(b[:n]))
+			p.graphemes = append([]int(nil), e.paragraphReader.Graphemes()...)
+		}
+		dst = append(dst, p)
+		b = b[n:]
+	}
+	return dst
+}
+
+// asciiGraphemes returns the grapheme cluster boundaries of the ASCII
+// text b, where every character except the line feed of a CR LF pair
+// is a cluster of its own.
+func asciiGraphemes(b []byte) []int {
+	g := make([]int, 0, len(b)+1)
+	g = append(g, 0)
+	for i := range b {
+		if b[i] == '\r' && i+1 < len(b) && b[i+1] == '\n' {
+			continue
+		}
+		g = append(g, i+1)
+	}
+	return g
+}
@@ -0,0 +1,216 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package widget
+
+import (
+	"image"
+	"math"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Seikaijyu/gio/font"
+	"github.com/Seikaijyu/gio/font/gofont"
+	"github.com/Seikaijyu/gio/layout"
+	"github.com/Seikaijyu/gio/op"
+	"github.com/Seikaijyu/gio/text"
+)
+
+// TestIncrementalLayout verifies that laying out a text by paragraph
+// matches shaping it as a whole while it is edited.
+func TestIncrementalLayout(t *testing.T) {
+	defer func(size int64) {
+		incrementalLayoutSize = size
+	}(incrementalLayoutSize)
+	lt := text.NewShaper(text.NoSystemFonts(), text.WithCollection(gofont.Collection()))
+	gtx := layout.Context{
+		Ops: new(op.Ops),
+		// A viewport taller than the text, so that every paragraph is
+		// shaped.
+		Constraints: layout.Exact(image.Pt(200, 1<<20)),
+	}
+	layoutView := func(v *textView, incremental bool) {
+		incrementalLayoutSize = math.MaxInt64
+		if incremental {
+			incrementalLayoutSize = 0
+		}
+		v.Layout(gtx, lt, font.Font{}, 14)
+	}
+	paragraphs := []string{
+		"Short line.\n",
+		"Control \t characters and a CR LF pair.\r\n",
+		"\n",
+		"A paragraph long enough to wrap across several lines of the narrow view.\n",
+		"Mixed scripts: héllo 世界 and עברית with bidi runs.\n",
+		"Emoji 👍🏽 and combining é clusters.\n",
+	}
+	var sb strings.Builder
+	for i := 0; i < 40; i++ {
+		sb.WriteString(paragraphs[i%len(paragraphs)])
+	}
+	buf := new(editBuffer)
+	buf.ReplaceRunes(0, 0, sb.String())
+	v := new(textView)
+	v.SetSource(buf)
+	layoutView(v, true)
+
+	r := rand.New(rand.NewSource(1))
+	inserts := []string{"", "x", "\n", "new\nparagraphs\n", " wrapping words", "世"}
+	for i := 0; i < 60; i++ {
+		n := v.Len()
+		start := r.Intn(n + 1)
+		end := start + r.Intn(min(n-start, 20)+1)
+		v.Replace(start, end, inserts[r.Intn(len(inserts))])
+		if i == 30 {
+			// Delete the end of the text, including its last
+			// paragraphs.
+			v.Replace(v.Len()/2, v.Len(), "")
+		}
+		layoutView(v, true)
+		if len(v.paragraphs) == 0 {
+			t.Fatal("text not laid out by paragraph")
+		}
+
+		ref := new(textView)
+		ref.SetSource(newStringSource(string(v.Text(nil))))
+		layoutView(ref, false)
+		if !reflect.DeepEqual(v.index.glyphs, ref.index.glyphs) {
+			t.Fatalf("edit %d: glyphs differ from a full layout", i)
+		}
+		if !reflect.DeepEqual(v.index.positions, ref.index.positions) {
+			t.Fatalf("edit %d: positions differ from a full layout", i)
+		}
+		if !reflect.DeepEqual(v.index.lines, ref.index.lines) {
+			t.Fatalf("edit %d: lines differ from a full layout", i)
+		}
+		if !reflect.DeepEqual(v.graphemes, ref.graphemes) {
+			t.Fatalf("edit %d: graphemes differ from a full layout", i)
+		}
+		if v.dims != ref.dims {
+			t.Fatalf("edit %d: dimensions %v, want %v", i, v.dims, ref.dims)
+		}
+	}
+}
+
+// TestLazyLayout verifies that only the paragraphs near the viewport and
+// the carets are shaped, and that the viewport keeps its content in place
+// when estimated paragraphs are shaped.
+func TestLazyLayout(t *testing.T) {
+	defer func(size int64) {
+		incrementalLayoutSize = size
+	}(incrementalLayoutSize)
+	incrementalLayoutSize = 0
+	lt := text.NewShaper(text.NoSystemFonts(), text.WithCollection(gofont.Collection()))
+	gtx := layout.Context{
+		Ops:         new(op.Ops),
+		Constraints: layout.Exact(image.Pt(200, 100)),
+	}
+	src := largeText(64 << 10)
+	buf := new(editBuffer)
+	buf.ReplaceRunes(0, 0, src)
+	v := new(textView)
+	v.SetSource(buf)
+	v.Layout(gtx, lt, font.Font{}, 14)
+	// checkShaped verifies that the paragraphs intersecting the viewport
+	// are shaped, and that at most a few are.
+	checkShaped := func(name string) {
+		t.Helper()
+		shaped := 0
+		for i, p := range v.paragraphs {
+			if p.shaped {
+				shaped++
+			}
+			visible := p.y+p.bounds.Max.Y > v.scrollOff.Y && p.y+p.bounds.Min.Y < v.scrollOff.Y+v.viewSize.Y
+			if visible && !p.shaped {
+				t.Errorf("%s: visible paragraph %d is not shaped", name, i)
+			}
+		}
+		if shaped == 0 || shaped > len(v.paragraphs)/10 {
+			t.Errorf("%s: %d of %d paragraphs shaped", name, shaped, len(v.paragraphs))
+		}
+	}
+	checkShaped("first layout")
+	if h := v.FullDimensions().Size.Y; h < 100*len(v.paragraphs) {
+		t.Errorf("estimated height %d for %d wrapped paragraphs", h, len(v.paragraphs))
+	}
+	// The leading paragraphs match a full layout.
+	ref := new(textView)
+	ref.SetSource(newStringSource(src))
+	incrementalLayoutSize = math.MaxInt64
+	ref.Layout(gtx, lt, font.Font{}, 14)
+	incrementalLayoutSize = 0
+	n := v.paragraphs[0].end.glyphs
+	if !reflect.DeepEqual(v.index.glyphs[:n], ref.index.glyphs[:n]) {
+		t.Error("glyphs of the first paragraph differ from a full layout")
+	}
+	if !reflect.DeepEqual(v.graphemes, ref.graphemes) {
+		t.Error("graphemes differ from a full layout")
+	}
+
+	// Scrolling shapes the paragraphs scrolled into view.
+	v.ScrollRel(0, v.FullDimensions().Size.Y/2)
+	checkShaped("scrolled")
+
+	// Moving the caret shapes its paragraph, and scrolling to it keeps it
+	// in view.
+	v.SetCaret(v.Len(), v.Len())
+	v.ScrollToCaret()
+	checkShaped("caret at the end")
+	if c := v.CaretCoords(); c.Y < 0 || c.Y > float32(v.viewSize.Y) {
+		t.Errorf("caret at %v outside the viewport", c)
+	}
+	if p := v.paragraphs[len(v.paragraphs)-1]; !p.shaped {
+		t.Error("paragraph of the caret is not shaped")
+	}
+
+	// Edits above the viewport don't move its content.
+	v.scrollAbs(0, v.FullDimensions().Size.Y/4)
+	before := v.CaretCoords()
+	v.SetCaret(0, 0)
+	v.Replace(0, 0, strings.Repeat("x\n", 10))
+	v.SetCaret(v.Len(), v.Len())
+	v.makeValid()
+	if after := v.CaretCoords(); after != before {
+		t.Errorf("caret moved from %v to %v after an edit above the viewport", before, after)
+	}
+}
+
+// BenchmarkEditorLayoutLarge measures the first layout of a large
+// document.
+func BenchmarkEditorLayoutLarge(b *testing.B) {
+	lt := text.NewShaper(text.NoSystemFonts(), text.WithCollection(gofont.Collection()))
+	gtx := layout.Context{
+		Ops:         new(op.Ops),
+		Constraints: layout.Exact(image.Pt(800, 600)),
+	}
+	txt := largeText(2 << 20)
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		gtx.Ops.Reset()
+		e := new(Editor)
+		e.SetText(txt)
+		b.StartTimer()
+		e.Layout(gtx, lt, font.Font{}, 14, op.CallOp{}, op.CallOp{})
+	}
+}
+
+// BenchmarkEditorInsertLarge measures the latency of typing into a large
+// document, including the layout that follows each edit.
+func BenchmarkEditorInsertLarge(b *testing.B) {
+	lt := text.NewShaper(text.NoSystemFonts(), text.WithCollection(gofont.Collection()))
+	gtx := layout.Context{
+		Ops:         new(op.Ops),
+		Constraints: layout.Exact(image.Pt(800, 600)),
+	}
+	e := new(Editor)
+	e.SetText(largeText(2 << 20))
+	e.Layout(gtx, lt, font.Font{}, 14, op.CallOp{}, op.CallOp{})
+	e.SetCaret(e.Len()/2, e.Len()/2)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		gtx.Ops.Reset()
+		e.Insert("a")
+		e.Layout(gtx, lt, font.Font{}, 14, op.CallOp{}, op.CallOp{})
+	}
+}
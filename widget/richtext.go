@@ -0,0 +1,371 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package widget
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Seikaijyu/gio/f32"
+	"github.com/Seikaijyu/gio/font"
+	"github.com/Seikaijyu/gio/gesture"
+	"github.com/Seikaijyu/gio/io/key"
+	"github.com/Seikaijyu/gio/io/pointer"
+	"github.com/Seikaijyu/gio/io/semantic"
+	"github.com/Seikaijyu/gio/io/system"
+	"github.com/Seikaijyu/gio/layout"
+	"github.com/Seikaijyu/gio/op"
+	"github.com/Seikaijyu/gio/op/clip"
+	"github.com/Seikaijyu/gio/op/paint"
+	"github.com/Seikaijyu/gio/text"
+	"github.com/Seikaijyu/gio/unit"
+	"golang.org/x/image/math/fixed"
+)
+
+// RichText lays out spans of differently styled text, links and inline
+// widgets, flowing them into lines together. RichText keeps the state of
+// its links, and reports their interactions as LinkEvents.
+type RichText struct {
+	// Alignment specifies the alignment of the lines.
+	Alignment text.Alignment
+	// WrapPolicy configures how the text of spans is broken into lines.
+	WrapPolicy text.WrapPolicy
+
+	links []richLink
+	// glyphs holds the glyphs of the pieces of the last layout.
+	glyphs []text.Glyph
+	pieces []richPiece
+	lines  []richLine
+}
+
+// RichSpan is a span of RichText.
+type RichSpan struct {
+	// Content is the text of the span.
+	Content string
+	// Font of the text.
+	Font font.Font
+	// Size of the text.
+	Size unit.Sp
+	// Color of the text, and of its underline.
+	Color color.NRGBA
+	// Underline the text.
+	Underline bool
+	// Link, if set, makes the span a link to the URL. Interactions with
+	// links are reported by RichText.Update.
+	Link string
+	// Object, if set, is laid out in place of Content as a single
+	// unbreakable piece, aligned to the baseline of the line.
+	Object layout.Widget
+}
+
+// LinkEvent describes an interaction with a link of a RichText.
+type LinkEvent struct {
+	Kind LinkEventKind
+	// Span is the index of the span of the link.
+	Span int
+	// URL of the link.
+	URL string
+	// Modifiers of a click.
+	Modifiers key.Modifiers
+}
+
+// LinkEventKind is the kind of a LinkEvent.
+type LinkEventKind uint8
+
+const (
+	// LinkClick is a click of a link.
+	LinkClick LinkEventKind = iota
+	// LinkHover is the start of a pointer hovering a link.
+	LinkHover
+	// LinkUnhover is the end of a pointer hovering a link.
+	LinkUnhover
+)
+
+// richLink is the state of a link span.
+type richLink struct {
+	click gesture.Click
+	url   string
+	// hovered is the hover state last reported.
+	hovered bool
+}
+
+// richPiece is the part of a span in a line.
+type richPiece struct {
+	span int
+	// glyphs are the bounds of the glyphs of a text piece in
+	// RichText.glyphs.
+	glyphs [2]int
+	// call lays out an object piece.
+	call op.CallOp
+	// origin is the x coordinate of the left edge of the piece in the
+	// coordinates of its glyphs, and x its offset in the line.
+	origin, x int
+	// width is the advance of the piece, and ink its width excluding
+	// trailing whitespace.
+	width, ink      int
+	ascent, descent int
+}
+
+// richLine is a line of RichText.
+type richLine struct {
+	// pieces are the bounds of the pieces of the line in
+	// RichText.pieces.
+	pieces          [2]int
+	width           int
+	ascent, descent int
+}
+
+func (l LinkEventKind) String() string {
+	switch l {
+	case LinkClick:
+		return "Click"
+	case LinkHover:
+		return "Hover"
+	case LinkUnhover:
+		return "Unhover"
+	default:
+		panic("invalid LinkEventKind")
+	}
+}
+
+// Hovered reports whether a pointer hovers the link of span.
+func (r *RichText) Hovered(span int) bool {
+	return span < len(r.links) && r.links[span].click.Hovered()
+}
+
+// Update the state of the links, and return their events, if any.
+func (r *RichText) Update(gtx layout.Context) []LinkEvent {
+	var events []LinkEvent
+	for i := range r.links {
+		l := &r.links[i]
+		if l.url == "" {
+			continue
+		}
+		for _, e := range l.click.Update(gtx) {
+			if e.Kind == gesture.KindClick {
+				events = append(events, LinkEvent{Kind: LinkClick, Span: i, URL: l.url, Modifiers: e.Modifiers})
+			}
+		}
+		if h := l.click.Hovered(); h != l.hovered {
+			l.hovered = h
+			kind := LinkUnhover
+			if h {
+				kind = LinkHover
+			}
+			events = append(events, LinkEvent{Kind: kind, Span: i, URL: l.url})
+		}
+	}
+	return events
+}
+
+// Layout the spans with the given shaper.
+func (r *RichText) Layout(gtx layout.Context, lt *text.Shaper, spans ...RichSpan) layout.Dimensions {
+	r.Update(gtx)
+	if n := len(spans); len(r.links) < n {
+		r.links = append(r.links, make([]richLink, n-len(r.links))...)
+	}
+	for i := range r.links {
+		r.links[i].url = ""
+		if i < len(spans) {
+			r.links[i].url = spans[i].Link
+		}
+	}
+	r.flow(gtx, lt, spans)
+
+	width := gtx.Constraints.Min.X
+	height := 0
+	for _, l := range r.lines {
+		width = max(width, l.width)
+		height += l.ascent + l.descent
+	}
+	dims := layout.Dimensions{Size: gtx.Constraints.Constrain(image.Pt(width, height))}
+	defer clip.Rect{Max: dims.Size}.Push(gtx.Ops).Pop()
+	var label strings.Builder
+	for _, s := range spans {
+		label.WriteString(s.Content)
+	}
+	semantic.LabelOp(label.String()).Add(gtx.Ops)
+	y := 0
+	for i, l := range r.lines {
+		baseline := y + l.ascent
+		if i == 0 {
+			dims.Baseline = dims.Size.Y - baseline
+		}
+		x := 0
+		switch r.Alignment {
+		case text.Middle:
+			x = (dims.Size.X - l.width) / 2
+		case text.End:
+			x = dims.Size.X - l.width
+		}
+		if gtx.Locale.Direction.Progression() == system.TowardOrigin {
+			// The alignment is relative to the direction.
+			x = dims.Size.X - l.width - x
+		}
+		for _, p := range r.pieces[l.pieces[0]:l.pieces[1]] {
+			r.paintPiece(gtx, lt, spans[p.span], p, image.Pt(x+p.x, baseline))
+		}
+		y = baseline + l.descent
+	}
+	return dims
+}
+
+// paintPiece paints p of span s with its left edge at the x coordinate of
+// pos, and its baseline at the y coordinate.
+func (r *RichText) paintPiece(gtx layout.Context, lt *text.Shaper, s RichSpan, p richPiece, pos image.Point) {
+	bounds := image.Rect(pos.X, pos.Y-p.ascent, pos.X+p.width, pos.Y+p.descent)
+	if p.call != (op.CallOp{}) {
+		t := op.Offset(bounds.Min).Push(gtx.Ops)
+		p.call.Add(gtx.Ops)
+		t.Pop()
+	} else if gs := r.glyphs[p.glyphs[0]:p.glyphs[1]]; len(gs) > 0 {
+		off := f32.Pt(float32(pos.X-p.origin)+fixedToFloat(gs[0].X), float32(pos.Y))
+		t := op.Affine(f32.Affine2D{}.Offset(off)).Push(gtx.Ops)
+		paint.FillShape(gtx.Ops, s.Color, clip.Outline{Path: lt.Shape(gs)}.Op())
+		if call := lt.Bitmaps(gs); call != (op.CallOp{}) {
+			call.Add(gtx.Ops)
+		}
+		t.Pop()
+		if s.Underline && p.ink > 0 {
+			thick := max(1, gtx.Sp(s.Size)/16)
+			top := pos.Y + max(1, p.descent/3)
+			line := image.Rect(pos.X, top, pos.X+p.ink, top+thick)
+			paint.FillShape(gtx.Ops, s.Color, clip.Rect(line).Op())
+		}
+	}
+	if s.Link != "" {
+		area := clip.Rect(bounds).Push(gtx.Ops)
+		semantic.LabelOp(s.Content).Add(gtx.Ops)
+		pointer.CursorPointer.Add(gtx.Ops)
+		r.links[p.span].click.Add(gtx.Ops)
+		area.Pop()
+	}
+}
+
+// flow breaks the spans into pieces and the pieces into lines.
+func (r *RichText) flow(gtx layout.Context, lt *text.Shaper, spans []RichSpan) {
+	r.glyphs = r.glyphs[:0]
+	r.pieces = r.pieces[:0]
+	r.lines = r.lines[:0]
+	maxWidth := gtx.Constraints.Max.X
+	var line richLine
+	// wrap ends the current line.
+	wrap := func() {
+		line.pieces[1] = len(r.pieces)
+		r.lines = append(r.lines, line)
+		line = richLine{pieces: [2]int{len(r.pieces), len(r.pieces)}}
+	}
+	add := func(p richPiece) {
+		p.x = line.width
+		line.width += p.width
+		line.ascent = max(line.ascent, p.ascent)
+		line.descent = max(line.descent, p.descent)
+		r.pieces = append(r.pieces, p)
+	}
+	for i, s := range spans {
+		if s.Object != nil {
+			m := op.Record(gtx.Ops)
+			ogtx := gtx
+			ogtx.Constraints = layout.Constraints{Max: gtx.Constraints.Max}
+			dims := s.Object(ogtx)
+			p := richPiece{
+				span:    i,
+				call:    m.Stop(),
+				width:   dims.Size.X,
+				ink:     dims.Size.X,
+				ascent:  dims.Size.Y - dims.Baseline,
+				descent: dims.Baseline,
+			}
+			if len(r.pieces) > line.pieces[0] && line.width+p.width > maxWidth {
+				wrap()
+			}
+			add(p)
+			continue
+		}
+		txt := s.Content
+		for txt != "" {
+			avail := maxWidth - line.width
+			policy := r.WrapPolicy
+			if len(r.pieces) > line.pieces[0] {
+				// Don't break words to fill the rest of a line.
+				policy = text.WrapWords
+			}
+			lt.LayoutString(text.Parameters{
+				Font:       s.Font,
+				PxPerEm:    fixed.I(gtx.Sp(s.Size)),
+				WrapPolicy: policy,
+				MaxWidth:   max(avail, 0),
+				Locale:     gtx.Locale,
+			}, txt)
+			p, runes, paragraph := r.firstLine(lt)
+			p.span = i
+			if len(r.pieces) > line.pieces[0] && p.ink > avail {
+				// Not even a word fits; move to a new line.
+				r.glyphs = r.glyphs[:p.glyphs[0]]
+				wrap()
+				continue
+			}
+			add(p)
+			if runes == 0 {
+				break
+			}
+			for ; runes > 0 && txt != ""; runes-- {
+				_, n := utf8.DecodeRuneInString(txt)
+				txt = txt[n:]
+			}
+			if txt != "" || paragraph {
+				wrap()
+				if paragraph {
+					// An empty line after a newline keeps the height of
+					// its text.
+					line.ascent, line.descent = p.ascent, p.descent
+				}
+			}
+		}
+	}
+	if len(r.pieces) > line.pieces[0] || line.ascent+line.descent > 0 {
+		wrap()
+	}
+}
+
+// firstLine adds the glyphs of the first line laid out by lt to
+// r.glyphs, and returns them as a piece, the number of runes of the line,
+// and whether the line ends in a paragraph break.
+func (r *RichText) firstLine(lt *text.Shaper) (p richPiece, runes int, paragraph bool) {
+	p.glyphs[0] = len(r.glyphs)
+	first := true
+	var right, ink fixed.Int26_6
+	for g, ok := lt.NextGlyph(); ok; g, ok = lt.NextGlyph() {
+		left, end := g.X, g.X+g.Advance
+		if first {
+			p.origin = left.Floor()
+			right = end
+			first = false
+		}
+		p.origin = min(p.origin, left.Floor())
+		if end > right {
+			right = end
+		}
+		if !g.Bounds.Empty() && end > ink {
+			ink = end
+		}
+		p.ascent = max(p.ascent, g.Ascent.Ceil())
+		p.descent = max(p.descent, g.Descent.Ceil())
+		if g.Flags&text.FlagClusterBreak != 0 {
+			runes += int(g.Runes)
+		}
+		r.glyphs = append(r.glyphs, g)
+		if g.Flags&text.FlagLineBreak != 0 {
+			paragraph = g.Flags&text.FlagParagraphBreak != 0
+			break
+		}
+	}
+	p.glyphs[1] = len(r.glyphs)
+	if !first {
+		p.width = right.Ceil() - p.origin
+		p.ink = max(ink.Ceil()-p.origin, 0)
+	}
+	return p, runes, paragraph
+}
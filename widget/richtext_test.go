@@ -0,0 +1,117 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package widget
+
+import (
+	"image"
+	"testing"
+
+	"github.com/Seikaijyu/gio/f32"
+	"github.com/Seikaijyu/gio/font/gofont"
+	"github.com/Seikaijyu/gio/io/pointer"
+	"github.com/Seikaijyu/gio/io/router"
+	"github.com/Seikaijyu/gio/io/system"
+	"github.com/Seikaijyu/gio/layout"
+	"github.com/Seikaijyu/gio/op"
+	"github.com/Seikaijyu/gio/text"
+)
+
+func TestRichTextFlow(t *testing.T) {
+	lt := text.NewShaper(text.NoSystemFonts(), text.WithCollection(gofont.Collection()))
+	gtx := layout.Context{
+		Ops:         new(op.Ops),
+		Constraints: layout.Constraints{Max: image.Pt(1000, 1000)},
+	}
+	object := func(gtx layout.Context) layout.Dimensions {
+		return layout.Dimensions{Size: image.Pt(30, 40), Baseline: 10}
+	}
+	spans := []RichSpan{
+		{Content: "Small text, ", Size: 10},
+		{Content: "large text", Size: 20},
+		{Object: object},
+		{Content: " and more.", Size: 10},
+	}
+	var r RichText
+	dims := r.Layout(gtx, lt, spans...)
+	if n := len(r.lines); n != 1 {
+		t.Fatalf("wide layout has %d lines, want 1", n)
+	}
+	if l := r.lines[0]; l.ascent != 30 || dims.Size.Y != l.ascent+l.descent {
+		t.Errorf("line of ascent %d and height %d, want ascent of object", l.ascent, dims.Size.Y)
+	}
+	for i, p := range r.pieces[1:] {
+		prev := r.pieces[i]
+		if p.x != prev.x+prev.width {
+			t.Errorf("piece %d at %d, want %d", i+1, p.x, prev.x+prev.width)
+		}
+	}
+	wide := dims.Size.X
+
+	// Narrow the text to break within spans, at word boundaries.
+	gtx.Constraints.Max.X = wide / 2
+	dims = r.Layout(gtx, lt, spans...)
+	if len(r.lines) < 2 {
+		t.Fatalf("narrow layout has %d lines, want several", len(r.lines))
+	}
+	if dims.Size.X > gtx.Constraints.Max.X {
+		t.Errorf("narrow layout width %d exceeds %d", dims.Size.X, gtx.Constraints.Max.X)
+	}
+	var runes int
+	for _, g := range r.glyphs {
+		if g.Flags&text.FlagClusterBreak != 0 {
+			runes += int(g.Runes)
+		}
+	}
+	if want := len("Small text, large text and more."); runes != want {
+		t.Errorf("layout covers %d runes, want %d", runes, want)
+	}
+
+	// Newlines break lines.
+	gtx.Constraints.Max.X = 1000
+	r.Layout(gtx, lt, RichSpan{Content: "one\ntwo\n", Size: 10})
+	if n := len(r.lines); n != 3 {
+		t.Errorf("text with 2 newlines has %d lines, want 3", n)
+	}
+}
+
+func TestRichTextLinks(t *testing.T) {
+	lt := text.NewShaper(text.NoSystemFonts(), text.WithCollection(gofont.Collection()))
+	var (
+		ops op.Ops
+		rt  router.Router
+		r   RichText
+	)
+	gtx := layout.NewContext(&ops, system.FrameEvent{Queue: &rt, Size: image.Pt(1000, 1000)})
+	gtx.Constraints.Min = image.Point{}
+	spans := []RichSpan{
+		{Content: "Go to ", Size: 20},
+		{Content: "the site", Size: 20, Link: "https://example.com"},
+	}
+	var events []LinkEvent
+	frame := func() {
+		events = append(events, r.Update(gtx)...)
+		ops.Reset()
+		r.Layout(gtx, lt, spans...)
+		rt.Frame(gtx.Ops)
+	}
+	frame()
+	link := r.pieces[1]
+	pos := f32.Pt(float32(link.x+link.width/2), 10)
+	rt.Queue(
+		pointer.Event{Kind: pointer.Move, Source: pointer.Mouse, Position: pos},
+		pointer.Event{Kind: pointer.Press, Source: pointer.Mouse, Buttons: pointer.ButtonPrimary, Position: pos},
+		pointer.Event{Kind: pointer.Release, Source: pointer.Mouse, Position: pos},
+	)
+	frame()
+	rt.Queue(pointer.Event{Kind: pointer.Move, Source: pointer.Mouse, Position: f32.Pt(1, 10)})
+	frame()
+	want := []LinkEventKind{LinkClick, LinkHover, LinkUnhover}
+	if len(events) != len(want) {
+		t.Fatalf("got events %v, want kinds %v", events, want)
+	}
+	for i, e := range events {
+		if e.Kind != want[i] || e.Span != 1 || e.URL != "https://example.com" {
+			t.Errorf("event %d is %v, want %v of span 1", i, e, want[i])
+		}
+	}
+}
@@ -66,8 +66,15 @@ type Selectable struct {
 	// LineHeightScale applies a scaling factor to the LineHeight. If zero, a
 	// sensible default will be used.
 	LineHeightScale float32
-	initialized     bool
-	source          stringSource
+	// TouchSelection configures the handles and the loupe for adjusting
+	// selections by touch.
+	TouchSelection TouchSelection
+	// KeepSelection paints the selection even when the label is not
+	// focused, such as for highlighting text selected by SetSelection.
+	KeepSelection bool
+
+	initialized bool
+	source      stringSource
 	// scratch is a buffer reused to efficiently read text out of the
 	// textView.
 	scratch      []byte
@@ -81,6 +88,10 @@ type Selectable struct {
 	scrollOff    image.Point
 
 	clicker gesture.Click
+	// clicks is the number of clicks of the last multi-click.
+	clicks int
+	// handles adjust selections made by touch.
+	handles selectionHandles
 	// events is the list of events not yet processed.
 	events []EditorEvent
 	// prevEvents is the number of events from the previous frame.
@@ -111,7 +122,7 @@ func (l *Selectable) Focused() bool {
 // paintSelection paints the contrasting background for selected text.
 func (l *Selectable) paintSelection(gtx layout.Context, material op.CallOp) {
 	l.initialize()
-	if !l.focused {
+	if !l.focused && !l.KeepSelection {
 		return
 	}
 	l.text.PaintSelection(gtx, material)
@@ -144,6 +155,24 @@ func (l *Selectable) SetCaret(start, end int) {
 	l.text.SetCaret(start, end)
 }
 
+// SetSelection sets the selection such that Selection returns start and
+// end, with the caret at start. Unlike SetCaret, it generates a
+// SelectEvent if the selection changes.
+func (l *Selectable) SetSelection(start, end int) {
+	l.initialize()
+	oldStart, oldLen := min(l.text.Selection()), l.text.SelectionLen()
+	l.text.SetCaret(start, end)
+	if newStart, newLen := min(l.text.Selection()), l.text.SelectionLen(); oldStart != newStart || oldLen != newLen {
+		l.events = append(l.events, SelectEvent{Programmatic: true})
+	}
+}
+
+// SelectAll selects all of the text, like SetSelection.
+func (l *Selectable) SelectAll() {
+	l.initialize()
+	l.SetSelection(l.text.Len(), 0)
+}
+
 // SelectedText returns the currently selected text (if any) from the editor.
 func (l *Selectable) SelectedText() string {
 	l.initialize()
@@ -217,9 +246,13 @@ func (l *Selectable) Layout(gtx layout.Context, lt *text.Shaper, font font.Font,
 
 	l.clicker.Add(gtx.Ops)
 	l.dragger.Add(gtx.Ops)
+	l.handles.longPress.Add(gtx.Ops)
 
 	l.paintSelection(gtx, selectionMaterial)
 	l.paintText(gtx, textMaterial)
+	if l.focused {
+		l.handles.layout(gtx, &l.text, l.TouchSelection, textMaterial, selectionMaterial)
+	}
 	return dims
 }
 
@@ -229,15 +262,17 @@ func (l *Selectable) handleEvents(gtx layout.Context) {
 	l.events = l.events[:n]
 	l.prevEvents = n
 	oldStart, oldLen := min(l.text.Selection()), l.text.SelectionLen()
+	l.clicks = 0
 	l.processPointer(gtx)
 	l.processKey(gtx)
 	// Queue a SelectEvent if the selection changed, including if it went away.
 	if newStart, newLen := min(l.text.Selection()), l.text.SelectionLen(); oldStart != newStart || oldLen != newLen {
-		l.events = append(l.events, SelectEvent{})
+		l.events = append(l.events, SelectEvent{NumClicks: l.clicks})
 	}
 }
 
 func (e *Selectable) processPointer(gtx layout.Context) {
+	e.handles.update(gtx, &e.text)
 	for _, evt := range e.clickDragEvents(gtx) {
 		switch evt := evt.(type) {
 		case gesture.ClickEvent:
@@ -261,8 +296,10 @@ func (e *Selectable) processPointer(gtx layout.Context) {
 					e.text.ClearSelection()
 				}
 				e.dragging = true
+				e.handles.active = evt.Source != pointer.Mouse
 
 				// Process multi-clicks.
+				e.clicks = evt.NumClicks
 				switch {
 				case evt.NumClicks == 2:
 					e.text.MoveWord(-1, selectionClear)
@@ -274,6 +311,12 @@ func (e *Selectable) processPointer(gtx layout.Context) {
 					e.dragging = false
 				}
 			}
+		case gesture.LongPressEvent:
+			if evt.Kind == gesture.LongPressTriggered && evt.Source != pointer.Mouse {
+				e.handles.selectWord(&e.text, evt.Position)
+				e.requestFocus = true
+				e.dragging = false
+			}
 		case pointer.Event:
 			release := false
 			switch {
@@ -298,12 +341,17 @@ func (e *Selectable) processPointer(gtx layout.Context) {
 
 func (e *Selectable) clickDragEvents(gtx layout.Context) []event.Event {
 	var combinedEvents []event.Event
+	e.clicker.DoubleClickDuration = gtx.Gestures.DoubleClick
+	e.dragger.MouseSlop = gtx.Gestures.DragThreshold
 	for _, evt := range e.clicker.Update(gtx) {
 		combinedEvents = append(combinedEvents, evt)
 	}
 	for _, evt := range e.dragger.Update(gtx.Metric, gtx, gesture.Both) {
 		combinedEvents = append(combinedEvents, evt)
 	}
+	for _, evt := range e.handles.longPress.Update(gtx.Metric, gtx, gtx.Now) {
+		combinedEvents = append(combinedEvents, evt)
+	}
 	return combinedEvents
 }
 
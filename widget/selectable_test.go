@@ -0,0 +1,80 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package widget
+
+import (
+	"image"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Seikaijyu/gio/font"
+	"github.com/Seikaijyu/gio/font/gofont"
+	"github.com/Seikaijyu/gio/io/pointer"
+	"github.com/Seikaijyu/gio/io/router"
+	"github.com/Seikaijyu/gio/layout"
+	"github.com/Seikaijyu/gio/op"
+	"github.com/Seikaijyu/gio/text"
+)
+
+func TestSelectableSelection(t *testing.T) {
+	lt := text.NewShaper(text.NoSystemFonts(), text.WithCollection(gofont.Collection()))
+	var (
+		ops op.Ops
+		r   router.Router
+	)
+	l := new(Selectable)
+	l.SetText("hello world")
+	frame := func() []EditorEvent {
+		layoutFrame(&r, &ops, image.Pt(300, 100), func(gtx layout.Context) {
+			l.Layout(gtx, lt, font.Font{}, 14, op.CallOp{}, op.CallOp{})
+		})
+		return l.Events()
+	}
+	frame()
+
+	l.SetSelection(6, 11)
+	if got := l.SelectedText(); got != "world" {
+		t.Errorf("SetSelection selected %q, want %q", got, "world")
+	}
+	if start, end := l.Selection(); start != 6 || end != 11 {
+		t.Errorf("selection %d-%d, want 6-11", start, end)
+	}
+	want := []EditorEvent{SelectEvent{Programmatic: true}}
+	if got := frame(); !reflect.DeepEqual(got, want) {
+		t.Errorf("SetSelection generated %v, want %v", got, want)
+	}
+	// Setting the same selection is not a change.
+	l.SetSelection(6, 11)
+	if got := frame(); len(got) > 0 {
+		t.Errorf("unchanged selection generated %v", got)
+	}
+	l.SelectAll()
+	if got := l.SelectedText(); got != "hello world" {
+		t.Errorf("SelectAll selected %q", got)
+	}
+	frame()
+
+	// Double click "hello".
+	pos, _, _ := l.text.runeInfo(2)
+	click := func(t time.Duration) {
+		r.Queue(
+			pointer.Event{Kind: pointer.Press, Source: pointer.Mouse, Buttons: pointer.ButtonPrimary, Position: layout.FPt(pos), Time: t},
+			pointer.Event{Kind: pointer.Release, Source: pointer.Mouse, Position: layout.FPt(pos), Time: t},
+		)
+	}
+	click(0)
+	click(10 * time.Millisecond)
+	var sel []SelectEvent
+	for _, e := range frame() {
+		if e, ok := e.(SelectEvent); ok {
+			sel = append(sel, e)
+		}
+	}
+	if got := l.SelectedText(); got != "hello" {
+		t.Errorf("double click selected %q, want %q", got, "hello")
+	}
+	if n := len(sel); n == 0 || sel[n-1] != (SelectEvent{NumClicks: 2}) {
+		t.Errorf("double click generated %v, want a SelectEvent with 2 clicks", sel)
+	}
+}
@@ -0,0 +1,229 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package widget
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/Seikaijyu/gio/f32"
+	"github.com/Seikaijyu/gio/gesture"
+	"github.com/Seikaijyu/gio/io/pointer"
+	"github.com/Seikaijyu/gio/layout"
+	"github.com/Seikaijyu/gio/op"
+	"github.com/Seikaijyu/gio/op/clip"
+	"github.com/Seikaijyu/gio/op/paint"
+	"github.com/Seikaijyu/gio/unit"
+	"golang.org/x/image/math/fixed"
+)
+
+// TouchSelection configures the handles and the loupe for adjusting
+// selections of text by touch. Editor and Selectable show handles at
+// the ends of selections made by touch, such as by double tapping or
+// long pressing a word, and the loupe above a handle while it is
+// dragged.
+type TouchSelection struct {
+	// Color of the handles. The zero value hides the handles.
+	Color color.NRGBA
+	// Size is the diameter of the knobs of the handles. The zero value
+	// selects 20 dp.
+	Size unit.Dp
+	// Magnification is the zoom of the loupe. The zero value selects
+	// 1.25, and negative values hide the loupe.
+	Magnification float32
+	// LoupeWidth and LoupeHeight are the size of the loupe. The zero
+	// values select 120 by 48 dp.
+	LoupeWidth, LoupeHeight unit.Dp
+	// LoupeBackground fills the loupe behind the text. The zero value
+	// selects opaque white.
+	LoupeBackground color.NRGBA
+}
+
+// selectionHandles implements TouchSelection for a textView.
+type selectionHandles struct {
+	// active is set when the selection was made by touch.
+	active bool
+	// longPress selects words by touch.
+	longPress gesture.LongPress
+	// drags are the drags of the handles at the start and end of the
+	// selection.
+	drags [2]gesture.Drag
+	// dragging is set while the handle at index handle is dragged.
+	dragging bool
+	handle   int
+	// grab is the offset from the pointer to the point it drags, in the
+	// line above the tip of the handle.
+	grab f32.Point
+	// target is the point dragged.
+	target image.Point
+}
+
+const (
+	defaultHandleSize    = 20
+	defaultMagnification = 1.25
+	defaultLoupeWidth    = 120
+	defaultLoupeHeight   = 48
+	// loupeGap is the distance between the loupe and the top of the
+	// magnified line.
+	loupeGap = 8
+)
+
+// selectionEnds returns the ends of the selection of v in text order.
+func selectionEnds(v *textView) (start, end int) {
+	start, end = v.Selection()
+	if start > end {
+		start, end = end, start
+	}
+	return start, end
+}
+
+// visible reports whether the handles are shown for the selection of
+// v.
+func (h *selectionHandles) visible(v *textView) bool {
+	return h.active && v.SelectionLen() > 0
+}
+
+// selectWord selects the word at pos, for a long press.
+func (h *selectionHandles) selectWord(v *textView, pos image.Point) {
+	v.MoveCoord(pos)
+	v.MoveWord(-1, selectionClear)
+	v.MoveWord(1, selectionExtend)
+	h.active = true
+}
+
+// update processes the drags of the handles, and reports whether they
+// changed the selection of v.
+func (h *selectionHandles) update(gtx layout.Context, v *textView) bool {
+	changed := false
+	for i := range h.drags {
+		for _, e := range h.drags[i].Update(gtx.Metric, gtx, gesture.Both) {
+			switch e.Kind {
+			case pointer.Press:
+				if !h.visible(v) {
+					break
+				}
+				var ends [2]int
+				ends[0], ends[1] = selectionEnds(v)
+				pos, ascent, descent := v.runeInfo(ends[i])
+				mid := image.Pt(pos.X, pos.Y+(descent-ascent)/2)
+				h.dragging, h.handle = true, i
+				h.grab = layout.FPt(mid).Sub(e.Position)
+				h.target = mid
+			case pointer.Drag:
+				if !h.dragging || h.handle != i {
+					break
+				}
+				h.target = e.Position.Add(h.grab).Round()
+				start, end := selectionEnds(v)
+				other := end
+				if h.handle == 1 {
+					other = start
+				}
+				x := fixed.I(h.target.X + v.scrollOff.X)
+				y := h.target.Y + v.scrollOff.Y
+				r := v.closestToXYGraphemes(x, y).runes
+				if r == other {
+					// Keep the selection from collapsing.
+					break
+				}
+				if s, _ := v.Selection(); s != r {
+					v.SetCaret(r, other)
+					changed = true
+				}
+				// The handle follows the pointer past the other.
+				h.handle = 0
+				if r > other {
+					h.handle = 1
+				}
+			case pointer.Release, pointer.Cancel:
+				if h.handle == i {
+					h.dragging = false
+				}
+			}
+		}
+	}
+	if !h.visible(v) {
+		h.dragging = false
+	}
+	return changed
+}
+
+// layout the handles and the loupe of the selection of v on top of
+// everything else. The loupe paints the text of v with textMaterial and
+// its selection with selectMaterial.
+func (h *selectionHandles) layout(gtx layout.Context, v *textView, s TouchSelection, textMaterial, selectMaterial op.CallOp) {
+	if !h.visible(v) || s.Color.A == 0 {
+		return
+	}
+	size := s.Size
+	if size == 0 {
+		size = defaultHandleSize
+	}
+	d := gtx.Dp(size)
+	m := op.Record(gtx.Ops)
+	view := image.Rectangle{Max: v.viewSize}
+	var ends [2]int
+	ends[0], ends[1] = selectionEnds(v)
+	for i, r := range ends {
+		pos, _, descent := v.runeInfo(r)
+		tip := pos.Add(image.Pt(0, descent))
+		if !tip.In(view.Inset(-1)) {
+			continue
+		}
+		// The knob hangs below the tip, away from the selection, with
+		// a square corner pointing at the tip.
+		knob := image.Rectangle{Min: tip, Max: tip.Add(image.Pt(d, d))}
+		rr := clip.RRect{Rect: knob, NE: d / 2, SE: d / 2, SW: d / 2}
+		if i == 0 {
+			knob = knob.Sub(image.Pt(d, 0))
+			rr = clip.RRect{Rect: knob, NW: d / 2, SE: d / 2, SW: d / 2}
+		}
+		paint.FillShape(gtx.Ops, s.Color, rr.Op(gtx.Ops))
+		// Knobs are small targets for fingers.
+		area := clip.Rect(knob.Inset(-d / 2)).Push(gtx.Ops)
+		pointer.CursorDefault.Add(gtx.Ops)
+		h.drags[i].Add(gtx.Ops)
+		area.Pop()
+	}
+	if h.dragging && s.Magnification >= 0 {
+		h.loupe(gtx, v, s, ends[h.handle], textMaterial, selectMaterial)
+	}
+	op.Defer(gtx.Ops, m.Stop())
+}
+
+// loupe paints the text around the dragged point magnified, above the
+// line of rune r.
+func (h *selectionHandles) loupe(gtx layout.Context, v *textView, s TouchSelection, r int, textMaterial, selectMaterial op.CallOp) {
+	mag := s.Magnification
+	if mag == 0 {
+		mag = defaultMagnification
+	}
+	w, ht := s.LoupeWidth, s.LoupeHeight
+	if w == 0 {
+		w = defaultLoupeWidth
+	}
+	if ht == 0 {
+		ht = defaultLoupeHeight
+	}
+	bg := s.LoupeBackground
+	if bg == (color.NRGBA{}) {
+		bg = color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	}
+	size := image.Pt(gtx.Dp(w), gtx.Dp(ht))
+	pos, ascent, descent := v.runeInfo(r)
+	center := image.Pt(h.target.X, pos.Y+(descent-ascent)/2)
+	origin := image.Pt(center.X-size.X/2, pos.Y-ascent-gtx.Dp(loupeGap)-size.Y)
+	bounds := image.Rectangle{Min: origin, Max: origin.Add(size)}
+	defer clip.UniformRRect(bounds, gtx.Dp(8)).Push(gtx.Ops).Pop()
+	paint.ColorOp{Color: bg}.Add(gtx.Ops)
+	paint.PaintOp{}.Add(gtx.Ops)
+	// Magnify around center, moved to the center of the loupe.
+	c := layout.FPt(center)
+	tr := f32.Affine2D{}.
+		Offset(c.Mul(-1)).
+		Scale(f32.Point{}, f32.Pt(mag, mag)).
+		Offset(layout.FPt(bounds.Min.Add(size.Div(2))))
+	defer op.Affine(tr).Push(gtx.Ops).Pop()
+	v.PaintSelection(gtx, selectMaterial)
+	v.PaintText(gtx, textMaterial)
+}
@@ -0,0 +1,277 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package widget
+
+import (
+	"image"
+
+	"github.com/Seikaijyu/gio/gesture"
+	"github.com/Seikaijyu/gio/io/pointer"
+	"github.com/Seikaijyu/gio/layout"
+	"github.com/Seikaijyu/gio/op"
+	"github.com/Seikaijyu/gio/op/clip"
+	"github.com/Seikaijyu/gio/unit"
+)
+
+// Table is the state of a table of rows and columns, such as the
+// records of a business app. The header row stays in place while the
+// rows scroll, and only the visible rows are laid out.
+//
+// The user resizes a column by dragging the trailing edge of its
+// header, reorders the columns by dragging their headers, and sorts
+// the rows by clicking the header of a sortable column.
+type Table struct {
+	// List lays out and scrolls the rows. Its Scrollbar is for drawing
+	// a scrollbar, such as with material.Scrollbar.
+	List List
+	// Columns are the columns, in the order shown. Resizing and
+	// reordering by the user update Columns.
+	Columns []TableColumn
+	// MinColumnWidth is the minimum width of a column resized by the
+	// user. The zero value means 24dp.
+	MinColumnWidth unit.Dp
+	// Sort, if set, is called when the user clicks the header of a
+	// sortable column, to sort the rows by the column. Clicking the
+	// header of the sort column again reverses the order.
+	Sort func(column string, descending bool)
+	// SortColumn is the ID of the column the rows are sorted by, and
+	// SortDescending the order, as set by the user.
+	SortColumn     string
+	SortDescending bool
+
+	columns map[string]*tableColumn
+	// ids is scratch space for the column IDs.
+	ids []string
+	// dragging is the ID of the column being reordered.
+	dragging string
+}
+
+// TableColumn describes a column of a Table.
+type TableColumn struct {
+	// ID identifies the column, such as the name of a field. It must be
+	// unique within the Table.
+	ID string
+	// Width of the column.
+	Width unit.Dp
+	// Sortable enables sorting by the column.
+	Sortable bool
+}
+
+// tableColumn is the state of the header of a column.
+type tableColumn struct {
+	click gesture.Click
+	// move reorders the column, and resize resizes it.
+	move, resize gesture.Drag
+	// x and width are the bounds of the column in the last frame.
+	x, width int
+	// startX and startWidth are the pointer position and width at the
+	// start of a resize.
+	startX, startWidth int
+}
+
+// TableCell lays out the cell of a Table at a row and column. The column
+// is an index into Table.Columns. The constraints of a cell have the
+// exact width of its column.
+type TableCell func(gtx layout.Context, row, col int) layout.Dimensions
+
+// Update the state of the table in response to input events.
+func (t *Table) Update(gtx layout.Context) {
+	minWidth := t.MinColumnWidth
+	if minWidth == 0 {
+		minWidth = 24
+	}
+	// Iterate over a copy of the columns, because reordering changes
+	// their order.
+	t.ids = t.ids[:0]
+	for _, col := range t.Columns {
+		t.ids = append(t.ids, col.ID)
+	}
+	for _, id := range t.ids {
+		s := t.columns[id]
+		if s == nil {
+			continue
+		}
+		i := t.index(id)
+		col := t.Columns[i]
+		for _, e := range s.click.Update(gtx) {
+			if e.Kind != gesture.KindClick || !col.Sortable || t.Sort == nil || t.dragging != "" {
+				continue
+			}
+			if t.SortColumn == col.ID {
+				t.SortDescending = !t.SortDescending
+			} else {
+				t.SortColumn, t.SortDescending = col.ID, false
+			}
+			t.Sort(t.SortColumn, t.SortDescending)
+		}
+		s.resize.MouseSlop = gtx.Gestures.DragThreshold
+		s.move.MouseSlop = gtx.Gestures.DragThreshold
+		for _, e := range s.resize.Update(gtx.Metric, gtx, gesture.Horizontal) {
+			// Positions are relative to the resize handle, which is
+			// at the trailing edge of the column.
+			x := s.x + s.width + int(e.Position.X)
+			switch e.Kind {
+			case pointer.Press:
+				s.startX, s.startWidth = x, s.width
+			case pointer.Drag:
+				w := max(s.startWidth+x-s.startX, gtx.Dp(minWidth))
+				t.Columns[i].Width = unit.Dp(float32(w) / gtx.Metric.PxPerDp)
+			}
+		}
+		for _, e := range s.move.Update(gtx.Metric, gtx, gesture.Horizontal) {
+			switch e.Kind {
+			case pointer.Drag:
+				t.dragging = col.ID
+				i = t.moveColumn(i, s.x+int(e.Position.X))
+				col = t.Columns[i]
+			case pointer.Release, pointer.Cancel:
+				t.dragging = ""
+			}
+		}
+	}
+}
+
+// index returns the index of the column with the given ID, or -1.
+func (t *Table) index(id string) int {
+	for i, col := range t.Columns {
+		if col.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// moveColumn moves the column at index i to the column under the
+// position x, and returns its new index.
+func (t *Table) moveColumn(i, x int) int {
+	for j, col := range t.Columns {
+		s := t.columns[col.ID]
+		if s == nil || j == i || x < s.x || x >= s.x+s.width {
+			continue
+		}
+		c := t.Columns[i]
+		if j < i {
+			copy(t.Columns[j+1:i+1], t.Columns[j:i])
+		} else {
+			copy(t.Columns[i:j], t.Columns[i+1:j+1])
+		}
+		t.Columns[j] = c
+		// Update the bounds of the columns for the rest of the drag.
+		x := 0
+		for _, col := range t.Columns {
+			if s := t.columns[col.ID]; s != nil {
+				s.x = x
+				x += s.width
+			}
+		}
+		return j
+	}
+	return i
+}
+
+// Dragging returns the ID of the column being reordered by the user, if
+// any.
+func (t *Table) Dragging() (string, bool) {
+	return t.dragging, t.dragging != ""
+}
+
+// Layout the table with the given number of rows. header lays out the
+// header of a column, with the row argument set to -1, and cell the
+// cells of the rows.
+func (t *Table) Layout(gtx layout.Context, rows int, header, cell TableCell) layout.Dimensions {
+	t.Update(gtx)
+	if t.columns == nil {
+		t.columns = make(map[string]*tableColumn)
+	}
+	// Forget the state of removed columns.
+	for id := range t.columns {
+		if t.index(id) == -1 {
+			delete(t.columns, id)
+		}
+	}
+	x := 0
+	for _, col := range t.Columns {
+		s := t.columns[col.ID]
+		if s == nil {
+			s = new(tableColumn)
+			t.columns[col.ID] = s
+		}
+		s.x, s.width = x, gtx.Dp(col.Width)
+		x += s.width
+	}
+	size := gtx.Constraints.Max
+	defer clip.Rect{Max: size}.Push(gtx.Ops).Pop()
+
+	hgtx := gtx
+	hgtx.Constraints.Max.Y = size.Y
+	headerDims := t.layoutRow(hgtx, -1, header)
+	headerHeight := headerDims.Size.Y
+	t.layoutHandles(gtx, headerHeight)
+
+	rgtx := gtx
+	rgtx.Constraints.Min = image.Point{}
+	rgtx.Constraints.Max.Y = max(size.Y-headerHeight, 0)
+	t.List.Axis = layout.Vertical
+	off := op.Offset(image.Pt(0, headerHeight)).Push(gtx.Ops)
+	dims := t.List.Layout(rgtx, rows, func(gtx layout.Context, row int) layout.Dimensions {
+		return t.layoutRow(gtx, row, cell)
+	})
+	off.Pop()
+	return layout.Dimensions{
+		Size: gtx.Constraints.Constrain(image.Pt(x, headerHeight+dims.Size.Y)),
+	}
+}
+
+// layoutRow lays out the cells of a row side by side, as high as the
+// highest cell.
+func (t *Table) layoutRow(gtx layout.Context, row int, cell TableCell) layout.Dimensions {
+	var calls [16]op.CallOp
+	cells := calls[:0]
+	height := 0
+	for i, col := range t.Columns {
+		s := t.columns[col.ID]
+		cgtx := gtx
+		cgtx.Constraints = layout.Constraints{
+			Min: image.Pt(s.width, 0),
+			Max: image.Pt(s.width, gtx.Constraints.Max.Y),
+		}
+		m := op.Record(gtx.Ops)
+		dims := cell(cgtx, row, i)
+		cells = append(cells, m.Stop())
+		height = max(height, dims.Size.Y)
+	}
+	x := 0
+	for i, col := range t.Columns {
+		s := t.columns[col.ID]
+		st := op.Offset(image.Pt(s.x, 0)).Push(gtx.Ops)
+		cl := clip.Rect{Max: image.Pt(s.width, height)}.Push(gtx.Ops)
+		if row == -1 {
+			s.click.Add(gtx.Ops)
+			s.move.Add(gtx.Ops)
+			if t.dragging == col.ID {
+				pointer.CursorGrabbing.Add(gtx.Ops)
+			}
+		}
+		cells[i].Add(gtx.Ops)
+		cl.Pop()
+		st.Pop()
+		x = s.x + s.width
+	}
+	return layout.Dimensions{Size: image.Pt(x, height)}
+}
+
+// layoutHandles adds the resize handles at the trailing edges of the
+// headers.
+func (t *Table) layoutHandles(gtx layout.Context, height int) {
+	w := gtx.Dp(8)
+	for _, col := range t.Columns {
+		s := t.columns[col.ID]
+		r := image.Rect(s.x+s.width-w/2, 0, s.x+s.width+w/2, height)
+		st := op.Offset(image.Pt(s.x+s.width, 0)).Push(gtx.Ops)
+		cl := clip.Rect(r.Sub(image.Pt(s.x+s.width, 0))).Push(gtx.Ops)
+		pointer.CursorColResize.Add(gtx.Ops)
+		s.resize.Add(gtx.Ops)
+		cl.Pop()
+		st.Pop()
+	}
+}
@@ -0,0 +1,99 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package widget
+
+import (
+	"image"
+	"reflect"
+	"testing"
+
+	"github.com/Seikaijyu/gio/f32"
+	"github.com/Seikaijyu/gio/io/pointer"
+	"github.com/Seikaijyu/gio/io/router"
+	"github.com/Seikaijyu/gio/layout"
+	"github.com/Seikaijyu/gio/op"
+)
+
+func TestTable(t *testing.T) {
+	type sort struct {
+		column     string
+		descending bool
+	}
+	var sorts []sort
+	tbl := &Table{
+		Columns: []TableColumn{
+			{ID: "a", Width: 50, Sortable: true},
+			{ID: "b", Width: 50},
+			{ID: "c", Width: 50},
+		},
+		Sort: func(column string, descending bool) {
+			sorts = append(sorts, sort{column, descending})
+		},
+	}
+	var (
+		ops op.Ops
+		r   router.Router
+	)
+	const rowHeight = 10
+	rows := make(map[int]bool)
+	frame := func() {
+		layoutFrame(&r, &ops, image.Pt(200, 100), func(gtx layout.Context) {
+			tbl.Layout(gtx, 1000, func(gtx layout.Context, row, col int) layout.Dimensions {
+				return layout.Dimensions{Size: image.Pt(gtx.Constraints.Min.X, 20)}
+			}, func(gtx layout.Context, row, col int) layout.Dimensions {
+				rows[row] = true
+				return layout.Dimensions{Size: image.Pt(gtx.Constraints.Min.X, rowHeight)}
+			})
+		})
+	}
+	frame()
+	if n := len(rows); n == 0 || n > 10 {
+		t.Errorf("laid out %d rows, want the 8 visible rows", n)
+	}
+
+	click := func(pos f32.Point) {
+		r.Queue(
+			pointer.Event{Kind: pointer.Press, Source: pointer.Mouse, Buttons: pointer.ButtonPrimary, Position: pos},
+			pointer.Event{Kind: pointer.Release, Source: pointer.Mouse, Position: pos},
+		)
+		frame()
+	}
+	drag := func(from, to f32.Point) {
+		r.Queue(
+			pointer.Event{Kind: pointer.Press, Source: pointer.Mouse, Buttons: pointer.ButtonPrimary, Position: from},
+			pointer.Event{Kind: pointer.Move, Source: pointer.Mouse, Buttons: pointer.ButtonPrimary, Position: to},
+		)
+		frame()
+		r.Queue(pointer.Event{Kind: pointer.Release, Source: pointer.Mouse, Position: to})
+		frame()
+	}
+	// Sorting.
+	click(f32.Pt(20, 10))
+	click(f32.Pt(20, 10))
+	// The second column is not sortable.
+	click(f32.Pt(70, 10))
+	if want := []sort{{"a", false}, {"a", true}}; !reflect.DeepEqual(sorts, want) {
+		t.Errorf("sorted %v, want %v", sorts, want)
+	}
+
+	// Resize the first column by its trailing edge.
+	drag(f32.Pt(50, 10), f32.Pt(80, 10))
+	if w := tbl.Columns[0].Width; w != 80 {
+		t.Errorf("resized column to %v, want 80", w)
+	}
+	tbl.Columns[0].Width = 50
+	frame()
+
+	// Move the first column after the last.
+	drag(f32.Pt(20, 10), f32.Pt(130, 10))
+	var order []string
+	for _, c := range tbl.Columns {
+		order = append(order, c.ID)
+	}
+	if want := []string{"b", "c", "a"}; !reflect.DeepEqual(order, want) {
+		t.Errorf("reordered columns to %v, want %v", order, want)
+	}
+	if len(sorts) != 2 {
+		t.Errorf("dragging a header sorted by it")
+	}
+}
@@ -3,6 +3,7 @@ package widget
 import (
 	"bufio"
 	"image"
+	"image/color"
 	"io"
 	"math"
 	"sort"
@@ -11,6 +12,7 @@ import (
 
 	"github.com/Seikaijyu/gio/f32"
 	"github.com/Seikaijyu/gio/font"
+	"github.com/Seikaijyu/gio/io/system"
 	"github.com/Seikaijyu/gio/layout"
 	"github.com/Seikaijyu/gio/op"
 	"github.com/Seikaijyu/gio/op/clip"
@@ -54,6 +56,10 @@ type textView struct {
 	// SingleLine also sets the scrolling direction to
 	// horizontal.
 	SingleLine bool
+	// NoWrap disables wrapping lines, scrolling long lines horizontally.
+	NoWrap bool
+	// WrapIndent indents the continuation lines of wrapped lines.
+	WrapIndent unit.Sp
 	// MaxLines limits the shaped text to a specific quantity of shaped lines.
 	MaxLines int
 	// Truncator is the text that will be shown at the end of the final
@@ -61,10 +67,16 @@ type textView struct {
 	Truncator string
 	// WrapPolicy configures how displayed text will be broken into lines.
 	WrapPolicy text.WrapPolicy
+	// Vertical shapes the text for vertical layout. The text is laid out
+	// horizontally, and rotated into columns by the caller.
+	Vertical bool
 	// Mask replaces the visual display of each rune in the contents with the given rune.
 	// Newline characters are not masked. When non-zero, the unmasked contents
 	// are accessed by Len, Text, and SetText.
 	Mask rune
+	// Spans style ranges of the text. They must be sorted by Start and
+	// not overlap.
+	Spans []TextSpan
 
 	params     text.Parameters
 	shaper     *text.Shaper
@@ -80,27 +92,101 @@ type textView struct {
 	valid           bool
 	regions         []Region
 	dims            layout.Dimensions
+	// indent is WrapIndent in pixels, as laid out.
+	indent fixed.Int26_6
 
 	// offIndex is an index of rune index to byte offsets.
 	offIndex []offEntry
 
 	index glyphIndex
 
-	caret struct {
-		// xoff is the offset to the current position when moving between lines.
-		xoff fixed.Int26_6
-		// start is the current caret position in runes, and also the start position of
-		// selected text. end is the end position of selected text. If start
-		// == end, then there's no selection. Note that it's possible (and
-		// common) that the caret (start) is after the end, e.g. after
-		// Shift-DownArrow.
-		start int
-		end   int
-	}
+	// paragraphs caches the layout of every paragraph of a large text, so
+	// that edits only reshape the paragraphs they touch. See
+	// layoutParagraphs.
+	paragraphs []paragraphLayout
+	// paragraphKey is the configuration paragraphs were shaped with.
+	paragraphKey paragraphKey
+	// laidOut is the number of leading paragraphs in index.
+	laidOut int
+	// estimate is the glyph the layout of unshaped paragraphs is
+	// estimated from.
+	estimate paragraphEstimate
+	// shapedSpan is the vertical span of shaped paragraphs around the
+	// viewport.
+	shapedSpan [2]int
+	// maxHeight is the maximum height of the viewport, which is the
+	// distance from the viewport paragraphs are shaped within.
+	maxHeight int
+
+	caret textCaret
+	// carets are the secondary carets, in addition to the primary caret.
+	// They don't overlap each other or the primary caret.
+	carets []textCaret
 
 	scrollOff image.Point
 }
 
+// textCaret is a caret and its selection.
+type textCaret struct {
+	// xoff is the offset to the current position when moving between lines.
+	xoff fixed.Int26_6
+	// start is the current caret position in runes, and also the start position of
+	// selected text. end is the end position of selected text. If start
+	// == end, then there's no selection. Note that it's possible (and
+	// common) that the caret (start) is after the end, e.g. after
+	// Shift-DownArrow.
+	start int
+	end   int
+}
+
+// wrapIndenter indents the continuation lines of wrapped lines, by
+// moving their glyphs. In text flowing towards the origin, it moves the
+// glyphs of the other lines instead.
+type wrapIndenter struct {
+	indent fixed.Int26_6
+	// towardOrigin is set for text flowing towards the origin.
+	towardOrigin bool
+	// continued is set for the glyphs of a continuation line, and
+	// lineEnded after the last glyph of a line.
+	continued, lineEnded bool
+}
+
+// wrapIndenter returns the indenter of the text of e.
+func (e *textView) wrapIndenter() wrapIndenter {
+	return wrapIndenter{
+		indent:       e.indent,
+		towardOrigin: e.params.Locale.Direction.Progression() == system.TowardOrigin,
+	}
+}
+
+// indentGlyph returns g indented, if it is on an indented line. It must
+// be called for every glyph in order.
+func (w *wrapIndenter) indentGlyph(g text.Glyph) text.Glyph {
+	if w.lineEnded {
+		w.continued = g.Flags&text.FlagParagraphStart == 0
+		w.lineEnded = false
+	}
+	if w.continued != w.towardOrigin {
+		g.X += w.indent
+	}
+	w.lineEnded = g.Flags&text.FlagLineBreak != 0
+	return g
+}
+
+// TextSpan styles the runes [Start, End) of a text.
+type TextSpan struct {
+	Start, End int
+	// Color of the glyphs. The zero value keeps the material of the
+	// text.
+	Color color.NRGBA
+	// Background is painted behind the glyphs, unless transparent.
+	Background color.NRGBA
+	// Weight emboldens the glyphs if heavier than font.Normal. The
+	// glyphs are emboldened in place, so that spans never reflow the
+	// text.
+	Weight font.Weight
+}
+
 func (e *textView) Changed() bool {
 	return e.rr.Changed()
 }
@@ -121,6 +207,7 @@ func (e *textView) FullDimensions() layout.Dimensions {
 // must be done before invoking any other methods on Text.
 func (e *textView) SetSource(source textSource) {
 	e.rr = source
+	e.paragraphs = e.paragraphs[:0]
 	e.invalidate()
 	e.seekCursor = 0
 }
@@ -152,6 +239,9 @@ func (e *textView) ReadRuneBefore(off int64) (rune, int, error) {
 }
 
 func (e *textView) makeValid() {
+	if e.valid && len(e.paragraphs) > 0 && !e.caretsShaped() {
+		e.valid = false
+	}
 	if e.valid {
 		return
 	}
@@ -241,9 +331,19 @@ func (e *textView) Layout(gtx layout.Context, lt *text.Shaper, font font.Font, s
 		e.params.PxPerEm = textSize
 	}
 	maxWidth := gtx.Constraints.Max.X
-	if e.SingleLine {
+	if e.SingleLine || e.NoWrap {
 		maxWidth = math.MaxInt
 	}
+	var indent fixed.Int26_6
+	if maxWidth != math.MaxInt {
+		indent = fixed.I(gtx.Sp(e.WrapIndent))
+		// Make room for the indentation.
+		maxWidth = max(maxWidth-indent.Ceil(), 0)
+	}
+	if indent != e.indent {
+		e.indent = indent
+		e.invalidate()
+	}
 	minWidth := gtx.Constraints.Min.X
 	if maxWidth != e.params.MaxWidth {
 		e.params.MaxWidth = maxWidth
@@ -285,6 +385,14 @@ func (e *textView) Layout(gtx layout.Context, lt *text.Shaper, font font.Font, s
 		e.params.LineHeightScale = e.LineHeightScale
 		e.invalidate()
 	}
+	if e.Vertical != e.params.Vertical {
+		e.params.Vertical = e.Vertical
+		e.invalidate()
+	}
+	if h := gtx.Constraints.Max.Y; h != e.maxHeight {
+		e.maxHeight = h
+		e.valid = false
+	}
 
 	e.makeValid()
 
@@ -301,7 +409,15 @@ func (e *textView) PaintSelection(gtx layout.Context, material op.CallOp) {
 	localViewport := image.Rectangle{Max: e.viewSize}
 	docViewport := image.Rectangle{Max: e.viewSize}.Add(e.scrollOff)
 	defer clip.Rect(localViewport).Push(gtx.Ops).Pop()
-	e.regions = e.index.locate(docViewport, e.caret.start, e.caret.end, e.regions)
+	e.paintSelection(gtx, material, docViewport, e.caret)
+	for _, c := range e.carets {
+		e.paintSelection(gtx, material, docViewport, c)
+	}
+}
+
+// paintSelection paints the visible selection rectangles of c.
+func (e *textView) paintSelection(gtx layout.Context, material op.CallOp, docViewport image.Rectangle, c textCaret) {
+	e.regions = e.index.locate(docViewport, c.start, c.end, e.regions)
 	for _, region := range e.regions {
 		area := clip.Rect(region.Bounds).Push(gtx.Ops)
 		material.Add(gtx.Ops)
@@ -311,7 +427,7 @@ func (e *textView) PaintSelection(gtx layout.Context, material op.CallOp) {
 }
 
 // PaintText clips and paints the visible text glyph outlines using the provided
-// material to fill the glyphs.
+// material to fill the glyphs, or the colors of Spans.
 func (e *textView) PaintText(gtx layout.Context, material op.CallOp) {
 	m := op.Record(gtx.Ops)
 	viewport := image.Rectangle{
@@ -324,7 +440,10 @@ func (e *textView) PaintText(gtx layout.Context, material op.CallOp) {
 	}
 
 	startGlyph := 0
+	// runes is the rune offset of the next glyph cluster.
+	runes := 0
 	for _, line := range e.index.lines {
+		runes = line.runes
 		if line.descent.Ceil()+line.yOff >= viewport.Min.Y {
 			break
 		}
@@ -332,20 +451,80 @@ func (e *textView) PaintText(gtx layout.Context, material op.CallOp) {
 	}
 	var glyphs [32]text.Glyph
 	line := glyphs[:0]
+	spans := e.Spans
+	styled := len(spans) > 0
+	var style, zero TextSpan
+	var embolden float32
 	for _, g := range e.index.glyphs[startGlyph:] {
+		if styled {
+			for len(spans) > 0 && spans[0].End <= runes {
+				spans = spans[1:]
+			}
+			s := zero
+			if len(spans) > 0 && spans[0].Start <= runes {
+				s = spans[0]
+			}
+			if s.Color != style.Color || s.Weight != style.Weight {
+				// Glyphs are shaped in batches of a single style.
+				line = it.paintLine(gtx, e.shaper, line)
+				it.material = material
+				if s.Color != (color.NRGBA{}) {
+					c := op.Record(gtx.Ops)
+					paint.ColorOp{Color: s.Color}.Add(gtx.Ops)
+					it.material = c.Stop()
+				}
+				it.embolden = e.embolden(s.Weight)
+				if it.embolden > embolden {
+					embolden = it.embolden
+				}
+			}
+			style = s
+		}
 		var ok bool
 		if line, ok = it.paintGlyph(gtx, e.shaper, g, line); !ok {
 			break
 		}
+		if g.Flags&text.FlagClusterBreak != 0 {
+			runes += int(g.Runes)
+		}
 	}
 
 	call := m.Stop()
-	viewport.Min = viewport.Min.Add(it.padding.Min)
-	viewport.Max = viewport.Max.Add(it.padding.Max)
+	// Leave room for the strokes of emboldened glyphs.
+	grow := int(math.Ceil(float64(embolden / 2)))
+	viewport.Min = viewport.Min.Add(it.padding.Min).Sub(image.Pt(grow, grow))
+	viewport.Max = viewport.Max.Add(it.padding.Max).Add(image.Pt(grow, grow))
 	defer clip.Rect(viewport.Sub(e.scrollOff)).Push(gtx.Ops).Pop()
 	call.Add(gtx.Ops)
 }
 
+// embolden returns the width of the stroke that emboldens glyphs to
+// weight. Bold widens glyphs by a 24th of an em, like the synthetic
+// bold of FreeType.
+func (e *textView) embolden(weight font.Weight) float32 {
+	if weight <= font.Normal {
+		return 0
+	}
+	em := fixedToFloat(e.params.PxPerEm)
+	return em / 24 * float32(weight-font.Normal) / float32(font.Bold-font.Normal)
+}
+
+// PaintSpans clips and paints the backgrounds of the visible Spans.
+func (e *textView) PaintSpans(gtx layout.Context) {
+	localViewport := image.Rectangle{Max: e.viewSize}
+	docViewport := image.Rectangle{Max: e.viewSize}.Add(e.scrollOff)
+	defer clip.Rect(localViewport).Push(gtx.Ops).Pop()
+	for _, s := range e.Spans {
+		if s.Background.A == 0 {
+			continue
+		}
+		e.regions = e.index.locate(docViewport, s.Start, s.End, e.regions)
+		for _, region := range e.regions {
+			paint.FillShape(gtx.Ops, s.Background, clip.Rect(region.Bounds).Op())
+		}
+	}
+}
+
 // caretWidth returns the width occupied by the caret for the current
 // gtx.
 func (e *textView) caretWidth(gtx layout.Context) int {
@@ -359,8 +538,16 @@ func (e *textView) caretWidth(gtx layout.Context) int {
 // PaintCaret clips and paints the caret rectangle, adding material immediately
 // before painting to set the appropriate paint material.
 func (e *textView) PaintCaret(gtx layout.Context, material op.CallOp) {
+	e.paintCaret(gtx, material, e.caret.start)
+	for _, c := range e.carets {
+		e.paintCaret(gtx, material, c.start)
+	}
+}
+
+// paintCaret paints the caret before rune r.
+func (e *textView) paintCaret(gtx layout.Context, material op.CallOp, r int) {
 	carWidth2 := e.caretWidth(gtx)
-	caretPos, carAsc, carDesc := e.CaretInfo()
+	caretPos, carAsc, carDesc := e.runeInfo(r)
 
 	carRect := image.Rectangle{
 		Min: caretPos.Sub(image.Pt(carWidth2, carAsc)),
@@ -376,7 +563,13 @@ func (e *textView) PaintCaret(gtx layout.Context, material op.CallOp) {
 }
 
 func (e *textView) CaretInfo() (pos image.Point, ascent, descent int) {
-	caretStart := e.closestToRune(e.caret.start)
+	return e.runeInfo(e.caret.start)
+}
+
+// runeInfo returns the position of the caret before rune r relative to
+// the viewport, and the ascent and descent of its line.
+func (e *textView) runeInfo(r int) (pos image.Point, ascent, descent int) {
+	caretStart := e.closestToRune(r)
 
 	ascent = caretStart.ascent.Ceil()
 	descent = caretStart.descent.Ceil()
@@ -429,6 +622,12 @@ func (e *textView) ScrollBounds() image.Rectangle {
 		b.Max.X = e.dims.Size.X + b.Min.X - e.viewSize.X
 	} else {
 		b.Max.Y = e.dims.Size.Y - e.viewSize.Y
+		if e.NoWrap {
+			for _, line := range e.index.lines {
+				b.Min.X = min(b.Min.X, line.xOff.Floor())
+			}
+			b.Max.X = e.dims.Size.X + b.Min.X - e.viewSize.X
+		}
 	}
 	return b
 }
@@ -445,6 +644,11 @@ func (e *textView) ScrollOff() image.Point {
 func (e *textView) scrollAbs(x, y int) {
 	e.scrollOff.X = x
 	e.scrollOff.Y = y
+	if len(e.paragraphs) > 0 && (y < e.shapedSpan[0] || y+e.viewSize.Y > e.shapedSpan[1]) {
+		// Shape the paragraphs scrolled into view.
+		e.valid = false
+		e.makeValid()
+	}
 	b := e.ScrollBounds()
 	if e.scrollOff.X > b.Max.X {
 		e.scrollOff.X = b.Max.X
@@ -476,6 +680,11 @@ func (e *textView) Truncated() bool {
 }
 
 func (e *textView) layoutText(lt *text.Shaper) {
+	if lt != nil && e.incremental() {
+		e.layoutParagraphs(lt)
+		return
+	}
+	e.paragraphs = e.paragraphs[:0]
 	e.Seek(0, io.SeekStart)
 	var r io.Reader = e
 	if e.Mask != 0 {
@@ -486,8 +695,10 @@ func (e *textView) layoutText(lt *text.Shaper) {
 	it := textIterator{viewport: image.Rectangle{Max: image.Point{X: math.MaxInt, Y: math.MaxInt}}}
 	if lt != nil {
 		lt.Layout(e.params, r)
+		w := e.wrapIndenter()
 		for {
 			g, ok := lt.NextGlyph()
+			g = w.indentGlyph(g)
 			if !it.processGlyph(g, ok) {
 				break
 			}
@@ -576,11 +787,14 @@ func (e *textView) Replace(start, end int, s string) int {
 	startPos := e.closestToRune(start)
 	endPos := e.closestToRune(end)
 	startOff := e.runeOffset(startPos.runes)
+	endOff := e.runeOffset(endPos.runes)
 	replaceSize := endPos.runes - startPos.runes
 	sc := utf8.RuneCountInString(s)
 	newEnd := startPos.runes + sc
 
+	oldSize := e.rr.Size()
 	e.rr.ReplaceRunes(int64(startOff), int64(replaceSize), s)
+	e.invalidateParagraphs(startOff, endOff, int(e.rr.Size()-oldSize))
 	adjust := func(pos int) int {
 		switch {
 		case newEnd < pos && pos <= endPos.runes:
@@ -593,7 +807,17 @@ func (e *textView) Replace(start, end int, s string) int {
 	}
 	e.caret.start = adjust(e.caret.start)
 	e.caret.end = adjust(e.caret.end)
-	e.invalidate()
+	for i := range e.carets {
+		c := &e.carets[i]
+		c.start = adjust(c.start)
+		c.end = adjust(c.end)
+	}
+	// The rune offsets before the replaced text remain valid.
+	keep := sort.Search(len(e.offIndex), func(i int) bool {
+		return e.offIndex[i].bytes > startOff
+	})
+	e.offIndex = e.offIndex[:keep]
+	e.valid = false
 	return sc
 }
 
@@ -612,6 +836,7 @@ func (e *textView) MovePages(pages int, selAct selectionAction) {
 // moveByGraphemes returns the rune index resulting from moving the
 // specified number of grapheme clusters from startRuneidx.
 func (e *textView) moveByGraphemes(startRuneidx, graphemes int) int {
+	e.makeValid()
 	if len(e.graphemes) == 0 {
 		return startRuneidx
 	}
@@ -709,25 +934,24 @@ func (e *textView) MoveWord(distance int, selAct selectionAction) {
 
 func (e *textView) ScrollToCaret() {
 	caret := e.closestToRune(e.caret.start)
-	if e.SingleLine {
-		var dist int
+	var dx, dy int
+	if e.SingleLine || e.NoWrap {
 		if d := caret.x.Floor() - e.scrollOff.X; d < 0 {
-			dist = d
+			dx = d
 		} else if d := caret.x.Ceil() - (e.scrollOff.X + e.viewSize.X); d > 0 {
-			dist = d
+			dx = d
 		}
-		e.ScrollRel(dist, 0)
-	} else {
+	}
+	if !e.SingleLine {
 		miny := caret.y - caret.ascent.Ceil()
 		maxy := caret.y + caret.descent.Ceil()
-		var dist int
 		if d := miny - e.scrollOff.Y; d < 0 {
-			dist = d
+			dy = d
 		} else if d := maxy - (e.scrollOff.Y + e.viewSize.Y); d > 0 {
-			dist = d
+			dy = d
 		}
-		e.ScrollRel(0, dist)
 	}
+	e.ScrollRel(dx, dy)
 }
 
 // SelectionLen returns the length of the selection, in runes; it is
@@ -824,3 +1048,99 @@ func (e *textView) Regions(start, end int, regions []Region) []Region {
 	}
 	return e.index.locate(viewport, start, end, regions)
 }
+
+// AddCaret adds a secondary caret at start, with its selection ending at
+// end. start and end are clamped to grapheme cluster boundaries, and the
+// caret is merged with the carets it overlaps.
+func (e *textView) AddCaret(start, end int) {
+	e.carets = append(e.carets, textCaret{
+		start: e.moveByGraphemes(e.closestToRune(start).runes, 0),
+		end:   e.moveByGraphemes(e.closestToRune(end).runes, 0),
+	})
+	e.mergeCarets()
+}
+
+// ClearCarets removes the secondary carets.
+func (e *textView) ClearCarets() {
+	e.carets = e.carets[:0]
+}
+
+// eachCaret calls f for every caret, secondary carets first, with the
+// caret in turn as the primary caret. Carets that overlap afterwards are
+// merged.
+func (e *textView) eachCaret(f func()) {
+	for i := range e.carets {
+		e.caret, e.carets[i] = e.carets[i], e.caret
+		f()
+		e.caret, e.carets[i] = e.carets[i], e.caret
+	}
+	f()
+	e.mergeCarets()
+}
+
+// mergeCarets merges secondary carets into the primary caret or the
+// secondary carets before them if they overlap.
+func (e *textView) mergeCarets() {
+	kept := e.carets[:0]
+next:
+	for _, c := range e.carets {
+		if c.overlaps(e.caret) {
+			e.caret = e.caret.merge(c)
+			continue
+		}
+		for i, k := range kept {
+			if c.overlaps(k) {
+				kept[i] = k.merge(c)
+				continue next
+			}
+		}
+		kept = append(kept, c)
+	}
+	e.carets = kept
+}
+
+// SelectBox replaces the carets with a caret for every line between the
+// document coordinates from and to, selecting the text between their x
+// coordinates. The primary caret is on the line of to.
+func (e *textView) SelectBox(from, to image.Point) {
+	first := e.closestToXY(fixed.I(from.X), from.Y).lineCol.line
+	last := e.closestToXY(fixed.I(to.X), to.Y).lineCol.line
+	step := 1
+	if last < first {
+		step = -1
+	}
+	e.carets = e.carets[:0]
+	for line := first; ; line += step {
+		y := e.closestToLineCol(line, 0).y
+		c := textCaret{
+			start: e.closestToXYGraphemes(fixed.I(to.X), y).runes,
+			end:   e.closestToXYGraphemes(fixed.I(from.X), y).runes,
+		}
+		if line == last {
+			e.caret = c
+			break
+		}
+		e.carets = append(e.carets, c)
+	}
+}
+
+// overlaps reports whether the selections of c and o overlap, or whether
+// both are at the same position.
+func (c textCaret) overlaps(o textCaret) bool {
+	cmin, cmax := min(c.start, c.end), max(c.start, c.end)
+	omin, omax := min(o.start, o.end), max(o.start, o.end)
+	return cmin == omin || cmin < omax && omin < cmax
+}
+
+// merge returns c with its selection extended to cover the selection of
+// o, in the direction of c.
+func (c textCaret) merge(o textCaret) textCaret {
+	lo := min(min(c.start, c.end), min(o.start, o.end))
+	hi := max(max(c.start, c.end), max(o.start, o.end))
+	if c.start < c.end {
+		c.start, c.end = lo, hi
+	} else {
+		c.start, c.end = hi, lo
+	}
+	return c
+}
@@ -0,0 +1,212 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package widget
+
+import (
+	"image"
+	"time"
+
+	"github.com/Seikaijyu/gio/f32"
+	"github.com/Seikaijyu/gio/gesture"
+	"github.com/Seikaijyu/gio/io/pointer"
+	"github.com/Seikaijyu/gio/layout"
+	"github.com/Seikaijyu/gio/op"
+	"github.com/Seikaijyu/gio/op/clip"
+	"github.com/Seikaijyu/gio/unit"
+)
+
+// TooltipArea shows a tooltip when the pointer rests on a widget. The
+// tooltip appears after the pointer stayed still for Delay, and hides
+// when the pointer moves, leaves the widget or presses a button. After a
+// press, the tooltip stays hidden until the pointer leaves the widget.
+//
+// The tooltip is drawn by a Tooltips, which shows at most one tooltip
+// at a time.
+type TooltipArea struct {
+	// Delay is how long the pointer must rest on the widget before the
+	// tooltip appears. The zero value means 500ms.
+	Delay time.Duration
+
+	hover gesture.Hover
+	rest  tooltipRest
+}
+
+// Tooltips draws the tooltip of at most one TooltipArea at a time, next
+// to the pointer and inside the window. Lay it out over the whole
+// window after the rest of the frame, so that the tooltip is drawn on
+// top.
+//
+// Tooltips also shows the help text given by semantic.HelpOp of the
+// component under the pointer, if Help is set and no TooltipArea shows
+// a tooltip. The help text tooltip appears and hides like the tooltip
+// of a TooltipArea covering the component.
+type Tooltips struct {
+	// Popup positions the tooltip next to the pointer. The zero value
+	// places the tooltip below the pointer, flipped above it near the
+	// bottom of the window. A zero Gap means 20dp, to clear the cursor.
+	Popup layout.Popup
+	// Help lays out the tooltip for a help text. Help texts are not
+	// shown if Help is nil.
+	Help func(gtx layout.Context, text string) layout.Dimensions
+	// HelpDelay is the delay of help text tooltips. The zero value
+	// means 500ms.
+	HelpDelay time.Duration
+
+	// pos is the position of the pointer in the window, and hovering
+	// is set while it hovers over the window.
+	pos      f32.Point
+	hovering bool
+	// help tracks the rest of the pointer on the component with help
+	// text and helpBounds.
+	help       tooltipRest
+	helpBounds image.Rectangle
+	// area and tip are the area whose tooltip is shown in the frame,
+	// and the tooltip.
+	area *TooltipArea
+	tip  layout.Widget
+	// placement is the placement of the last tooltip.
+	placement layout.Placement
+}
+
+const (
+	// defaultTooltipDelay is the delay of a TooltipArea with zero Delay.
+	defaultTooltipDelay = 500 * time.Millisecond
+	// tooltipSlop is how far the pointer may move and still rest.
+	tooltipSlop = unit.Dp(3)
+)
+
+// tooltipRest tracks the pointer coming to rest on a widget, for the
+// tooltip of the widget.
+type tooltipRest struct {
+	// since is when the pointer came to rest at pos.
+	since time.Time
+	pos   f32.Point
+	// pressed is set after a press hid the tooltip.
+	pressed bool
+	shown   bool
+}
+
+// helpQueue is implemented by event queues that know the help texts of
+// the previous frame, such as router.Router.
+type helpQueue interface {
+	HelpAt(pos f32.Point) (string, image.Rectangle, bool)
+}
+
+// Update the state of the area, and report whether its tooltip is
+// shown.
+func (t *TooltipArea) Update(gtx layout.Context) bool {
+	hovered := t.hover.Update(gtx)
+	for _, e := range gtx.Events(t) {
+		if e, ok := e.(pointer.Event); ok && e.Kind == pointer.Press {
+			t.rest.pressed = true
+		}
+	}
+	if !hovered {
+		t.rest = tooltipRest{}
+		return false
+	}
+	return t.rest.update(gtx, t.hover.Position(), t.Delay)
+}
+
+// update the rest state for the pointer at pos, and report whether the
+// tooltip is shown after delay.
+func (r *tooltipRest) update(gtx layout.Context, pos f32.Point, delay time.Duration) bool {
+	if d, slop := pos.Sub(r.pos), float32(gtx.Dp(tooltipSlop)); r.since.IsZero() || d.X*d.X+d.Y*d.Y > slop*slop {
+		r.since, r.pos, r.shown = gtx.Now, pos, false
+	}
+	if r.pressed {
+		r.shown = false
+		return false
+	}
+	if delay == 0 {
+		delay = defaultTooltipDelay
+	}
+	if due := r.since.Add(delay); gtx.Now.Before(due) {
+		op.InvalidateOp{At: due}.Add(gtx.Ops)
+	} else {
+		r.shown = true
+	}
+	return r.shown
+}
+
+// Layout the widget w, and pass the tooltip tip to tips when it is
+// shown. Tooltips of areas laid out later in the frame, such as areas
+// within w, take precedence.
+func (t *TooltipArea) Layout(gtx layout.Context, tips *Tooltips, w, tip layout.Widget) layout.Dimensions {
+	if t.Update(gtx) {
+		tips.area, tips.tip = t, tip
+	}
+	m := op.Record(gtx.Ops)
+	dims := w(gtx)
+	c := m.Stop()
+	defer clip.Rect(image.Rectangle{Max: dims.Size}).Push(gtx.Ops).Pop()
+	t.hover.TrackPosition = true
+	t.hover.Add(gtx.Ops)
+	pointer.InputOp{Tag: t, Kinds: pointer.Press}.Add(gtx.Ops)
+	c.Add(gtx.Ops)
+	return dims
+}
+
+// Layout the tooltip shown in the frame, if any, and track the pointer
+// within the constraints.
+func (t *Tooltips) Layout(gtx layout.Context) layout.Dimensions {
+	for _, e := range gtx.Events(t) {
+		e, ok := e.(pointer.Event)
+		if !ok {
+			continue
+		}
+		switch e.Kind {
+		case pointer.Enter, pointer.Move:
+			t.pos, t.hovering = e.Position, true
+		case pointer.Drag:
+			t.pos = e.Position
+		case pointer.Press:
+			t.help.pressed = true
+		case pointer.Leave, pointer.Cancel:
+			t.hovering = false
+		}
+	}
+	size := gtx.Constraints.Max
+	area := clip.Rect(image.Rectangle{Max: size}).Push(gtx.Ops)
+	pass := pointer.PassOp{}.Push(gtx.Ops)
+	pointer.InputOp{Tag: t, Kinds: pointer.Enter | pointer.Move | pointer.Drag | pointer.Press | pointer.Leave}.Add(gtx.Ops)
+	pass.Pop()
+	area.Pop()
+	if t.tip == nil {
+		t.tip = t.helpTip(gtx)
+	}
+	if t.tip != nil {
+		p := t.Popup
+		if p.Gap == 0 {
+			p.Gap = unit.Dp(20)
+		}
+		pos := t.pos.Round()
+		anchor := image.Rectangle{Min: pos, Max: pos}
+		t.placement = p.Layout(gtx, anchor, image.Rectangle{Max: size}, t.tip)
+	}
+	t.area, t.tip = nil, nil
+	return layout.Dimensions{Size: size}
+}
+
+// helpTip returns the tooltip for the help text under the pointer, or
+// nil if it isn't shown.
+func (t *Tooltips) helpTip(gtx layout.Context) layout.Widget {
+	q, ok := gtx.Queue.(helpQueue)
+	if t.Help == nil || !ok || !t.hovering {
+		t.help, t.helpBounds = tooltipRest{}, image.Rectangle{}
+		return nil
+	}
+	// The help texts are those of the previous frame, which is what
+	// the pointer rests on.
+	text, bounds, ok := q.HelpAt(t.pos)
+	if !ok || bounds != t.helpBounds {
+		// The pointer left the component.
+		t.help, t.helpBounds = tooltipRest{}, bounds
+	}
+	if !ok || !t.help.update(gtx, t.pos, t.HelpDelay) {
+		return nil
+	}
+	return func(gtx layout.Context) layout.Dimensions {
+		return t.Help(gtx, text)
+	}
+}
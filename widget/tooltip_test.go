@@ -0,0 +1,188 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package widget
+
+import (
+	"image"
+	"testing"
+	"time"
+
+	"github.com/Seikaijyu/gio/f32"
+	"github.com/Seikaijyu/gio/io/pointer"
+	"github.com/Seikaijyu/gio/io/router"
+	"github.com/Seikaijyu/gio/io/semantic"
+	"github.com/Seikaijyu/gio/layout"
+	"github.com/Seikaijyu/gio/op"
+	"github.com/Seikaijyu/gio/op/clip"
+)
+
+func TestTooltipArea(t *testing.T) {
+	var (
+		ops        op.Ops
+		r          router.Router
+		tips       Tooltips
+		outer, btn TooltipArea
+		click      Clickable
+	)
+	now := time.Now()
+	var shown string
+	clicks := 0
+	frame := func() {
+		shown = ""
+		layoutFrame(&r, &ops, image.Pt(200, 100), func(gtx layout.Context) {
+			gtx.Now = now
+			for click.Clicked(gtx) {
+				clicks++
+			}
+			tip := func(name string) layout.Widget {
+				return func(gtx layout.Context) layout.Dimensions {
+					shown = name
+					return layout.Dimensions{Size: image.Pt(80, 20)}
+				}
+			}
+			outer.Layout(gtx, &tips, func(gtx layout.Context) layout.Dimensions {
+				return btn.Layout(gtx, &tips, func(gtx layout.Context) layout.Dimensions {
+					return click.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+						return layout.Dimensions{Size: image.Pt(200, 100)}
+					})
+				}, tip("button"))
+			}, tip("outer"))
+			tips.Layout(gtx)
+		})
+	}
+	move := func(x, y float32) {
+		r.Queue(pointer.Event{Kind: pointer.Move, Source: pointer.Mouse, Position: f32.Pt(x, y)})
+	}
+	frame()
+	move(190, 90)
+	frame()
+	if shown != "" {
+		t.Fatalf("tooltip %q shown before the delay", shown)
+	}
+	if _, ok := r.WakeupTime(); !ok {
+		t.Error("no wakeup scheduled for the delay")
+	}
+	now = now.Add(defaultTooltipDelay)
+	frame()
+	if shown != "button" {
+		t.Fatalf("shown tooltip %q, want the innermost %q", shown, "button")
+	}
+	want := image.Rect(120, 50, 200, 70)
+	if got := tips.placement.Rect; got != want {
+		t.Errorf("tooltip at %v, want %v kept inside the window", got, want)
+	}
+
+	// Moving the pointer hides the tooltip until it rests again.
+	move(100, 50)
+	frame()
+	if shown != "" {
+		t.Errorf("tooltip %q shown after a move", shown)
+	}
+	now = now.Add(defaultTooltipDelay)
+	frame()
+	if shown != "button" {
+		t.Errorf("tooltip %q shown after resting, want %q", shown, "button")
+	}
+
+	// A press hides the tooltip, and is still delivered to the button.
+	r.Queue(
+		pointer.Event{Kind: pointer.Press, Source: pointer.Mouse, Buttons: pointer.ButtonPrimary, Position: f32.Pt(100, 50)},
+		pointer.Event{Kind: pointer.Release, Source: pointer.Mouse, Position: f32.Pt(100, 50)},
+	)
+	frame()
+	if shown != "" {
+		t.Errorf("tooltip %q shown after a press", shown)
+	}
+	frame()
+	if clicks != 1 {
+		t.Error("press not delivered to the widget under the tooltip area")
+	}
+	now = now.Add(2 * defaultTooltipDelay)
+	frame()
+	if shown != "" {
+		t.Errorf("tooltip %q shown again before the pointer left", shown)
+	}
+}
+
+func TestTooltipsHelp(t *testing.T) {
+	var (
+		ops  op.Ops
+		r    router.Router
+		tips Tooltips
+		area TooltipArea
+	)
+	now := time.Now()
+	var shown string
+	tips.Help = func(gtx layout.Context, text string) layout.Dimensions {
+		shown = text
+		return layout.Dimensions{Size: image.Pt(80, 20)}
+	}
+	withArea := false
+	frame := func() {
+		shown = ""
+		layoutFrame(&r, &ops, image.Pt(200, 100), func(gtx layout.Context) {
+			gtx.Now = now
+			for i, help := range []string{"left", "right"} {
+				off := op.Offset(image.Pt(i*100, 0)).Push(gtx.Ops)
+				cl := clip.Rect{Max: image.Pt(100, 100)}.Push(gtx.Ops)
+				semantic.HelpOp(help).Add(gtx.Ops)
+				cl.Pop()
+				off.Pop()
+			}
+			if withArea {
+				area.Layout(gtx, &tips, func(gtx layout.Context) layout.Dimensions {
+					return layout.Dimensions{Size: image.Pt(100, 100)}
+				}, func(gtx layout.Context) layout.Dimensions {
+					shown = "area"
+					return layout.Dimensions{Size: image.Pt(80, 20)}
+				})
+			}
+			tips.Layout(gtx)
+		})
+	}
+	move := func(x, y float32) {
+		r.Queue(pointer.Event{Kind: pointer.Move, Source: pointer.Mouse, Position: f32.Pt(x, y)})
+	}
+	frame()
+	move(50, 50)
+	frame()
+	if shown != "" {
+		t.Fatalf("help %q shown before the delay", shown)
+	}
+	now = now.Add(defaultTooltipDelay)
+	frame()
+	if shown != "left" {
+		t.Fatalf("shown help %q, want %q", shown, "left")
+	}
+
+	// A press hides the help until the pointer moves to another
+	// component.
+	r.Queue(
+		pointer.Event{Kind: pointer.Press, Source: pointer.Mouse, Buttons: pointer.ButtonPrimary, Position: f32.Pt(50, 50)},
+		pointer.Event{Kind: pointer.Release, Source: pointer.Mouse, Position: f32.Pt(50, 50)},
+	)
+	frame()
+	now = now.Add(defaultTooltipDelay)
+	frame()
+	if shown != "" {
+		t.Errorf("help %q shown after a press", shown)
+	}
+	move(150, 50)
+	frame()
+	now = now.Add(defaultTooltipDelay)
+	frame()
+	if shown != "right" {
+		t.Errorf("shown help %q, want %q", shown, "right")
+	}
+
+	// The tooltip of a TooltipArea takes precedence.
+	withArea = true
+	frame()
+	move(50, 50)
+	frame()
+	now = now.Add(defaultTooltipDelay)
+	frame()
+	if shown != "area" {
+		t.Errorf("shown tooltip %q, want the tooltip of the area", shown)
+	}
+}
@@ -0,0 +1,420 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package widget
+
+import (
+	"image"
+
+	"github.com/Seikaijyu/gio/gesture"
+	"github.com/Seikaijyu/gio/io/key"
+	"github.com/Seikaijyu/gio/io/pointer"
+	"github.com/Seikaijyu/gio/io/semantic"
+	"github.com/Seikaijyu/gio/layout"
+	"github.com/Seikaijyu/gio/op"
+	"github.com/Seikaijyu/gio/op/clip"
+)
+
+// Tree is the state of a tree view, such as of the folders of a file
+// browser or the sections of a settings UI. Nodes are identified by
+// keys, such as paths, and the empty key is the root, which is not
+// shown. The tree is virtualized: it lays out only the visible rows of
+// the nodes below expanded nodes.
+//
+// A focused Tree is navigated by keyboard. The up and down arrows move
+// the selection, the right arrow expands the selected node or moves to
+// its first child, the left arrow collapses the selected node or moves
+// to its parent, space toggles the selected node and return activates
+// it. A double click toggles and activates a node.
+type Tree struct {
+	// List lays out and scrolls the rows.
+	List layout.List
+	// Children returns the keys of the children of a node. It is called
+	// when the node is first expanded, and the children are kept until
+	// Reload, so they can be loaded lazily, such as by listing a
+	// directory.
+	Children func(key string) []string
+	// IsLeaf, if set, reports whether a node has no children without
+	// loading them, such as for the files of a file browser.
+	IsLeaf func(key string) bool
+	// Selected is the key of the selected node, or empty.
+	Selected string
+
+	expanded map[string]bool
+	// children are the loaded children of nodes.
+	children map[string][]string
+	// rows are the visible nodes, in order.
+	rows      []TreeNode
+	rowsValid bool
+	// clicks are the click states of the nodes laid out, and
+	// prevClicks of the nodes laid out in the previous frame.
+	clicks, prevClicks map[string]*gesture.Click
+
+	keyTag       struct{}
+	focused      bool
+	requestFocus bool
+	// scrollTo is set to scroll the selected node into view.
+	scrollTo bool
+	events   []TreeEvent
+}
+
+// TreeNode is a visible node of a Tree, passed to the function laying out
+// its row.
+type TreeNode struct {
+	Key string
+	// Depth is the number of ancestors of the node, not counting the
+	// root. Use it to indent the row.
+	Depth int
+	// Leaf is set for nodes without children.
+	Leaf bool
+	// Expanded is set for nodes whose children are shown.
+	Expanded bool
+	// Selected is set for the selected node.
+	Selected bool
+}
+
+// TreeEvent is an interaction of the user with a node of a Tree.
+type TreeEvent struct {
+	Kind TreeEventKind
+	Key  string
+}
+
+// TreeEventKind is the kind of a TreeEvent.
+type TreeEventKind uint8
+
+const (
+	// TreeSelect is reported when the user selects a node.
+	TreeSelect TreeEventKind = iota
+	// TreeActivate is reported when the user double clicks a node or
+	// presses return on the selected node.
+	TreeActivate
+	// TreeExpand is reported when the user expands a node.
+	TreeExpand
+	// TreeCollapse is reported when the user collapses a node.
+	TreeCollapse
+)
+
+// Expand shows the children of the node with key k, loading them if
+// necessary. The ancestors of the node are not expanded.
+func (t *Tree) Expand(k string) {
+	if t.expanded == nil {
+		t.expanded = make(map[string]bool)
+	}
+	t.expanded[k] = true
+	t.rowsValid = false
+}
+
+// Collapse hides the children of the node with key k.
+func (t *Tree) Collapse(k string) {
+	delete(t.expanded, k)
+	t.rowsValid = false
+}
+
+// Expanded reports whether the children of the node with key k are
+// shown.
+func (t *Tree) Expanded(k string) bool {
+	return t.expanded[k]
+}
+
+// Reload forgets the children of the node with key k, loading them
+// again if the node is expanded, such as after the contents of a
+// directory changed. Reload the root, the empty key, to reload the top
+// level nodes.
+func (t *Tree) Reload(k string) {
+	delete(t.children, k)
+	t.rowsValid = false
+}
+
+// Focus requests the input focus for the tree.
+func (t *Tree) Focus() {
+	t.requestFocus = true
+}
+
+// Focused reports whether the tree has the input focus.
+func (t *Tree) Focused() bool {
+	return t.focused
+}
+
+// Nodes returns the visible nodes, in order.
+func (t *Tree) Nodes() []TreeNode {
+	t.layoutRows()
+	return t.rows
+}
+
+// Update the state of the tree, and return the events of the user
+// interaction since the last call, if any.
+func (t *Tree) Update(gtx layout.Context) []TreeEvent {
+	t.update(gtx)
+	events := t.events
+	t.events = nil
+	return events
+}
+
+// update processes input, queueing the resulting events for Update.
+func (t *Tree) update(gtx layout.Context) {
+	if gtx.Queue == nil {
+		t.focused = false
+	}
+	if t.requestFocus {
+		key.FocusOp{Tag: &t.keyTag}.Add(gtx.Ops)
+		t.requestFocus = false
+	}
+	t.layoutRows()
+	// Process the clicks in the order of the rows, for a deterministic
+	// order of events. Toggling a node lays out the rows again.
+	for r := 0; r < len(t.rows); r++ {
+		k := t.rows[r].Key
+		c := t.clicks[k]
+		if c == nil {
+			continue
+		}
+		c.DoubleClickDuration = gtx.Gestures.DoubleClick
+		for _, e := range c.Update(gtx) {
+			i := t.index(k)
+			if i == -1 {
+				break
+			}
+			n := t.rows[i]
+			switch e.Kind {
+			case gesture.KindPress:
+				if e.Source == pointer.Mouse {
+					key.FocusOp{Tag: &t.keyTag}.Add(gtx.Ops)
+				}
+			case gesture.KindClick:
+				t.selectNode(n.Key)
+				if e.NumClicks == 2 {
+					t.toggle(n)
+					t.events = append(t.events, TreeEvent{Kind: TreeActivate, Key: n.Key})
+				}
+			}
+		}
+	}
+	for _, e := range gtx.Events(&t.keyTag) {
+		switch e := e.(type) {
+		case key.FocusEvent:
+			t.focused = e.Focus
+		case key.Event:
+			if t.focused && e.State == key.Press {
+				t.command(e)
+			}
+		}
+	}
+}
+
+// command handles the navigation key k.
+func (t *Tree) command(k key.Event) {
+	t.layoutRows()
+	if len(t.rows) == 0 {
+		return
+	}
+	i := t.index(t.Selected)
+	if i == -1 {
+		// Start navigation at the first node.
+		t.selectNode(t.rows[0].Key)
+		return
+	}
+	n := t.rows[i]
+	switch k.Name {
+	case key.NameUpArrow:
+		if i > 0 {
+			t.selectNode(t.rows[i-1].Key)
+		}
+	case key.NameDownArrow:
+		if i < len(t.rows)-1 {
+			t.selectNode(t.rows[i+1].Key)
+		}
+	case key.NameHome:
+		t.selectNode(t.rows[0].Key)
+	case key.NameEnd:
+		t.selectNode(t.rows[len(t.rows)-1].Key)
+	case key.NameRightArrow:
+		switch {
+		case !n.Leaf && !n.Expanded:
+			t.toggle(n)
+		case n.Expanded && i < len(t.rows)-1 && t.rows[i+1].Depth > n.Depth:
+			t.selectNode(t.rows[i+1].Key)
+		}
+	case key.NameLeftArrow:
+		if n.Expanded {
+			t.toggle(n)
+			break
+		}
+		for j := i - 1; j >= 0; j-- {
+			if t.rows[j].Depth < n.Depth {
+				t.selectNode(t.rows[j].Key)
+				break
+			}
+		}
+	case key.NameSpace:
+		if !n.Leaf {
+			t.toggle(n)
+		}
+	case key.NameReturn, key.NameEnter:
+		t.events = append(t.events, TreeEvent{Kind: TreeActivate, Key: n.Key})
+	}
+}
+
+// selectNode selects the node with key k by user interaction.
+func (t *Tree) selectNode(k string) {
+	t.scrollTo = true
+	if t.Selected == k {
+		return
+	}
+	t.Selected = k
+	t.events = append(t.events, TreeEvent{Kind: TreeSelect, Key: k})
+}
+
+// toggle expands or collapses n by user interaction.
+func (t *Tree) toggle(n TreeNode) {
+	if n.Leaf {
+		return
+	}
+	if n.Expanded {
+		t.Collapse(n.Key)
+		t.events = append(t.events, TreeEvent{Kind: TreeCollapse, Key: n.Key})
+	} else {
+		t.Expand(n.Key)
+		t.events = append(t.events, TreeEvent{Kind: TreeExpand, Key: n.Key})
+	}
+	// The selection may have been hidden by collapsing an ancestor.
+	t.layoutRows()
+	if t.Selected != "" && t.index(t.Selected) == -1 {
+		t.selectNode(n.Key)
+	}
+}
+
+// index returns the row of the node with key k, or -1.
+func (t *Tree) index(k string) int {
+	for i, n := range t.rows {
+		if n.Key == k {
+			return i
+		}
+	}
+	return -1
+}
+
+// layoutRows updates the visible rows if they changed.
+func (t *Tree) layoutRows() {
+	if t.rowsValid {
+		return
+	}
+	t.rowsValid = true
+	t.rows = t.appendRows(t.rows[:0], "", 0)
+}
+
+func (t *Tree) appendRows(rows []TreeNode, parent string, depth int) []TreeNode {
+	for _, k := range t.loadChildren(parent) {
+		n := TreeNode{
+			Key:      k,
+			Depth:    depth,
+			Expanded: t.expanded[k],
+		}
+		if n.Expanded {
+			n.Leaf = len(t.loadChildren(k)) == 0
+		} else if t.IsLeaf != nil {
+			n.Leaf = t.IsLeaf(k)
+		}
+		if n.Leaf {
+			n.Expanded = false
+		}
+		rows = append(rows, n)
+		if n.Expanded {
+			rows = t.appendRows(rows, k, depth+1)
+		}
+	}
+	return rows
+}
+
+// loadChildren returns the children of the node with key k, loading
+// them if necessary.
+func (t *Tree) loadChildren(k string) []string {
+	if children, ok := t.children[k]; ok {
+		return children
+	}
+	if t.Children == nil {
+		return nil
+	}
+	if t.children == nil {
+		t.children = make(map[string][]string)
+	}
+	children := t.Children(k)
+	t.children[k] = children
+	return children
+}
+
+// scrollSelected scrolls the selected node into view.
+func (t *Tree) scrollSelected() {
+	i := t.index(t.Selected)
+	if i == -1 {
+		return
+	}
+	p := &t.List.Position
+	switch {
+	case i < p.First || i == p.First && p.Offset > 0:
+		p.First, p.Offset = i, 0
+	case p.Count > 0 && i >= p.First+p.Count:
+		p.First, p.Offset = i-p.Count+1, 0
+	}
+	p.BeforeEnd = true
+}
+
+// Layout the visible nodes, with w laying out the row of a node.
+func (t *Tree) Layout(gtx layout.Context, w func(gtx layout.Context, n TreeNode) layout.Dimensions) layout.Dimensions {
+	t.update(gtx)
+	if t.scrollTo {
+		t.scrollTo = false
+		t.scrollSelected()
+	}
+	t.List.Axis = layout.Vertical
+	// Keep the click states of the nodes laid out in this frame only.
+	t.clicks, t.prevClicks = t.prevClicks, t.clicks
+	if t.clicks == nil {
+		t.clicks = make(map[string]*gesture.Click)
+	}
+	for k := range t.clicks {
+		delete(t.clicks, k)
+	}
+	m := op.Record(gtx.Ops)
+	dims := t.List.Layout(gtx, len(t.rows), func(gtx layout.Context, i int) layout.Dimensions {
+		n := t.rows[i]
+		n.Selected = n.Key == t.Selected
+		c := t.prevClicks[n.Key]
+		if c == nil {
+			c = new(gesture.Click)
+		}
+		t.clicks[n.Key] = c
+		m := op.Record(gtx.Ops)
+		dims := w(gtx, n)
+		call := m.Stop()
+		defer clip.Rect{Max: dims.Size}.Push(gtx.Ops).Pop()
+		c.Add(gtx.Ops)
+		semantic.SelectedOp(n.Selected).Add(gtx.Ops)
+		call.Add(gtx.Ops)
+		return dims
+	})
+	call := m.Stop()
+	defer clip.Rect(image.Rectangle{Max: dims.Size}).Push(gtx.Ops).Pop()
+	if gtx.Queue != nil {
+		keys := key.Set("↑|↓|←|→|⇱|⇲|Space|⏎|⌤")
+		if !t.focused {
+			keys = ""
+		}
+		key.InputOp{Tag: &t.keyTag, Keys: keys}.Add(gtx.Ops)
+	}
+	call.Add(gtx.Ops)
+	return dims
+}
+
+func (k TreeEventKind) String() string {
+	switch k {
+	case TreeSelect:
+		return "Select"
+	case TreeActivate:
+		return "Activate"
+	case TreeExpand:
+		return "Expand"
+	case TreeCollapse:
+		return "Collapse"
+	default:
+		panic("invalid TreeEventKind")
+	}
+}
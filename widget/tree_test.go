@@ -0,0 +1,141 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package widget
+
+import (
+	"image"
+	"reflect"
+	"testing"
+
+	"github.com/Seikaijyu/gio/f32"
+	"github.com/Seikaijyu/gio/io/key"
+	"github.com/Seikaijyu/gio/io/pointer"
+	"github.com/Seikaijyu/gio/io/router"
+	"github.com/Seikaijyu/gio/layout"
+	"github.com/Seikaijyu/gio/op"
+)
+
+func TestTree(t *testing.T) {
+	children := map[string][]string{
+		"":    {"a", "b"},
+		"a":   {"a/1", "a/2"},
+		"a/1": {"a/1/x"},
+	}
+	var loads []string
+	tree := &Tree{
+		Children: func(k string) []string {
+			loads = append(loads, k)
+			return children[k]
+		},
+		IsLeaf: func(k string) bool {
+			return len(children[k]) == 0
+		},
+	}
+	var (
+		ops op.Ops
+		r   router.Router
+	)
+	const rowHeight = 20
+	var events []TreeEvent
+	frame := func() {
+		layoutFrame(&r, &ops, image.Pt(100, 1000), func(gtx layout.Context) {
+			events = append(events, tree.Update(gtx)...)
+			tree.Layout(gtx, func(gtx layout.Context, n TreeNode) layout.Dimensions {
+				return layout.Dimensions{Size: image.Pt(100, rowHeight)}
+			})
+		})
+	}
+	keys := func() []string {
+		var keys []string
+		for _, n := range tree.Nodes() {
+			keys = append(keys, n.Key)
+		}
+		return keys
+	}
+	tree.Focus()
+	frame()
+	frame()
+	if got, want := keys(), []string{"a", "b"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("nodes %v, want %v", got, want)
+	}
+	if got, want := loads, []string{""}; !reflect.DeepEqual(got, want) {
+		t.Errorf("loaded %v before expanding, want %v", got, want)
+	}
+
+	press := func(names ...string) {
+		for _, n := range names {
+			r.Queue(key.Event{Name: n, State: key.Press})
+		}
+		frame()
+	}
+	// Navigate to the first node, expand it and select its first child.
+	press(key.NameDownArrow, key.NameRightArrow, key.NameRightArrow)
+	if got, want := keys(), []string{"a", "a/1", "a/2", "b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expanded nodes %v, want %v", got, want)
+	}
+	if tree.Selected != "a/1" {
+		t.Errorf("selected %q, want %q", tree.Selected, "a/1")
+	}
+	if n := tree.Nodes()[1]; n.Depth != 1 || n.Leaf || n.Expanded {
+		t.Errorf("got node %+v, want a collapsed node at depth 1", n)
+	}
+	// Left moves to the parent, and then collapses it.
+	press(key.NameLeftArrow, key.NameLeftArrow)
+	if got, want := keys(), []string{"a", "b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("collapsed nodes %v, want %v", got, want)
+	}
+	want := []TreeEvent{
+		{Kind: TreeSelect, Key: "a"},
+		{Kind: TreeExpand, Key: "a"},
+		{Kind: TreeSelect, Key: "a/1"},
+		{Kind: TreeSelect, Key: "a"},
+		{Kind: TreeCollapse, Key: "a"},
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("events %v, want %v", events, want)
+	}
+	events = nil
+
+	// Clicking selects, double clicking toggles and activates.
+	click := func(row int) {
+		pos := f32.Pt(50, float32(row*rowHeight+rowHeight/2))
+		r.Queue(
+			pointer.Event{Kind: pointer.Press, Source: pointer.Mouse, Buttons: pointer.ButtonPrimary, Position: pos},
+			pointer.Event{Kind: pointer.Release, Source: pointer.Mouse, Position: pos},
+		)
+	}
+	click(1)
+	frame()
+	click(0)
+	click(0)
+	frame()
+	want = []TreeEvent{
+		{Kind: TreeSelect, Key: "b"},
+		{Kind: TreeSelect, Key: "a"},
+		{Kind: TreeExpand, Key: "a"},
+		{Kind: TreeActivate, Key: "a"},
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("events %v, want %v", events, want)
+	}
+	events = nil
+
+	// Clicks in the same frame are reported in the order of the rows.
+	click(2)
+	click(1)
+	frame()
+	want = []TreeEvent{
+		{Kind: TreeSelect, Key: "a/1"},
+		{Kind: TreeSelect, Key: "a/2"},
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("events %v, want %v", events, want)
+	}
+
+	// Reload loads the children again.
+	children["a"] = []string{"a/3"}
+	tree.Reload("a")
+	if got, want := keys(), []string{"a", "a/3", "b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("reloaded nodes %v, want %v", got, want)
+	}
+}
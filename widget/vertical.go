@@ -0,0 +1,52 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package widget
+
+import (
+	"image"
+	"math"
+
+	"github.com/Seikaijyu/gio/f32"
+	"github.com/Seikaijyu/gio/io/key"
+	"github.com/Seikaijyu/gio/layout"
+	"github.com/Seikaijyu/gio/op"
+)
+
+// Vertical text is laid out as horizontal lines that are rotated
+// clockwise into columns. The lines run from top to bottom, and the
+// columns from right to left. Logical, horizontal, coordinates (x, y)
+// map to (w-y, x) where w is the width of the text in columns.
+
+// verticalConstraints returns the constraints of the horizontal layout
+// of vertical text constrained by cs.
+func verticalConstraints(cs layout.Constraints) layout.Constraints {
+	return layout.Constraints{
+		Min: image.Pt(cs.Min.Y, cs.Min.X),
+		Max: image.Pt(cs.Max.Y, cs.Max.X),
+	}
+}
+
+// verticalTransform rotates horizontal text into columns, for text
+// whose lines are height pixels high in total.
+func verticalTransform(height int) op.TransformOp {
+	return op.Affine(f32.Affine2D{}.
+		Rotate(f32.Point{}, math.Pi/2).
+		Offset(f32.Pt(float32(height), 0)))
+}
+
+// verticalKey maps the arrow key name to the key that moves the caret
+// the same way in horizontal text: up and down move along the column,
+// left and right between columns.
+func verticalKey(name string) string {
+	switch name {
+	case key.NameUpArrow:
+		return key.NameLeftArrow
+	case key.NameDownArrow:
+		return key.NameRightArrow
+	case key.NameLeftArrow:
+		return key.NameDownArrow
+	case key.NameRightArrow:
+		return key.NameUpArrow
+	}
+	return name
+}
@@ -0,0 +1,123 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package widget
+
+import (
+	"image"
+	"reflect"
+	"testing"
+
+	"github.com/Seikaijyu/gio/f32"
+	"github.com/Seikaijyu/gio/font"
+	"github.com/Seikaijyu/gio/font/gofont"
+	"github.com/Seikaijyu/gio/io/key"
+	"github.com/Seikaijyu/gio/io/pointer"
+	"github.com/Seikaijyu/gio/io/router"
+	"github.com/Seikaijyu/gio/layout"
+	"github.com/Seikaijyu/gio/op"
+	"github.com/Seikaijyu/gio/text"
+
+	"golang.org/x/exp/slices"
+	"golang.org/x/image/math/fixed"
+)
+
+func TestVerticalUpright(t *testing.T) {
+	lt := text.NewShaper(text.NoSystemFonts(), text.WithCollection(gofont.Collection()))
+	lt.LayoutString(text.Parameters{
+		PxPerEm:  fixed.I(10),
+		MaxWidth: 1000,
+		Vertical: true,
+	}, "a中「")
+	var upright []bool
+	for g, ok := lt.NextGlyph(); ok; g, ok = lt.NextGlyph() {
+		upright = append(upright, g.Flags&text.FlagUpright != 0)
+	}
+	if want := []bool{false, true, false}; !reflect.DeepEqual(upright, want) {
+		t.Errorf("upright glyphs %v, want %v", upright, want)
+	}
+}
+
+func TestVerticalLabel(t *testing.T) {
+	lt := text.NewShaper(text.NoSystemFonts(), text.WithCollection(gofont.Collection()))
+	gtx := layout.Context{
+		Ops:         new(op.Ops),
+		Constraints: layout.Constraints{Max: image.Pt(1000, 200)},
+	}
+	horiz := Label{}.Layout(gtx, lt, font.Font{}, 10, "vertical text", op.CallOp{})
+	vert := Label{Vertical: true}.Layout(gtx, lt, font.Font{}, 10, "vertical text", op.CallOp{})
+	if got, want := vert.Size, image.Pt(horiz.Size.Y, horiz.Size.X); got != want {
+		t.Errorf("vertical size %v, want %v", got, want)
+	}
+}
+
+func TestVerticalEditor(t *testing.T) {
+	lt := text.NewShaper(text.NoSystemFonts(), text.WithCollection(gofont.Collection()))
+	e := &Editor{Vertical: true, ReadOnly: true}
+	e.SetText("abc\ndef")
+	var (
+		ops  op.Ops
+		r    router.Router
+		dims layout.Dimensions
+	)
+	frame := func() {
+		layoutFrame(&r, &ops, image.Pt(200, 200), func(gtx layout.Context) {
+			gtx.Constraints.Min = image.Point{}
+			dims = e.Layout(gtx, lt, font.Font{}, 10, op.CallOp{}, op.CallOp{})
+		})
+	}
+	e.Focus()
+	frame()
+	frame()
+	if lines := e.text.Dimensions().Size; dims.Size != image.Pt(lines.Y, lines.X) {
+		t.Fatalf("vertical editor size %v, want the lines %v rotated", dims.Size, lines)
+	}
+	steps := []struct {
+		key   string
+		caret int
+	}{
+		// Down moves along the column, left to the next column.
+		{key.NameDownArrow, 1},
+		{key.NameDownArrow, 2},
+		{key.NameLeftArrow, 6},
+		{key.NameUpArrow, 5},
+		{key.NameRightArrow, 1},
+	}
+	for _, s := range steps {
+		r.Queue(key.Event{Name: s.key, State: key.Press})
+		frame()
+		if caret, _ := e.Selection(); caret != s.caret {
+			t.Errorf("after %s: caret %d, want %d", s.key, caret, s.caret)
+		}
+	}
+	// The arrows that move backward from the start of the text, or
+	// forward from its end, are left for moving the focus.
+	ends := []struct {
+		caret   int
+		handled []string
+		ignored []string
+	}{
+		{0, []string{key.NameDownArrow, key.NameLeftArrow}, []string{key.NameUpArrow, key.NameRightArrow}},
+		{e.Len(), []string{key.NameUpArrow, key.NameRightArrow}, []string{key.NameDownArrow, key.NameLeftArrow}},
+	}
+	for _, end := range ends {
+		for _, n := range append(end.handled, end.ignored...) {
+			e.SetCaret(end.caret, end.caret)
+			frame()
+			handled := r.Queue(key.Event{Name: n, State: key.Press})
+			if want := slices.Contains(end.handled, n); handled != want {
+				t.Errorf("caret at %d: %s handled %v, want %v", end.caret, n, handled, want)
+			}
+			frame()
+		}
+	}
+	// The left column is the second line.
+	pos := f32.Pt(1, 1)
+	r.Queue(
+		pointer.Event{Kind: pointer.Press, Source: pointer.Mouse, Buttons: pointer.ButtonPrimary, Position: pos},
+		pointer.Event{Kind: pointer.Release, Source: pointer.Mouse, Position: pos},
+	)
+	frame()
+	if caret, _ := e.Selection(); caret != 4 {
+		t.Errorf("clicked caret %d, want 4", caret)
+	}
+}